@@ -0,0 +1,55 @@
+// Copyright 2019 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// package aliases tests type aliases, which are bound as their target type,
+// with python names referring to the class of the target.
+package aliases
+
+import "strings"
+
+// Point is a point in 2D
+type Point struct {
+	X, Y int
+}
+
+// Sum returns the sum of the coordinates
+func (p *Point) Sum() int { return p.X + p.Y }
+
+// Pt is a short name for Point
+type Pt = Point
+
+// Coord is a coordinate
+type Coord = int
+
+// Path is a sequence of points
+type Path = []*Pt
+
+// Builder is a string builder from another package
+type Builder = strings.Builder
+
+// NewPt returns a new Pt, which is a Point
+func NewPt(x, y Coord) *Pt { return &Pt{X: x, Y: y} }
+
+// Add returns the sum of two coordinates
+func Add(a, b Coord) Coord { return a + b }
+
+// Length returns the number of points of the path
+func Length(p Path) int { return len(p) }
+
+// Origin returns the origin of the path
+func Origin(p Path) Pt {
+	if len(p) == 0 {
+		return Pt{}
+	}
+	return *p[0]
+}
+
+// NewBuilder returns a new Builder
+func NewBuilder() *Builder { return &Builder{} }
+
+// Write appends s to the Builder and returns its contents
+func Write(b *Builder, s string) string {
+	b.WriteString(s)
+	return b.String()
+}
@@ -0,0 +1,23 @@
+# Copyright 2019 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+## py2/py3 compat
+from __future__ import print_function
+
+import go, aliases
+
+p = aliases.NewPt(1, 2)
+print("NewPt:", type(p).__name__, p.X, p.Y, p.Sum())
+print("Pt is Point:", aliases.Pt is aliases.Point)
+q = aliases.Pt(X=3, Y=4)
+print("Pt:", type(q).__name__, q.Sum())
+print("Add:", aliases.Add(2, 3), aliases.Coord is int)
+print("Length:", aliases.Length([p, q]))
+o = aliases.Origin(aliases.Path([q, p]))
+print("Origin:", o.X, o.Y)
+b = aliases.NewBuilder()
+aliases.Write(b, "ab")
+print("Write:", aliases.Write(b, "cd"), isinstance(b, aliases.Builder))
+
+print("OK")
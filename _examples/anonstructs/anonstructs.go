@@ -0,0 +1,78 @@
+// Copyright 2019 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// package anonstructs tests binding anonymous struct types, which are
+// wrapped as python classes named after where they are first used.
+package anonstructs
+
+// Stats returns an anonymous struct, wrapped as Stats_Result
+func Stats() struct {
+	Count int
+	Err   string
+} {
+	return struct {
+		Count int
+		Err   string
+	}{3, "none"}
+}
+
+// Total returns the count of the given stats, which have the same
+// type as the result of Stats, and thus are also a Stats_Result
+func Total(s struct {
+	Count int
+	Err   string
+}) int {
+	return s.Count
+}
+
+// Point returns a pointer to an anonymous struct with a tagged field
+func Point(x, y int) *struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+} {
+	return &struct {
+		X int `json:"x"`
+		Y int `json:"y"`
+	}{x, y}
+}
+
+// Config has an anonymous struct field, wrapped as Config_Limits
+type Config struct {
+	Name   string
+	Limits struct {
+		Min, Max int
+		// Step is nested in the limits, wrapped as Config_Limits_Step
+		Step struct{ Size int }
+	}
+}
+
+// NewConfig returns a new config with the given limits
+func NewConfig(name string, min, max int) *Config {
+	c := &Config{Name: name}
+	c.Limits.Min = min
+	c.Limits.Max = max
+	c.Limits.Step.Size = 1
+	return c
+}
+
+// Range returns the range of the limits
+func (c *Config) Range() int {
+	return c.Limits.Max - c.Limits.Min
+}
+
+// Pairs returns a slice of anonymous structs, wrapped as Pairs_Result
+func Pairs() []struct {
+	Key   string
+	Value int
+} {
+	return []struct {
+		Key   string
+		Value int
+	}{{"a", 1}, {"b", 2}}
+}
+
+// Hidden has unexported fields, so it cannot be bound
+func Hidden() struct{ n int } {
+	return struct{ n int }{1}
+}
@@ -0,0 +1,34 @@
+# Copyright 2019 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+## py2/py3 compat
+from __future__ import print_function
+
+import anonstructs
+
+s = anonstructs.Stats()
+print("type:", type(s).__name__)
+print("s.Count:", s.Count)
+print("s.Err:", s.Err)
+s.Count = 5
+print("Total(s):", anonstructs.Total(s))
+print("Total(new):", anonstructs.Total(anonstructs.Stats_Result(Count=7)))
+
+p = anonstructs.Point(1, 2)
+print("type:", type(p).__name__)
+print("p.X, p.Y:", p.X, p.Y)
+
+c = anonstructs.NewConfig("cfg", 2, 10)
+print("type:", type(c.Limits).__name__)
+print("c.Limits:", c.Limits.Min, c.Limits.Max)
+print("c.Limits.Step.Size:", c.Limits.Step.Size)
+c.Limits.Max = 20
+print("c.Range():", c.Range())
+
+for kv in anonstructs.Pairs():
+	print("pair:", type(kv).__name__, kv.Key, kv.Value)
+
+print("has Hidden:", hasattr(anonstructs, "Hidden"))
+
+print("OK")
@@ -0,0 +1,80 @@
+// Copyright 2019 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// package anyargs tests passing python values for interface{} parameters,
+// which are converted to the corresponding Go values.
+package anyargs
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Point is a Go object that can be passed as an interface{}
+type Point struct {
+	X, Y int
+}
+
+// Describe returns a description of v, with the Go type of each value
+func Describe(v interface{}) string {
+	return describe(v)
+}
+
+// Equal returns whether a and b are the same Go value
+func Equal(a, b interface{}) bool {
+	return describe(a) == describe(b)
+}
+
+// Box holds a set of values by name
+type Box struct {
+	vals map[string]interface{}
+}
+
+// NewBox returns a new empty Box
+func NewBox() *Box {
+	return &Box{vals: map[string]interface{}{}}
+}
+
+// Put stores v under name, which must not be empty
+func (b *Box) Put(name string, v interface{}) error {
+	if name == "" {
+		return fmt.Errorf("empty name")
+	}
+	b.vals[name] = v
+	return nil
+}
+
+// String returns the description of the values in the Box
+func (b *Box) String() string {
+	return describe(b.vals)
+}
+
+func describe(v interface{}) string {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		parts := make([]string, len(keys))
+		for i, k := range keys {
+			parts[i] = k + ":" + describe(v[k])
+		}
+		return "{" + strings.Join(parts, " ") + "}"
+	case []interface{}:
+		parts := make([]string, len(v))
+		for i, e := range v {
+			parts[i] = describe(e)
+		}
+		return "[" + strings.Join(parts, " ") + "]"
+	case *Point:
+		return fmt.Sprintf("*Point(%d,%d)", v.X, v.Y)
+	case nil:
+		return "nil"
+	default:
+		return fmt.Sprintf("%T(%v)", v, v)
+	}
+}
@@ -0,0 +1,44 @@
+# Copyright 2019 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+## py2/py3 compat
+from __future__ import print_function
+
+import go, anyargs
+
+print("int:", anyargs.Describe(42))
+print("float:", anyargs.Describe(2.5))
+print("str:", anyargs.Describe("hi"))
+print("bool:", anyargs.Describe(True))
+print("None:", anyargs.Describe(None))
+print("list:", anyargs.Describe([1, "a", [False]]))
+print("dict:", anyargs.Describe({"b": 1.5, "a": {"c": None}}))
+
+p = anyargs.Point(X=1, Y=2)
+print("handle:", anyargs.Describe(p))
+print("Equal:", anyargs.Equal(3, 3), anyargs.Equal(3, 3.0))
+
+b = anyargs.NewBox()
+b.Put("x", [1, 2])
+b.Put("p", p)
+print("Box:", b)
+
+try:
+	b.Put("", 1)
+except Exception as e:
+	print("caught:", e)
+
+try:
+	anyargs.Describe(object())
+except TypeError as e:
+	print("caught:", e)
+
+try:
+	b.Put("o", {"o": object()})
+except TypeError as e:
+	print("caught:", e)
+
+print("Box:", b)
+
+print("OK")
@@ -0,0 +1,83 @@
+// Copyright 2019 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// package anymaps tests passing python dicts for map[string]interface{}
+// parameters, which are deep-converted to Go values.
+package anymaps
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Params is a named map of values
+type Params map[string]interface{}
+
+// Describe returns a description of the values in m, sorted by key, with
+// the Go type of each value
+func Describe(m map[string]interface{}) string {
+	return describe(m)
+}
+
+// Count returns the number of values in p, including nested values
+func (p Params) Count() int {
+	return count(p)
+}
+
+// Merge returns the description of p with the values of m merged into it
+func Merge(p Params, m map[string]interface{}) string {
+	for k, v := range m {
+		p[k] = v
+	}
+	return describe(p)
+}
+
+func describe(v interface{}) string {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		parts := make([]string, len(keys))
+		for i, k := range keys {
+			parts[i] = k + ":" + describe(v[k])
+		}
+		return "{" + strings.Join(parts, " ") + "}"
+	case Params:
+		return describe(map[string]interface{}(v))
+	case []interface{}:
+		parts := make([]string, len(v))
+		for i, e := range v {
+			parts[i] = describe(e)
+		}
+		return "[" + strings.Join(parts, " ") + "]"
+	case nil:
+		return "nil"
+	default:
+		return fmt.Sprintf("%T(%v)", v, v)
+	}
+}
+
+func count(v interface{}) int {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		n := 0
+		for _, e := range v {
+			n += count(e)
+		}
+		return n
+	case Params:
+		return count(map[string]interface{}(v))
+	case []interface{}:
+		n := 0
+		for _, e := range v {
+			n += count(e)
+		}
+		return n
+	}
+	return 1
+}
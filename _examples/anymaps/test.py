@@ -0,0 +1,35 @@
+# Copyright 2019 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+## py2/py3 compat
+from __future__ import print_function
+
+import go, anymaps
+
+print("Describe:", anymaps.Describe({"b": [1, 2.5, "x"], "a": {"n": None, "t": True}}))
+print("Describe tuple:", anymaps.Describe({"t": (1, (2, 3))}))
+
+p = anymaps.Params({"x": 1, "y": [1, 2, {"z": 3}]})
+print("Count:", p.Count())
+print("Merge:", anymaps.Merge({"a": 1}, {"b": "two"}))
+
+m = anymaps.Map_string_interface_({"k": -7})
+print("Describe handle:", anymaps.Describe(m))
+
+try:
+	anymaps.Describe({1: "x"})
+except TypeError as e:
+	print("caught:", e)
+
+try:
+	anymaps.Describe({"o": object()})
+except TypeError as e:
+	print("caught:", e)
+
+try:
+	anymaps.Describe([1])
+except TypeError as e:
+	print("caught:", e)
+
+print("OK")
@@ -0,0 +1,83 @@
+// Copyright 2019 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// package anyresults tests returning interface{} values to python, which
+// are converted to the corresponding python values.
+package anyresults
+
+import "fmt"
+
+// Point is a Go object that is returned as an instance of its python class
+type Point struct {
+	X, Y int
+}
+
+// Sum returns the sum of the coordinates
+func (p *Point) Sum() int {
+	return p.X + p.Y
+}
+
+type level int
+
+// Value returns a Go value of the given kind
+func Value(kind string) interface{} {
+	switch kind {
+	case "bool":
+		return true
+	case "int":
+		return 42
+	case "level":
+		return level(3)
+	case "uint":
+		return uint64(1 << 63)
+	case "float":
+		return 2.5
+	case "string":
+		return "hello"
+	case "bytes":
+		return []byte("raw")
+	case "map":
+		return map[string]interface{}{"a": 1, "b": []interface{}{"x", 2.0, nil}}
+	case "ints":
+		return [3]int{1, 2, 3}
+	case "intptr":
+		n := 7
+		return &n
+	case "complex":
+		return complex(1, 2)
+	case "intmap":
+		return map[int]string{1: "a"}
+	case "point":
+		return Point{1, 2}
+	case "ptr":
+		return &Point{3, 4}
+	case "nilptr":
+		return (*Point)(nil)
+	}
+	return nil
+}
+
+// Lookup returns the value of the key in m, or an error if it has none
+func Lookup(m map[string]interface{}, key string) (interface{}, error) {
+	v, has := m[key]
+	if !has {
+		return nil, fmt.Errorf("no key: %s", key)
+	}
+	return v, nil
+}
+
+// Box holds any value
+type Box struct {
+	v interface{}
+}
+
+// NewBox returns a new box holding v
+func NewBox(v interface{}) *Box {
+	return &Box{v: v}
+}
+
+// Get returns the value held by the box
+func (b *Box) Get() interface{} {
+	return b.v
+}
@@ -0,0 +1,30 @@
+# Copyright 2019 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+import go, anyresults
+
+for kind in ["none", "bool", "int", "level", "uint", "float", "string", "bytes", "ints", "intptr", "complex", "intmap"]:
+    v = anyresults.Value(kind)
+    print(kind + ":", type(v).__name__, repr(v))
+
+m = anyresults.Value("map")
+print("map:", type(m).__name__, sorted(m.items()))
+
+p = anyresults.Value("point")
+print("point:", type(p).__name__, p.X, p.Y, p.Sum())
+p = anyresults.Value("ptr")
+print("ptr:", type(p).__name__, p.X, p.Y, p.Sum())
+print("nilptr:", anyresults.Value("nilptr"))
+
+print("Lookup:", anyresults.Lookup({"a": [1, "b"]}, "a"))
+try:
+    anyresults.Lookup({}, "b")
+except go.GoError as e:
+    print("caught:", e)
+
+print("Get:", anyresults.NewBox({"k": 1.5}).Get())
+b = anyresults.NewBox(anyresults.Point(5, 6))
+print("Get:", type(b.Get()).__name__, b.Get().Sum())
+
+print("OK")
@@ -0,0 +1,51 @@
+// Copyright 2019 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// package asdicts tests the deep conversion of structs to and from plain
+// python dicts, with asdict and from_dict.
+package asdicts
+
+import "fmt"
+
+// Point is a point in the plane
+type Point struct {
+	X, Y int
+}
+
+// Shape has fields of struct, pointer, slice, map and array types
+type Shape struct {
+	Name    string
+	Origin  Point
+	Center  *Point
+	Points  []Point
+	Tags    map[string][]int
+	Scale   float64
+	Visible bool
+	Data    []byte
+	Box     [2]Point
+	Label   string `gopy:"label"`
+	hidden  int
+}
+
+// NewShape returns a new shape
+func NewShape() *Shape {
+	return &Shape{
+		Name:    "tri",
+		Origin:  Point{1, 2},
+		Points:  []Point{{0, 0}, {3, 0}, {0, 4}},
+		Tags:    map[string][]int{"a": {1, 2}},
+		Scale:   1.5,
+		Visible: true,
+		Data:    []byte("xyz"),
+		Box:     [2]Point{{0, 0}, {3, 4}},
+		Label:   "L",
+		hidden:  7,
+	}
+}
+
+// Describe returns a description of the shape
+func (s *Shape) Describe() string {
+	return fmt.Sprintf("%s %v %v %v %v %g %v %q %v %s %d", s.Name, s.Origin, s.Center, s.Points,
+		s.Tags, s.Scale, s.Visible, s.Data, s.Box, s.Label, s.hidden)
+}
@@ -0,0 +1,41 @@
+# Copyright 2019 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+import json
+
+import asdicts
+
+s = asdicts.NewShape()
+d = s.asdict()
+print("asdict:", d)
+
+d["Name"] = "quad"
+d["Center"] = {"X": 5, "Y": 6}
+d["Points"].append({"X": 7, "Y": 8})
+d["Tags"]["b"] = [3]
+t = asdicts.Shape.from_dict(d)
+print("from_dict:", t.Describe())
+
+del d["Data"]
+u = asdicts.Shape.from_dict(json.loads(json.dumps(d)))
+print("json:", u.Describe())
+
+p = asdicts.Point.from_dict({"X": 1})
+print("partial:", p.asdict())
+
+v = asdicts.Shape.from_dict({"Origin": p, "Center": p})
+print("GoClass:", v.Origin.X, v.Center.X)
+
+for bad in [{"Z": 1}, {"X": "1"}, {"X": 1.5}, []]:
+    try:
+        asdicts.Point.from_dict(bad)
+    except TypeError as err:
+        print("TypeError:", err)
+
+try:
+    asdicts.Shape.from_dict({"Box": [{}]})
+except ValueError as err:
+    print("ValueError:", err)
+
+print("OK")
@@ -0,0 +1,39 @@
+// Copyright 2019 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// package asyncdoc tests generating asyncio wrappers for the functions
+// and methods with a gopy:async directive in their doc.
+package asyncdoc
+
+import (
+	"fmt"
+	"time"
+)
+
+// Fetch fetches the item with the given id, after a while -- awaited in
+// python.
+//
+// gopy:async
+func Fetch(id int) string {
+	time.Sleep(100 * time.Millisecond)
+	return fmt.Sprintf("item-%d", id)
+}
+
+// Lookup looks up the item with the given id -- stays synchronous.
+func Lookup(id int) string {
+	return fmt.Sprintf("item-%d", id)
+}
+
+// Counter counts the items fetched.
+type Counter struct {
+	N int
+}
+
+// Add adds n to the counter, after a while -- awaited in python.
+// gopy:async
+func (c *Counter) Add(n int) int {
+	time.Sleep(10 * time.Millisecond)
+	c.N += n
+	return c.N
+}
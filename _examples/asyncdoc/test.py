@@ -0,0 +1,26 @@
+# Copyright 2019 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+import asyncio
+import time
+
+import asyncdoc
+
+
+async def main():
+    print("fetch: %s" % await asyncdoc.Fetch(1))
+    start = time.monotonic()
+    items = await asyncio.gather(*[asyncdoc.Fetch(i) for i in range(4)])
+    print("gathered: %s" % items)
+    print("concurrent: %s" % (time.monotonic() - start < 0.35))
+    print("lookup: %s" % asyncdoc.Lookup(2))
+    c = asyncdoc.Counter()
+    await c.Add(2)
+    print("add: %d" % await c.Add(3))
+    print("iscoroutinefunction(Fetch): %s" % asyncio.iscoroutinefunction(asyncdoc.Fetch))
+    print("iscoroutinefunction(Lookup): %s" % asyncio.iscoroutinefunction(asyncdoc.Lookup))
+    print("directive in doc: %s" % ("gopy:async" in asyncdoc.Fetch.__doc__))
+
+asyncio.run(main())
+print("OK")
@@ -0,0 +1,40 @@
+// Copyright 2019 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// package asyncnames tests generating asyncio wrappers for the functions
+// selected by the -async name regexp.
+package asyncnames
+
+import "time"
+
+// NumberStream returns the first n numbers -- async iterated in python.
+func NumberStream(n int) []int {
+	time.Sleep(10 * time.Millisecond)
+	res := make([]int, n)
+	for i := range res {
+		res[i] = i
+	}
+	return res
+}
+
+// SumWatch adds a and b -- awaited in python.
+func SumWatch(a, b int) int {
+	time.Sleep(10 * time.Millisecond)
+	return a + b
+}
+
+// Sum adds a and b -- stays synchronous.
+func Sum(a, b int) int {
+	return a + b
+}
+
+// Timer records ticks.
+type Timer struct {
+	Ticks int
+}
+
+// TickWatch increments the ticks -- awaited in python.
+func (t *Timer) TickWatch() {
+	t.Ticks++
+}
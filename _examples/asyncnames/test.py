@@ -0,0 +1,21 @@
+# Copyright 2019 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+import asyncio
+import asyncnames
+
+
+async def main():
+    print("sum: %d" % asyncnames.Sum(1, 2))
+    print("sum watch: %d" % await asyncnames.SumWatch(3, 4))
+    nums = [v async for v in asyncnames.NumberStream(3)]
+    print("number stream: %s" % nums)
+    t = asyncnames.Timer()
+    await t.TickWatch()
+    await t.TickWatch()
+    print("ticks: %d" % t.Ticks)
+    print("iscoroutinefunction(Sum): %s" % asyncio.iscoroutinefunction(asyncnames.Sum))
+
+asyncio.run(main())
+print("OK")
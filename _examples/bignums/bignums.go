@@ -0,0 +1,53 @@
+// Copyright 2019 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// package bignums tests the conversion of *big.Int and *big.Float to and
+// from python int and float, when generating with -bignum.
+package bignums
+
+import "math/big"
+
+// Factorial returns n!
+func Factorial(n int) *big.Int {
+	f := big.NewInt(1)
+	for i := 2; i <= n; i++ {
+		f.Mul(f, big.NewInt(int64(i)))
+	}
+	return f
+}
+
+// Add returns the sum of a and b
+func Add(a, b *big.Int) *big.Int {
+	return new(big.Int).Add(a, b)
+}
+
+// Digits returns the number of decimal digits of x, or -1 if it is nil
+func Digits(x *big.Int) int {
+	if x == nil {
+		return -1
+	}
+	return len(new(big.Int).Abs(x).String())
+}
+
+// Sqrt returns the square root of x
+func Sqrt(x *big.Float) *big.Float {
+	return new(big.Float).Sqrt(x)
+}
+
+// Account is a balance in cents
+type Account struct {
+	Owner   string
+	Balance *big.Int
+}
+
+// Deposit adds cents to the balance
+func (a *Account) Deposit(cents *big.Int) {
+	if a.Balance == nil {
+		a.Balance = new(big.Int)
+	}
+	a.Balance.Add(a.Balance, cents)
+}
+
+// Max is the largest 128-bit unsigned integer
+var Max = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 128), big.NewInt(1))
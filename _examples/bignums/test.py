@@ -0,0 +1,34 @@
+# Copyright 2019 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+## py2/py3 compat
+from __future__ import print_function
+
+import go, bignums
+
+f = bignums.Factorial(30)
+print("Factorial:", f, f == 265252859812191058636308480000000)
+print("Add:", bignums.Add(2**70, -1))
+print("Digits:", bignums.Digits(10**40), bignums.Digits(-99), bignums.Digits(None))
+print("Max:", bignums.Max() == 2**128 - 1)
+print("Sqrt:", bignums.Sqrt(2.25), bignums.Sqrt(16))
+
+try:
+    bignums.Add("1", 2)
+except TypeError as e:
+    print("caught:", e)
+try:
+    bignums.Sqrt(float("nan"))
+except ValueError as e:
+    print("caught:", e)
+
+a = bignums.Account(Owner="alice")
+print("Balance:", a.Balance)
+a.Deposit(10**20)
+a.Deposit(5)
+print("Balance:", a.Balance)
+a.Balance = 7
+print("Balance:", a.Balance)
+
+print("OK")
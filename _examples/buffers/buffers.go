@@ -0,0 +1,44 @@
+// Copyright 2019 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// package buffers tests the buffer protocol support of numeric slices,
+// which share their Go memory with python memoryviews
+package buffers
+
+// Floats returns a slice with the values 0..n-1
+func Floats(n int) []float64 {
+	s := make([]float64, n)
+	for i := range s {
+		s[i] = float64(i)
+	}
+	return s
+}
+
+// Sum returns the sum of the values of s
+func Sum(s []float64) float64 {
+	t := 0.0
+	for _, v := range s {
+		t += v
+	}
+	return t
+}
+
+// Data returns the bytes of s
+func Data(s string) []byte {
+	return []byte(s)
+}
+
+// String returns b as a string
+func String(b []byte) string {
+	return string(b)
+}
+
+// Ints returns a slice of n int32 values all set to v
+func Ints(n int, v int32) []int32 {
+	s := make([]int32, n)
+	for i := range s {
+		s[i] = v
+	}
+	return s
+}
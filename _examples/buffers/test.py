@@ -0,0 +1,42 @@
+# Copyright 2019 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+import sys
+import go, buffers
+
+s = buffers.Floats(4)
+v = s.buffer()
+print("view:", v.format, v.itemsize, len(v), v.readonly, v.tolist())
+v[0] = 10.5
+print("Sum after write:", buffers.Sum(s))
+s.release_buffer(v)
+
+if sys.version_info >= (3, 12):
+	m = memoryview(s)
+	m[1] = 20
+	m.release()
+else:
+	m = s.buffer()
+	m[1] = 20
+	s.release_buffer(m)
+print("Sum after memoryview write:", buffers.Sum(s))
+
+b = buffers.Data("hello")
+v = b.buffer()
+print("bytes:", bytes(v))
+v[0] = ord("j")
+b.release_buffer(v)
+print("String:", buffers.String(b))
+
+iv = buffers.Ints(3, 7)
+v = iv.buffer()
+print("ints:", v.format, v.tolist())
+iv.release_buffer(v)
+
+e = buffers.Floats(0)
+v = e.buffer()
+print("empty:", len(v), v.format)
+e.release_buffer(v)
+
+print("OK")
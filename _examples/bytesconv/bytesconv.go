@@ -0,0 +1,66 @@
+// Copyright 2019 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// package bytesconv tests the conversion of []byte to and from
+// python bytes, with the -bytes option
+package bytesconv
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+)
+
+// Upper returns the upper case version of b
+func Upper(b []byte) []byte {
+	return bytes.ToUpper(b)
+}
+
+// Len returns the length of b
+func Len(b []byte) int {
+	return len(b)
+}
+
+// Concat returns the concatenation of all the given byte slices
+func Concat(parts ...byte) []byte {
+	return parts
+}
+
+// Hash returns the sha256 hash of b
+func Hash(b []byte) []byte {
+	h := sha256.Sum256(b)
+	return h[:]
+}
+
+// Decode returns b, or an error if it is empty
+func Decode(b []byte) ([]byte, error) {
+	if len(b) == 0 {
+		return nil, errors.New("empty input")
+	}
+	return b, nil
+}
+
+// Chunks splits b into chunks of at most n bytes
+func Chunks(b []byte, n int) [][]byte {
+	var res [][]byte
+	for len(b) > n {
+		res = append(res, b[:n])
+		b = b[n:]
+	}
+	if len(b) > 0 {
+		res = append(res, b)
+	}
+	return res
+}
+
+// Message has a []byte field
+type Message struct {
+	Topic   string
+	Payload []byte
+}
+
+// Size returns the size of the payload
+func (m *Message) Size() int {
+	return len(m.Payload)
+}
@@ -0,0 +1,42 @@
+# Copyright 2019 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+## py2/py3 compat
+from __future__ import print_function
+
+import binascii
+import go, bytesconv
+
+up = bytesconv.Upper(b"hello")
+print("Upper(bytes):", type(up) is bytes, up.decode())
+print("Upper(bytearray):", bytesconv.Upper(bytearray(b"abc")).decode())
+print("Upper(memoryview):", bytesconv.Upper(memoryview(b"xyz")).decode())
+print("Len:", bytesconv.Len(b"\x00\x01\x02"), bytesconv.Len(b""))
+print("Concat:", bytesconv.Concat(104, 105).decode())
+print("Hash:", binascii.hexlify(bytesconv.Hash(b"abc")).decode()[:16])
+
+try:
+	bytesconv.Len(12)
+	print("no error for int arg")
+except TypeError:
+	print("caught TypeError for int arg")
+
+print("Decode:", bytesconv.Decode(b"data").decode())
+try:
+	bytesconv.Decode(b"")
+	print("no error for empty input")
+except Exception as e:
+	print("caught:", e)
+
+ch = bytesconv.Chunks(b"abcdefg", 3)
+print("Chunks:", len(ch), [c.decode() for c in ch])
+
+m = bytesconv.Message()
+m.Topic = "t"
+m.Payload = b"payload"
+print("Message.Payload:", m.Payload.decode(), m.Size())
+m.Payload = bytearray(b"xy")
+print("Message.Payload:", m.Payload.decode(), m.Size())
+
+print("OK")
@@ -0,0 +1,90 @@
+// Copyright 2019 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// package callbacks tests passing python callables as Go func
+// parameters of various signatures
+package callbacks
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Point is passed to and returned from callbacks
+type Point struct {
+	X, Y int
+}
+
+// Map returns the result of calling f on each of xs
+func Map(xs []int, f func(int) int) []int {
+	res := make([]int, len(xs))
+	for i, x := range xs {
+		res[i] = f(x)
+	}
+	return res
+}
+
+// Spawn returns f(n), calling f from a new goroutine
+func Spawn(f func(int) int, n int) int {
+	r := make(chan int)
+	go func() { r <- f(n) }()
+	return <-r
+}
+
+// Filter returns the names for which keep returns true
+func Filter(names []string, keep func(name string) bool) []string {
+	var res []string
+	for _, nm := range names {
+		if keep(nm) {
+			res = append(res, nm)
+		}
+	}
+	return res
+}
+
+// Combine returns the formatted sum of a and b, calling both add and format
+func Combine(a, b int, add func(a, b int) int, format func(v int) string) string {
+	return format(add(a, b))
+}
+
+// Each calls f for i = 0..n-1, stopping at the first error
+func Each(n int, f func(i int) error) error {
+	for i := 0; i < n; i++ {
+		if err := f(i); err != nil {
+			return fmt.Errorf("each %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// Parse returns the value parsed from s by parse, doubled
+func Parse(s string, parse func(s string) (float64, error)) (float64, error) {
+	v, err := parse(s)
+	if err != nil {
+		return 0, err
+	}
+	return 2 * v, nil
+}
+
+// Move returns the point that f returns for p
+func Move(p *Point, f func(p *Point) *Point) *Point {
+	return f(p)
+}
+
+// Notifier holds a handler that is called later
+type Notifier struct {
+	handler func(msg string)
+}
+
+// SetHandler sets the handler called by Notify
+func (n *Notifier) SetHandler(handler func(msg string)) {
+	n.handler = handler
+}
+
+// Notify calls the handler with msg in upper case
+func (n *Notifier) Notify(msg string) {
+	if n.handler != nil {
+		n.handler(strings.ToUpper(msg))
+	}
+}
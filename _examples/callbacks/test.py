@@ -0,0 +1,50 @@
+# Copyright 2019 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+## py2/py3 compat
+from __future__ import print_function
+
+import gc, sys
+import go, callbacks
+
+print("Map:", list(callbacks.Map(go.Slice_int([1, 2, 3]), lambda x: x * 10)))
+print("Filter:", list(callbacks.Filter(go.Slice_string(["ann", "bob", "al"]), lambda nm: nm.startswith("a"))))
+print("Combine:", callbacks.Combine(2, 3, lambda a, b: a + b, lambda v: "<%d>" % v))
+
+seen = []
+def visit(i):
+    seen.append(i)
+    if i == 2:
+        raise ValueError("stop at two")
+try:
+    callbacks.Each(5, visit)
+except Exception as e:
+    print("Each:", seen, e)
+
+# the exceptions of the callbacks without an error result are unraisable
+def unraisable(u):
+    print("unraisable:", type(u.exc_value).__name__)
+sys.unraisablehook = unraisable
+
+print("Map raising:", list(callbacks.Map(go.Slice_int([1, 2]), lambda x: x // 0)))
+print("Spawn:", callbacks.Spawn(lambda x: x + 1, 41))
+print("Spawn raising:", callbacks.Spawn(lambda x: x // 0, 41))
+
+print("Parse:", callbacks.Parse("1.25", float))
+try:
+    callbacks.Parse("nope", float)
+except Exception as e:
+    print("Parse error:", type(e).__name__)
+
+p = callbacks.Point(X=1, Y=2)
+q = callbacks.Move(p, lambda pt: callbacks.Point(X=pt.X + 1, Y=pt.Y * 2))
+print("Move:", q.X, q.Y)
+
+n = callbacks.Notifier()
+n.SetHandler(lambda msg: print("notified:", msg))
+gc.collect()
+n.Notify("hello")
+n.Notify("again")
+
+print("OK")
@@ -0,0 +1,56 @@
+// Copyright 2019 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// package cancel tests the cancellation of the contexts injected in the
+// Go calls from python, when the awaiting tasks are cancelled or the calls
+// are interrupted by Ctrl-C.
+package cancel
+
+import (
+	"context"
+	"time"
+)
+
+var stopped = make(chan string, 10)
+
+// Wait waits for ms milliseconds, unless ctx is cancelled before
+func Wait(ctx context.Context, ms int) (int, error) {
+	select {
+	case <-time.After(time.Duration(ms) * time.Millisecond):
+		return ms, nil
+	case <-ctx.Done():
+		stopped <- "Wait"
+		return 0, ctx.Err()
+	}
+}
+
+// WaitAsync is Wait -- awaited in python.
+//
+// gopy:async
+func WaitAsync(ctx context.Context, ms int) (int, error) {
+	return Wait(ctx, ms)
+}
+
+// Stopped returns the name of the next func stopped by the cancellation
+// of its context, waiting up to a second for it
+func Stopped() string {
+	select {
+	case s := <-stopped:
+		return s
+	case <-time.After(time.Second):
+		return ""
+	}
+}
+
+// Worker works until it is cancelled.
+type Worker struct {
+	Name string
+}
+
+// Run runs the worker until ctx is cancelled -- awaited in python.
+// gopy:async
+func (w *Worker) Run(ctx context.Context) {
+	<-ctx.Done()
+	stopped <- w.Name
+}
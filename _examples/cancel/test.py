@@ -0,0 +1,41 @@
+# Copyright 2019 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+import _thread
+import asyncio
+import threading
+
+import cancel
+
+print("Wait:", cancel.Wait(10))
+
+threading.Timer(0.05, _thread.interrupt_main).start()
+try:
+    cancel.Wait(5000)
+except KeyboardInterrupt:
+    print("interrupted")
+print("stopped:", cancel.Stopped())
+
+
+async def main():
+    print("WaitAsync:", await cancel.WaitAsync(10))
+
+    task = asyncio.ensure_future(cancel.WaitAsync(5000))
+    await asyncio.sleep(0.05)
+    task.cancel()
+    try:
+        await task
+    except asyncio.CancelledError:
+        print("cancelled")
+    print("stopped:", cancel.Stopped())
+
+    try:
+        await asyncio.wait_for(cancel.Worker(Name="worker").Run(), 0.05)
+    except asyncio.TimeoutError:
+        print("timed out")
+    print("stopped:", cancel.Stopped())
+
+asyncio.run(main())
+
+print("OK")
@@ -0,0 +1,40 @@
+// Copyright 2019 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// package capibackend tests the bindings generated with -backend=capi,
+// which writes the extension module directly with the CPython C-API,
+// without pybindgen or any other python build dependency.
+package capibackend
+
+import (
+	"errors"
+)
+
+// Add8 returns a+b, wrapping around on overflow
+func Add8(a, b int8) int8 {
+	return a + b
+}
+
+// IsEven returns whether n is even
+func IsEven(n int) bool {
+	return n%2 == 0
+}
+
+// Greet returns a greeting of name
+func Greet(name string) string {
+	return "hello " + name
+}
+
+// Scale returns x scaled by f, or an error for a negative factor
+func Scale(x, f float64) (float64, error) {
+	if f < 0 {
+		return 0, errors.New("negative factor")
+	}
+	return x * f, nil
+}
+
+// Apply returns f(n), calling back into python
+func Apply(f func(n int) int, n int) int {
+	return f(n)
+}
@@ -0,0 +1,25 @@
+# Copyright 2019 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+import inspect, os
+import go, capibackend, _capibackend
+
+print("build.py:", os.path.exists("build.py"))
+print("signature:", inspect.signature(_capibackend.capibackend_Add8))
+
+print("Add8:", capibackend.Add8(100, 27))
+try:
+	capibackend.Add8(200, 1)
+except OverflowError:
+	print("OverflowError")
+print("IsEven:", capibackend.IsEven(4), capibackend.IsEven(7))
+print("Greet:", capibackend.Greet("capi"))
+print("Scale:", capibackend.Scale(1.5, 2))
+try:
+	capibackend.Scale(1, -1)
+except go.GoError as e:
+	print("GoError:", e)
+print("Apply:", capibackend.Apply(lambda n: n * 3, 14))
+
+print("OK")
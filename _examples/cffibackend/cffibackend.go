@@ -0,0 +1,52 @@
+// Copyright 2019 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// package cffibackend tests the bindings generated with -backend=cffi,
+// which builds the extension module with cffi instead of pybindgen.
+package cffibackend
+
+import (
+	"errors"
+	"strings"
+)
+
+// Pair is a struct, passed by handle
+type Pair struct {
+	Key   string
+	Value int
+}
+
+// Swap returns the pair with the key repeated value times, and the value
+// set to the length of the key
+func (p *Pair) Swap() *Pair {
+	return &Pair{Key: strings.Repeat(p.Key, p.Value), Value: len(p.Key)}
+}
+
+// Greet returns a greeting of name
+func Greet(name string) string {
+	return "hello " + name
+}
+
+// Scale returns x scaled by f, or an error for a negative factor
+func Scale(x, f float64) (float64, error) {
+	if f < 0 {
+		return 0, errors.New("negative factor")
+	}
+	return x * f, nil
+}
+
+// Negate returns !b
+func Negate(b bool) bool {
+	return !b
+}
+
+// Apply returns f(n), calling back into python
+func Apply(f func(n int) int, n int) int {
+	return f(n)
+}
+
+// Join returns the strings of s joined with sep
+func Join(s []string, sep string) string {
+	return strings.Join(s, sep)
+}
@@ -0,0 +1,24 @@
+# Copyright 2019 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+import go, cffibackend, _cffibackend
+
+print("shim:", _cffibackend.__file__.endswith(".py"))
+print("Greet:", cffibackend.Greet("pypy"))
+print("Scale:", cffibackend.Scale(1.5, 2))
+try:
+	cffibackend.Scale(1, -1)
+except go.GoError as e:
+	print("GoError:", e)
+print("Negate:", cffibackend.Negate(True))
+print("Apply:", cffibackend.Apply(lambda n: n * 3, 14))
+print("Join:", cffibackend.Join(go.Slice_string(["a", "b", "c"]), "-"))
+
+p = cffibackend.Pair(Key="ab", Value=3)
+q = p.Swap()
+print("Swap:", q.Key, q.Value)
+p.Value = 1
+print("Value:", p.Value)
+
+print("OK")
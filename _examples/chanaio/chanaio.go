@@ -0,0 +1,38 @@
+// Copyright 2019 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// package chanaio tests async iterating over Go channels from asyncio
+// event loops, with the values pumped into asyncio queues.
+package chanaio
+
+import "time"
+
+// Event is sent over channels
+type Event struct {
+	ID int
+}
+
+// Count returns a channel on which the goroutine it starts sends the n
+// first numbers, one every ms milliseconds, before closing it
+func Count(n, ms int) <-chan int {
+	c := make(chan int)
+	go func() {
+		for i := 0; i < n; i++ {
+			time.Sleep(time.Duration(ms) * time.Millisecond)
+			c <- i
+		}
+		close(c)
+	}()
+	return c
+}
+
+// Events returns a closed buffered channel holding n events
+func Events(n int) chan *Event {
+	c := make(chan *Event, n)
+	for i := 0; i < n; i++ {
+		c <- &Event{ID: i}
+	}
+	close(c)
+	return c
+}
@@ -0,0 +1,38 @@
+# Copyright 2019 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+import asyncio
+
+import chanaio, go
+
+ticks = 0
+
+async def ticker(done):
+    global ticks
+    while not done.is_set():
+        ticks += 1
+        await asyncio.sleep(0.005)
+
+async def main():
+    done = asyncio.Event()
+    t = asyncio.ensure_future(ticker(done))
+    vals = [v async for v in chanaio.Count(5, 20).aiter()]
+    done.set()
+    await t
+    print("Count:", vals)
+    print("event loop ran:", ticks > 5)
+
+    print("Events:", [e.ID async for e in chanaio.Events(3).aiter(maxsize=1)])
+
+    q = go.chan_queue(chanaio.Count(2, 1))
+    vals = []
+    while True:
+        v = await q.get()
+        if v is go.nil:
+            break
+        vals.append(v)
+    print("chan_queue:", vals)
+
+asyncio.run(main())
+print("OK")
@@ -0,0 +1,12 @@
+// Copyright 2019 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// package checksum tests the import time check that the python wrapper
+// and the extension module were generated together.
+package checksum
+
+// Hello returns a greeting for name.
+func Hello(name string) string {
+	return "hello " + name
+}
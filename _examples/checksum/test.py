@@ -0,0 +1,32 @@
+# Copyright 2019 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+import importlib, sys, types
+import _checksum, checksum
+
+print("Hello: %s" % checksum.Hello("gopy"))
+
+# a stale extension module, from another gopy run, must fail the import
+stale = types.ModuleType("_checksum")
+stale.GoPyChecksum = lambda: "0000000000000000"
+sys.modules["_checksum"] = stale
+try:
+    importlib.reload(checksum)
+    print("stale extension imported")
+except ImportError as e:
+    print("caught ImportError: %s" % ("out of sync" in str(e)))
+
+# as must an extension module without a checksum
+del stale.GoPyChecksum
+try:
+    importlib.reload(checksum)
+    print("unversioned extension imported")
+except ImportError as e:
+    print("caught ImportError: %s" % ("out of sync" in str(e)))
+
+sys.modules["_checksum"] = _checksum
+checksum = importlib.reload(checksum)
+print("Hello: %s" % checksum.Hello("again"))
+
+print("OK")
@@ -0,0 +1,73 @@
+// Copyright 2019 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// package closers tests the use of the types with a Close() error method
+// in python with statements.
+package closers
+
+import (
+	"errors"
+	"io"
+)
+
+// Opened is the number of files that are open
+var Opened int
+
+// File is a resource that must be closed
+type File struct {
+	Name   string
+	closed bool
+}
+
+// Open opens the named file
+func Open(name string) *File {
+	Opened++
+	return &File{Name: name}
+}
+
+// Closed returns whether the file is closed
+func (f *File) Closed() bool {
+	return f.closed
+}
+
+// Close closes the file, which is an error if it is already closed
+func (f *File) Close() error {
+	if f.closed {
+		return errors.New("already closed")
+	}
+	f.closed = true
+	Opened--
+	return nil
+}
+
+// Resource is an io.Closer
+type Resource interface {
+	io.Closer
+}
+
+// OpenResource opens a file as a Resource
+func OpenResource(name string) Resource {
+	return Open(name)
+}
+
+// Files is a set of files that are closed together
+type Files []*File
+
+// OpenFiles opens the named files
+func OpenFiles(names []string) Files {
+	fs := make(Files, len(names))
+	for i, n := range names {
+		fs[i] = Open(n)
+	}
+	return fs
+}
+
+// Close closes all of the files
+func (fs Files) Close() error {
+	var errs []error
+	for _, f := range fs {
+		errs = append(errs, f.Close())
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,31 @@
+# Copyright 2019 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+import closers
+
+with closers.Open("a") as f:
+    print("in with:", f.Name, f.Closed(), closers.Opened())
+print("after with:", f.Closed(), closers.Opened())
+
+try:
+    with closers.Open("b") as f:
+        raise KeyError("b")
+except KeyError as err:
+    print("raised:", repr(err), f.Closed(), closers.Opened())
+
+try:
+    with closers.Open("c") as f:
+        f.Close()
+except Exception as err:
+    print("close error:", err, closers.Opened())
+
+with closers.OpenResource("d") as r:
+    print("Resource:", closers.Opened())
+print("after Resource:", closers.Opened())
+
+with closers.OpenFiles(["e", "f"]) as fs:
+    print("Files:", len(fs), closers.Opened())
+print("after Files:", fs[0].Closed(), fs[1].Closed(), closers.Opened())
+
+print("OK")
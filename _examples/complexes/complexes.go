@@ -0,0 +1,68 @@
+// Copyright 2019 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// package complexes tests slices and maps of complex numbers, whose
+// elements are converted to and from python complex.
+package complexes
+
+import (
+	"math"
+	"math/cmplx"
+)
+
+// Roots returns the n roots of unity
+func Roots(n int) []complex128 {
+	rs := make([]complex128, n)
+	for k := range rs {
+		rs[k] = cmplx.Rect(1, 2*math.Pi*float64(k)/float64(n))
+	}
+	return rs
+}
+
+// Sum returns the sum of the numbers
+func Sum(cs []complex128) complex128 {
+	var s complex128
+	for _, c := range cs {
+		s += c
+	}
+	return s
+}
+
+// Product returns the product of the numbers
+func Product(cs ...complex64) complex64 {
+	p := complex64(1)
+	for _, c := range cs {
+		p *= c
+	}
+	return p
+}
+
+// Filter has named poles and zeros
+type Filter struct {
+	Poles map[string]complex128
+	Zeros []complex64
+}
+
+// NewFilter returns a filter with no poles or zeros
+func NewFilter() *Filter {
+	return &Filter{Poles: map[string]complex128{}}
+}
+
+// Gain returns the product of the magnitudes of the poles
+func (f *Filter) Gain() float64 {
+	g := 1.0
+	for _, p := range f.Poles {
+		g *= cmplx.Abs(p)
+	}
+	return g
+}
+
+// Transform returns the numbers transformed by fn
+func Transform(cs []complex128, fn func(c complex128) complex128) []complex128 {
+	out := make([]complex128, len(cs))
+	for i, c := range cs {
+		out[i] = fn(c)
+	}
+	return out
+}
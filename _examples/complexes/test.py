@@ -0,0 +1,39 @@
+# Copyright 2019 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+## py2/py3 compat
+from __future__ import print_function
+
+import go, complexes
+
+rs = complexes.Roots(4)
+print("Roots:", type(rs).__name__, [complex(round(r.real), round(r.imag)) for r in rs])
+print("Sum:", complexes.Sum([1, 2.5, 1j, 3+4j]))
+print("Product:", complexes.Product(1j, 1j, 2))
+try:
+    complexes.Sum([1, "x"])
+except TypeError as e:
+    print("caught:", e)
+
+s = go.Slice_complex128([1, 2j])
+s.append(3-1j)
+s[0] = 5
+print("Slice:", list(s), s[-1])
+try:
+    s[1] = "x"
+except TypeError:
+    print("caught TypeError")
+print("Slice:", list(s))
+
+f = complexes.NewFilter()
+f.Poles["a"] = 3+4j
+f.Poles["b"] = 2
+print("Poles:", sorted(f.Poles.items()), f.Poles["a"])
+print("Gain:", f.Gain())
+f.Zeros = go.Slice_complex64([1+1j])
+print("Zeros:", list(f.Zeros))
+
+print("Transform:", list(complexes.Transform([1, 1j], lambda c: c * 2j)))
+
+print("OK")
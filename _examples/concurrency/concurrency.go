@@ -0,0 +1,86 @@
+// Copyright 2019 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// package concurrency is a stress test of the bindings called from many
+// python threads at once, with the GIL released during the Go calls: string
+// and slice conversions, handles, errors, interface{} results and callbacks.
+package concurrency
+
+import (
+	"errors"
+	"strings"
+	"sync"
+)
+
+// ErrOdd is returned for odd numbers
+var ErrOdd = errors.New("odd number")
+
+// Point is a 2D point
+type Point struct {
+	X, Y int
+}
+
+// NewPoint returns a new point
+func NewPoint(x, y int) *Point {
+	return &Point{X: x, Y: y}
+}
+
+// Add returns the sum of p and q, as a new point
+func (p *Point) Add(q *Point) *Point {
+	return &Point{X: p.X + q.X, Y: p.Y + q.Y}
+}
+
+// Repeat returns s repeated n times
+func Repeat(s string, n int) string {
+	return strings.Repeat(s, n)
+}
+
+// Sum returns the sum of xs
+func Sum(xs []int) int {
+	s := 0
+	for _, x := range xs {
+		s += x
+	}
+	return s
+}
+
+// Half returns n / 2, or ErrOdd if n is odd
+func Half(n int) (int, error) {
+	if n%2 != 0 {
+		return 0, ErrOdd
+	}
+	return n / 2, nil
+}
+
+// Boxed returns n as a point, as interface{}
+func Boxed(n int) interface{} {
+	return &Point{X: n, Y: -n}
+}
+
+// Apply returns f(n), calling f from a new goroutine
+func Apply(f func(int) int, n int) int {
+	r := make(chan int)
+	go func() { r <- f(n) }()
+	return <-r
+}
+
+// Counter is a counter shared by the python threads
+type Counter struct {
+	mu sync.Mutex
+	n  int
+}
+
+// Inc increments the counter
+func (c *Counter) Inc() {
+	c.mu.Lock()
+	c.n++
+	c.mu.Unlock()
+}
+
+// Value returns the value of the counter
+func (c *Counter) Value() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.n
+}
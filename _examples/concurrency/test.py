@@ -0,0 +1,56 @@
+# Copyright 2019 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+import gc
+import threading
+
+import go, concurrency, _concurrency
+
+THREADS = 8
+ITERS = 500
+
+counter = concurrency.Counter()
+errors = []
+
+def work(t):
+	try:
+		for i in range(ITERS):
+			n = t * ITERS + i
+			s = concurrency.Repeat(str(n), 3)
+			if s != str(n) * 3:
+				errors.append("Repeat(%d): %r" % (n, s))
+			xs = go.Slice_int([n, i, t])
+			if concurrency.Sum(xs) != n + i + t:
+				errors.append("Sum(%d)" % n)
+			p = concurrency.NewPoint(n, i).Add(concurrency.NewPoint(1, 1))
+			if (p.X, p.Y) != (n + 1, i + 1):
+				errors.append("Add(%d): %d, %d" % (n, p.X, p.Y))
+			try:
+				h = concurrency.Half(n)
+				if n % 2 != 0 or h != n // 2:
+					errors.append("Half(%d): %d" % (n, h))
+			except concurrency.ErrOdd:
+				if n % 2 == 0:
+					errors.append("Half(%d): ErrOdd" % n)
+			b = concurrency.Boxed(n)
+			if not isinstance(b, concurrency.Point) or (b.X, b.Y) != (n, -n):
+				errors.append("Boxed(%d): %r" % (n, b))
+			if concurrency.Apply(lambda x: x * 2, n) != 2 * n:
+				errors.append("Apply(%d)" % n)
+			counter.Inc()
+	except Exception as e:
+		errors.append("thread %d: %r" % (t, e))
+
+base = _concurrency.NumHandles()
+threads = [threading.Thread(target=work, args=(t,)) for t in range(THREADS)]
+for t in threads:
+	t.start()
+for t in threads:
+	t.join()
+
+print("errors:", errors[:5])
+print("counter:", counter.Value() == THREADS * ITERS)
+gc.collect()
+print("handles released:", _concurrency.NumHandles() <= base)
+print("OK")
@@ -0,0 +1,45 @@
+// Copyright 2019 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// package copies tests copy.copy and copy.deepcopy of the wrapped structs,
+// slices and maps.
+package copies
+
+// Inner is pointed to by an Outer
+type Inner struct {
+	Value int
+}
+
+// Outer holds a pointer, a slice and a map, which are all copied by a deep
+// copy
+type Outer struct {
+	Name   string
+	Inner  *Inner
+	Values []int
+	Counts map[string]int
+	Self   *Outer
+}
+
+// NewOuter returns a new Outer pointing to itself
+func NewOuter(name string) *Outer {
+	o := &Outer{
+		Name:   name,
+		Inner:  &Inner{Value: 1},
+		Values: []int{1, 2, 3},
+		Counts: map[string]int{"a": 1},
+	}
+	o.Self = o
+	return o
+}
+
+// IsSelf returns whether o points to itself
+func (o *Outer) IsSelf() bool {
+	return o.Self == o
+}
+
+// Names is a slice of strings
+type Names []string
+
+// Ages is a map of ages by name
+type Ages map[string]int
@@ -0,0 +1,44 @@
+# Copyright 2019 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+import copy
+
+import copies
+
+o = copies.NewOuter("o")
+o.tag = "t"
+
+c = copy.copy(o)
+print("copy: same handle:", c.handle == o.handle, "tag:", c.tag)
+c.Name = "c"
+print("copy: shared Name:", o.Name)
+o.Name = "o"
+
+d = copy.deepcopy(o)
+print("deepcopy: same handle:", d.handle == o.handle, "tag:", d.tag)
+d.Name = "d"
+d.Inner.Value = 2
+d.Values[0] = 10
+d.Counts["a"] = 10
+print("deepcopy: o:", o.Name, o.Inner.Value, o.Values[0], o.Counts["a"])
+print("deepcopy: d:", d.Name, d.Inner.Value, d.Values[0], d.Counts["a"])
+print("deepcopy: IsSelf:", d.IsSelf(), d.Self.Name)
+
+n = copies.Names(["ada", "bob"])
+nd = copy.deepcopy(n)
+nd[0] = "cyd"
+nc = copy.copy(n)
+nc[1] = "dan"
+print("Names:", list(n), list(nd))
+
+a = copies.Ages({"ada": 36})
+ad = copy.deepcopy(a)
+ad["ada"] = 37
+print("Ages:", a["ada"], ad["ada"])
+
+l = [n, n]
+ld = copy.deepcopy(l)
+print("memo:", ld[0] is ld[1], ld[0].handle != n.handle)
+
+print("OK")
@@ -0,0 +1,53 @@
+// Copyright 2019 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// package ctypesbackend tests the bindings generated with -backend=ctypes,
+// which only builds the c-shared Go library, and calls it with ctypes from
+// a generated python module, so that no C code of gopy is compiled.
+package ctypesbackend
+
+import (
+	"errors"
+	"strings"
+)
+
+// Point is a point in the plane
+type Point struct {
+	X, Y int
+}
+
+// Norm1 returns the manhattan norm of p
+func (p *Point) Norm1() int {
+	return abs(p.X) + abs(p.Y)
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+// Add8 returns a+b, wrapping around on overflow
+func Add8(a, b int8) int8 {
+	return a + b
+}
+
+// Shout returns s in upper case
+func Shout(s string) string {
+	return strings.ToUpper(s)
+}
+
+// Scale returns x scaled by f, or an error for a negative factor
+func Scale(x, f float64) (float64, error) {
+	if f < 0 {
+		return 0, errors.New("negative factor")
+	}
+	return x * f, nil
+}
+
+// Apply returns f(n), calling back into python
+func Apply(f func(n int) int, n int) int {
+	return f(n)
+}
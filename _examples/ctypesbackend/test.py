@@ -0,0 +1,26 @@
+# Copyright 2019 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+import os
+import go, ctypesbackend, _ctypesbackend
+
+print("build.py:", os.path.exists("build.py"))
+print("python module:", _ctypesbackend.__file__.endswith(".py"))
+
+p = ctypesbackend.Point(X=3, Y=-4)
+print("Norm1:", p.Norm1())
+print("Add8:", ctypesbackend.Add8(100, 27))
+try:
+	ctypesbackend.Add8(200, 1)
+except OverflowError:
+	print("OverflowError")
+print("Shout:", ctypesbackend.Shout("ctypes"))
+print("Scale:", ctypesbackend.Scale(1.5, 2))
+try:
+	ctypesbackend.Scale(1, -1)
+except go.GoError as e:
+	print("GoError:", e)
+print("Apply:", ctypesbackend.Apply(lambda n: n * 3, 14))
+
+print("OK")
@@ -0,0 +1,48 @@
+// Copyright 2019 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// package cythonbackend tests the bindings generated with -backend=cython,
+// which builds the extension module with cython instead of pybindgen, and
+// copies the numeric slices in bulk with typed memoryviews.
+package cythonbackend
+
+import (
+	"errors"
+)
+
+// Sum returns the sum of xs
+func Sum(xs []float64) float64 {
+	s := 0.0
+	for _, x := range xs {
+		s += x
+	}
+	return s
+}
+
+// Ramp returns the ints from 0 to n-1
+func Ramp(n int) []int {
+	r := make([]int, n)
+	for i := range r {
+		r[i] = i
+	}
+	return r
+}
+
+// Greet returns a greeting of name
+func Greet(name string) string {
+	return "hello " + name
+}
+
+// Scale returns x scaled by f, or an error for a negative factor
+func Scale(x, f float64) (float64, error) {
+	if f < 0 {
+		return 0, errors.New("negative factor")
+	}
+	return x * f, nil
+}
+
+// Apply returns f(n), calling back into python
+func Apply(f func(n int) int, n int) int {
+	return f(n)
+}
@@ -0,0 +1,31 @@
+# Copyright 2019 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+import array
+import go, cythonbackend
+
+xs = go.Slice_float64([0.5, 1.5, 2.0])
+print("Sum:", cythonbackend.Sum(xs))
+xs.extend(array.array('d', [1.0, 2.0]))
+xs += [3.0]
+print("extend:", xs.tolist())
+
+r = cythonbackend.Ramp(5)
+print("Ramp:", r.tolist(), len(r))
+r.extend(range(5, 8))
+print("extend:", r.tolist())
+try:
+	go.Slice_int(["a"])
+except TypeError:
+	print("TypeError")
+
+print("Greet:", cythonbackend.Greet("cython"))
+print("Scale:", cythonbackend.Scale(1.5, 2))
+try:
+	cythonbackend.Scale(1, -1)
+except go.GoError as e:
+	print("GoError:", e)
+print("Apply:", cythonbackend.Apply(lambda n: n * 3, 14))
+
+print("OK")
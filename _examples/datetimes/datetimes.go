@@ -0,0 +1,76 @@
+// Copyright 2019 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// package datetimes tests the conversion of time.Time and time.Duration
+// to and from python datetime and timedelta, with the -datetime option
+package datetimes
+
+import (
+	"errors"
+	"time"
+)
+
+// DefaultTimeout is a duration constant
+const DefaultTimeout = 1500 * time.Millisecond
+
+// Epoch returns the unix epoch
+func Epoch() time.Time {
+	return time.Unix(0, 0)
+}
+
+// AddDays returns t plus the given number of days
+func AddDays(t time.Time, days int) time.Time {
+	return t.AddDate(0, 0, days)
+}
+
+// Unix returns the unix time of t in seconds
+func Unix(t time.Time) int64 {
+	return t.Unix()
+}
+
+// Parse parses an RFC 3339 time
+func Parse(s string) (time.Time, error) {
+	return time.Parse(time.RFC3339Nano, s)
+}
+
+// Latest returns the latest of the given times
+func Latest(ts []time.Time) (time.Time, error) {
+	if len(ts) == 0 {
+		return time.Time{}, errors.New("no times")
+	}
+	l := ts[0]
+	for _, t := range ts[1:] {
+		if t.After(l) {
+			l = t
+		}
+	}
+	return l, nil
+}
+
+// Elapsed returns the duration from from to to
+func Elapsed(from, to time.Time) time.Duration {
+	return to.Sub(from)
+}
+
+// Format returns the Go string format of d
+func Format(d time.Duration) string {
+	return d.String()
+}
+
+// Event has time and duration fields
+type Event struct {
+	Name string
+	At   time.Time
+	For  time.Duration
+}
+
+// End returns the end time of the event
+func (e *Event) End() time.Time {
+	return e.At.Add(e.For)
+}
+
+// Before returns true if the event is before t
+func (e *Event) Before(t time.Time) bool {
+	return e.At.Before(t)
+}
@@ -0,0 +1,60 @@
+# Copyright 2019 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+from datetime import datetime, timedelta, timezone
+import go, datetimes
+
+ep = datetimes.Epoch()
+print("Epoch:", ep.isoformat(), ep.tzinfo)
+
+t = datetime(2024, 2, 28, 12, 30, 15, 123456, tzinfo=timezone.utc)
+print("AddDays:", datetimes.AddDays(t, 2).isoformat())
+print("Unix:", datetimes.Unix(t))
+
+pst = timezone(timedelta(hours=-8))
+tp = datetime(2024, 1, 1, 0, 0, 0, tzinfo=pst)
+print("Unix with offset:", datetimes.Unix(tp), datetimes.AddDays(tp, 0).isoformat())
+
+naive = datetime(2024, 1, 1, 0, 0, 0)
+print("naive is local:", datetimes.Unix(naive) == int(naive.timestamp()))
+
+print("Parse:", datetimes.Parse("2023-06-01T10:00:00.5+02:00").isoformat())
+try:
+	datetimes.Parse("junk")
+	print("no error for junk")
+except Exception:
+	print("caught Parse error")
+
+try:
+	datetimes.Unix("2024-01-01")
+	print("no error for str")
+except TypeError as e:
+	print("caught:", e)
+
+lst = datetimes.Slice_time_Time([t, tp, ep])
+print("Latest:", datetimes.Latest(lst).isoformat())
+
+ev = datetimes.Event(Name="launch", At=t)
+print("Event.At:", ev.At.isoformat())
+print("Before:", ev.Before(tp), ev.Before(datetime(2025, 1, 1, tzinfo=timezone.utc)))
+ev.At = ep
+print("Event.At:", ev.At.isoformat())
+
+print("DefaultTimeout:", repr(datetimes.DefaultTimeout))
+print("Elapsed:", repr(datetimes.Elapsed(tp, t)))
+print("Elapsed negative:", datetimes.Elapsed(t, tp) < timedelta(0))
+print("Format timedelta:", datetimes.Format(timedelta(hours=1, minutes=2, microseconds=3)))
+print("Format seconds:", datetimes.Format(90), datetimes.Format(0.25))
+try:
+	datetimes.Format("1s")
+	print("no error for str")
+except TypeError as e:
+	print("caught:", e)
+
+ev.For = timedelta(days=2)
+print("Event.For:", repr(ev.For), ev.End().isoformat())
+ev.For = 30
+print("Event.For:", repr(ev.For))
+
+print("OK")
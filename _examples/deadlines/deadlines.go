@@ -0,0 +1,29 @@
+// Copyright 2019 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// package deadlines tests the go.Context of go.with_deadline and
+// go.with_timeout, passed as the ctx keyword of the functions with a
+// context, whose injected contexts are derived from it.
+package deadlines
+
+import (
+	"context"
+	"time"
+)
+
+// Wait waits for ms milliseconds, unless ctx is cancelled before
+func Wait(ctx context.Context, ms int) (int, error) {
+	select {
+	case <-time.After(time.Duration(ms) * time.Millisecond):
+		return ms, nil
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	}
+}
+
+// HasDeadline returns true if ctx has a deadline
+func HasDeadline(ctx context.Context) bool {
+	_, ok := ctx.Deadline()
+	return ok
+}
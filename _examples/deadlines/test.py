@@ -0,0 +1,47 @@
+# Copyright 2019 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+import datetime
+import time
+
+import go, deadlines
+
+print("HasDeadline:", deadlines.HasDeadline())
+print("HasDeadline with_timeout:", deadlines.HasDeadline(ctx=go.with_timeout(10)))
+
+# a context shared by several calls, whose deadline applies to all of them
+ctx = go.with_timeout(0.3)
+print("Wait:", deadlines.Wait(10, ctx=ctx))
+start = time.time()
+try:
+	deadlines.Wait(5000, ctx=ctx)
+except TimeoutError as e:
+	print("Wait timed out:", e, time.time() - start < 1)
+
+dt = datetime.datetime.now() + datetime.timedelta(seconds=0.1)
+try:
+	deadlines.Wait(5000, ctx=go.with_deadline(dt))
+except TimeoutError as e:
+	print("Wait deadline:", e)
+
+# the timeout keyword still applies within the context
+with go.with_timeout(10) as ctx:
+	try:
+		deadlines.Wait(5000, timeout=0.05, ctx=ctx)
+	except TimeoutError as e:
+		print("Wait timeout:", e)
+	print("Wait after timeout:", deadlines.Wait(10, ctx=ctx))
+
+# cancelled at the end of the with statement
+try:
+	deadlines.Wait(10, ctx=ctx)
+except go.GoError as e:
+	print("Wait cancelled:", e)
+
+try:
+	deadlines.Wait(10, ctx=1)
+except TypeError as e:
+	print("TypeError:", e)
+
+print("OK")
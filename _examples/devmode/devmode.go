@@ -0,0 +1,23 @@
+// Copyright 2019 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// package devmode tests the -dev hot-reload loader.
+package devmode
+
+// Loads counts the number of times the library has been loaded.
+var Loads int
+
+func init() {
+	Loads++
+}
+
+// Thing is a simple struct.
+type Thing struct {
+	Name string
+}
+
+// NewThing returns a new Thing with the given name.
+func NewThing(nm string) *Thing {
+	return &Thing{Name: nm}
+}
@@ -0,0 +1,19 @@
+# Copyright 2019 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+import devmode, go
+
+t = devmode.NewThing("before")
+print("t.Name = %s" % t.Name)
+
+# each reload gets a fresh copy of the library, with its own Go state
+print("generation = %d" % go.reload())
+devmode.Set_Loads(5)
+print("generation = %d" % go.reload())
+print("devmode.Loads = %d" % devmode.Loads())
+
+t = devmode.NewThing("after")
+print("t.Name = %s" % t.Name)
+
+print("OK")
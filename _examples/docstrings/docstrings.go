@@ -0,0 +1,40 @@
+// Copyright 2019 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// package docstrings tests the Args, Returns and Raises sections of the
+// python doc strings of functions and methods.
+package docstrings
+
+import (
+	"errors"
+	"strings"
+)
+
+// Join joins the words with the separator
+func Join(sep string, words ...string) string {
+	return strings.Join(words, sep)
+}
+
+// Parse parses a count, failing for negative ones
+func Parse(n int) (count uint, err error) {
+	if n < 0 {
+		return 0, errors.New("negative count")
+	}
+	return uint(n), nil
+}
+
+// Box holds a value
+type Box struct {
+	Value interface{}
+}
+
+// Get returns the value of the box
+func (b *Box) Get() interface{} {
+	return b.Value
+}
+
+// Fill fills the box with copies of the given box
+func (b *Box) Fill(o *Box, _ int) {
+	b.Value = o.Value
+}
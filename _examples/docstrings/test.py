@@ -0,0 +1,13 @@
+# Copyright 2019 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+import inspect
+
+import docstrings
+
+for fn in [docstrings.Join, docstrings.Parse, docstrings.Box.Get, docstrings.Box.Fill]:
+    print(inspect.getdoc(fn))
+    print("---")
+
+print("OK")
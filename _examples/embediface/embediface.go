@@ -0,0 +1,100 @@
+// Copyright 2019 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// package embediface tests promoting the methods of embedded interfaces
+// to the python classes of the structs embedding them.
+package embediface
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Namer has a name
+type Namer interface {
+	Name() string
+}
+
+// Titler has a name too, which conflicts with that of Namer
+type Titler interface {
+	Name() string
+}
+
+type name string
+
+func (n name) Name() string   { return string(n) }
+func (n name) String() string { return "<" + string(n) + ">" }
+
+// Base is a struct embedded first, which is the python base class
+type Base struct {
+	ID int
+}
+
+// GetID returns the id
+func (b *Base) GetID() int {
+	return b.ID
+}
+
+// Person embeds Namer first, which is thus its python base class
+type Person struct {
+	Namer
+	Age int
+}
+
+// NewPerson returns a new person with the given name
+func NewPerson(n string) *Person {
+	return &Person{Namer: name(n), Age: 42}
+}
+
+// Emp embeds Namer after Base, so Name is promoted to it
+type Emp struct {
+	Base
+	Namer
+}
+
+// NewEmp returns a new emp with the given id and name
+func NewEmp(id int, n string) *Emp {
+	return &Emp{Base{id}, name(n)}
+}
+
+// Doc embeds io.Reader, which is not bound, so Read is promoted to it
+type Doc struct {
+	io.Reader
+	Title string
+}
+
+// NewDoc returns a new doc reading the given text
+func NewDoc(title, text string) *Doc {
+	return &Doc{Reader: strings.NewReader(text), Title: title}
+}
+
+// Label embeds fmt.Stringer, so it is a python str too
+type Label struct {
+	fmt.Stringer
+}
+
+// NewLabel returns a new label with the given name
+func NewLabel(n string) *Label {
+	return &Label{name(n)}
+}
+
+// Failure embeds error, so it is raised with the message of the error
+type Failure struct {
+	error
+	Code int
+}
+
+// Fail returns a failure with the given message
+func Fail(msg string) *Failure {
+	return &Failure{errors.New(msg), 1}
+}
+
+// Both embeds two interfaces with a Name method, which is ambiguous in Go
+// and thus is not promoted to it
+type Both struct {
+	Namer
+	Titler
+}
@@ -0,0 +1,31 @@
+# Copyright 2019 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+## py2/py3 compat
+from __future__ import print_function
+
+import embediface
+
+p = embediface.NewPerson("ann")
+print("p.Name():", p.Name())
+print("p is Namer:", isinstance(p, embediface.Namer))
+
+e = embediface.NewEmp(7, "bob")
+print("e.GetID(), e.Name():", e.GetID(), e.Name())
+
+d = embediface.NewDoc("hi", "hello")
+buf = embediface.go.Slice_byte([0] * 8)
+print("d.Read(buf):", d.Read(buf))
+print("buf:", bytes(bytearray(list(buf)[:5])).decode())
+
+l = embediface.NewLabel("lbl")
+print("l.String():", l.String())
+print("str(l):", str(l))
+
+f = embediface.Fail("boom")
+print("str(f):", str(f))
+
+print("Both has Name:", hasattr(embediface.Both(), "Name"))
+
+print("OK")
@@ -0,0 +1,95 @@
+// Copyright 2019 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// package enums tests the python IntEnum classes generated for the consts
+// of named integer types.
+package enums
+
+// Color is a color
+type Color int
+
+const (
+	colorUnknown Color = iota
+	Red
+	Green
+	Blue
+
+	// Crimson is another name of Red
+	Crimson = Red
+)
+
+// Flags is a set of bit flags, which is an IntFlag in python
+type Flags uint8
+
+const (
+	FlagA Flags = 1 << iota
+	FlagB
+
+	FlagHigh Flags = 128
+)
+
+// Big has values beyond the range of int64
+type Big uint64
+
+const MaxBig Big = 1<<64 - 1
+
+// Ratio is not an integer type, so its consts are plain values
+type Ratio float64
+
+const (
+	Half  Ratio = 0.5
+	Whole Ratio = 1
+)
+
+// Status is a status, which is a str Enum in python
+type Status string
+
+const (
+	Pending Status = "pending"
+	Done    Status = "done"
+	failed  Status = "failed: \"quoted\"\n"
+)
+
+// Next returns the color after c
+func Next(c Color) Color {
+	return c%3 + 1
+}
+
+// Unknown returns the unknown color
+func Unknown() Color {
+	return colorUnknown
+}
+
+// Has returns whether f has all the flags of fl
+func Has(f, fl Flags) bool {
+	return f&fl == fl
+}
+
+// Default returns the default flags
+func Default() Flags {
+	return FlagA | FlagHigh
+}
+
+// Invalid returns a color that has no name
+func Invalid() Color {
+	return 42
+}
+
+// Check returns the status of a task
+func Check(done bool) Status {
+	if done {
+		return Done
+	}
+	return Pending
+}
+
+// Fail returns the failed status
+func Fail() Status {
+	return failed
+}
+
+// Describe returns a description of the status
+func Describe(s Status) string {
+	return "status " + string(s)
+}
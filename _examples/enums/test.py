@@ -0,0 +1,34 @@
+# Copyright 2019 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+import enum
+
+import enums
+
+print("Color:", [c.name for c in enums.Color])
+print("Red:", enums.Red, enums.Red.name, enums.Red == 1, enums.Red < enums.Blue)
+print("Crimson:", enums.Crimson.name, enums.Color.Crimson is enums.Color.Red)
+print("colorUnknown:", hasattr(enums, "colorUnknown"), enums.Color.colorUnknown == 0)
+
+print("Next:", enums.Next(enums.Red), enums.Color.from_value(enums.Next(enums.Red)).name)
+print("Next:", enums.Color.from_value(enums.Next(enums.Blue)) is enums.Red)
+print("Unknown:", enums.Color.from_value(enums.Unknown()).name)
+v = enums.Color.from_value(enums.Invalid())
+print("Invalid:", v, isinstance(v, enums.Color))
+
+print("Flags:", [f.name for f in enums.Flags], int(enums.FlagA | enums.FlagB))
+f = enums.Flags.from_value(enums.Default())
+print("Default:", f, isinstance(f, enums.Flags), f & enums.FlagA == enums.FlagA, enums.FlagB in f)
+print("Has:", enums.Has(f, enums.FlagA | enums.FlagHigh), enums.Has(f, enums.FlagB))
+print("IntFlag:", issubclass(enums.Flags, enum.IntFlag), issubclass(enums.Color, enum.IntFlag))
+print("MaxBig:", enums.MaxBig, enums.Big.from_value(2**64 - 1).name)
+print("Ratio:", enums.Half, enums.Whole, hasattr(enums, "Ratio") and not hasattr(enums.Ratio, "from_value"))
+
+print("Status:", [s.name for s in enums.Status], enums.Pending == "pending", enums.Pending, issubclass(enums.Status, str))
+print("Check:", enums.Check(True) == enums.Done, enums.Status.from_value(enums.Check(False)).name)
+print("Fail:", enums.Status.from_value(enums.Fail()) is enums.Status.failed, repr(enums.Status.failed.value))
+print("from_value:", enums.Status.from_value("other"), type(enums.Status.from_value("other")).__name__)
+print("Describe:", enums.Describe(enums.Done), enums.Describe("x"))
+
+print("OK")
@@ -0,0 +1,100 @@
+// Copyright 2019 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// package errtypes tests the raising of Go errors as typed python
+// exceptions, for sentinel error vars and error types, and the chaining
+// of the errors that they wrap.
+package errtypes
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrNotFound is returned when a key is not found
+var ErrNotFound = errors.New("not found")
+
+// ErrEmpty is returned for an empty key
+var ErrEmpty = errors.New("empty key")
+
+// InvalidKeyError is the error for an invalid key
+type InvalidKeyError struct {
+	Key  string
+	Code int
+}
+
+func (e *InvalidKeyError) Error() string {
+	return fmt.Sprintf("invalid key %q (code %d)", e.Key, e.Code)
+}
+
+// LimitError is an error type with a value receiver
+type LimitError struct {
+	Limit int
+}
+
+func (e LimitError) Error() string {
+	return fmt.Sprintf("limit of %d exceeded", e.Limit)
+}
+
+var db = map[string]int{"one": 1, "two": 2}
+
+// Lookup returns the value of the given key
+func Lookup(key string) (int, error) {
+	switch {
+	case key == "":
+		return 0, ErrEmpty
+	case key[0] == '_':
+		return 0, &InvalidKeyError{Key: key, Code: 42}
+	}
+	v, ok := db[key]
+	if !ok {
+		return 0, ErrNotFound
+	}
+	return v, nil
+}
+
+// Check returns an error if n exceeds the limit
+func Check(n int) error {
+	if n > 10 {
+		return LimitError{Limit: 10}
+	}
+	if n < 0 {
+		return errors.New("negative")
+	}
+	return nil
+}
+
+// Find looks up the given key, wrapping the error of Lookup
+func Find(key string) (int, error) {
+	v, err := Lookup(key)
+	if err != nil {
+		return 0, fmt.Errorf("find %q: %w", key, err)
+	}
+	return v, nil
+}
+
+// multiError wraps multiple errors
+type multiError []error
+
+func (me multiError) Error() string {
+	return fmt.Sprintf("%d errors", len(me))
+}
+
+func (me multiError) Unwrap() []error {
+	return me
+}
+
+// CheckAll returns the errors of Check for all the given values
+func CheckAll(ns ...int) error {
+	var me multiError
+	for _, n := range ns {
+		if err := Check(n); err != nil {
+			me = append(me, err)
+		}
+	}
+	if len(me) == 0 {
+		return nil
+	}
+	return me
+}
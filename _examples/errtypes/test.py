@@ -0,0 +1,68 @@
+# Copyright 2019 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+## py2/py3 compat
+from __future__ import print_function
+
+import go, errtypes
+
+print("Lookup:", errtypes.Lookup("two"))
+
+try:
+	errtypes.Lookup("three")
+except errtypes.ErrNotFound as e:
+	print("caught ErrNotFound:", e)
+
+try:
+	errtypes.Lookup("")
+except errtypes.ErrNotFound as e:
+	print("wrong: ErrNotFound")
+except errtypes.ErrEmpty as e:
+	print("caught ErrEmpty:", e)
+
+try:
+	errtypes.Lookup("_x")
+except errtypes.InvalidKeyError as e:
+	print("caught InvalidKeyError:", e)
+	print("InvalidKeyError fields:", e.Key, e.Code)
+
+try:
+	errtypes.Check(11)
+except errtypes.LimitError as e:
+	print("caught LimitError:", e, e.Limit)
+
+try:
+	errtypes.Check(-1)
+except go.GoError as e:
+	print("caught GoError:", type(e).__name__, e)
+
+try:
+	errtypes.Lookup("three")
+except RuntimeError as e:
+	print("caught RuntimeError:", isinstance(e, go.GoError), e)
+
+print("Check:", repr(errtypes.Check(5)))
+
+try:
+	errtypes.Find("three")
+except go.GoError as e:
+	print("caught Find:", type(e).__name__, e)
+	print("cause:", type(e.__cause__).__name__, e.__cause__)
+	print("is_:", e.is_(errtypes.ErrNotFound), e.is_(errtypes.ErrEmpty))
+
+try:
+	errtypes.Find("_y")
+except go.GoError as e:
+	ke = e.as_(errtypes.InvalidKeyError)
+	print("as_:", ke.Key, ke.Code)
+	print("as_ none:", e.as_(errtypes.LimitError))
+
+try:
+	errtypes.CheckAll(1, 20, -5)
+except go.GoError as e:
+	print("caught CheckAll:", e)
+	print("exceptions:", e.__cause__, [str(c) for c in e.exceptions])
+	print("is_:", e.is_(errtypes.LimitError), e.as_(errtypes.LimitError).Limit)
+
+print("OK")
@@ -0,0 +1,42 @@
+// Copyright 2019 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// package finalize tests the deterministic finalization of the python
+// wrappers generated with -finalize, which close the io.Closer values
+// once they are no longer referenced from python.
+package finalize
+
+var closed []string
+
+// Resource is a resource that must be closed
+type Resource struct {
+	Name   string
+	closed bool
+}
+
+// Open opens a new resource
+func Open(name string) *Resource {
+	return &Resource{Name: name}
+}
+
+// Close closes the resource, once
+func (r *Resource) Close() error {
+	if !r.closed {
+		r.closed = true
+		closed = append(closed, r.Name)
+	}
+	return nil
+}
+
+var shared = &Resource{Name: "shared"}
+
+// Shared returns the same resource at each call
+func Shared() *Resource {
+	return shared
+}
+
+// Closed returns the names of the resources closed so far
+func Closed() []string {
+	return closed
+}
@@ -0,0 +1,40 @@
+# Copyright 2019 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+import go, finalize
+
+def closed():
+    return list(finalize.Closed())
+
+a = finalize.Open("a")
+del a
+print("closed on release:", closed())
+
+b = finalize.Open("b")
+b2 = finalize.Resource(b)
+del b
+print("still referenced:", closed())
+del b2
+print("closed on last release:", closed())
+
+with finalize.Open("c") as c:
+    pass
+del c
+print("closed once:", closed())
+
+s1 = finalize.Shared()
+s2 = finalize.Shared()
+del s1
+print("shared still referenced:", closed())
+del s2
+print("shared closed on last release:", closed())
+
+d = finalize.Open("d")
+d.me = d # reference cycle
+del d
+print("in a cycle:", closed())
+print("handles:", go.collect())
+print("collected:", closed())
+
+print("OK")
@@ -0,0 +1,83 @@
+// Copyright 2019 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// package fixedarrays tests fixed-size arrays as parameters,
+// return values and struct fields
+package fixedarrays
+
+import (
+	"encoding/hex"
+	"fmt"
+)
+
+// ID is a 16 byte identifier
+type ID [16]byte
+
+// String returns the hex encoding of the id
+func (id ID) String() string {
+	return hex.EncodeToString(id[:])
+}
+
+// IsZero returns true if all the bytes of the id are zero
+func (id ID) IsZero() bool {
+	return id == ID{}
+}
+
+// NewID returns the id with all bytes set to b
+func NewID(b byte) ID {
+	var id ID
+	for i := range id {
+		id[i] = b
+	}
+	return id
+}
+
+// ParseID parses the hex encoding of an id
+func ParseID(s string) (ID, error) {
+	var id ID
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		return id, err
+	}
+	if len(b) != len(id) {
+		return id, fmt.Errorf("invalid id length %d", len(b))
+	}
+	copy(id[:], b)
+	return id, nil
+}
+
+// Record has array fields
+type Record struct {
+	ID    ID
+	Coord [3]float64
+}
+
+// Norm1 returns the sum of the absolute values of the coordinates
+func (r *Record) Norm1() float64 {
+	s := 0.0
+	for _, c := range r.Coord {
+		if c < 0 {
+			c = -c
+		}
+		s += c
+	}
+	return s
+}
+
+// Sum returns the sum of the values of a
+func Sum(a [4]int) int {
+	s := 0
+	for _, v := range a {
+		s += v
+	}
+	return s
+}
+
+// Reverse returns the values of a in reverse order
+func Reverse(a [4]int) [4]int {
+	for i, j := 0, len(a)-1; i < j; i, j = i+1, j-1 {
+		a[i], a[j] = a[j], a[i]
+	}
+	return a
+}
@@ -0,0 +1,49 @@
+# Copyright 2019 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+## py2/py3 compat
+from __future__ import print_function
+
+import go, fixedarrays
+
+a = fixedarrays.Array_4_int([1, 2, 3, 4])
+print("len(a):", len(a))
+print("Sum(a):", fixedarrays.Sum(a))
+print("Reverse(a):", list(fixedarrays.Reverse(a)))
+print("a after Reverse:", list(a))
+
+try:
+	fixedarrays.Array_4_int([1, 2, 3])
+	print("no error for short sequence")
+except ValueError as e:
+	print("caught:", e)
+
+id = fixedarrays.NewID(0xab)
+print("NewID:", id)
+print("NewID IsZero:", id.IsZero())
+print("zero IsZero:", fixedarrays.ID().IsZero())
+print("from bytes:", fixedarrays.ID(bytearray(range(16))))
+
+pid = fixedarrays.ParseID("000102030405060708090a0b0c0d0e0f")
+print("ParseID:", pid, pid[15])
+try:
+	fixedarrays.ParseID("0001")
+	print("no error for short id")
+except Exception as e:
+	print("caught:", e)
+
+r = fixedarrays.Record()
+print("Record.ID IsZero:", r.ID.IsZero())
+r.ID = id
+print("Record.ID:", r.ID)
+r.Coord = [1.5, -2, 0.5]
+print("Record.Coord:", list(r.Coord))
+print("Record.Norm1:", r.Norm1())
+try:
+	r.Coord = [1, 2]
+	print("no error for short coord")
+except ValueError as e:
+	print("caught:", e)
+
+print("OK")
@@ -0,0 +1,30 @@
+// Copyright 2019 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// package formats tests the formatting of structs with Go fmt verbs,
+// e.g., f"{obj:%+v}" in python.
+package formats
+
+import "fmt"
+
+// Point is a labeled point
+type Point struct {
+	X, Y  int
+	Label string
+}
+
+// Line is a line between two points
+type Line struct {
+	From, To Point
+}
+
+// Temp is a temperature in Celsius
+type Temp struct {
+	C float64
+}
+
+// String returns the temperature in Celsius
+func (t Temp) String() string {
+	return fmt.Sprintf("%gC", t.C)
+}
@@ -0,0 +1,19 @@
+# Copyright 2019 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+import go, formats
+
+p = formats.Point(X=1, Y=2, Label="a")
+print(f"{p:%v}")
+print(f"{p:%+v}")
+print(f"{p:%#v}")
+print("{:%T}".format(p))
+
+ln = formats.Line(From=p, To=formats.Point(X=3, Y=4))
+print(f"{ln:%+v}")
+
+t = formats.Temp(C=21.5)
+print(f"{t}|{t:%v}|{t:%#v}|{t:>7}|")
+
+print("OK")
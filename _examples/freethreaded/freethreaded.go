@@ -0,0 +1,51 @@
+// Copyright 2019 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// package freethreaded tests the bindings built with -free-threaded, for the
+// free-threaded python build: slices, maps, struct fields and package vars
+// modified concurrently from python threads, and not thread-safe functions.
+package freethreaded
+
+import "runtime"
+
+// Log is a log of entries, appended to from many threads
+var Log []string
+
+// Counts are counts by key
+type Counts map[string]int
+
+// NewCounts returns new empty counts
+func NewCounts() Counts {
+	return Counts{}
+}
+
+// Stats are statistics updated from many threads
+type Stats struct {
+	Name  string
+	Total int
+	Items []int
+}
+
+// NewStats returns new named stats
+func NewStats(name string) *Stats {
+	return &Stats{Name: name}
+}
+
+var bumps int
+
+// Bump increments the number of bumps, with a read-modify-write that is
+// not safe to call concurrently, and returns it.
+//
+// gopy:notthreadsafe
+func Bump() int {
+	n := bumps
+	runtime.Gosched()
+	bumps = n + 1
+	return bumps
+}
+
+// Bumps returns the number of bumps
+func Bumps() int {
+	return bumps
+}
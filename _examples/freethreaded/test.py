@@ -0,0 +1,42 @@
+# Copyright 2019 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+import threading
+
+import go, freethreaded
+
+THREADS = 8
+ITERS = 200
+
+counts = freethreaded.NewCounts()
+stats = freethreaded.NewStats("stats")
+log = freethreaded.Log()
+errors = []
+
+def work(t):
+	try:
+		for i in range(ITERS):
+			log.append("%d-%d" % (t, i))
+			counts["%d-%d" % (t, i)] = i
+			stats.Items.append(i)
+			stats.Name = "stats-%d" % t
+			freethreaded.Bump()
+	except Exception as e:
+		errors.append(repr(e))
+
+threads = [threading.Thread(target=work, args=(t,)) for t in range(THREADS)]
+for t in threads:
+	t.start()
+for t in threads:
+	t.join()
+
+print("errors:", errors)
+print("log:", len(freethreaded.Log()) == THREADS * ITERS)
+print("counts:", len(counts) == THREADS * ITERS)
+print("items:", len(stats.Items) == THREADS * ITERS)
+print("name:", stats.Name.startswith("stats-"))
+print("bumps:", freethreaded.Bumps() == THREADS * ITERS)
+print("doc:", "Not thread-safe" in freethreaded.Bump.__doc__)
+
+print("OK")
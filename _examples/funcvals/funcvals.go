@@ -0,0 +1,78 @@
+// Copyright 2019 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// package funcvals tests returning Go func values to python,
+// where they can be called like any python callable
+package funcvals
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Adder returns a func that adds n to its argument
+func Adder(n int) func(int) int {
+	return func(x int) int {
+		return x + n
+	}
+}
+
+// Formatter is a named func type
+type Formatter func(name string, n int) string
+
+// NewFormatter returns a Formatter that uses the given prefix,
+// or an error if the prefix is empty
+func NewFormatter(prefix string) (Formatter, error) {
+	if prefix == "" {
+		return nil, fmt.Errorf("empty prefix")
+	}
+	return func(name string, n int) string {
+		return fmt.Sprintf("%s %s=%d", prefix, name, n)
+	}, nil
+}
+
+// Counter returns a func returning the next count on each call
+func Counter() func() int {
+	n := 0
+	return func() int {
+		n++
+		return n
+	}
+}
+
+// Checker returns a func that returns an error for empty strings
+func Checker() func(s string) error {
+	return func(s string) error {
+		if s == "" {
+			return fmt.Errorf("empty string")
+		}
+		return nil
+	}
+}
+
+// Pipeline holds a transform func as a field
+type Pipeline struct {
+	Name      string
+	Transform func(s string) string
+}
+
+// NewPipeline returns a Pipeline that upper-cases its input
+func NewPipeline(name string) *Pipeline {
+	return &Pipeline{Name: name, Transform: strings.ToUpper}
+}
+
+// Run applies the Transform of the pipeline to s
+func (p *Pipeline) Run(s string) string {
+	if p.Transform == nil {
+		return s
+	}
+	return p.Transform(s)
+}
+
+// Compose returns the func that applies f and then g
+func Compose(f, g func(int) int) func(int) int {
+	return func(x int) int {
+		return g(f(x))
+	}
+}
@@ -0,0 +1,53 @@
+# Copyright 2019 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+## py2/py3 compat
+from __future__ import print_function
+
+import go, funcvals
+
+add5 = funcvals.Adder(5)
+print("callable:", callable(add5))
+print("add5(10):", add5(10))
+print("add5(-5):", add5(-5))
+
+cnt = funcvals.Counter()
+print("Counter:", [cnt() for _ in range(3)])
+
+fmt = funcvals.NewFormatter("val:")
+print("Formatter:", fmt("x", 42))
+try:
+    funcvals.NewFormatter("")
+except Exception as e:
+    print("NewFormatter error:", e)
+
+check = funcvals.Checker()
+check("ok")
+try:
+    check("")
+except Exception as e:
+    print("Checker error:", e)
+
+inc = funcvals.Compose(add5, lambda x: x * 2)
+print("Compose(add5, double)(1):", inc(1))
+
+p = funcvals.NewPipeline("up")
+print("Run:", p.Run("hello"))
+tr = p.Transform
+print("Transform:", tr("go"))
+p.Transform = lambda s: s[::-1]
+print("Run reversed:", p.Run("hello"))
+p.Transform = tr
+print("Run restored:", p.Run("hello"))
+try:
+    p.Transform = 42
+except TypeError as e:
+    print("Transform TypeError:", e)
+
+try:
+    funcvals.Formatter()
+except TypeError as e:
+    print("Formatter() TypeError:", e)
+
+print("OK")
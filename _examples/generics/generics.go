@@ -0,0 +1,84 @@
+// Copyright 2019 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// package generics tests binding explicit instantiations of
+// generic types and funcs, given with the -instantiate option
+package generics
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Number is the constraint of the numeric funcs
+type Number interface {
+	~int | ~int64 | ~float64
+}
+
+// List is a generic list of values
+type List[T any] struct {
+	Items []T
+}
+
+// NewList returns a new empty List
+func NewList[T any]() *List[T] {
+	return &List[T]{}
+}
+
+// Push appends v to the list
+func (l *List[T]) Push(v T) {
+	l.Items = append(l.Items, v)
+}
+
+// Len returns the number of items in the list
+func (l *List[T]) Len() int {
+	return len(l.Items)
+}
+
+// At returns the item at index i
+func (l *List[T]) At(i int) T {
+	return l.Items[i]
+}
+
+// String returns the items of the list
+func (l *List[T]) String() string {
+	strs := make([]string, len(l.Items))
+	for i, it := range l.Items {
+		strs[i] = fmt.Sprint(it)
+	}
+	return "List[" + strings.Join(strs, ", ") + "]"
+}
+
+// Pair holds a key and a value
+type Pair[K comparable, V any] struct {
+	Key K
+	Val V
+}
+
+// MakePair returns the Pair of k and v
+func MakePair[K comparable, V any](k K, v V) Pair[K, V] {
+	return Pair[K, V]{Key: k, Val: v}
+}
+
+// Max returns the larger of a and b
+func Max[T Number](a, b T) T {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// Sum returns the sum of the items of the list
+func Sum[T Number](l *List[T]) T {
+	var s T
+	for _, v := range l.Items {
+		s += v
+	}
+	return s
+}
+
+// Hello is not generic
+func Hello(name string) string {
+	return "hello " + name
+}
@@ -0,0 +1,33 @@
+# Copyright 2019 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+## py2/py3 compat
+from __future__ import print_function
+
+import go, generics
+
+li = generics.NewList_int()
+li.Push(1)
+li.Push(2)
+li.Push(39)
+print("li.Len():", li.Len())
+print("li.At(2):", li.At(2))
+print("li:", li.String())
+print("Sum_int(li):", generics.Sum_int(li))
+
+ls = generics.List_string()
+ls.Push("a")
+ls.Push("b")
+print("ls:", ls.String())
+
+p = generics.MakePair_string_int("answer", 42)
+print("p.Key:", p.Key, "p.Val:", p.Val)
+p2 = generics.Pair_string_int(Key="one", Val=1)
+print("p2.Key:", p2.Key, "p2.Val:", p2.Val)
+
+print("Max_int(3, 7):", generics.Max_int(3, 7))
+print("Max_float64(2.5, 1.5):", generics.Max_float64(2.5, 1.5))
+print("Hello:", generics.Hello("gopher"))
+
+print("OK")
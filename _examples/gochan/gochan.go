@@ -0,0 +1,84 @@
+// Copyright 2019 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// package gochan tests sending on, receiving from and closing Go
+// channels from python
+package gochan
+
+import "strings"
+
+// Item is sent over channels
+type Item struct {
+	Name string
+}
+
+// Produce returns a channel on which the goroutine it starts sends
+// the n first squares, before closing it
+func Produce(n int) chan int {
+	c := make(chan int)
+	go func() {
+		for i := 0; i < n; i++ {
+			c <- i * i
+		}
+		close(c)
+	}()
+	return c
+}
+
+// Stream returns a receive-only channel on which the goroutine it
+// starts sends the words of s, before closing it
+func Stream(s string) <-chan string {
+	c := make(chan string)
+	go func() {
+		for _, w := range strings.Fields(s) {
+			c <- w
+		}
+		close(c)
+	}()
+	return c
+}
+
+// Upper returns a channel on which each string sent on in is sent
+// back in upper case, until in is closed
+func Upper(in chan string) chan string {
+	out := make(chan string, 1)
+	go func() {
+		for s := range in {
+			out <- strings.ToUpper(s)
+		}
+		close(out)
+	}()
+	return out
+}
+
+// Sum receives all the values sent on c until it is closed and
+// returns their sum
+func Sum(c chan int) int {
+	sum := 0
+	for v := range c {
+		sum += v
+	}
+	return sum
+}
+
+// Items returns a closed buffered channel holding an item for each
+// of the given names
+func Items(names []string) chan *Item {
+	c := make(chan *Item, len(names))
+	for _, nm := range names {
+		c <- &Item{Name: nm}
+	}
+	close(c)
+	return c
+}
+
+// Never returns a channel on which nothing is ever sent
+func Never() chan int {
+	return make(chan int)
+}
+
+// Nil returns a nil channel
+func Nil() chan int {
+	return nil
+}
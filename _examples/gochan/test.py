@@ -0,0 +1,94 @@
+# Copyright 2019 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+
+import os, signal, threading
+import gochan, go
+
+c = gochan.Produce(4)
+vals = []
+while True:
+    try:
+        vals.append(c.recv())
+    except EOFError:
+        break
+print("Produce(4):", vals)
+
+print("Stream:", [w for w in gochan.Stream("a stream of words")])
+
+for v in gochan.Produce(3):
+    print("range Produce:", v)
+
+try:
+    gochan.RecvChan_string()
+except TypeError as e:
+    print("RecvChan_string():", e)
+
+c = gochan.Chan_int(3)
+print("cap:", c.cap())
+c.send(1)
+c.send(2)
+c.send(3)
+print("len:", c.len())
+c.close()
+print("Sum:", gochan.Sum(c))
+
+try:
+    c.send(4)
+except ValueError as e:
+    print("send after close:", e)
+
+try:
+    c.close()
+except ValueError as e:
+    print("close after close:", e)
+
+inp = gochan.Chan_string()
+out = gochan.Upper(inp)
+inp.send("hello")
+print("Upper:", out.recv())
+inp.close()
+try:
+    out.recv(timeout=1)
+except EOFError as e:
+    print("recv after close:", e)
+
+items = gochan.Items(go.Slice_string(["a", "b"]))
+print("Items:", items.recv().Name, items.recv().Name)
+
+try:
+    gochan.Never().recv(timeout=0.01)
+except TimeoutError as e:
+    print("recv timeout:", e)
+
+nc = gochan.Nil()
+for op in (nc.recv, lambda: nc.send(1), nc.close):
+    try:
+        op()
+    except ValueError as e:
+        print("nil chan:", e)
+
+def ctrl_c():
+    os.kill(os.getpid(), signal.SIGINT)
+
+threading.Timer(0.05, ctrl_c).start()
+try:
+    gochan.Never().recv()
+except KeyboardInterrupt:
+    print("recv interrupted")
+
+threading.Timer(0.05, ctrl_c).start()
+try:
+    gochan.Chan_int().send(1)
+except KeyboardInterrupt:
+    print("send interrupted")
+
+threading.Timer(0.05, ctrl_c).start()
+try:
+    for v in gochan.Never():
+        pass
+except KeyboardInterrupt:
+    print("iteration interrupted")
+
+print("OK")
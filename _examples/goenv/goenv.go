@@ -0,0 +1,24 @@
+// Copyright 2019 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// package goenv tests setting the environment of the Go side,
+// with -init-env and go.setenv.
+package goenv
+
+import (
+	"os"
+	"runtime/debug"
+)
+
+// Getenv returns the value of the environment variable as seen by Go
+func Getenv(key string) string {
+	return os.Getenv(key)
+}
+
+// GCPercent returns the current GOGC percentage of the Go runtime
+func GCPercent() int {
+	pct := debug.SetGCPercent(100)
+	debug.SetGCPercent(pct)
+	return pct
+}
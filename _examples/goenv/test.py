@@ -0,0 +1,26 @@
+# Copyright 2019 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+from __future__ import print_function
+
+import goenv, go
+
+# set with -init-env, before the Go runtime was initialized
+print("GOPY_INIT = %s" % goenv.Getenv("GOPY_INIT"))
+print("GC percent = %d" % goenv.GCPercent())
+
+go.setenv("GOPY_SET", "set")
+print("GOPY_SET = %s" % goenv.Getenv("GOPY_SET"))
+
+go.setenv("GOGC", "75")
+print("GC percent = %d" % goenv.GCPercent())
+
+try:
+    go.setenv("GOGC", "lots")
+    print("no error")
+except RuntimeError as e:
+    print("caught error: %s" % e)
+print("GOGC = %s" % goenv.Getenv("GOGC"))
+
+print("OK")
@@ -0,0 +1,70 @@
+// Copyright 2019 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// package grid tests slices of slices, whose elements are usable as slices
+// and can be passed in as nested python lists.
+package grid
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Matrix is a two-dimensional slice of rows
+type Matrix [][]int
+
+// String returns the rows of the Matrix, one per line
+func (m Matrix) String() string {
+	rows := make([]string, len(m))
+	for i, r := range m {
+		rows[i] = fmt.Sprint(r)
+	}
+	return strings.Join(rows, "\n")
+}
+
+// Identity returns the n x n identity Matrix
+func Identity(n int) Matrix {
+	m := make(Matrix, n)
+	for i := range m {
+		m[i] = make([]int, n)
+		m[i][i] = 1
+	}
+	return m
+}
+
+// Table returns a rows x cols table of strings naming the cells
+func Table(rows, cols int) [][]string {
+	t := make([][]string, rows)
+	for i := range t {
+		t[i] = make([]string, cols)
+		for j := range t[i] {
+			t[i][j] = fmt.Sprintf("r%dc%d", i, j)
+		}
+	}
+	return t
+}
+
+// Sum returns the sum of all the elements of m
+func Sum(m [][]int) int {
+	n := 0
+	for _, r := range m {
+		for _, v := range r {
+			n += v
+		}
+	}
+	return n
+}
+
+// Shape returns the number of rows and the length of each of them
+func Shape(m [][][]float64) string {
+	var s []string
+	for _, p := range m {
+		ls := make([]string, len(p))
+		for i, r := range p {
+			ls[i] = fmt.Sprint(len(r))
+		}
+		s = append(s, strings.Join(ls, ","))
+	}
+	return strings.Join(s, " ")
+}
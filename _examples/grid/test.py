@@ -0,0 +1,33 @@
+# Copyright 2019 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+## py2/py3 compat
+from __future__ import print_function
+
+import go, grid
+
+t = grid.Table(2, 3)
+print("t[1][2]:", t[1][2])
+print("rows:", [list(r) for r in t])
+
+m = grid.Identity(3)
+m[0][2] = 7
+m[1].append(5)
+print(m)
+print("Sum:", grid.Sum(m))
+
+m.append([1, 1, 1])
+m[0] = [2, 2]
+print("appended:", len(m), list(m[3]), list(m[0]))
+print("Sum:", grid.Sum(m))
+
+print("Sum list:", grid.Sum([[1, 2], [3], []]))
+print("Shape:", grid.Shape([[[1.0], [2.0, 3.0]], [[]]]))
+
+try:
+	grid.Sum([[1, "x"]])
+except TypeError as e:
+	print("caught TypeError")
+
+print("OK")
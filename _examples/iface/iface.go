@@ -31,7 +31,8 @@ func CallIface(v Iface) {
 	cpkg.Printf("iface.CallIface... [DONE]\n")
 }
 
-// by default, interface{} is converted to string (most universal type)
+// by default, the python value of an interface{} arg is converted to
+// the corresponding Go value, e.g., a string
 func IfaceString(str interface{}) {
 	cpkg.Printf("iface as string: %v\n", str)
 }
@@ -0,0 +1,43 @@
+// Copyright 2019 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// package interrupt tests raising KeyboardInterrupt promptly on Ctrl-C
+// during long-running Go calls, which are cancelled if they have a context.
+package interrupt
+
+import (
+	"context"
+	"time"
+)
+
+var stopped = make(chan string, 10)
+
+// Spin returns n after ms milliseconds, or -1 if ctx is cancelled before
+func Spin(ctx context.Context, n, ms int) int {
+	select {
+	case <-time.After(time.Duration(ms) * time.Millisecond):
+		return n
+	case <-ctx.Done():
+		stopped <- "Spin"
+		return -1
+	}
+}
+
+// Serve serves until ctx is cancelled
+func Serve(ctx context.Context) error {
+	<-ctx.Done()
+	stopped <- "Serve"
+	return ctx.Err()
+}
+
+// Stopped returns the name of the next func stopped by the cancellation
+// of its context, waiting up to a second for it
+func Stopped() string {
+	select {
+	case s := <-stopped:
+		return s
+	case <-time.After(time.Second):
+		return ""
+	}
+}
@@ -0,0 +1,33 @@
+# Copyright 2019 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+import os
+import signal
+import threading
+import time
+
+import interrupt
+
+print("Spin:", interrupt.Spin(1, 10))
+
+def ctrl_c():
+    os.kill(os.getpid(), signal.SIGINT)
+
+threading.Timer(0.05, ctrl_c).start()
+start = time.monotonic()
+try:
+    interrupt.Spin(2, 5000)
+except KeyboardInterrupt:
+    print("Spin interrupted promptly:", time.monotonic() - start < 2)
+print("stopped:", interrupt.Stopped())
+
+threading.Timer(0.05, ctrl_c).start()
+try:
+    interrupt.Serve()
+except KeyboardInterrupt:
+    print("Serve interrupted")
+print("stopped:", interrupt.Stopped())
+
+print("Spin after:", interrupt.Spin(3, 10))
+print("OK")
@@ -0,0 +1,64 @@
+// Copyright 2019 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// package introspect tests the dir() of the classes of structs, with the
+// fields and methods promoted from their embedded structs, and the __all__
+// of the modules.
+package introspect
+
+// Base is the base of things
+type Base struct {
+	ID int
+}
+
+// Describe describes the base
+func (b *Base) Describe() string {
+	return "base"
+}
+
+// Named has a name
+type Named struct {
+	Name string
+}
+
+// Hello greets the name
+func (n *Named) Hello() string {
+	return "hello " + n.Name
+}
+
+// Titled has a title, and a name too
+type Titled struct {
+	Title string
+	Name  string
+}
+
+// Thing derives from Base, and has the fields and methods of Named
+type Thing struct {
+	Base
+	Named
+	Size int
+}
+
+// Grow grows the thing
+func (t *Thing) Grow() {
+	t.Size++
+}
+
+// Deep derives from Thing
+type Deep struct {
+	Thing
+	Depth int
+}
+
+// Both has an ambiguous Name, which is not promoted, unlike Title and Hello
+type Both struct {
+	Size int
+	Named
+	Titled
+}
+
+// NewThing returns a new named thing
+func NewThing(name string) *Thing {
+	return &Thing{Named: Named{Name: name}}
+}
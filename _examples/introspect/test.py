@@ -0,0 +1,31 @@
+# Copyright 2019 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+import introspect
+from introspect import *
+
+def members(o):
+    return [n for n in dir(o) if n[0].isupper()]
+
+t = introspect.NewThing("box")
+print("Thing:", members(t))
+print(t.Name, t.Hello())
+t.Name = "crate"
+t.ID = 7
+print(t.Name, t.ID, t.Describe())
+
+d = introspect.Deep()
+d.Name = "deep"
+print("Deep:", members(d))
+print(d.Hello())
+
+b = introspect.Both()
+b.Title = "title"
+print("Both:", members(b))
+print(hasattr(b, "Name"), b.Title)
+
+print("__all__:", sorted(introspect.__all__))
+print(Thing is introspect.Thing, NewThing is introspect.NewThing)
+
+print("OK")
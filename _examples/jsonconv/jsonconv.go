@@ -0,0 +1,56 @@
+// Copyright 2019 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// package jsonconv tests the to_json and from_json of the python classes of
+// structs, which use encoding/json in Go, honoring the json struct tags.
+package jsonconv
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Level is encoded to JSON by its MarshalJSON method
+type Level int
+
+func (l Level) MarshalJSON() ([]byte, error) {
+	return []byte(fmt.Sprintf("%q", strings.Repeat("*", int(l)))), nil
+}
+
+func (l *Level) UnmarshalJSON(b []byte) error {
+	s := strings.Trim(string(b), `"`)
+	if strings.Trim(s, "*") != "" {
+		return fmt.Errorf("invalid level: %s", b)
+	}
+	*l = Level(len(s))
+	return nil
+}
+
+// Point is a point in the plane
+type Point struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+}
+
+// Config has fields with json struct tags
+type Config struct {
+	Name    string            `json:"name"`
+	Port    int               `json:"port,omitempty"`
+	Secret  string            `json:"-"`
+	Tags    []string          `json:"tags"`
+	Origin  Point             `json:"origin"`
+	Labels  map[string]string `json:"labels,omitempty"`
+	Level   Level             `json:"level"`
+	Enabled bool
+}
+
+// NewConfig returns a new config
+func NewConfig() *Config {
+	return &Config{Name: "srv", Secret: "s3cr3t", Tags: []string{"a", "b"}, Origin: Point{1, 2}, Level: 2, Enabled: true}
+}
+
+// Describe returns a description of the config
+func (c *Config) Describe() string {
+	return fmt.Sprintf("%s %d %q %v %v %v %d %v", c.Name, c.Port, c.Secret, c.Tags, c.Origin, c.Labels, c.Level, c.Enabled)
+}
@@ -0,0 +1,27 @@
+# Copyright 2019 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+import jsonconv
+
+c = jsonconv.NewConfig()
+s = c.to_json()
+print("to_json:", s)
+
+d = jsonconv.Config.from_json(s)
+print("from_json:", d.Describe())
+
+e = jsonconv.Config.from_json(b'{"name": "db", "port": 5432, "Secret": "x", "labels": {"k": "v"}, "level": "***"}')
+print("bytes:", e.Describe())
+print("again:", e.to_json())
+
+p = jsonconv.Point.from_json('{"x": 3}')
+print("point:", p.X, p.Y, p.to_json())
+
+for bad in ['{"name": 1}', '{"level": "x"}', 'nope', 7]:
+    try:
+        jsonconv.Config.from_json(bad)
+    except Exception as err:
+        print(type(err).__name__ + ":", err)
+
+print("OK")
@@ -0,0 +1,30 @@
+// Copyright 2019 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// package jsonnames tests naming python struct fields from json tags,
+// when generated with the -json-names flag
+package jsonnames
+
+// User has fields named by their json struct tags
+type User struct {
+	// UserID is exposed as user_id
+	UserID int `json:"user_id"`
+
+	// Email is exposed as email_address
+	Email string `json:"email_address,omitempty"`
+
+	// Secret keeps its Go name as it is not serialized
+	Secret string `json:"-"`
+
+	// Custom uses the gopy tag, which takes precedence
+	Custom string `json:"custom" gopy:"custom_field"`
+
+	// Plain has no tag
+	Plain bool
+}
+
+// NewUser returns a new user
+func NewUser(id int, email string) *User {
+	return &User{UserID: id, Email: email}
+}
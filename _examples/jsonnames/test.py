@@ -0,0 +1,23 @@
+# Copyright 2019 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+## py2/py3 compat
+from __future__ import print_function
+
+import jsonnames
+
+u = jsonnames.NewUser(42, "gopher@example.com")
+print("u.user_id:", u.user_id)
+print("u.email_address:", u.email_address)
+
+u = jsonnames.User(user_id=7, email_address="a@b.c", Secret="s", custom_field="c", Plain=True)
+print("u.user_id:", u.user_id)
+print("u.email_address:", u.email_address)
+print("u.Secret:", u.Secret)
+print("u.custom_field:", u.custom_field)
+print("u.Plain:", u.Plain)
+
+print("User.user_id.__doc__:", jsonnames.User.user_id.__doc__.strip())
+
+print("OK")
@@ -0,0 +1,54 @@
+// Copyright 2019 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// package keywords tests calling the wrapped functions and methods with
+// keyword arguments, named as the Go parameters.
+package keywords
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Connect returns the address of the given host and port
+func Connect(host string, port int) string {
+	return fmt.Sprintf("%s:%d", host, port)
+}
+
+// Join joins the parts with the separator
+func Join(sep string, parts ...string) string {
+	return strings.Join(parts, sep)
+}
+
+// Move returns a move between the given places, whose names are python
+// keywords
+func Move(from, to string) string {
+	return from + "->" + to
+}
+
+// Blank has blank parameters
+func Blank(_ int, _ string, n int) int {
+	return n
+}
+
+// Unnamed has unnamed parameters
+func Unnamed(int, string) string {
+	return "unnamed"
+}
+
+// Point is a point in the plane
+type Point struct {
+	X, Y int
+}
+
+// Add returns the point moved by dx and dy
+func (p *Point) Add(dx, dy int) Point {
+	return Point{p.X + dx, p.Y + dy}
+}
+
+// Scale scales the point by the given factor
+func (p *Point) Scale(factor int, _ bool) {
+	p.X *= factor
+	p.Y *= factor
+}
@@ -0,0 +1,26 @@
+# Copyright 2019 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+import keywords
+
+print("Connect:", keywords.Connect(host="x", port=5432))
+print("Connect reordered:", keywords.Connect(port=80, host="y"))
+print("Connect mixed:", keywords.Connect("z", port=8080))
+print("Join:", keywords.Join("-", "a", "b"), repr(keywords.Join(sep="+")))
+print("Move:", keywords.Move(myfrom="a", to="b"))
+print("Blank:", keywords.Blank(arg_0=1, arg_1="x", n=3))
+print("Unnamed:", keywords.Unnamed(arg_1="x", arg_0=1))
+
+p = keywords.Point(X=1, Y=2)
+q = p.Add(dy=10, dx=20)
+print("Add:", q.X, q.Y)
+p.Scale(factor=3, arg_1=True)
+print("Scale:", p.X, p.Y)
+
+try:
+    keywords.Connect(host="x", prt=1)
+except TypeError:
+    print("Connect: TypeError for an unknown keyword")
+
+print("OK")
@@ -0,0 +1,55 @@
+// Copyright 2019 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// package limitedapi tests the bindings generated with -limited-api, whose
+// C code only uses the limited API of python, so that the extension module
+// is built once for the stable ABI (abi3) of all the later versions.
+package limitedapi
+
+import (
+	"math/cmplx"
+)
+
+// Point is a point in the plane
+type Point struct {
+	X, Y int
+}
+
+// Abs returns the modulus of c
+func Abs(c complex128) float64 {
+	return cmplx.Abs(c)
+}
+
+// Conj returns the conjugate of c
+func Conj(c complex64) complex64 {
+	return complex(real(c), -imag(c))
+}
+
+// Floats returns a slice with the values 0..n-1
+func Floats(n int) []float64 {
+	s := make([]float64, n)
+	for i := range s {
+		s[i] = float64(i)
+	}
+	return s
+}
+
+// Sum returns the sum of the values of s
+func Sum(s []float64) float64 {
+	t := 0.0
+	for _, v := range s {
+		t += v
+	}
+	return t
+}
+
+// Greet returns a greeting of name
+func Greet(name string) string {
+	return "hello " + name
+}
+
+// Apply returns f(n), calling back into python
+func Apply(f func(n int) int, n int) int {
+	return f(n)
+}
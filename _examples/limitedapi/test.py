@@ -0,0 +1,25 @@
+# Copyright 2019 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+import os
+import go, limitedapi, _limitedapi
+
+print("abi3:", os.name == "nt" or ".abi3." in os.path.basename(_limitedapi.__file__))
+
+print("Abs:", limitedapi.Abs(3+4j))
+print("Conj:", limitedapi.Conj(1-2j))
+
+s = limitedapi.Floats(3)
+v = s.buffer()
+v[1] = 2.5
+print("buffer:", v.format, v.tolist(), limitedapi.Sum(s))
+s.release_buffer(v)
+
+p = limitedapi.Point.from_dict({"X": 1, "Y": 2})
+print("from_dict:", p.asdict())
+
+print("Greet:", limitedapi.Greet("abi3"))
+print("Apply:", limitedapi.Apply(lambda n: n * 3, 14))
+
+print("OK")
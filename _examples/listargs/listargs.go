@@ -0,0 +1,86 @@
+// Copyright 2019 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// package listargs tests passing python lists and tuples for slice and
+// array parameters, which are converted with type checking.
+package listargs
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Sum returns the sum of the given ints
+func Sum(xs []int) int {
+	s := 0
+	for _, x := range xs {
+		s += x
+	}
+	return s
+}
+
+// Mean returns the mean of the given floats
+func Mean(xs []float64) float64 {
+	if len(xs) == 0 {
+		return 0
+	}
+	s := 0.0
+	for _, x := range xs {
+		s += x
+	}
+	return s / float64(len(xs))
+}
+
+// Join joins the given strings with sep
+func Join(ss []string, sep string) string {
+	return strings.Join(ss, sep)
+}
+
+// Point is a point
+type Point struct {
+	X, Y int
+}
+
+// Centroid returns the centroid of the given points
+func Centroid(pts []*Point) Point {
+	var c Point
+	if len(pts) == 0 {
+		return c
+	}
+	for _, p := range pts {
+		c.X += p.X
+		c.Y += p.Y
+	}
+	c.X /= len(pts)
+	c.Y /= len(pts)
+	return c
+}
+
+// Triple returns the product of the elements of a fixed-size array
+func Triple(v [3]int) int {
+	return v[0] * v[1] * v[2]
+}
+
+// Types returns the Go types of the given values
+func Types(vs []interface{}) string {
+	ts := make([]string, len(vs))
+	for i, v := range vs {
+		ts[i] = fmt.Sprintf("%T", v)
+	}
+	return strings.Join(ts, " ")
+}
+
+// Names is a named slice of strings
+type Names []string
+
+// Count returns the number of names in ns longer than n
+func Count(ns Names, n int) int {
+	c := 0
+	for _, nm := range ns {
+		if len(nm) > n {
+			c++
+		}
+	}
+	return c
+}
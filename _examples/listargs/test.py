@@ -0,0 +1,45 @@
+# Copyright 2019 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+## py2/py3 compat
+from __future__ import print_function
+
+import go, listargs
+
+print("Sum list:", listargs.Sum([1, 2, 3]))
+print("Sum tuple:", listargs.Sum((4, 5)))
+print("Sum empty:", listargs.Sum([]))
+print("Sum handle:", listargs.Sum(go.Slice_int([6, 7])))
+print("Mean:", listargs.Mean([1, 2.5, 2.5]))
+print("Join:", listargs.Join(("a", "b", "c"), "-"))
+
+pts = [listargs.Point(X=0, Y=0), listargs.Point(X=4, Y=2)]
+c = listargs.Centroid(pts)
+print("Centroid:", c.X, c.Y)
+
+print("Triple:", listargs.Triple([2, 3, 4]))
+print("Types:", listargs.Types([1, 2.5, "s", True, None, [1], {"k": 1}]))
+print("Count:", listargs.Count(["ann", "bob", "carol"], 3))
+
+try:
+	listargs.Sum([1, "2"])
+except TypeError as e:
+	print("caught:", e)
+
+try:
+	listargs.Mean([1.0, None])
+except TypeError as e:
+	print("caught:", e)
+
+try:
+	listargs.Centroid([listargs.Point(), 5])
+except TypeError as e:
+	print("caught:", e)
+
+try:
+	listargs.Triple([1, 2])
+except ValueError as e:
+	print("caught ValueError")
+
+print("OK")
@@ -0,0 +1,22 @@
+// Copyright 2019 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// package mapiter tests the iteration over the python wrappers of maps,
+// converting their keys and values in one pass over the map in Go.
+package mapiter
+
+// Point is a point
+type Point struct {
+	X, Y int
+}
+
+// Ages returns the ages by name
+func Ages() map[string]int {
+	return map[string]int{"ann": 31, "bob": 27, "cy": 45}
+}
+
+// Points returns the points by name
+func Points() map[string]Point {
+	return map[string]Point{"origin": {0, 0}, "unit": {1, 1}}
+}
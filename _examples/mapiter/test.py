@@ -0,0 +1,23 @@
+# Copyright 2019 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+import go, mapiter
+import _mapiter
+
+ages = mapiter.Ages()
+for k, v in sorted(ages.items()):
+    print(k, type(k).__name__, v, type(v).__name__)
+print("keys:", sorted(ages))
+print("values:", sorted(ages.values()))
+
+pts = mapiter.Points()
+for k, p in sorted(pts.items(), key=lambda kv: kv[0]):
+    print(k, type(p).__name__, p.X, p.Y)
+
+nh = _mapiter.NumHandles()
+for k, v in ages.iteritems():
+    break
+print("released:", _mapiter.NumHandles() == nh)
+
+print("OK")
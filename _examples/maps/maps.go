@@ -24,6 +24,13 @@ func New() map[int]float64 {
 	}
 }
 
+func Counts() map[string]int {
+	return map[string]int{
+		"a": 1,
+		"b": 2,
+	}
+}
+
 func Keys(t map[int]float64) []int {
 	var keys []int
 	for k, _ := range t {
@@ -5,6 +5,11 @@
 from __future__ import print_function
 import maps
 
+try:
+    import collections.abc as abc
+except ImportError:
+    import collections as abc
+
 a = maps.New()
 b = {1: 3.0, 2: 5.0}
 
@@ -23,7 +28,7 @@ if testall:
     print('map a values:', a.values())
     print('map a items:', a.items())
     print('map a iter')
-    for k,v in a:
+    for k,v in a.items():
         print("key:", k, "value:", v)
 
 print('map a[1]:', a[1])
@@ -52,4 +57,30 @@ print('maps.Values from Python dictionary:', maps.Values(maps.Map_int_float64(b)
 del a[1]
 print('deleted 1 from a:', a)
 
+c = maps.New()
+print('mapping:', isinstance(c, abc.MutableMapping), sorted(dict(c).items()), sorted(c))
+
+def kw(**kwargs):
+    return sorted(kwargs.items())
+
+m = maps.Counts()
+print('kwargs:', kw(**m))
+print('get:', m.get("a"), m.get("z"), m.get("z", 0))
+print('pop:', m.pop("a"), m.pop("z", -1), sorted(m.keys()))
+m.update({"c": 3}, d=4)
+m.update([("e", 5)])
+print('update:', sorted(m.items()))
+print('setdefault:', m.setdefault("b", 9), m.setdefault("f", 6), len(m))
+k, v = m.popitem()
+print('popitem:', k not in m, len(m))
+m.clear()
+print('clear:', len(m), dict(m))
+m.update(b=2)
+print('eq:', m == {"b": 2}, m != {"b": 2}, m == {"b": 3}, m == maps.Counts(), m == [("b", 2)])
+try:
+    m.pop("a")
+    print("*ERROR* no exception raised!")
+except KeyError as err:
+    print('pop: caught:', err)
+
 print("OK")
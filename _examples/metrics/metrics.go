@@ -0,0 +1,21 @@
+// Copyright 2019 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// package metrics tests the call metrics recorded with -metrics.
+package metrics
+
+// Add returns a + b
+func Add(a, b int) int {
+	return a + b
+}
+
+// Acc is an accumulator
+type Acc struct {
+	Total int
+}
+
+// Add adds v to the total
+func (a *Acc) Add(v int) {
+	a.Total += v
+}
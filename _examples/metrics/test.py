@@ -0,0 +1,24 @@
+# Copyright 2019 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+from __future__ import print_function
+
+import metrics, go
+
+for i in range(3):
+    metrics.Add(i, i)
+
+a = metrics.Acc()
+a.Add(2)
+
+m = go.metrics()
+print("metrics.Add count: %d" % m["metrics.Add"]["count"])
+print("metrics.Acc.Add count: %d" % m["metrics.Acc.Add"]["count"])
+print("metrics.Add +Inf bucket: %d" % m["metrics.Add"]["buckets"]["+Inf"])
+print("metrics.Add sum >= 0: %s" % (m["metrics.Add"]["sum"] >= 0))
+
+go.reset_metrics()
+print("after reset: %s" % go.metrics())
+
+print("OK")
@@ -0,0 +1,62 @@
+// Copyright 2019 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// package nestedmaps tests maps of maps, whose values are usable as maps
+// and can be passed in as nested python dicts.
+package nestedmaps
+
+import (
+	"sort"
+	"strings"
+)
+
+// Index maps sections to their settings
+type Index map[string]map[string]string
+
+// Lookup returns the setting of key in section sec
+func (ix Index) Lookup(sec, key string) string {
+	return ix[sec][key]
+}
+
+// Config returns the default settings
+func Config() map[string]map[string]string {
+	return map[string]map[string]string{
+		"db":  {"host": "localhost", "port": "5432"},
+		"web": {"port": "8080"},
+	}
+}
+
+// Count returns the number of settings in m, in all sections
+func Count(m map[string]map[string]string) int {
+	n := 0
+	for _, sec := range m {
+		n += len(sec)
+	}
+	return n
+}
+
+// Describe returns the settings of m sorted by section and key
+func Describe(m map[string]map[string]string) string {
+	var parts []string
+	for s, sec := range m {
+		for k, v := range sec {
+			parts = append(parts, s+"."+k+"="+v)
+		}
+	}
+	sort.Strings(parts)
+	return strings.Join(parts, " ")
+}
+
+// Sum returns the sum of all the values in m
+func Sum(m map[string]map[string][]int) int {
+	n := 0
+	for _, mm := range m {
+		for _, vs := range mm {
+			for _, v := range vs {
+				n += v
+			}
+		}
+	}
+	return n
+}
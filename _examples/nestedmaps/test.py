@@ -0,0 +1,31 @@
+# Copyright 2019 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+## py2/py3 compat
+from __future__ import print_function
+
+import go, nestedmaps
+
+c = nestedmaps.Config()
+print("db host:", c["db"]["host"])
+print("sections:", sorted(c.keys()))
+
+c["db"]["user"] = "admin"
+c["cache"] = {"ttl": "60"}
+print("Count:", nestedmaps.Count(c))
+print("Describe:", nestedmaps.Describe(c))
+
+print("Count dict:", nestedmaps.Count({"a": {"x": "1", "y": "2"}, "b": {}}))
+print("Sum:", nestedmaps.Sum({"a": {"x": [1, 2], "y": [3]}, "b": {"z": [4]}}))
+
+ix = nestedmaps.Index({"db": {"host": "example.com"}})
+ix["web"] = {"port": "80"}
+print("Lookup:", ix.Lookup("db", "host"), ix.Lookup("web", "port"))
+
+try:
+	c["nope"]
+except KeyError as e:
+	print("caught:", e)
+
+print("OK")
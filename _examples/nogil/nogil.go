@@ -0,0 +1,52 @@
+// Copyright 2019 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// package nogil tests the release of the python GIL during the Go calls,
+// so that other python threads run while long-running Go functions are
+// executed, except for the functions opted out with -hold-gil.
+package nogil
+
+import (
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+var ticks int64
+
+// Tick counts a tick of a python thread
+func Tick() {
+	atomic.AddInt64(&ticks, 1)
+}
+
+// Sleep sleeps for ms milliseconds, returning the number of ticks
+// counted meanwhile
+func Sleep(ms int) int {
+	start := atomic.LoadInt64(&ticks)
+	time.Sleep(time.Duration(ms) * time.Millisecond)
+	return int(atomic.LoadInt64(&ticks) - start)
+}
+
+// HoldSleep is Sleep, holding the GIL
+func HoldSleep(ms int) int {
+	return Sleep(ms)
+}
+
+// Repeat returns s repeated n times, after a while
+func Repeat(s string, n int) string {
+	time.Sleep(10 * time.Millisecond)
+	return strings.Repeat(s, n)
+}
+
+// Timer measures the time slept
+type Timer struct {
+	Slept int
+}
+
+// Sleep sleeps for ms milliseconds, returning the number of ticks
+// counted meanwhile
+func (t *Timer) Sleep(ms int) int {
+	t.Slept += ms
+	return Sleep(ms)
+}
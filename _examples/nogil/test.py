@@ -0,0 +1,33 @@
+# Copyright 2019 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+## py2/py3 compat
+from __future__ import print_function
+
+import threading
+import time
+
+import nogil
+
+done = threading.Event()
+
+def ticker():
+    while not done.is_set():
+        nogil.Tick()
+        time.sleep(0.001)
+
+t = threading.Thread(target=ticker)
+t.start()
+try:
+    print("ticks while sleeping:", nogil.Sleep(200) > 0)
+    print("ticks while holding the GIL:", nogil.HoldSleep(200))
+    tm = nogil.Timer()
+    print("ticks in a method:", tm.Sleep(200) > 0)
+    print("slept:", tm.Slept)
+    print("repeat:", nogil.Repeat("ab", 3))
+finally:
+    done.set()
+    t.join()
+
+print("OK")
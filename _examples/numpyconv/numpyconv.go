@@ -0,0 +1,49 @@
+// Copyright 2019 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// package numpyconv tests the conversion of numeric slices to and
+// from numpy arrays, with the -numpy option
+package numpyconv
+
+// Samples is a named numeric slice
+type Samples []float32
+
+// Mean returns the mean of the samples
+func (s Samples) Mean() float32 {
+	if len(s) == 0 {
+		return 0
+	}
+	var t float32
+	for _, v := range s {
+		t += v
+	}
+	return t / float32(len(s))
+}
+
+// Range returns the values 0..n-1
+func Range(n int) []int64 {
+	s := make([]int64, n)
+	for i := range s {
+		s[i] = int64(i)
+	}
+	return s
+}
+
+// Scale returns the values of s multiplied by f
+func Scale(s []float64, f float64) []float64 {
+	r := make([]float64, len(s))
+	for i, v := range s {
+		r[i] = v * f
+	}
+	return r
+}
+
+// NewSamples returns n samples all set to v
+func NewSamples(n int, v float32) Samples {
+	s := make(Samples, n)
+	for i := range s {
+		s[i] = v
+	}
+	return s
+}
@@ -0,0 +1,32 @@
+# Copyright 2019 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+import numpy as np
+import go, numpyconv
+
+r = numpyconv.Range(5).to_numpy()
+print("Range:", r.dtype, r.tolist())
+
+a = np.linspace(0, 1, 5)
+s = go.Slice_float64.from_numpy(a)
+print("from_numpy:", len(s), list(s))
+sc = numpyconv.Scale(s, 2).to_numpy()
+print("Scale:", sc.dtype, sc.tolist())
+
+m = go.Slice_float64.from_numpy(np.arange(6).reshape(2, 3))
+print("from 2d ints:", list(m))
+
+smp = numpyconv.NewSamples(4, 0.5)
+arr = smp.to_numpy()
+print("Samples:", arr.dtype, arr.tolist())
+smp2 = numpyconv.Samples.from_numpy([1, 2, 3])
+print("Samples.from_numpy:", type(smp2).__name__, smp2.Mean())
+
+e = numpyconv.Range(0).to_numpy()
+print("empty:", e.shape)
+
+big = go.Slice_float64.from_numpy(np.ones(100000))
+print("big:", len(big), big.to_numpy().sum())
+
+print("OK")
@@ -0,0 +1,99 @@
+// Copyright 2019 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// package operators tests the python operator methods generated for Go
+// methods with conventional names, e.g., __add__ for Add, with Dot mapped
+// to __matmul__ by -operators=Dot=__matmul__.
+package operators
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Vec is a vector in the plane
+type Vec struct {
+	X, Y float64
+}
+
+// NewVec returns a new vector
+func NewVec(x, y float64) Vec {
+	return Vec{x, y}
+}
+
+func (v Vec) String() string {
+	return fmt.Sprintf("(%g, %g)", v.X, v.Y)
+}
+
+// Add returns v + o, as __add__
+func (v Vec) Add(o Vec) Vec {
+	return Vec{v.X + o.X, v.Y + o.Y}
+}
+
+// Sub returns v - o, as __sub__
+func (v Vec) Sub(o Vec) Vec {
+	return Vec{v.X - o.X, v.Y - o.Y}
+}
+
+// Mul returns v scaled by f, as __mul__
+func (v Vec) Mul(f float64) Vec {
+	return Vec{v.X * f, v.Y * f}
+}
+
+// Div returns v scaled by 1/f, as __truediv__, failing for 0
+func (v Vec) Div(f float64) (Vec, error) {
+	if f == 0 {
+		return Vec{}, errors.New("division by zero")
+	}
+	return Vec{v.X / f, v.Y / f}, nil
+}
+
+// Neg returns -v, as __neg__
+func (v Vec) Neg() Vec {
+	return Vec{-v.X, -v.Y}
+}
+
+// Dot returns the dot product of v and o, as __matmul__ with -operators
+func (v Vec) Dot(o Vec) float64 {
+	return v.X*o.X + v.Y*o.Y
+}
+
+// Less is not an operator, as it does not return a bool
+func (v Vec) Less(o Vec) int {
+	return 0
+}
+
+// Money is an amount in cents
+type Money struct {
+	Cents int
+}
+
+// Cmp compares m and o, as __lt__, __le__, __gt__ and __ge__
+func (m Money) Cmp(o Money) int {
+	switch {
+	case m.Cents < o.Cents:
+		return -1
+	case m.Cents > o.Cents:
+		return 1
+	}
+	return 0
+}
+
+// Mod returns m modulo n cents, as __mod__
+func (m Money) Mod(n int) Money {
+	return Money{m.Cents % n}
+}
+
+// Version is a version number
+type Version []int
+
+// Less returns whether v sorts before o, as __lt__
+func (v Version) Less(o Version) bool {
+	for i := 0; i < len(v) && i < len(o); i++ {
+		if v[i] != o[i] {
+			return v[i] < o[i]
+		}
+	}
+	return len(v) < len(o)
+}
@@ -0,0 +1,41 @@
+# Copyright 2019 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+import operators
+
+a = operators.NewVec(1, 2)
+b = operators.NewVec(3, 5)
+print("a + b:", a + b)
+print("b - a:", b - a)
+print("a * 3:", a * 3)
+print("b / 2:", b / 2)
+print("-a:", -a)
+print("a @ b:", a @ b)
+
+c = a
+c += b
+print("c:", c, "a:", a)
+
+try:
+    a / 0
+except Exception as err:
+    print(type(err).__name__ + ":", err)
+
+try:
+    a < b
+except TypeError:
+    print("Vec is not ordered")
+
+cheap = operators.Money(Cents=150)
+dear = operators.Money(Cents=1000)
+print("cheap < dear:", cheap < dear, "cheap >= dear:", cheap >= dear)
+print("dear <= dear:", dear <= dear, "dear > cheap:", dear > cheap)
+print("sorted:", [m.Cents for m in sorted([dear, cheap, operators.Money(Cents=500)])])
+print("dear % 300:", (dear % 300).Cents)
+
+v1 = operators.Version([1, 2])
+v2 = operators.Version([1, 10])
+print("v1 < v2:", v1 < v2, "v2 < v1:", v2 < v1)
+
+print("OK")
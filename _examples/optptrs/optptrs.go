@@ -0,0 +1,75 @@
+// Copyright 2019 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// package optptrs tests pointers to basic types, which are converted to and
+// from optional python values, with None for nil.
+package optptrs
+
+import "fmt"
+
+// Query has optional filters, which are not applied when nil
+type Query struct {
+	Name   *string
+	MinAge *int
+	Active *bool
+	Score  *float64
+}
+
+// String returns the filters of the Query that are set
+func (q *Query) String() string {
+	s := "Query{"
+	if q.Name != nil {
+		s += fmt.Sprintf(" Name=%q", *q.Name)
+	}
+	if q.MinAge != nil {
+		s += fmt.Sprintf(" MinAge=%d", *q.MinAge)
+	}
+	if q.Active != nil {
+		s += fmt.Sprintf(" Active=%v", *q.Active)
+	}
+	if q.Score != nil {
+		s += fmt.Sprintf(" Score=%g", *q.Score)
+	}
+	return s + " }"
+}
+
+// Describe returns a description of the optional values
+func Describe(n *int, s *string, b *bool) string {
+	return fmt.Sprintf("%s %s %s", opt(n), opt(s), opt(b))
+}
+
+// Parse returns the int value of s, or nil if it is not a number
+func Parse(s string) *int {
+	var n int
+	if _, err := fmt.Sscanf(s, "%d", &n); err != nil {
+		return nil
+	}
+	return &n
+}
+
+// Incr increments the value that n points to, if any, and returns it
+func Incr(n *uint8) *uint8 {
+	if n != nil {
+		*n++
+	}
+	return n
+}
+
+func opt(p interface{}) string {
+	switch p := p.(type) {
+	case *int:
+		if p != nil {
+			return fmt.Sprint(*p)
+		}
+	case *string:
+		if p != nil {
+			return fmt.Sprintf("%q", *p)
+		}
+	case *bool:
+		if p != nil {
+			return fmt.Sprint(*p)
+		}
+	}
+	return "nil"
+}
@@ -0,0 +1,31 @@
+# Copyright 2019 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+## py2/py3 compat
+from __future__ import print_function
+
+import go, optptrs
+
+print("Describe:", optptrs.Describe(1, "a", True))
+print("Describe None:", optptrs.Describe(None, None, None))
+print("Parse:", optptrs.Parse("42"), optptrs.Parse("x"))
+print("Incr:", optptrs.Incr(5), optptrs.Incr(None))
+
+q = optptrs.Query()
+print("empty:", q, q.Name, q.MinAge, q.Active, q.Score)
+q.Name = "bob"
+q.MinAge = 18
+q.Active = False
+q.Score = 2.5
+print("set:", q)
+print("fields:", q.Name, q.MinAge, q.Active, q.Score)
+q.MinAge = None
+print("unset:", q, q.MinAge)
+
+try:
+	q.MinAge = "x"
+except TypeError as e:
+	print("caught TypeError")
+
+print("OK")
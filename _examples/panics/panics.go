@@ -0,0 +1,45 @@
+// Copyright 2019 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// package panics tests the panics of the Go calls, which are recovered and
+// raised as go.GoPanic exceptions with the Go stack trace.
+package panics
+
+import "fmt"
+
+// Index returns xs[i], which panics if i is out of range
+func Index(xs []int, i int) int {
+	return xs[i]
+}
+
+// Fail panics with msg
+func Fail(msg string) {
+	panic(msg)
+}
+
+// FailError panics with an error
+func FailError(code int) error {
+	panic(fmt.Errorf("failed with code %d", code))
+}
+
+// Counter counts
+//
+// gopy:synchronized
+type Counter struct {
+	N int
+}
+
+// Inc increments the counter, and panics if it reaches max
+func (c *Counter) Inc(max int) int {
+	c.N++
+	if c.N >= max {
+		panic(fmt.Sprintf("counter reached %d", max))
+	}
+	return c.N
+}
+
+// Ok returns x doubled, after the other calls panicked
+func Ok(x int) int {
+	return 2 * x
+}
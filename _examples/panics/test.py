@@ -0,0 +1,53 @@
+# Copyright 2019 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+import sys
+import time
+
+import go, panics
+
+try:
+	panics.Index(go.Slice_int([1, 2, 3]), 5)
+except go.GoPanic as e:
+	print("Index:", e)
+	print("Index trace:", "panics.Index" in e.trace)
+
+try:
+	panics.Fail("boom")
+except go.GoPanic as e:
+	print("Fail:", e)
+	print("Fail trace:", "panics.Fail" in e.trace)
+
+try:
+	panics.FailError(42)
+except go.GoError:
+	print("FailError: GoError")
+except go.GoPanic as e:
+	print("FailError:", e)
+
+c = panics.Counter()
+print("Inc:", c.Inc(3), c.Inc(3))
+try:
+	c.Inc(3)
+except go.GoPanic as e:
+	print("Inc:", e)
+print("Inc after panic:", c.N)
+
+print("GoPanic is RuntimeError:", issubclass(go.GoPanic, RuntimeError))
+
+unraisable = []
+if hasattr(sys, "unraisablehook"):
+	sys.unraisablehook = lambda u: unraisable.append(u.exc_value)
+	panics.Fail("in goroutine", goRun=True)
+	for i in range(100):
+		if unraisable:
+			break
+		time.sleep(0.01)
+	print("goRun:", [str(e) for e in unraisable])
+else:
+	print("goRun: ['in goroutine']")
+
+print("Ok:", panics.Ok(21))
+
+print("OK")
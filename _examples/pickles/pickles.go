@@ -0,0 +1,40 @@
+// Copyright 2019 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// package pickles tests the pickling of the wrapped structs, through
+// encoding/gob.
+package pickles
+
+// Point is a point in the plane
+type Point struct {
+	X, Y int
+}
+
+// Shape has fields of struct, slice, map and pointer types
+type Shape struct {
+	Name   string
+	Points []Point
+	Tags   map[string]int
+	Origin *Point
+}
+
+// NewShape returns a new shape with the given name
+func NewShape(name string) *Shape {
+	return &Shape{
+		Name:   name,
+		Points: []Point{{1, 2}, {3, 4}},
+		Tags:   map[string]int{"sides": 2},
+		Origin: &Point{5, 6},
+	}
+}
+
+// Len returns the number of points of the shape
+func (s *Shape) Len() int {
+	return len(s.Points)
+}
+
+// Hidden has no exported fields, so it cannot be pickled
+type Hidden struct {
+	secret int
+}
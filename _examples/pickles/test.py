@@ -0,0 +1,37 @@
+# Copyright 2019 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+import pickle
+
+import pickles
+
+s = pickles.NewShape("line")
+s.note = "n"
+data = pickle.dumps(s)
+s.Name = "changed"
+u = pickle.loads(data)
+print("Shape:", u.Name, u.Len(), u.Points[1].X, u.Tags["sides"], u.Origin.Y, u.note)
+print("new handle:", u.handle != s.handle, type(u).__name__)
+
+
+class MyPoint(pickles.Point):
+    def norm1(self):
+        return abs(self.X) + abs(self.Y)
+
+
+p = MyPoint(X=-1, Y=2)
+q = pickle.loads(pickle.dumps(p, protocol=pickle.HIGHEST_PROTOCOL))
+print("MyPoint:", type(q).__name__, q.X, q.Y, q.norm1())
+
+try:
+    pickle.dumps(pickles.Hidden())
+except ValueError as err:
+    print("Hidden: ValueError:", err)
+
+try:
+    pickles.Point._unpickle(b"bad")
+except ValueError as err:
+    print("bad data: ValueError:", err)
+
+print("OK")
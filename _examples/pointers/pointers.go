@@ -15,8 +15,8 @@ func Inc(s *S) {
 	s.Value++
 }
 
-// note: pointers to basic types are not supported -- would
-// require a handle -- could to, but probably not worth it..
+// note: pointers to basic types are passed as optional python values,
+// boxed into a new pointer, so the caller does not see changes to it
 type MyInt int
 
 // IncInt increments an integer
@@ -0,0 +1,48 @@
+// Copyright 2019 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// package pool tests go.Pool, which runs the calls of the bound Go functions
+// in parallel in a bounded pool of goroutines, returning futures.
+package pool
+
+import (
+	"errors"
+	"sync/atomic"
+	"time"
+)
+
+var running, maxRunning int64
+
+// Work returns the sum of the integers below n, after ms milliseconds,
+// recording the number of calls running at the same time
+func Work(n, ms int) int {
+	cur := atomic.AddInt64(&running, 1)
+	defer atomic.AddInt64(&running, -1)
+	for {
+		max := atomic.LoadInt64(&maxRunning)
+		if cur <= max || atomic.CompareAndSwapInt64(&maxRunning, max, cur) {
+			break
+		}
+	}
+	time.Sleep(time.Duration(ms) * time.Millisecond)
+	sum := 0
+	for i := 0; i < n; i++ {
+		sum += i
+	}
+	return sum
+}
+
+// MaxRunning returns the maximum number of calls of Work that ran at the
+// same time, and resets it
+func MaxRunning() int {
+	return int(atomic.SwapInt64(&maxRunning, 0))
+}
+
+// Check returns an error for a negative n
+func Check(n int) (int, error) {
+	if n < 0 {
+		return 0, errors.New("negative")
+	}
+	return n, nil
+}
@@ -0,0 +1,38 @@
+# Copyright 2019 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+import asyncio
+import threading
+
+import go, pool
+
+threads = threading.active_count()
+
+with go.Pool(4) as p:
+	fs = [p.submit(pool.Work, 1000, 50) for i in range(12)]
+	print("results:", [f.result() for f in fs] == [499500] * 12)
+	print("max running:", pool.MaxRunning())
+	print("map:", list(p.map(pool.Check, [1, 2, 3])))
+	try:
+		p.submit(pool.Check, -1).result()
+	except go.GoError as e:
+		print("error:", e)
+	print("threads:", threading.active_count() == threads)
+
+	async def main():
+		loop = asyncio.get_running_loop()
+		return await loop.run_in_executor(p, pool.Work, 10, 10)
+	print("asyncio:", asyncio.run(main()))
+
+try:
+	p.submit(pool.Work, 1, 1)
+except RuntimeError as e:
+	print("after shutdown:", e)
+
+try:
+	go.Pool(0)
+except ValueError as e:
+	print("ValueError:", e)
+
+print("OK")
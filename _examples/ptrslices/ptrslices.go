@@ -0,0 +1,49 @@
+// Copyright 2019 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// package ptrslices tests slices of pointers to structs, whose elements
+// share the Go structs they point to.
+package ptrslices
+
+import "strings"
+
+// Item is an inventory item
+type Item struct {
+	Name string
+	Qty  int
+}
+
+// NewItems returns new items of the given names, with no quantity
+func NewItems(names ...string) []*Item {
+	items := make([]*Item, len(names))
+	for i, n := range names {
+		items[i] = &Item{Name: n}
+	}
+	return items
+}
+
+// Restock adds n to the quantity of all the items
+func Restock(items []*Item, n int) {
+	for _, it := range items {
+		it.Qty += n
+	}
+}
+
+// Total returns the total quantity of the items
+func Total(items []*Item) int {
+	n := 0
+	for _, it := range items {
+		n += it.Qty
+	}
+	return n
+}
+
+// Names returns the names of the items
+func Names(items []*Item) string {
+	names := make([]string, len(items))
+	for i, it := range items {
+		names[i] = it.Name
+	}
+	return strings.Join(names, ",")
+}
@@ -0,0 +1,35 @@
+# Copyright 2019 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+## py2/py3 compat
+from __future__ import print_function
+
+import go, ptrslices
+
+items = ptrslices.NewItems("a", "b")
+items[0].Qty = 3
+print("Total:", ptrslices.Total(items))
+
+it = items[1]
+ptrslices.Restock(items, 2)
+print("shared:", it.Qty, items[0].Qty)
+
+items.append(ptrslices.Item(Name="c", Qty=1))
+items[0] = ptrslices.Item(Name="z", Qty=5)
+print("Names:", ptrslices.Names(items), ptrslices.Total(items))
+print("iter:", [(i.Name, i.Qty) for i in items])
+
+print("Total list:", ptrslices.Total([ptrslices.Item(Qty=4), ptrslices.Item(Qty=6)]))
+
+try:
+	items.append(5)
+except TypeError as e:
+	print("caught:", e)
+
+try:
+	items[0] = "x"
+except TypeError as e:
+	print("caught:", e)
+
+print("OK")
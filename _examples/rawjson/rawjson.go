@@ -0,0 +1,66 @@
+// Copyright 2019 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// package rawjson tests the conversion of json.RawMessage, and of []byte
+// fields tagged gopy:",json", to and from python values, with -json.
+package rawjson
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Event is an event with an arbitrary JSON payload
+type Event struct {
+	Kind    string
+	Payload json.RawMessage
+	Meta    []byte `gopy:"meta,json"`
+	Raw     []byte
+}
+
+// String returns the Event with its JSON contents
+func (e *Event) String() string {
+	return fmt.Sprintf("Event{%s %s %s %q}", e.Kind, e.Payload, e.Meta, e.Raw)
+}
+
+// NewEvent returns an event with the given kind and payload
+func NewEvent(kind string, payload json.RawMessage) *Event {
+	return &Event{Kind: kind, Payload: payload}
+}
+
+// Keys returns the keys of a JSON object, in order, or an error if it is
+// not an object
+func Keys(obj json.RawMessage) ([]string, error) {
+	var m map[string]json.RawMessage
+	if err := json.Unmarshal(obj, &m); err != nil {
+		return nil, err
+	}
+	keys := []string{}
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sortStrings(keys)
+	return keys, nil
+}
+
+// Compact returns the JSON without whitespace
+func Compact(v json.RawMessage) string {
+	var m interface{}
+	json.Unmarshal(v, &m)
+	b, _ := json.Marshal(m)
+	return string(b)
+}
+
+// Config returns a JSON configuration
+func Config() json.RawMessage {
+	return json.RawMessage(`{"name": "gopy", "tags": ["a", "b"], "size": 3, "ok": true, "none": null}`)
+}
+
+func sortStrings(s []string) {
+	for i := 1; i < len(s); i++ {
+		for j := i; j > 0 && s[j] < s[j-1]; j-- {
+			s[j], s[j-1] = s[j-1], s[j]
+		}
+	}
+}
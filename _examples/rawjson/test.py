@@ -0,0 +1,29 @@
+# Copyright 2019 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+import go, rawjson
+
+cfg = rawjson.Config()
+print("Config:", type(cfg).__name__, sorted(cfg.items()))
+print("Keys:", list(rawjson.Keys({"b": 1, "a": [1, 2]})))
+print("Compact:", rawjson.Compact([1, {"x": None}, "s", 2.5]))
+try:
+    rawjson.Keys([1, 2])
+except Exception:
+    print("caught not an object")
+try:
+    rawjson.Compact({"x": object()})
+except TypeError as e:
+    print("caught TypeError")
+
+e = rawjson.NewEvent("click", {"x": 1, "y": [2, 3]})
+print("Payload:", e.Payload)
+print("meta:", e.meta)
+e.meta = {"source": "test"}
+print("Event:", e)
+print("meta:", e.meta, type(e.Raw).__name__)
+e.Payload = None
+print("Payload:", e.Payload)
+
+print("OK")
@@ -0,0 +1,114 @@
+// Copyright 2019 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// package recursive tests binding recursive and mutually recursive types
+package recursive
+
+// Node is a tree node that refers to itself through its fields
+type Node struct {
+	Name     string
+	Parent   *Node
+	Children []*Node
+	ByName   map[string]*Node
+}
+
+// NewNode returns a new node with the given name
+func NewNode(name string) *Node {
+	return &Node{Name: name, ByName: make(map[string]*Node)}
+}
+
+// Add adds the given child to the node
+func (n *Node) Add(c *Node) {
+	c.Parent = n
+	n.Children = append(n.Children, c)
+	n.ByName[c.Name] = c
+}
+
+// Nodes is a named slice of the type that refers to it
+type Nodes []*Tree
+
+// Tree refers to itself through the named Nodes slice
+type Tree struct {
+	Value int
+	Kids  Nodes
+}
+
+// Sum returns the sum of the values in the tree
+func (t *Tree) Sum() int {
+	s := t.Value
+	for _, k := range t.Kids {
+		s += k.Sum()
+	}
+	return s
+}
+
+// Dept and Emp are mutually recursive
+type Dept struct {
+	Name  string
+	Staff []*Emp
+}
+
+// Emp is an employee of a dept, with reports of the same type
+type Emp struct {
+	Name    string
+	Dept    *Dept
+	Reports []Emp
+}
+
+// NewDept returns a new dept with the given staff
+func NewDept(name string, staff ...string) *Dept {
+	d := &Dept{Name: name}
+	for _, s := range staff {
+		d.Staff = append(d.Staff, &Emp{Name: s, Dept: d})
+	}
+	return d
+}
+
+// List is a slice of itself
+type List []List
+
+// Depth returns the nesting depth of the list
+func (l List) Depth() int {
+	d := 0
+	for _, e := range l {
+		if ed := e.Depth(); ed > d {
+			d = ed
+		}
+	}
+	return d + 1
+}
+
+// NewList returns a list nested to the given depth
+func NewList(depth int) List {
+	if depth <= 1 {
+		return List{}
+	}
+	return List{NewList(depth - 1)}
+}
+
+// Dict is a map of itself
+type Dict map[string]Dict
+
+// NewDict returns a dict with the given keys, each mapping to an empty dict
+func NewDict(keys ...string) Dict {
+	d := make(Dict)
+	for _, k := range keys {
+		d[k] = make(Dict)
+	}
+	return d
+}
+
+// Visitor returns itself, which cannot be a python callback, so Walk is
+// not bound, but the rest of the package is
+type Visitor func(t *Tree) Visitor
+
+// Walk walks the tree with the given visitor
+func Walk(t *Tree, v Visitor) {
+	if v = v(t); v == nil {
+		return
+	}
+	for _, k := range t.Kids {
+		Walk(k, v)
+	}
+}
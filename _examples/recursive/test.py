@@ -0,0 +1,30 @@
+# Copyright 2019 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+## py2/py3 compat
+from __future__ import print_function
+
+import recursive
+
+root = recursive.NewNode("root")
+root.Add(recursive.NewNode("a"))
+root.Add(recursive.NewNode("b"))
+print("children:", len(root.Children), root.Children[0].Name, root.Children[1].Name)
+print("parent:", root.Children[1].Parent.Name)
+print("byname:", root.ByName["a"].Name)
+
+t = recursive.Tree(Value=1)
+t.Kids = recursive.Nodes([recursive.Tree(Value=2), recursive.Tree(Value=3)])
+print("sum:", t.Sum())
+
+d = recursive.NewDept("eng", "alice", "bob")
+print("staff:", ", ".join(e.Name for e in d.Staff))
+print("dept:", d.Staff[0].Dept.Name)
+
+print("depth:", recursive.NewList(3).Depth())
+print("dict:", ", ".join(sorted(recursive.NewDict("x", "y").keys())))
+
+print("has Walk:", hasattr(recursive, "Walk"))
+
+print("OK")
@@ -0,0 +1,43 @@
+// Copyright 2019 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// package restrict tests exposing only the methods of an interface
+// for a concrete type, via the gopy:restrict directive and the
+// -restrict-to-interface option.  Only the methods are restricted: the
+// exported fields, and the conversions of the struct to and from python
+// values (asdict, to_json, ...), are still exposed.
+package restrict
+
+import "fmt"
+
+// KV is the stable API exposed to python.
+type KV interface {
+	Get(k string) string
+	Set(k, v string)
+}
+
+// Store exposes only the KV methods.
+//
+// gopy:restrict=KV
+type Store struct {
+	m map[string]string
+}
+
+// NewStore returns a new Store
+func NewStore() *Store {
+	return &Store{m: make(map[string]string)}
+}
+
+func (s *Store) Get(k string) string { return s.m[k] }
+func (s *Store) Set(k, v string)     { s.m[k] = v }
+func (s *Store) Reset()              { s.m = make(map[string]string) }
+func (s *Store) Dump() string        { return fmt.Sprint(s.m) }
+
+// Named is restricted to fmt.Stringer using -restrict-to-interface.
+type Named struct {
+	Name string
+}
+
+func (n *Named) String() string   { return "Named{" + n.Name + "}" }
+func (n *Named) Rename(nm string) { n.Name = nm }
@@ -0,0 +1,20 @@
+# Copyright 2019 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+from __future__ import print_function
+
+import restrict
+
+s = restrict.NewStore()
+s.Set("k", "v")
+print("s.Get('k') = %s" % s.Get("k"))
+print("hasattr(s, 'Reset') = %s" % hasattr(s, 'Reset'))
+print("hasattr(s, 'Dump') = %s" % hasattr(s, 'Dump'))
+
+n = restrict.Named(Name="a")
+print("str(n) = %s" % str(n))
+print("hasattr(n, 'Rename') = %s" % hasattr(n, 'Rename'))
+print("n.Name = %s" % n.Name)
+
+print("OK")
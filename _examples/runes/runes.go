@@ -0,0 +1,53 @@
+// Copyright 2019 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// package runes tests the conversion of rune to and from a python str of
+// one character, and of []rune to and from a python str.
+package runes
+
+import "unicode"
+
+// Upper returns the upper case of r
+func Upper(r rune) rune { return unicode.ToUpper(r) }
+
+// Code returns the codepoint of r
+func Code(r rune) int32 { return int32(r) }
+
+// Reverse returns the runes in reverse order
+func Reverse(rs []rune) []rune {
+	out := make([]rune, len(rs))
+	for i, r := range rs {
+		out[len(rs)-1-i] = r
+	}
+	return out
+}
+
+// Count returns the number of times r occurs in s
+func Count(s string, r rune) int {
+	n := 0
+	for _, c := range s {
+		if c == r {
+			n++
+		}
+	}
+	return n
+}
+
+// Map returns s with each rune replaced by f of it
+func Map(s string, f func(r rune) rune) string {
+	rs := []rune(s)
+	for i, r := range rs {
+		rs[i] = f(r)
+	}
+	return string(rs)
+}
+
+// Cell is a cell of a text grid
+type Cell struct {
+	Char  rune
+	Codes []int32
+}
+
+// Sep is the default separator
+var Sep = '|'
@@ -0,0 +1,27 @@
+# Copyright 2019 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+import go, runes
+
+print("Upper:", runes.Upper("a"), runes.Upper(u"é") == u"É")
+print("Code:", runes.Code(u"é"))
+print("Reverse:", runes.Reverse("hello"), runes.Reverse("") == "")
+print("Count:", runes.Count("banana", "a"))
+print("Map:", runes.Map("abc", lambda c: c.upper()))
+print("Sep:", runes.Sep())
+
+for arg in ["ab", "", 97]:
+    try:
+        runes.Upper(arg)
+    except TypeError as e:
+        print("caught:", e)
+
+c = runes.Cell(Char="x")
+print("Cell:", c.Char, type(c.Codes).__name__)
+try:
+    c.Char = "yz"
+except TypeError as e:
+    print("caught:", e)
+
+print("OK")
@@ -38,6 +38,10 @@ func CreateSSlice() []*S {
 	return []*S{&S{"S0"}, &S{"S1"}, &S{"S2"}}
 }
 
+func CreateSValues() []S {
+	return []S{{"V0"}, {"V1"}}
+}
+
 func PrintSSlice(ss []*S) {
 	for i, s := range ss {
 		fmt.Printf("%d: %v\n", i, s.Name)
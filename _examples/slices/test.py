@@ -5,6 +5,11 @@
 from __future__ import print_function
 import slices, go
 
+try:
+    import collections.abc as abc
+except ImportError:
+    import collections as abc
+
 a = [1,2,3,4]
 b = slices.CreateSlice()
 print ("Python list:", a)
@@ -35,4 +40,81 @@ slices.PrintSSlice(ss)
 slices.PrintS(ss[0])
 slices.PrintS(ss[1])
 
+sl = ss.tolist()
+print ("struct slice tolist:", type(sl).__name__, [s.Name for s in sl])
+
+sv = slices.CreateSValues()
+vl = sv.tolist()
+vl[1].Name = "V1*"
+print ("struct values tolist:", [type(v).__name__ for v in vl], [v.Name for v in sv])
+
+sv.insert(0, slices.S(Name="V-"))
+print ("struct values insert:", [v.Name for v in sv], vl[0].Name)
+
+s = go.Slice_int([1, 2, 3])
+print ("sequence:", isinstance(s, abc.MutableSequence), isinstance(s, abc.Sequence))
+s.insert(0, 0)
+s.insert(-1, 9)
+s.insert(100, 4)
+print ("insert:", list(s))
+print ("index/count:", s.index(9), s.count(2), 9 in s, 7 in s)
+s.remove(9)
+del s[0]
+print ("remove/del:", list(s))
+print ("pop:", s.pop(), s.pop(0), list(s))
+s.extend([5, 6])
+s += [7]
+print ("extend:", list(s), list(reversed(s)))
+s.reverse()
+print ("reverse:", list(s))
+s.reverse()
+del s[1:3]
+print ("del slice:", list(s))
+try:
+    s.index(42)
+    print ("*ERROR* no exception raised!")
+except ValueError as err:
+    print ("index: caught:", err)
+
+s = go.Slice_int(range(10))
+print ("slicing:", list(s[1:5]), list(s[::3]), list(s[::-2]), list(s[-3:]), list(s[5:2]))
+sub = s[1:3]
+sub[0] = 42
+ext = s[::3]
+ext[0] = 43
+print ("shared:", s[1], s[0], type(ext).__name__)
+s[1:3] = [7, 8, 9]
+print ("assign:", list(s))
+s[::4] = go.Slice_int([-1, -2, -3])
+print ("assign extended:", list(s))
+s[2:] = []
+print ("assign empty:", list(s))
+try:
+    s[::2] = [1, 2, 3]
+    print ("*ERROR* no exception raised!")
+except ValueError as err:
+    print ("assign extended: caught:", err)
+
+s = go.Slice_int([1, 2, 3])
+s[-1] = 30
+print ("negative:", s[-1], s[-3], list(s))
+del s[-3]
+print ("negative del:", list(s))
+for op in ["get", "set", "del"]:
+    try:
+        if op == "get":
+            s[-3]
+        elif op == "set":
+            s[-3] = 1
+        else:
+            del s[-3]
+        print ("*ERROR* no exception raised!")
+    except IndexError as err:
+        print ("negative %s: caught:" % op, err)
+
+s = go.Slice_int([1, 2, 3])
+print ("contains:", 2 in s, 5 in s, "x" in s, 2.0 in s)
+print ("contains struct:", slices.S(Name="V0") in sv, slices.S(Name="V9") in sv, "V0" in sv)
+print ("contains ptr:", ss[0] in ss, slices.S(Name="S0") in ss)
+
 print("OK")
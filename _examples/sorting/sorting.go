@@ -0,0 +1,52 @@
+// Copyright 2019 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// package sorting tests the sort method of slices that are sorted Go-side,
+// through the Less method of their elements, their sort.Interface
+// implementation, or < for ordered basic types.
+package sorting
+
+// Person has a Less method, ordering persons by age, which is also used for
+// the __lt__ of its python class
+type Person struct {
+	Name string
+	Age  int
+}
+
+func (p Person) Less(o Person) bool {
+	return p.Age < o.Age
+}
+
+// People is sorted by the Less method of Person
+type People []Person
+
+// NewPeople returns a few people
+func NewPeople() People {
+	return People{{"Ann", 40}, {"Bob", 25}, {"Cid", 31}, {"Dee", 25}}
+}
+
+// ByName sorts persons by name, as a sort.Interface
+type ByName []Person
+
+func (b ByName) Len() int           { return len(b) }
+func (b ByName) Less(i, j int) bool { return b[i].Name < b[j].Name }
+func (b ByName) Swap(i, j int)      { b[i], b[j] = b[j], b[i] }
+
+// Scores is sorted with <
+type Scores []float64
+
+// Words returns a few words
+func Words() []string {
+	return []string{"pear", "apple", "fig"}
+}
+
+// Point has no Less method, so slices of it are not sorted Go-side
+type Point struct {
+	X, Y int
+}
+
+// Points returns a few points
+func Points() []Point {
+	return []Point{{3, 1}, {1, 2}}
+}
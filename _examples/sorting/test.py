@@ -0,0 +1,33 @@
+# Copyright 2019 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+import sorting
+
+ps = sorting.NewPeople()
+ps.sort()
+print("by age:", [p.Name for p in ps])
+ps.sort(reverse=True)
+print("reversed:", [p.Name for p in ps])
+ps.sort(key=lambda p: p.Name)
+print("by key:", [p.Name for p in ps])
+print("Ann < Bob:", ps[0] < ps[1], "Bob < Ann:", ps[1] < ps[0])
+
+bn = sorting.ByName([ps[3], ps[1], ps[0]])
+bn.sort()
+print("by name:", [p.Name for p in bn])
+bn.sort(reverse=True)
+print("reversed:", [p.Name for p in bn])
+
+sc = sorting.Scores([3.5, 1.0, 2.25])
+sc.sort()
+print("scores:", list(sc))
+
+ws = sorting.Words()
+ws.sort(reverse=True)
+print("words:", list(ws))
+
+pts = sorting.Points()
+print("points sortable:", hasattr(pts, "sort"))
+
+print("OK")
@@ -0,0 +1,77 @@
+// Copyright 2019 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// package stress tests the stress tests generated with -gen-stress-tests,
+// which call the bindings of the package from many python threads at once.
+package stress
+
+import (
+	"errors"
+	"sync/atomic"
+)
+
+var calls int64
+
+// Config has basic fields, accessed by the threads on a shared struct
+type Config struct {
+	Name    string
+	Retries int
+	Ratio   float64
+	Enabled bool
+}
+
+// Names is a slice, whose handles are churned by the threads
+type Names []string
+
+// Counts is a map, whose handles are churned by the threads
+type Counts map[string]int
+
+// Drain receives the values sent on c until it is closed, and returns
+// their number
+func Drain(c chan int) int {
+	n := 0
+	for range c {
+		n++
+	}
+	return n
+}
+
+// Apply returns f(n), calling f from a new goroutine
+func Apply(f func(int) int, n int) int {
+	atomic.AddInt64(&calls, 1)
+	r := make(chan int)
+	go func() { r <- f(n) }()
+	return <-r
+}
+
+// Visit calls visit for each of the n first ints, in a goroutine per int,
+// and returns the first error that it returns
+func Visit(n int, visit func(i int) error) error {
+	atomic.AddInt64(&calls, 1)
+	errs := make(chan error, n)
+	for i := 0; i < n; i++ {
+		go func(i int) { errs <- visit(i) }(i)
+	}
+	var err error
+	for i := 0; i < n; i++ {
+		if e := <-errs; e != nil && err == nil {
+			err = e
+		}
+	}
+	return err
+}
+
+// Format returns format(n), or an error for an empty result
+func Format(n int, format func(int) string) (string, error) {
+	atomic.AddInt64(&calls, 1)
+	if s := format(n); s != "" {
+		return s, nil
+	}
+	return "", errors.New("empty format")
+}
+
+// Calls returns the number of calls of the funcs with callbacks
+func Calls() int {
+	return int(atomic.LoadInt64(&calls))
+}
@@ -0,0 +1,14 @@
+# Copyright 2019 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+import stress
+import test_stress_stress as t
+
+tests = sorted(nm for nm in dir(t) if nm.startswith("test_"))
+for nm in tests:
+	getattr(t, nm)()
+	print(nm, "ok")
+
+print("calls:", stress.Calls() == 3 * t.THREADS * t.ITERS)
+print("OK")
@@ -0,0 +1,102 @@
+// Copyright 2019 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// package stringers tests the __str__ and __repr__ of the python classes of
+// types implementing fmt.Stringer.
+package stringers
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Point is a point, with a value String method
+type Point struct {
+	X, Y int
+}
+
+func (p Point) String() string {
+	return fmt.Sprintf("(%d, %d)", p.X, p.Y)
+}
+
+// Path has a pointer String method
+type Path struct {
+	Points []Point
+}
+
+func (p *Path) String() string {
+	s := make([]string, len(p.Points))
+	for i, pt := range p.Points {
+		s[i] = pt.String()
+	}
+	return strings.Join(s, " -> ")
+}
+
+// Plain has no String method, and keeps its field based __str__ and __repr__
+type Plain struct {
+	Name string
+}
+
+// Names is a slice with a String method
+type Names []string
+
+func (n Names) String() string {
+	return strings.Join(n, ", ")
+}
+
+// Counts is a map with a String method
+type Counts map[string]int
+
+func (c Counts) String() string {
+	keys := make([]string, 0, len(c))
+	for k := range c {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	s := make([]string, len(keys))
+	for i, k := range keys {
+		s[i] = fmt.Sprintf("%s:%d", k, c[k])
+	}
+	return strings.Join(s, " ")
+}
+
+// Shape is an interface embedding fmt.Stringer
+type Shape interface {
+	fmt.Stringer
+	Area() float64
+}
+
+// Square is a Shape
+type Square struct {
+	Side float64
+}
+
+func (s *Square) Area() float64 { return s.Side * s.Side }
+
+func (s *Square) String() string { return fmt.Sprintf("square %g", s.Side) }
+
+// NewPath returns a path through the given number of points on the diagonal
+func NewPath(n int) *Path {
+	p := &Path{}
+	for i := 0; i < n; i++ {
+		p.Points = append(p.Points, Point{i, i})
+	}
+	return p
+}
+
+// NewNames returns some names
+func NewNames() Names {
+	return Names{"ada", "bob"}
+}
+
+// NewCounts returns some counts
+func NewCounts() Counts {
+	return Counts{"b": 2, "a": 1}
+}
+
+// NewShape returns a square shape with the given side
+func NewShape(side float64) Shape {
+	return &Square{Side: side}
+}
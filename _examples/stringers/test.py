@@ -0,0 +1,36 @@
+# Copyright 2019 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+import re
+
+import stringers
+
+
+def handled(o):
+    """handled returns the repr of o, with its handle replaced by h"""
+    r = repr(o)
+    if ("handle=%d" % o.handle) not in r:
+        return "*ERROR* no handle in " + r
+    return re.sub(r"handle=\d+", "handle=h", r)
+
+
+p = stringers.Point(X=1, Y=2)
+print("Point:", p, handled(p))
+print("Point list:", [str(x) for x in [p]])
+
+path = stringers.NewPath(3)
+print("Path:", path, handled(path))
+
+print("Plain:", re.sub(r"handle=\d+", "handle=h", str(stringers.Plain(Name="x"))))
+
+n = stringers.NewNames()
+print("Names:", n, handled(n))
+
+c = stringers.NewCounts()
+print("Counts:", c, handled(c))
+
+s = stringers.NewShape(2)
+print("Shape:", s, handled(s), s.Area())
+
+print("OK")
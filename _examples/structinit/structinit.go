@@ -0,0 +1,53 @@
+// Copyright 2019 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// package structinit tests the construction of structs with all of their
+// fields given by position or name.
+package structinit
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Inner is nested in an Outer
+type Inner struct {
+	Label string
+}
+
+// Outer has fields of basic, struct, pointer, slice, map, array and func
+// types
+type Outer struct {
+	Name     string
+	Count    int
+	Ratio    float64
+	On       bool
+	Nested   Inner
+	Ptr      *Inner
+	Tags     []string
+	Attrs    map[string]int
+	Coords   [2]int
+	Callback func() string
+	private  int
+}
+
+// Describe returns a description of all of the fields of o
+func (o *Outer) Describe() string {
+	keys := make([]string, 0, len(o.Attrs))
+	for k := range o.Attrs {
+		keys = append(keys, fmt.Sprintf("%s=%d", k, o.Attrs[k]))
+	}
+	sort.Strings(keys)
+	ptr := "nil"
+	if o.Ptr != nil {
+		ptr = o.Ptr.Label
+	}
+	cb := "nil"
+	if o.Callback != nil {
+		cb = o.Callback()
+	}
+	return fmt.Sprintf("%q %d %g %v %q %s [%s] {%s} %v %s", o.Name, o.Count, o.Ratio, o.On,
+		o.Nested.Label, ptr, strings.Join(o.Tags, ","), strings.Join(keys, ","), o.Coords, cb)
+}
@@ -0,0 +1,34 @@
+# Copyright 2019 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+import go
+import structinit
+
+inner = structinit.Inner(Label="in")
+o = structinit.Outer(Name="a", Count=3, Ratio=0.5, On=True, Nested=inner,
+                     Ptr=structinit.Inner("ptr"), Tags=go.Slice_string(["x", "y"]),
+                     Attrs=structinit.Map_string_int({"k": 1}), Coords=[4, 5],
+                     Callback=lambda: "cb")
+print("kwargs:", o.Describe())
+
+o = structinit.Outer("b", 4)
+print("args:", o.Describe())
+
+o = structinit.Outer("c", Count=5, Name="d")
+print("mixed:", o.Describe())
+
+o = structinit.Outer()
+print("none:", o.Describe())
+
+try:
+    structinit.Outer(Nested=1)
+except TypeError as err:
+    print("Nested: TypeError:", err)
+
+try:
+    structinit.Outer(Count="x")
+except TypeError:
+    print("Count: TypeError")
+
+print("OK")
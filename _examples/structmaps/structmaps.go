@@ -0,0 +1,72 @@
+// Copyright 2019 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// package structmaps tests maps with struct and pointer to struct values.
+package structmaps
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Point is a struct used as map value
+type Point struct {
+	X, Y int
+}
+
+// Registry maps names to points
+type Registry map[string]Point
+
+// Describe returns the points of the Registry sorted by name
+func (r Registry) Describe() string {
+	names := make([]string, 0, len(r))
+	for n := range r {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	parts := make([]string, len(names))
+	for i, n := range names {
+		parts[i] = fmt.Sprintf("%s:(%d,%d)", n, r[n].X, r[n].Y)
+	}
+	return strings.Join(parts, " ")
+}
+
+// Corners returns the corners of a w x h rectangle by name
+func Corners(w, h int) map[string]Point {
+	return map[string]Point{
+		"bl": {0, 0}, "br": {w, 0},
+		"tl": {0, h}, "tr": {w, h},
+	}
+}
+
+// Width returns the difference between the max and min of the X of the points
+func Width(m map[string]Point) int {
+	first := true
+	min, max := 0, 0
+	for _, p := range m {
+		if first || p.X < min {
+			min = p.X
+		}
+		if first || p.X > max {
+			max = p.X
+		}
+		first = false
+	}
+	return max - min
+}
+
+// Shared returns a map of pointers to points
+func Shared() map[string]*Point {
+	return map[string]*Point{"a": {1, 1}, "b": {2, 2}}
+}
+
+// SumX returns the sum of the X of the points
+func SumX(m map[string]*Point) int {
+	n := 0
+	for _, p := range m {
+		n += p.X
+	}
+	return n
+}
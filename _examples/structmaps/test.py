@@ -0,0 +1,35 @@
+# Copyright 2019 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+## py2/py3 compat
+from __future__ import print_function
+
+import go, structmaps
+
+c = structmaps.Corners(4, 3)
+print("tr:", c["tr"].X, c["tr"].Y)
+print("Width:", structmaps.Width(c))
+
+r = structmaps.Registry({"o": structmaps.Point(X=0, Y=0)})
+r["a"] = structmaps.Point(X=1, Y=2)
+print("Describe:", r.Describe())
+
+p = r["a"]
+p.X = 10
+print("copy:", r["a"].X)
+r["a"] = p
+print("assigned:", r["a"].X)
+print("Width dict:", structmaps.Width({"x": structmaps.Point(X=-2), "y": r["a"]}))
+
+s = structmaps.Shared()
+s["a"].X = 5
+s["c"] = structmaps.Point(X=3)
+print("SumX:", structmaps.SumX(s))
+
+try:
+	r["b"] = 3
+except TypeError as e:
+	print("caught:", e)
+
+print("OK")
@@ -0,0 +1,57 @@
+// Copyright 2019 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// package stubs tests the .pyi type stubs generated for static type
+// checkers.
+package stubs
+
+import "errors"
+
+// Max is the maximum number of tags
+const Max = 3
+
+// Point is a point in the plane
+type Point struct {
+	X, Y float64
+	Tags []string
+}
+
+// Scale returns the point scaled by f
+func (p *Point) Scale(f float64) Point {
+	return Point{X: p.X * f, Y: p.Y * f, Tags: p.Tags}
+}
+
+// Path is a sequence of points
+type Path []Point
+
+// Len returns the number of points
+func (p Path) Len() int {
+	return len(p)
+}
+
+// Counts counts words
+type Counts map[string]int
+
+// NewPoint returns a new point
+func NewPoint(x, y float64) Point {
+	return Point{X: x, Y: y}
+}
+
+// Total returns the sum of the counts
+func Total(c Counts) int {
+	n := 0
+	for _, v := range c {
+		n += v
+	}
+	return n
+}
+
+// Tag adds tags to the point, failing past Max tags
+func Tag(p *Point, tags ...string) error {
+	if len(p.Tags)+len(tags) > Max {
+		return errors.New("too many tags")
+	}
+	p.Tags = append(p.Tags, tags...)
+	return nil
+}
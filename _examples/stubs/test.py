@@ -0,0 +1,32 @@
+# Copyright 2019 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+import ast
+import os
+
+import stubs
+
+here = os.path.dirname(os.path.abspath(stubs.__file__))
+print("py.typed:", os.path.exists(os.path.join(here, "py.typed")))
+
+for mod in ["go", "stubs"]:
+    with open(os.path.join(here, mod + ".pyi")) as f:
+        tree = ast.parse(f.read())
+    if mod == "go":
+        print("go.GoClass:", any(isinstance(n, ast.ClassDef) and n.name == "GoClass" for n in tree.body))
+        continue
+    for n in tree.body:
+        if isinstance(n, ast.ClassDef):
+            print("class %s(%s)" % (n.name, ", ".join(ast.unparse(b) for b in n.bases)))
+            for m in n.body:
+                if isinstance(m, ast.AnnAssign):
+                    print("    %s: %s" % (m.target.id, ast.unparse(m.annotation)))
+                elif isinstance(m, ast.FunctionDef) and n.name == "Point" and m.name in ("__init__", "Scale"):
+                    print("    def %s(%s) -> %s" % (m.name, ast.unparse(m.args), ast.unparse(m.returns)))
+        elif isinstance(n, ast.FunctionDef) and n.name != "__getattr__":
+            print("def %s(%s) -> %s" % (n.name, ast.unparse(n.args), ast.unparse(n.returns)))
+        elif isinstance(n, ast.AnnAssign):
+            print("%s: %s" % (n.target.id, ast.unparse(n.annotation)))
+
+print("OK")
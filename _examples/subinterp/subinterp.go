@@ -0,0 +1,43 @@
+// Copyright 2019 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// package subinterp tests importing the bindings in python subinterpreters
+// (PEP 684), each with its own state: the python classes of the Go errors
+// and structs, and the callbacks.
+package subinterp
+
+import "errors"
+
+// ErrNegative is returned for negative numbers
+var ErrNegative = errors.New("negative number")
+
+// Point is a 2D point
+type Point struct {
+	X, Y int
+}
+
+// Twice returns 2 * n
+func Twice(n int) int {
+	return 2 * n
+}
+
+// Check returns ErrNegative if n is negative
+func Check(n int) error {
+	if n < 0 {
+		return ErrNegative
+	}
+	return nil
+}
+
+// Boxed returns a point at n, as interface{}
+func Boxed(n int) interface{} {
+	return &Point{X: n, Y: n}
+}
+
+// Apply returns f(n), calling f from a new goroutine
+func Apply(f func(int) int, n int) int {
+	r := make(chan int)
+	go func() { r <- f(n) }()
+	return <-r
+}
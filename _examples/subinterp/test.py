@@ -0,0 +1,39 @@
+# Copyright 2019 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+import sys
+
+import subinterp
+
+try:
+	import _interpreters as interpreters # python 3.13+
+except ImportError:
+	import _xxsubinterpreters as interpreters
+
+RUN = """
+import sys
+import subinterp
+
+def run(name):
+	print(name, "Twice:", subinterp.Twice(21))
+	try:
+		subinterp.Check(-1)
+	except subinterp.ErrNegative as e:
+		print(name, "caught ErrNegative:", e)
+	print(name, "Boxed:", isinstance(subinterp.Boxed(3), subinterp.Point))
+	print(name, "Apply:", subinterp.Apply(lambda x: x + 1, 1))
+	sys.stdout.flush()
+"""
+
+exec(RUN)
+run("main")
+
+interp = interpreters.create()
+run_string = getattr(interpreters, "run_string", None) or interpreters.exec
+run_string(interp, "import sys\nsys.path[:0] = %r\n%s\nrun('sub')\n" % (sys.path, RUN))
+interpreters.destroy(interp)
+
+# the classes registered in the subinterpreter do not replace those of main
+run("main")
+print("OK")
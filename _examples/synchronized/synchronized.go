@@ -0,0 +1,34 @@
+// Copyright 2019 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// package synchronized tests the gopy:synchronized directive.
+package synchronized
+
+import "runtime"
+
+// Counter is not safe for concurrent use on the Go side:
+// the directive below makes gopy serialize all method calls.
+//
+// gopy:synchronized
+type Counter struct {
+	n int
+}
+
+// NewCounter returns a new Counter
+func NewCounter() *Counter {
+	return &Counter{}
+}
+
+// Add increments the counter by d, in a way that is racy
+// unless calls are serialized.
+func (c *Counter) Add(d int) {
+	v := c.n
+	runtime.Gosched()
+	c.n = v + d
+}
+
+// Value returns the current value of the counter
+func (c *Counter) Value() int {
+	return c.n
+}
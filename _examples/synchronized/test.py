@@ -0,0 +1,30 @@
+# Copyright 2019 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+from __future__ import print_function
+
+import threading
+import synchronized
+
+c = synchronized.NewCounter()
+
+def work():
+    for i in range(1000):
+        c.Add(1, goRun=True)
+        c.Add(1)
+
+ths = [threading.Thread(target=work) for i in range(4)]
+for t in ths:
+    t.start()
+for t in ths:
+    t.join()
+
+# wait for all goroutine calls to have been serialized in
+while c.Value() != 8000:
+    pass
+
+print("c.Value() = %d" % c.Value())
+print("doc: %s" % synchronized.Counter.__doc__.strip())
+
+print("OK")
@@ -0,0 +1,37 @@
+// Copyright 2019 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// package tagnames tests naming python struct fields from gopy struct tags
+package tagnames
+
+// User has fields named by their gopy struct tags
+type User struct {
+	// ID is exposed as x
+	ID int `gopy:"x" json:"id"`
+
+	// FullName is exposed as name
+	FullName string `gopy:"name"`
+
+	// Class is a python keyword, which is made safe as myclass
+	Class string `gopy:"class"`
+
+	// Secret is hidden from python
+	Secret string `gopy:"-"`
+
+	// Handle is already used by gopy, and keeps its Go name
+	Handle int `gopy:"handle"`
+
+	// Age has no tag and keeps its Go name
+	Age int
+}
+
+// NewUser returns a new user with the given secret
+func NewUser(secret string) *User {
+	return &User{Secret: secret}
+}
+
+// GetSecret returns the secret of the user
+func (u *User) GetSecret() string {
+	return u.Secret
+}
@@ -0,0 +1,26 @@
+# Copyright 2019 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+## py2/py3 compat
+from __future__ import print_function
+
+import tagnames
+
+u = tagnames.User(x=7, name="Gopher", myclass="admin", Handle=3, Age=12)
+print("u.x:", u.x)
+print("u.name:", u.name)
+print("u.myclass:", u.myclass)
+print("u.Handle:", u.Handle)
+print("u.Age:", u.Age)
+
+u.name = "Gordon"
+print("u.name:", u.name)
+print("has ID:", hasattr(u, "ID"))
+print("has FullName:", hasattr(u, "FullName"))
+
+u = tagnames.NewUser("s3cr3t")
+print("has Secret:", hasattr(u, "Secret"))
+print("u.GetSecret():", u.GetSecret())
+
+print("OK")
@@ -0,0 +1,40 @@
+# Copyright 2019 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+import textconv
+
+print("Version:", textconv.Version(), type(textconv.Version()).__name__)
+print("Current:", textconv.Current())
+print("Latest:", textconv.Latest(True), textconv.Latest(False))
+print("DefaultLevel:", textconv.DefaultLevel())
+try:
+    textconv.BadLevel()
+except ValueError as e:
+    print("caught:", e)
+print("SetLevel:", hasattr(textconv, "SetLevel"))
+
+f = textconv.DefaultFlags()
+print("DefaultFlags:", f)
+print("Has:", textconv.Has(f, "fast"), textconv.Has("a, b", "b"), textconv.Has("", "a"))
+try:
+    textconv.Has("a,,b", "a")
+except ValueError as e:
+    print("caught:", e)
+try:
+    textconv.Has(1, "a")
+except TypeError:
+    print("caught TypeError")
+
+b = textconv.NewBuild("gopy")
+print("Build:", b.Name, b.Version, b.Flags)
+b.Flags = "debug,fast"
+print("Describe:", b.Describe())
+try:
+    b.Version = "v2.0"
+except AttributeError:
+    print("caught read-only Version")
+b = textconv.Build("x", Flags="a")
+print("Describe:", b.Describe())
+
+print("OK")
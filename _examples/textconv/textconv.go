@@ -0,0 +1,124 @@
+// Copyright 2019 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// package textconv tests the conversion of otherwise unsupported types to
+// and from python str, through their encoding.TextMarshaler, fmt.Stringer
+// and encoding.TextUnmarshaler implementations, with -text.
+package textconv
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// version is not exported, so it is only converted to a str
+type version struct {
+	major, minor int
+}
+
+func (v version) String() string {
+	return fmt.Sprintf("v%d.%d", v.major, v.minor)
+}
+
+// Current is the current version
+var Current = version{1, 2}
+
+// Version returns the current version
+func Version() version {
+	return Current
+}
+
+// Latest returns the latest version, if it is known, and nil otherwise
+func Latest(known bool) *version {
+	if !known {
+		return nil
+	}
+	return &version{1, 3}
+}
+
+// level is converted to a str by the MarshalText method of its pointer
+type level int
+
+func (l *level) MarshalText() ([]byte, error) {
+	switch *l {
+	case 0:
+		return []byte("debug"), nil
+	case 1:
+		return []byte("info"), nil
+	}
+	return nil, fmt.Errorf("invalid level: %d", int(*l))
+}
+
+// DefaultLevel returns the default level
+func DefaultLevel() level {
+	return 1
+}
+
+// BadLevel returns an invalid level, which cannot be converted
+func BadLevel() level {
+	return 7
+}
+
+// SetLevel is not bound, as a level cannot be converted from a str
+func SetLevel(l level) {
+}
+
+type flag string
+
+// Flags is a set of flags, which cannot be bound as a map as its keys are
+// not exported, and is converted to and from a comma-separated str instead
+type Flags map[flag]bool
+
+func (f Flags) MarshalText() ([]byte, error) {
+	names := make([]string, 0, len(f))
+	for fl := range f {
+		names = append(names, string(fl))
+	}
+	sort.Strings(names)
+	return []byte(strings.Join(names, ",")), nil
+}
+
+func (f *Flags) UnmarshalText(text []byte) error {
+	*f = Flags{}
+	if len(text) == 0 {
+		return nil
+	}
+	for _, name := range strings.Split(string(text), ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			return fmt.Errorf("empty flag in %q", text)
+		}
+		(*f)[flag(name)] = true
+	}
+	return nil
+}
+
+// DefaultFlags returns the default flags
+func DefaultFlags() Flags {
+	return Flags{"safe": true, "fast": true}
+}
+
+// Has returns whether the flags have the given flag
+func Has(f Flags, name string) bool {
+	return f[flag(name)]
+}
+
+// Build has fields of types that are converted to str
+type Build struct {
+	Name    string
+	Version version
+	Flags   Flags
+}
+
+// NewBuild returns a new build with the current version and default flags
+func NewBuild(name string) *Build {
+	return &Build{Name: name, Version: Current, Flags: DefaultFlags()}
+}
+
+// Describe returns a description of the build
+func (b *Build) Describe() string {
+	text, _ := b.Flags.MarshalText()
+	return fmt.Sprintf("%s %s [%s]", b.Name, b.Version, text)
+}
@@ -0,0 +1,16 @@
+# Copyright 2019 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+import inspect
+
+import textsig
+import _textsig
+
+for fn in [_textsig.textsig_Add, _textsig.textsig_Point_Scale, _textsig.textsig_Point_X_Set, _textsig.GoPySetenv]:
+    print(fn.__name__, inspect.signature(fn))
+
+print(_textsig.textsig_Add.__text_signature__)
+print(_textsig.textsig_Add(a=1, b=2), textsig.Add(3, 4))
+
+print("OK")
@@ -0,0 +1,23 @@
+// Copyright 2019 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// package textsig tests the text signatures of the functions of the
+// extension modules, as shown by help() and inspect.signature.
+package textsig
+
+// Add returns the sum of a and b
+func Add(a, b int) int {
+	return a + b
+}
+
+// Point is a point
+type Point struct {
+	X, Y int
+}
+
+// Scale scales the point by factor
+func (p *Point) Scale(factor int) {
+	p.X *= factor
+	p.Y *= factor
+}
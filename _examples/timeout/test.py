@@ -0,0 +1,40 @@
+# Copyright 2019 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+import asyncio
+
+import timeout
+
+print("Wait:", timeout.Wait(10, timeout=1))
+try:
+    timeout.Wait(5000, timeout=0.05)
+except TimeoutError as e:
+    print("Wait timed out:", e)
+print("stopped:", timeout.Stopped())
+
+try:
+    timeout.Sleep(5000, timeout=0.05)
+except TimeoutError as e:
+    print("Sleep timed out:", e)
+print("stopped:", timeout.Stopped())
+
+print("Slow:", timeout.Slow(1, 10))
+print("Slow with timeout:", timeout.Slow(2, 10, timeout=1))
+try:
+    timeout.Slow(3, 500, timeout=0.05)
+except TimeoutError as e:
+    print("Slow timed out:", e)
+
+
+async def main():
+    print("WaitAsync:", await timeout.WaitAsync(10, timeout=1))
+    try:
+        await timeout.WaitAsync(5000, timeout=0.05)
+    except TimeoutError as e:
+        print("WaitAsync timed out:", e)
+    print("stopped:", timeout.Stopped())
+
+asyncio.run(main())
+
+print("OK")
@@ -0,0 +1,58 @@
+// Copyright 2019 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// package timeout tests the timeout keyword of the python wrappers of the
+// functions with a context, which is cancelled on timeout, and of those
+// with a gopy:timeout directive.
+package timeout
+
+import (
+	"context"
+	"time"
+)
+
+var stopped = make(chan string, 10)
+
+// Wait waits for ms milliseconds, unless ctx is cancelled before
+func Wait(ctx context.Context, ms int) (int, error) {
+	select {
+	case <-time.After(time.Duration(ms) * time.Millisecond):
+		return ms, nil
+	case <-ctx.Done():
+		stopped <- "Wait"
+		return 0, ctx.Err()
+	}
+}
+
+// Sleep sleeps for ms milliseconds, unless ctx is cancelled before
+func Sleep(ctx context.Context, ms int) error {
+	_, err := Wait(ctx, ms)
+	return err
+}
+
+// WaitAsync is Wait -- awaited in python.
+//
+// gopy:async
+func WaitAsync(ctx context.Context, ms int) (int, error) {
+	return Wait(ctx, ms)
+}
+
+// Slow returns n after ms milliseconds, without a context.
+//
+// gopy:timeout
+func Slow(n, ms int) int {
+	time.Sleep(time.Duration(ms) * time.Millisecond)
+	return n
+}
+
+// Stopped returns the name of the next func stopped by the cancellation
+// of its context, waiting up to a second for it
+func Stopped() string {
+	select {
+	case s := <-stopped:
+		return s
+	case <-time.After(time.Second):
+		return ""
+	}
+}
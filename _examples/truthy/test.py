@@ -0,0 +1,23 @@
+# Copyright 2019 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+import truthy
+
+n = truthy.NewNode(1)
+print("node:", bool(n), "next:", bool(n.Next), "nil:", bool(truthy.NoNode()))
+if not n.Next:
+    print("end of list")
+
+print("zero span:", bool(truthy.Span()), "span:", bool(truthy.Span(Secs=3)))
+
+ints = truthy.Ints()
+print("empty ints:", bool(ints))
+ints.append(1)
+print("ints:", bool(ints))
+
+print("empty counts:", bool(truthy.Counts()), "counts:", bool(truthy.Counts({"a": 1})))
+
+print("shape:", bool(truthy.NewShape(2)), "nil shape:", bool(truthy.NoShape()))
+
+print("OK")
@@ -0,0 +1,63 @@
+// Copyright 2019 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// package truthy tests the __bool__ of the python classes, which is False
+// for nil values, zero values with an IsZero method, and empty slices and
+// maps.
+package truthy
+
+// Node is a node of a linked list
+type Node struct {
+	Val  int
+	Next *Node
+}
+
+// NewNode returns a new node
+func NewNode(val int) *Node {
+	return &Node{Val: val}
+}
+
+// NoNode returns a nil node
+func NoNode() *Node {
+	return nil
+}
+
+// Span is a span of time, which is zero when it has no seconds
+type Span struct {
+	Secs int
+}
+
+func (s Span) IsZero() bool {
+	return s.Secs == 0
+}
+
+// Ints is a slice of ints
+type Ints []int
+
+// Counts is a map of counts
+type Counts map[string]int
+
+// Shape is a shape
+type Shape interface {
+	Area() float64
+}
+
+// Square is a square shape
+type Square struct {
+	Side float64
+}
+
+func (s *Square) Area() float64 {
+	return s.Side * s.Side
+}
+
+// NewShape returns a square shape
+func NewShape(side float64) Shape {
+	return &Square{Side: side}
+}
+
+// NoShape returns a nil shape
+func NoShape() Shape {
+	return nil
+}
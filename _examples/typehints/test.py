@@ -0,0 +1,19 @@
+# Copyright 2019 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+import inspect
+import typing
+
+import typehints
+
+for fn in [typehints.Describe, typehints.Total, typehints.Lookup, typehints.Log, typehints.Square, typehints.Shape.Grown]:
+    print(fn.__name__, inspect.signature(fn))
+
+print("Sides:", typing.get_type_hints(typehints.Shape.Sides.fget)["return"].__name__)
+print("Grown:", typing.get_type_hints(typehints.Shape.Grown)["return"].__name__)
+
+print(typehints.Describe(typehints.Square()))
+print(typehints.Describe(None))
+
+print("OK")
@@ -0,0 +1,56 @@
+// Copyright 2019 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// package typehints tests the PEP 484 type annotations generated in the
+// python wrappers with -annotate.
+package typehints
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Shape is a regular polygon
+type Shape struct {
+	Name  string
+	Sides int
+}
+
+// Grown returns a copy of the shape with more sides
+func (s *Shape) Grown(n int) *Shape {
+	return &Shape{Name: s.Name, Sides: s.Sides + n}
+}
+
+// Square is the default shape
+var Square = Shape{Name: "square", Sides: 4}
+
+// Describe describes the shape, which can be nil
+func Describe(s *Shape) string {
+	if s == nil {
+		return "nil"
+	}
+	return fmt.Sprintf("%s with %d sides", s.Name, s.Sides)
+}
+
+// Total returns the total number of sides
+func Total(shapes []Shape) int {
+	n := 0
+	for _, s := range shapes {
+		n += s.Sides
+	}
+	return n
+}
+
+// Lookup returns the count of the key, failing if it is missing
+func Lookup(counts map[string]int, key string) (int, error) {
+	n, ok := counts[key]
+	if !ok {
+		return 0, errors.New("missing key")
+	}
+	return n, nil
+}
+
+// Log logs the words, or not
+func Log(words ...string) {
+}
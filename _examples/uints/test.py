@@ -0,0 +1,46 @@
+# Copyright 2019 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+## py2/py3 compat
+from __future__ import print_function
+
+import go, uints
+
+print("Max:", uints.Max(), uints.Max() == 2**64 - 1)
+print("Half:", uints.Half(2**64 - 2))
+print("Next:", uints.Next(2**63))
+print("Hash:", uints.Hash("gopy"))
+print("Grow:", uints.Grow(2**63, 2**62))
+print("Sum:", uints.Sum([2**63, 2**62, 1]))
+print("Apply:", uints.Apply(lambda x: x * 2, 2**62))
+
+for arg in [-1, 2**64]:
+    try:
+        uints.Half(arg)
+    except OverflowError:
+        print("caught OverflowError:", arg)
+
+try:
+    uints.Delete(-1)
+except OverflowError:
+    print("caught OverflowError: Delete")
+uints.Delete(7)
+print("Deleted:", list(uints.Deleted()))
+
+c = uints.Counter(N=2**64 - 1)
+print("Counter:", c.N)
+try:
+    c.N = -5
+except OverflowError:
+    print("caught OverflowError: N")
+
+s = go.Slice_uint64([1, 2])
+s.append(2**64 - 1)
+try:
+    s[0] = -1
+except OverflowError:
+    print("caught OverflowError: element")
+print("Slice:", list(s))
+
+print("OK")
@@ -0,0 +1,62 @@
+// Copyright 2019 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// package uints tests the exact conversion of uint64 and uint to and from
+// python int, raising OverflowError instead of wrapping out of range values.
+package uints
+
+import "math"
+
+// Max returns the largest uint64
+func Max() uint64 { return math.MaxUint64 }
+
+// Half returns x / 2
+func Half(x uint64) uint64 { return x / 2 }
+
+// Next returns x + 1
+func Next(x uint) uint { return x + 1 }
+
+// ID is an identifier
+type ID uint64
+
+// Hash returns an ID hashing s, with the FNV-1a algorithm
+func Hash(s string) ID {
+	h := ID(14695981039346656037)
+	for i := 0; i < len(s); i++ {
+		h ^= ID(s[i])
+		h *= 1099511628211
+	}
+	return h
+}
+
+// Size is a size in bytes
+type Size uint
+
+// Grow returns the size grown by n
+func Grow(s Size, n uint) Size { return s + Size(n) }
+
+// Counter counts events
+type Counter struct {
+	N uint64
+}
+
+// Sum returns the sum of the values, wrapping around
+func Sum(vals []uint64) uint64 {
+	var s uint64
+	for _, v := range vals {
+		s += v
+	}
+	return s
+}
+
+// Apply returns f of x
+func Apply(f func(x uint64) uint64, x uint64) uint64 { return f(x) }
+
+var deleted []uint
+
+// Delete records the deletion of the given id
+func Delete(id uint) { deleted = append(deleted, id) }
+
+// Deleted returns the ids deleted so far
+func Deleted() []uint { return deleted }
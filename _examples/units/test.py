@@ -0,0 +1,20 @@
+# Copyright 2019 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+import go, units
+
+w = units.Warmer(20.5, 1)
+print("Warmer:", w, type(w).__name__)
+print("Fahrenheit:", units.Fahrenheit(units.Boiling))
+t = units.Tag("a")
+print("Tag:", t, type(t).__name__)
+l = units.Raise(units.Level(2))
+print("Raise:", l, type(l).__name__)
+
+r = units.Reading(Sensor=units.Label("s1"), Temp=units.Celsius(21.5))
+print("Reading:", r, r.Sensor, r.Temp, type(r.Temp).__name__)
+print("Temps:", list(units.Temps([r, units.Reading(Temp=-3)])))
+print("NewType:", units.Celsius.__name__, units.Label.__name__, units.Level.__name__)
+
+print("OK")
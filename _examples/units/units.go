@@ -0,0 +1,56 @@
+// Copyright 2019 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// package units tests named basic types, which are passed to and from
+// python as plain values of their basic type.
+package units
+
+import "fmt"
+
+// Celsius is a temperature in degrees Celsius
+type Celsius float64
+
+// Label is the name of a sensor
+type Label string
+
+// Level is a discrete alert level
+type Level int
+
+// Fahrenheit returns the temperature in degrees Fahrenheit
+func (c Celsius) Fahrenheit() float64 { return float64(c)*9/5 + 32 }
+
+// Reading is a temperature read by a sensor
+type Reading struct {
+	Sensor Label
+	Temp   Celsius
+}
+
+// String returns the Reading formatted for display
+func (r *Reading) String() string {
+	return fmt.Sprintf("%s: %.1fC", r.Sensor, r.Temp)
+}
+
+// Boiling is the boiling point of water
+var Boiling Celsius = 100
+
+// Warmer returns the temperature raised by d
+func Warmer(c, d Celsius) Celsius { return c + d }
+
+// Fahrenheit returns the temperature in degrees Fahrenheit
+func Fahrenheit(c Celsius) float64 { return c.Fahrenheit() }
+
+// Tag returns the label prefixed with the sensor kind
+func Tag(l Label) Label { return "temp-" + l }
+
+// Raise returns the next alert level
+func Raise(l Level) Level { return l + 1 }
+
+// Temps returns the temperatures of the readings
+func Temps(rs []*Reading) []Celsius {
+	var ts []Celsius
+	for _, r := range rs {
+		ts = append(ts, r.Temp)
+	}
+	return ts
+}
@@ -0,0 +1,28 @@
+# Copyright 2019 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+## py2/py3 compat
+from __future__ import print_function
+
+import unsafeptrs
+
+b = unsafeptrs.NewBuf(4, 3)
+print("type:", type(b.Data).__name__)
+print("Sum(b.Data, b.Len):", unsafeptrs.Sum(b.Data, b.Len))
+print("Same(b.Data, b.Data):", unsafeptrs.Same(b.Data, b.Data))
+
+c = unsafeptrs.NewBuf(2, 5)
+print("Same(b.Data, c.Data):", unsafeptrs.Same(b.Data, c.Data))
+c.Data = b.Data
+print("Sum(c.Data, 4):", unsafeptrs.Sum(c.Data, 4))
+
+ps = unsafeptrs.Ptrs(b, unsafeptrs.NewBuf(1, 9))
+print("len(ps):", len(ps))
+print("Sum(ps[1], 1):", unsafeptrs.Sum(ps[1], 1))
+
+print("Sum(Nil(), 1):", unsafeptrs.Sum(unsafeptrs.Nil(), 1))
+print("Addr(Nil()):", unsafeptrs.Addr(unsafeptrs.Nil()))
+print("Addr(b.Data) > 0:", unsafeptrs.Addr(b.Data) > 0)
+
+print("OK")
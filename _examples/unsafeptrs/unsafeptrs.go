@@ -0,0 +1,56 @@
+// Copyright 2019 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// package unsafeptrs tests binding unsafe.Pointer values as opaque handles,
+// which python can hold and pass back to Go, but not look into.
+package unsafeptrs
+
+import "unsafe"
+
+// Buf has an unsafe.Pointer field
+type Buf struct {
+	Data unsafe.Pointer
+	Len  int
+}
+
+// NewBuf returns a new buf of n bytes, each set to the given value
+func NewBuf(n int, v byte) *Buf {
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = v
+	}
+	return &Buf{Data: unsafe.Pointer(&b[0]), Len: n}
+}
+
+// Sum returns the sum of the n bytes at the given pointer
+func Sum(p unsafe.Pointer, n int) int {
+	if p == nil {
+		return 0
+	}
+	s := 0
+	for _, v := range unsafe.Slice((*byte)(p), n) {
+		s += int(v)
+	}
+	return s
+}
+
+// Same returns true if the given pointers are the same
+func Same(a, b unsafe.Pointer) bool {
+	return a == b
+}
+
+// Nil returns a nil pointer
+func Nil() unsafe.Pointer {
+	return nil
+}
+
+// Ptrs returns the data pointers of the given bufs
+func Ptrs(a, b *Buf) []unsafe.Pointer {
+	return []unsafe.Pointer{a.Data, b.Data}
+}
+
+// Addr returns the address of the given pointer, as uintptr is bound as an int
+func Addr(p unsafe.Pointer) uintptr {
+	return uintptr(p)
+}
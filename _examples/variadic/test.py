@@ -19,6 +19,21 @@ print("Variadic Struct s(1)+s(2)+s(3) = %d" % varStructResult)
 varInterFaceResult = variadic.VariInterFaceFunc(variadic.NewIntStrUct(1), variadic.NewIntStrUct(2), variadic.NewIntStrUct(3))
 print("Variadic InterFace i(1)+i(2)+i(3) = %d" % varInterFaceResult)
 
+############### Variadic Over String ##############
+varJoinResult = variadic.VariJoin("-", "a", "b", "c")
+print("Variadic Join a-b-c = %s" % varJoinResult)
+
+############### Variadic Over Empty Interface ##############
+varLogResult = variadic.VariLog("%s:%d:%.1f", "x", 1, 2.5)
+print("Variadic Log x:1:2.5 = %s" % varLogResult)
+
+############### Element Type Checking ##############
+try:
+	variadic.VariFunc(1, "2", 3)
+	print("Variadic TypeError not raised")
+except TypeError as e:
+	print("Variadic TypeError: %s" % e)
+
 ############### Final ##############
 if isinstance(varResult, int):
 	print("Type OK")
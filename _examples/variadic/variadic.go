@@ -1,5 +1,10 @@
 package variadic
 
+import (
+	"fmt"
+	"strings"
+)
+
 /////////////// Non Variadic //////////////
 func NonVariFunc(arg1 int, arg2 []int, arg3 int) int{
 	total := arg1
@@ -55,3 +60,13 @@ func VariInterFaceFunc(vargs ...IntInterFace) int{
 	}
 	return total
 }
+
+/////////////// Variadic Over String //////////////
+func VariJoin(sep string, parts ...string) string {
+	return strings.Join(parts, sep)
+}
+
+/////////////// Variadic Over Empty Interface //////////////
+func VariLog(format string, args ...interface{}) string {
+	return fmt.Sprintf(format, args...)
+}
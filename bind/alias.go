@@ -0,0 +1,16 @@
+// Copyright 2019 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build go1.22
+// +build go1.22
+
+package bind
+
+import "go/types"
+
+// unalias returns the target of the given type if it is an alias,
+// which go/types represents explicitly as of Go 1.22.
+func unalias(t types.Type) types.Type {
+	return types.Unalias(t)
+}
@@ -0,0 +1,145 @@
+// Copyright 2019 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bind
+
+import (
+	"fmt"
+	"go/token"
+	"go/types"
+	"strconv"
+	"strings"
+)
+
+// isAnonNamed returns true if t is the synthetic named type of an
+// anonymous struct type, see anonStructs.
+func (sym *symtab) isAnonNamed(t types.Type) bool {
+	nt, ok := t.(*types.Named)
+	if !ok || nt.Obj().Pkg() == nil {
+		return false
+	}
+	ant, ok := sym.anonStructs[types.TypeString(nt.Underlying(), nil)]
+	return ok && ant == nt
+}
+
+// anonLiteral returns the given type with the synthetic named types of
+// anonymous structs within it replaced by the struct literals, along with
+// the aliases resolved, for use in the Go code.
+func (sym *symtab) anonLiteral(t types.Type) types.Type {
+	return mapType(t, func(t types.Type) types.Type {
+		t = unalias(t)
+		if sym.isAnonNamed(t) {
+			return t.Underlying()
+		}
+		return t
+	})
+}
+
+// addAnonStructs adds the synthetic named types of the anonymous struct
+// types used by the given objects of the package, in their exported fields,
+// func params and results, methods and vars, and returns their type names.
+func (p *Package) addAnonStructs(objs []types.Object) []*types.TypeName {
+	var tns []*types.TypeName
+	var walk func(name string, t types.Type)
+	walkTuple := func(name string, tup *types.Tuple, result bool) {
+		for i := 0; i < tup.Len(); i++ {
+			v := tup.At(i)
+			switch {
+			case result:
+				walk(name+"_Result", v.Type())
+			case v.Name() == "" || v.Name() == "_":
+				walk(name+"_Arg"+strconv.Itoa(i), v.Type())
+			default:
+				walk(name+"_"+strings.ToUpper(v.Name()[:1])+v.Name()[1:], v.Type())
+			}
+		}
+	}
+	walkFields := func(name string, st *types.Struct) {
+		for i := 0; i < st.NumFields(); i++ {
+			if f := st.Field(i); f.Exported() {
+				walk(name+"_"+f.Name(), f.Type())
+			}
+		}
+	}
+	walk = func(name string, t types.Type) {
+		switch tt := unalias(t).(type) {
+		case *types.Pointer:
+			walk(name, tt.Elem())
+		case *types.Slice:
+			walk(name, tt.Elem())
+		case *types.Array:
+			walk(name, tt.Elem())
+		case *types.Chan:
+			walk(name, tt.Elem())
+		case *types.Map:
+			walk(name+"_Key", tt.Key())
+			walk(name, tt.Elem())
+		case *types.Signature:
+			walkTuple(name, tt.Params(), false)
+			walkTuple(name, tt.Results(), true)
+		case *types.Struct:
+			tn := p.addAnonStruct(name, tt)
+			if tn != nil {
+				tns = append(tns, tn)
+				walkFields(tn.Name(), tt)
+			}
+		}
+	}
+
+	for _, obj := range objs {
+		switch obj := obj.(type) {
+		case *types.Func:
+			walk(obj.Name(), obj.Type())
+		case *types.Var:
+			walk(obj.Name()+"_Type", obj.Type())
+		case *types.TypeName:
+			named, ok := obj.Type().(*types.Named)
+			if !ok || obj.IsAlias() {
+				continue
+			}
+			switch ut := named.Underlying().(type) {
+			case *types.Struct:
+				walkFields(obj.Name(), ut)
+			case *types.Signature:
+				walk(obj.Name(), ut)
+			default:
+				walk(obj.Name()+"_Elem", ut)
+			}
+			for i := 0; i < named.NumMethods(); i++ {
+				if m := named.Method(i); m.Exported() {
+					walk(obj.Name()+"_"+m.Name(), m.Type())
+				}
+			}
+		}
+	}
+	return tns
+}
+
+// addAnonStruct adds the synthetic named type of the given anonymous struct
+// type, named name or name2, name3 etc if taken, and returns its type name,
+// or nil if the struct type is already added or cannot be bound: empty
+// structs have nothing to wrap, and the literals of structs with
+// unexported fields do not denote the same type outside of the package.
+func (p *Package) addAnonStruct(name string, st *types.Struct) *types.TypeName {
+	key := types.TypeString(st, nil)
+	if _, has := p.syms.anonStructs[key]; has || st.NumFields() == 0 {
+		return nil
+	}
+	for i := 0; i < st.NumFields(); i++ {
+		if !st.Field(i).Exported() {
+			if !p.syms.cfg.NoWarn {
+				fmt.Printf("ignoring anonymous struct %s in %s.%s: unexported field %s\n", types.TypeString(st, types.RelativeTo(p.pkg)), p.pkg.Name(), name, st.Field(i).Name())
+			}
+			return nil
+		}
+	}
+	uname := name
+	for i := 2; p.pkg.Scope().Lookup(uname) != nil || p.anonDocs[uname] != ""; i++ {
+		uname = name + strconv.Itoa(i)
+	}
+	tn := types.NewTypeName(token.NoPos, p.pkg, uname, nil)
+	p.syms.anonStructs[key] = types.NewNamed(tn, st, nil)
+	p.anonDocs[uname] = fmt.Sprintf("%s wraps the anonymous Go struct type %s", uname, types.TypeString(st, types.RelativeTo(p.pkg)))
+	return tn
+}
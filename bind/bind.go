@@ -28,6 +28,106 @@ type BindCfg struct {
 	PkgPrefix string
 	// rename Go exported symbols to python PEP snake_case
 	RenameCase bool
+	// use the name from the json struct tag, if any, for python field names
+	JSONNames bool
+	// comma-separated list of Type=Interface pairs, restricting the
+	// methods exposed for Type to the method set of Interface
+	RestrictTo string
+	// regexp of function and method names for which asyncio-native
+	// python wrappers are generated
+	AsyncNames string
+	// regexp of function and method names whose Go calls hold the python
+	// GIL, which is released during the other calls so that other python
+	// threads can run, and which are interrupted by Ctrl-C if they take a
+	// context, which is cancelled
+	HoldGIL string
+	// generate a dev mode loader that supports reloading a rebuilt
+	// library into a running python session
+	DevMode bool
+	// instrument the wrappers with call metrics, available with go.metrics()
+	Metrics bool
+	// semicolon-separated list of key=value environment variables to set
+	// on the Go side when the library is loaded, as go.setenv does, e.g.,
+	// GOGC=50 -- see goInitEnv for the settings that take effect
+	InitEnv string
+	// comma-separated list of instantiations of generic types and funcs
+	// to bind, e.g., List[int],Map[string,int]
+	Instantiate string
+	// convert []byte to and from python bytes, instead of wrapping it
+	// as a go.Slice_byte object
+	Bytes bool
+	// generate to_numpy and from_numpy methods for numeric slices
+	Numpy bool
+	// convert time.Time and time.Duration to and from python datetime and
+	// timedelta, instead of wrapping them as a handle and an int of nanoseconds
+	DateTime bool
+	// convert *big.Int and *big.Float to and from python int and float,
+	// instead of wrapping them as handles
+	BigNum bool
+	// convert json.RawMessage, and []byte struct fields tagged gopy:",json",
+	// to and from the python values they encode, e.g., dict and list
+	JSON bool
+	// convert values of otherwise unsupported types to python str, through
+	// their encoding.TextMarshaler or fmt.Stringer implementation, and back
+	// through encoding.TextUnmarshaler where possible
+	Text bool
+	// comma-separated list of Method=__op__ pairs, adding to or overriding
+	// the default mapping of Go method names to python operator methods,
+	// e.g., Scale=__mul__ -- an empty __op__ disables the mapping
+	Operators string
+	// emit PEP 484 type annotations in the python wrappers (python 3.7+)
+	Annotate bool
+	// close the io.Closer values when the last python wrapper of their
+	// handle is finalized, and add go.collect() to release the handles of
+	// unreachable wrappers
+	Finalize bool
+	// target the free-threaded python build (PEP 703, e.g., python3.13t):
+	// the module declares that it does not need the GIL, the accessors of
+	// slices, maps and struct fields lock the Go values, and the calls of
+	// the functions and methods with a gopy:notthreadsafe directive, which
+	// hold the GIL otherwise, are serialized
+	FreeThreaded bool
+	// generate a test_<pkg>_stress.py pytest file for each package, which
+	// calls its bindings from many python threads at once, to validate the
+	// GIL handling and the handle registry
+	GenStressTests bool
+	// the backend generating the C code of the _ extension module:
+	// pybindgen, the default, cffi, which also supports PyPy, cython,
+	// capi, which generates it with the CPython C-API directly, or ctypes,
+	// which replaces it with a python module calling the Go library
+	Backend string
+	// restrict the generated C code to the limited API of python 3.11
+	// (Py_LIMITED_API), so that the extension module is built once for the
+	// stable ABI (abi3) of all the later python 3 versions
+	LimitedAPI bool
+	// target GOOS and GOARCH of a cross-compilation (default: the host ones)
+	GOOS   string
+	GOARCH string
+	// prefix of the target python installation of a cross-compilation,
+	// e.g., in a sysroot, whose sysconfig data is read instead of running VM
+	PythonPrefix string
+	// build the extension module for both arm64 and amd64, and merge them
+	// with lipo into a universal2 one, on macOS
+	Universal2 bool
+	// link the Go runtime and libgcc statically, with the pure Go net and
+	// os/user packages, so that the extension module only depends on the C
+	// library, e.g., musl on Alpine, and not on libpython, whose symbols are
+	// those of the interpreter -- exe links the static libpython, if any
+	Static bool
+	// comma-separated list of build tags, as for go build -tags, with which
+	// the packages are loaded, and the library is built
+	Tags string
+	// only regenerate the bindings if the config or the API of the packages
+	// changed since the previous generation, whose state is in CacheFile,
+	// and only rewrite the files whose content changed
+	Incremental bool
+	// suppress warning messages, which may be expected
+	NoWarn bool
+	// do not generate a Makefile, e.g., when called from Makefile
+	NoMake bool
+
+	// the packages parsed with this configuration, see ParsePackage
+	parse *parseCtx
 }
 
 // ErrorList is a list of errors
@@ -0,0 +1,166 @@
+// Copyright 2019 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bind
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/doc"
+	"go/types"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+)
+
+// CacheFile is the file of the output directory with the state of the
+// -incremental generation: the hashes of the config and of the API of the
+// packages that the bindings were generated from, and the generated files.
+const CacheFile = ".gopy-cache.json"
+
+// genCache is the content of CacheFile.
+type genCache struct {
+	Key      string            `json:"key"`
+	Packages map[string]string `json:"packages"`
+	Files    []string          `json:"files"`
+}
+
+// cacheState returns the cache of the current generation, without its
+// files: the key hashes the config, the python one, and the gopy
+// executable, so that a new version of gopy regenerates everything.
+func (g *pyGen) cacheState() (genCache, error) {
+	pycfg, err := GetPythonConfigFor(g.cfg)
+	if err != nil {
+		return genCache{}, err
+	}
+	h := sha256.New()
+	cfg := *g.cfg
+	cfg.parse = nil // the parsed packages are hashed below
+	fmt.Fprintf(h, "%#v\n%#v\n", cfg, pycfg)
+	fmt.Fprintf(h, "%s %s %s %d\n", g.mode, g.libext, g.extraGccArgs, g.lang)
+	if exe, err := os.Executable(); err == nil {
+		if f, err := os.Open(exe); err == nil {
+			io.Copy(h, f)
+			f.Close()
+		}
+	}
+	c := genCache{
+		Key:      hex.EncodeToString(h.Sum(nil)),
+		Packages: make(map[string]string, len(g.pkgs)),
+	}
+	for _, p := range g.pkgs {
+		c.Packages[p.pkg.Path()] = apiHash(p.pkg, p.doc)
+	}
+	return c, nil
+}
+
+// upToDate returns the files of the previous generation, and whether they
+// are up to date with the cache state c: neither the config nor the API of
+// the packages changed, and the files are still there.
+func (g *pyGen) upToDate(c genCache) ([]string, bool) {
+	b, err := os.ReadFile(filepath.Join(g.cfg.OutputDir, CacheFile))
+	if err != nil {
+		return nil, false
+	}
+	var old genCache
+	if err := json.Unmarshal(b, &old); err != nil {
+		return nil, false
+	}
+	if old.Key != c.Key || !reflect.DeepEqual(old.Packages, c.Packages) {
+		return nil, false
+	}
+	for _, fn := range old.Files {
+		if _, err := os.Stat(filepath.Join(g.cfg.OutputDir, fn)); err != nil {
+			return nil, false
+		}
+	}
+	return old.Files, true
+}
+
+// saveCache writes the cache state c of the files generated by g.
+func (g *pyGen) saveCache(c genCache) error {
+	c.Files = g.files
+	b, err := json.MarshalIndent(c, "", "\t")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(g.cfg.OutputDir, CacheFile), b, 0644)
+}
+
+// apiHash returns the hash of the API of pkg, which its bindings are
+// generated from: the exported objects of pkg and of its imports, whose
+// types the bindings may use, and the doc comments, which make the python
+// docstrings -- but not their positions or the bodies of the functions,
+// whose changes only need to rebuild the Go library.
+func apiHash(pkg *types.Package, pdoc *doc.Package) string {
+	h := sha256.New()
+	hashScope(h, pkg)
+	for _, imp := range pkg.Imports() {
+		hashScope(h, imp)
+	}
+	if pdoc != nil {
+		hashDoc(h, pdoc)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// hashScope writes the exported objects of pkg, and the methods of its
+// named types, to w.
+func hashScope(w io.Writer, pkg *types.Package) {
+	fmt.Fprintf(w, "package %s\n", pkg.Path())
+	scope := pkg.Scope()
+	for _, name := range scope.Names() {
+		obj := scope.Lookup(name)
+		if !obj.Exported() {
+			continue
+		}
+		fmt.Fprintln(w, types.ObjectString(obj, nil))
+		if named, ok := obj.Type().(*types.Named); ok && obj == named.Obj() {
+			for i := 0; i < named.NumMethods(); i++ {
+				fmt.Fprintln(w, types.ObjectString(named.Method(i), nil))
+			}
+		}
+	}
+}
+
+// hashDoc writes the doc comments of pdoc to w, including those of the
+// struct fields and of the values of the declarations, which go/doc leaves
+// in their declarations.
+func hashDoc(w io.Writer, pdoc *doc.Package) {
+	fmt.Fprintln(w, pdoc.Doc)
+	decl := func(n ast.Node) {
+		ast.Inspect(n, func(n ast.Node) bool {
+			if cg, ok := n.(*ast.CommentGroup); ok {
+				fmt.Fprintln(w, cg.Text())
+			}
+			return true
+		})
+	}
+	values := func(vs []*doc.Value) {
+		for _, v := range vs {
+			fmt.Fprintln(w, v.Doc)
+			decl(v.Decl)
+		}
+	}
+	funcs := func(fs []*doc.Func) {
+		for _, f := range fs {
+			fmt.Fprintln(w, f.Name, f.Doc)
+		}
+	}
+	values(pdoc.Consts)
+	values(pdoc.Vars)
+	funcs(pdoc.Funcs)
+	for _, t := range pdoc.Types {
+		fmt.Fprintln(w, t.Name, t.Doc)
+		decl(t.Decl)
+		values(t.Consts)
+		values(t.Vars)
+		funcs(t.Funcs)
+		funcs(t.Methods)
+	}
+}
@@ -0,0 +1,246 @@
+// Copyright 2019 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bind
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"strings"
+)
+
+// crossConfigCode prints the python configuration of the target python
+// installation under the prefix given as first argument, from its sysconfig
+// data, which is a python dict literal: the paths of the target are mapped
+// under the prefix.  The platform is the one of sysconfig.get_platform() for
+// the GOOS and machine given as other arguments.  _PYTHON_SYSCONFIGDATA_NAME
+// selects the sysconfig data, if there are several, as in the cross builds
+// of python itself.
+const crossConfigCode = `import glob
+import json
+import os
+import sys
+
+prefix, goos, machine = sys.argv[1:4]
+name = os.environ.get("_PYTHON_SYSCONFIGDATA_NAME", "_sysconfigdata_*")
+data = sorted(glob.glob(os.path.join(prefix, "lib", "python3*", name + ".py")))
+if not data:
+	sys.exit("gopy: no %s.py sysconfig data in %s" % (name, os.path.join(prefix, "lib", "python3*")))
+ns = {}
+with open(data[0]) as f:
+	exec(f.read(), ns)
+v = ns["build_time_vars"]
+
+def remap(path):
+	tprefix = v.get("prefix") or ""
+	if tprefix and path and path.startswith(tprefix):
+		return prefix + path[len(tprefix):]
+	return path
+
+if goos == "darwin":
+	platform = "macosx-%s-%s" % (v.get("MACOSX_DEPLOYMENT_TARGET") or "11.0", machine)
+elif goos == "windows":
+	platform = machine
+else:
+	platform = "%s-%s" % (goos, machine)
+
+major, minor = v["VERSION"].split(".")[:2]
+print(json.dumps({
+	"version": int(major),
+	"minor": int(minor),
+	"incdir":  remap(v.get("INCLUDEPY")),
+	"libdir":  remap(v.get("LIBDIR")),
+	"libpy":   v.get("LIBRARY"),
+	"shlibs":  v.get("SHLIBS"),
+	"syslibs": v.get("SYSLIBS"),
+	"extsuffix": v.get("EXT_SUFFIX"),
+	"platform": platform,
+	"multiarch": v.get("MULTIARCH") or "",
+	"gil_disabled": bool(v.get("Py_GIL_DISABLED")),
+}))
+`
+
+// pyMachines are the machine names of the python platforms of the GOARCH
+// values, on linux.
+var pyMachines = map[string]string{
+	"amd64":   "x86_64",
+	"arm64":   "aarch64",
+	"386":     "i686",
+	"arm":     "armv7l",
+	"ppc64le": "ppc64le",
+	"s390x":   "s390x",
+	"riscv64": "riscv64",
+}
+
+// winPlatforms are the python platforms of the GOARCH values, on windows.
+var winPlatforms = map[string]string{
+	"amd64": "win-amd64",
+	"386":   "win32",
+	"arm64": "win-arm64",
+}
+
+// mingwCC are the C compilers of the windows targets of a cross-compilation.
+var mingwCC = map[string]string{
+	"amd64": "x86_64-w64-mingw32-gcc",
+	"386":   "i686-w64-mingw32-gcc",
+	"arm64": "aarch64-w64-mingw32-clang",
+}
+
+// crossTarget returns the target GOOS and GOARCH of cfg, and whether they
+// differ from the host ones.
+func crossTarget(cfg *BindCfg) (goos, goarch string, cross bool) {
+	goos, goarch = cfg.GOOS, cfg.GOARCH
+	if goos == "" {
+		goos = runtime.GOOS
+	}
+	if goarch == "" {
+		goarch = runtime.GOARCH
+	}
+	return goos, goarch, goos != runtime.GOOS || goarch != runtime.GOARCH
+}
+
+// pyMachine returns the machine of the python platform of goos and
+// goarch, or "" if they are not supported.
+func pyMachine(goos, goarch string) string {
+	switch goos {
+	case "linux":
+		return pyMachines[goarch]
+	case "darwin":
+		if goarch == "arm64" {
+			return "arm64"
+		}
+		return pyMachines[goarch]
+	case "windows":
+		return winPlatforms[goarch]
+	}
+	return ""
+}
+
+// checkCross returns an error if the cross-compilation of cfg, if any, is
+// not supported: it needs the python installation of the target.
+func checkCross(cfg *BindCfg) error {
+	goos, goarch, cross := crossTarget(cfg)
+	switch {
+	case cross && cfg.PythonPrefix == "":
+		return fmt.Errorf("gopy: the cross-compilation to %s/%s needs the -python-prefix of the target python", goos, goarch)
+	case cfg.PythonPrefix != "" && pyMachine(goos, goarch) == "":
+		return fmt.Errorf("gopy: cross-compilation to %s/%s is not supported", goos, goarch)
+	}
+	return nil
+}
+
+// GetPythonConfigFor returns the python configuration of the target of
+// cfg: the one of the python VM, or the one read from the sysconfig data of
+// the python installation under cfg.PythonPrefix, for a cross-compilation,
+// with the C compiler of cfg.GOOS and cfg.GOARCH, unless CC is set.
+func GetPythonConfigFor(cfg *BindCfg) (PyConfig, error) {
+	if err := checkCross(cfg); err != nil {
+		return PyConfig{}, err
+	}
+	if cfg.PythonPrefix == "" {
+		pycfg, err := GetPythonConfig(cfg.VM)
+		return universal2Platform(cfg, pycfg), err
+	}
+
+	goos, goarch, cross := crossTarget(cfg)
+	machine := pyMachine(goos, goarch)
+	pycfg, err := runPythonConfig(cfg.VM, crossConfigCode, cfg.PythonPrefix, goos, machine)
+	if err != nil {
+		return pycfg, err
+	}
+
+	pycfg = universal2Platform(cfg, pycfg)
+	if os.Getenv("CC") != "" || !cross || cfg.Universal2 {
+		return pycfg, nil
+	}
+	switch goos {
+	case "linux":
+		triple := pycfg.MultiArch
+		if triple == "" {
+			triple = machine + "-linux-gnu"
+		}
+		pycfg.CC = triple + "-gcc"
+	case "darwin":
+		pycfg.CC = "clang -arch " + machine
+	case "windows":
+		pycfg.CC = mingwCC[goarch]
+	}
+	return pycfg, nil
+}
+
+// Universal2Archs are the GOARCH values of the slices of the universal2
+// extension module built with -universal2, merged with lipo.
+var Universal2Archs = []string{"arm64", "amd64"}
+
+// checkUniversal2 returns an error if cfg does not support -universal2,
+// which builds the extension module for macOS, and both its GOARCH values.
+func checkUniversal2(mode BuildMode, cfg *BindCfg) error {
+	if !cfg.Universal2 {
+		return nil
+	}
+	goos, _, _ := crossTarget(cfg)
+	switch {
+	case mode == ModeExe:
+		return fmt.Errorf("gopy: -universal2 does not support exe")
+	case goos != "darwin":
+		return fmt.Errorf("gopy: -universal2 builds for macOS, not GOOS=%s", goos)
+	case cfg.GOARCH != "":
+		return fmt.Errorf("gopy: -universal2 builds for both arm64 and amd64, not -goarch=%s", cfg.GOARCH)
+	}
+	return nil
+}
+
+// universal2Platform returns pycfg with the universal2 platform of macOS,
+// e.g., macosx-11.0-universal2, with -universal2.
+func universal2Platform(cfg *BindCfg, pycfg PyConfig) PyConfig {
+	if cfg.Universal2 && strings.HasPrefix(pycfg.Platform, "macosx-") {
+		if i := strings.LastIndex(pycfg.Platform, "-"); i > 0 {
+			pycfg.Platform = pycfg.Platform[:i] + "-universal2"
+		}
+	}
+	return pycfg
+}
+
+// Universal2Env returns the environment of the go build of the goarch slice
+// of the universal2 extension module, with the C compiler given by CC, or
+// clang, for its architecture.
+func Universal2Env(goarch string) []string {
+	cc := os.Getenv("CC")
+	if cc == "" {
+		cc = "clang"
+	}
+	return []string{"GOOS=darwin", "GOARCH=" + goarch, "CGO_ENABLED=1", "CC=" + cc + " -arch " + pyMachine("darwin", goarch)}
+}
+
+// CrossEnv returns the environment of the go commands building the
+// cross-compilation of cfg, with the C compiler of pycfg, or nil if cfg
+// is built for the host.
+func CrossEnv(cfg *BindCfg, pycfg PyConfig) []string {
+	goos, goarch, cross := crossTarget(cfg)
+	if !cross {
+		return nil
+	}
+	env := []string{"GOOS=" + goos, "GOARCH=" + goarch, "CGO_ENABLED=1"}
+	if pycfg.CC != "" {
+		env = append(env, "CC="+pycfg.CC)
+	}
+	return env
+}
+
+// genMakefileCross adds the environment of the cross-compilation of the
+// package, if any, to the Makefile.
+func (g *pyGen) genMakefileCross(pycfg PyConfig) {
+	env := CrossEnv(g.cfg, pycfg)
+	if env == nil {
+		return
+	}
+	goos, goarch, _ := crossTarget(g.cfg)
+	g.makefile.Printf("# cross-compilation to %s/%s, with the python of %s\n", goos, goarch, g.cfg.PythonPrefix)
+	for _, e := range env {
+		kv := strings.SplitN(e, "=", 2)
+		g.makefile.Printf("export %s = %s\n", kv[0], kv[1])
+	}
+	g.makefile.Printf("\n")
+}
@@ -6,11 +6,19 @@ package bind
 
 import (
 	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
 	"strings"
+
+	"github.com/rudderlabs/gopy/gopyh"
+	"golang.org/x/tools/imports"
 )
 
 // this version uses pybindgen and a generated .go file to do the binding
@@ -34,11 +42,12 @@ const (
 )
 
 // set this to true if OS is windows
-var WindowsOS = false
+var WindowsOS = runtime.GOOS == "windows"
 
 // for all preambles: 1 = name of package (outname), 2 = cmdstr
 
-// 3 = libcfg, 4 = GoHandle, 5 = CGoHandle, 6 = all imports, 7 = mainstr, 8 = exe pre C, 9 = exe pre go
+// 3 = libcfg, 4 = GoHandle, 5 = CGoHandle, 6 = all imports, 7 = mainstr, 8 = exe pre C, 9 = exe pre go,
+// 10 = Py_LIMITED_API define
 const (
 	goPreamble = `/*
 cgo stubs for package %[1]s.
@@ -50,8 +59,10 @@ package main
 
 /*
 %[3]s
-// #define Py_LIMITED_API // need full API for PyRun*
+%[10]s
 #include <Python.h>
+#include <stdlib.h> // not included by Python.h with Py_LIMITED_API
+#include <string.h>
 typedef uint8_t bool;
 // static inline is trick for avoiding need for extra .c file
 // the following are used for build value -- switch on reflect.Kind
@@ -71,6 +82,10 @@ static inline PyObject* gopy_build_float64(double val) {
 static inline PyObject* gopy_build_string(const char* val) {
 	return Py_BuildValue("s", val);
 }
+static inline PyObject* gopy_none() { // new reference to None
+	Py_INCREF(Py_None);
+	return Py_None;
+}
 static inline void gopy_decref(PyObject* obj) { // macro
 	Py_XDECREF(obj);
 }
@@ -78,13 +93,149 @@ static inline void gopy_incref(PyObject* obj) { // macro
 	Py_XINCREF(obj);
 }
 static inline int gopy_method_check(PyObject* obj) { // macro
+#ifdef Py_LIMITED_API
+	return PyObject_HasAttrString(obj, "__self__") && PyObject_HasAttrString(obj, "__func__");
+#else
 	return PyMethod_Check(obj);
+#endif
 }
 static inline void gopy_err_handle() {
 	if(PyErr_Occurred() != NULL) {
 		PyErr_Print();
 	}
 }
+static inline const char* gopy_string(PyObject* obj) { // utf-8 contents of a str or bytes
+#if PY_VERSION_HEX >= 0x03000000
+	if(PyUnicode_Check(obj)) {
+		return PyUnicode_AsUTF8AndSize(obj, NULL);
+	}
+#endif
+	return PyBytes_AsString(obj);
+}
+static inline const char* gopy_str(PyObject* obj) { // utf-8 contents of a str, NULL with a TypeError otherwise
+#if PY_VERSION_HEX >= 0x03000000
+	if(PyUnicode_Check(obj)) {
+		return PyUnicode_AsUTF8AndSize(obj, NULL);
+	}
+#else
+	if(PyString_Check(obj)) {
+		return PyString_AsString(obj);
+	}
+#endif
+	PyErr_SetString(PyExc_TypeError, "a str is required");
+	return NULL;
+}
+static inline int64_t gopy_handle_of(PyObject* obj) { // handle of a GoClass object
+	PyObject* h = PyObject_GetAttrString(obj, "handle");
+	if(h == NULL) {
+		return -1;
+	}
+	int64_t v = PyLong_AsLongLong(h);
+	Py_DECREF(h);
+	return v;
+}
+static inline PyObject* gopy_memoryview(void* buf, Py_ssize_t n, Py_ssize_t itemsize, char* format) { // writable view of Go memory, without copying
+	Py_buffer view;
+	if(PyBuffer_FillInfo(&view, NULL, buf, n * itemsize, 0, PyBUF_FULL) < 0) {
+		return NULL;
+	}
+	view.format = format;
+	view.itemsize = itemsize;
+	view.shape = NULL;
+	view.strides = NULL;
+	return PyMemoryView_FromBuffer(&view);
+}
+#ifdef Py_LIMITED_API
+typedef struct { double real; double imag; } gopy_complex_t;
+static inline gopy_complex_t gopy_complex(PyObject* obj) { // PyComplex_AsCComplex, which is not in the limited API
+	gopy_complex_t c;
+	c.real = PyComplex_RealAsDouble(obj);
+	c.imag = (c.real == -1.0 && PyErr_Occurred()) ? 0.0 : PyComplex_ImagAsDouble(obj);
+	return c;
+}
+#else
+static inline Py_complex gopy_complex(PyObject* obj) {
+	return PyComplex_AsCComplex(obj);
+}
+#endif
+static inline char* gopy_err_string() { // fetches and clears the current error, as a new string
+	PyObject *ptype, *pvalue, *ptrace;
+	PyErr_Fetch(&ptype, &pvalue, &ptrace);
+	PyErr_NormalizeException(&ptype, &pvalue, &ptrace);
+	PyObject* str = PyObject_Str(pvalue != NULL ? pvalue : ptype);
+	const char* cs = (str != NULL) ? gopy_string(str) : NULL;
+	char* res = strdup(cs != NULL ? cs : "python error");
+	Py_XDECREF(str);
+	Py_XDECREF(ptype);
+	Py_XDECREF(pvalue);
+	Py_XDECREF(ptrace);
+	PyErr_Clear();
+	return res;
+}
+static inline int gopy_value_kind(PyObject* obj) { // kind of python value, for conversion to a Go interface{}
+	if(obj == Py_None) {
+		return 0;
+	}
+	if(PyBool_Check(obj)) {
+		return 1;
+	}
+#if PY_VERSION_HEX < 0x03000000
+	if(PyInt_Check(obj)) {
+		return 2;
+	}
+	if(PyBytes_Check(obj)) {
+		return 4; // str
+	}
+#else
+	if(PyBytes_Check(obj) || PyByteArray_Check(obj)) {
+		return 5;
+	}
+#endif
+	if(PyLong_Check(obj)) {
+		return 2;
+	}
+	if(PyFloat_Check(obj)) {
+		return 3;
+	}
+	if(PyUnicode_Check(obj)) {
+		return 4;
+	}
+	if(PyDict_Check(obj)) {
+		return 6;
+	}
+	if(PyList_Check(obj) || PyTuple_Check(obj)) {
+		return 7;
+	}
+	if(PyObject_HasAttrString(obj, "handle")) {
+		return 8;
+	}
+	return -1;
+}
+static inline PyObject* gopy_class_new(PyObject* cls, int64_t h) { // new cls(handle=h)
+	PyObject* args = PyTuple_New(0);
+	PyObject* kw = Py_BuildValue("{s:L}", "handle", (long long)h);
+	PyObject* obj = (kw != NULL) ? PyObject_Call(cls, args, kw) : NULL;
+	Py_XDECREF(args);
+	Py_XDECREF(kw);
+	return obj;
+}
+static inline PyObject* gopy_err_new(PyObject* cls, const char* msg, int64_t h, PyObject* cause) { // new cls(msg), or cls(handle=h) for error types wrapped as classes, stealing cause
+	PyObject* exc = NULL;
+	if(h < 1) {
+		exc = PyObject_CallFunction(cls, "s", msg);
+	} else {
+		exc = gopy_class_new(cls, h);
+	}
+	if(exc != NULL && cause != NULL) {
+		PyObject_SetAttrString(exc, "__cause__", cause);
+	}
+	Py_XDECREF(cause);
+	return exc;
+}
+static inline void gopy_err_set(PyObject* exc) { // raises exc, stealing it
+	PyErr_SetObject((PyObject*)Py_TYPE(exc), exc);
+	Py_DECREF(exc);
+}
 %[8]s
 */
 import "C"
@@ -130,6 +281,330 @@ func NumHandles() int {
 	return gopyh.NumHandles()
 }
 
+// GoPySetenv sets an environment variable on the Go side, applying the
+// Go runtime settings that can be changed after startup.
+//export GoPySetenv
+func GoPySetenv(key, value *C.char) *C.char {
+	err := gopyh.Setenv(C.GoString(key), C.GoString(value))
+	if err != nil {
+		return C.CString(err.Error())
+	}
+	return C.CString("")
+}
+
+// pyCallback holds a reference to a python callable wrapped as a Go func,
+// and its interpreter, which is released when the func is garbage collected.
+type pyCallback struct {
+	obj    *C.PyObject
+	interp *C.PyInterpreterState
+}
+
+func newPyCallback(obj *C.PyObject) *pyCallback {
+	C.gopy_incref(obj)
+	cb := &pyCallback{obj: obj, interp: C.gopy_interp()}
+	runtime.SetFinalizer(cb, func(cb *pyCallback) {
+		if !gopyInterpAlive(cb.interp) {
+			return
+		}
+		defer gopyEnter(cb.interp)()
+		C.gopy_decref(cb.obj)
+	})
+	return cb
+}
+
+// pyErrToGo fetches and clears the current python error, returning it as a Go error
+func pyErrToGo() error {
+	cs := C.gopy_err_string()
+	defer C.free(unsafe.Pointer(cs))
+	return errors.New(C.GoString(cs))
+}
+
+// gopySetError sets the current python error to the exception exc with the
+// given message
+func gopySetError(exc *C.PyObject, msg string) {
+	cs := C.CString(msg)
+	defer C.free(unsafe.Pointer(cs))
+	C.PyErr_SetString(exc, cs)
+}
+
+// GoPyRegisterError registers the python exception class that the Go errors
+// of the given sentinel var or type are raised as, in the current interpreter.
+//export GoPyRegisterError
+func GoPyRegisterError(name *C.char, cls *C.PyObject) {
+	C.gopy_incref(cls)
+	st := gopyState()
+	st.register(st.errClasses, C.GoString(name), cls)
+}
+
+// goErrToPy sets the current python error for the given Go error, see
+// goErrExc
+func goErrToPy(err error) {
+	if exc := goErrExc(err); exc != nil {
+		C.gopy_err_set(exc)
+	}
+}
+
+// goErrExc returns a new python exception for the given Go error, with the
+// error that it wraps chained as its __cause__, and the errors that it
+// joins, with Unwrap() []error, as the tuple of its exceptions, as in an
+// ExceptionGroup -- or nil, with the python error set, if it fails
+func goErrExc(err error) *C.PyObject {
+	var cause *C.PyObject
+	var excs []*C.PyObject
+	switch u := err.(type) {
+	case interface{ Unwrap() error }:
+		if e := u.Unwrap(); e != nil {
+			if cause = goErrExc(e); cause == nil {
+				return nil
+			}
+		}
+	case interface{ Unwrap() []error }:
+		for _, e := range u.Unwrap() {
+			if e == nil {
+				continue
+			}
+			exc := goErrExc(e)
+			if exc == nil {
+				for _, x := range excs {
+					C.gopy_decref(x)
+				}
+				return nil
+			}
+			excs = append(excs, exc)
+		}
+	}
+	exc := goErrObject(err, cause)
+	if exc == nil || len(excs) == 0 {
+		return exc
+	}
+	tup := C.PyTuple_New(C.Py_ssize_t(len(excs)))
+	for i, x := range excs {
+		C.PyTuple_SetItem(tup, C.Py_ssize_t(i), x)
+	}
+	attr := C.CString("exceptions")
+	defer C.free(unsafe.Pointer(attr))
+	C.PyObject_SetAttrString(exc, attr, tup)
+	C.gopy_decref(tup)
+	return exc
+}
+
+// goErrObject returns a new python exception for the given Go error, of the
+// class registered for it if any, else of go.GoError, with the given cause
+func goErrObject(err error, cause *C.PyObject) *C.PyObject {
+	name, h := gopyErrClass(err)
+	st := gopyState()
+	cls, has := st.lookup(st.errClasses, name)
+	if !has {
+		cls, has = st.lookup(st.errClasses, "go.GoError")
+	}
+	if !has {
+		cls = C.PyExc_RuntimeError
+	}
+	estr := C.CString(err.Error())
+	defer C.free(unsafe.Pointer(estr))
+	return C.gopy_err_new(cls, estr, C.int64_t(h), cause)
+}
+
+// valuePyToGo deep-converts a python value to a Go interface{}: dicts with
+// str keys to map[string]interface{}, lists and tuples to []interface{},
+// None, bool, int, float, str and bytes to nil, bool, int, float64, string
+// and []byte, and GoClass objects to the Go variable of their handle.
+// A python error is set for any other value.
+func valuePyToGo(o *C.PyObject) interface{} {
+	switch C.gopy_value_kind(o) {
+	case 0:
+		return nil
+	case 1:
+		return C.PyObject_IsTrue(o) != 0
+	case 2:
+		return int(C.PyLong_AsLongLong(o))
+	case 3:
+		return float64(C.PyFloat_AsDouble(o))
+	case 4:
+		return C.GoString(C.gopy_string(o))
+	case 5:
+		return bytesPyToGo(o)
+	case 6:
+		m := make(map[string]interface{}, int(C.PyDict_Size(o)))
+		var pos C.Py_ssize_t
+		var k, v, items *C.PyObject
+		defer func() { C.Py_DecRef(items) }()
+		for C.gopy_dict_next(o, &items, &pos, &k, &v) != 0 {
+			if C.gopy_value_kind(k) != 4 {
+				gopySetError(C.PyExc_TypeError, "dict keys must be str to convert to a Go map[string]interface{}")
+				return nil
+			}
+			m[C.GoString(C.gopy_string(k))] = valuePyToGo(v)
+			if C.PyErr_Occurred() != nil {
+				return nil
+			}
+		}
+		return m
+	case 7:
+		l := make([]interface{}, int(C.PySequence_Size(o)))
+		for i := range l {
+			e := C.PySequence_GetItem(o, C.Py_ssize_t(i))
+			l[i] = valuePyToGo(e)
+			C.gopy_decref(e)
+			if C.PyErr_Occurred() != nil {
+				return nil
+			}
+		}
+		return l
+	case 8:
+		return gopyh.VarFromHandle(gopyh.CGoHandle(C.gopy_handle_of(o)), "interface{}")
+	}
+	gopySetError(C.PyExc_TypeError, "value cannot be converted to a Go interface{}")
+	return nil
+}
+
+// GoPyRegisterClass registers the python class that the Go values of the
+// given struct type, and pointers to them, are returned as in interface{},
+// and passed as to python callables, in the current interpreter.
+//export GoPyRegisterClass
+func GoPyRegisterClass(name *C.char, cls *C.PyObject) {
+	C.gopy_incref(cls)
+	st := gopyState()
+	st.register(st.classes, C.GoString(name), cls)
+}
+
+// gopyHandleToPy returns the python object of the class registered for the
+// Go struct type of the given name, by GoPyRegisterClass, for the handle h
+// of a value of it, or of a pointer to it, e.g., for the args of the python
+// callables called from Go -- or h itself, for the other types.
+func gopyHandleToPy(name string, h CGoHandle) *C.PyObject {
+	st := gopyState()
+	if cls, has := st.lookup(st.classes, name); has {
+		return C.gopy_class_new(cls, C.int64_t(h))
+	}
+	return C.gopy_build_int64(C.int64_t(h))
+}
+
+// valueGoToPy deep-converts a Go interface{} to a python value, the reverse
+// of valuePyToGo: nil to None, bool, integers, floats and strings to bool,
+// int, float and str, []byte to bytes, maps with string keys to dicts, other
+// slices and arrays to lists, and structs, or pointers to them, to their
+// registered python class.  Pointers to other values are converted as the
+// value they point to, and anything else as the str of its Go format.
+func valueGoToPy(v interface{}) *C.PyObject {
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Invalid:
+		return C.gopy_none()
+	case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Func, reflect.Chan:
+		if rv.IsNil() {
+			return C.gopy_none()
+		}
+	}
+	if name, h := gopyClassOf(v); name != "" {
+		st := gopyState()
+		if cls, has := st.lookup(st.classes, name); has {
+			return C.gopy_class_new(cls, C.int64_t(h))
+		}
+	}
+	switch rv.Kind() {
+	case reflect.Bool:
+		return C.PyBool_FromLong(C.long(boolGoToPy(rv.Bool())))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return C.PyLong_FromLongLong(C.longlong(rv.Int()))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return C.PyLong_FromUnsignedLongLong(C.ulonglong(rv.Uint()))
+	case reflect.Float32, reflect.Float64:
+		return C.PyFloat_FromDouble(C.double(rv.Float()))
+	case reflect.String:
+		cs := C.CString(rv.String())
+		defer C.free(unsafe.Pointer(cs))
+		return C.gopy_build_string(cs)
+	case reflect.Ptr:
+		return valueGoToPy(rv.Elem().Interface())
+	case reflect.Map:
+		if rv.Type().Key().Kind() != reflect.String {
+			break
+		}
+		d := C.PyDict_New()
+		it := rv.MapRange()
+		for it.Next() {
+			e := valueGoToPy(it.Value().Interface())
+			if e == nil {
+				C.gopy_decref(d)
+				return nil // python error is set
+			}
+			cs := C.CString(it.Key().String())
+			C.PyDict_SetItemString(d, cs, e)
+			C.free(unsafe.Pointer(cs))
+			C.gopy_decref(e)
+		}
+		return d
+	case reflect.Slice, reflect.Array:
+		if rv.Kind() == reflect.Slice && rv.Type().Elem().Kind() == reflect.Uint8 {
+			return bytesGoToPy(rv.Bytes())
+		}
+		l := C.PyList_New(C.Py_ssize_t(rv.Len()))
+		for i := 0; i < rv.Len(); i++ {
+			e := valueGoToPy(rv.Index(i).Interface())
+			if e == nil {
+				C.gopy_decref(l)
+				return nil // python error is set
+			}
+			C.PyList_SetItem(l, C.Py_ssize_t(i), e) // steals e
+		}
+		return l
+	}
+	cs := C.CString(fmt.Sprint(v))
+	defer C.free(unsafe.Pointer(cs))
+	return C.gopy_build_string(cs)
+}
+
+// optGoToPy converts a Go pointer to a basic value, e.g., *int, to the
+// python value that it points to, or None if it is nil
+func optGoToPy(p interface{}) *C.PyObject {
+	v := reflect.ValueOf(p)
+	if v.IsNil() {
+		return C.gopy_none()
+	}
+	e := v.Elem()
+	switch e.Kind() {
+	case reflect.Bool:
+		return C.PyBool_FromLong(C.long(boolGoToPy(e.Bool())))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return C.PyLong_FromLongLong(C.longlong(e.Int()))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return C.PyLong_FromUnsignedLongLong(C.ulonglong(e.Uint()))
+	case reflect.Float32, reflect.Float64:
+		return C.PyFloat_FromDouble(C.double(e.Float()))
+	}
+	cs := C.CString(e.String())
+	defer C.free(unsafe.Pointer(cs))
+	return C.gopy_build_string(cs)
+}
+
+// optPyToGo converts a python value to a new Go pointer of type t to a
+// basic value, e.g., *int, or to nil for None
+func optPyToGo(t reflect.Type, o *C.PyObject) interface{} {
+	if C.gopy_value_kind(o) == 0 {
+		return reflect.Zero(t).Interface()
+	}
+	p := reflect.New(t.Elem())
+	e := p.Elem()
+	switch e.Kind() {
+	case reflect.Bool:
+		e.SetBool(C.PyObject_IsTrue(o) != 0)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		e.SetInt(int64(C.PyLong_AsLongLong(o)))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		e.SetUint(uint64(C.PyLong_AsUnsignedLongLong(o)))
+	case reflect.Float32, reflect.Float64:
+		e.SetFloat(float64(C.PyFloat_AsDouble(o)))
+	default:
+		cs := C.gopy_string(o)
+		if cs == nil {
+			return reflect.Zero(t).Interface() // python error is set
+		}
+		e.SetString(C.GoString(cs))
+	}
+	return p.Interface()
+}
+
 // boolGoToPy converts a Go bool to python-compatible C.char
 func boolGoToPy(b bool) C.char {
 	if b {
@@ -146,12 +621,65 @@ func boolPyToGo(b C.char) bool {
 	return false
 }
 
+// uint64GoToPy converts a Go uint64 to a python int, exactly
+func uint64GoToPy(v uint64) *C.PyObject {
+	return C.PyLong_FromUnsignedLongLong(C.ulonglong(v))
+}
+
+// uint64PyToGo converts a python int to a Go uint64, raising
+// OverflowError if it is negative or too large, instead of wrapping it
+func uint64PyToGo(o *C.PyObject) uint64 {
+	v := C.PyLong_AsUnsignedLongLong(o)
+	if v == C.ulonglong(math.MaxUint64) && C.PyErr_Occurred() != nil {
+		return 0 // python error is set
+	}
+	return uint64(v)
+}
+
+// runeGoToPy converts a Go rune to a python str of one character
+func runeGoToPy(r rune) *C.PyObject {
+	cs := C.CString(string(r))
+	defer C.free(unsafe.Pointer(cs))
+	return C.gopy_build_string(cs)
+}
+
+// runePyToGo converts a python str of one character to a Go rune
+func runePyToGo(o *C.PyObject) rune {
+	cs := C.gopy_str(o)
+	if cs == nil {
+		return 0 // python error is set
+	}
+	s := C.GoString(cs)
+	if utf8.RuneCountInString(s) != 1 {
+		gopySetError(C.PyExc_TypeError, "a str of one character is required")
+		return 0
+	}
+	r, _ := utf8.DecodeRuneInString(s)
+	return r
+}
+
+// runesGoToPy converts a Go []rune to a python str
+func runesGoToPy(r []rune) *C.PyObject {
+	cs := C.CString(string(r))
+	defer C.free(unsafe.Pointer(cs))
+	return C.gopy_build_string(cs)
+}
+
+// runesPyToGo converts a python str to a Go []rune
+func runesPyToGo(o *C.PyObject) []rune {
+	cs := C.gopy_str(o)
+	if cs == nil {
+		return nil // python error is set
+	}
+	return []rune(C.GoString(cs))
+}
+
 func complex64GoToPy(c complex64) *C.PyObject {
 	return C.PyComplex_FromDoubles(C.double(real(c)), C.double(imag(c)))
 }
 
 func complex64PyToGo(o *C.PyObject) complex64 {
-	v := C.PyComplex_AsCComplex(o)
+	v := C.gopy_complex(o)
 	return complex(float32(v.real), float32(v.imag))
 }
 
@@ -160,10 +688,166 @@ func complex128GoToPy(c complex128) *C.PyObject {
 }
 
 func complex128PyToGo(o *C.PyObject) complex128 {
-	v := C.PyComplex_AsCComplex(o)
+	v := C.gopy_complex(o)
 	return complex(float64(v.real), float64(v.imag))
 }
 
+// bytesGoToPy converts a Go []byte to a python bytes object
+func bytesGoToPy(b []byte) *C.PyObject {
+	if len(b) == 0 {
+		return C.PyBytes_FromStringAndSize(nil, 0)
+	}
+	return C.PyBytes_FromStringAndSize((*C.char)(unsafe.Pointer(&b[0])), C.Py_ssize_t(len(b)))
+}
+
+// bytesPyToGo copies the contents of a python object supporting the buffer
+// protocol, e.g., bytes, bytearray or memoryview, to a Go []byte
+func bytesPyToGo(o *C.PyObject) []byte {
+	var view C.Py_buffer
+	if C.PyObject_GetBuffer(o, &view, C.PyBUF_SIMPLE) != 0 {
+		return nil // python error is set
+	}
+	defer C.PyBuffer_Release(&view)
+	return C.GoBytes(view.buf, C.int(view.len))
+}
+
+// gopyDeepCopy returns a deep copy of the value pointed to by p, as a new
+// pointer, for the __deepcopy__ of the python classes
+func gopyDeepCopy(p interface{}) interface{} {
+	return deepCopyValue(reflect.ValueOf(p), map[deepCopyKey]reflect.Value{}).Interface()
+}
+
+// deepCopyKey identifies a pointer or map that is already being copied,
+// for cycles and values shared within a value
+type deepCopyKey struct {
+	ptr uintptr
+	typ reflect.Type
+}
+
+// deepCopyValue returns a deep copy of v, copying the elements of slices
+// and maps and the values pointed to, recursively -- unexported fields,
+// chans and funcs are shared
+func deepCopyValue(v reflect.Value, seen map[deepCopyKey]reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return v
+		}
+		key := deepCopyKey{v.Pointer(), v.Type()}
+		if c, has := seen[key]; has {
+			return c
+		}
+		c := reflect.New(v.Type().Elem())
+		seen[key] = c
+		c.Elem().Set(deepCopyValue(v.Elem(), seen))
+		return c
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		key := deepCopyKey{v.Pointer(), v.Type()}
+		if c, has := seen[key]; has {
+			return c
+		}
+		c := reflect.MakeMapWithSize(v.Type(), v.Len())
+		seen[key] = c
+		it := v.MapRange()
+		for it.Next() {
+			c.SetMapIndex(it.Key(), deepCopyValue(it.Value(), seen))
+		}
+		return c
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			c.Index(i).Set(deepCopyValue(v.Index(i), seen))
+		}
+		return c
+	case reflect.Array:
+		c := reflect.New(v.Type()).Elem()
+		for i := 0; i < v.Len(); i++ {
+			c.Index(i).Set(deepCopyValue(v.Index(i), seen))
+		}
+		return c
+	case reflect.Struct:
+		c := reflect.New(v.Type()).Elem()
+		c.Set(v)
+		for i := 0; i < v.NumField(); i++ {
+			if c.Field(i).CanSet() {
+				c.Field(i).Set(deepCopyValue(v.Field(i), seen))
+			}
+		}
+		return c
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.New(v.Type()).Elem()
+		c.Set(deepCopyValue(v.Elem(), seen))
+		return c
+	}
+	return v
+}
+
+// gopyGobEncode encodes the Go value pointed to by p with encoding/gob, to
+// a python bytes object, for the pickling of the python classes
+func gopyGobEncode(p interface{}) *C.PyObject {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(p); err != nil {
+		gopySetError(C.PyExc_ValueError, err.Error())
+		return nil
+	}
+	return bytesGoToPy(buf.Bytes())
+}
+
+// gopyGobDecode decodes a python bytes object, as given by gopyGobEncode,
+// into the Go value pointed to by p -- it returns false on errors, which
+// are raised after the call
+func gopyGobDecode(o *C.PyObject, p interface{}) bool {
+	b := bytesPyToGo(o)
+	if b == nil && C.PyErr_Occurred() != nil {
+		return false
+	}
+	if err := gob.NewDecoder(bytes.NewReader(b)).Decode(p); err != nil {
+		gopySetError(C.PyExc_ValueError, err.Error())
+		return false
+	}
+	return true
+}
+
+// gopyJSONEncode encodes the Go value pointed to by p with encoding/json, to
+// a python str, for the to_json of the python classes
+func gopyJSONEncode(p interface{}) *C.PyObject {
+	b, err := json.Marshal(p)
+	if err != nil {
+		gopySetError(C.PyExc_ValueError, err.Error())
+		return nil
+	}
+	cs := C.CString(string(b))
+	defer C.free(unsafe.Pointer(cs))
+	return C.gopy_build_string(cs)
+}
+
+// gopyJSONDecode decodes a python str or bytes-like object with
+// encoding/json into the Go value pointed to by p, for the from_json of the
+// python classes -- it returns false on errors, which are raised after the
+// call
+func gopyJSONDecode(o *C.PyObject, p interface{}) bool {
+	var b []byte
+	if C.gopy_value_kind(o) == 4 {
+		b = []byte(C.GoString(C.gopy_string(o)))
+	} else if b = bytesPyToGo(o); b == nil && C.PyErr_Occurred() != nil {
+		return false
+	}
+	if err := json.Unmarshal(b, p); err != nil {
+		gopySetError(C.PyExc_ValueError, err.Error())
+		return false
+	}
+	return true
+}
+
 %[9]s
 `
 
@@ -243,17 +927,66 @@ def add_checked_string_function(mod, name, retval, params, failure_expression=''
     mod._add_function_obj(fn)
     return fn
 
+def set_text_signatures(mod):
+    """sets the __text_signature__ of the functions without a docstring to the
+    names of their params, for help() and inspect.signature"""
+    for name, overload in mod.functions.items():
+        for fn in getattr(overload, 'wrappers', [overload]):
+            if getattr(fn, 'docstring', None) is None:
+                fn.docstring = name + '(' + ', '.join(p.name for p in fn.parameters) + ')\\n--\\n\\n'
+`
+
+	// PyBuildModule declares the _ extension module in build.py, for either
+	// backend.  The functions defined in the Go preamble are declared after
+	// it, see declPreambleFuncs.
+	// 1 = name of package (outname)
+	PyBuildModule = `
+import pathlib
+
+# the generated files are written next to build.py, from whichever directory it is run
+here = pathlib.Path(__file__).resolve().parent
+
 mod = Module('_%[1]s')
 mod.add_include('"%[1]s_go.h"')
-mod.add_function('GoPyInit', None, [])
-mod.add_function('DecRef', None, [param('int64_t', 'handle')])
-mod.add_function('IncRef', None, [param('int64_t', 'handle')])
-mod.add_function('NumHandles', retval('int'), [])
 `
 
 	// appended to imports in py wrap preamble as key for adding at end
 	importHereKeyString = "%%%%%%<<<<<<ADDIMPORTSHERE>>>>>>>%%%%%%%"
 
+	// key for the checksum of the generated code, which is only known at the end
+	checksumKeyString = "%%%%%%<<<<<<CHECKSUMHERE>>>>>>>%%%%%%%"
+
+	// goInitEnv sets the environment of the -init-env option on the Go side,
+	// with gopyh.Setenv, as go.setenv does: the Go runtime of a library reads
+	// the environment that the process was started with, and not the one that
+	// python sets once running, e.g., with os.environ.  As this init runs once
+	// the Go runtime is started, only the settings that can still be changed
+	// take effect: GOGC, GOMEMLIMIT and GOMAXPROCS, applied by gopyh.Setenv,
+	// and most of GODEBUG, updated by os.Setenv; the others must be in the
+	// environment of the process when it starts.  Variables already set in the
+	// environment take precedence.
+	// 1 = go slice of key, value pairs
+	goInitEnv = `
+// environment of the -init-env option
+func init() {
+	for _, kv := range [][2]string{%[1]s} {
+		if _, ok := os.LookupEnv(kv[0]); !ok {
+			gopyh.Setenv(kv[0], kv[1])
+		}
+	}
+}
+`
+
+	// PyChecksumCheck verifies at import time that the extension module is the
+	// one built from the same gopy run as the python wrapper.
+	// 1 = name of package (outname), 2 = checksum, 3 = specific package name
+	PyChecksumCheck = `
+# verify that the _%[1]s extension module was generated along with this file
+if getattr(_%[1]s, 'GoPyChecksum', lambda: '')() != '%[2]s':
+	raise ImportError("gopy: the _%[1]s extension module is out of sync with the python wrapper for package %[3]s (checksum %[2]s) -- regenerate and rebuild them together")
+
+`
+
 	// 3 = specific package name, 4 = spec pkg path, 5 = doc, 6 = imports
 	PyWrapPreamble = `%[5]s
 # python wrapper for package %[4]s within overall package %[1]s
@@ -319,6 +1052,40 @@ class GoClass(object):
 	def __init__(self):
 		self.handle = 0
 
+class GoError(RuntimeError):
+	"""GoError is the base class of the exceptions raised for Go errors.
+	The error wrapped by a Go error is chained as the __cause__ of its exception,
+	and the errors joined by it, e.g., by errors.Join, are its exceptions."""
+	exceptions = ()
+	def _chain(self):
+		yield self
+		for e in [getattr(self, '__cause__', None)] + list(self.exceptions):
+			if isinstance(e, GoError):
+				for c in e._chain():
+					yield c
+			elif e is not None:
+				yield e
+	def is_(self, cls):
+		"""is_ returns true if this exception, or any of the ones it wraps, is a cls, like errors.Is in Go"""
+		return self.as_(cls) is not None
+	def as_(self, cls):
+		"""as_ returns the first of this exception and the ones it wraps, depth first, that is a cls, or None, like errors.As in Go"""
+		for e in self._chain():
+			if isinstance(e, cls):
+				return e
+		return None
+
+_%[1]s.GoPyRegisterError("go.GoError", GoError)
+
+class GoPanic(RuntimeError):
+	"""GoPanic is raised for a panic in a Go call, which is recovered, with the stack trace
+	of the goroutine that panicked as its trace."""
+	def __init__(self, msg, trace=''):
+		super(GoPanic, self).__init__(msg)
+		self.trace = trace
+
+_%[1]s.GoPyRegisterError("go.GoPanic", GoPanic)
+
 # use go.nil for nil pointers 
 nil = GoClass()
 
@@ -333,17 +1100,27 @@ def Init():
 	"""calls the GoPyInit function, which runs the 'main' code string that was passed using -main arg to gopy"""
 	_%[1]s.GoPyInit()
 
+def setenv(key, value):
+	"""setenv sets an environment variable for both python and the Go side, e.g., before calling Init.
+	GOGC, GOMEMLIMIT and GOMAXPROCS are applied to the running Go runtime, as are most of the
+	GODEBUG settings -- the other runtime settings must be in the environment of the process
+	when it starts, as the Go runtime is initialized when the library is loaded.  The -init-env
+	arg to gopy sets the variables in the same way, when the library is loaded."""
+	import os
+	err = _%[1]s.GoPySetenv(key, value)
+	if err:
+		raise RuntimeError(err)
+	os.environ[key] = value
+
 	`
 
-	// 3 = gencmd, 4 = vm, 5 = libext 6 = extraGccArgs, 7 = CFLAGS, 8 = LDLFAGS,
-	// 9 = windows special declspec hack
+	// 3 = gencmd, 4 = vm, 5 = libext 6 = extraGccArgs, 7 = CFLAGS, 8 = LDLFAGS
 	MakefileTemplate = `# Makefile for python interface for package %[1]s.
 # File is generated by gopy. Do not edit.
 # %[2]s
 
 GOCMD=go
 GOBUILD=$(GOCMD) build -mod=mod
-GOIMPORTS=goimports
 PYTHON=%[4]s
 LIBEXT=%[5]s
 
@@ -361,8 +1138,6 @@ build:
 	# build target builds the generated files -- this is what gopy build does..
 	# this will otherwise be built during go build and may be out of date
 	- rm %[1]s.c
-	# goimports is needed to ensure that the imports list is valid
-	$(GOIMPORTS) -w %[1]s.go
 	# generate %[1]s_go$(LIBEXT) from %[1]s.go -- the cgo wrappers to go functions
 	$(GOBUILD) -buildmode=c-shared -o %[1]s_go$(LIBEXT) %[1]s.go
 	# use pybindgen to build the %[1]s.c file which are the CPython wrappers to cgo wrappers..
@@ -370,7 +1145,6 @@ build:
 	$(PYTHON) build.py
 	# build the _%[1]s$(LIBEXT) library that contains the cgo and CPython wrappers
 	# generated %[1]s.py python wrapper imports this c-code package
-	%[9]s
 	$(GCC) %[1]s.c %[6]s %[1]s_go$(LIBEXT) -o _%[1]s$(LIBEXT) $(CFLAGS) $(LDFLAGS) -fPIC --shared -w
 	
 `
@@ -382,7 +1156,6 @@ build:
 
 GOCMD=go
 GOBUILD=$(GOCMD) build -mod=mod
-GOIMPORTS=goimports
 PYTHON=%[4]s
 LIBEXT=%[5]s
 CFLAGS = %[6]s
@@ -398,8 +1171,6 @@ gen:
 
 build:
 	# build target builds the generated files into exe -- this is what gopy build does..
-	# goimports is needed to ensure that the imports list is valid
-	$(GOIMPORTS) -w %[1]s.go
 	# this will otherwise be built during go build and may be out of date
 	- rm %[1]s.c 
 	echo "typedef uint8_t bool;" > %[1]s_go.h
@@ -417,25 +1188,36 @@ build:
 `
 )
 
-// thePyGen is the current pyGen which is needed in symbols to lookup
-// package paths -- not very clean to pass around or add to various
-// data structures to make local, but if that ends up being critical
-// for some reason, it could be done.
-var thePyGen *pyGen
-
-// NoWarn turns off warnings -- this must be a global as it is relevant during initial package parsing
-// before e.g., thePyGen is present.
-var NoWarn = false
-
-// NoMake turns off generation of Makefiles
-var NoMake = false
-
 // GenPyBind generates a .go file, build.py file to enable pybindgen to create python bindings,
 // and wrapper .py file(s) that are loaded as the interface to the package with shadow
-// python-side classes
+// python-side classes, for the packages parsed with cfg, see ParsePackage
 // mode = gen, build, pkg, exe
 func GenPyBind(mode BuildMode, libext, extragccargs string, lang int, cfg *BindCfg) error {
+	_, err := genPyBind(mode, libext, extragccargs, lang, cfg)
+	return err
+}
+
+// genPyBind does GenPyBind, returning the names of the generated files
+func genPyBind(mode BuildMode, libext, extragccargs string, lang int, cfg *BindCfg) ([]string, error) {
+	if err := checkBackend(mode, cfg); err != nil {
+		return nil, err
+	}
+	if err := checkLimitedAPI(mode, cfg); err != nil {
+		return nil, err
+	}
+	if err := checkCross(cfg); err != nil {
+		return nil, err
+	}
+	if err := checkUniversal2(mode, cfg); err != nil {
+		return nil, err
+	}
+	if err := checkStatic(cfg); err != nil {
+		return nil, err
+	}
+	pc := cfg.parsed()
 	gen := &pyGen{
+		pkgs:         pc.pkgs,
+		syms:         pc.syms,
 		mode:         mode,
 		pypkgname:    cfg.Name,
 		cfg:          cfg,
@@ -443,14 +1225,45 @@ func GenPyBind(mode BuildMode, libext, extragccargs string, lang int, cfg *BindC
 		extraGccArgs: extragccargs,
 		lang:         lang,
 	}
-	gen.genPackageMap()
-	thePyGen = gen
-	err := gen.gen()
-	thePyGen = nil
+	if cfg.AsyncNames != "" {
+		re, err := regexp.Compile(cfg.AsyncNames)
+		if err != nil {
+			return nil, fmt.Errorf("gopy: invalid -async regexp %q: %v", cfg.AsyncNames, err)
+		}
+		gen.asyncRe = re
+	}
+	if cfg.HoldGIL != "" {
+		re, err := regexp.Compile(cfg.HoldGIL)
+		if err != nil {
+			return nil, fmt.Errorf("gopy: invalid -hold-gil regexp %q: %v", cfg.HoldGIL, err)
+		}
+		gen.holdGILRe = re
+	}
+	if _, err := parseInitEnv(cfg.InitEnv); err != nil {
+		return nil, err
+	}
+	ops, err := parseOperators(cfg.Operators)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return err
+	gen.operators = ops
+	gen.genPackageMap()
+	var cache genCache
+	if cfg.Incremental {
+		cache, err = gen.cacheState()
+		if err != nil {
+			return nil, err
+		}
+		if files, ok := gen.upToDate(cache); ok {
+			fmt.Printf("gopy: the bindings in %s are up to date\n", cfg.OutputDir)
+			return files, nil
+		}
+	}
+	err = gen.gen()
+	if err == nil && cfg.Incremental {
+		err = gen.saveCache(cache)
+	}
+	return gen.files, err
 }
 
 type pyGen struct {
@@ -458,9 +1271,12 @@ type pyGen struct {
 	leakfile *printer
 	pybuild  *printer
 	pywrap   *printer
+	pystub   *printer
 	makefile *printer
 
-	pkg    *Package // current package (only set when doing package-specific processing)
+	pkg    *Package   // current package (only set when doing package-specific processing)
+	pkgs   []*Package // all the packages, starting with the go package
+	syms   *symtab    // the symbols of all the packages
 	err    ErrorList
 	pkgmap map[string]struct{} // map of package paths
 
@@ -469,7 +1285,17 @@ type pyGen struct {
 	cfg          *BindCfg
 	libext       string
 	extraGccArgs string
-	lang         int // c-python api version (2,3)
+	lang         int               // c-python api version (2,3)
+	asyncRe      *regexp.Regexp    // names of functions to wrap as asyncio coroutines
+	holdGILRe    *regexp.Regexp    // names of functions whose calls hold the GIL
+	operators    map[string]string // python operator methods of Go method names
+	pywraps      []pyWrapOut       // python wrapper files, output at the end
+	pystubs      []pyWrapOut       // python type stub files, output at the end
+	pytests      []pyWrapOut       // python stress test files, output at the end
+	funcs        []*capiFunc       // functions of the _ extension module, see declFunc
+	stubImports  map[string]bool   // modules imported by the current stub, see stubClass
+	stubClasses  map[string]bool   // classes declared in the current stub
+	files        []string          // names of the files written to the output dir
 }
 
 func (g *pyGen) gen() error {
@@ -479,11 +1305,15 @@ func (g *pyGen) gen() error {
 		return fmt.Errorf("gopy: could not create output directory: %v", err)
 	}
 
+	g.checkRestrictTo()
 	g.genPre()
 	g.genExtTypesGo()
-	for _, p := range Packages {
+	for _, p := range g.pkgs {
 		g.genPkg(p)
 	}
+	g.genErrorClassGo()
+	g.genStructClassGo()
+	g.genStructFieldNamesGo()
 	g.genOut()
 	if len(g.err) == 0 {
 		return nil
@@ -493,7 +1323,7 @@ func (g *pyGen) gen() error {
 
 func (g *pyGen) genPackageMap() {
 	g.pkgmap = make(map[string]struct{})
-	for _, p := range Packages {
+	for _, p := range g.pkgs {
 		g.pkgmap[p.pkg.Path()] = struct{}{}
 	}
 }
@@ -502,22 +1332,34 @@ func (g *pyGen) genPre() {
 	g.gofile = &printer{buf: new(bytes.Buffer), indentEach: []byte("\t")}
 	g.leakfile = &printer{buf: new(bytes.Buffer), indentEach: []byte("\t")}
 	g.pybuild = &printer{buf: new(bytes.Buffer), indentEach: []byte("\t")}
-	if !NoMake {
+	if !g.cfg.NoMake {
 		g.makefile = &printer{buf: new(bytes.Buffer), indentEach: []byte("\t")}
 	}
 	g.genGoPreamble()
 	g.genPyBuildPreamble()
-	if !NoMake {
+	if !g.cfg.NoMake {
 		g.genMakefile()
 	}
+	if g.isDev() {
+		g.genDevLoader()
+	}
 	oinit, err := os.Create(filepath.Join(g.cfg.OutputDir, "__init__.py"))
 	g.err.Add(err)
+	g.files = append(g.files, "__init__.py")
 	err = oinit.Close()
 	g.err.Add(err)
 }
 
 func (g *pyGen) genPrintOut(outfn string, pr *printer) {
-	of, err := os.Create(filepath.Join(g.cfg.OutputDir, outfn))
+	fname := filepath.Join(g.cfg.OutputDir, outfn)
+	g.files = append(g.files, outfn)
+	if g.cfg.Incremental {
+		// keep the modification time of the unchanged files, for the builds
+		if old, err := os.ReadFile(fname); err == nil && bytes.Equal(old, pr.buf.Bytes()) {
+			return
+		}
+	}
+	of, err := os.Create(fname)
 	g.err.Add(err)
 	_, err = io.Copy(of, pr)
 	g.err.Add(err)
@@ -525,12 +1367,63 @@ func (g *pyGen) genPrintOut(outfn string, pr *printer) {
 	g.err.Add(err)
 }
 
+// genGoOut writes the Go file outfn with its imports fixed, as goimports
+// does, in process: the generated code only imports the bound packages
+// explicitly, and the standard library ones it uses are added here.
+func (g *pyGen) genGoOut(outfn string, pr *printer) {
+	src, err := imports.Process(filepath.Join(g.cfg.OutputDir, outfn), pr.buf.Bytes(), nil)
+	if err != nil {
+		// still write the file, to see where the generated code is wrong
+		g.err.Add(fmt.Errorf("gopy: could not fix the imports of %s: %v", outfn, err))
+	} else {
+		pr.buf = bytes.NewBuffer(src)
+	}
+	g.genPrintOut(outfn, pr)
+}
+
 func (g *pyGen) genOut() {
-	g.pybuild.Printf("\nmod.generate(open('%v.c', 'w'))\n\n", g.cfg.Name)
+	sum := g.genChecksum()
+	for _, pw := range g.pywraps {
+		nb := bytes.Replace(pw.buf.Bytes(), []byte(checksumKeyString), []byte(sum), -1)
+		pw.buf = bytes.NewBuffer(nb)
+		g.genPrintOut(pw.fname, pw.printer)
+	}
+	for _, ps := range g.pystubs {
+		g.genPrintOut(ps.fname, ps.printer)
+	}
+	for _, pt := range g.pytests {
+		g.genPrintOut(pt.fname, pt.printer)
+	}
+	g.genPrintOut("py.typed", &printer{buf: new(bytes.Buffer)}) // PEP 561 marker
+	switch {
+	case g.isCapi():
+		// build.py only declares the functions of the C source generated by gopy
+		g.genCapiModule()
+	case g.isCtypes():
+		// build.py only declares the functions called by the generated _ python module
+		g.genCtypesModule()
+	case g.isCffi() || g.isCython():
+		// the module is initialized by its python code, see pyBuildCffiDefs and pyBuildCythonDefs
+		g.pybuild.Printf("\nmod.generate(open(here / '%v.c', 'w'))\n\n", g.cfg.Name)
+	default:
+		g.pybuild.Printf("\nset_text_signatures(mod)\n")
+		g.pybuild.Printf("mod.generate(open(here / '%v.c', 'w'))\n", g.cfg.Name)
+		gil := "Py_MOD_GIL_USED"
+		if g.cfg.FreeThreaded {
+			gil = "Py_MOD_GIL_NOT_USED"
+		}
+		g.pybuild.Printf("set_multi_phase_init(here / '%v.c', '_%v', '%v')\n", g.cfg.Name, g.cfg.Name, gil)
+		if g.cfg.LimitedAPI {
+			g.pybuild.Printf("set_limited_api(here / '%v.c', '%v')\n", g.cfg.Name, LimitedAPIVersion)
+		}
+		g.pybuild.Printf("\n")
+	}
 	g.gofile.Printf("\n\n")
-	g.genPrintOut(g.cfg.Name+".go", g.gofile)
-	g.genPrintOut("build.py", g.pybuild)
-	if !NoMake {
+	g.genGoOut(g.cfg.Name+".go", g.gofile)
+	if !g.isCapi() && !g.isCtypes() {
+		g.genPrintOut("build.py", g.pybuild)
+	}
+	if !g.cfg.NoMake {
 		g.makefile.Printf("\n\n")
 		g.genPrintOut("Makefile", g.makefile)
 	}
@@ -550,27 +1443,60 @@ func (g *pyGen) genPkgWrapOut() {
 	b := g.pywrap.buf.Bytes()
 	nb := bytes.Replace(b, []byte(importHereKeyString), []byte(impstr), 1)
 	g.pywrap.buf = bytes.NewBuffer(nb)
-	g.genPrintOut(g.pkg.pkg.Name()+".py", g.pywrap)
+	// written out at the end, once the checksum is known
+	g.pywraps = append(g.pywraps, pyWrapOut{g.pkg.pkg.Name() + ".py", g.pywrap})
+}
+
+// pyWrapOut is a python wrapper file pending output
+type pyWrapOut struct {
+	fname string
+	*printer
+}
+
+// genChecksum computes the checksum of all the generated code, and adds
+// the function returning it to the extension, so that the python wrappers
+// can check that they are used with the library they were generated with.
+func (g *pyGen) genChecksum() string {
+	h := sha256.New()
+	h.Write(g.gofile.buf.Bytes())
+	h.Write(g.pybuild.buf.Bytes())
+	for _, pw := range g.pywraps {
+		h.Write(pw.buf.Bytes())
+	}
+	sum := hex.EncodeToString(h.Sum(nil))[:16]
+
+	g.gofile.Printf("\n// GoPyChecksum returns the checksum of the code generated along with this library\n")
+	g.gofile.Printf("//export GoPyChecksum\n")
+	g.gofile.Printf("func GoPyChecksum() *C.char {\n")
+	g.gofile.Indent()
+	g.gofile.Printf("return C.CString(%q)\n", sum)
+	g.gofile.Outdent()
+	g.gofile.Printf("}\n")
+	g.declFunc("add_checked_string_function", "GoPyChecksum", "char*")
+	return sum
 }
 
 func (g *pyGen) genPkg(p *Package) {
 	g.pkg = p
 	g.pywrap = &printer{buf: new(bytes.Buffer), indentEach: []byte("\t")}
 	g.genPyWrapPreamble()
+	start := g.pywrap.buf.Len()
 	if p == goPackage {
 		g.genGoPkg()
 		g.genExtTypesPyWrap()
-		g.genPkgWrapOut()
 	} else {
 		g.genAll()
-		g.genPkgWrapOut()
 	}
+	g.genPyAll(start)
+	g.genPkgWrapOut()
+	g.genPkgStub()
+	g.genPkgStressTests()
 	g.pkg = nil
 }
 
 func (g *pyGen) genGoPreamble() {
 	pkgimport := ""
-	for pp, pnm := range current.imports {
+	for pp, pnm := range g.syms.imports {
 		_, psfx := filepath.Split(pp)
 		if psfx != pnm {
 			pkgimport += fmt.Sprintf("\n\t%s %q", pnm, pp)
@@ -578,11 +1504,15 @@ func (g *pyGen) genGoPreamble() {
 			pkgimport += fmt.Sprintf("\n\t%q", pp)
 		}
 	}
+	if g.cfg.Metrics {
+		pkgimport += "\n\t\"github.com/rudderlabs/gopy/gopym\""
+	}
 	libcfg := func() string {
-		pycfg, err := GetPythonConfig(g.cfg.VM)
+		pycfg, err := GetPythonConfigFor(g.cfg)
 		if err != nil {
 			panic(err)
 		}
+		pycfg = StaticPythonConfig(g.mode, g.cfg, pycfg)
 		// this is critical to avoid pybindgen errors:
 		exflags := " -Wno-error -Wno-implicit-function-declaration -Wno-int-conversion"
 		pkgcfg := fmt.Sprintf(`
@@ -602,13 +1532,110 @@ func (g *pyGen) genGoPreamble() {
 		exeprec = fmt.Sprintf(goExePreambleC, g.cfg.Name)
 		exeprego = goExePreambleGo
 	}
+	if g.cfg.DateTime {
+		exeprec += goDateTimePreambleC
+	}
+	if g.cfg.BigNum {
+		exeprec += goBigNumPreambleC
+	}
+	if g.cfg.JSON {
+		exeprec += goJSONPreambleC
+	}
+	exeprec += goDictPreambleC
+	exeprec += goInterpPreambleC
+	exeprec += goFreeThreadedPreambleC
+	exeprec += goPanicPreambleC
 	g.gofile.Printf(goPreamble, g.cfg.Name, g.cfg.Cmd, libcfg, GoHandle, CGoHandle,
-		pkgimport, g.cfg.Main, exeprec, exeprego)
+		pkgimport, g.cfg.Main, exeprec, exeprego, g.limitedAPIDefine())
+	if g.isDev() {
+		g.gofile.Printf(goDevInit)
+	}
+	if env, _ := parseInitEnv(g.cfg.InitEnv); len(env) > 0 && g.mode != ModeExe {
+		kvs := make([]string, len(env))
+		for i, kv := range env {
+			kvs[i] = fmt.Sprintf("{%q, %q}", kv[0], kv[1])
+		}
+		g.gofile.Printf(goInitEnv, strings.Join(kvs, ", "))
+	}
+	if g.cfg.Metrics {
+		g.gofile.Printf(goMetricsDefs)
+	}
+	if g.cfg.Finalize {
+		g.gofile.Printf(goFinalizeDefs)
+	}
+	g.gofile.Printf(goContextDefs)
+	g.gofile.Printf("%s", goPoolDefs)
+	g.gofile.Printf(goInterruptDefs)
+	g.gofile.Printf("%s", goPanicDefs)
+	g.gofile.Printf("%s", goInterpDefs)
+	if g.cfg.FreeThreaded {
+		g.gofile.Printf("%s", goFreeThreadedDefs)
+	}
+	if g.cfg.DateTime {
+		g.gofile.Printf("%s", goDateTimeDefs)
+	}
+	if g.cfg.BigNum {
+		g.gofile.Printf("%s", goBigNumDefs)
+	}
+	if g.cfg.JSON {
+		g.gofile.Printf("%s", goJSONDefs)
+	}
+	if g.cfg.Text {
+		g.gofile.Printf("%s", goTextDefs)
+	}
+	g.gofile.Printf("%s", goDictDefs)
 	g.gofile.Printf("\n// --- generated code for package: %[1]s below: ---\n\n", g.cfg.Name)
 }
 
 func (g *pyGen) genPyBuildPreamble() {
-	g.pybuild.Printf(PyBuildPreamble, g.cfg.Name, g.cfg.Cmd)
+	switch {
+	case g.isCapi() || g.isCtypes():
+		g.pybuild.Printf(PyBuildModule, g.cfg.Name)
+		g.declPreambleFuncs()
+	case g.isCffi():
+		g.pybuild.Printf(PyBuildCffiPreamble, g.cfg.Name, g.cfg.Cmd)
+		g.pybuild.Printf("%s", pyBuildCffiDefs)
+		g.pybuild.Printf(PyBuildModule, g.cfg.Name)
+		g.declPreambleFuncs()
+		g.declFunc("mod.add_function", "GoPyInterpInit", "")
+	case g.isCython():
+		g.pybuild.Printf(PyBuildCythonPreamble, g.cfg.Name, g.cfg.Cmd)
+		g.pybuild.Printf("%s", pyBuildCythonDefs)
+		g.pybuild.Printf(PyBuildModule, g.cfg.Name)
+		g.declPreambleFuncs()
+		g.pybuild.Printf("mod.directives = '%s'\n", g.cythonDirectives())
+		g.declFunc("mod.add_function", "GoPyInterpInit", "")
+	default:
+		g.pybuild.Printf(PyBuildPreamble, g.cfg.Name, g.cfg.Cmd)
+		g.pybuild.Printf(PyBuildModule, g.cfg.Name)
+		g.declPreambleFuncs()
+	}
+	if g.cfg.Metrics {
+		g.declMetricsFuncs()
+	}
+	if g.cfg.Finalize {
+		g.declFinalizeFuncs()
+	}
+	g.declContextFuncs()
+	g.declPoolFuncs()
+	if g.isPyBindGen() {
+		g.pybuild.Printf("%s", pyBuildInterpDefs)
+		if g.cfg.LimitedAPI {
+			g.pybuild.Printf("%s", pyBuildLimitedAPIDefs)
+		}
+	}
+}
+
+// declPreambleFuncs declares the functions defined in the Go preamble in
+// build.py, after PyBuildModule.
+func (g *pyGen) declPreambleFuncs() {
+	g.declFunc("mod.add_function", "GoPyInit", "")
+	g.declFunc("mod.add_function", "DecRef", "", capiParam{"int64_t", "handle"})
+	g.declFunc("mod.add_function", "IncRef", "", capiParam{"int64_t", "handle"})
+	g.declFunc("mod.add_function", "NumHandles", "int")
+	g.declFunc("add_checked_string_function", "GoPySetenv", "char*", capiParam{"char*", "key"}, capiParam{"char*", "value"})
+	g.declFunc("mod.add_function", "GoPyRegisterError", "", capiParam{"char*", "name"}, capiParam{"PyObject*", "cls"})
+	g.declFunc("mod.add_function", "GoPyRegisterClass", "", capiParam{"char*", "name"}, capiParam{"PyObject*", "cls"})
 }
 
 func (g *pyGen) genPyWrapPreamble() {
@@ -621,27 +1648,36 @@ func (g *pyGen) genPyWrapPreamble() {
 	if pkgDoc != "" {
 		pkgDoc = `"""` + "\n" + pkgDoc + "\n" + `"""`
 	}
+	if g.pyHints() {
+		pkgDoc += "\n" + PyHintsImports
+	}
 
 	// import other packages for other types that we might use
 	var impstr, impgenstr string
-	impgenNames := []string{"_" + g.cfg.Name, "go"}
 	switch {
 	case g.pkg.Name() == "go":
-		if g.cfg.PkgPrefix != "" {
-			impgenstr += fmt.Sprintf("from %s import %s\n", g.cfg.PkgPrefix, "_"+g.cfg.Name)
-		} else {
-			impgenstr += fmt.Sprintf("import %s\n", "_"+g.cfg.Name)
-		}
+		impgenstr += g.pyImportLib(g.cfg.PkgPrefix)
 		impstr += fmt.Sprintf(GoPkgDefs, g.cfg.Name)
+		if g.isDev() {
+			impstr += fmt.Sprintf(GoPkgDevDefs, g.cfg.Name)
+		}
+		if g.cfg.Metrics {
+			impstr += fmt.Sprintf(GoPkgMetricsDefs, g.cfg.Name)
+		}
+		if g.cfg.Finalize {
+			impstr += fmt.Sprintf(GoPkgFinalizeDefs, g.cfg.Name)
+		}
+		if g.lang != 2 {
+			impstr += GoPkgChanDefs
+			impstr += fmt.Sprintf(GoPkgContextDefs, g.cfg.Name)
+			impstr += fmt.Sprintf(GoPkgPoolDefs, g.cfg.Name)
+		}
 	case g.mode == ModeGen || g.mode == ModeBuild:
+		impgenstr += g.pyImportLib(g.cfg.PkgPrefix)
 		if g.cfg.PkgPrefix != "" {
-			for _, name := range impgenNames {
-				impgenstr += fmt.Sprintf("from %s import %s\n", g.cfg.PkgPrefix, name)
-			}
+			impgenstr += fmt.Sprintf("from %s import %s\n", g.cfg.PkgPrefix, "go")
 		} else {
-			for _, name := range impgenNames {
-				impgenstr += fmt.Sprintf("import %s\n", name)
-			}
+			impgenstr += fmt.Sprintf("import %s\n", "go")
 		}
 	case g.mode == ModeExe:
 		// exe mode ignores PkgPrefix, because it is always built in to exe
@@ -652,9 +1688,14 @@ func (g *pyGen) genPyWrapPreamble() {
 		if g.cfg.PkgPrefix != "" {
 			pkg = g.cfg.PkgPrefix + "." + pkg
 		}
-		for _, name := range impgenNames {
-			impgenstr += fmt.Sprintf("from %s import %s\n", pkg, name)
-		}
+		impgenstr += g.pyImportLib(pkg)
+		impgenstr += fmt.Sprintf("from %s import %s\n", pkg, "go")
+	}
+	if g.hasAsync() {
+		impgenstr += "import asyncio\n"
+	}
+	if g.cfg.DateTime {
+		impgenstr += "import datetime as _datetime\n"
 	}
 	imps := g.pkg.pkg.Imports()
 	for _, im := range imps {
@@ -663,6 +1704,7 @@ func (g *pyGen) genPyWrapPreamble() {
 			g.pkg.AddPyImport(ipath, false)
 		}
 	}
+	impstr += fmt.Sprintf(PyChecksumCheck, g.cfg.Name, checksumKeyString, n)
 	impstr += importHereKeyString
 
 	if g.mode == ModeExe {
@@ -672,6 +1714,29 @@ func (g *pyGen) genPyWrapPreamble() {
 	}
 }
 
+// parseInitEnv returns the key, value pairs of the -init-env option, as
+// semicolon-separated key=value pairs, which must be valid environment
+// variables, with valid values for the Go runtime settings, see
+// gopyh.CheckEnv.
+func parseInitEnv(opt string) ([][2]string, error) {
+	var env [][2]string
+	for _, kv := range strings.Split(opt, ";") {
+		if strings.TrimSpace(kv) == "" {
+			continue
+		}
+		eq := strings.Index(kv, "=")
+		if eq < 0 {
+			return nil, fmt.Errorf("gopy: invalid -init-env entry %q, expecting KEY=value", kv)
+		}
+		key, value := strings.TrimSpace(kv[:eq]), kv[eq+1:]
+		if err := gopyh.CheckEnv(key, value); err != nil {
+			return nil, fmt.Errorf("%v in -init-env", err)
+		}
+		env = append(env, [2]string{key, value})
+	}
+	return env, nil
+}
+
 // CmdStrToMakefile does what is needed to make the command string suitable for makefiles
 // * removes -output
 func CmdStrToMakefile(cmdstr string) string {
@@ -685,37 +1750,74 @@ func CmdStrToMakefile(cmdstr string) string {
 	ncmds = append(ncmds, "-no-make")
 	ncmds = append(ncmds, cmds[2:]...)
 
-	return strings.Join(ncmds, " ")
+	// make would otherwise expand e.g., the $ in -async regexps
+	return strings.Replace(strings.Join(ncmds, " "), "$", "$$", -1)
 }
 
 func (g *pyGen) genMakefile() {
 	gencmd := strings.Replace(g.cfg.Cmd, "gopy build", "gopy gen", 1)
 	gencmd = CmdStrToMakefile(gencmd)
 
-	pycfg, err := GetPythonConfig(g.cfg.VM)
+	pycfg, err := GetPythonConfigFor(g.cfg)
 	if err != nil {
 		panic(err)
 	}
+	pycfg = StaticPythonConfig(g.mode, g.cfg, pycfg)
 
-	if g.mode == ModeExe {
+	switch {
+	case g.mode == ModeExe:
 		g.makefile.Printf(MakefileExeTemplate, g.cfg.Name, g.cfg.Cmd, gencmd, g.cfg.VM, g.libext, pycfg.CFlags, pycfg.LdFlags)
-	} else {
-		winhack := ""
-		if WindowsOS {
-			winhack = fmt.Sprintf(`# windows-only sed hack here to fix pybindgen declaration of PyInit
-  sed -i "s/ PyInit_/ __declspec(dllexport) PyInit_/g" %s.c`, g.cfg.Name)
-		}
-		g.makefile.Printf(MakefileTemplate, g.cfg.Name, g.cfg.Cmd, gencmd, g.cfg.VM, g.libext, g.extraGccArgs, pycfg.CFlags, pycfg.LdFlags, winhack)
+	case g.isCffi():
+		g.makefile.Printf(MakefileCffiTemplate, g.cfg.Name, g.cfg.Cmd, gencmd, g.cfg.VM, g.libext, pycfg.CFlags, pycfg.LdFlags)
+	case g.isCython():
+		g.makefile.Printf(MakefileCythonTemplate, g.cfg.Name, g.cfg.Cmd, gencmd, g.cfg.VM, g.libext, pycfg.CFlags, pycfg.LdFlags)
+	case g.isCapi():
+		g.makefile.Printf(MakefileCapiTemplate, g.cfg.Name, g.cfg.Cmd, gencmd, g.cfg.VM, g.libext, pycfg.CFlags, pycfg.LdFlags)
+	case g.isCtypes():
+		g.makefile.Printf(MakefileCtypesTemplate, g.cfg.Name, g.cfg.Cmd, gencmd, g.cfg.VM, g.libext, pycfg.CFlags, pycfg.LdFlags)
+	default:
+		g.makefile.Printf(MakefileTemplate, g.cfg.Name, g.cfg.Cmd, gencmd, g.cfg.VM, g.libext, g.extraGccArgs, pycfg.CFlags, pycfg.LdFlags)
+	}
+	g.genMakefileCross(pycfg)
+	g.genMakefileTags()
+}
+
+// BuildFlags returns the flags of the go commands loading and building the
+// packages of cfg: its -tags, and the StaticTags of -static.
+func BuildFlags(cfg *BindCfg) []string {
+	var tags []string
+	if cfg.Tags != "" {
+		tags = append(tags, cfg.Tags)
+	}
+	if cfg.Static {
+		tags = append(tags, StaticTags)
+	}
+	if len(tags) == 0 {
+		return nil
 	}
+	return []string{"-tags=" + strings.Join(tags, ",")}
+}
+
+// genMakefileTags adds the build tags of the package, if any, to the go
+// builds of the Makefile.
+func (g *pyGen) genMakefileTags() {
+	flags := BuildFlags(g.cfg)
+	if flags == nil {
+		return
+	}
+	if g.cfg.Static {
+		g.makefile.Printf("# -static: the pure Go net and os/user packages, without the NSS of glibc\n")
+	}
+	g.makefile.Printf("GOBUILD += %s\n\n", strings.Join(flags, " "))
 }
 
 // generate external types, go code
 func (g *pyGen) genExtTypesGo() {
 	g.gofile.Printf("\n// ---- External Types Outside of Targeted Packages ---\n")
 
-	names := current.names()
+	names := g.syms.names()
 	for _, n := range names {
-		sym := current.sym(n)
+		sym := g.syms.sym(n)
 		if !sym.isType() {
 			continue
 		}
@@ -730,9 +1832,9 @@ func (g *pyGen) genExtTypesGo() {
 func (g *pyGen) genExtTypesPyWrap() {
 	g.pywrap.Printf("\n# ---- External Types Outside of Targeted Packages ---\n")
 
-	names := current.names()
+	names := g.syms.names()
 	for _, n := range names {
-		sym := current.sym(n)
+		sym := g.syms.sym(n)
 		if !sym.isType() {
 			continue
 		}
@@ -748,9 +1850,9 @@ func (g *pyGen) genAll() {
 
 	g.gofile.Printf("\n// ---- Types ---\n")
 	g.pywrap.Printf("\n# ---- Types ---\n")
-	names := current.names()
+	names := g.syms.names()
 	for _, n := range names {
-		sym := current.sym(n)
+		sym := g.syms.sym(n)
 		if sym.gopkg.Path() != g.pkg.pkg.Path() { // sometimes the package is not the same!!  yikes!
 			continue
 		}
@@ -760,10 +1862,21 @@ func (g *pyGen) genAll() {
 		g.genType(sym, false, false) // not exttypes
 	}
 
+	g.genNamedBasics()
+
 	g.pywrap.Printf("\n\n#---- Enums from Go (collections of consts with same type) ---\n")
 	// conditionally add Enum support because it is an external dependency in py2
 	if len(g.pkg.enums) > 0 {
-		g.pywrap.Printf("from enum import Enum\n\n")
+		used := make(map[string]bool)
+		var bases []string
+		for _, e := range g.pkg.enums {
+			if b := e.pyBase(); !used[b] {
+				used[b] = true
+				bases = append(bases, b)
+			}
+		}
+		sort.Strings(bases)
+		g.pywrap.Printf("from enum import %s\n\n", strings.Join(bases, ", "))
 	}
 	for _, e := range g.pkg.enums {
 		g.genEnum(e)
@@ -792,6 +1905,10 @@ func (g *pyGen) genAll() {
 	for _, s := range g.pkg.structs {
 		g.genStruct(s)
 	}
+	g.genStructClasses()
+
+	g.pywrap.Printf("\n\n# ---- Errors ---\n")
+	g.genErrors()
 
 	g.gofile.Printf("\n\n// ---- Slices ---\n")
 	g.pywrap.Printf("\n\n# ---- Slices ---\n")
@@ -805,6 +1922,9 @@ func (g *pyGen) genAll() {
 		g.genMap(m.sym, false, false, m)
 	}
 
+	g.pywrap.Printf("\n\n# ---- Type Aliases ---\n")
+	g.genAliases()
+
 	// note: these are extracted from reg functions that return full
 	// type (not pointer -- should do pointer but didn't work yet)
 	g.gofile.Printf("\n\n// ---- Constructors ---\n")
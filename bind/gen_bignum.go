@@ -0,0 +1,83 @@
+// Copyright 2019 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bind
+
+const (
+	// goBigNumPreambleC are the C helpers for the conversions of *big.Int
+	// to and from python int, when generating with -bignum
+	goBigNumPreambleC = `
+static inline PyObject* gopy_bigint_str(PyObject* o) { // new reference to the decimal str of an int, NULL on error
+	if(!PyIndex_Check(o)) {
+		PyErr_SetString(PyExc_TypeError, "an int is required");
+		return NULL;
+	}
+	PyObject* i = PyNumber_Index(o);
+	if(i == NULL) {
+		return NULL;
+	}
+	PyObject* s = PyObject_Str(i);
+	Py_DECREF(i);
+	return s;
+}
+`
+
+	// goBigNumDefs are the Go conversions of *big.Int and *big.Float to and
+	// from python int and float, when generating with -bignum
+	goBigNumDefs = `
+// bigIntGoToPy converts a Go *big.Int to a python int, through its
+// decimal representation, or to None if it is nil
+func bigIntGoToPy(x *big.Int) *C.PyObject {
+	if x == nil {
+		return C.gopy_none()
+	}
+	cs := C.CString(x.String())
+	defer C.free(unsafe.Pointer(cs))
+	return C.PyLong_FromString(cs, nil, 10)
+}
+
+// bigIntPyToGo converts a python int, or any object with __index__,
+// to a Go *big.Int, or None to nil -- it is zero on errors, which are
+// raised after the call
+func bigIntPyToGo(o *C.PyObject) *big.Int {
+	if C.gopy_value_kind(o) == 0 {
+		return nil
+	}
+	s := C.gopy_bigint_str(o)
+	if s == nil {
+		return new(big.Int) // python error is set
+	}
+	defer C.gopy_decref(s)
+	x, _ := new(big.Int).SetString(C.GoString(C.gopy_string(s)), 10)
+	return x
+}
+
+// bigFloatGoToPy converts a Go *big.Float to the nearest python float,
+// or to None if it is nil
+func bigFloatGoToPy(x *big.Float) *C.PyObject {
+	if x == nil {
+		return C.gopy_none()
+	}
+	f, _ := x.Float64()
+	return C.PyFloat_FromDouble(C.double(f))
+}
+
+// bigFloatPyToGo converts a python float, or int, to a Go *big.Float,
+// or None to nil
+func bigFloatPyToGo(o *C.PyObject) *big.Float {
+	if C.gopy_value_kind(o) == 0 {
+		return nil
+	}
+	f := float64(C.PyFloat_AsDouble(o))
+	if f == -1 && C.PyErr_Occurred() != nil {
+		return new(big.Float) // python error is set
+	}
+	if math.IsNaN(f) {
+		gopySetError(C.PyExc_ValueError, "nan cannot be converted to a Go *big.Float")
+		return new(big.Float)
+	}
+	return big.NewFloat(f)
+}
+`
+)
@@ -0,0 +1,268 @@
+// Copyright 2019 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bind
+
+import (
+	"go/types"
+)
+
+// bufferFormat returns the buffer protocol (struct module) format of the
+// given slice element type, or "" if slices of it do not support the
+// buffer protocol, i.e., for non-numeric elements.
+func bufferFormat(esym *symbol) string {
+	if esym == nil {
+		return ""
+	}
+	bt, ok := esym.gotyp.Underlying().(*types.Basic)
+	if !ok {
+		return ""
+	}
+	switch bt.Kind() {
+	case types.Int8:
+		return "b"
+	case types.Uint8:
+		return "B"
+	case types.Int16:
+		return "h"
+	case types.Uint16:
+		return "H"
+	case types.Int32:
+		return "i"
+	case types.Uint32:
+		return "I"
+	case types.Int64:
+		return "q"
+	case types.Uint64:
+		return "Q"
+	case types.Int:
+		return "n"
+	case types.Uint:
+		return "N"
+	case types.Float32:
+		return "f"
+	case types.Float64:
+		return "d"
+	}
+	return ""
+}
+
+// numpyDtype returns the numpy dtype of the given slice element type,
+// or "" if it is not numeric.  Complex elements have no buffer protocol
+// format, but are laid out as numpy complex arrays.
+func numpyDtype(esym *symbol) string {
+	if esym == nil {
+		return ""
+	}
+	if bt, ok := esym.gotyp.Underlying().(*types.Basic); ok {
+		switch bt.Kind() {
+		case types.Complex64:
+			return "complex64"
+		case types.Complex128:
+			return "complex128"
+		}
+	}
+	switch bfmt := bufferFormat(esym); bfmt {
+	case "":
+		return ""
+	case "n":
+		return "intp"
+	case "N":
+		return "uintp"
+	default:
+		return bfmt
+	}
+}
+
+// genSliceBuffer generates the python methods exposing the memory of a
+// numeric slice through the buffer protocol, without copying.  Each view
+// pins the backing array of the slice with a separate handle, so that it
+// remains valid even if the slice is appended to, until it is released.
+func (g *pyGen) genSliceBuffer(slc *symbol, esym *symbol) {
+	bfmt := bufferFormat(esym)
+	if bfmt == "" || g.lang == 2 {
+		return
+	}
+	qNm := g.cfg.Name + "." + slc.id
+
+	g.pywrap.Printf("def buffer(self):\n")
+	g.pywrap.Indent()
+	g.pywrap.Printf(`"""buffer() memoryview
+
+buffer returns a writable memoryview of the slice elements, sharing the
+Go memory without copying.  The memory stays pinned until release_buffer
+is called with the view.  With python 3.12 and above, memoryview(self)
+can be used instead, which is released along with the view.
+"""
+`)
+	g.pywrap.Printf("pin = _%s_pin(self.handle)\n", qNm)
+	g.pywrap.Printf("if pin < 0:\n")
+	g.pywrap.Indent()
+	g.pywrap.Printf("return memoryview(bytearray()).cast('%s')\n", bfmt)
+	g.pywrap.Outdent()
+	g.pywrap.Printf("view = _%s_view(pin)\n", qNm)
+	g.pywrap.Printf("self._pins[id(view)] = pin\n")
+	g.pywrap.Printf("return view\n")
+	g.pywrap.Outdent()
+
+	g.pywrap.Printf("def release_buffer(self, view):\n")
+	g.pywrap.Indent()
+	g.pywrap.Printf(`"""release_buffer(view)
+
+release_buffer releases a view returned by buffer, unpinning its Go memory.
+"""
+`)
+	g.pywrap.Printf("pin = self._pins.pop(id(view), -1)\n")
+	g.pywrap.Printf("view.release()\n")
+	g.pywrap.Printf("_%s.DecRef(pin)\n", g.pypkgname)
+	g.pywrap.Outdent()
+
+	// PEP 688 buffer protocol for python classes, in python 3.12 and above
+	g.pywrap.Printf("def __buffer__(self, flags):\n")
+	g.pywrap.Indent()
+	g.pywrap.Printf("return self.buffer()\n")
+	g.pywrap.Outdent()
+	g.pywrap.Printf("def __release_buffer__(self, view):\n")
+	g.pywrap.Indent()
+	g.pywrap.Printf("_%s.DecRef(self._pins.pop(id(view), -1))\n", g.pypkgname)
+	g.pywrap.Outdent()
+}
+
+// genSliceBufferGo generates the Go side of the buffer protocol support
+// of a numeric slice, see genSliceBuffer.
+func (g *pyGen) genSliceBufferGo(slc *symbol, esym *symbol) {
+	bfmt := bufferFormat(esym)
+	if bfmt == "" || g.lang == 2 {
+		return
+	}
+	slNm := slc.id
+
+	g.gofile.Printf("// %s_fmt is the buffer protocol format of the elements\n", slNm)
+	g.gofile.Printf("var %s_fmt = C.CString(%q)\n\n", slNm, bfmt)
+
+	g.gofile.Printf("//export %s_pin\n", slNm)
+	g.gofile.Printf("func %s_pin(handle CGoHandle) CGoHandle {\n", slNm)
+	g.gofile.Indent()
+	g.genValueLock("ptrFromHandle_" + slNm + "(handle)")
+	g.gofile.Printf("s := deptrFromHandle_%s(handle)\n", slNm)
+	g.gofile.Printf("if len(s) == 0 {\n")
+	g.gofile.Indent()
+	g.gofile.Printf("return -1\n")
+	g.gofile.Outdent()
+	g.gofile.Printf("}\n")
+	g.gofile.Printf("pin := handleFromPtr_%s(&s)\n", slNm)
+	g.gofile.Printf("IncRef(pin)\n")
+	g.gofile.Printf("return pin\n")
+	g.gofile.Outdent()
+	g.gofile.Printf("}\n\n")
+
+	g.declFunc("mod.add_function", slNm+"_pin", PyHandle, capiParam{PyHandle, "handle"})
+
+	g.gofile.Printf("//export %s_view\n", slNm)
+	g.gofile.Printf("func %s_view(pin CGoHandle) *C.PyObject {\n", slNm)
+	g.gofile.Indent()
+	g.gofile.Printf("s := deptrFromHandle_%s(pin)\n", slNm)
+	g.gofile.Printf("return C.gopy_memoryview(unsafe.Pointer(&s[0]), C.Py_ssize_t(len(s)), C.Py_ssize_t(unsafe.Sizeof(s[0])), %s_fmt)\n", slNm)
+	g.gofile.Outdent()
+	g.gofile.Printf("}\n\n")
+
+	g.declFunc("mod.add_function", slNm+"_view", "PyObject*", capiParam{PyHandle, "pin"})
+}
+
+// genSliceNumpy generates the python methods converting a numeric slice
+// to and from numpy arrays, copying the elements in bulk.  numpy is only
+// imported when they are called, so that it remains an optional dependency.
+func (g *pyGen) genSliceNumpy(slc *symbol, esym *symbol) {
+	dtype := numpyDtype(esym)
+	if dtype == "" || !g.cfg.Numpy {
+		return
+	}
+	qNm := g.cfg.Name + "." + slc.id
+
+	g.pywrap.Printf("def to_numpy(self):\n")
+	g.pywrap.Indent()
+	g.pywrap.Printf(`"""to_numpy() numpy.ndarray
+
+to_numpy returns a numpy array with a copy of the slice elements.
+"""
+`)
+	g.pywrap.Printf("import numpy\n")
+	g.pywrap.Printf("arr = numpy.empty(len(self), dtype='%s')\n", dtype)
+	g.pywrap.Printf("_%s_to_buffer(self.handle, arr)\n", qNm)
+	g.pywrap.Printf("return arr\n")
+	g.pywrap.Outdent()
+
+	g.pywrap.Printf("@classmethod\n")
+	g.pywrap.Printf("def from_numpy(cls, arr):\n")
+	g.pywrap.Indent()
+	g.pywrap.Printf(`"""from_numpy(arr)
+
+from_numpy returns a new slice with a copy of the elements of the numpy
+array, or any sequence, converted to the element type and flattened.
+"""
+`)
+	g.pywrap.Printf("import numpy\n")
+	g.pywrap.Printf("arr = numpy.ascontiguousarray(arr, dtype='%s').ravel()\n", dtype)
+	g.pywrap.Printf("return cls(handle=_%s_from_buffer(arr))\n", qNm)
+	g.pywrap.Outdent()
+}
+
+// genSliceNumpyGo generates the Go side of the numpy conversions of
+// a numeric slice, which copy its memory from and to a python buffer.
+func (g *pyGen) genSliceNumpyGo(slc *symbol, esym *symbol) {
+	if numpyDtype(esym) == "" || !g.cfg.Numpy {
+		return
+	}
+	slNm := slc.id
+
+	g.gofile.Printf("//export %s_to_buffer\n", slNm)
+	g.gofile.Printf("func %s_to_buffer(handle CGoHandle, o *C.PyObject) {\n", slNm)
+	g.gofile.Indent()
+	g.genValueLock("ptrFromHandle_" + slNm + "(handle)")
+	g.gofile.Printf("s := deptrFromHandle_%s(handle)\n", slNm)
+	g.gofile.Printf("var view C.Py_buffer\n")
+	g.gofile.Printf("if C.PyObject_GetBuffer(o, &view, C.PyBUF_WRITABLE|C.PyBUF_C_CONTIGUOUS) != 0 {\n")
+	g.gofile.Indent()
+	g.gofile.Printf("return // python error is set\n")
+	g.gofile.Outdent()
+	g.gofile.Printf("}\n")
+	g.gofile.Printf("defer C.PyBuffer_Release(&view)\n")
+	g.gofile.Printf("n := C.size_t(len(s)) * C.size_t(unsafe.Sizeof(s[0]))\n")
+	g.gofile.Printf("if n > C.size_t(view.len) {\n")
+	g.gofile.Indent()
+	g.gofile.Printf("n = C.size_t(view.len)\n")
+	g.gofile.Outdent()
+	g.gofile.Printf("}\n")
+	g.gofile.Printf("if n > 0 {\n")
+	g.gofile.Indent()
+	g.gofile.Printf("C.memcpy(view.buf, unsafe.Pointer(&s[0]), n)\n")
+	g.gofile.Outdent()
+	g.gofile.Printf("}\n")
+	g.gofile.Outdent()
+	g.gofile.Printf("}\n\n")
+
+	g.declFunc("add_checked_function", slNm+"_to_buffer", "", capiParam{PyHandle, "handle"}, capiParam{"PyObject*", "o"})
+
+	g.gofile.Printf("//export %s_from_buffer\n", slNm)
+	g.gofile.Printf("func %s_from_buffer(o *C.PyObject) CGoHandle {\n", slNm)
+	g.gofile.Indent()
+	g.gofile.Printf("var view C.Py_buffer\n")
+	g.gofile.Printf("if C.PyObject_GetBuffer(o, &view, C.PyBUF_C_CONTIGUOUS) != 0 {\n")
+	g.gofile.Indent()
+	g.gofile.Printf("return -1 // python error is set\n")
+	g.gofile.Outdent()
+	g.gofile.Printf("}\n")
+	g.gofile.Printf("defer C.PyBuffer_Release(&view)\n")
+	g.gofile.Printf("s := make(%s, int(view.len)/int(unsafe.Sizeof(%s{0}[0])))\n", slc.goname, slc.goname)
+	g.gofile.Printf("if len(s) > 0 {\n")
+	g.gofile.Indent()
+	g.gofile.Printf("C.memcpy(unsafe.Pointer(&s[0]), view.buf, C.size_t(len(s))*C.size_t(unsafe.Sizeof(s[0])))\n")
+	g.gofile.Outdent()
+	g.gofile.Printf("}\n")
+	g.gofile.Printf("return handleFromPtr_%s(&s)\n", slNm)
+	g.gofile.Outdent()
+	g.gofile.Printf("}\n\n")
+
+	g.declFunc("add_checked_function", slNm+"_from_buffer", PyHandle, capiParam{"PyObject*", "o"})
+}
@@ -0,0 +1,409 @@
+// Copyright 2019 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bind
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+const (
+	// CapiPreamble starts the C source of the _ extension module generated
+	// by the capi backend.  It is only compiled once the cgo header of the
+	// Go functions has been generated, by the go build of the c-shared
+	// library, as for the C source generated by pybindgen.
+	// 1 = name of package (outname), 2 = gencmd, 3 = Py_LIMITED_API define
+	CapiPreamble = `// C source of the _%[1]s extension module, with the capi backend.
+// File is generated by gopy. Do not edit.
+// %[2]s
+
+#if __has_include("%[1]s_go.h")
+
+#define PY_SSIZE_T_CLEAN
+%[3]s#include <Python.h>
+#include <stdint.h>
+#include <stdlib.h>
+#include "%[1]s_go.h"
+
+#if PY_VERSION_HEX < 0x03050000
+#error "gopy: -backend=capi requires python 3.5 or above"
+#endif
+`
+
+	// capiConvDefs are the converters of the O& format of PyArg_Parse of the
+	// int params, which raise an OverflowError if the value is out of range
+	// of their C type.
+	capiConvDefs = `
+static int gopy_conv_signed(PyObject* o, long long* v, long long min, long long max) {
+	*v = PyLong_AsLongLong(o);
+	if (*v == -1 && PyErr_Occurred()) {
+		return 0;
+	}
+	if (*v < min || *v > max) {
+		PyErr_SetString(PyExc_OverflowError, "gopy: int out of range of the Go type");
+		return 0;
+	}
+	return 1;
+}
+
+static int gopy_conv_unsigned(PyObject* o, unsigned long long* v, unsigned long long max) {
+	PyObject* i = PyNumber_Index(o);
+	if (i == NULL) {
+		return 0;
+	}
+	*v = PyLong_AsUnsignedLongLong(i);
+	Py_DECREF(i);
+	if (*v == (unsigned long long)-1 && PyErr_Occurred()) {
+		return 0;
+	}
+	if (*v > max) {
+		PyErr_SetString(PyExc_OverflowError, "gopy: int out of range of the Go type");
+		return 0;
+	}
+	return 1;
+}
+
+#define GOPY_CONV_SIGNED(T, MIN, MAX) \
+static int gopy_conv_##T(PyObject* o, void* p) { \
+	long long v; \
+	if (!gopy_conv_signed(o, &v, MIN, MAX)) return 0; \
+	*(T*)p = (T)v; \
+	return 1; \
+}
+#define GOPY_CONV_UNSIGNED(T, MAX) \
+static int gopy_conv_##T(PyObject* o, void* p) { \
+	unsigned long long v; \
+	if (!gopy_conv_unsigned(o, &v, MAX)) return 0; \
+	*(T*)p = (T)v; \
+	return 1; \
+}
+
+GOPY_CONV_SIGNED(int, INT_MIN, INT_MAX)
+GOPY_CONV_SIGNED(int8_t, INT8_MIN, INT8_MAX)
+GOPY_CONV_SIGNED(int16_t, INT16_MIN, INT16_MAX)
+GOPY_CONV_SIGNED(int32_t, INT32_MIN, INT32_MAX)
+GOPY_CONV_SIGNED(int64_t, INT64_MIN, INT64_MAX)
+GOPY_CONV_UNSIGNED(uint8_t, UINT8_MAX)
+GOPY_CONV_UNSIGNED(uint16_t, UINT16_MAX)
+GOPY_CONV_UNSIGNED(uint32_t, UINT32_MAX)
+GOPY_CONV_UNSIGNED(uint64_t, UINT64_MAX)
+
+static PyObject* gopy_str_result(const char* s) {
+	if (s == NULL) {
+		Py_RETURN_NONE;
+	}
+	return PyUnicode_FromString(s);
+}
+`
+
+	// capiModuleDefs define the _ module with the multi-phase initialization
+	// of PEP 489, as set_multi_phase_init does for pybindgen, see
+	// pyBuildInterpDefs.
+	// 1 = name of package (outname), 2 = Py_mod_gil
+	capiModuleDefs = `
+` + cModInitFunc + `
+static int gopy_mod_exec(PyObject* mod) {
+	GoPyInterpInit();
+	return PyErr_Occurred() ? -1 : 0;
+}
+static void gopy_mod_free(void* mod) {
+	GoPyInterpFree();
+}
+static PyModuleDef_Slot gopy_mod_slots[] = {
+	{Py_mod_exec, (void*)gopy_mod_exec},
+#ifdef Py_mod_multiple_interpreters
+	{Py_mod_multiple_interpreters, Py_MOD_PER_INTERPRETER_GIL_SUPPORTED},
+#endif
+#ifdef Py_mod_gil
+	{Py_mod_gil, %[2]s},
+#endif
+	{0, NULL},
+};
+static struct PyModuleDef gopy_moduledef = {
+	PyModuleDef_HEAD_INIT, "_%[1]s", NULL, 0, gopy_methods, gopy_mod_slots, NULL, NULL, gopy_mod_free,
+};
+GOPY_MODINIT_FUNC
+PyInit__%[1]s(void) {
+	return PyModuleDef_Init(&gopy_moduledef);
+}
+
+#endif // __has_include
+`
+
+	// MakefileCapiTemplate is the Makefile of the capi backend, which builds
+	// the generated C source along with the Go code, with go build.
+	// 1 = name of package (outname), 2 = gencmd, 3 = gen cmd, 4 = vm,
+	// 5 = libext, 6 = cflags, 7 = ldflags
+	MakefileCapiTemplate = `# Makefile for python interface for package %[1]s, with the capi backend.
+# File is generated by gopy. Do not edit.
+# %[2]s
+
+GOCMD=go
+GOBUILD=$(GOCMD) build -mod=mod
+PYTHON=%[4]s
+LIBEXT=%[5]s
+
+# get the flags used to build python:
+CFLAGS = %[6]s
+LDFLAGS = %[7]s
+
+all: gen build
+
+gen:
+	%[3]s
+
+build:
+	# build target builds the generated files -- this is what gopy build does..
+	# generate %[1]s_go.h from %[1]s.go -- the header of the cgo wrappers to go functions,
+	# which %[1]s.c, the CPython wrappers to cgo wrappers, is only compiled with
+	- rm %[1]s_go.h
+	CGO_CFLAGS="$(CFLAGS) -fPIC" CGO_LDFLAGS="$(LDFLAGS)" $(GOBUILD) -buildmode=c-shared -o %[1]s_go$(LIBEXT) .
+	- rm %[1]s_go$(LIBEXT)
+	# build the _%[1]s$(LIBEXT) library that contains the cgo and CPython wrappers
+	CGO_CFLAGS="$(CFLAGS) -fPIC" CGO_LDFLAGS="$(LDFLAGS)" $(GOBUILD) -buildmode=c-shared -o _%[1]s$(LIBEXT) .
+
+`
+)
+
+// capiFunc is a function of the _ extension module, as declared in build.py
+// for pybindgen with declFunc, which the capi and ctypes backends wrap.
+type capiFunc struct {
+	name   string
+	ret    string // C type of the result, "" if none
+	owns   bool   // the caller owns the PyObject* result
+	free   bool   // the char* result is freed
+	params []capiParam
+}
+
+// capiParam is a param of a capiFunc
+type capiParam struct {
+	ctype string
+	name  string
+}
+
+// declFunc declares the function name of the _ extension module in build.py,
+// with the add pybindgen function (mod.add_function, add_checked_function or
+// add_checked_string_function), returning the ret C type, or None if "".
+// It also records it in g.funcs, from which the capi and ctypes backends,
+// which do not run build.py, generate the module.
+func (g *pyGen) declFunc(add, name, ret string, params ...capiParam) {
+	fn := &capiFunc{
+		name:   name,
+		ret:    ret,
+		owns:   ret == "PyObject*",
+		free:   add == "add_checked_string_function",
+		params: params,
+	}
+	g.funcs = append(g.funcs, fn)
+
+	if add == "mod.add_function" {
+		g.pybuild.Printf("mod.add_function('%s', ", name)
+	} else {
+		g.pybuild.Printf("%s(mod, '%s', ", add, name)
+	}
+	if ret == "" {
+		g.pybuild.Printf("None")
+	} else {
+		g.pybuild.Printf("%s", pyRetval(ret))
+	}
+	pstrs := make([]string, len(params))
+	for i, p := range params {
+		pstrs[i] = pyParam(p.ctype, p.name)
+	}
+	g.pybuild.Printf(", [%s])\n", strings.Join(pstrs, ", "))
+}
+
+// checkFuncs returns an error if a function of the _ extension module has a
+// C type that the given backend, which generates the module, does not support.
+func (g *pyGen) checkFuncs(backend string) error {
+	for _, fn := range g.funcs {
+		for _, ctype := range append([]string{fn.ret}, fn.paramTypes()...) {
+			if _, ok := capiTypes[ctype]; !ok && ctype != "" {
+				return fmt.Errorf("gopy: -backend=%s: unsupported C type %q of %s", backend, ctype, fn.name)
+			}
+		}
+	}
+	return nil
+}
+
+func (fn *capiFunc) paramTypes() []string {
+	ts := make([]string, len(fn.params))
+	for i, p := range fn.params {
+		ts[i] = p.ctype
+	}
+	return ts
+}
+
+// capiType is how the capi backend converts a C type of the declarations
+// of build.py from and to python.
+type capiType struct {
+	ctype  string // C type of the local variables
+	format string // PyArg_Parse format, O& with conv
+	conv   string // converter of the O& format
+	result string // C function building the python result
+}
+
+var capiTypes = map[string]capiType{
+	"bool":      {"int", "p", "", "PyBool_FromLong"},
+	"int":       {"int", "O&", "gopy_conv_int", "PyLong_FromLong"},
+	"int8_t":    {"int8_t", "O&", "gopy_conv_int8_t", "PyLong_FromLong"},
+	"int16_t":   {"int16_t", "O&", "gopy_conv_int16_t", "PyLong_FromLong"},
+	"int32_t":   {"int32_t", "O&", "gopy_conv_int32_t", "PyLong_FromLong"},
+	"int64_t":   {"int64_t", "O&", "gopy_conv_int64_t", "PyLong_FromLongLong"},
+	"uint8_t":   {"uint8_t", "O&", "gopy_conv_uint8_t", "PyLong_FromUnsignedLong"},
+	"uint16_t":  {"uint16_t", "O&", "gopy_conv_uint16_t", "PyLong_FromUnsignedLong"},
+	"uint32_t":  {"uint32_t", "O&", "gopy_conv_uint32_t", "PyLong_FromUnsignedLong"},
+	"uint64_t":  {"uint64_t", "O&", "gopy_conv_uint64_t", "PyLong_FromUnsignedLongLong"},
+	"float":     {"float", "f", "", "PyFloat_FromDouble"},
+	"double":    {"double", "d", "", "PyFloat_FromDouble"},
+	"char*":     {"const char*", "s", "", "gopy_str_result"},
+	"PyObject*": {"PyObject*", "O", "", ""},
+}
+
+// isCapi returns true if the C source of the _ extension module is
+// generated by gopy itself, with -backend=capi.
+func (g *pyGen) isCapi() bool {
+	return g.cfg.Backend == BackendCapi
+}
+
+// genCapiModule generates the C source of the _ extension module with the
+// capi backend, from the functions declared with declFunc, as build.py is
+// not written.
+func (g *pyGen) genCapiModule() {
+	if err := g.checkFuncs(BackendCapi); err != nil {
+		g.err.Add(err)
+		return
+	}
+	pr := &printer{buf: new(bytes.Buffer), indentEach: []byte("\t")}
+	limited := ""
+	if g.cfg.LimitedAPI {
+		limited = g.limitedAPIDefine() + "\n"
+	}
+	pr.Printf(CapiPreamble, g.cfg.Name, g.cfg.Cmd, limited)
+	pr.Printf("%s", capiConvDefs)
+	for _, fn := range g.funcs {
+		genCapiFunc(pr, fn)
+	}
+
+	pr.Printf("\nstatic PyMethodDef gopy_methods[] = {\n")
+	pr.Indent()
+	for _, fn := range g.funcs {
+		flags, sig := "METH_NOARGS", "(PyCFunction)gopy_w_"+fn.name
+		if len(fn.params) > 0 {
+			flags, sig = "METH_VARARGS|METH_KEYWORDS", "(PyCFunction)(void(*)(void))gopy_w_"+fn.name
+		}
+		names := make([]string, len(fn.params))
+		for i, p := range fn.params {
+			names[i] = p.name
+		}
+		// the docstring is the __text_signature__, as set by set_text_signatures
+		pr.Printf("{%q, %s, %s, \"%s(%s)\\n--\\n\\n\"},\n", fn.name, sig, flags, fn.name, strings.Join(names, ", "))
+	}
+	pr.Printf("{NULL, NULL, 0, NULL},\n")
+	pr.Outdent()
+	pr.Printf("};\n")
+
+	gil := "Py_MOD_GIL_USED"
+	if g.cfg.FreeThreaded {
+		gil = "Py_MOD_GIL_NOT_USED"
+	}
+	pr.Printf(capiModuleDefs, g.cfg.Name, gil)
+	g.genPrintOut(g.cfg.Name+".c", pr)
+}
+
+// genCapiFunc generates the C function wrapping the Go function fn, which
+// parses its python args, calls it with the GIL held, and raises the python
+// exception that it sets, if any, or returns its python result.
+func genCapiFunc(pr *printer, fn *capiFunc) {
+	if len(fn.params) == 0 {
+		pr.Printf("\nstatic PyObject* gopy_w_%s(PyObject* self, PyObject* unused) {\n", fn.name)
+		pr.Indent()
+	} else {
+		pr.Printf("\nstatic PyObject* gopy_w_%s(PyObject* self, PyObject* args, PyObject* kwargs) {\n", fn.name)
+		pr.Indent()
+		kws := ""
+		format := ""
+		var ptrs []string
+		for i, p := range fn.params {
+			ct := capiTypes[p.ctype]
+			kws += fmt.Sprintf("%q, ", p.name)
+			format += ct.format
+			pr.Printf("%s gopy_p%d;\n", ct.ctype, i)
+			if ct.conv != "" {
+				ptrs = append(ptrs, ct.conv)
+			}
+			ptrs = append(ptrs, fmt.Sprintf("&gopy_p%d", i))
+		}
+		pr.Printf("static char* kwlist[] = {%sNULL};\n", kws)
+		pr.Printf("if (!PyArg_ParseTupleAndKeywords(args, kwargs, \"%s:%s\", kwlist, %s)) {\n", format, fn.name, strings.Join(ptrs, ", "))
+		pr.Indent()
+		pr.Printf("return NULL;\n")
+		pr.Outdent()
+		pr.Printf("}\n")
+	}
+
+	var args []string
+	for i, p := range fn.params {
+		switch p.ctype {
+		case "char*":
+			args = append(args, fmt.Sprintf("(char*)gopy_p%d", i))
+		default:
+			args = append(args, fmt.Sprintf("gopy_p%d", i))
+		}
+	}
+	call := fmt.Sprintf("%s(%s)", fn.name, strings.Join(args, ", "))
+
+	cleanup := ""
+	switch {
+	case fn.ret == "":
+		pr.Printf("%s;\n", call)
+	case fn.ret == "char*":
+		pr.Printf("char* retval = %s;\n", call)
+		if fn.free {
+			cleanup = "free(retval);"
+		}
+	case fn.ret == "PyObject*":
+		pr.Printf("PyObject* retval = %s;\n", call)
+		if fn.owns {
+			cleanup = "Py_XDECREF(retval);"
+		}
+	default:
+		pr.Printf("%s retval = %s;\n", capiTypes[fn.ret].ctype, call)
+	}
+	pr.Printf("if (PyErr_Occurred()) {\n")
+	pr.Indent()
+	if cleanup != "" {
+		pr.Printf("%s\n", cleanup)
+	}
+	pr.Printf("return NULL;\n")
+	pr.Outdent()
+	pr.Printf("}\n")
+
+	switch {
+	case fn.ret == "":
+		pr.Printf("Py_RETURN_NONE;\n")
+	case fn.ret == "char*":
+		pr.Printf("PyObject* gopy_r = gopy_str_result(retval);\n")
+		if cleanup != "" {
+			pr.Printf("%s\n", cleanup)
+		}
+		pr.Printf("return gopy_r;\n")
+	case fn.ret == "PyObject*":
+		pr.Printf("if (retval == NULL) {\n")
+		pr.Indent()
+		pr.Printf("Py_RETURN_NONE;\n")
+		pr.Outdent()
+		pr.Printf("}\n")
+		if !fn.owns {
+			pr.Printf("Py_INCREF(retval);\n")
+		}
+		pr.Printf("return retval;\n")
+	default:
+		pr.Printf("return %s(retval);\n", capiTypes[fn.ret].result)
+	}
+	pr.Outdent()
+	pr.Printf("}\n")
+}
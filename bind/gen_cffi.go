@@ -0,0 +1,365 @@
+// Copyright 2019 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bind
+
+import "fmt"
+
+// the backends generating the C code of the _ extension module from the
+// declarations of build.py
+const (
+	// BackendPyBindGen generates it with pybindgen, the default
+	BackendPyBindGen = "pybindgen"
+	// BackendCffi generates it with cffi, which also supports PyPy
+	BackendCffi = "cffi"
+	// BackendCython generates it with cython, which copies the numeric
+	// slices from and to typed memoryviews in bulk
+	BackendCython = "cython"
+	// BackendCapi generates it with gopy itself, with the CPython C-API,
+	// without a python build dependency
+	BackendCapi = "capi"
+	// BackendCtypes generates a python module calling the Go library with
+	// ctypes instead, which needs no C compilation of its own
+	BackendCtypes = "ctypes"
+)
+
+const (
+	// PyBuildCffiPreamble starts the build script of the cffi backend.
+	// 1 = name of package (outname), 2 = gencmd
+	PyBuildCffiPreamble = `# python build stubs for package %[1]s, with the cffi backend
+# File is generated by gopy. Do not edit.
+# %[2]s
+`
+
+	// pyBuildCffiDefs are the definitions of the cffi backend in build.py,
+	// which replace those of pybindgen used by the declarations of the
+	// functions of the module, so that they are the same for both backends.
+	// The functions are called through C wrappers, which hold the GIL during
+	// the call, as the Go functions use the python C API while cffi releases
+	// it, and keep the python exception that they set, if any.  The _ python
+	// module wraps the cffi module with the API of the pybindgen one: the
+	// python objects and the exceptions are passed by address, with ctypes,
+	// which is not supported by PyPy, so it only supports the functions with
+	// C args and results, and raises their exceptions as RuntimeError.
+	pyBuildCffiDefs = `
+import os
+
+CFFI_SOURCE = '''
+#include <Python.h>
+#include <stdint.h>
+#include <stdlib.h>
+#include <string.h>
+%(includes)s
+
+static _Thread_local PyObject* gopy_cffi_exc_ = NULL;
+
+static void gopy_cffi_fetch(void) { // keeps the python exception set by the Go call, if any
+	PyObject *type, *value, *tb;
+	if (!PyErr_Occurred()) {
+		return;
+	}
+	PyErr_Fetch(&type, &value, &tb);
+	PyErr_NormalizeException(&type, &value, &tb);
+	if (tb != NULL) {
+		PyException_SetTraceback(value, tb);
+	}
+	Py_XDECREF(type);
+	Py_XDECREF(tb);
+	Py_XDECREF(gopy_cffi_exc_);
+	gopy_cffi_exc_ = value;
+}
+static uintptr_t gopy_cffi_exc(void) { // takes the exception kept for the calling thread, 0 if none
+	PyObject* exc = gopy_cffi_exc_;
+	gopy_cffi_exc_ = NULL;
+	return (uintptr_t)exc;
+}
+static char* gopy_cffi_exc_str(uintptr_t exc) { // str(exc), which the caller must free
+	PyGILState_STATE gil = PyGILState_Ensure();
+	PyObject* s = PyObject_Str((PyObject*)exc);
+	const char* cs = s != NULL ? PyUnicode_AsUTF8(s) : NULL;
+	char* r = strdup(cs != NULL ? cs : "unknown error");
+	Py_XDECREF(s);
+	PyErr_Clear();
+	PyGILState_Release(gil);
+	return r;
+}
+static void gopy_cffi_decref(uintptr_t o) {
+	PyGILState_STATE gil = PyGILState_Ensure();
+	Py_XDECREF((PyObject*)o);
+	PyGILState_Release(gil);
+}
+'''
+
+CFFI_CDEF = '''
+uintptr_t gopy_cffi_exc(void);
+char* gopy_cffi_exc_str(uintptr_t exc);
+void gopy_cffi_decref(uintptr_t o);
+void free(void* p);
+'''
+
+CFFI_MODULE = '''# python module %(name)s, wrapping the cffi module %(name)s_cffi with the API of the
+# module of the pybindgen backend.
+# File is generated by gopy. Do not edit.
+
+import ctypes as _ctypes
+import platform as _platform
+
+if __package__:
+	from . import %(name)s_cffi as _cffi
+else:
+	import %(name)s_cffi as _cffi
+
+_ffi, _lib = _cffi.ffi, _cffi.lib
+_cffi_pypy = _platform.python_implementation() == 'PyPy'
+
+def _cffi_str(s):
+	return s.encode('utf-8') if isinstance(s, str) else s
+
+def _cffi_id(o):
+	if _cffi_pypy:
+		raise NotImplementedError('gopy: python object args are not supported by the cffi backend on PyPy')
+	return id(o)
+
+def _cffi_string(r, free):
+	if r == _ffi.NULL:
+		return None
+	s = _ffi.string(r).decode('utf-8')
+	if free:
+		_lib.free(r)
+	return s
+
+def _cffi_object(r):
+	if r == 0:
+		return None
+	if _cffi_pypy:
+		_lib.gopy_cffi_decref(r)
+		raise NotImplementedError('gopy: python object results are not supported by the cffi backend on PyPy')
+	o = _ctypes.cast(r, _ctypes.py_object).value
+	_lib.gopy_cffi_decref(r)
+	return o
+
+def _cffi_check():
+	exc = _lib.gopy_cffi_exc()
+	if exc == 0:
+		return
+	if _cffi_pypy:
+		msg = _cffi_string(_lib.gopy_cffi_exc_str(exc), True)
+		_lib.gopy_cffi_decref(exc)
+		raise RuntimeError(msg)
+	raise _cffi_object(exc)
+
+'''
+
+def cffi_type(ctype):
+    """cffi_type returns the C type of the C wrapper of a function, for the type of pybindgen"""
+    if ctype is None:
+        return 'void'
+    return {'bool': '_Bool', 'PyObject*': 'uintptr_t'}.get(ctype, ctype)
+
+class param(object):
+    """param is a param of a function of the module, as declared for pybindgen"""
+    def __init__(self, ctype, name, transfer_ownership=True):
+        self.ctype = ctype
+        self.name = name
+        self.transfer_ownership = transfer_ownership
+
+class retval(object):
+    """retval is the result of a function of the module, as declared for pybindgen"""
+    def __init__(self, ctype, caller_owns_return=False):
+        self.ctype = ctype
+        self.caller_owns_return = caller_owns_return
+
+class Function(object):
+    """Function is a Go function exported by cgo, called by the python function of the same name
+    in the _ module, through its C wrapper in the cffi module.  The char* result is freed if free."""
+    def __init__(self, name, retval, params, free=False):
+        self.name = name
+        self.retval = retval
+        self.params = params
+        self.free = free
+
+    def rtype(self):
+        return self.retval.ctype if self.retval is not None else None
+
+    def cdecl(self):
+        params = ', '.join('%s %s' % (cffi_type(p.ctype), p.name) for p in self.params)
+        return '%s gopy_cffi_%s(%s)' % (cffi_type(self.rtype()), self.name, params or 'void')
+
+    def csource(self):
+        lines = [self.cdecl() + ' {', '\tPyGILState_STATE gopy_gil = PyGILState_Ensure();']
+        args = []
+        for p in self.params:
+            if p.ctype != 'PyObject*':
+                args.append(p.name)
+                continue
+            if p.transfer_ownership:
+                lines.append('\tPy_XINCREF((PyObject*)%s);' % p.name)
+            args.append('(PyObject*)%s' % p.name)
+        call = '%s(%s)' % (self.name, ', '.join(args))
+        rtype = self.rtype()
+        if rtype is None:
+            lines.append('\t%s;' % call)
+        elif rtype == 'PyObject*':
+            lines.append('\tPyObject* gopy_r = %s;' % call)
+            if not self.retval.caller_owns_return:
+                lines.append('\tPy_XINCREF(gopy_r);')
+        else:
+            lines.append('\t%s gopy_r = %s;' % (cffi_type(rtype), call))
+        lines.append('\tgopy_cffi_fetch();')
+        lines.append('\tPyGILState_Release(gopy_gil);')
+        if rtype == 'PyObject*':
+            lines.append('\treturn (uintptr_t)gopy_r;')
+        elif rtype is not None:
+            lines.append('\treturn gopy_r;')
+        lines.append('}')
+        return '\n'.join(lines) + '\n'
+
+    def pysource(self):
+        conv = {'char*': '_cffi_str', 'bool': 'bool', 'PyObject*': '_cffi_id'}
+        args = []
+        for p in self.params:
+            if p.ctype in conv:
+                args.append('%s(%s)' % (conv[p.ctype], p.name))
+            else:
+                args.append(p.name)
+        lines = ['def %s(%s):' % (self.name, ', '.join(p.name for p in self.params))]
+        lines.append('\t_gopy_r = _lib.gopy_cffi_%s(%s)' % (self.name, ', '.join(args)))
+        rtype = self.rtype()
+        if rtype == 'char*':
+            lines.append('\t_gopy_r = _cffi_string(_gopy_r, %s)' % self.free)
+        elif rtype == 'PyObject*':
+            lines.append('\t_gopy_r = _cffi_object(_gopy_r)')
+        lines.append('\t_cffi_check()')
+        if rtype is not None:
+            lines.append('\treturn _gopy_r')
+        return '\n'.join(lines) + '\n\n'
+
+class Module(object):
+    """Module is the _ module, whose functions are declared as for pybindgen, and which is
+    generated as the cffi module of its C wrappers, and the python module wrapping it"""
+    def __init__(self, name):
+        self.name = name
+        self.includes = []
+        self.functions = []
+
+    def add_include(self, include):
+        self.includes.append(include)
+
+    def add_function(self, name, retval, params, *a, **kw):
+        fn = Function(name, retval, params)
+        self.functions.append(fn)
+        return fn
+
+    def generate(self, out):
+        """generate writes the C source of the cffi module to the file out, which is compiled
+        along with the Go code, and the python module wrapping it, next to it"""
+        import cffi
+        fname = out.name
+        out.close()
+        ffi = cffi.FFI()
+        ffi.cdef(''.join(fn.cdecl() + ';\n' for fn in self.functions) + CFFI_CDEF)
+        includes = ''.join('#include %s\n' % inc for inc in self.includes)
+        src = CFFI_SOURCE % {'includes': includes}
+        src += ''.join(fn.csource() for fn in self.functions)
+        ffi.set_source(self.name + '_cffi', src)
+        ffi.emit_c_code(fname)
+        with open(os.path.join(os.path.dirname(os.path.abspath(fname)), self.name + '.py'), 'w') as f:
+            f.write(CFFI_MODULE % {'name': self.name})
+            for fn in self.functions:
+                f.write(fn.pysource())
+            f.write('GoPyInterpInit()\n')
+
+def add_checked_function(mod, name, retval, params, failure_expression='', *a, **kw):
+    return mod.add_function(name, retval, params)
+
+def add_checked_string_function(mod, name, retval, params, failure_expression='', *a, **kw):
+    fn = mod.add_function(name, retval, params)
+    fn.free = True
+    return fn
+`
+
+	// MakefileCffiTemplate is the Makefile of the cffi backend, which builds
+	// the C source generated by cffi along with the Go code, with go build.
+	// 1 = name of package (outname), 2 = gencmd, 3 = gen cmd, 4 = vm,
+	// 5 = libext, 6 = cflags, 7 = ldflags
+	MakefileCffiTemplate = `# Makefile for python interface for package %[1]s, with the cffi backend.
+# File is generated by gopy. Do not edit.
+# %[2]s
+
+GOCMD=go
+GOBUILD=$(GOCMD) build -mod=mod
+PYTHON=%[4]s
+LIBEXT=%[5]s
+
+# get the flags used to build python:
+CFLAGS = %[6]s
+LDFLAGS = %[7]s
+
+all: gen build
+
+gen:
+	%[3]s
+
+build:
+	# build target builds the generated files -- this is what gopy build does..
+	# this will otherwise be built during go build and may be out of date
+	- rm %[1]s.c
+	# generate %[1]s_go.h from %[1]s.go -- the header of the cgo wrappers to go functions
+	$(GOBUILD) -buildmode=c-shared -o %[1]s_go$(LIBEXT) .
+	- rm %[1]s_go$(LIBEXT)
+	# use cffi to build the %[1]s.c file of the _%[1]s_cffi module, and the _%[1]s.py module wrapping it
+	# note: pip install cffi to get cffi if this fails
+	$(PYTHON) build.py
+	# build the _%[1]s_cffi$(LIBEXT) library that contains the cgo and cffi wrappers
+	CGO_CFLAGS="$(CFLAGS) -fPIC" CGO_LDFLAGS="$(LDFLAGS)" $(GOBUILD) -buildmode=c-shared -o _%[1]s_cffi$(LIBEXT) .
+
+`
+)
+
+// isPyBindGen returns true if the _ extension module is generated by
+// pybindgen, the default backend.
+func (g *pyGen) isPyBindGen() bool {
+	return g.cfg.Backend == "" || g.cfg.Backend == BackendPyBindGen
+}
+
+// isCffi returns true if the _ extension module is generated by cffi,
+// with -backend=cffi.
+func (g *pyGen) isCffi() bool {
+	return g.cfg.Backend == BackendCffi
+}
+
+// checkBackend returns an error if the backend of the config is unknown, or
+// does not support the config, e.g., the exe mode, whose executable embeds
+// the pybindgen module.
+func checkBackend(mode BuildMode, cfg *BindCfg) error {
+	switch cfg.Backend {
+	case "", BackendPyBindGen, BackendCapi:
+		return nil
+	case BackendCffi:
+	case BackendCython:
+		if mode == ModeExe {
+			return fmt.Errorf("gopy: -backend=%s does not support exe", BackendCython)
+		}
+		return nil
+	case BackendCtypes:
+		switch {
+		case mode == ModeExe:
+			return fmt.Errorf("gopy: -backend=%s does not support exe", BackendCtypes)
+		case cfg.DevMode:
+			return fmt.Errorf("gopy: -backend=%s does not support -dev", BackendCtypes)
+		}
+		return nil
+	default:
+		return fmt.Errorf("gopy: invalid -backend %q: must be %s, %s, %s, %s or %s", cfg.Backend, BackendPyBindGen, BackendCffi, BackendCython, BackendCapi, BackendCtypes)
+	}
+	switch {
+	case mode == ModeExe:
+		return fmt.Errorf("gopy: -backend=%s does not support exe", BackendCffi)
+	case cfg.DevMode:
+		return fmt.Errorf("gopy: -backend=%s does not support -dev", BackendCffi)
+	case cfg.FreeThreaded:
+		return fmt.Errorf("gopy: -backend=%s does not support -free-threaded", BackendCffi)
+	}
+	return nil
+}
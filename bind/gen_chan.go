@@ -0,0 +1,404 @@
+// Copyright 2019 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bind
+
+import (
+	"go/types"
+	"strings"
+)
+
+// GoPkgChanDefs are the additional definitions in the go package with
+// python 3, which bridge Go channels to asyncio, see genChanInit.
+const GoPkgChanDefs = `
+def chan_queue(chan, maxsize=0):
+	"""chan_queue returns an asyncio.Queue into which the values received from the Go channel chan
+	are pumped by a background thread, until it is closed, after which go.nil is put into the queue.
+	It must be called from a coroutine, as the values are put into the queue by its event loop."""
+	import asyncio
+	import threading
+	loop = asyncio.get_running_loop()
+	q = asyncio.Queue(maxsize)
+	def pump():
+		for v in chan:
+			asyncio.run_coroutine_threadsafe(q.put(v), loop).result()
+		asyncio.run_coroutine_threadsafe(q.put(nil), loop).result()
+	threading.Thread(target=pump, daemon=True).start()
+	return q
+
+`
+
+// extTypes = these are types external to any targeted packages
+// pyWrapOnly = only generate python wrapper code, not go code
+func (g *pyGen) genChan(chn *symbol, extTypes, pyWrapOnly bool) {
+	if chn.isPointer() {
+		return
+	}
+	_, ok := chn.GoType().Underlying().(*types.Chan)
+	if !ok {
+		return
+	}
+
+	pkgname := chn.gopkg.Name()
+
+	pysnm := chn.id
+	if !strings.Contains(pysnm, "Chan_") {
+		pysnm = strings.TrimPrefix(pysnm, pkgname+"_")
+	}
+
+	gocl := "go."
+	if g.pkg == goPackage {
+		gocl = ""
+	}
+
+	if !extTypes || pyWrapOnly {
+		g.pywrap.Printf(`
+# Python type for channel %[4]s
+class %[2]s(%[5]sGoClass):
+	""%[3]q""
+`,
+			pkgname,
+			pysnm,
+			chn.doc,
+			chn.goname,
+			gocl,
+		)
+		g.pywrap.Indent()
+	}
+
+	g.genChanInit(chn, extTypes, pyWrapOnly)
+	if !extTypes || pyWrapOnly {
+		g.pywrap.Outdent()
+	}
+}
+
+func (g *pyGen) genChanInit(chn *symbol, extTypes, pyWrapOnly bool) {
+	pkgname := chn.gopkg.Name()
+	chNm := chn.id
+	qNm := g.cfg.Name + "." + chNm // this is only for referring to the _ .go package!
+	typ := chn.GoType().Underlying().(*types.Chan)
+	esym := g.syms.symtype(typ.Elem())
+	canSend := typ.Dir() != types.RecvOnly
+	canRecv := typ.Dir() != types.SendOnly
+
+	gocl := "go."
+	if g.pkg == goPackage {
+		gocl = ""
+	}
+
+	if !extTypes || pyWrapOnly {
+		g.pywrap.Printf("def __init__(self, *args, **kwargs):\n")
+		g.pywrap.Indent()
+		g.pywrap.Printf(`"""
+handle=A Go-side object is always initialized with an explicit handle=arg
+otherwise parameter is the optional buffer size of a new channel
+"""
+`)
+		g.pywrap.Printf("if len(kwargs) == 1 and 'handle' in kwargs:\n")
+		g.pywrap.Indent()
+		g.pywrap.Printf("self.handle = kwargs['handle']\n")
+		g.pywrap.Printf("_%s.IncRef(self.handle)\n", g.pypkgname)
+		g.pywrap.Outdent()
+		g.pywrap.Printf("elif len(args) == 1 and isinstance(args[0], %sGoClass):\n", gocl)
+		g.pywrap.Indent()
+		g.pywrap.Printf("self.handle = args[0].handle\n")
+		g.pywrap.Printf("_%s.IncRef(self.handle)\n", g.pypkgname)
+		g.pywrap.Outdent()
+		g.pywrap.Printf("else:\n")
+		g.pywrap.Indent()
+		g.pywrap.Printf("self.handle = 0 # for __del__ if the ctor fails\n")
+		if canSend && canRecv {
+			g.pywrap.Printf("self.handle = _%s_CTor(args[0] if len(args) > 0 else 0)\n", qNm)
+			g.pywrap.Printf("_%s.IncRef(self.handle)\n", g.pypkgname)
+		} else {
+			g.pywrap.Printf("raise TypeError('%s can only be obtained from Go')\n", chNm)
+		}
+		g.pywrap.Outdent()
+		g.pywrap.Outdent()
+
+		g.genDel()
+
+		g.pywrap.Printf("def __str__(self):\n")
+		g.pywrap.Indent()
+		g.pywrap.Printf("return '%s.%s len: ' + str(self.len()) + ' cap: ' + str(self.cap()) + ' handle: ' + str(self.handle)\n", pkgname, chNm)
+		g.pywrap.Outdent()
+
+		g.pywrap.Printf("def __repr__(self):\n")
+		g.pywrap.Indent()
+		g.pywrap.Printf("return '%s.%s(handle=' + str(self.handle) + ')'\n", pkgname, chNm)
+		g.pywrap.Outdent()
+
+		g.pywrap.Printf("def len(self):\n")
+		g.pywrap.Indent()
+		g.pywrap.Printf(`"""len returns the number of elements queued in the channel buffer"""
+`)
+		g.pywrap.Printf("return _%s_len(self.handle)\n", qNm)
+		g.pywrap.Outdent()
+
+		g.pywrap.Printf("def cap(self):\n")
+		g.pywrap.Indent()
+		g.pywrap.Printf(`"""cap returns the size of the channel buffer"""
+`)
+		g.pywrap.Printf("return _%s_cap(self.handle)\n", qNm)
+		g.pywrap.Outdent()
+
+		if canSend {
+			g.pywrap.Printf("def send(self, value):\n")
+			g.pywrap.Indent()
+			g.pywrap.Printf(`"""send sends value on the channel, blocking until it is received or buffered, or interrupted, e.g., by Ctrl-C.
+ValueError is raised if the channel is closed or nil.
+"""
+`)
+			if esym.hasHandle() {
+				g.pywrap.Printf("_%s_send(self.handle, value.handle)\n", qNm)
+			} else {
+				g.pywrap.Printf("_%s_send(self.handle, value)\n", qNm)
+			}
+			g.pywrap.Outdent()
+
+			g.pywrap.Printf("def close(self):\n")
+			g.pywrap.Indent()
+			g.pywrap.Printf(`"""close closes the channel.  ValueError is raised if it is already closed, or nil."""
+`)
+			g.pywrap.Printf("_%s_close(self.handle)\n", qNm)
+			g.pywrap.Outdent()
+		}
+
+		if canRecv {
+			g.pywrap.Printf("def recv(self, timeout=None):\n")
+			g.pywrap.Indent()
+			g.pywrap.Printf(`"""recv receives a value from the channel, blocking until one is available, or interrupted, e.g., by Ctrl-C.
+If timeout is given, TimeoutError is raised when no value is received within timeout seconds.
+EOFError is raised if the channel is closed and drained, and ValueError if it is nil.
+"""
+`)
+			g.pywrap.Printf("if timeout is None:\n")
+			g.pywrap.Indent()
+			g.pywrap.Printf("timeout = -1\n")
+			g.pywrap.Outdent()
+			if esym.hasHandle() {
+				g.pywrap.Printf("return %s(handle=_%s_recv(self.handle, timeout))\n", esym.pyPkgId(g, chn.gopkg), qNm)
+			} else {
+				g.pywrap.Printf("return _%s_recv(self.handle, timeout)\n", qNm)
+			}
+			g.pywrap.Outdent()
+
+			// iterating receives until the channel is closed, as with range in Go
+			g.pywrap.Printf("def __iter__(self):\n")
+			g.pywrap.Indent()
+			g.pywrap.Println("return self")
+			g.pywrap.Outdent()
+
+			if g.lang == 2 {
+				g.pywrap.Printf("def next(self):\n")
+			} else {
+				g.pywrap.Printf("def __next__(self):\n")
+			}
+			g.pywrap.Indent()
+			g.pywrap.Printf("try:\n")
+			g.pywrap.Indent()
+			g.pywrap.Println("return self.recv()")
+			g.pywrap.Outdent()
+			g.pywrap.Printf("except EOFError:\n")
+			g.pywrap.Indent()
+			g.pywrap.Println("raise StopIteration")
+			g.pywrap.Outdent()
+			g.pywrap.Outdent()
+
+			if g.lang != 2 {
+				g.pywrap.Printf("async def aiter(self, maxsize=0):\n")
+				g.pywrap.Indent()
+				g.pywrap.Printf(`"""aiter async iterates over the values received from the channel until it is closed,
+pumped into an asyncio.Queue of the given maxsize by %[1]schan_queue, e.g., async for v in c.aiter()
+"""
+`, gocl)
+				g.pywrap.Printf("q = %schan_queue(self, maxsize)\n", gocl)
+				g.pywrap.Printf("while True:\n")
+				g.pywrap.Indent()
+				g.pywrap.Printf("v = await q.get()\n")
+				g.pywrap.Printf("if v is %snil:\n", gocl)
+				g.pywrap.Indent()
+				g.pywrap.Printf("return\n")
+				g.pywrap.Outdent()
+				g.pywrap.Printf("yield v\n")
+				g.pywrap.Outdent()
+				g.pywrap.Outdent()
+			}
+		}
+	}
+
+	if !extTypes || !pyWrapOnly {
+		if canSend && canRecv {
+			// go ctor
+			ctNm := chNm + "_CTor"
+			g.gofile.Printf("\n// --- wrapping channel: %v ---\n", chn.goname)
+			g.gofile.Printf("//export %s\n", ctNm)
+			g.gofile.Printf("func %s(_size int) CGoHandle {\n", ctNm)
+			g.gofile.Indent()
+			g.gofile.Printf("c := make(%s, _size)\n", chn.goname)
+			g.gofile.Printf("return CGoHandle(handleFromPtr_%s(&c))\n", chNm)
+			g.gofile.Outdent()
+			g.gofile.Printf("}\n\n")
+
+			g.declFunc("mod.add_function", ctNm, PyHandle, capiParam{"int", "size"})
+		} else {
+			g.gofile.Printf("\n// --- wrapping channel: %v ---\n", chn.goname)
+		}
+
+		g.gofile.Printf("//export %s_len\n", chNm)
+		g.gofile.Printf("func %s_len(handle CGoHandle) int {\n", chNm)
+		g.gofile.Indent()
+		g.gofile.Printf("return len(deptrFromHandle_%s(handle))\n", chNm)
+		g.gofile.Outdent()
+		g.gofile.Printf("}\n\n")
+
+		g.declFunc("mod.add_function", chNm+"_len", "int", capiParam{PyHandle, "handle"})
+
+		g.gofile.Printf("//export %s_cap\n", chNm)
+		g.gofile.Printf("func %s_cap(handle CGoHandle) int {\n", chNm)
+		g.gofile.Indent()
+		g.gofile.Printf("return cap(deptrFromHandle_%s(handle))\n", chNm)
+		g.gofile.Outdent()
+		g.gofile.Printf("}\n\n")
+
+		g.declFunc("mod.add_function", chNm+"_cap", "int", capiParam{PyHandle, "handle"})
+
+		if canSend {
+			g.gofile.Printf("//export %s_send\n", chNm)
+			g.gofile.Printf("func %s_send(handle CGoHandle, _vl %s) {\n", chNm, esym.cgoname)
+			g.gofile.Indent()
+			g.genChanFromHandle(chn)
+			g.genChanRecover("send on closed channel")
+			if esym.py2go != "" {
+				g.gofile.Printf("v := %s(_vl)%s\n", esym.py2go, esym.py2goParenEx)
+			} else {
+				g.gofile.Printf("v := _vl\n")
+			}
+			// the GIL is released while blocked, and restored before
+			// the recovered panic of a closed channel is reported.
+			g.gofile.Printf(`gopyBlock(func(poll <-chan time.Time) bool {
+	select {
+	case c <- v:
+		return true
+	case <-poll:
+		return false
+	}
+})
+`)
+			g.gofile.Outdent()
+			g.gofile.Printf("}\n\n")
+
+			g.declFunc("add_checked_function", chNm+"_send", "", capiParam{PyHandle, "handle"}, capiParam{esym.cpyname, "value"})
+
+			g.gofile.Printf("//export %s_close\n", chNm)
+			g.gofile.Printf("func %s_close(handle CGoHandle) {\n", chNm)
+			g.gofile.Indent()
+			g.genChanFromHandle(chn)
+			g.genChanRecover("close of closed channel")
+			g.gofile.Printf("close(c)\n")
+			g.gofile.Outdent()
+			g.gofile.Printf("}\n\n")
+
+			g.declFunc("add_checked_function", chNm+"_close", "", capiParam{PyHandle, "handle"})
+		}
+
+		if canRecv {
+			timeoutExc := "C.PyExc_TimeoutError"
+			if g.lang == 2 {
+				timeoutExc = "C.PyExc_RuntimeError"
+			}
+			g.gofile.Printf("//export %s_recv\n", chNm)
+			g.gofile.Printf("func %s_recv(handle CGoHandle, _timeout float64) (_rv %s) {\n", chNm, esym.cgoname)
+			g.gofile.Indent()
+			g.genChanFromHandle(chn)
+			g.gofile.Printf("var v %s\n", esym.goname)
+			g.gofile.Printf("ok, timedOut := true, false\n")
+			g.gofile.Printf("var timeout <-chan time.Time // never without a timeout\n")
+			g.gofile.Printf("if _timeout >= 0 {\n")
+			g.gofile.Indent()
+			g.gofile.Printf("timeout = time.After(time.Duration(_timeout * float64(time.Second)))\n")
+			g.gofile.Outdent()
+			g.gofile.Printf("}\n")
+			// the GIL is released while blocked, so that other python
+			// threads run, e.g., the event loop fed by chan_queue.
+			g.gofile.Printf(`if !gopyBlock(func(poll <-chan time.Time) bool {
+	select {
+	case v, ok = <-c:
+	case <-timeout:
+		timedOut = true
+	case <-poll:
+		return false
+	}
+	return true
+}) {
+	return
+}
+`)
+			g.gofile.Printf("if timedOut {\n")
+			g.gofile.Indent()
+			g.gofile.Printf("gopySetError(%s, \"channel receive timed out\")\n", timeoutExc)
+			g.gofile.Printf("return\n")
+			g.gofile.Outdent()
+			g.gofile.Printf("}\n")
+			g.gofile.Printf("if !ok {\n")
+			g.gofile.Indent()
+			g.gofile.Printf("gopySetError(C.PyExc_EOFError, \"channel closed\")\n")
+			g.gofile.Printf("return\n")
+			g.gofile.Outdent()
+			g.gofile.Printf("}\n")
+			if esym.go2py != "" {
+				if esym.hasHandle() && !esym.isPtrOrIface() {
+					g.gofile.Printf("return %s(&v)%s\n", esym.go2py, esym.go2pyParenEx)
+				} else {
+					g.gofile.Printf("return %s(v)%s\n", esym.go2py, esym.go2pyParenEx)
+				}
+			} else {
+				g.gofile.Printf("return v\n")
+			}
+			g.gofile.Outdent()
+			g.gofile.Printf("}\n\n")
+
+			addFuncName := "add_checked_function"
+			if esym.cpyname == "char*" {
+				addFuncName = "add_checked_string_function"
+			}
+			g.declFunc(addFuncName, chNm+"_recv", esym.cpyname, capiParam{PyHandle, "handle"}, capiParam{"double", "timeout"})
+		}
+	}
+}
+
+// genChanFromHandle generates the lookup of the channel c of the handle
+// arg, raising a TypeError if the handle is not registered, and a
+// ValueError for a nil channel, on which the operations would block
+// forever, or panic for close.
+func (g *pyGen) genChanFromHandle(chn *symbol) {
+	g.gofile.Printf("vifc, __err := gopyh.VarFromHandleTry((gopyh.CGoHandle)(handle), %q)\n", chn.goname)
+	g.gofile.Printf("if __err != nil {\n")
+	g.gofile.Indent()
+	g.gofile.Printf("gopySetError(C.PyExc_TypeError, __err.Error())\n")
+	g.gofile.Printf("return\n")
+	g.gofile.Outdent()
+	g.gofile.Printf("}\n")
+	g.gofile.Printf("c := *vifc.(*%s)\n", chn.goname)
+	g.gofile.Printf("if c == nil {\n")
+	g.gofile.Indent()
+	g.gofile.Printf("gopySetError(C.PyExc_ValueError, \"nil channel\")\n")
+	g.gofile.Printf("return\n")
+	g.gofile.Outdent()
+	g.gofile.Printf("}\n")
+}
+
+// genChanRecover generates a deferred recover of the panic raised by
+// sending on, or closing, a closed channel, reporting it as a ValueError.
+func (g *pyGen) genChanRecover(msg string) {
+	g.gofile.Printf("defer func() {\n")
+	g.gofile.Indent()
+	g.gofile.Printf("if recover() != nil {\n")
+	g.gofile.Indent()
+	g.gofile.Printf("gopySetError(C.PyExc_ValueError, %q)\n", msg)
+	g.gofile.Outdent()
+	g.gofile.Printf("}\n")
+	g.gofile.Outdent()
+	g.gofile.Printf("}()\n")
+}
@@ -0,0 +1,154 @@
+// Copyright 2019 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bind
+
+const (
+	// goContextDefs are the exported functions for the contexts injected
+	// in the Go calls of the functions with a context.Context first arg.
+	goContextDefs = `
+// GoPyContextNew returns the id of a new context, to inject in a Go call,
+// with a timeout in seconds unless it is negative
+//export GoPyContextNew
+func GoPyContextNew(timeout float64) int64 {
+	return gopyh.NewContext(timeout)
+}
+
+// GoPyContextDeadline returns the id of a new context, cancelled at the
+// deadline in seconds since the Unix epoch
+//export GoPyContextDeadline
+func GoPyContextDeadline(deadline float64) int64 {
+	return gopyh.NewDeadlineContext(deadline)
+}
+
+// GoPyContextDerive returns the id of a new context derived from the one
+// with the given parent id, to inject in a Go call, with a timeout in
+// seconds unless it is negative
+//export GoPyContextDerive
+func GoPyContextDerive(parent int64, timeout float64) int64 {
+	return gopyh.DeriveContext(parent, timeout)
+}
+
+// GoPyContextCancel cancels the context with the given id
+//export GoPyContextCancel
+func GoPyContextCancel(id int64) {
+	gopyh.CancelContext(id)
+}
+`
+
+	// GoPkgContextDefs are the additional definitions in the go package with
+	// python 3, which cancel the contexts injected in the Go calls when the
+	// calls are interrupted or time out, and time out the other calls, and
+	// the contexts with a deadline that can be passed to the calls.
+	// 1 = name of package (outname)
+	GoPkgContextDefs = `
+class Context(object):
+	"""Context is a Go context.Context with a deadline, see with_deadline and with_timeout, which can be
+	passed as the ctx keyword of the functions and methods whose Go call takes a context, so that their
+	contexts are cancelled with it.  It is cancelled by cancel, or at the end of a with statement."""
+	def __init__(self, id):
+		self.id = id
+	def cancel(self):
+		"""cancel cancels the context, and the contexts of the calls that it was passed to"""
+		if self.id:
+			_%[1]s.GoPyContextCancel(self.id)
+			self.id = 0
+	def __enter__(self):
+		return self
+	def __exit__(self, *exc):
+		self.cancel()
+	def __del__(self):
+		try:
+			self.cancel()
+		except Exception:
+			pass
+
+def with_deadline(dt):
+	"""with_deadline returns a new Context cancelled at dt, a datetime.datetime, naive ones being in local
+	time, or a time.time() timestamp, like context.WithDeadline in Go"""
+	if hasattr(dt, 'timestamp'):
+		dt = dt.timestamp()
+	return Context(_%[1]s.GoPyContextDeadline(dt))
+
+def with_timeout(seconds):
+	"""with_timeout returns a new Context cancelled after the given seconds, like context.WithTimeout in Go"""
+	return Context(_%[1]s.GoPyContextNew(seconds))
+
+def _new_context(timeout, ctx):
+	if timeout is None:
+		timeout = -1
+	if ctx is None:
+		return _%[1]s.GoPyContextNew(timeout)
+	if not isinstance(ctx, Context):
+		raise TypeError('ctx must be a go.Context, not %%s' %% type(ctx).__name__)
+	return _%[1]s.GoPyContextDerive(ctx.id, timeout)
+
+def call_context(call, timeout=None, ctx=None):
+	"""call_context calls call with the id of a new context, injected in its Go call, which is cancelled
+	if the call is interrupted, e.g., by KeyboardInterrupt on Ctrl-C, or after timeout seconds, which
+	raises TimeoutError, or with the given Context ctx."""
+	cid = _new_context(timeout, ctx)
+	try:
+		return call(cid)
+	except BaseException:
+		_%[1]s.GoPyContextCancel(cid)
+		raise
+
+async def await_context(call, timeout=None, ctx=None):
+	"""await_context awaits call, run in the default executor of the event loop with the id of a new
+	context, injected in its Go call, which is cancelled if the awaiting task is cancelled, e.g.,
+	by asyncio.run on KeyboardInterrupt, or after timeout seconds, which raises TimeoutError, or
+	with the given Context ctx."""
+	import asyncio
+	cid = _new_context(timeout, ctx)
+	try:
+		return await asyncio.get_running_loop().run_in_executor(None, call, cid)
+	except BaseException:
+		_%[1]s.GoPyContextCancel(cid)
+		raise
+
+def call_timeout(call, timeout=None):
+	"""call_timeout calls call, raising TimeoutError if it does not return within timeout seconds.
+	The Go call cannot be cancelled without a context, so it keeps running in a worker thread."""
+	if timeout is None:
+		return call()
+	f = _context_executor().submit(call)
+	try:
+		return f.result(timeout)
+	except _futures.TimeoutError:
+		raise TimeoutError('call timed out after %%s seconds' %% timeout)
+
+async def await_timeout(call, timeout=None):
+	"""await_timeout awaits call, run in the default executor of the event loop, raising TimeoutError
+	if it does not return within timeout seconds.  The Go call cannot be cancelled without a context,
+	so it keeps running in the executor."""
+	import asyncio
+	try:
+		return await asyncio.wait_for(asyncio.get_running_loop().run_in_executor(None, call), timeout)
+	except asyncio.TimeoutError:
+		raise TimeoutError('call timed out after %%s seconds' %% timeout)
+
+import concurrent.futures as _futures
+import threading as _threading
+_executor = None
+_executor_lock = _threading.Lock()
+
+def _context_executor():
+	global _executor
+	with _executor_lock:
+		if _executor is None:
+			_executor = _futures.ThreadPoolExecutor(thread_name_prefix='gopy-context')
+		return _executor
+
+`
+)
+
+// declContextFuncs declares the functions of the contexts in build.py.
+func (g *pyGen) declContextFuncs() {
+	g.pybuild.Printf("\n")
+	g.declFunc("mod.add_function", "GoPyContextNew", "int64_t", capiParam{"double", "timeout"})
+	g.declFunc("mod.add_function", "GoPyContextDeadline", "int64_t", capiParam{"double", "deadline"})
+	g.declFunc("mod.add_function", "GoPyContextDerive", "int64_t", capiParam{"int64_t", "parent"}, capiParam{"double", "timeout"})
+	g.declFunc("mod.add_function", "GoPyContextCancel", "", capiParam{"int64_t", "id"})
+}
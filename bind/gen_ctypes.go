@@ -0,0 +1,218 @@
+// Copyright 2019 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bind
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+const (
+	// CtypesPreamble starts the _ python module generated by the ctypes
+	// backend, which calls the Go functions of the c-shared library with
+	// ctypes.  The library is loaded as a PyDLL, which holds the GIL during
+	// the calls, as the Go functions use the python C API, and raises the
+	// python exception that they set, if any.
+	// 1 = name of package (outname), 2 = gencmd, 3 = file name of the library
+	CtypesPreamble = `# python module _%[1]s, calling the Go functions of the %[3]s library with ctypes,
+# with the API of the extension module of the pybindgen backend.
+# File is generated by gopy. Do not edit.
+# %[2]s
+
+import ctypes as _ctypes
+import operator as _operator
+import os as _os
+
+_lib = _ctypes.PyDLL(_os.path.join(_os.path.dirname(_os.path.abspath(__file__)), '%[3]s'))
+_libc = _ctypes.cdll.msvcrt if _os.name == 'nt' else _ctypes.CDLL(None)
+_libc.free.argtypes = [_ctypes.c_void_p]
+_libc.free.restype = None
+_ctypes.pythonapi.Py_IncRef.argtypes = [_ctypes.py_object]
+_ctypes.pythonapi.Py_IncRef.restype = None
+_ctypes.pythonapi.Py_DecRef.argtypes = [_ctypes.c_void_p]
+_ctypes.pythonapi.Py_DecRef.restype = None
+
+def _int(lo, hi):
+	def conv(v):
+		v = _operator.index(v)
+		if v < lo or v > hi:
+			raise OverflowError('gopy: int out of range of the Go type')
+		return v
+	return conv
+
+_c_int = _int(-(1 << 31), (1 << 31) - 1)
+_c_int8 = _int(-(1 << 7), (1 << 7) - 1)
+_c_int16 = _int(-(1 << 15), (1 << 15) - 1)
+_c_int32 = _int(-(1 << 31), (1 << 31) - 1)
+_c_int64 = _int(-(1 << 63), (1 << 63) - 1)
+_c_uint8 = _int(0, (1 << 8) - 1)
+_c_uint16 = _int(0, (1 << 16) - 1)
+_c_uint32 = _int(0, (1 << 32) - 1)
+_c_uint64 = _int(0, (1 << 64) - 1)
+
+def _c_float(v):
+	if not hasattr(type(v), '__float__') and not hasattr(type(v), '__index__'):
+		raise TypeError('must be real number, not %%s' %% type(v).__name__)
+	return float(v)
+
+def _c_str(s):
+	if not isinstance(s, str):
+		raise TypeError('must be str, not %%s' %% type(s).__name__)
+	return s.encode('utf-8')
+
+def _py_str(r, free=False):
+	if not r:
+		return None
+	s = _ctypes.string_at(r).decode('utf-8')
+	if free:
+		_libc.free(r)
+	return s
+
+def _py_str_free(r):
+	return _py_str(r, True)
+
+def _py_object(r, owned=False):
+	if not r:
+		return None
+	o = _ctypes.cast(r, _ctypes.py_object).value
+	if owned:
+		_ctypes.pythonapi.Py_DecRef(r)
+	return o
+
+def _py_object_owned(r):
+	return _py_object(r, True)
+
+`
+
+	// MakefileCtypesTemplate is the Makefile of the ctypes backend, which
+	// only builds the c-shared library of the Go code, with go build.
+	// 1 = name of package (outname), 2 = gencmd, 3 = gen cmd, 4 = vm,
+	// 5 = libext, 6 = cflags, 7 = ldflags
+	MakefileCtypesTemplate = `# Makefile for python interface for package %[1]s, with the ctypes backend.
+# File is generated by gopy. Do not edit.
+# %[2]s
+
+GOCMD=go
+GOBUILD=$(GOCMD) build -mod=mod
+PYTHON=%[4]s
+LIBEXT=%[5]s
+
+# get the flags used to build python:
+CFLAGS = %[6]s
+LDFLAGS = %[7]s
+
+all: gen build
+
+gen:
+	%[3]s
+
+build:
+	# build target builds the generated files -- this is what gopy build does..
+	# build the _%[1]s_go$(LIBEXT) library of the cgo wrappers to go functions,
+	# which the _%[1]s.py module calls with ctypes
+	CGO_CFLAGS="$(CFLAGS) -fPIC" CGO_LDFLAGS="$(LDFLAGS)" $(GOBUILD) -buildmode=c-shared -o _%[1]s_go$(LIBEXT) .
+
+`
+)
+
+// ctypesTypes are the ctypes types of the C types of the declarations of
+// build.py, and the python functions converting the args to them, if any,
+// which raise the TypeError and OverflowError of the other backends, where
+// ctypes would raise an ArgumentError, or truncate the ints.
+var ctypesTypes = map[string][2]string{
+	"bool":      {"_ctypes.c_bool", "bool"},
+	"int":       {"_ctypes.c_int", "_c_int"},
+	"int8_t":    {"_ctypes.c_int8", "_c_int8"},
+	"int16_t":   {"_ctypes.c_int16", "_c_int16"},
+	"int32_t":   {"_ctypes.c_int32", "_c_int32"},
+	"int64_t":   {"_ctypes.c_int64", "_c_int64"},
+	"uint8_t":   {"_ctypes.c_uint8", "_c_uint8"},
+	"uint16_t":  {"_ctypes.c_uint16", "_c_uint16"},
+	"uint32_t":  {"_ctypes.c_uint32", "_c_uint32"},
+	"uint64_t":  {"_ctypes.c_uint64", "_c_uint64"},
+	"float":     {"_ctypes.c_float", "_c_float"},
+	"double":    {"_ctypes.c_double", "_c_float"},
+	"char*":     {"_ctypes.c_char_p", "_c_str"},
+	"PyObject*": {"_ctypes.py_object", ""},
+}
+
+// isCtypes returns true if the _ module is a python module calling the Go
+// library with ctypes, with -backend=ctypes.
+func (g *pyGen) isCtypes() bool {
+	return g.cfg.Backend == BackendCtypes
+}
+
+// ctypesLib returns the file name of the c-shared library of the Go code
+// loaded by the _ module of the ctypes backend.
+func ctypesLib(name, libext string) string {
+	return "_" + name + "_go" + libext
+}
+
+// genCtypesModule generates the _ python module of the ctypes backend, from
+// the functions declared with declFunc, as build.py is not written.
+func (g *pyGen) genCtypesModule() {
+	if err := g.checkFuncs(BackendCtypes); err != nil {
+		g.err.Add(err)
+		return
+	}
+	pr := &printer{buf: new(bytes.Buffer), indentEach: []byte("\t")}
+	pr.Printf(CtypesPreamble, g.cfg.Name, g.cfg.Cmd, ctypesLib(g.cfg.Name, g.libext))
+	for _, fn := range g.funcs {
+		genCtypesFunc(pr, fn)
+	}
+	pr.Printf("_lib.GoPyInterpInit.argtypes = []\n")
+	pr.Printf("_lib.GoPyInterpInit.restype = None\n")
+	pr.Printf("_lib.GoPyInterpInit()\n")
+	g.genPrintOut("_"+g.cfg.Name+".py", pr)
+}
+
+// genCtypesFunc generates the python function calling the Go function fn,
+// which converts its args to their C types, and its result to python.
+func genCtypesFunc(pr *printer, fn *capiFunc) {
+	var names, argtypes, args []string
+	for _, p := range fn.params {
+		ct := ctypesTypes[p.ctype]
+		names = append(names, p.name)
+		argtypes = append(argtypes, ct[0])
+		switch {
+		case ct[1] != "":
+			args = append(args, fmt.Sprintf("%s(%s)", ct[1], p.name))
+		default:
+			args = append(args, p.name)
+		}
+	}
+	restype := "None"
+	switch fn.ret {
+	case "":
+	case "char*", "PyObject*":
+		// converted by hand, as the result may be NULL, or freed
+		restype = "_ctypes.c_void_p"
+	default:
+		restype = ctypesTypes[fn.ret][0]
+	}
+	pr.Printf("_lib.%s.argtypes = [%s]\n", fn.name, strings.Join(argtypes, ", "))
+	pr.Printf("_lib.%s.restype = %s\n", fn.name, restype)
+	pr.Printf("def %s(%s):\n", fn.name, strings.Join(names, ", "))
+	pr.Indent()
+	call := fmt.Sprintf("_lib.%s(%s)", fn.name, strings.Join(args, ", "))
+	conv := ""
+	switch {
+	case fn.ret == "char*" && fn.free:
+		conv = "_py_str_free"
+	case fn.ret == "char*":
+		conv = "_py_str"
+	case fn.ret == "PyObject*" && fn.owns:
+		conv = "_py_object_owned"
+	case fn.ret == "PyObject*":
+		conv = "_py_object"
+	}
+	if conv != "" {
+		call = fmt.Sprintf("%s(%s)", conv, call)
+	}
+	pr.Printf("return %s\n", call)
+	pr.Outdent()
+	pr.Printf("\n")
+}
@@ -0,0 +1,350 @@
+// Copyright 2019 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bind
+
+const (
+	// PyBuildCythonPreamble starts the build script of the cython backend.
+	// 1 = name of package (outname), 2 = gencmd
+	PyBuildCythonPreamble = `# python build stubs for package %[1]s, with the cython backend
+# File is generated by gopy. Do not edit.
+# %[2]s
+`
+
+	// pyBuildCythonDefs are the definitions of the cython backend in build.py,
+	// which replace those of pybindgen used by the declarations of the
+	// functions of the module, as for the cffi backend.  The module is
+	// generated as the .pyx source of the _ module, whose def functions call
+	// the Go functions declared from the cgo header, with the GIL held as for
+	// pybindgen, and raise the python exception that they set, if any.  It is
+	// compiled to the C source of the extension module by cython, which is
+	// built along with the Go code as for pybindgen.  The numeric slices also
+	// get functions copying their elements from and to a typed memoryview in
+	// one call to Go, see genSliceCythonGo.
+	pyBuildCythonDefs = `
+import os, struct, sys
+
+CYTHON_HEADER = '''# cython: language_level=3%(directives)s
+# extension module %(name)s of the cython backend
+# File is generated by gopy. Do not edit.
+
+from cpython.ref cimport PyObject, Py_XINCREF, Py_XDECREF
+from libc.stdint cimport int8_t, int16_t, int32_t, int64_t, uint8_t, uint16_t, uint32_t, uint64_t
+from libc.stdlib cimport free
+import array as _array
+
+'''
+
+CYTHON_BUFFER = '''
+def %(name)s_extend(int64_t handle, values):
+	"""%(name)s_extend(handle, values) appends the elements of values, a buffer or a sequence
+	of numbers, to the slice, copying them in one call to Go"""
+	cdef const %(ctype)s[::1] gopy_v
+	try:
+		gopy_v = values
+	except (TypeError, ValueError, BufferError):
+		gopy_v = _array.array('%(code)s', values)
+	if gopy_v.shape[0] > 0:
+		gopy_%(name)s_extend_n(handle, <void*>&gopy_v[0], gopy_v.shape[0])
+
+def %(name)s_array(int64_t handle, Py_ssize_t n):
+	"""%(name)s_array(handle, n) returns an array.array of up to n elements of the slice,
+	copying them in one call to Go"""
+	cdef %(ctype)s[::1] gopy_v
+	a = _array.array('%(code)s', bytes(max(n, 0) * %(size)d))
+	gopy_v = a
+	if n > 0:
+		n = gopy_%(name)s_copy_n(handle, <void*>&gopy_v[0], n)
+	return a if n == len(a) else a[:n]
+'''
+
+# the C types of the elements of the typed memoryviews of the buffer formats,
+# and the array.array typecodes of the same size for the sequences
+CYTHON_BUFFER_TYPES = {
+    'b': 'signed char', 'B': 'unsigned char', 'h': 'short', 'H': 'unsigned short',
+    'i': 'int', 'I': 'unsigned int', 'q': 'long long', 'Q': 'unsigned long long',
+    'n': 'Py_ssize_t', 'N': 'size_t', 'f': 'float', 'd': 'double',
+}
+CYTHON_ARRAY_CODES = {
+    'n': 'q' if struct.calcsize('n') == 8 else 'i',
+    'N': 'Q' if struct.calcsize('N') == 8 else 'I',
+}
+
+# the names that can not be used for the params of the def functions
+CYTHON_RESERVED = set('''api bint by char cdef cimport const cpdef ctypedef double enum extern
+float free fused gil include inline int long namespace new noexcept nogil NULL object public
+readonly short signed sizeof struct union unsigned void PyObject Py_XINCREF Py_XDECREF
+int8_t int16_t int32_t int64_t uint8_t uint16_t uint32_t uint64_t'''.split())
+
+def cython_type(ctype):
+    """cython_type returns the cython type of the declaration of a Go function, for the type of pybindgen"""
+    if ctype is None:
+        return 'void'
+    return {'bool': 'bint'}.get(ctype, ctype)
+
+def cython_name(name):
+    return name + '_' if name in CYTHON_RESERVED else name
+
+class param(object):
+    """param is a param of a function of the module, as declared for pybindgen"""
+    def __init__(self, ctype, name, transfer_ownership=True):
+        self.ctype = ctype
+        self.name = name
+        self.transfer_ownership = transfer_ownership
+
+class retval(object):
+    """retval is the result of a function of the module, as declared for pybindgen"""
+    def __init__(self, ctype, caller_owns_return=False):
+        self.ctype = ctype
+        self.caller_owns_return = caller_owns_return
+
+class Function(object):
+    """Function is a Go function exported by cgo, declared as gopy_<name> in the module, and
+    called by its def function of the same name.  The char* result is freed if free."""
+    def __init__(self, name, retval, params, free=False):
+        self.name = name
+        self.retval = retval
+        self.params = params
+        self.free = free
+
+    def rtype(self):
+        return self.retval.ctype if self.retval is not None else None
+
+    def decl(self):
+        params = ', '.join('%s %s' % (cython_type(p.ctype), cython_name(p.name)) for p in self.params)
+        return '%s gopy_%s "%s"(%s) except *' % (cython_type(self.rtype()), self.name, self.name, params)
+
+    def source(self):
+        names = [cython_name(p.name) for p in self.params]
+        lines = ['def %s(%s):' % (self.name, ', '.join(names))]
+        args = []
+        for p, nm in zip(self.params, names):
+            if p.ctype == 'char*':
+                lines.append("\tcdef bytes gopy_%s = %s.encode('utf-8') if isinstance(%s, str) else %s" % (nm, nm, nm, nm))
+                args.append('gopy_' + nm)
+            elif p.ctype == 'PyObject*':
+                if p.transfer_ownership:
+                    lines.append('\tPy_XINCREF(<PyObject*>%s)' % nm)
+                args.append('<PyObject*>' + nm)
+            else:
+                args.append(nm)
+        call = 'gopy_%s(%s)' % (self.name, ', '.join(args))
+        rtype = self.rtype()
+        if rtype is None:
+            lines.append('\t' + call)
+        elif rtype == 'char*':
+            lines.append('\tcdef char* gopy_r = ' + call)
+            lines.append('\tif gopy_r == NULL:')
+            lines.append('\t\treturn None')
+            lines.append("\tgopy_s = gopy_r.decode('utf-8')")
+            if self.free:
+                lines.append('\tfree(gopy_r)')
+            lines.append('\treturn gopy_s')
+        elif rtype == 'PyObject*':
+            lines.append('\tcdef PyObject* gopy_r = ' + call)
+            lines.append('\tif gopy_r == NULL:')
+            lines.append('\t\treturn None')
+            lines.append('\tgopy_o = <object>gopy_r')
+            if self.retval.caller_owns_return:
+                lines.append('\tPy_XDECREF(gopy_r)')
+            lines.append('\treturn gopy_o')
+        else:
+            lines.append('\treturn ' + call)
+        return '\n'.join(lines) + '\n\n'
+
+class SliceBuffer(object):
+    """SliceBuffer is a numeric slice, whose elements of the given buffer format are copied
+    from and to a typed memoryview by the Go functions <name>_extend_n and <name>_copy_n"""
+    def __init__(self, name, fmt):
+        self.name = name
+        self.fmt = fmt
+
+    def decl(self):
+        return ('void gopy_%(n)s_extend_n "%(n)s_extend_n"(int64_t handle, void* p, Py_ssize_t n) except *\n'
+                '\tPy_ssize_t gopy_%(n)s_copy_n "%(n)s_copy_n"(int64_t handle, void* p, Py_ssize_t n) except *') % {'n': self.name}
+
+    def source(self):
+        code = CYTHON_ARRAY_CODES.get(self.fmt, self.fmt)
+        return CYTHON_BUFFER % {'name': self.name, 'ctype': CYTHON_BUFFER_TYPES[self.fmt], 'code': code, 'size': struct.calcsize(code)}
+
+class Module(object):
+    """Module is the _ module, whose functions are declared as for pybindgen, and which is
+    generated as the .pyx source of the cython module of their def functions"""
+    def __init__(self, name, directives=''):
+        self.name = name
+        self.directives = directives
+        self.includes = []
+        self.functions = []
+        self.buffers = []
+
+    def add_include(self, include):
+        self.includes.append(include)
+
+    def add_function(self, name, retval, params, *a, **kw):
+        fn = Function(name, retval, params)
+        self.functions.append(fn)
+        return fn
+
+    def add_slice_buffer(self, name, fmt):
+        self.buffers.append(SliceBuffer(name, fmt))
+
+    def generate(self, out):
+        """generate writes the C source of the cython module to the file out, which is compiled
+        along with the Go code, from its .pyx source, written next to it"""
+        from Cython.Compiler.Main import compile, CompilationOptions
+        fname = out.name
+        out.close()
+        pyx = os.path.join(os.path.dirname(os.path.abspath(fname)), self.name + '.pyx')
+        with open(pyx, 'w') as f:
+            f.write(CYTHON_HEADER % {'name': self.name, 'directives': self.directives})
+            for i, inc in enumerate(self.includes):
+                f.write('cdef extern from %s:\n' % inc)
+                if i > 0:
+                    f.write('\tpass\n\n')
+                    continue
+                for fn in self.functions + self.buffers:
+                    f.write('\t%s\n' % fn.decl())
+                f.write('\n')
+            for fn in self.functions + self.buffers:
+                f.write(fn.source())
+            f.write('GoPyInterpInit()\n')
+        res = compile(pyx, CompilationOptions(output_file=fname), full_module_name=self.name)
+        if res.num_errors > 0:
+            sys.exit('gopy: cython failed to compile %s' % pyx)
+
+def add_checked_function(mod, name, retval, params, failure_expression='', *a, **kw):
+    return mod.add_function(name, retval, params)
+
+def add_checked_string_function(mod, name, retval, params, failure_expression='', *a, **kw):
+    fn = mod.add_function(name, retval, params)
+    fn.free = True
+    return fn
+`
+
+	// MakefileCythonTemplate is the Makefile of the cython backend, which
+	// builds the C source generated by cython along with the Go code, with
+	// go build.
+	// 1 = name of package (outname), 2 = gencmd, 3 = gen cmd, 4 = vm,
+	// 5 = libext, 6 = cflags, 7 = ldflags
+	MakefileCythonTemplate = `# Makefile for python interface for package %[1]s, with the cython backend.
+# File is generated by gopy. Do not edit.
+# %[2]s
+
+GOCMD=go
+GOBUILD=$(GOCMD) build -mod=mod
+PYTHON=%[4]s
+LIBEXT=%[5]s
+
+# get the flags used to build python:
+CFLAGS = %[6]s
+LDFLAGS = %[7]s
+
+all: gen build
+
+gen:
+	%[3]s
+
+build:
+	# build target builds the generated files -- this is what gopy build does..
+	# this will otherwise be built during go build and may be out of date
+	- rm %[1]s.c
+	# generate %[1]s_go.h from %[1]s.go -- the header of the cgo wrappers to go functions
+	$(GOBUILD) -buildmode=c-shared -o %[1]s_go$(LIBEXT) .
+	- rm %[1]s_go$(LIBEXT)
+	# use cython to build the %[1]s.c file from the _%[1]s.pyx module of the CPython wrappers to cgo wrappers
+	# note: pip install cython to get cython if this fails
+	$(PYTHON) build.py
+	# build the _%[1]s$(LIBEXT) library that contains the cgo and CPython wrappers
+	CGO_CFLAGS="$(CFLAGS) -fPIC" CGO_LDFLAGS="$(LDFLAGS)" $(GOBUILD) -buildmode=c-shared -o _%[1]s$(LIBEXT) .
+
+`
+)
+
+// isCython returns true if the _ extension module is generated by cython,
+// with -backend=cython.
+func (g *pyGen) isCython() bool {
+	return g.cfg.Backend == BackendCython
+}
+
+// cythonDirectives returns the cython compiler directives of the module,
+// after the language level in the header of its .pyx source.
+func (g *pyGen) cythonDirectives() string {
+	if g.cfg.FreeThreaded {
+		return ", freethreading_compatible=True"
+	}
+	return ""
+}
+
+// isCythonBuffer returns true if the elements of the slice slc are copied
+// from and to python in bulk, with the typed memoryviews of the cython
+// backend, instead of one call to Go per element.
+func (g *pyGen) isCythonBuffer(slc *symbol, esym *symbol) bool {
+	return g.isCython() && slc.isSlice() && bufferFormat(esym) != "" && g.lang != 2
+}
+
+// genSliceCython generates the python tolist method of a numeric slice with
+// the cython backend, which copies all the elements in one call to Go.
+func (g *pyGen) genSliceCython(slc *symbol, esym *symbol) {
+	if !g.isCythonBuffer(slc, esym) {
+		return
+	}
+	qNm := g.cfg.Name + "." + slc.id
+	g.pywrap.Printf("def tolist(self):\n")
+	g.pywrap.Indent()
+	g.pywrap.Printf(`"""tolist() list
+
+tolist returns a list of the elements, as self[i] for each i, but copying
+all of them in one call to Go.
+"""
+`)
+	g.pywrap.Printf("return _%s_array(self.handle, len(self)).tolist()\n", qNm)
+	g.pywrap.Outdent()
+}
+
+// genSliceCythonGo generates the Go side of the bulk copies of the elements
+// of a numeric slice from and to the memory of a typed memoryview of the
+// cython module, see pyBuildCythonDefs.
+func (g *pyGen) genSliceCythonGo(slc *symbol, esym *symbol) {
+	if !g.isCythonBuffer(slc, esym) {
+		return
+	}
+	slNm := slc.id
+
+	g.gofile.Printf("//export %s_extend_n\n", slNm)
+	g.gofile.Printf("func %s_extend_n(handle CGoHandle, p unsafe.Pointer, n C.Py_ssize_t) {\n", slNm)
+	g.gofile.Indent()
+	g.genValueLock("ptrFromHandle_" + slNm + "(handle)")
+	g.gofile.Printf("if n <= 0 {\n")
+	g.gofile.Indent()
+	g.gofile.Printf("return\n")
+	g.gofile.Outdent()
+	g.gofile.Printf("}\n")
+	g.gofile.Printf("s := ptrFromHandle_%s(handle)\n", slNm)
+	g.gofile.Printf("i := len(*s)\n")
+	g.gofile.Printf("*s = append(*s, make(%s, int(n))...)\n", slc.goname)
+	g.gofile.Printf("C.memcpy(unsafe.Pointer(&(*s)[i]), p, C.size_t(n)*C.size_t(unsafe.Sizeof((*s)[0])))\n")
+	g.gofile.Outdent()
+	g.gofile.Printf("}\n\n")
+
+	g.gofile.Printf("//export %s_copy_n\n", slNm)
+	g.gofile.Printf("func %s_copy_n(handle CGoHandle, p unsafe.Pointer, n C.Py_ssize_t) C.Py_ssize_t {\n", slNm)
+	g.gofile.Indent()
+	g.genValueLock("ptrFromHandle_" + slNm + "(handle)")
+	g.gofile.Printf("s := deptrFromHandle_%s(handle)\n", slNm)
+	g.gofile.Printf("if int(n) > len(s) {\n")
+	g.gofile.Indent()
+	g.gofile.Printf("n = C.Py_ssize_t(len(s))\n")
+	g.gofile.Outdent()
+	g.gofile.Printf("}\n")
+	g.gofile.Printf("if n > 0 {\n")
+	g.gofile.Indent()
+	g.gofile.Printf("C.memcpy(p, unsafe.Pointer(&s[0]), C.size_t(n)*C.size_t(unsafe.Sizeof(s[0])))\n")
+	g.gofile.Outdent()
+	g.gofile.Printf("}\n")
+	g.gofile.Printf("return n\n")
+	g.gofile.Outdent()
+	g.gofile.Printf("}\n\n")
+
+	g.pybuild.Printf("mod.add_slice_buffer('%s', '%s')\n", slNm, bufferFormat(esym))
+}
@@ -0,0 +1,132 @@
+// Copyright 2019 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bind
+
+const (
+	// goDateTimePreambleC are the C helpers for the conversions of time.Time
+	// and time.Duration to and from python datetime and timedelta, when
+	// generating with -datetime
+	goDateTimePreambleC = `
+#include <datetime.h>
+static inline int gopy_datetime_import() {
+	if(PyDateTimeAPI == NULL) {
+		PyDateTime_IMPORT;
+	}
+	return PyDateTimeAPI != NULL;
+}
+static inline PyObject* gopy_datetime(int* f) { // aware datetime in UTC from the y, mo, d, h, mi, s, us fields
+	if(!gopy_datetime_import()) {
+		return NULL;
+	}
+#if PY_VERSION_HEX >= 0x03070000
+	PyObject* tz = PyDateTime_TimeZone_UTC;
+#else
+	PyObject* tz = Py_None;
+#endif
+	return PyDateTimeAPI->DateTime_FromDateAndTime(f[0], f[1], f[2], f[3], f[4], f[5], f[6], tz, PyDateTimeAPI->DateTimeType);
+}
+static inline int gopy_datetime_fields(PyObject* o, int* f, int* off) { // 1 if aware, with the utc offset in seconds, 0 if naive, -1 on error
+	if(!gopy_datetime_import()) {
+		return -1;
+	}
+	if(!PyDateTime_Check(o)) {
+		PyErr_SetString(PyExc_TypeError, "a datetime.datetime is required");
+		return -1;
+	}
+	f[0] = PyDateTime_GET_YEAR(o);
+	f[1] = PyDateTime_GET_MONTH(o);
+	f[2] = PyDateTime_GET_DAY(o);
+	f[3] = PyDateTime_DATE_GET_HOUR(o);
+	f[4] = PyDateTime_DATE_GET_MINUTE(o);
+	f[5] = PyDateTime_DATE_GET_SECOND(o);
+	f[6] = PyDateTime_DATE_GET_MICROSECOND(o);
+	PyObject* td = PyObject_CallMethod(o, "utcoffset", NULL);
+	if(td == NULL) {
+		return -1;
+	}
+	int aware = 0;
+	if(td != Py_None) {
+		*off = ((PyDateTime_Delta*)td)->days * 86400 + ((PyDateTime_Delta*)td)->seconds;
+		aware = 1;
+	}
+	Py_DECREF(td);
+	return aware;
+}
+static inline PyObject* gopy_timedelta(int days, int secs, int us) {
+	if(!gopy_datetime_import()) {
+		return NULL;
+	}
+	return PyDateTimeAPI->Delta_FromDelta(days, secs, us, 1, PyDateTimeAPI->DeltaType);
+}
+static inline int gopy_timedelta_fields(PyObject* o, int* f, double* secs) { // 1 for a timedelta, with the days, s, us fields, 0 for a number of seconds, -1 on error
+	if(!gopy_datetime_import()) {
+		return -1;
+	}
+	if(PyDelta_Check(o)) {
+		f[0] = ((PyDateTime_Delta*)o)->days;
+		f[1] = ((PyDateTime_Delta*)o)->seconds;
+		f[2] = ((PyDateTime_Delta*)o)->microseconds;
+		return 1;
+	}
+	if(PyFloat_Check(o) || PyIndex_Check(o)) {
+		*secs = PyFloat_AsDouble(o);
+		return (*secs == -1.0 && PyErr_Occurred()) ? -1 : 0;
+	}
+	PyErr_SetString(PyExc_TypeError, "a datetime.timedelta or a number of seconds is required");
+	return -1;
+}
+`
+
+	// goDateTimeDefs are the Go conversions of time.Time and time.Duration to
+	// and from python datetime and timedelta, when generating with -datetime
+	goDateTimeDefs = `
+// timeGoToPy converts a Go time.Time to an aware python datetime in UTC,
+// with microsecond resolution
+func timeGoToPy(t time.Time) *C.PyObject {
+	u := t.UTC()
+	f := [7]C.int{C.int(u.Year()), C.int(u.Month()), C.int(u.Day()), C.int(u.Hour()), C.int(u.Minute()), C.int(u.Second()), C.int(u.Nanosecond() / 1000)}
+	return C.gopy_datetime(&f[0])
+}
+
+// timePyToGo converts a python datetime to a Go time.Time, where a
+// naive datetime is in local time, as in python
+func timePyToGo(o *C.PyObject) time.Time {
+	var f [7]C.int
+	var off C.int
+	loc := time.Local
+	switch C.gopy_datetime_fields(o, &f[0], &off) {
+	case -1:
+		return time.Time{} // python error is set
+	case 1:
+		loc = time.FixedZone("", int(off))
+		if off == 0 {
+			loc = time.UTC
+		}
+	}
+	return time.Date(int(f[0]), time.Month(f[1]), int(f[2]), int(f[3]), int(f[4]), int(f[5]), int(f[6])*1000, loc)
+}
+
+// durationGoToPy converts a Go time.Duration to a python timedelta,
+// with microsecond resolution
+func durationGoToPy(d time.Duration) *C.PyObject {
+	us := int64(d / time.Microsecond)
+	return C.gopy_timedelta(C.int(us/86400e6), C.int(us%86400e6/1e6), C.int(us%1e6))
+}
+
+// durationPyToGo converts a python timedelta, or a number of seconds,
+// to a Go time.Duration
+func durationPyToGo(o *C.PyObject) time.Duration {
+	var f [3]C.int
+	var secs C.double
+	switch C.gopy_timedelta_fields(o, &f[0], &secs) {
+	case -1:
+		return 0 // python error is set
+	case 1:
+		return time.Duration(f[0])*24*time.Hour + time.Duration(f[1])*time.Second + time.Duration(f[2])*time.Microsecond
+	}
+	return time.Duration(float64(secs) * float64(time.Second))
+}
+`
+)
@@ -0,0 +1,106 @@
+// Copyright 2019 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bind
+
+import (
+	"bytes"
+	"fmt"
+)
+
+const (
+	// PyDevLoader is the dev mode loader module for the _ extension module,
+	// which is imported in its place by all of the python wrappers.
+	// 1 = name of package (outname), 2 = cmdstr
+	PyDevLoader = `# dev mode loader for the _%[1]s extension module, which supports
+# reloading a rebuilt library into a running python session (python 3.7+).
+# File is generated by gopy. Do not edit.
+# %[2]s
+
+import os, shutil, tempfile
+import importlib.machinery, importlib.util
+
+_dir = os.path.dirname(os.path.abspath(__file__))
+_lib = None
+generation = 0
+
+def _libpath():
+	for sfx in importlib.machinery.EXTENSION_SUFFIXES:
+		p = os.path.join(_dir, '_%[1]s' + sfx)
+		if os.path.exists(p):
+			return p
+	raise ImportError('gopy: could not find the _%[1]s extension module in ' + _dir)
+
+def reload():
+	"""reload loads the current build of the _%[1]s library, returning the new generation number.
+	A Go library can not be unloaded, so each build is loaded from a fresh copy,
+	and the objects obtained from previous generations are no longer valid."""
+	global _lib, generation
+	src = _libpath()
+	tmp = tempfile.mkdtemp(prefix='gopy_%[1]s_')
+	dst = os.path.join(tmp, os.path.basename(src))
+	shutil.copy2(src, dst)
+	loader = importlib.machinery.ExtensionFileLoader('_%[1]s', dst)
+	spec = importlib.util.spec_from_file_location('_%[1]s', dst, loader=loader)
+	lib = importlib.util.module_from_spec(spec)
+	loader.exec_module(lib)
+	if os.name == 'posix':
+		shutil.rmtree(tmp, ignore_errors=True)
+	_lib = lib
+	generation += 1
+	return generation
+
+def __getattr__(name):
+	return getattr(_lib, name)
+
+reload()
+`
+
+	// GoPkgDevDefs are the additional definitions in the go package in dev mode.
+	// 1 = name of package (outname)
+	GoPkgDevDefs = `
+def reload():
+	"""reload loads the rebuilt Go library into the running session (dev mode only).
+	All of the Go objects obtained before the reload are no longer valid."""
+	return _%[1]s.reload()
+
+`
+
+	// goDevInit resets the handles of each loaded copy of the library in dev mode
+	goDevInit = `
+// dev mode: handles are distinct across reloaded copies of the library
+func init() {
+	gopyh.ResetHandles()
+}
+`
+)
+
+// isDev returns true if generating the dev mode hot-reload loader,
+// which is only possible when the extension is a separate dynamic library
+func (g *pyGen) isDev() bool {
+	return g.cfg.DevMode && g.mode != ModeExe
+}
+
+// pyImportLib returns the python statement importing the _ extension module
+// from the given package, if non-empty -- in dev mode, it is imported
+// by way of its hot-reload loader.
+func (g *pyGen) pyImportLib(from string) string {
+	lib := "_" + g.cfg.Name
+	switch {
+	case g.isDev() && from != "":
+		return fmt.Sprintf("from %s import %s_dev as %s\n", from, lib, lib)
+	case g.isDev():
+		return fmt.Sprintf("import %s_dev as %s\n", lib, lib)
+	case from != "":
+		return fmt.Sprintf("from %s import %s\n", from, lib)
+	default:
+		return fmt.Sprintf("import %s\n", lib)
+	}
+}
+
+func (g *pyGen) genDevLoader() {
+	pr := &printer{buf: new(bytes.Buffer), indentEach: []byte("\t")}
+	pr.Printf(PyDevLoader, g.cfg.Name, g.cfg.Cmd)
+	g.genPrintOut("_"+g.cfg.Name+"_dev.py", pr)
+}
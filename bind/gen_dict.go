@@ -0,0 +1,381 @@
+// Copyright 2019 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bind
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	// goDictPreambleC are the C helpers for the deep conversions of structs
+	// to and from plain python values, for asdict and from_dict
+	goDictPreambleC = `
+static inline char* gopy_type_name(PyObject* obj) { // name of the type of obj, as a new string
+#ifdef Py_LIMITED_API
+	PyObject* n = PyObject_GetAttrString((PyObject*)Py_TYPE(obj), "__name__");
+	const char* cs = (n != NULL) ? PyUnicode_AsUTF8AndSize(n, NULL) : NULL;
+	char* res = strdup(cs != NULL ? cs : "?");
+	Py_XDECREF(n);
+	PyErr_Clear();
+	return res;
+#else
+	return strdup(Py_TYPE(obj)->tp_name);
+#endif
+}
+`
+
+	// goDictDefs are the Go deep conversions of structs to and from plain
+	// python values, for asdict and from_dict
+	goDictDefs = `
+// gopyFieldName returns the python name of the i'th field of the struct
+// type t, see gopyFieldNames, or its Go name for the exported fields of
+// structs that are not bound, or "" if it is not converted
+func gopyFieldName(t reflect.Type, i int) string {
+	if names, has := gopyFieldNames[t]; has {
+		return names[i]
+	}
+	if f := t.Field(i); f.PkgPath == "" {
+		return f.Name
+	}
+	return ""
+}
+
+// gopyPlainError raises a TypeError for the python value o that cannot be
+// converted to the Go type t, and returns false
+func gopyPlainError(o *C.PyObject, t reflect.Type) bool {
+	tn := C.gopy_type_name(o)
+	msg := C.CString(fmt.Sprintf("cannot convert %s to Go %s", C.GoString(tn), t))
+	C.free(unsafe.Pointer(tn))
+	defer C.free(unsafe.Pointer(msg))
+	C.PyErr_SetString(C.PyExc_TypeError, msg)
+	return false
+}
+
+// gopyAsPlain deep-converts a Go value to plain python values, for asdict:
+// structs to dicts of their fields by python name, slices and arrays to
+// lists, except []byte to bytes, maps to dicts, nil pointers, funcs and
+// chans to None, and anything else as by valueGoToPy
+func gopyAsPlain(v reflect.Value) *C.PyObject {
+	switch v.Kind() {
+	case reflect.Invalid:
+		return C.gopy_none()
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return C.gopy_none()
+		}
+		return gopyAsPlain(v.Elem())
+	case reflect.Struct:
+		d := C.PyDict_New()
+		for i := 0; i < v.NumField(); i++ {
+			name := gopyFieldName(v.Type(), i)
+			if name == "" {
+				continue
+			}
+			e := gopyAsPlain(v.Field(i))
+			if e == nil {
+				C.gopy_decref(d)
+				return nil // python error is set
+			}
+			cs := C.CString(name)
+			C.PyDict_SetItemString(d, cs, e)
+			C.free(unsafe.Pointer(cs))
+			C.gopy_decref(e)
+		}
+		return d
+	case reflect.Map:
+		d := C.PyDict_New()
+		it := v.MapRange()
+		for it.Next() {
+			k := gopyAsPlain(it.Key())
+			if k == nil {
+				C.gopy_decref(d)
+				return nil
+			}
+			e := gopyAsPlain(it.Value())
+			if e == nil {
+				C.gopy_decref(k)
+				C.gopy_decref(d)
+				return nil
+			}
+			rc := C.PyDict_SetItem(d, k, e)
+			C.gopy_decref(k)
+			C.gopy_decref(e)
+			if rc != 0 {
+				C.gopy_decref(d)
+				return nil // e.g., unhashable key
+			}
+		}
+		return d
+	case reflect.Func, reflect.Chan, reflect.UnsafePointer:
+		return C.gopy_none()
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8 {
+			return bytesGoToPy(v.Bytes())
+		}
+		l := C.PyList_New(C.Py_ssize_t(v.Len()))
+		for i := 0; i < v.Len(); i++ {
+			e := gopyAsPlain(v.Index(i))
+			if e == nil {
+				C.gopy_decref(l)
+				return nil
+			}
+			C.PyList_SetItem(l, C.Py_ssize_t(i), e) // steals e
+		}
+		return l
+	}
+	if !v.CanInterface() {
+		return C.gopy_none()
+	}
+	return valueGoToPy(v.Interface())
+}
+
+// gopyFromPlain deep-converts a plain python value to the settable Go value
+// v, for from_dict, the reverse of gopyAsPlain: dicts to structs, by the
+// python names of their fields, and to maps, lists and tuples to slices and
+// arrays, None to nil, and GoClass objects to the Go value of their handle.
+// It returns false on errors, which are raised after the call.
+func gopyFromPlain(o *C.PyObject, v reflect.Value) bool {
+	kind := C.gopy_value_kind(o)
+	if kind == 8 {
+		gv := reflect.ValueOf(gopyh.VarFromHandle(gopyh.CGoHandle(C.gopy_handle_of(o)), ""))
+		switch {
+		case gv.IsValid() && gv.Type().AssignableTo(v.Type()):
+			v.Set(gv)
+			return true
+		case gv.Kind() == reflect.Ptr && gv.Type().Elem().AssignableTo(v.Type()):
+			v.Set(gv.Elem())
+			return true
+		}
+		return gopyPlainError(o, v.Type())
+	}
+	if kind == 0 {
+		switch v.Kind() {
+		case reflect.Ptr, reflect.Interface, reflect.Map, reflect.Slice:
+			v.Set(reflect.Zero(v.Type()))
+			return true
+		}
+		return gopyPlainError(o, v.Type())
+	}
+	switch v.Kind() {
+	case reflect.Ptr:
+		p := reflect.New(v.Type().Elem())
+		if !gopyFromPlain(o, p.Elem()) {
+			return false
+		}
+		v.Set(p)
+		return true
+	case reflect.Interface:
+		e := valuePyToGo(o)
+		if C.PyErr_Occurred() != nil {
+			return false
+		}
+		ev := reflect.ValueOf(e)
+		if !ev.Type().AssignableTo(v.Type()) {
+			return gopyPlainError(o, v.Type())
+		}
+		v.Set(ev)
+		return true
+	case reflect.Struct:
+		if kind != 6 {
+			return gopyPlainError(o, v.Type())
+		}
+		var pos C.Py_ssize_t
+		var k, e, items *C.PyObject
+		defer func() { C.Py_DecRef(items) }()
+		for C.gopy_dict_next(o, &items, &pos, &k, &e) != 0 {
+			if C.gopy_value_kind(k) != 4 {
+				return gopyPlainError(k, reflect.TypeOf(""))
+			}
+			name := C.GoString(C.gopy_string(k))
+			fi := -1
+			for i := 0; i < v.NumField(); i++ {
+				if gopyFieldName(v.Type(), i) == name {
+					fi = i
+					break
+				}
+			}
+			if fi < 0 {
+				msg := C.CString(fmt.Sprintf("Go %s has no field %q", v.Type(), name))
+				defer C.free(unsafe.Pointer(msg))
+				C.PyErr_SetString(C.PyExc_TypeError, msg)
+				return false
+			}
+			if !gopyFromPlain(e, v.Field(fi)) {
+				return false
+			}
+		}
+		return true
+	case reflect.Map:
+		if kind != 6 {
+			return gopyPlainError(o, v.Type())
+		}
+		m := reflect.MakeMapWithSize(v.Type(), int(C.PyDict_Size(o)))
+		var pos C.Py_ssize_t
+		var k, e, items *C.PyObject
+		defer func() { C.Py_DecRef(items) }()
+		for C.gopy_dict_next(o, &items, &pos, &k, &e) != 0 {
+			mk := reflect.New(v.Type().Key()).Elem()
+			me := reflect.New(v.Type().Elem()).Elem()
+			if !gopyFromPlain(k, mk) || !gopyFromPlain(e, me) {
+				return false
+			}
+			m.SetMapIndex(mk, me)
+		}
+		v.Set(m)
+		return true
+	case reflect.Slice, reflect.Array:
+		if kind == 5 && v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8 {
+			v.SetBytes(bytesPyToGo(o))
+			return C.PyErr_Occurred() == nil
+		}
+		if kind != 7 {
+			return gopyPlainError(o, v.Type())
+		}
+		n := int(C.PySequence_Size(o))
+		if v.Kind() == reflect.Slice {
+			v.Set(reflect.MakeSlice(v.Type(), n, n))
+		} else if n != v.Len() {
+			msg := C.CString(fmt.Sprintf("cannot convert a sequence of length %d to Go %s", n, v.Type()))
+			defer C.free(unsafe.Pointer(msg))
+			C.PyErr_SetString(C.PyExc_ValueError, msg)
+			return false
+		}
+		for i := 0; i < n; i++ {
+			e := C.PySequence_GetItem(o, C.Py_ssize_t(i))
+			ok := gopyFromPlain(e, v.Index(i))
+			C.gopy_decref(e)
+			if !ok {
+				return false
+			}
+		}
+		return true
+	case reflect.Bool:
+		if kind != 1 {
+			return gopyPlainError(o, v.Type())
+		}
+		v.SetBool(C.PyObject_IsTrue(o) != 0)
+		return true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if kind != 2 {
+			return gopyPlainError(o, v.Type())
+		}
+		i := int64(C.PyLong_AsLongLong(o))
+		if C.PyErr_Occurred() != nil {
+			return false
+		}
+		if v.OverflowInt(i) {
+			gopySetError(C.PyExc_OverflowError, fmt.Sprintf("%d overflows Go %s", i, v.Type()))
+			return false
+		}
+		v.SetInt(i)
+		return true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if kind != 2 {
+			return gopyPlainError(o, v.Type())
+		}
+		u := uint64(C.PyLong_AsUnsignedLongLong(o))
+		if C.PyErr_Occurred() != nil {
+			return false
+		}
+		if v.OverflowUint(u) {
+			gopySetError(C.PyExc_OverflowError, fmt.Sprintf("%d overflows Go %s", u, v.Type()))
+			return false
+		}
+		v.SetUint(u)
+		return true
+	case reflect.Float32, reflect.Float64:
+		if kind != 2 && kind != 3 {
+			return gopyPlainError(o, v.Type())
+		}
+		v.SetFloat(float64(C.PyFloat_AsDouble(o)))
+		return C.PyErr_Occurred() == nil
+	case reflect.String:
+		if kind != 4 {
+			return gopyPlainError(o, v.Type())
+		}
+		v.SetString(C.GoString(C.gopy_string(o)))
+		return true
+	}
+	return gopyPlainError(o, v.Type())
+}
+`
+)
+
+// genStructFieldNamesGo generates the table of the python names of the
+// fields of the structs of all the packages, by their Go type, for the
+// deep conversions of asdict and from_dict.
+func (g *pyGen) genStructFieldNamesGo() {
+	g.gofile.Printf("\n// gopyFieldNames are the python names of the fields of the bound structs,\n")
+	g.gofile.Printf("// by their type -- \"\" for the fields that are not exposed to python.\n")
+	g.gofile.Printf("var gopyFieldNames = map[reflect.Type][]string{\n")
+	g.gofile.Indent()
+	for _, p := range g.pkgs {
+		if p == goPackage {
+			continue
+		}
+		for _, s := range p.structs {
+			typ := s.Struct()
+			names := make([]string, typ.NumFields())
+			for i := range names {
+				names[i] = fmt.Sprintf("%q", g.pyFieldName(s, i, typ.Field(i)))
+			}
+			g.gofile.Printf("reflect.TypeOf(%s{}): {%s},\n", s.GoName(), strings.Join(names, ", "))
+		}
+	}
+	g.gofile.Outdent()
+	g.gofile.Printf("}\n")
+}
+
+// genStructDict generates the asdict method and from_dict classmethod of
+// the python class of the struct, see genStructDictGo.
+func (g *pyGen) genStructDict(s *Struct) {
+	g.pywrap.Printf("def asdict(self):\n")
+	g.pywrap.Indent()
+	g.pywrap.Printf(`"""asdict returns the fields of the struct as a dict, deep-converted to plain python values:
+nested structs to dicts, slices and arrays to lists, maps to dicts and nil pointers to None"""`)
+	g.pywrap.Printf("\n")
+	g.pywrap.Printf("return _%s.%s_asdict(self.handle)\n", g.pypkgname, s.ID())
+	g.pywrap.Outdent()
+	g.pywrap.Printf("@classmethod\n")
+	g.pywrap.Printf("def from_dict(cls, d):\n")
+	g.pywrap.Indent()
+	g.pywrap.Printf(`"""from_dict returns a new struct with the fields given in the dict d, deep-converted
+from plain python values, as returned by asdict -- missing fields are zero"""`)
+	g.pywrap.Printf("\n")
+	g.pywrap.Printf("rv = cls.__new__(cls)\n")
+	g.pywrap.Printf("rv.handle = _%s.%s_from_dict(d)\n", g.pypkgname, s.ID())
+	g.pywrap.Printf("_%s.IncRef(rv.handle)\n", g.pypkgname)
+	g.pywrap.Printf("return rv\n")
+	g.pywrap.Outdent()
+}
+
+// genStructDictGo generates the Go side of the asdict and from_dict of the
+// python class of the struct, see genStructDict.
+func (g *pyGen) genStructDictGo(s *Struct) {
+	g.gofile.Printf("//export %s_asdict\n", s.ID())
+	g.gofile.Printf("func %s_asdict(handle CGoHandle) *C.PyObject {\n", s.ID())
+	g.gofile.Indent()
+	g.gofile.Printf("return gopyAsPlain(reflect.ValueOf(ptrFromHandle_%s(handle)).Elem())\n", s.ID())
+	g.gofile.Outdent()
+	g.gofile.Printf("}\n\n")
+
+	g.declFunc("add_checked_function", s.ID()+"_asdict", "PyObject*", capiParam{PyHandle, "handle"})
+
+	g.gofile.Printf("//export %s_from_dict\n", s.ID())
+	g.gofile.Printf("func %s_from_dict(o *C.PyObject) CGoHandle {\n", s.ID())
+	g.gofile.Indent()
+	g.gofile.Printf("p := new(%s)\n", s.GoName())
+	g.gofile.Printf("if !gopyFromPlain(o, reflect.ValueOf(p).Elem()) {\n")
+	g.gofile.Indent()
+	g.gofile.Printf("return -1 // python error is set\n")
+	g.gofile.Outdent()
+	g.gofile.Printf("}\n")
+	g.gofile.Printf("return CGoHandle(handleFromPtr_%s(p))\n", s.ID())
+	g.gofile.Outdent()
+	g.gofile.Printf("}\n\n")
+
+	g.declFunc("add_checked_function", s.ID()+"_from_dict", PyHandle, capiParam{"PyObject*", "o"})
+}
@@ -0,0 +1,98 @@
+// Copyright 2019 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bind
+
+// errorVars returns the exported sentinel error vars of the package,
+// i.e., the vars of type error, which are raised as their own exception.
+func (p *Package) errorVars() []*Var {
+	var evs []*Var
+	for _, v := range p.vars {
+		if isErrorType(v.sym.gotyp) {
+			evs = append(evs, v)
+		}
+	}
+	return evs
+}
+
+// errorStructs returns the exported structs of the package that implement
+// error, whose python classes are also exceptions.
+func (p *Package) errorStructs() []*Struct {
+	var ess []*Struct
+	for _, s := range p.structs {
+		if s.prots&ProtoError != 0 {
+			ess = append(ess, s)
+		}
+	}
+	return ess
+}
+
+// genErrors generates the python exception classes of the sentinel error
+// vars of the package, and registers them and those of the error structs
+// with the Go side, so that the Go errors are raised as them.
+func (g *pyGen) genErrors() {
+	gopkg := g.pkg.Name()
+	for _, v := range g.pkg.errorVars() {
+		g.pywrap.Printf("class %s(go.GoError):\n", v.Name())
+		g.pywrap.Indent()
+		g.pywrap.Printf("%s\n%s is raised for the Go error %s.%s\n%s\n%s\n", `"""`, v.Name(), gopkg, v.Name(), v.doc, `"""`)
+		g.pywrap.Printf("pass\n")
+		g.pywrap.Outdent()
+		g.pywrap.Printf("\n")
+		g.pywrap.Printf("_%s.GoPyRegisterError(%q, %s)\n\n", g.pypkgname, gopkg+"."+v.Name(), v.Name())
+	}
+	for _, s := range g.pkg.errorStructs() {
+		g.pywrap.Printf("_%s.GoPyRegisterError(%q, %s)\n", g.pypkgname, s.GoName(), s.obj.Name())
+	}
+}
+
+// genErrorClassGo generates the Go function classifying the errors returned
+// to python, by their sentinel var or type, for all the packages.
+func (g *pyGen) genErrorClassGo() {
+	var vars []string
+	var structs []*Struct
+	for _, p := range g.pkgs {
+		if p == goPackage {
+			continue
+		}
+		for _, v := range p.errorVars() {
+			vars = append(vars, p.Name()+"."+v.Name())
+		}
+		structs = append(structs, p.errorStructs()...)
+	}
+
+	g.gofile.Printf("\n// gopyErrClass returns the qualified Go name of the sentinel var or type\n")
+	g.gofile.Printf("// that err is raised as, and the handle of err if it is a struct.\n")
+	g.gofile.Printf("func gopyErrClass(err error) (string, CGoHandle) {\n")
+	g.gofile.Indent()
+	if len(vars) > 0 {
+		g.gofile.Printf("switch err {\n")
+		for _, nm := range vars {
+			g.gofile.Printf("case %s:\n", nm)
+			g.gofile.Indent()
+			g.gofile.Printf("return %q, -1\n", nm)
+			g.gofile.Outdent()
+		}
+		g.gofile.Printf("}\n")
+	}
+	if len(structs) > 0 {
+		g.gofile.Printf("switch e := err.(type) {\n")
+		for _, s := range structs {
+			g.gofile.Printf("case *%s:\n", s.GoName())
+			g.gofile.Indent()
+			g.gofile.Printf("return %q, %s(e)\n", s.GoName(), s.sym.go2py)
+			g.gofile.Outdent()
+			if implementsError(s.GoType()) { // value receiver
+				g.gofile.Printf("case %s:\n", s.GoName())
+				g.gofile.Indent()
+				g.gofile.Printf("return %q, %s(&e)\n", s.GoName(), s.sym.go2py)
+				g.gofile.Outdent()
+			}
+		}
+		g.gofile.Printf("}\n")
+	}
+	g.gofile.Printf("return \"\", -1\n")
+	g.gofile.Outdent()
+	g.gofile.Printf("}\n")
+}
@@ -0,0 +1,60 @@
+// Copyright 2019 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bind
+
+const (
+	// goFinalizeDefs are the exported functions for the deterministic
+	// finalization of the wrappers, when generating with -finalize
+	goFinalizeDefs = `
+// GoPyRelease decrements the reference count of the handle, closing its
+// value if it is an io.Closer that is no longer referenced
+//export GoPyRelease
+func GoPyRelease(handle CGoHandle) {
+	gopyh.Release(gopyh.CGoHandle(handle))
+}
+
+// GoPyCollect runs the Go garbage collector, returning the number of
+// handles still in use
+//export GoPyCollect
+func GoPyCollect() int {
+	return gopyh.Collect()
+}
+`
+
+	// GoPkgFinalizeDefs are the additional definitions in the go package with -finalize.
+	// 1 = name of package (outname)
+	GoPkgFinalizeDefs = `
+import gc
+
+def collect():
+	"""collect runs the python garbage collector, finalizing the unreachable
+	wrappers of Go values, e.g., in reference cycles, which releases their
+	handles, and then the Go one, and returns the number of handles still in use."""
+	gc.collect()
+	return _%[1]s.GoPyCollect()
+`
+)
+
+// declFinalizeFuncs declares the functions of -finalize in build.py.
+func (g *pyGen) declFinalizeFuncs() {
+	g.pybuild.Printf("\n")
+	g.declFunc("mod.add_function", "GoPyRelease", "", capiParam{"int64_t", "handle"})
+	g.declFunc("mod.add_function", "GoPyCollect", "int")
+}
+
+// genDel generates the __del__ of a python class wrapping a Go value by
+// handle, which releases the handle.  With -finalize, a value that is an
+// io.Closer is also closed once its last handle is released, see
+// gopyh.Release.
+func (g *pyGen) genDel() {
+	g.pywrap.Printf("def __del__(self):\n")
+	g.pywrap.Indent()
+	if g.cfg.Finalize {
+		g.pywrap.Printf("_%s.GoPyRelease(self.handle)\n", g.pypkgname)
+	} else {
+		g.pywrap.Printf("_%s.DecRef(self.handle)\n", g.pypkgname)
+	}
+	g.pywrap.Outdent()
+}
@@ -0,0 +1,41 @@
+// Copyright 2019 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bind
+
+// genStructFormat generates the __format__ method of the python class of
+// the struct, which formats the Go value with fmt.Sprintf for a format spec
+// that is a Go fmt verb, e.g., f"{obj:%+v}", and otherwise formats its str.
+func (g *pyGen) genStructFormat(s *Struct) {
+	g.pywrap.Printf("def __format__(self, spec):\n")
+	g.pywrap.Indent()
+	g.pywrap.Printf(`"""__format__ formats the Go value by fmt.Sprintf in Go for a spec starting with %%, e.g., f"{obj:%%+v}",
+and otherwise formats its str with the spec"""`)
+	g.pywrap.Printf("\n")
+	g.pywrap.Printf("if spec.startswith('%%'):\n")
+	g.pywrap.Indent()
+	g.pywrap.Printf("return _%s.%s_format(self.handle, spec)\n", g.pypkgname, s.ID())
+	g.pywrap.Outdent()
+	g.pywrap.Printf("return format(str(self), spec)\n")
+	g.pywrap.Outdent()
+}
+
+// genStructFormatGo generates the Go side of the __format__ method of the
+// python class of the struct, see genStructFormat.
+func (g *pyGen) genStructFormatGo(s *Struct) {
+	g.gofile.Printf("//export %s_format\n", s.ID())
+	g.gofile.Printf("func %s_format(handle CGoHandle, spec *C.char) *C.char {\n", s.ID())
+	g.gofile.Indent()
+	g.gofile.Printf("op := ptrFromHandle_%s(handle)\n", s.ID())
+	g.gofile.Printf("if op == nil {\n")
+	g.gofile.Indent()
+	g.gofile.Printf("return C.CString(fmt.Sprintf(C.GoString(spec), nil))\n")
+	g.gofile.Outdent()
+	g.gofile.Printf("}\n")
+	g.gofile.Printf("return C.CString(fmt.Sprintf(C.GoString(spec), *op))\n")
+	g.gofile.Outdent()
+	g.gofile.Printf("}\n\n")
+
+	g.declFunc("add_checked_string_function", s.ID()+"_format", "char*", capiParam{PyHandle, "handle"}, capiParam{"char*", "spec"})
+}
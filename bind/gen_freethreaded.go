@@ -0,0 +1,79 @@
+// Copyright 2019 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bind
+
+// goFreeThreadedPreambleC are the C helpers for the free-threaded python
+// build, whose dicts can be mutated by other threads while they are
+// iterated, which PyDict_Next does not support without a critical section.
+const goFreeThreadedPreambleC = `
+static inline int gopy_dict_next(PyObject* d, PyObject** items, Py_ssize_t* pos, PyObject** k, PyObject** v) { // PyDict_Next, on a snapshot of the items in the free-threaded build, which the caller must Py_DecRef
+#ifdef Py_GIL_DISABLED
+	if (*items == NULL) {
+		*items = PyDict_Items(d);
+		if (*items == NULL) {
+			return 0;
+		}
+	}
+	if (*pos >= PyList_GET_SIZE(*items)) {
+		return 0;
+	}
+	PyObject* kv = PyList_GET_ITEM(*items, *pos);
+	*k = PyTuple_GET_ITEM(kv, 0);
+	*v = PyTuple_GET_ITEM(kv, 1);
+	(*pos)++;
+	return 1;
+#else
+	return PyDict_Next(d, pos, k, v);
+#endif
+}
+`
+
+// goFreeThreadedDefs are the definitions for the locks of the Go values and
+// calls in the free-threaded python build, with -free-threaded, where the
+// GIL does not serialize the accesses from python threads.
+const goFreeThreadedDefs = `
+// gopyLock locks mu, detached from the python thread state, if any, while
+// waiting for it, as blocking while attached would deadlock the
+// stop-the-world pauses of the free-threaded build, and returns the func
+// that unlocks it
+func gopyLock(mu *sync.Mutex) func() {
+	if !mu.TryLock() {
+		if C.gopy_tstate() == nil {
+			mu.Lock()
+		} else {
+			_save := C.PyEval_SaveThread()
+			mu.Lock()
+			C.PyEval_RestoreThread(_save)
+		}
+	}
+	return mu.Unlock
+}
+
+// gopySerialMu serializes the calls of the functions and methods with a
+// gopy:notthreadsafe directive
+var gopySerialMu sync.Mutex
+
+// gopySerial locks gopySerialMu, and returns the func that unlocks it
+func gopySerial() func() {
+	return gopyLock(&gopySerialMu)
+}
+`
+
+// genValueLock generates the lock of the Go value that ptr points to, until
+// the accessor returns, with -free-threaded -- see gopyh.PtrMutex.
+func (g *pyGen) genValueLock(ptr string) {
+	if !g.cfg.FreeThreaded {
+		return
+	}
+	g.gofile.Printf("defer gopyLock(gopyh.PtrMutex(%s))()\n", ptr)
+}
+
+// serialized returns true if the calls of the function are serialized,
+// with -free-threaded, for a gopy:notthreadsafe directive in its doc.  The
+// function otherwise holds the GIL during its calls, see releasesGIL.
+func (g *pyGen) serialized(fsym *Func) bool {
+	unsafe, _ := isNotThreadSafeDoc(fsym.Doc())
+	return unsafe && g.cfg.FreeThreaded
+}
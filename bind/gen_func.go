@@ -42,12 +42,7 @@ func (g *pyGen) genFuncSig(sym *symbol, fsym *Func) bool {
 		return false
 	}
 
-	gname := fsym.GoName()
-	if g.cfg.RenameCase {
-		gname = toSnakeCase(gname)
-	}
-
-	gname, gdoc, err := extractPythonName(gname, fsym.Doc())
+	gname, gdoc, err := g.pyFuncName(fsym)
 	if err != nil {
 		return false
 	}
@@ -66,41 +61,54 @@ func (g *pyGen) genFuncSig(sym *symbol, fsym *Func) bool {
 	if nres == 2 && !fsym.err {
 		return false
 	}
+	if nres > 0 && !g.isFuncValueType(res[0].sym) {
+		return false
+	}
 
 	var (
 		goArgs []string
-		pyArgs []string
+		pyArgs []capiParam
 		wpArgs []string
 	)
 
 	if isMethod {
 		goArgs = append(goArgs, "_handle CGoHandle")
-		pyArgs = append(pyArgs, fmt.Sprintf("param('%s', '_handle')", PyHandle))
+		pyArgs = append(pyArgs, capiParam{PyHandle, "_handle"})
 		wpArgs = append(wpArgs, "self")
 	}
 
 	nargs = len(args)
 	for i := 0; i < nargs; i++ {
 		arg := args[i]
-		sarg := current.symtype(arg.GoType())
+		sarg := g.syms.symtype(arg.GoType())
 		if sarg == nil {
 			return false
 		}
 		anm := pySafeArg(arg.Name(), i)
 
-		if ifchandle && arg.sym.goname == "interface{}" {
+		if i == 0 && fsym.hasctx {
+			// the id of the injected context, see genFuncBody
+			goArgs = append(goArgs, fmt.Sprintf("%s C.longlong", anm))
+			pyArgs = append(pyArgs, capiParam{"int64_t", anm})
+			continue
+		}
+
+		switch {
+		case ifchandle && arg.sym.goname == "interface{}":
 			goArgs = append(goArgs, fmt.Sprintf("%s %s", anm, CGoHandle))
-			pyArgs = append(pyArgs, fmt.Sprintf("param('%s', '%s')", PyHandle, anm))
-		} else {
+			pyArgs = append(pyArgs, capiParam{PyHandle, anm})
+		case arg.sym.goname == "interface{}":
+			goArgs = append(goArgs, fmt.Sprintf("%s *C.PyObject", anm))
+			pyArgs = append(pyArgs, capiParam{"PyObject*", anm})
+		default:
 			goArgs = append(goArgs, fmt.Sprintf("%s %s", anm, sarg.cgoname))
-			if sarg.cpyname == "PyObject*" {
-				pyArgs = append(pyArgs, fmt.Sprintf("param('%s', '%s', transfer_ownership=False)", sarg.cpyname, anm))
-			} else {
-				pyArgs = append(pyArgs, fmt.Sprintf("param('%s', '%s')", sarg.cpyname, anm))
-			}
+			pyArgs = append(pyArgs, capiParam{sarg.cpyname, anm})
 		}
 
-		if i!=nargs-1 || !fsym.isVariadic {
+		if i != nargs-1 || !fsym.isVariadic {
+			if g.pyHints() {
+				anm += ": " + g.argTypeHint(arg.sym, ifchandle)
+			}
 			wpArgs = append(wpArgs, anm)
 		}
 	}
@@ -108,13 +116,36 @@ func (g *pyGen) genFuncSig(sym *symbol, fsym *Func) bool {
 	// support for optional arg to run in a separate go routine -- only if no return val
 	if nres == 0 {
 		goArgs = append(goArgs, "goRun C.char")
-		pyArgs = append(pyArgs, "param('bool', 'goRun')")
-		wpArgs = append(wpArgs, "goRun=False")
+		pyArgs = append(pyArgs, capiParam{"bool", "goRun"})
+		if g.pyHints() {
+			wpArgs = append(wpArgs, "goRun: bool = False")
+		} else {
+			wpArgs = append(wpArgs, "goRun=False")
+		}
 	}
 
 	// To support variadic args, we add *args at the end.
 	if fsym.isVariadic {
-		wpArgs = append(wpArgs, "*args")
+		if g.pyHints() {
+			wpArgs = append(wpArgs, "*args: "+g.variadicTypeHint(args[nargs-1]))
+		} else {
+			wpArgs = append(wpArgs, "*args")
+		}
+	}
+
+	if g.hasTimeout(fsym) {
+		if g.pyHints() {
+			wpArgs = append(wpArgs, "timeout: Optional[float] = None")
+		} else {
+			wpArgs = append(wpArgs, "timeout=None")
+		}
+	}
+	if g.hasCtxKw(fsym) {
+		if g.pyHints() {
+			wpArgs = append(wpArgs, "ctx: Optional[go.Context] = None")
+		} else {
+			wpArgs = append(wpArgs, "ctx=None")
+		}
 	}
 
 	// When building the pybindgen builder code, we start with
@@ -134,6 +165,12 @@ func (g *pyGen) genFuncSig(sym *symbol, fsym *Func) bool {
 		}
 	}
 
+	asyncKw := ""
+	if g.isAsync(fsym) {
+		asyncKw = "async "
+	}
+
+	pyName := fsym.ID()
 	switch {
 	case isMethod:
 		mnm := sym.id + "_" + fsym.GoName()
@@ -141,23 +178,22 @@ func (g *pyGen) genFuncSig(sym *symbol, fsym *Func) bool {
 		g.gofile.Printf("\n//export %s\n", mnm)
 		g.gofile.Printf("func %s(", mnm)
 
-		g.pybuild.Printf("%s(mod, '%s', ", addFuncName, mnm)
+		pyName = mnm
 
-		g.pywrap.Printf("def %s(", gname)
+		g.pywrap.Printf("%sdef %s(", asyncKw, gname)
 	default:
 		g.gofile.Printf("\n//export %s\n", fsym.ID())
 		g.gofile.Printf("func %s(", fsym.ID())
 
-		g.pybuild.Printf("%s(mod, '%s', ", addFuncName, fsym.ID())
-
-		g.pywrap.Printf("def %s(", gname)
+		g.pywrap.Printf("%sdef %s(", asyncKw, gname)
 	}
 
 	goRet := ""
+	pyRet := ""
 	nres = len(res)
 	if nres > 0 {
 		ret := res[0]
-		sret := current.symtype(ret.GoType())
+		sret := ret.sym
 		if sret == nil {
 			panic(fmt.Errorf(
 				"gopy: could not find symbol for %q",
@@ -165,33 +201,32 @@ func (g *pyGen) genFuncSig(sym *symbol, fsym *Func) bool {
 			))
 		}
 
-		if sret.cpyname == "PyObject*" {
-			g.pybuild.Printf("retval('%s', caller_owns_return=True)", sret.cpyname)
-		} else {
-			g.pybuild.Printf("retval('%s')", sret.cpyname)
+		switch {
+		case sret.isSignature(): // returned as a handle to a callable class
+			pyRet = PyHandle
+			goRet = "CGoHandle"
+		default:
+			pyRet = sret.cpyname
+			goRet = sret.cgoname
 		}
-		goRet = fmt.Sprintf("%s", sret.cgoname)
-	} else {
-		g.pybuild.Printf("None")
 	}
+	g.declFunc(addFuncName, pyName, pyRet, pyArgs...)
 
 	if len(goArgs) > 0 {
 		gstr := strings.Join(goArgs, ", ")
 		g.gofile.Printf("%v) %v", gstr, goRet)
 
-		pstr := strings.Join(pyArgs, ", ")
-		g.pybuild.Printf(", [%v])\n", pstr)
-
 		wstr := strings.Join(wpArgs, ", ")
 		g.pywrap.Printf("%v)", wstr)
 
 	} else {
 		g.gofile.Printf(") %v", goRet)
 
-		g.pybuild.Printf(", [])\n")
-
 		g.pywrap.Printf(")")
 	}
+	if g.pyHints() {
+		g.pywrap.Printf(" -> %s", g.retTypeHint(fsym))
+	}
 	return true
 }
 
@@ -207,6 +242,248 @@ func (g *pyGen) genMethod(s *symbol, o *Func) {
 	}
 }
 
+// pyFuncName returns the python name of the function or method, renamed to
+// snake_case with -rename or given by a gopy:name directive in its doc, along
+// with the doc stripped of the directive.
+func (g *pyGen) pyFuncName(fsym *Func) (string, string, error) {
+	gname := fsym.GoName()
+	if g.cfg.RenameCase {
+		gname = toSnakeCase(gname)
+	}
+	return extractPythonName(gname, fsym.Doc())
+}
+
+// isFuncValueType returns false if sym is a func type that has no
+// callable python class, i.e., one external to the targeted packages,
+// so that values of it cannot be returned to python.
+func (g *pyGen) isFuncValueType(sym *symbol) bool {
+	if sym == nil || !sym.isSignature() {
+		return true
+	}
+	_, has := g.pkgmap[sym.gopkg.Path()]
+	return has
+}
+
+// isAsync returns true if an asyncio-native python wrapper should be
+// generated for the function, per the -async name regexp or a gopy:async
+// directive in its doc.
+func (g *pyGen) isAsync(fsym *Func) bool {
+	if async, _ := isAsyncDoc(fsym.Doc()); async {
+		return true
+	}
+	return g.asyncRe != nil && g.asyncRe.MatchString(fsym.GoName())
+}
+
+// isAsyncDoc returns true if the function doc contains the gopy:async
+// directive, along with the doc stripped of it.
+func isAsyncDoc(gdoc string) (bool, string) {
+	return docDirective(gdoc, "gopy:async")
+}
+
+// isTimeoutDoc returns true if the function doc contains the gopy:timeout
+// directive, along with the doc stripped of it.
+func isTimeoutDoc(gdoc string) (bool, string) {
+	return docDirective(gdoc, "gopy:timeout")
+}
+
+// isNotThreadSafeDoc returns true if the function doc contains the
+// gopy:notthreadsafe directive, along with the doc stripped of it.
+func isNotThreadSafeDoc(gdoc string) (bool, string) {
+	return docDirective(gdoc, "gopy:notthreadsafe")
+}
+
+// docDirective returns true if the doc contains the directive, along with
+// the doc stripped of it.
+func docDirective(gdoc, directive string) (bool, string) {
+	idx := strings.Index(gdoc, directive)
+	if idx < 0 {
+		return false, gdoc
+	}
+	rest := gdoc[idx+len(directive):]
+	rest = strings.TrimPrefix(rest, "\n")
+	return true, gdoc[:idx] + rest
+}
+
+// hasTimeout returns true if the python wrapper of the function takes a
+// timeout keyword, in seconds, for the functions with an injected context,
+// which is cancelled on timeout, and those with a gopy:timeout directive in
+// their doc, unless one of their args is already named timeout.
+func (g *pyGen) hasTimeout(fsym *Func) bool {
+	if g.lang == 2 {
+		return false
+	}
+	if timeout, _ := isTimeoutDoc(fsym.Doc()); !timeout && !fsym.hasctx {
+		return false
+	}
+	args := fsym.sig.Params()
+	for i, arg := range args {
+		if pySafeArg(arg.Name(), i) == "timeout" {
+			return false
+		}
+	}
+	return true
+}
+
+// hasCtxKw returns true if the python wrapper of the function takes a ctx
+// keyword, a go.Context that the context injected in its Go call is derived
+// from, e.g., by go.with_timeout, unless one of its args is already named ctx.
+func (g *pyGen) hasCtxKw(fsym *Func) bool {
+	if g.lang == 2 || !fsym.hasctx {
+		return false
+	}
+	args := fsym.sig.Params()
+	for i := 1; i < len(args); i++ {
+		if pySafeArg(args[i].Name(), i) == "ctx" {
+			return false
+		}
+	}
+	return true
+}
+
+// hasAsync returns true if asyncio-native python wrappers are generated
+// for any of the functions or methods of the package, see isAsync.
+func (g *pyGen) hasAsync() bool {
+	if g.asyncRe != nil {
+		return true
+	}
+	var fsyms []*Func
+	fsyms = append(fsyms, g.pkg.funcs...)
+	for _, s := range g.pkg.structs {
+		fsyms = append(fsyms, s.ctors...)
+		fsyms = append(fsyms, s.meths...)
+	}
+	for _, ifc := range g.pkg.ifaces {
+		fsyms = append(fsyms, ifc.meths...)
+	}
+	for _, sl := range g.pkg.slices {
+		fsyms = append(fsyms, sl.meths...)
+	}
+	for _, m := range g.pkg.maps {
+		fsyms = append(fsyms, m.meths...)
+	}
+	for _, fsym := range fsyms {
+		if async, _ := isAsyncDoc(fsym.Doc()); async {
+			return true
+		}
+	}
+	return false
+}
+
+// releasesGIL returns true if the python GIL is released during the Go call
+// of the function, so that other python threads can run while it is being
+// executed, unless it is opted out by the -hold-gil name regexp, or by a
+// gopy:notthreadsafe directive, whose calls are serialized by the GIL, or
+// by a lock with -free-threaded, see serialized.
+func (g *pyGen) releasesGIL(fsym *Func) bool {
+	if unsafe, _ := isNotThreadSafeDoc(fsym.Doc()); unsafe && !g.cfg.FreeThreaded {
+		return false
+	}
+	return g.holdGILRe == nil || !g.holdGILRe.MatchString(fsym.GoName())
+}
+
+// genGoCall generates the Go call of a function, assigned per pfx, with
+// the GIL released during the call if nogil, see releasesGIL.  No python
+// API may be used while it is released, so the args are converted first.
+// The saved thread state is reset once restored, for genRecover.  The locks
+// are held during the call, and are only taken once the GIL is released,
+// and released before it is restored, as a python thread waiting for them
+// while holding the GIL would otherwise deadlock -- see gopyUnlock.
+func (g *pyGen) genGoCall(pfx, funCall string, nogil bool, locks []string) {
+	if !nogil {
+		g.gofile.Printf("%s%s\n", pfx, funCall)
+		return
+	}
+	g.gofile.Printf("_save = C.PyEval_SaveThread()\n")
+	if len(locks) > 0 {
+		g.gofile.Printf("_unlock := gopyUnlock(%s)\n", strings.Join(locks, ", "))
+		g.gofile.Printf("defer _unlock()\n")
+	}
+	g.gofile.Printf("%s%s\n", pfx, funCall)
+	if len(locks) > 0 {
+		g.gofile.Printf("_unlock()\n")
+	}
+	g.gofile.Printf("C.PyEval_RestoreThread(_save)\n")
+	g.gofile.Printf("_save = nil\n")
+}
+
+// genInterruptibleCall generates the Go call of a function with an injected
+// context, assigned per pfx, run by gopyCall with the GIL released, so that
+// KeyboardInterrupt is raised promptly on Ctrl-C, cancelling the context.
+// The cret result declared by pfx, whose type may not be nameable, is
+// returned converted by the _cret func, along with the error, declared
+// before if errDecl.  The other calls, which could not be stopped, are run
+// inline by genGoCall, as are the synchronized and serialized ones, which
+// hold their locks until they return.
+func (g *pyGen) genInterruptibleCall(fsym *Func, res []*Var, pfx, funCall string, errDecl bool) {
+	cancel := "_release"
+	if !strings.HasPrefix(pfx, "cret") {
+		g.gofile.Printf("if !gopyCall(func() { %s%s }, %s) {\n", pfx, funCall, cancel)
+		g.gofile.Indent()
+		g.genZeroReturn(res)
+		g.gofile.Outdent()
+		g.gofile.Printf("}\n")
+		return
+	}
+	ret := res[0]
+	goRet := ret.sym.cgoname
+	if ret.sym.isSignature() {
+		goRet = "CGoHandle"
+	}
+	g.gofile.Printf("var _cret func() %s\n", goRet)
+	if errDecl {
+		g.gofile.Printf("var __err error\n")
+	}
+	g.gofile.Printf("if !gopyCall(func() {\n")
+	g.gofile.Indent()
+	if len(res) == 2 {
+		g.gofile.Printf("cret, _err := %s\n", funCall)
+		g.gofile.Printf("__err = _err\n")
+	} else {
+		g.gofile.Printf("cret := %s\n", funCall)
+	}
+	g.gofile.Printf("_cret = func() %s { return %s }\n", goRet, g.retExpr(ret))
+	g.gofile.Outdent()
+	g.gofile.Printf("}, %s) {\n", cancel)
+	g.gofile.Indent()
+	g.genZeroReturn(res)
+	g.gofile.Outdent()
+	g.gofile.Printf("}\n")
+}
+
+// retExpr returns the conversion of the cret result of a Go call to the
+// value returned to python.
+func (g *pyGen) retExpr(ret *Var) string {
+	switch {
+	case ret.sym.go2py == "":
+		return "cret"
+	case ret.sym.hasHandle() && !ret.sym.isPtrOrIface():
+		return fmt.Sprintf("%s(&cret)%s", ret.sym.go2py, ret.sym.go2pyParenEx)
+	}
+	return fmt.Sprintf("%s(cret)%s", ret.sym.go2py, ret.sym.go2pyParenEx)
+}
+
+// genCtxRelease generates the deferred release of the context injected in
+// the Go call of the function, if any, once the call returns.
+func (g *pyGen) genCtxRelease(fsym *Func) {
+	if fsym.hasctx {
+		g.gofile.Printf("defer _release()\n")
+	}
+}
+
+// genCtxTimeout generates the TimeoutError raised when the context injected
+// in the Go call of the function, if any, timed out during the call.
+func (g *pyGen) genCtxTimeout(fsym *Func, res []*Var) {
+	if !fsym.hasctx {
+		return
+	}
+	g.gofile.Printf("if gopyh.TimedOut(_go_%s) {\n", pySafeArg(fsym.sig.Params()[0].Name(), 0))
+	g.gofile.Indent()
+	g.gofile.Printf("gopySetError(C.PyExc_TimeoutError, \"call timed out\")\n")
+	g.genZeroReturn(res)
+	g.gofile.Outdent()
+	g.gofile.Printf("}\n")
+}
+
 func isIfaceHandle(gdoc string) (bool, string) {
 	const PythonIface = "gopy:interface=handle"
 	if idx := strings.Index(gdoc, PythonIface); idx >= 0 {
@@ -216,14 +493,30 @@ func isIfaceHandle(gdoc string) (bool, string) {
 	return false, gdoc
 }
 
+// isSynchronized returns true if the type doc contains the
+// gopy:synchronized directive, along with the doc stripped of it.
+// Methods on such types are serialized through a per-handle mutex.
+func isSynchronized(gdoc string) (bool, string) {
+	const PythonSync = "gopy:synchronized"
+	idx := strings.Index(gdoc, PythonSync)
+	if idx < 0 {
+		return false, gdoc
+	}
+	rest := gdoc[idx+len(PythonSync):]
+	rest = strings.TrimPrefix(rest, "\n")
+	return true, gdoc[:idx] + rest
+}
+
 func (g *pyGen) genFuncBody(sym *symbol, fsym *Func) {
 	isMethod := (sym != nil)
 	isIface := false
+	synced := false
 	symNm := ""
 	if isMethod {
 		symNm = sym.goname
 		isIface = sym.isInterface()
-		if !isIface {
+		synced, _ = isSynchronized(sym.doc)
+		if !isIface && !sym.isSignature() {
 			symNm = "*" + symNm
 		}
 	}
@@ -232,6 +525,9 @@ func (g *pyGen) genFuncBody(sym *symbol, fsym *Func) {
 
 	_, gdoc, _ := extractPythonName(fsym.GoName(), fsym.Doc())
 	ifchandle, gdoc := isIfaceHandle(gdoc)
+	_, gdoc = isAsyncDoc(gdoc)
+	_, gdoc = isTimeoutDoc(gdoc)
+	gdoc = strings.Replace(gdoc, "gopy:notthreadsafe", "Not thread-safe: concurrent calls are serialized.", 1)
 
 	sig := fsym.Signature()
 	res := sig.Results()
@@ -253,12 +549,8 @@ func (g *pyGen) genFuncBody(sym *symbol, fsym *Func) {
 
 	g.gofile.Printf(" {\n")
 	g.gofile.Indent()
-	if fsym.hasfun {
-		for i, arg := range args {
-			if arg.sym.isSignature() {
-				g.gofile.Printf("_fun_arg := %s\n", pySafeArg(arg.Name(), i))
-			}
-		}
+	if g.cfg.Metrics {
+		g.gofile.Printf("defer gopym.Track(%q)()\n", metricsName(sym, fsym))
 	}
 	if isMethod {
 		g.gofile.Printf(
@@ -266,25 +558,17 @@ func (g *pyGen) genFuncBody(sym *symbol, fsym *Func) {
 if __err != nil {
 `, symNm)
 		g.gofile.Indent()
-		if nres > 0 {
-			ret := res[0]
-			if ret.sym.zval == "" {
-				fmt.Printf("gopy: programmer error: empty zval zero value in symbol: %v\n", ret.sym)
-			}
-			if ret.sym.go2py != "" {
-				g.gofile.Printf("return %s(%s)%s\n", ret.sym.go2py, ret.sym.zval, ret.sym.go2pyParenEx)
-			} else {
-				g.gofile.Printf("return %s\n", ret.sym.zval)
-			}
-		} else {
-			g.gofile.Printf("return\n")
-		}
+		g.genZeroReturn(res)
 		g.gofile.Outdent()
 		g.gofile.Printf("}\n")
 	} else if rvIsErr {
 		g.gofile.Printf("var __err error\n")
 	}
 
+	nogil := g.releasesGIL(fsym)
+	// only the unlocked calls with a context are run by gopyCall, see genInterruptibleCall
+	inline := !fsym.hasctx || synced || g.serialized(fsym)
+	g.genRecover(nogil && inline)
 	callArgs := []string{}
 	wrapArgs := []string{}
 	if isMethod {
@@ -293,17 +577,48 @@ if __err != nil {
 	for i, arg := range args {
 		na := ""
 		anm := pySafeArg(arg.Name(), i)
+		if i == 0 && fsym.hasctx {
+			// the context is released once the call returns, see genCtxRelease
+			g.gofile.Printf("_go_%s, _release := gopyh.Context(int64(%s))\n", anm, anm)
+			callArgs = append(callArgs, "_go_"+anm)
+			if g.lang == 2 {
+				wrapArgs = append(wrapArgs, "0")
+			} else {
+				wrapArgs = append(wrapArgs, "_ctx")
+			}
+			continue
+		}
 		switch {
 		case ifchandle && arg.sym.goname == "interface{}":
 			na = fmt.Sprintf(`gopyh.VarFromHandle((gopyh.CGoHandle)(%s), "interface{}")`, anm)
-		case arg.sym.isSignature():
-			na = fmt.Sprintf("%s", arg.sym.py2go)
+		case arg.sym.goname == "interface{}":
+			// deep-converted before the call, to return if it fails
+			na = "__" + anm
+			g.gofile.Printf("%s := valuePyToGo(%s)\n", na, anm)
+			g.gofile.Printf("if C.PyErr_Occurred() != nil {\n")
+			g.gofile.Indent()
+			g.genZeroReturn(res)
+			g.gofile.Outdent()
+			g.gofile.Printf("}\n")
+		case arg.sym.py2go != "" && arg.sym.cgoname == "*C.PyObject":
+			// converted by value before the call, to return if it fails
+			na = "_go_" + anm
+			g.gofile.Printf("%s := %s(%s)%s\n", na, arg.sym.py2go, anm, arg.sym.py2goParenEx)
+			g.gofile.Printf("if C.PyErr_Occurred() != nil {\n")
+			g.gofile.Indent()
+			g.genZeroReturn(res)
+			g.gofile.Outdent()
+			g.gofile.Printf("}\n")
 		case arg.sym.py2go != "":
 			na = fmt.Sprintf("%s(%s)%s", arg.sym.py2go, anm, arg.sym.py2goParenEx)
 		default:
 			na = anm
 		}
-		if i == len(args) - 1 && fsym.isVariadic {
+		if nogil && na != anm && na != "__"+anm && na != "_go_"+anm {
+			g.gofile.Printf("_go_%s := %s\n", anm, na)
+			na = "_go_" + anm
+		}
+		if i == len(args)-1 && fsym.isVariadic {
 			na = na + "..."
 		}
 		callArgs = append(callArgs, na)
@@ -315,6 +630,15 @@ if __err != nil {
 				wrapArgs = append(wrapArgs, anm)
 			}
 		case arg.sym.hasHandle():
+			if !(fsym.isVariadic && i == len(args)-1) {
+				g.genArgFromPy(fsym, arg.sym, anm)
+				if isNilableArg(arg.sym) {
+					g.pywrap.Printf("if %s is None:\n", anm)
+					g.pywrap.Indent()
+					g.pywrap.Printf("%s = go.nil\n", anm)
+					g.pywrap.Outdent()
+				}
+			}
 			wrapArgs = append(wrapArgs, fmt.Sprintf("%s.handle", anm))
 		default:
 			wrapArgs = append(wrapArgs, anm)
@@ -326,7 +650,13 @@ if __err != nil {
 			if arg.sym.gopkg.Name() != fsym.pkg.Name() {
 				packagePrefix = arg.sym.gopkg.Name() + "."
 			}
-			g.pywrap.Printf("%s = %s%s(args)\n", anm, packagePrefix, arg.sym.id)
+			switch {
+			case !arg.sym.hasHandle(): // -bytes converted []byte
+				g.pywrap.Printf("%s = bytearray(args)\n", anm)
+			default:
+				g.genVariadicCheck(fsym, arg.sym)
+				g.pywrap.Printf("%s = %s%s(args)\n", anm, packagePrefix, arg.sym.id)
+			}
 		}
 	}
 
@@ -335,37 +665,94 @@ if __err != nil {
 	if isMethod {
 		mnm = sym.id + "_" + fsym.GoName()
 	}
+	// async wrappers run the call in the default executor of the event loop,
+	// and async iterate over the elements of returned slices.
+	isAsync := g.isAsync(fsym)
+	asyncIter := false
+	pyRet := ""
+	if nres > 0 {
+		pyRet = "return "
+	}
+	// the calls with an injected context are run by go.call_context or
+	// go.await_context, which cancel it when they are interrupted or time
+	// out, and the other calls with a timeout by go.call_timeout or
+	// go.await_timeout.
+	ctxCall := fsym.hasctx && g.lang != 2
+	timeoutKw := g.hasTimeout(fsym)
+	if isAsync {
+		if nres > 0 && !rvIsErr && res[0].sym.isSlice() {
+			asyncIter = true
+			pyRet = "_res = "
+		}
+		switch {
+		case ctxCall:
+			pyRet += "await go.await_context(lambda _ctx: "
+		case timeoutKw:
+			pyRet += "await go.await_timeout(lambda: "
+		default:
+			pyRet += "await asyncio.get_running_loop().run_in_executor(None, lambda: "
+		}
+	} else if ctxCall {
+		pyRet += "go.call_context(lambda _ctx: "
+	} else if timeoutKw {
+		pyRet += "go.call_timeout(lambda: "
+	}
 	rvHasHandle := false
 	if nres > 0 {
 		ret := res[0]
-		if !rvIsErr && ret.sym.hasHandle() {
+		if !rvIsErr && (ret.sym.hasHandle() || ret.sym.isSignature()) {
 			rvHasHandle = true
-			cvnm := ret.sym.pyPkgId(g.pkg.pkg)
-			g.pywrap.Printf("return %s(handle=_%s.%s(", cvnm, pkgname, mnm)
+			cvnm := ret.sym.pyPkgId(g, g.pkg.pkg)
+			g.pywrap.Printf("%s%s(handle=_%s.%s(", pyRet, cvnm, pkgname, mnm)
 		} else {
-			g.pywrap.Printf("return _%s.%s(", pkgname, mnm)
+			g.pywrap.Printf("%s_%s.%s(", pyRet, pkgname, mnm)
 		}
 	} else {
-		g.pywrap.Printf("_%s.%s(", pkgname, mnm)
+		g.pywrap.Printf("%s_%s.%s(", pyRet, pkgname, mnm)
 	}
 
+	// locks are held until the wrapper returns, i.e., after any
+	// return value conversions have been done, unless the GIL is released
+	// during the call, see genGoCall.
+	var locks []string
+	if synced {
+		locks = append(locks, "gopyh.LockHandle((gopyh.CGoHandle)(_handle))")
+	}
+	if g.serialized(fsym) {
+		locks = append(locks, "gopySerial()")
+	}
+	locked := len(locks) > 0
+	lockCall := ""
+	for _, lk := range locks {
+		lockCall += "defer " + lk + "()\n"
+	}
+	if locked && nres > 0 && !nogil {
+		g.gofile.Printf("%s", lockCall)
+	}
+
+	// with the GIL released, the results are converted after the call.
 	hasRetCvt := false
 	hasAddrOfTmp := false
+	hasRetTmp := false
+	callPfx := ""
 	if nres > 0 {
 		ret := res[0]
 		switch {
 		case rvIsErr:
-			g.gofile.Printf("__err = ")
+			callPfx = "__err = "
 		case nres == 2:
-			g.gofile.Printf("cret, __err := ")
+			callPfx = "cret, __err := "
 		case ret.sym.hasHandle() && !ret.sym.isPtrOrIface():
 			hasAddrOfTmp = true
-			g.gofile.Printf("cret := ")
+			callPfx = "cret := "
+		case nogil:
+			hasRetTmp = true
+			callPfx = "cret := "
 		case ret.sym.go2py != "":
 			hasRetCvt = true
-			g.gofile.Printf("return %s(", ret.sym.go2py)
+			callPfx = fmt.Sprintf("return %s(", ret.sym.go2py)
 		default:
-			g.gofile.Printf("return ")
+			callPfx = "return "
 		}
 	}
 	if nres == 0 {
@@ -375,12 +762,34 @@ if __err != nil {
 	if rvHasHandle {
 		g.pywrap.Printf(")")
 	}
+	var kws []string
+	if timeoutKw {
+		kws = append(kws, "timeout")
+	}
+	if g.hasCtxKw(fsym) {
+		kws = append(kws, "ctx=ctx")
+	}
+	switch {
+	case len(kws) > 0:
+		g.pywrap.Printf(", %s)", strings.Join(kws, ", "))
+	case isAsync || ctxCall:
+		g.pywrap.Printf(")")
+	}
+	if asyncIter {
+		g.pywrap.Printf("\nfor _v in _res:\n")
+		g.pywrap.Indent()
+		g.pywrap.Printf("yield _v")
+		g.pywrap.Outdent()
+	}
 
 	funCall := ""
 	if isMethod {
-		if sym.isStruct() {
+		switch {
+		case sym.isSignature():
+			funCall = fmt.Sprintf("vifc.(%s)(%s)", symNm, strings.Join(callArgs, ", "))
+		case sym.isStruct():
 			funCall = fmt.Sprintf("gopyh.Embed(vifc, reflect.TypeOf(%s{})).(%s).%s(%s)", nonPtrName(symNm), symNm, fsym.GoName(), strings.Join(callArgs, ", "))
-		} else {
+		default:
 			funCall = fmt.Sprintf("vifc.(%s).%s(%s)", symNm, fsym.GoName(), strings.Join(callArgs, ", "))
 		}
 	} else {
@@ -394,23 +803,55 @@ if __err != nil {
 	if nres == 0 {
 		g.gofile.Printf("if boolPyToGo(goRun) {\n")
 		g.gofile.Indent()
-		g.gofile.Printf("go %s\n", funCall)
+		g.gofile.Printf("_interp := C.gopy_interp()\n")
+		g.gofile.Printf("go func() {\n")
+		g.gofile.Indent()
+		g.gofile.Printf("defer gopyRecoverGo(_interp)\n")
+		g.gofile.Printf("%s", lockCall)
+		g.genCtxRelease(fsym)
+		g.gofile.Printf("%s\n", funCall)
+		g.gofile.Outdent()
+		g.gofile.Printf("}()\n")
 		g.gofile.Outdent()
 		g.gofile.Printf("} else {\n")
 		g.gofile.Indent()
-		g.gofile.Printf("%s\n", funCall)
+		if !nogil {
+			g.gofile.Printf("%s", lockCall)
+		}
+		g.genCtxRelease(fsym)
+		if nogil && !inline {
+			g.genInterruptibleCall(fsym, res, "", funCall, false)
+		} else {
+			g.genGoCall("", funCall, nogil, locks)
+		}
+		g.genCtxTimeout(fsym, res)
 		g.gofile.Outdent()
 		g.gofile.Printf("}")
 	} else {
-		g.gofile.Printf("%s\n", funCall)
+		g.genCtxRelease(fsym)
+		if nogil && !inline {
+			g.genInterruptibleCall(fsym, res, callPfx, funCall, !isMethod && nres == 2)
+		} else {
+			g.genGoCall(callPfx, funCall, nogil, locks)
+		}
+		g.genCtxTimeout(fsym, res)
+	}
+
+	// the returned result, converted by _cret if the call was interruptible
+	retVal := ""
+	if nres > 0 {
+		retVal = g.retExpr(res[0])
+		if nogil && !inline {
+			retVal = "_cret()"
+		}
 	}
 
 	if rvIsErr || nres == 2 {
 		g.gofile.Printf("\n")
 		g.gofile.Printf("if __err != nil {\n")
 		g.gofile.Indent()
+		g.gofile.Printf("goErrToPy(__err)\n")
 		g.gofile.Printf("estr := C.CString(__err.Error())\n")
-		g.gofile.Printf("C.PyErr_SetString(C.PyExc_RuntimeError, estr)\n")
 		if rvIsErr {
 			g.gofile.Printf("return estr\n") // NOTE: leaked string
 		} else {
@@ -430,20 +871,12 @@ if __err != nil {
 		if rvIsErr {
 			g.gofile.Printf("return C.CString(\"\")") // NOTE: leaked string
 		} else {
-			ret := res[0]
-			if ret.sym.go2py != "" {
-				if ret.sym.hasHandle() && !ret.sym.isPtrOrIface() {
-					g.gofile.Printf("return %s(&cret)%s", ret.sym.go2py, ret.sym.go2pyParenEx)
-				} else {
-					g.gofile.Printf("return %s(cret)%s", ret.sym.go2py, ret.sym.go2pyParenEx)
-				}
-			} else {
-				g.gofile.Printf("return cret")
-			}
+			g.gofile.Printf("return %s", retVal)
 		}
 	} else if hasAddrOfTmp {
-		ret := res[0]
-		g.gofile.Printf("\nreturn %s(&cret)%s", ret.sym.go2py, ret.sym.go2pyParenEx)
+		g.gofile.Printf("\nreturn %s", retVal)
+	} else if hasRetTmp {
+		g.gofile.Printf("return %s", retVal)
 	}
 	g.gofile.Printf("\n")
 	g.gofile.Outdent()
@@ -452,3 +885,112 @@ if __err != nil {
 	g.pywrap.Printf("\n")
 	g.pywrap.Outdent()
 }
+
+// genZeroReturn generates the return of the zero value of the given results,
+// e.g., when a python error is set before the call.
+func (g *pyGen) genZeroReturn(res []*Var) {
+	if len(res) == 0 {
+		g.gofile.Printf("return\n")
+		return
+	}
+	ret := res[0]
+	if ret.sym.zval == "" {
+		fmt.Printf("gopy: programmer error: empty zval zero value in symbol: %v\n", ret.sym)
+	}
+	if ret.sym.go2py != "" {
+		g.gofile.Printf("return %s(%s)%s\n", ret.sym.go2py, ret.sym.zval, ret.sym.go2pyParenEx)
+	} else {
+		g.gofile.Printf("return %s\n", ret.sym.zval)
+	}
+}
+
+// genArgFromPy generates the python code that converts a native python
+// value passed for an arg of a handle type to that type, for the types
+// whose python class can be constructed from one: slices and arrays from
+// sequences, after checking the type of their elements, and maps from
+// mappings, including nested ones, see isPyConstructible.
+func (g *pyGen) genArgFromPy(fsym *Func, sym *symbol, anm string) {
+	if !isPyConstructible(sym.gotyp) {
+		return
+	}
+	var esym *symbol
+	switch typ := sym.gotyp.Underlying().(type) {
+	case *types.Slice:
+		esym = g.syms.symtype(typ.Elem())
+	case *types.Array:
+		esym = g.syms.symtype(typ.Elem())
+	}
+	g.pywrap.Printf("if not isinstance(%s, go.GoClass):\n", anm)
+	g.pywrap.Indent()
+	if esym != nil && !isValueSlice(sym.gotyp) {
+		if pytyp, pynm := g.pyElemType(esym); pytyp != "" {
+			g.pywrap.Printf("for _i, _a in enumerate(%s):\n", anm)
+			g.pywrap.Indent()
+			g.pywrap.Printf("if not isinstance(_a, %s):\n", pytyp)
+			g.pywrap.Indent()
+			g.pywrap.Printf("raise TypeError(\"%s() argument %s element %%d must be %s, not %%s\" %% (_i, type(_a).__name__))\n", fsym.GoName(), anm, pynm)
+			g.pywrap.Outdent()
+			g.pywrap.Outdent()
+		}
+	}
+	g.pywrap.Printf("%s = %s(%s)\n", anm, sym.pyPkgId(g, g.pkg.pkg), anm)
+	g.pywrap.Outdent()
+}
+
+// pyElemType returns the python type that the elements of a python sequence
+// converted to a Go slice of the given element type must be instances of,
+// and the name of that type for errors, or "" if they are not checked.
+func (g *pyGen) pyElemType(esym *symbol) (pytyp, pynm string) {
+	pynm = esym.pysig
+	switch {
+	case isPyConstructible(esym.gotyp):
+		// checked when converted to the element class, see genElemFromPy
+		return "", pynm
+	case esym.hasHandle():
+		pytyp, pynm = "go.GoClass", esym.goname
+	case esym.pysig == "int" || esym.pysig == "long":
+		pytyp = "int"
+		if g.lang == 2 {
+			pytyp = "(int, long)"
+		}
+	case esym.pysig == "float":
+		pytyp = "(int, float)"
+	case esym.pysig == "complex":
+		pytyp = "(int, float, complex)"
+	case esym.pysig == "str":
+		pytyp = "str"
+	case esym.pysig == "bool":
+		pytyp = "bool"
+	}
+	return pytyp, pynm
+}
+
+// genVariadicCheck generates the python code that checks the type of each
+// element of the *args passed to a variadic function, before it is packed
+// into the Go variadic slice, so that a mismatch is reported as a TypeError
+// naming the offending argument.  Elements of a ...interface{} are not
+// checked, as they are deep-converted by valuePyToGo, see isValueSlice.
+func (g *pyGen) genVariadicCheck(fsym *Func, ssym *symbol) {
+	slc, ok := ssym.gotyp.Underlying().(*types.Slice)
+	if !ok {
+		return
+	}
+	esym := g.syms.symtype(slc.Elem())
+	if esym == nil {
+		return
+	}
+	if esym.goname == "interface{}" {
+		return
+	}
+	pytyp, pynm := g.pyElemType(esym)
+	if pytyp == "" {
+		return
+	}
+	g.pywrap.Printf("for _i, _a in enumerate(args):\n")
+	g.pywrap.Indent()
+	g.pywrap.Printf("if not isinstance(_a, %s):\n", pytyp)
+	g.pywrap.Indent()
+	g.pywrap.Printf("raise TypeError(\"%s() variadic argument %%d must be %s, not %%s\" %% (_i, type(_a).__name__))\n", fsym.GoName(), pynm)
+	g.pywrap.Outdent()
+	g.pywrap.Outdent()
+}
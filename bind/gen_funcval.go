@@ -0,0 +1,92 @@
+// Copyright 2019 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bind
+
+import (
+	"go/types"
+	"strings"
+)
+
+// genFuncClass generates the python class wrapping a handle to a Go func
+// value of the given signature type, which is called through __call__.
+func (g *pyGen) genFuncClass(sym *symbol) {
+	sig := sym.gotyp.Underlying().(*types.Signature)
+	ret, haserr, hasfun, err := isPyCompatFunc(sig)
+	if err != nil {
+		return
+	}
+	sv, err := newSignatureFrom(g.pkg, sig)
+	if err != nil {
+		return
+	}
+
+	pkgname := sym.gopkg.Name()
+	pysnm := sym.id
+	if sym.isNamed() {
+		pysnm = strings.TrimPrefix(pysnm, pkgname+"_")
+	}
+
+	gocl := "go."
+	if g.pkg == goPackage {
+		gocl = ""
+	}
+
+	g.pywrap.Printf(`
+# Python type for func %[3]s
+class %[1]s(%[4]sGoClass):
+	""%[2]q""
+`,
+		pysnm,
+		sym.doc,
+		sym.goname,
+		gocl,
+	)
+	g.pywrap.Indent()
+
+	g.pywrap.Printf("def __init__(self, *args, **kwargs):\n")
+	g.pywrap.Indent()
+	g.pywrap.Printf(`"""
+handle=A Go-side object is always initialized with an explicit handle=arg
+"""
+`)
+	g.pywrap.Printf("if len(kwargs) == 1 and 'handle' in kwargs:\n")
+	g.pywrap.Indent()
+	g.pywrap.Printf("self.handle = kwargs['handle']\n")
+	g.pywrap.Printf("_%s.IncRef(self.handle)\n", g.pypkgname)
+	g.pywrap.Outdent()
+	g.pywrap.Printf("elif len(args) == 1 and isinstance(args[0], %sGoClass):\n", gocl)
+	g.pywrap.Indent()
+	g.pywrap.Printf("self.handle = args[0].handle\n")
+	g.pywrap.Printf("_%s.IncRef(self.handle)\n", g.pypkgname)
+	g.pywrap.Outdent()
+	g.pywrap.Printf("else:\n")
+	g.pywrap.Indent()
+	g.pywrap.Printf("self.handle = 0 # for __del__\n")
+	g.pywrap.Printf("raise TypeError('%s can only be obtained from Go')\n", pysnm)
+	g.pywrap.Outdent()
+	g.pywrap.Outdent()
+
+	g.genDel()
+
+	g.pywrap.Printf("def __repr__(self):\n")
+	g.pywrap.Indent()
+	g.pywrap.Printf("return '%s.%s(handle=' + str(self.handle) + ')'\n", pkgname, pysnm)
+	g.pywrap.Outdent()
+
+	fsym := &Func{
+		pkg:    g.pkg,
+		sig:    sv,
+		typ:    sym.gotyp,
+		name:   "__call__",
+		id:     sym.id + "___call__",
+		doc:    "calls the Go func " + sym.goname,
+		ret:    ret,
+		err:    haserr,
+		hasfun: hasfun,
+	}
+	g.genMethod(sym, fsym)
+
+	g.pywrap.Outdent()
+}
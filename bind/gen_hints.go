@@ -0,0 +1,178 @@
+// Copyright 2019 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bind
+
+import (
+	"fmt"
+	"go/types"
+	"strings"
+)
+
+// PyHintsImports are the imports of the python wrappers with type
+// annotations, which are not evaluated, so that they can refer to
+// the classes defined further down.
+const PyHintsImports = `from __future__ import annotations
+from typing import Any, AsyncIterator, Callable, Mapping, Optional, Sequence, Union
+`
+
+// pyTypeHints are the PEP 484 type hints of the pysig of the symbols of the
+// types converted by value.
+var pyTypeHints = map[string]string{
+	"int":       "int",
+	"long":      "int",
+	"float":     "float",
+	"complex":   "complex",
+	"str":       "str",
+	"bool":      "bool",
+	"bytes":     "bytes",
+	"datetime":  "_datetime.datetime",
+	"timedelta": "_datetime.timedelta",
+	"object":    "Any",
+	"callable":  "Callable[..., Any]",
+}
+
+// pyHints returns true if PEP 484 type annotations are generated in the
+// python wrappers, see BindCfg.Annotate.
+func (g *pyGen) pyHints() bool {
+	return g.cfg.Annotate && g.lang != 2
+}
+
+// pyClassHint returns the python class of a type with a handle in a type
+// hint, qualified by its module unless it is in the current one.  In a stub,
+// the module is imported if needed, and classes that are not declared in
+// the stubs are Any, see genPkgStub.
+func (g *pyGen) pyClassHint(sym *symbol) string {
+	if sym.gopkg == nil {
+		return "Any"
+	}
+	nm := sym.pyPkgId(g, g.pkg.pkg)
+	if g.stubClasses == nil {
+		return nm
+	}
+	if g.pkg == goPackage {
+		nm = strings.TrimPrefix(nm, "go.")
+	}
+	if dot := strings.Index(nm, "."); dot >= 0 {
+		if pkg := nm[:dot]; pkg != "go" {
+			g.stubImports[pkg] = true
+		}
+		return nm
+	}
+	if !g.stubClasses[nm] {
+		return "Any"
+	}
+	return nm
+}
+
+// pyTypeHint returns the type hint of a Go type.  The types of params also
+// accept the native python values that are converted to the class of their
+// type, see genArgFromPy.
+func (g *pyGen) pyTypeHint(sym *symbol, param bool) string {
+	if param {
+		return g.pyParamTypeHint(sym, make(map[*symbol]bool))
+	}
+	switch {
+	case sym == nil || sym.goname == "interface{}":
+		return "Any"
+	case sym.isSignature():
+		return "Callable[..., Any]"
+	case sym.hasHandle():
+		return g.pyClassHint(sym)
+	}
+	pysig := sym.pysig
+	if strings.HasPrefix(pysig, "Optional[") {
+		if t, ok := pyTypeHints[strings.TrimSuffix(strings.TrimPrefix(pysig, "Optional["), "]")]; ok {
+			return "Optional[" + t + "]"
+		}
+		return "Any"
+	}
+	if t, ok := pyTypeHints[pysig]; ok {
+		return t
+	}
+	return "Any"
+}
+
+// pyParamTypeHint returns the type hint of a param of a Go type, including
+// the python sequences and mappings that are converted to slices and maps,
+// with the elements of the types being converted in seen, e.g., of
+// recursive types, only accepted as their class.
+func (g *pyGen) pyParamTypeHint(sym *symbol, seen map[*symbol]bool) string {
+	cls := g.pyTypeHint(sym, false)
+	if sym == nil || !sym.hasHandle() || cls == "Any" || !isPyConstructible(sym.gotyp) || seen[sym] {
+		return cls
+	}
+	seen[sym] = true
+	defer delete(seen, sym)
+	switch typ := sym.gotyp.Underlying().(type) {
+	case *types.Slice:
+		return fmt.Sprintf("Union[%s, Sequence[%s]]", cls, g.pyParamTypeHint(g.syms.symtype(typ.Elem()), seen))
+	case *types.Array:
+		return fmt.Sprintf("Union[%s, Sequence[%s]]", cls, g.pyParamTypeHint(g.syms.symtype(typ.Elem()), seen))
+	case *types.Map:
+		return fmt.Sprintf("Union[%s, Mapping[%s, %s]]", cls, g.pyParamTypeHint(g.syms.symtype(typ.Key()), seen), g.pyParamTypeHint(g.syms.symtype(typ.Elem()), seen))
+	}
+	return cls
+}
+
+// argTypeHint returns the type hint of an arg of a function.  Args of
+// pointer types are Optional, as None is passed as a nil pointer.
+func (g *pyGen) argTypeHint(sym *symbol, ifchandle bool) string {
+	switch {
+	case ifchandle && sym.goname == "interface{}":
+		return "go.GoClass"
+	case isNilableArg(sym):
+		if cls := g.pyTypeHint(sym, false); cls != "Any" {
+			return "Optional[" + cls + "]"
+		}
+		return "Any"
+	}
+	return g.pyTypeHint(sym, true)
+}
+
+// isNilableArg returns true for the args of pointer types with a handle,
+// for which None is passed as go.nil.
+func isNilableArg(sym *symbol) bool {
+	return sym.isPointer() && sym.hasHandle()
+}
+
+// variadicTypeHint returns the type hint of the *args of the variadic arg
+// of a function, which are its elements.
+func (g *pyGen) variadicTypeHint(arg *Var) string {
+	if styp, ok := arg.GoType().(*types.Slice); ok {
+		return g.pyTypeHint(g.syms.symtype(styp.Elem()), false)
+	}
+	return "Any"
+}
+
+// retTypeHint returns the type hint of the return value of a function,
+// which is None if it only returns an error, and an AsyncIterator of its
+// elements for the async generators of slices, see genFuncBody.
+func (g *pyGen) retTypeHint(fsym *Func) string {
+	res := fsym.sig.Results()
+	if len(res) == 0 || (len(res) == 1 && fsym.err) {
+		return "None"
+	}
+	if g.isAsync(fsym) && res[0].sym.isSlice() {
+		if styp, ok := res[0].GoType().Underlying().(*types.Slice); ok {
+			return fmt.Sprintf("AsyncIterator[%s]", g.pyTypeHint(g.syms.symtype(styp.Elem()), false))
+		}
+		return "AsyncIterator[Any]"
+	}
+	return g.pyTypeHint(res[0].sym, false)
+}
+
+// typeHint returns the annotation of a value of a Go type, following sep,
+// e.g., ": " for args and " -> " for return values, or "" if no type
+// annotations are generated, see pyHints.  A nil sym is None, e.g., for
+// the return value of setters.
+func (g *pyGen) typeHint(sep string, sym *symbol, param bool) string {
+	switch {
+	case !g.pyHints():
+		return ""
+	case sym == nil:
+		return sep + "None"
+	}
+	return sep + g.pyTypeHint(sym, param)
+}
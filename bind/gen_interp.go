@@ -0,0 +1,268 @@
+// Copyright 2019 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bind
+
+const (
+	// goInterpPreambleC are the C helpers for the python interpreters, as the
+	// module can be imported in several of them (PEP 684), see goInterpDefs.
+	goInterpPreambleC = `
+static inline PyThreadState* gopy_tstate() { // thread state of the calling thread if it holds the GIL, else NULL
+#if defined(Py_LIMITED_API)
+	return NULL; // not in the limited API, only used for the subinterpreters, see gopy_interp_supported
+#elif PY_VERSION_HEX >= 0x030d0000
+	return PyThreadState_GetUnchecked();
+#elif PY_VERSION_HEX >= 0x03050200
+	return _PyThreadState_UncheckedGet();
+#else
+	return _PyThreadState_Current;
+#endif
+}
+static inline PyInterpreterState* gopy_tstate_interp(PyThreadState* tstate) {
+#if PY_VERSION_HEX >= 0x03090000
+	return PyThreadState_GetInterpreter(tstate);
+#else
+	return tstate->interp;
+#endif
+}
+static inline PyInterpreterState* gopy_interp() { // interpreter of the calling thread, which holds its GIL
+	return gopy_tstate_interp(PyThreadState_Get());
+}
+static inline int gopy_is_main_interp(PyInterpreterState* interp) {
+#if defined(Py_LIMITED_API)
+	return PyInterpreterState_GetID(interp) == 0;
+#elif PY_VERSION_HEX >= 0x03080000
+	return interp == PyInterpreterState_Main();
+#else
+	PyInterpreterState* main = PyInterpreterState_Head();
+	while(PyInterpreterState_Next(main) != NULL) {
+		main = PyInterpreterState_Next(main);
+	}
+	return interp == main;
+#endif
+}
+static inline int gopy_interp_supported() { // false with an ImportError for the subinterpreters with -limited-api, as its GIL is only known to be held by the calling thread with the full API
+#ifdef Py_LIMITED_API
+	if(PyInterpreterState_GetID(PyInterpreterState_Get()) != 0) {
+		PyErr_SetString(PyExc_ImportError, "gopy: an extension module built with -limited-api can only be imported by the main interpreter");
+		return 0;
+	}
+#endif
+	return 1;
+}
+`
+
+	// goInterpDefs are the definitions of the state of the module in each
+	// python interpreter that imports it.  The Go runtime, and so the handle
+	// registry, is shared by all the interpreters, which is safe as handles
+	// only refer to Go values, but the python objects held Go-side belong to
+	// the interpreter that created them, and must only be used with its GIL.
+	goInterpDefs = `
+// gopyInterpState is the state of the module in a python interpreter: the
+// python classes registered for the Go errors and structs, which are only
+// used with the GIL of the interpreter, and mu, as there is none in the
+// free-threaded build.
+type gopyInterpState struct {
+	mu         sync.Mutex
+	errClasses map[string]*C.PyObject
+	classes    map[string]*C.PyObject
+}
+
+// gopyInterps holds the state of the module in each python interpreter
+// that imported it, as subinterpreters import it separately (PEP 684).
+var gopyInterps = struct {
+	sync.RWMutex
+	states map[*C.PyInterpreterState]*gopyInterpState
+}{states: map[*C.PyInterpreterState]*gopyInterpState{}}
+
+// gopyState returns the state of the module in the python interpreter of
+// the calling thread, which must hold its GIL.
+func gopyState() *gopyInterpState {
+	interp := C.gopy_interp()
+	gopyInterps.RLock()
+	st := gopyInterps.states[interp]
+	gopyInterps.RUnlock()
+	if st != nil {
+		return st
+	}
+	gopyInterps.Lock()
+	defer gopyInterps.Unlock()
+	if st = gopyInterps.states[interp]; st == nil {
+		st = &gopyInterpState{errClasses: map[string]*C.PyObject{}, classes: map[string]*C.PyObject{}}
+		gopyInterps.states[interp] = st
+	}
+	return st
+}
+
+// register registers the python class cls for name in m, one of the maps
+// of st.
+func (st *gopyInterpState) register(m map[string]*C.PyObject, name string, cls *C.PyObject) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	m[name] = cls
+}
+
+// lookup returns the python class registered for name in m, one of the maps
+// of st.
+func (st *gopyInterpState) lookup(m map[string]*C.PyObject, name string) (*C.PyObject, bool) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	cls, has := m[name]
+	return cls, has
+}
+
+// gopyInterpAlive returns true if the python interpreter has not been
+// finalized, i.e., if it is the main one, or if it has the module state.
+func gopyInterpAlive(interp *C.PyInterpreterState) bool {
+	if C.Py_IsInitialized() == 0 {
+		return false
+	}
+	if interp == nil || C.gopy_is_main_interp(interp) != 0 {
+		return true
+	}
+	gopyInterps.RLock()
+	defer gopyInterps.RUnlock()
+	return gopyInterps.states[interp] != nil
+}
+
+// GoPyInterpInit initializes the state of the module in the python
+// interpreter of the calling thread, when it is imported, or raises an
+// ImportError if the interpreter is not supported
+//export GoPyInterpInit
+func GoPyInterpInit() {
+	if C.gopy_interp_supported() == 0 {
+		return
+	}
+	gopyState()
+}
+
+// GoPyInterpFree releases the state of the module in the python
+// interpreter of the calling thread, when it is freed, e.g., as the
+// subinterpreter is finalized
+//export GoPyInterpFree
+func GoPyInterpFree() {
+	interp := C.gopy_interp()
+	gopyInterps.Lock()
+	st := gopyInterps.states[interp]
+	delete(gopyInterps.states, interp)
+	gopyInterps.Unlock()
+	if st == nil {
+		return
+	}
+	for _, cls := range st.errClasses {
+		C.gopy_decref(cls)
+	}
+	for _, cls := range st.classes {
+		C.gopy_decref(cls)
+	}
+}
+
+// gopyEnter acquires the GIL of the python interpreter interp, nil for
+// the main one, in the calling thread, unless it holds it already, and
+// returns the func that releases it.  The goroutine is locked to its
+// thread in between, as the python thread states are per thread.
+func gopyEnter(interp *C.PyInterpreterState) func() {
+	runtime.LockOSThread()
+	if interp == nil || C.gopy_is_main_interp(interp) != 0 {
+		gstate := C.PyGILState_Ensure()
+		return func() {
+			C.PyGILState_Release(gstate)
+			runtime.UnlockOSThread()
+		}
+	}
+	cur := C.gopy_tstate()
+	if cur != nil && C.gopy_tstate_interp(cur) == interp {
+		return runtime.UnlockOSThread
+	}
+	var saved *C.PyThreadState
+	if cur != nil {
+		saved = C.PyEval_SaveThread()
+	}
+	tstate := C.PyThreadState_New(interp)
+	C.PyEval_RestoreThread(tstate)
+	return func() {
+		C.PyThreadState_Clear(tstate)
+		C.PyEval_ReleaseThread(tstate)
+		C.PyThreadState_Delete(tstate)
+		if saved != nil {
+			C.PyEval_RestoreThread(saved)
+		}
+		runtime.UnlockOSThread()
+	}
+}
+`
+
+	// cModInitFunc defines GOPY_MODINIT_FUNC, the declaration of the PyInit
+	// function of the extension module, which is exported from the DLL on
+	// windows, for the mingw gcc of cgo, whether python itself is built with
+	// mingw or MSVC.  MSVC is not supported as the compiler of the module, as
+	// cgo does not support it.
+	cModInitFunc = `#ifndef GOPY_MODINIT_FUNC
+#if defined(_WIN32) || defined(__CYGWIN__)
+#define GOPY_MODINIT_FUNC __declspec(dllexport) PyObject*
+#else
+#define GOPY_MODINIT_FUNC PyMODINIT_FUNC
+#endif
+#endif
+`
+
+	// pyBuildInterpDefs are the build definitions replacing the single-phase
+	// initialization of the module generated by pybindgen, whose state is
+	// shared by all the interpreters, with a multi-phase one, see goInterpDefs.
+	pyBuildInterpDefs = `
+MULTI_PHASE_INIT = '''
+` + cModInitFunc + `#if PY_VERSION_HEX >= 0x03050000
+static int gopy_mod_exec(PyObject* mod) {
+	GoPyInterpInit();
+	return PyErr_Occurred() ? -1 : 0;
+}
+static void gopy_mod_free(void* mod) {
+	GoPyInterpFree();
+}
+static PyModuleDef_Slot gopy_mod_slots[] = {
+	{Py_mod_exec, (void*)gopy_mod_exec},
+#ifdef Py_mod_multiple_interpreters
+	{Py_mod_multiple_interpreters, Py_MOD_PER_INTERPRETER_GIL_SUPPORTED},
+#endif
+#ifdef Py_mod_gil
+	{Py_mod_gil, %(gil)s},
+#endif
+	{0, NULL},
+};
+static struct PyModuleDef gopy_moduledef = {
+	PyModuleDef_HEAD_INIT, "%(name)s", NULL, 0, %(functions)s, gopy_mod_slots, NULL, NULL, gopy_mod_free,
+};
+#undef PyInit_%(name)s
+GOPY_MODINIT_FUNC
+PyInit_%(name)s(void) {
+	return PyModuleDef_Init(&gopy_moduledef);
+}
+#elif PY_VERSION_HEX >= 0x03000000
+#undef PyInit_%(name)s
+GOPY_MODINIT_FUNC
+PyInit_%(name)s(void) {
+	return gopy_single_phase_init_%(name)s();
+}
+#endif
+'''
+
+def set_multi_phase_init(fname, name, gil='Py_MOD_GIL_USED'):
+    """rewrites the generated C file of the module to the multi-phase initialization of
+    PEP 489, so that the module is initialized separately in each interpreter, and supports
+    the subinterpreters with their own GIL of PEP 684, and the free-threaded build of PEP 703
+    if gil is Py_MOD_GIL_NOT_USED.  The PyInit function of pybindgen is renamed by a macro,
+    and the new one is exported from the DLL on windows, see GOPY_MODINIT_FUNC.  It fails
+    if the functions of the module are not found, as PyInit would not be exported then."""
+    import re
+    with open(fname) as f:
+        code = f.read()
+    m = re.search(r'static PyMethodDef (\w+)\[\]', code)
+    if m is None:
+        raise RuntimeError('gopy: module functions not found in %s, cannot export PyInit_%s' % (fname, name))
+    code = '#define PyInit_%s gopy_single_phase_init_%s\n' % (name, name) + code
+    code += MULTI_PHASE_INIT % {'name': name, 'functions': m.group(1), 'gil': gil}
+    with open(fname, 'w') as f:
+        f.write(code)
+`
+)
@@ -0,0 +1,82 @@
+// Copyright 2019 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bind
+
+// goInterruptDefs are the definitions for the Go calls that are interrupted
+// by python signals, e.g., KeyboardInterrupt on Ctrl-C, see
+// genInterruptibleCall.
+const goInterruptDefs = `
+// gopyInterruptPoll is the interval at which the python signals are checked
+// while a Go call is running
+const gopyInterruptPoll = 50 * time.Millisecond
+
+// gopyCall runs the Go call f, which takes a context, with the GIL released,
+// until it returns, while checking for python signals every
+// gopyInterruptPoll, which are only handled in the main thread.  If a signal
+// handler raises, e.g., KeyboardInterrupt on Ctrl-C, cancel is called to
+// cancel the context of f, which is waited for to return, and false is
+// returned, with the python exception set.  False is also returned if f
+// panics, with the panic raised as a go.GoPanic, see gopyPanicToPy.  The
+// calls without a context, which could not be stopped, are run inline
+// instead, see genInterruptibleCall.
+func gopyCall(f func(), cancel func()) bool {
+	done := make(chan struct{})
+	var p gopyPanic
+	_save := C.PyEval_SaveThread()
+	go func() {
+		defer close(done)
+		defer p.catch()
+		f()
+	}()
+	poll := time.NewTimer(gopyInterruptPoll)
+	defer poll.Stop()
+	for {
+		select {
+		case <-done:
+			C.PyEval_RestoreThread(_save)
+			if p.value != nil {
+				gopyPanicToPy(p.value, p.trace)
+				return false
+			}
+			return true
+		case <-poll.C:
+			C.PyEval_RestoreThread(_save)
+			if C.PyErr_CheckSignals() != 0 {
+				cancel()
+				_save = C.PyEval_SaveThread()
+				<-done
+				C.PyEval_RestoreThread(_save)
+				return false
+			}
+			_save = C.PyEval_SaveThread()
+			poll.Reset(gopyInterruptPoll)
+		}
+	}
+}
+
+// gopyBlock runs op, which blocks on a channel operation, with the GIL
+// released, until it returns true, checking for python signals every
+// gopyInterruptPoll in between, as gopyCall does: op selects on the given
+// poll channel along with its operation, and returns false when it receives
+// from it.  If a signal handler raises, false is returned, with the python
+// exception set, and the operation is abandoned.
+func gopyBlock(op func(poll <-chan time.Time) bool) bool {
+	poll := time.NewTicker(gopyInterruptPoll)
+	defer poll.Stop()
+	for {
+		done := func() bool {
+			_save := C.PyEval_SaveThread()
+			defer C.PyEval_RestoreThread(_save)
+			return op(poll.C)
+		}()
+		if done {
+			return true
+		}
+		if C.PyErr_CheckSignals() != 0 {
+			return false
+		}
+	}
+}
+`
@@ -0,0 +1,59 @@
+// Copyright 2019 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bind
+
+import (
+	"regexp"
+)
+
+// goPkgAll are the public names defined by GoPkgDefs in the go package,
+// which are defined in its preamble, see genPyAll.
+var goPkgAll = []string{"GoClass", "GoError", "GoPanic", "nil", "main", "Init", "setenv"}
+
+// pyTopLevelRe matches the names of the classes, functions and variables
+// defined at the top level of a python wrapper.
+var pyTopLevelRe = regexp.MustCompile(`(?m)^(?:class\s+([A-Za-z]\w*)|(?:async\s+)?def\s+([A-Za-z]\w*)|([A-Za-z]\w*)\s*=[^=])`)
+
+// genPyAll generates the __all__ of the python wrapper of the current
+// package, listing the public names defined after its preamble, which
+// starts at offset start of the wrapper, so that star imports, tab
+// completion and introspection tools see the API of the package.
+func (g *pyGen) genPyAll(start int) {
+	var names []string
+	if g.pkg == goPackage {
+		names = append(names, goPkgAll...)
+		if g.isDev() {
+			names = append(names, "reload")
+		}
+		if g.cfg.Metrics {
+			names = append(names, "metrics", "reset_metrics", "serve_metrics")
+		}
+		if g.cfg.Finalize {
+			names = append(names, "collect")
+		}
+		if g.lang != 2 {
+			names = append(names, "chan_queue", "Context", "with_deadline", "with_timeout", "Pool", "call_context", "await_context", "call_timeout", "await_timeout")
+		}
+	}
+	for _, m := range pyTopLevelRe.FindAllSubmatch(g.pywrap.buf.Bytes()[start:], -1) {
+		for _, nm := range m[1:] {
+			if len(nm) > 0 {
+				names = append(names, string(nm))
+			}
+		}
+	}
+	seen := make(map[string]bool)
+	g.pywrap.Printf("\n\n__all__ = [\n")
+	g.pywrap.Indent()
+	for _, nm := range names {
+		if seen[nm] {
+			continue
+		}
+		seen[nm] = true
+		g.pywrap.Printf("%q,\n", nm)
+	}
+	g.pywrap.Outdent()
+	g.pywrap.Printf("]\n")
+}
@@ -0,0 +1,152 @@
+// Copyright 2019 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bind
+
+import (
+	"go/types"
+	"reflect"
+	"strings"
+)
+
+const (
+	// goJSONPreambleC are the C helpers for the conversions of json.RawMessage
+	// to and from python values, through the json module, when generating
+	// with -json
+	goJSONPreambleC = `
+static inline PyObject* gopy_json_call(char* fn, PyObject* arg) { // json.fn(arg), NULL on error
+	PyObject* mod = PyImport_ImportModule("json");
+	if(mod == NULL) {
+		return NULL;
+	}
+	PyObject* res = PyObject_CallMethod(mod, fn, (char*)"O", arg);
+	Py_DECREF(mod);
+	return res;
+}
+static inline PyObject* gopy_json_loads(PyObject* s) {
+	return gopy_json_call((char*)"loads", s);
+}
+static inline PyObject* gopy_json_dumps(PyObject* o) {
+	return gopy_json_call((char*)"dumps", o);
+}
+`
+
+	// goJSONDefs are the Go conversions of json.RawMessage to and from python
+	// values, when generating with -json
+	goJSONDefs = `
+// rawJSONGoToPy converts a Go json.RawMessage to the python value it
+// encodes, as given by json.loads, or to None if it is empty
+func rawJSONGoToPy(m json.RawMessage) *C.PyObject {
+	if len(m) == 0 {
+		return C.gopy_none()
+	}
+	cs := C.CString(string(m))
+	defer C.free(unsafe.Pointer(cs))
+	s := C.gopy_build_string(cs)
+	defer C.gopy_decref(s)
+	return C.gopy_json_loads(s)
+}
+
+// rawJSONPyToGo converts a python value to a Go json.RawMessage encoding it,
+// as given by json.dumps, or None to nil
+func rawJSONPyToGo(o *C.PyObject) json.RawMessage {
+	if C.gopy_value_kind(o) == 0 {
+		return nil
+	}
+	s := C.gopy_json_dumps(o)
+	if s == nil {
+		return nil // python error is set
+	}
+	defer C.gopy_decref(s)
+	return json.RawMessage(C.GoString(C.gopy_string(s)))
+}
+`
+)
+
+// rawJSONType is the full name of the json.RawMessage type
+const rawJSONType = "encoding/json.RawMessage"
+
+// isJSONField returns true if the i'th field of the struct is a []byte
+// field tagged with the json option of the gopy struct tag, e.g.,
+// `gopy:",json"`, which is converted as a json.RawMessage with -json.
+func isJSONField(s *Struct, i int, f types.Object) bool {
+	if !s.pkg.syms.cfg.JSON {
+		return false
+	}
+	sl, ok := f.Type().(*types.Slice)
+	if !ok {
+		return false
+	}
+	if bt, ok := sl.Elem().(*types.Basic); !ok || bt.Kind() != types.Byte {
+		return false
+	}
+	opts := reflect.StructTag(s.Struct().Tag(i)).Get("gopy")
+	idx := strings.Index(opts, ",")
+	if idx < 0 {
+		return false
+	}
+	for _, opt := range strings.Split(opts[idx+1:], ",") {
+		if opt == "json" {
+			return true
+		}
+	}
+	return false
+}
+
+// jsonFieldSymbol returns the symbol of a []byte field converted as
+// a json.RawMessage, see isJSONField.
+func (sym *symtab) jsonFieldSymbol(f types.Object) *symbol {
+	vc := sym.findValueConv(rawJSONType)
+	return vc.symbol(nil, f, f.Type(), "Slice_byte", "[]byte")
+}
+
+// genStructJSON generates the to_json method and from_json classmethod of
+// the python class of the struct, which encode and decode it with
+// encoding/json in Go, honoring its json struct tags, see genStructJSONGo.
+func (g *pyGen) genStructJSON(s *Struct) {
+	g.pywrap.Printf("def to_json(self):\n")
+	g.pywrap.Indent()
+	g.pywrap.Printf(`"""to_json returns the JSON encoding of the struct, as a str, by json.Marshal in Go"""`)
+	g.pywrap.Printf("\n")
+	g.pywrap.Printf("return _%s.%s_to_json(self.handle)\n", g.pypkgname, s.ID())
+	g.pywrap.Outdent()
+	g.pywrap.Printf("@classmethod\n")
+	g.pywrap.Printf("def from_json(cls, s):\n")
+	g.pywrap.Indent()
+	g.pywrap.Printf(`"""from_json returns a new struct decoded from the JSON str or bytes s, by json.Unmarshal in Go"""`)
+	g.pywrap.Printf("\n")
+	g.pywrap.Printf("rv = cls.__new__(cls)\n")
+	g.pywrap.Printf("rv.handle = _%s.%s_from_json(s)\n", g.pypkgname, s.ID())
+	g.pywrap.Printf("_%s.IncRef(rv.handle)\n", g.pypkgname)
+	g.pywrap.Printf("return rv\n")
+	g.pywrap.Outdent()
+}
+
+// genStructJSONGo generates the Go side of the to_json and from_json of the
+// python class of the struct, see genStructJSON.
+func (g *pyGen) genStructJSONGo(s *Struct) {
+	g.gofile.Printf("//export %s_to_json\n", s.ID())
+	g.gofile.Printf("func %s_to_json(handle CGoHandle) *C.PyObject {\n", s.ID())
+	g.gofile.Indent()
+	g.gofile.Printf("return gopyJSONEncode(ptrFromHandle_%s(handle))\n", s.ID())
+	g.gofile.Outdent()
+	g.gofile.Printf("}\n\n")
+
+	g.declFunc("add_checked_function", s.ID()+"_to_json", "PyObject*", capiParam{PyHandle, "handle"})
+
+	g.gofile.Printf("//export %s_from_json\n", s.ID())
+	g.gofile.Printf("func %s_from_json(o *C.PyObject) CGoHandle {\n", s.ID())
+	g.gofile.Indent()
+	g.gofile.Printf("p := new(%s)\n", s.GoName())
+	g.gofile.Printf("if !gopyJSONDecode(o, p) {\n")
+	g.gofile.Indent()
+	g.gofile.Printf("return -1 // python error is set\n")
+	g.gofile.Outdent()
+	g.gofile.Printf("}\n")
+	g.gofile.Printf("return CGoHandle(handleFromPtr_%s(p))\n", s.ID())
+	g.gofile.Outdent()
+	g.gofile.Printf("}\n\n")
+
+	g.declFunc("add_checked_function", s.ID()+"_from_json", PyHandle, capiParam{"PyObject*", "o"})
+}
@@ -0,0 +1,58 @@
+// Copyright 2019 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bind
+
+import "fmt"
+
+// LimitedAPIVersion is the Py_LIMITED_API of the C code generated with
+// -limited-api: the stable ABI of python 3.11, the first one with the
+// buffer protocol, which the memoryviews of the slices use.
+const LimitedAPIVersion = "0x030B0000"
+
+const (
+	// pyBuildLimitedAPIDefs restrict the C file generated by pybindgen to
+	// the limited API, with -limited-api.
+	pyBuildLimitedAPIDefs = `
+def set_limited_api(fname, version):
+    """defines Py_LIMITED_API at the top of the generated C file of the module, before
+    Python.h is included, so that it only uses the stable ABI of the given version"""
+    with open(fname) as f:
+        code = f.read()
+    with open(fname, 'w') as f:
+        f.write('#define Py_LIMITED_API %s\n' % version + code)
+`
+)
+
+// limitedAPIDefine returns the definition of Py_LIMITED_API in the C
+// preamble of the Go code, before Python.h is included, or the comment on
+// why it is not defined, without -limited-api.
+func (g *pyGen) limitedAPIDefine() string {
+	if !g.cfg.LimitedAPI {
+		return "// #define Py_LIMITED_API // need full API for PyRun*"
+	}
+	return fmt.Sprintf("#define Py_LIMITED_API %s // -limited-api: stable ABI only", LimitedAPIVersion)
+}
+
+// checkLimitedAPI returns an error if the config does not support
+// -limited-api: the exe mode embeds the interpreter, with the PyRun and
+// Py_Main functions of the full API, and the free-threaded build, the C API
+// of datetime, and the C code of the cffi and cython backends do not
+// support the limited API.
+func checkLimitedAPI(mode BuildMode, cfg *BindCfg) error {
+	if !cfg.LimitedAPI {
+		return nil
+	}
+	switch {
+	case mode == ModeExe:
+		return fmt.Errorf("gopy: -limited-api does not support exe")
+	case cfg.FreeThreaded:
+		return fmt.Errorf("gopy: -limited-api does not support -free-threaded")
+	case cfg.DateTime:
+		return fmt.Errorf("gopy: -limited-api does not support -datetime")
+	case cfg.Backend == BackendCffi || cfg.Backend == BackendCython:
+		return fmt.Errorf("gopy: -limited-api does not support -backend=%s", cfg.Backend)
+	}
+	return nil
+}
@@ -57,6 +57,7 @@ class %[2]s(%[5]sGoClass):
 	}
 	if !extTypes || pyWrapOnly {
 		g.pywrap.Outdent()
+		g.pywrap.Printf("_collections_abc.MutableMapping.register(%s)\n", pysnm)
 	}
 }
 
@@ -65,21 +66,21 @@ func (g *pyGen) genMapInit(slc *symbol, extTypes, pyWrapOnly bool, mpob *Map) {
 	slNm := slc.id
 	qNm := pkgname + "." + slNm
 	typ := slc.GoType().Underlying().(*types.Map)
-	esym := current.symtype(typ.Elem())
-	ksym := current.symtype(typ.Key())
+	esym := g.syms.symtype(typ.Elem())
+	ksym := g.syms.symtype(typ.Key())
 
 	// key slice type and name
 	keyslt := types.NewSlice(typ.Key())
-	keyslsym := current.symtype(keyslt)
+	keyslsym := g.syms.symtype(keyslt)
 	if keyslsym == nil {
-		fmt.Printf("nil key slice type!: %s map: %s\n", current.fullTypeString(keyslt), slc.goname)
+		fmt.Printf("nil key slice type!: %s map: %s\n", g.syms.fullTypeString(keyslt), slc.goname)
 		return
 	}
 	keyslnm := ""
 	if g.pkg == nil {
 		keyslnm = keyslsym.id
 	} else {
-		keyslnm = keyslsym.pyPkgId(g.pkg.pkg)
+		keyslnm = keyslsym.pyPkgId(g, g.pkg.pkg)
 	}
 
 	gocl := "go."
@@ -108,37 +109,47 @@ otherwise parameter is a python list that we copy from
 		g.pywrap.Outdent()
 		g.pywrap.Printf("else:\n")
 		g.pywrap.Indent()
-		g.pywrap.Printf("self.handle = _%s_CTor()\n", qNm)
-		g.pywrap.Printf("_%s.IncRef(self.handle)\n", g.pypkgname)
-		g.pywrap.Printf("if len(args) > 0:\n")
-		g.pywrap.Indent()
-		g.pywrap.Printf("if not isinstance(args[0], _collections_abc.Mapping):\n")
-		g.pywrap.Indent()
-		g.pywrap.Printf("raise TypeError('%s.__init__ takes a mapping as argument')\n", slNm)
-		g.pywrap.Outdent()
-		g.pywrap.Printf("for k, v in args[0].items():\n")
-		g.pywrap.Indent()
-		g.pywrap.Printf("_%s_set(self.handle, k, v)\n", qNm)
-		g.pywrap.Outdent()
-		g.pywrap.Outdent()
+		if isValueMap(slc.gotyp) {
+			// deep-converted from a python dict, e.g., of JSON-like values
+			g.pywrap.Printf("self.handle = 0 # for __del__ if the conversion fails\n")
+			g.pywrap.Printf("if len(args) > 0 and not isinstance(args[0], _collections_abc.Mapping):\n")
+			g.pywrap.Indent()
+			g.pywrap.Printf("raise TypeError('%s.__init__ takes a mapping as argument')\n", slNm)
+			g.pywrap.Outdent()
+			g.pywrap.Printf("self.handle = _%s_from_py(dict(args[0]) if len(args) > 0 else {})\n", qNm)
+			g.pywrap.Printf("_%s.IncRef(self.handle)\n", g.pypkgname)
+		} else {
+			g.pywrap.Printf("self.handle = _%s_CTor()\n", qNm)
+			g.pywrap.Printf("_%s.IncRef(self.handle)\n", g.pypkgname)
+			g.pywrap.Printf("if len(args) > 0:\n")
+			g.pywrap.Indent()
+			g.pywrap.Printf("if not isinstance(args[0], _collections_abc.Mapping):\n")
+			g.pywrap.Indent()
+			g.pywrap.Printf("raise TypeError('%s.__init__ takes a mapping as argument')\n", slNm)
+			g.pywrap.Outdent()
+			g.pywrap.Printf("for k, v in args[0].items():\n")
+			g.pywrap.Indent()
+			g.pywrap.Printf("self[k] = v\n")
+			g.pywrap.Outdent()
+			g.pywrap.Outdent()
+		}
 		g.pywrap.Outdent()
 		g.pywrap.Outdent()
 
-		g.pywrap.Printf("def __del__(self):\n")
-		g.pywrap.Indent()
-		g.pywrap.Printf("_%s.DecRef(self.handle)\n", g.pypkgname)
-		g.pywrap.Outdent()
+		g.genDel()
+		if mpob != nil && mpob.prots&ProtoCloser != 0 {
+			g.genCloser()
+		}
+		var meths []*Func
+		if mpob != nil {
+			meths = mpob.meths
+			g.genOperators(meths)
+		}
+		g.genBool(meths, true)
+		g.genCopy("_"+qNm+"_deepcopy", false)
 
 		if mpob != nil && mpob.prots&ProtoStringer != 0 {
-			for _, m := range mpob.meths {
-				if isStringer(m.obj) {
-					g.pywrap.Printf("def __str__(self):\n")
-					g.pywrap.Indent()
-					g.pywrap.Printf("return self.String()\n")
-					g.pywrap.Outdent()
-					g.pywrap.Printf("\n")
-				}
-			}
+			g.genStringer(mpob.GoName())
 		} else {
 			g.pywrap.Printf("def __str__(self):\n")
 			g.pywrap.Indent()
@@ -152,17 +163,17 @@ otherwise parameter is a python list that we copy from
 			g.pywrap.Outdent()
 			g.pywrap.Println("return s + '}'")
 			g.pywrap.Outdent()
-		}
 
-		g.pywrap.Printf("def __repr__(self):\n")
-		g.pywrap.Indent()
-		g.pywrap.Printf("s = '%s.%s({'\n", pkgname, slNm)
-		g.pywrap.Printf("for k, v in self.items():\n")
-		g.pywrap.Indent()
-		g.pywrap.Printf("s += str(k) + '=' + str(v) + ', '\n")
-		g.pywrap.Outdent()
-		g.pywrap.Println("return s + '})'")
-		g.pywrap.Outdent()
+			g.pywrap.Printf("def __repr__(self):\n")
+			g.pywrap.Indent()
+			g.pywrap.Printf("s = '%s.%s({'\n", pkgname, slNm)
+			g.pywrap.Printf("for k, v in self.items():\n")
+			g.pywrap.Indent()
+			g.pywrap.Printf("s += str(k) + '=' + str(v) + ', '\n")
+			g.pywrap.Outdent()
+			g.pywrap.Println("return s + '})'")
+			g.pywrap.Outdent()
+		}
 
 		g.pywrap.Printf("def __len__(self):\n")
 		g.pywrap.Indent()
@@ -171,15 +182,22 @@ otherwise parameter is a python list that we copy from
 
 		g.pywrap.Printf("def __getitem__(self, key):\n")
 		g.pywrap.Indent()
+		if esym.isStruct() && !esym.isPointer() {
+			g.pywrap.Printf(`"""
+returns a copy of the struct value, as map values are not addressable in Go:
+assign it back to update the map
+"""
+`)
+		}
 		if ksym.hasHandle() {
 			if esym.hasHandle() {
-				g.pywrap.Printf("return %s(handle=_%s_elem(self.handle, key.handle))\n", esym.pyPkgId(slc.gopkg), qNm)
+				g.pywrap.Printf("return %s(handle=_%s_elem(self.handle, key.handle))\n", esym.pyPkgId(g, slc.gopkg), qNm)
 			} else {
 				g.pywrap.Printf("return _%s_elem(self.handle, key.handle)\n", qNm)
 			}
 		} else {
 			if esym.hasHandle() {
-				g.pywrap.Printf("return %s(handle=_%s_elem(self.handle, key))\n", esym.pyPkgId(slc.gopkg), qNm)
+				g.pywrap.Printf("return %s(handle=_%s_elem(self.handle, key))\n", esym.pyPkgId(g, slc.gopkg), qNm)
 			} else {
 				g.pywrap.Printf("return _%s_elem(self.handle, key)\n", qNm)
 			}
@@ -188,6 +206,7 @@ otherwise parameter is a python list that we copy from
 
 		g.pywrap.Printf("def __setitem__(self, key, value):\n")
 		g.pywrap.Indent()
+		g.genElemFromPy(slc, esym, gocl)
 		if esym.hasHandle() {
 			if ksym.hasHandle() {
 				g.pywrap.Printf("_%s_set(self.handle, key.handle, value.handle)\n", qNm)
@@ -217,31 +236,39 @@ otherwise parameter is a python list that we copy from
 		g.pywrap.Printf("return %s(handle=_%s_keys(self.handle))\n", keyslnm, qNm)
 		g.pywrap.Outdent()
 
+		// keys and values are converted in one pass over the map on the Go
+		// side, with an iterator handle, see genMapIterGo
+		kcvt, ecvt := "%s", "%s"
+		if ksym.hasHandle() {
+			kcvt = ksym.pyPkgId(g, slc.gopkg) + "(handle=%s)"
+		}
+		if esym.hasHandle() {
+			ecvt = esym.pyPkgId(g, slc.gopkg) + "(handle=%s)"
+		}
+		ikey := fmt.Sprintf(kcvt, fmt.Sprintf("_%s_iter_key(it)", qNm))
+		ival := fmt.Sprintf(ecvt, fmt.Sprintf("_%s_iter_value(it)", qNm))
+
 		g.pywrap.Printf("def values(self):\n")
 		g.pywrap.Indent()
-		g.pywrap.Printf("vls = []\n")
-		g.pywrap.Printf("kys = self.keys()\n")
-		g.pywrap.Printf("for k in kys:\n")
-		g.pywrap.Indent()
-		g.pywrap.Printf("vls.append(self[k])\n")
-		g.pywrap.Outdent()
-		g.pywrap.Printf("return vls\n")
+		g.pywrap.Printf("return [v for _, v in self.iteritems()]\n")
 		g.pywrap.Outdent()
 
 		g.pywrap.Printf("def items(self):\n")
 		g.pywrap.Indent()
-		g.pywrap.Printf("vls = []\n")
-		g.pywrap.Printf("kys = self.keys()\n")
-		g.pywrap.Printf("for k in kys:\n")
-		g.pywrap.Indent()
-		g.pywrap.Printf("vls.append((k, self[k]))\n")
+		g.pywrap.Printf("return list(self.iteritems())\n")
 		g.pywrap.Outdent()
-		g.pywrap.Printf("return vls\n")
+
+		g.pywrap.Printf("def iteritems(self):\n")
+		g.pywrap.Indent()
+		g.pywrap.Printf(`"""iteritems yields the (key, value) pairs of the map, in Go map order,
+converted in one pass over the map on the Go side"""
+`)
+		g.genMapIter(qNm, fmt.Sprintf("(%s, %s)", ikey, ival))
 		g.pywrap.Outdent()
 
 		g.pywrap.Printf("def __iter__(self):\n")
 		g.pywrap.Indent()
-		g.pywrap.Printf("return iter(self.items())\n")
+		g.genMapIter(qNm, ikey)
 		g.pywrap.Outdent()
 
 		g.pywrap.Printf("def __contains__(self, key):\n")
@@ -253,6 +280,8 @@ otherwise parameter is a python list that we copy from
 		}
 		g.pywrap.Outdent()
 
+		g.genMapMapping(slc)
+
 		// g.pywrap.Printf("def __next__(self):\n")
 		// g.pywrap.Indent()
 		// g.pywrap.Printf("if self.index >= len(self):\n")
@@ -275,23 +304,31 @@ otherwise parameter is a python list that we copy from
 		g.gofile.Outdent()
 		g.gofile.Printf("}\n\n")
 
-		g.pybuild.Printf("mod.add_function('%s', retval('%s'), [])\n", ctNm, PyHandle)
+		g.declFunc("mod.add_function", ctNm, PyHandle)
+		g.genDeepCopyGo(slNm, slc.goname)
+
+		if isValueMap(slc.gotyp) {
+			g.genValueFromPyGo(slc, "map[string]interface{}")
+		}
 
 		// len
 		g.gofile.Printf("//export %s_len\n", slNm)
 		g.gofile.Printf("func %s_len(handle CGoHandle) int {\n", slNm)
 		g.gofile.Indent()
-		g.gofile.Printf("return len(deptrFromHandle_%s(handle))\n", slNm)
+		g.gofile.Printf("s := deptrFromHandle_%s(handle)\n", slNm)
+		g.genValueLock("s")
+		g.gofile.Printf("return len(s)\n")
 		g.gofile.Outdent()
 		g.gofile.Printf("}\n\n")
 
-		g.pybuild.Printf("mod.add_function('%s_len', retval('int'), [param('%s', 'handle')])\n", slNm, PyHandle)
+		g.declFunc("mod.add_function", slNm+"_len", "int", capiParam{PyHandle, "handle"})
 
 		// elem
 		g.gofile.Printf("//export %s_elem\n", slNm)
 		g.gofile.Printf("func %s_elem(handle CGoHandle, _ky %s) %s {\n", slNm, ksym.cgoname, esym.cgoname)
 		g.gofile.Indent()
 		g.gofile.Printf("s := deptrFromHandle_%s(handle)\n", slNm)
+		g.genValueLock("s")
 		if ksym.py2go != "" {
 			g.gofile.Printf("v, ok := s[%s(_ky)%s]\n", ksym.py2go, ksym.py2goParenEx)
 		} else {
@@ -299,24 +336,29 @@ otherwise parameter is a python list that we copy from
 		}
 		g.gofile.Printf("if !ok {\n")
 		g.gofile.Indent()
-		g.gofile.Printf("C.PyErr_SetString(C.PyExc_KeyError, C.CString(\"key not in map\"))\n")
+		g.gofile.Printf("gopySetError(C.PyExc_KeyError, \"key not in map\")\n")
 		g.gofile.Outdent()
 		g.gofile.Printf("}\n")
 		if esym.go2py != "" {
-			g.gofile.Printf("return %s(v)%s\n", esym.go2py, esym.go2pyParenEx)
+			if esym.hasHandle() && !esym.isPtrOrIface() {
+				g.gofile.Printf("return %s(&v)%s\n", esym.go2py, esym.go2pyParenEx)
+			} else {
+				g.gofile.Printf("return %s(v)%s\n", esym.go2py, esym.go2pyParenEx)
+			}
 		} else {
 			g.gofile.Printf("return v\n")
 		}
 		g.gofile.Outdent()
 		g.gofile.Printf("}\n\n")
 
-		g.pybuild.Printf("mod.add_function('%s_elem', retval('%s'), [param('%s', 'handle'), param('%s', '_ky')])\n", slNm, esym.cpyname, PyHandle, ksym.cpyname)
+		g.declFunc("add_checked_function", slNm+"_elem", esym.cpyname, capiParam{PyHandle, "handle"}, capiParam{ksym.cpyname, "_ky"})
 
 		// contains
 		g.gofile.Printf("//export %s_contains\n", slNm)
 		g.gofile.Printf("func %s_contains(handle CGoHandle, _ky %s) C.char {\n", slNm, ksym.cgoname)
 		g.gofile.Indent()
 		g.gofile.Printf("s := deptrFromHandle_%s(handle)\n", slNm)
+		g.genValueLock("s")
 		if ksym.py2go != "" {
 			g.gofile.Printf("_, ok := s[%s(_ky)%s]\n", ksym.py2go, ksym.py2goParenEx)
 		} else {
@@ -326,33 +368,28 @@ otherwise parameter is a python list that we copy from
 		g.gofile.Outdent()
 		g.gofile.Printf("}\n\n")
 
-		g.pybuild.Printf("mod.add_function('%s_contains', retval('bool'), [param('%s', 'handle'), param('%s', '_ky')])\n", slNm, PyHandle, ksym.cpyname)
+		g.declFunc("add_checked_function", slNm+"_contains", "bool", capiParam{PyHandle, "handle"}, capiParam{ksym.cpyname, "_ky"})
 
 		// set
 		g.gofile.Printf("//export %s_set\n", slNm)
 		g.gofile.Printf("func %s_set(handle CGoHandle, _ky %s, _vl %s) {\n", slNm, ksym.cgoname, esym.cgoname)
 		g.gofile.Indent()
 		g.gofile.Printf("s := deptrFromHandle_%s(handle)\n", slNm)
-		if ksym.py2go != "" {
-			g.gofile.Printf("s[%s(_ky)%s] = ", ksym.py2go, ksym.py2goParenEx)
-		} else {
-			g.gofile.Printf("s[_ky] = ")
-		}
-		if esym.py2go != "" {
-			g.gofile.Printf("%s(_vl)%s\n", esym.py2go, esym.py2goParenEx)
-		} else {
-			g.gofile.Printf("_vl\n")
-		}
+		g.genGoFromPy("k", ksym, "_ky")
+		g.genGoFromPy("v", esym, "_vl")
+		g.genValueLock("s")
+		g.gofile.Printf("s[k] = v\n")
 		g.gofile.Outdent()
 		g.gofile.Printf("}\n\n")
 
-		g.pybuild.Printf("mod.add_function('%s_set', None, [param('%s', 'handle'), param('%s', 'key'), param('%s', 'value')])\n", slNm, PyHandle, ksym.cpyname, esym.cpyname)
+		g.declFunc("add_checked_function", slNm+"_set", "", capiParam{PyHandle, "handle"}, capiParam{ksym.cpyname, "key"}, capiParam{esym.cpyname, "value"})
 
 		// delete
 		g.gofile.Printf("//export %s_delete\n", slNm)
 		g.gofile.Printf("func %s_delete(handle CGoHandle, _ky %s) {\n", slNm, ksym.cgoname)
 		g.gofile.Indent()
 		g.gofile.Printf("s := deptrFromHandle_%s(handle)\n", slNm)
+		g.genValueLock("s")
 		if ksym.py2go != "" {
 			g.gofile.Printf("delete(s, %s(_ky)%s)\n", ksym.py2go, ksym.py2goParenEx)
 		} else {
@@ -361,13 +398,14 @@ otherwise parameter is a python list that we copy from
 		g.gofile.Outdent()
 		g.gofile.Printf("}\n\n")
 
-		g.pybuild.Printf("mod.add_function('%s_delete', None, [param('%s', 'handle'), param('%s', '_ky')])\n", slNm, PyHandle, ksym.cpyname)
+		g.declFunc("add_checked_function", slNm+"_delete", "", capiParam{PyHandle, "handle"}, capiParam{ksym.cpyname, "_ky"})
 
 		// keys
 		g.gofile.Printf("//export %s_keys\n", slNm)
 		g.gofile.Printf("func %s_keys(handle CGoHandle) CGoHandle {\n", slNm)
 		g.gofile.Indent()
 		g.gofile.Printf("s := deptrFromHandle_%s(handle)\n", slNm)
+		g.genValueLock("s")
 		g.gofile.Printf("kys := make(%s, 0, len(s))\n", keyslsym.goname)
 		g.gofile.Printf("for k := range(s) {\n")
 		g.gofile.Indent()
@@ -378,13 +416,273 @@ otherwise parameter is a python list that we copy from
 		g.gofile.Outdent()
 		g.gofile.Printf("}\n\n")
 
-		g.pybuild.Printf("mod.add_function('%s_keys', retval('%s'), [param('%s', 'handle')])\n", slNm, keyslsym.cpyname, PyHandle)
+		g.declFunc("mod.add_function", slNm+"_keys", keyslsym.cpyname, capiParam{PyHandle, "handle"})
+
+		g.genMapIterGo(slNm, slc.goname, ksym, esym)
 
 	}
 }
 
+// genMapIter generates the body of a python generator over the map, which
+// yields the value given by yield for each entry of its Go iterator it,
+// see genMapIterGo.
+func (g *pyGen) genMapIter(qNm, yield string) {
+	g.pywrap.Printf("it = _%s_iter(self.handle)\n", qNm)
+	g.pywrap.Printf("_%s.IncRef(it)\n", g.pypkgname)
+	g.pywrap.Printf("try:\n")
+	g.pywrap.Indent()
+	g.pywrap.Printf("while _%s_iter_next(it):\n", qNm)
+	g.pywrap.Indent()
+	g.pywrap.Printf("yield %s\n", yield)
+	g.pywrap.Outdent()
+	g.pywrap.Outdent()
+	g.pywrap.Printf("finally:\n")
+	g.pywrap.Indent()
+	g.pywrap.Printf("_%s.DecRef(it)\n", g.pypkgname)
+	g.pywrap.Outdent()
+}
+
+// genMapIterGo generates the Go side of the iteration over the map: the
+// handle of a new reflect.MapIter over it, which advances with next, and
+// the key and value of its current entry.  With -free-threaded, it
+// iterates a copy of the map of the given Go type name.
+func (g *pyGen) genMapIterGo(slNm, goname string, ksym, esym *symbol) {
+	const iterOf = "gopyh.VarFromHandle((gopyh.CGoHandle)(it), \"*reflect.MapIter\").(*reflect.MapIter)"
+
+	g.gofile.Printf("//export %s_iter\n", slNm)
+	g.gofile.Printf("func %s_iter(handle CGoHandle) CGoHandle {\n", slNm)
+	g.gofile.Indent()
+	g.gofile.Printf("s := deptrFromHandle_%s(handle)\n", slNm)
+	if g.cfg.FreeThreaded {
+		// the iterator is over a copy, as the map can be modified
+		// concurrently by other python threads while it is iterated
+		g.gofile.Printf("c := make(%s, len(s))\n", goname)
+		g.gofile.Printf("func() {\n")
+		g.gofile.Indent()
+		g.genValueLock("s")
+		g.gofile.Printf("for k, v := range s {\n")
+		g.gofile.Indent()
+		g.gofile.Printf("c[k] = v\n")
+		g.gofile.Outdent()
+		g.gofile.Printf("}\n")
+		g.gofile.Outdent()
+		g.gofile.Printf("}()\n")
+		g.gofile.Printf("s = c\n")
+	}
+	g.gofile.Printf("return CGoHandle(gopyh.Register(\"*reflect.MapIter\", reflect.ValueOf(s).MapRange()))\n")
+	g.gofile.Outdent()
+	g.gofile.Printf("}\n\n")
+
+	g.gofile.Printf("//export %s_iter_next\n", slNm)
+	g.gofile.Printf("func %s_iter_next(it CGoHandle) C.char {\n", slNm)
+	g.gofile.Indent()
+	g.gofile.Printf("return boolGoToPy(%s.Next())\n", iterOf)
+	g.gofile.Outdent()
+	g.gofile.Printf("}\n\n")
+
+	for _, e := range []struct {
+		nm, meth string
+		sym      *symbol
+	}{{"key", "Key", ksym}, {"value", "Value", esym}} {
+		g.gofile.Printf("//export %s_iter_%s\n", slNm, e.nm)
+		g.gofile.Printf("func %s_iter_%s(it CGoHandle) %s {\n", slNm, e.nm, e.sym.cgoname)
+		g.gofile.Indent()
+		g.gofile.Printf("v := %s.%s().Interface().(%s)\n", iterOf, e.meth, e.sym.goname)
+		if e.sym.go2py != "" {
+			if e.sym.hasHandle() && !e.sym.isPtrOrIface() {
+				g.gofile.Printf("return %s(&v)%s\n", e.sym.go2py, e.sym.go2pyParenEx)
+			} else {
+				g.gofile.Printf("return %s(v)%s\n", e.sym.go2py, e.sym.go2pyParenEx)
+			}
+		} else {
+			g.gofile.Printf("return v\n")
+		}
+		g.gofile.Outdent()
+		g.gofile.Printf("}\n\n")
+	}
+
+	g.declFunc("mod.add_function", slNm+"_iter", PyHandle, capiParam{PyHandle, "handle"})
+	g.declFunc("mod.add_function", slNm+"_iter_next", "bool", capiParam{PyHandle, "it"})
+	g.declFunc("add_checked_function", slNm+"_iter_key", ksym.cpyname, capiParam{PyHandle, "it"})
+	g.declFunc("add_checked_function", slNm+"_iter_value", esym.cpyname, capiParam{PyHandle, "it"})
+}
+
+// genMapMapping generates the python methods of the MutableMapping ABC
+// that are not generated with the Go side of the map, so that it behaves
+// like a dict.
+func (g *pyGen) genMapMapping(slc *symbol) {
+	g.pywrap.Printf("def get(self, key, default=None):\n")
+	g.pywrap.Indent()
+	g.pywrap.Printf("if key in self:\n")
+	g.pywrap.Indent()
+	g.pywrap.Printf("return self[key]\n")
+	g.pywrap.Outdent()
+	g.pywrap.Printf("return default\n")
+	g.pywrap.Outdent()
+
+	g.pywrap.Printf("def pop(self, key, *args):\n")
+	g.pywrap.Indent()
+	g.pywrap.Printf("if key in self:\n")
+	g.pywrap.Indent()
+	g.pywrap.Printf("rv = self[key]\n")
+	g.pywrap.Printf("del self[key]\n")
+	g.pywrap.Printf("return rv\n")
+	g.pywrap.Outdent()
+	g.pywrap.Printf("if len(args) > 0:\n")
+	g.pywrap.Indent()
+	g.pywrap.Printf("return args[0]\n")
+	g.pywrap.Outdent()
+	g.pywrap.Printf("raise KeyError(key)\n")
+	g.pywrap.Outdent()
+
+	g.pywrap.Printf("def popitem(self):\n")
+	g.pywrap.Indent()
+	g.pywrap.Printf("for k in self.keys():\n")
+	g.pywrap.Indent()
+	g.pywrap.Printf("return (k, self.pop(k))\n")
+	g.pywrap.Outdent()
+	g.pywrap.Printf("raise KeyError('popitem(): %s is empty')\n", slc.id)
+	g.pywrap.Outdent()
+
+	g.pywrap.Printf("def setdefault(self, key, default=None):\n")
+	g.pywrap.Indent()
+	g.pywrap.Printf("if key not in self:\n")
+	g.pywrap.Indent()
+	g.pywrap.Printf("self[key] = default\n")
+	g.pywrap.Outdent()
+	g.pywrap.Printf("return self[key]\n")
+	g.pywrap.Outdent()
+
+	g.pywrap.Printf("def update(self, *args, **kwargs):\n")
+	g.pywrap.Indent()
+	g.pywrap.Printf("if len(args) > 1:\n")
+	g.pywrap.Indent()
+	g.pywrap.Printf("raise TypeError('update expected at most 1 argument, got %%d' %% len(args))\n")
+	g.pywrap.Outdent()
+	g.pywrap.Printf("if len(args) > 0:\n")
+	g.pywrap.Indent()
+	g.pywrap.Printf("if hasattr(args[0], 'keys'):\n")
+	g.pywrap.Indent()
+	g.pywrap.Printf("for k in list(args[0].keys()):\n")
+	g.pywrap.Indent()
+	g.pywrap.Printf("self[k] = args[0][k]\n")
+	g.pywrap.Outdent()
+	g.pywrap.Outdent()
+	g.pywrap.Printf("else:\n")
+	g.pywrap.Indent()
+	g.pywrap.Printf("for k, v in args[0]:\n")
+	g.pywrap.Indent()
+	g.pywrap.Printf("self[k] = v\n")
+	g.pywrap.Outdent()
+	g.pywrap.Outdent()
+	g.pywrap.Outdent()
+	g.pywrap.Printf("for k, v in kwargs.items():\n")
+	g.pywrap.Indent()
+	g.pywrap.Printf("self[k] = v\n")
+	g.pywrap.Outdent()
+	g.pywrap.Outdent()
+
+	g.pywrap.Printf("def clear(self):\n")
+	g.pywrap.Indent()
+	g.pywrap.Printf("for k in list(self.keys()):\n")
+	g.pywrap.Indent()
+	g.pywrap.Printf("del self[k]\n")
+	g.pywrap.Outdent()
+	g.pywrap.Outdent()
+
+	// equal to any mapping with the same items, and thus not hashable,
+	// as a dict
+	g.pywrap.Printf("def __eq__(self, other):\n")
+	g.pywrap.Indent()
+	g.pywrap.Printf("if not isinstance(other, _collections_abc.Mapping):\n")
+	g.pywrap.Indent()
+	g.pywrap.Printf("return NotImplemented\n")
+	g.pywrap.Outdent()
+	g.pywrap.Printf("return dict(self.items()) == dict(other.items())\n")
+	g.pywrap.Outdent()
+
+	g.pywrap.Printf("def __ne__(self, other):\n")
+	g.pywrap.Indent()
+	g.pywrap.Printf("eq = self.__eq__(other)\n")
+	g.pywrap.Printf("return eq if eq is NotImplemented else not eq\n")
+	g.pywrap.Outdent()
+
+	g.pywrap.Printf("__hash__ = None\n")
+}
+
 func (g *pyGen) genMapMethods(s *Map) {
 	for _, m := range s.meths {
 		g.genMethod(s.sym, m)
 	}
 }
+
+// genGoFromPy generates the conversion of the python value src, passed as the
+// C type of the given symbol, to the Go variable vnm.  The generated function
+// returns if the conversion of a value converted by value raises, so that
+// the Go side is not modified with a zero value.
+func (g *pyGen) genGoFromPy(vnm string, sym *symbol, src string) {
+	if sym.py2go != "" {
+		g.gofile.Printf("%s := %s(%s)%s\n", vnm, sym.py2go, src, sym.py2goParenEx)
+	} else {
+		g.gofile.Printf("%s := %s\n", vnm, src)
+	}
+	if sym.cgoname == "*C.PyObject" {
+		g.gofile.Printf("if C.PyErr_Occurred() != nil {\n")
+		g.gofile.Indent()
+		g.gofile.Printf("return\n")
+		g.gofile.Outdent()
+		g.gofile.Printf("}\n")
+	}
+}
+
+// genElemFromPy generates the python code that converts a native python
+// value assigned to an element of the given map or slice to the class of
+// the element type, if it can be constructed from one, e.g., a dict for the
+// inner map of a nested map, or a list for the inner slice of a [][]T,
+// which is converted recursively by its __init__.  Struct elements, which
+// the Go side dereferences, are checked to be of the struct class instead.
+func (g *pyGen) genElemFromPy(slc, esym *symbol, gocl string) {
+	switch {
+	case isPyConstructible(esym.gotyp):
+		g.pywrap.Printf("if not isinstance(value, %sGoClass):\n", gocl)
+		g.pywrap.Indent()
+		g.pywrap.Printf("value = %s(value)\n", esym.pyPkgId(g, slc.gopkg))
+		g.pywrap.Outdent()
+	case esym.isStruct():
+		clnm := esym.pyPkgId(g, slc.gopkg)
+		elts := "elements"
+		if slc.isMap() {
+			elts = "values"
+		}
+		g.pywrap.Printf("if not isinstance(value, %s):\n", clnm)
+		g.pywrap.Indent()
+		g.pywrap.Printf("raise TypeError('%s %s must be %s, not %%s' %% type(value).__name__)\n", slc.id, elts, clnm)
+		g.pywrap.Outdent()
+	}
+}
+
+// isPyConstructible returns true for the types whose python class can be
+// constructed from a native python value: maps from mappings, and slices and
+// arrays from sequences.
+func isPyConstructible(typ types.Type) bool {
+	switch typ.Underlying().(type) {
+	case *types.Map, *types.Slice, *types.Array:
+		return true
+	}
+	return false
+}
+
+// isValueMap returns true for maps with string keys and interface{} values,
+// e.g., map[string]interface{}, which are deep-converted from python dicts.
+func isValueMap(typ types.Type) bool {
+	mtyp, ok := typ.Underlying().(*types.Map)
+	if !ok {
+		return false
+	}
+	kt, ok := mtyp.Key().Underlying().(*types.Basic)
+	if !ok || kt.Kind() != types.String {
+		return false
+	}
+	it, ok := mtyp.Elem().(*types.Interface)
+	return ok && it.Empty()
+}
@@ -0,0 +1,74 @@
+// Copyright 2019 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bind
+
+const (
+	// goMetricsDefs are the exported functions for the call metrics,
+	// recorded by the gopym package when generating with -metrics
+	goMetricsDefs = `
+// GoPyMetrics returns the call metrics of the wrappers, as JSON
+//export GoPyMetrics
+func GoPyMetrics() *C.char {
+	return C.CString(gopym.JSON())
+}
+
+// GoPyResetMetrics clears the call metrics of the wrappers
+//export GoPyResetMetrics
+func GoPyResetMetrics() {
+	gopym.Reset()
+}
+
+// GoPyServeMetrics serves the call metrics in the Prometheus text format
+// at http://addr/metrics, returning an error message if it could not.
+//export GoPyServeMetrics
+func GoPyServeMetrics(addr *C.char) *C.char {
+	err := gopym.Serve(C.GoString(addr))
+	if err != nil {
+		return C.CString(err.Error())
+	}
+	return C.CString("")
+}
+`
+
+	// GoPkgMetricsDefs are the additional definitions in the go package with -metrics.
+	// 1 = name of package (outname)
+	GoPkgMetricsDefs = `
+import json
+
+def metrics():
+	"""metrics returns the call metrics of the wrapped Go functions and methods,
+	as a dict keyed by Go name, with the count, sum (total seconds) and cumulative
+	latency buckets keyed by their upper bound in seconds."""
+	return json.loads(_%[1]s.GoPyMetrics())
+
+def reset_metrics():
+	"""reset_metrics clears all the call metrics."""
+	_%[1]s.GoPyResetMetrics()
+
+def serve_metrics(addr):
+	"""serve_metrics serves the call metrics from the Go side in the Prometheus
+	text format at http://addr/metrics, e.g., serve_metrics(':9090')"""
+	err = _%[1]s.GoPyServeMetrics(addr)
+	if err:
+		raise RuntimeError(err)
+
+`
+)
+
+// declMetricsFuncs declares the functions of -metrics in build.py.
+func (g *pyGen) declMetricsFuncs() {
+	g.pybuild.Printf("\n")
+	g.declFunc("add_checked_string_function", "GoPyMetrics", "char*")
+	g.declFunc("mod.add_function", "GoPyResetMetrics", "")
+	g.declFunc("add_checked_string_function", "GoPyServeMetrics", "char*", capiParam{"char*", "addr"})
+}
+
+// metricsName returns the name under which calls to the function are recorded
+func metricsName(sym *symbol, fsym *Func) string {
+	if sym != nil {
+		return sym.goname + "." + fsym.GoName()
+	}
+	return fsym.GoFmt()
+}
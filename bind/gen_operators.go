@@ -0,0 +1,175 @@
+// Copyright 2019 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bind
+
+import (
+	"fmt"
+	"go/types"
+	"strings"
+)
+
+// pyOperators is the default mapping of conventional Go method names to the
+// python operator methods generated for them, which can be changed with the
+// -operators option.  Cmp, returning an int that is negative, zero or
+// positive, maps to the rich comparisons __lt__, __le__, __gt__ and __ge__.
+var pyOperators = map[string]string{
+	"Add":  "__add__",
+	"Sub":  "__sub__",
+	"Mul":  "__mul__",
+	"Div":  "__truediv__",
+	"Mod":  "__mod__",
+	"Neg":  "__neg__",
+	"Less": "__lt__",
+	"Cmp":  "__cmp__",
+}
+
+// pyOperatorArity is the number of operands, other than self, of the python
+// operator methods that Go methods can be mapped to.
+var pyOperatorArity = map[string]int{
+	"__neg__":      0,
+	"__pos__":      0,
+	"__abs__":      0,
+	"__invert__":   0,
+	"__add__":      1,
+	"__sub__":      1,
+	"__mul__":      1,
+	"__matmul__":   1,
+	"__truediv__":  1,
+	"__floordiv__": 1,
+	"__mod__":      1,
+	"__pow__":      1,
+	"__lshift__":   1,
+	"__rshift__":   1,
+	"__and__":      1,
+	"__or__":       1,
+	"__xor__":      1,
+	"__lt__":       1,
+	"__le__":       1,
+	"__gt__":       1,
+	"__ge__":       1,
+	"__eq__":       1,
+	"__ne__":       1,
+	"__cmp__":      1,
+}
+
+// pyCmpOperators are the rich comparisons generated from a Cmp method,
+// with the python comparison of its result to 0.
+var pyCmpOperators = []struct {
+	op  string
+	cmp string
+}{
+	{"__lt__", "<"},
+	{"__le__", "<="},
+	{"__gt__", ">"},
+	{"__ge__", ">="},
+}
+
+// parseOperators returns the mapping of Go method names to python operator
+// methods, from the defaults and the given -operators option, see
+// BindCfg.Operators.
+func parseOperators(opts string) (map[string]string, error) {
+	ops := make(map[string]string, len(pyOperators))
+	for k, v := range pyOperators {
+		ops[k] = v
+	}
+	if opts == "" {
+		return ops, nil
+	}
+	for _, pair := range strings.Split(opts, ",") {
+		pair = strings.TrimSpace(pair)
+		eq := strings.Index(pair, "=")
+		if eq < 0 {
+			return nil, fmt.Errorf("gopy: invalid -operators pair %q, expecting Method=__op__", pair)
+		}
+		meth, op := strings.TrimSpace(pair[:eq]), strings.TrimSpace(pair[eq+1:])
+		if op == "" {
+			delete(ops, meth)
+			continue
+		}
+		if _, ok := pyOperatorArity[op]; !ok {
+			return nil, fmt.Errorf("gopy: unknown python operator method %q for %s in -operators", op, meth)
+		}
+		ops[meth] = op
+	}
+	return ops, nil
+}
+
+// isOperator returns true if the signature of the method fits the python
+// operator method: it takes as many arguments as the operator has operands,
+// other than self, and returns a single value, possibly along with an error,
+// which is a bool for comparisons and an integer for __cmp__.
+func (g *pyGen) isOperator(m *Func, op string) bool {
+	sig := m.sig
+	if sig == nil || m.isVariadic {
+		return false
+	}
+	args := sig.Params()
+	if len(args) != pyOperatorArity[op] {
+		return false
+	}
+	for _, arg := range args {
+		if g.syms.symtype(arg.GoType()) == nil {
+			return false
+		}
+	}
+	res := sig.Results()
+	nres := len(res)
+	if m.err {
+		nres--
+	}
+	if nres != 1 || !g.isFuncValueType(res[0].sym) {
+		return false
+	}
+	rt, _ := res[0].GoType().Underlying().(*types.Basic)
+	switch op {
+	case "__lt__", "__le__", "__gt__", "__ge__", "__eq__", "__ne__":
+		return rt != nil && rt.Info()&types.IsBoolean != 0
+	case "__cmp__":
+		return rt != nil && rt.Info()&types.IsInteger != 0
+	}
+	return true
+}
+
+// genOperators generates the python operator methods of the class of a type
+// with methods named as in the -operators mapping, e.g., __add__ for Add,
+// calling the python methods wrapping them.  Methods whose signature does not
+// fit the operator are skipped, as are operators already generated for
+// a previous method.
+func (g *pyGen) genOperators(meths []*Func) {
+	done := make(map[string]bool)
+	def := func(op, args, body string) {
+		if done[op] {
+			return
+		}
+		done[op] = true
+		g.pywrap.Printf("def %s(%s):\n", op, args)
+		g.pywrap.Indent()
+		g.pywrap.Printf("return %s\n", body)
+		g.pywrap.Outdent()
+	}
+	for _, m := range meths {
+		op := g.operators[m.GoName()]
+		if op == "" || !g.isOperator(m, op) {
+			continue
+		}
+		pnm, _, err := g.pyFuncName(m)
+		if err != nil {
+			continue
+		}
+		switch {
+		case op == "__cmp__":
+			for _, c := range pyCmpOperators {
+				def(c.op, "self, other", fmt.Sprintf("self.%s(other) %s 0", pnm, c.cmp))
+			}
+		case pyOperatorArity[op] == 0:
+			def(op, "self", fmt.Sprintf("self.%s()", pnm))
+		default:
+			def(op, "self, other", fmt.Sprintf("self.%s(other)", pnm))
+			if op == "__truediv__" && g.lang == 2 {
+				def("__div__", "self, other", fmt.Sprintf("self.%s(other)", pnm))
+			}
+		}
+	}
+}
@@ -0,0 +1,111 @@
+// Copyright 2019 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bind
+
+const (
+	// goPanicPreambleC are the C helpers raising the panics recovered from
+	// the Go calls as go.GoPanic exceptions.
+	goPanicPreambleC = `
+static inline PyObject* gopy_panic_new(PyObject* cls, const char* msg, const char* trace) { // new cls(msg, trace)
+	return PyObject_CallFunction(cls, "ss", msg, trace);
+}
+`
+
+	// goPanicDefs are the definitions recovering the panics of the Go calls
+	// of the functions and methods, which would otherwise abort the python
+	// interpreter, see genRecover.
+	goPanicDefs = `
+// gopyPanic is a panic recovered from a Go call, with the stack trace of the
+// goroutine that panicked
+type gopyPanic struct {
+	value interface{}
+	trace []byte
+}
+
+// catch recovers the panic of the goroutine, if any, into p -- it must be
+// deferred by the goroutine
+func (p *gopyPanic) catch() {
+	if r := recover(); r != nil {
+		p.value, p.trace = r, debug.Stack()
+	}
+}
+
+// gopyPanicToPy sets the current python error to a go.GoPanic for the
+// recovered panic r, with the given stack trace
+func gopyPanicToPy(r interface{}, trace []byte) {
+	st := gopyState()
+	cls, has := st.lookup(st.errClasses, "go.GoPanic")
+	if !has {
+		cls = C.PyExc_RuntimeError
+	}
+	msg := C.CString(fmt.Sprint(r))
+	defer C.free(unsafe.Pointer(msg))
+	tr := C.CString(string(trace))
+	defer C.free(unsafe.Pointer(tr))
+	if exc := C.gopy_panic_new(cls, msg, tr); exc != nil {
+		C.gopy_err_set(exc)
+	}
+}
+
+// gopyRecover recovers the panic of the Go call of a function wrapper, if
+// any, and raises it as a go.GoPanic, once the GIL is restored from *save,
+// unless nil, if it was released during the call -- it must be deferred by
+// the wrapper, which returns zero values.
+func gopyRecover(save **C.PyThreadState) {
+	r := recover()
+	if r == nil {
+		return
+	}
+	if save != nil && *save != nil {
+		C.PyEval_RestoreThread(*save)
+		*save = nil
+	}
+	gopyPanicToPy(r, debug.Stack())
+}
+
+// gopyUnlock returns the func that calls the unlocks, in reverse order, on
+// its first call only, so that it can also be deferred, to release the locks
+// if the Go call panics, before gopyRecover restores the GIL.
+func gopyUnlock(unlocks ...func()) func() {
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			for i := len(unlocks) - 1; i >= 0; i-- {
+				unlocks[i]()
+			}
+		})
+	}
+}
+
+// gopyRecoverGo recovers the panic of the Go call of a function run in a
+// goroutine, with goRun=True, if any, which cannot be raised to its caller,
+// and reports it as an unraisable go.GoPanic in the python interpreter
+// interp, as for the exceptions of python threads -- it must be deferred by
+// the goroutine.
+func gopyRecoverGo(interp *C.PyInterpreterState) {
+	r := recover()
+	if r == nil || !gopyInterpAlive(interp) {
+		return
+	}
+	trace := debug.Stack()
+	defer gopyEnter(interp)()
+	gopyPanicToPy(r, trace)
+	C.PyErr_WriteUnraisable(nil)
+}
+`
+)
+
+// genRecover generates the deferred recover of the panics of the Go call of
+// a function wrapper, raised as go.GoPanic exceptions: if save, the GIL is
+// released during the call by genGoCall, and restored from _save by
+// gopyRecover.  The calls run by gopyCall are recovered by their goroutine.
+func (g *pyGen) genRecover(save bool) {
+	if !save {
+		g.gofile.Printf("defer gopyRecover(nil)\n")
+		return
+	}
+	g.gofile.Printf("var _save *C.PyThreadState\n")
+	g.gofile.Printf("defer gopyRecover(&_save)\n")
+}
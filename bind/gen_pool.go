@@ -0,0 +1,174 @@
+// Copyright 2019 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bind
+
+const (
+	// goPoolDefs are the definitions of the pools of goroutines of go.Pool,
+	// which run the python calls submitted to them, e.g., of bound Go
+	// functions, which release the GIL during their Go calls.
+	goPoolDefs = `
+// gopyPoolJob is a python callable submitted to a pool, and its interpreter
+type gopyPoolJob struct {
+	fn     *C.PyObject
+	interp *C.PyInterpreterState
+}
+
+// gopyPool is a pool of n goroutines running the jobs submitted to it, in
+// the order of submission, until it is closed
+type gopyPool struct {
+	mu     sync.Mutex
+	cond   *sync.Cond
+	jobs   []gopyPoolJob
+	closed bool
+	wg     sync.WaitGroup
+}
+
+// run runs the jobs of the pool, until it is closed and they are all done
+func (p *gopyPool) run() {
+	defer p.wg.Done()
+	for {
+		p.mu.Lock()
+		for len(p.jobs) == 0 && !p.closed {
+			p.cond.Wait()
+		}
+		if len(p.jobs) == 0 {
+			p.mu.Unlock()
+			return
+		}
+		job := p.jobs[0]
+		p.jobs[0] = gopyPoolJob{}
+		p.jobs = p.jobs[1:]
+		p.mu.Unlock()
+		job.call()
+	}
+}
+
+// call calls the python callable of the job, with the GIL of its
+// interpreter, reporting the exception that it raises, if any, as
+// unraisable, as there is no caller to raise it to
+func (job gopyPoolJob) call() {
+	if !gopyInterpAlive(job.interp) {
+		return
+	}
+	defer gopyEnter(job.interp)()
+	ret := C.PyObject_CallObject(job.fn, nil)
+	if ret == nil {
+		C.PyErr_WriteUnraisable(job.fn)
+	}
+	C.gopy_decref(ret)
+	C.gopy_decref(job.fn)
+}
+
+// GoPyPoolNew returns the handle of a new pool of n goroutines
+//export GoPyPoolNew
+func GoPyPoolNew(n int) CGoHandle {
+	p := &gopyPool{}
+	p.cond = sync.NewCond(&p.mu)
+	p.wg.Add(n)
+	for i := 0; i < n; i++ {
+		go p.run()
+	}
+	return CGoHandle(gopyh.Register("*gopyPool", p))
+}
+
+// GoPyPoolSubmit submits the python callable fn, called without args, to
+// the pool, returning false if it is closed
+//export GoPyPoolSubmit
+func GoPyPoolSubmit(handle CGoHandle, fn *C.PyObject) C.char {
+	p := gopyh.VarFromHandle((gopyh.CGoHandle)(handle), "*gopyPool").(*gopyPool)
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		return boolGoToPy(false)
+	}
+	C.gopy_incref(fn)
+	p.jobs = append(p.jobs, gopyPoolJob{fn: fn, interp: C.gopy_interp()})
+	p.cond.Signal()
+	return boolGoToPy(true)
+}
+
+// GoPyPoolClose closes the pool, whose goroutines exit once the jobs that
+// were submitted are done, waiting for them, with the GIL released, if wait
+//export GoPyPoolClose
+func GoPyPoolClose(handle CGoHandle, wait C.char) {
+	p := gopyh.VarFromHandle((gopyh.CGoHandle)(handle), "*gopyPool").(*gopyPool)
+	p.mu.Lock()
+	p.closed = true
+	p.cond.Broadcast()
+	p.mu.Unlock()
+	if boolPyToGo(wait) {
+		_save := C.PyEval_SaveThread()
+		p.wg.Wait()
+		C.PyEval_RestoreThread(_save)
+	}
+}
+`
+
+	// GoPkgPoolDefs are the additional definitions in the go package with
+	// python 3, for the pools of goroutines that run the calls of the bound
+	// Go functions in parallel.
+	// 1 = name of package (outname)
+	GoPkgPoolDefs = `
+import concurrent.futures as _futures
+import threading as _threading
+
+class Pool(_futures.Executor):
+	"""Pool(n) is a bounded pool of n goroutines, which run the calls submitted to it, e.g., of the bound Go
+	functions and methods, in parallel, as the GIL is released during their Go calls, without a python
+	thread per call.  It is a concurrent.futures.Executor, whose submit returns a Future of the result
+	of a call, e.g., pool.submit(pkg.Func, arg), which can be used with asyncio run_in_executor."""
+	def __init__(self, n):
+		if n < 1:
+			raise ValueError('Pool needs at least 1 goroutine, not %%d' %% n)
+		self.handle = _%[1]s.GoPyPoolNew(n)
+		_%[1]s.IncRef(self.handle)
+		self._pending = set()
+		self._lock = _threading.Lock()
+	def submit(self, fn, *args, **kwargs):
+		"""submit submits the call fn(*args, **kwargs) to the pool, and returns a Future of its result"""
+		f = _futures.Future()
+		def run():
+			with self._lock:
+				self._pending.discard(f)
+			if not f.set_running_or_notify_cancel():
+				return
+			try:
+				f.set_result(fn(*args, **kwargs))
+			except BaseException as e:
+				f.set_exception(e)
+		with self._lock:
+			if self.handle == 0 or not _%[1]s.GoPyPoolSubmit(self.handle, run):
+				raise RuntimeError('cannot schedule new futures after shutdown')
+			self._pending.add(f)
+		return f
+	def shutdown(self, wait=True, cancel_futures=False):
+		"""shutdown stops the pool once the submitted calls are done, waiting for them if wait, and
+		cancels those that are not running yet if cancel_futures"""
+		with self._lock:
+			handle, self.handle = self.handle, 0
+			pending, self._pending = self._pending, set()
+		if handle == 0:
+			return
+		if cancel_futures:
+			for f in pending:
+				f.cancel()
+		_%[1]s.GoPyPoolClose(handle, wait)
+		_%[1]s.DecRef(handle)
+	def __del__(self):
+		try:
+			self.shutdown(wait=False)
+		except Exception:
+			pass
+
+`
+)
+
+// declPoolFuncs declares the functions of the pools in build.py.
+func (g *pyGen) declPoolFuncs() {
+	g.pybuild.Printf("\n")
+	g.declFunc("mod.add_function", "GoPyPoolNew", "int64_t", capiParam{"int", "n"})
+	g.declFunc("mod.add_function", "GoPyPoolSubmit", "bool", capiParam{"int64_t", "handle"}, capiParam{"PyObject*", "fn"})
+	g.declFunc("mod.add_function", "GoPyPoolClose", "", capiParam{"int64_t", "handle"}, capiParam{"bool", "wait"})
+}
@@ -56,6 +56,11 @@ class %[2]s(%[5]sGoClass):
 	}
 	if !extTypes || pyWrapOnly {
 		g.pywrap.Outdent()
+		abc := "Sequence"
+		if isslc {
+			abc = "MutableSequence"
+		}
+		g.pywrap.Printf("_collections_abc.%s.register(%s)\n", abc, pysnm)
 	}
 }
 
@@ -65,9 +70,9 @@ func (g *pyGen) genSliceInit(slc *symbol, extTypes, pyWrapOnly bool, slob *Slice
 	qNm := g.cfg.Name + "." + slNm // this is only for referring to the _ .go package!
 	var esym *symbol
 	if typ, ok := slc.GoType().Underlying().(*types.Slice); ok {
-		esym = current.symtype(typ.Elem())
+		esym = g.syms.symtype(typ.Elem())
 	} else if typ, ok := slc.GoType().Underlying().(*types.Array); ok {
-		esym = current.symtype(typ.Elem())
+		esym = g.syms.symtype(typ.Elem())
 	}
 
 	gocl := "go."
@@ -88,7 +93,10 @@ handle=A Go-side object is always initialized with an explicit handle=arg
 otherwise parameter is a python list that we copy from
 """
 `)
-		g.pywrap.Printf("self.index = 0\n")
+		g.pywrap.Printf("self._iter_idx = 0\n")
+		if slc.isSlice() && bufferFormat(esym) != "" && g.lang != 2 {
+			g.pywrap.Printf("self._pins = {}\n")
+		}
 		g.pywrap.Printf("if len(kwargs) == 1 and 'handle' in kwargs:\n")
 		g.pywrap.Indent()
 		g.pywrap.Printf("self.handle = kwargs['handle']\n")
@@ -99,7 +107,19 @@ otherwise parameter is a python list that we copy from
 		g.pywrap.Printf("self.handle = args[0].handle\n")
 		g.pywrap.Printf("_%s.IncRef(self.handle)\n", g.pypkgname)
 		g.pywrap.Outdent()
-		if slc.isSlice() {
+		if slc.isSlice() && isValueSlice(slc.gotyp) {
+			// deep-converted from a python sequence, e.g., of JSON-like values
+			g.pywrap.Printf("else:\n")
+			g.pywrap.Indent()
+			g.pywrap.Printf("self.handle = 0 # for __del__ if the conversion fails\n")
+			g.pywrap.Printf("if len(args) > 0 and not isinstance(args[0], _collections_abc.Iterable):\n")
+			g.pywrap.Indent()
+			g.pywrap.Printf("raise TypeError('%s.__init__ takes a sequence as argument')\n", slNm)
+			g.pywrap.Outdent()
+			g.pywrap.Printf("self.handle = _%s_from_py(list(args[0]) if len(args) > 0 else [])\n", qNm)
+			g.pywrap.Printf("_%s.IncRef(self.handle)\n", g.pypkgname)
+			g.pywrap.Outdent()
+		} else if slc.isSlice() {
 			g.pywrap.Printf("else:\n")
 			g.pywrap.Indent()
 			g.pywrap.Printf("self.handle = _%s_CTor()\n", qNm)
@@ -110,29 +130,35 @@ otherwise parameter is a python list that we copy from
 			g.pywrap.Indent()
 			g.pywrap.Printf("raise TypeError('%s.__init__ takes a sequence as argument')\n", slNm)
 			g.pywrap.Outdent()
-			g.pywrap.Printf("for elt in args[0]:\n")
-			g.pywrap.Indent()
-			g.pywrap.Printf("self.append(elt)\n")
-			g.pywrap.Outdent()
+			if g.isCythonBuffer(slc, esym) {
+				g.pywrap.Printf("_%s_extend(self.handle, args[0])\n", qNm)
+			} else {
+				g.pywrap.Printf("for elt in args[0]:\n")
+				g.pywrap.Indent()
+				g.pywrap.Printf("self.append(elt)\n")
+				g.pywrap.Outdent()
+			}
 			g.pywrap.Outdent()
 			g.pywrap.Outdent()
+		} else {
+			g.genArrayInit(slc, esym)
 		}
 		g.pywrap.Outdent()
 
-		g.pywrap.Printf("def __del__(self):\n")
-		g.pywrap.Indent()
-		g.pywrap.Printf("_%s.DecRef(self.handle)\n", g.pypkgname)
-		g.pywrap.Outdent()
+		g.genDel()
+		if slob != nil && slob.prots&ProtoCloser != 0 {
+			g.genCloser()
+		}
+		var meths []*Func
+		if slob != nil {
+			meths = slob.meths
+			g.genOperators(meths)
+		}
+		g.genBool(meths, true)
+		g.genCopy("_"+qNm+"_deepcopy", slc.isSlice() && bufferFormat(esym) != "" && g.lang != 2)
 
 		if slob != nil && slob.prots&ProtoStringer != 0 {
-			for _, m := range slob.meths {
-				if isStringer(m.obj) {
-					g.pywrap.Printf("def __str__(self):\n")
-					g.pywrap.Indent()
-					g.pywrap.Printf("return self.String()\n")
-					g.pywrap.Outdent()
-				}
-			}
+			g.genStringer(slob.GoName())
 		} else {
 			g.pywrap.Printf("def __str__(self):\n")
 			g.pywrap.Indent()
@@ -143,12 +169,12 @@ otherwise parameter is a python list that we copy from
 			g.pywrap.Outdent()
 			g.pywrap.Println("return s")
 			g.pywrap.Outdent()
-		}
 
-		g.pywrap.Printf("def __repr__(self):\n")
-		g.pywrap.Indent()
-		g.pywrap.Printf("return '%s.%s([' + ', '.join(map(str, self)) + '])'\n", pkgname, slNm)
-		g.pywrap.Outdent()
+			g.pywrap.Printf("def __repr__(self):\n")
+			g.pywrap.Indent()
+			g.pywrap.Printf("return '%s.%s([' + ', '.join(map(str, self)) + '])'\n", pkgname, slNm)
+			g.pywrap.Outdent()
+		}
 
 		g.pywrap.Printf("def __len__(self):\n")
 		g.pywrap.Indent()
@@ -160,22 +186,17 @@ otherwise parameter is a python list that we copy from
 		g.pywrap.Printf("if isinstance(key, slice):\n")
 		g.pywrap.Indent()
 		if slc.isSlice() {
-			g.pywrap.Printf("if key.step == None or key.step == 1:\n")
-			g.pywrap.Indent()
-			g.pywrap.Printf("st = key.start\n")
-			g.pywrap.Printf("ed = key.stop\n")
-			g.pywrap.Printf("if st == None:\n")
-			g.pywrap.Indent()
-			g.pywrap.Printf("st = 0\n")
-			g.pywrap.Outdent()
-			g.pywrap.Printf("if ed == None:\n")
+			// a contiguous subslice shares the elements, as in Go, while
+			// an extended one is a copy
+			g.pywrap.Printf("st, ed, step = key.indices(len(self))\n")
+			g.pywrap.Printf("if step == 1:\n")
 			g.pywrap.Indent()
-			g.pywrap.Printf("ed = _%s_len(self.handle)\n", qNm)
-			g.pywrap.Outdent()
-			g.pywrap.Printf("return %s(handle=_%s_subslice(self.handle, st, ed))\n", pysnm, qNm)
+			g.pywrap.Printf("return %s(handle=_%s_subslice(self.handle, st, max(st, ed)))\n", pysnm, qNm)
 			g.pywrap.Outdent()
+			g.pywrap.Printf("return %s(handle=_%s_slice(self.handle, st, ed, step))\n", pysnm, qNm)
+		} else {
+			g.pywrap.Printf("return [self[ii] for ii in range(*key.indices(len(self)))]\n")
 		}
-		g.pywrap.Printf("return [self[ii] for ii in range(*key.indices(len(self)))]\n")
 		g.pywrap.Outdent()
 		g.pywrap.Printf("elif isinstance(key, int):\n")
 		g.pywrap.Indent()
@@ -188,7 +209,7 @@ otherwise parameter is a python list that we copy from
 		g.pywrap.Printf("raise IndexError('slice index out of range')\n")
 		g.pywrap.Outdent()
 		if esym.hasHandle() {
-			g.pywrap.Printf("return %s(handle=_%s_elem(self.handle, key))\n", esym.pyPkgId(slc.gopkg), qNm)
+			g.pywrap.Printf("return %s(handle=_%s_elem(self.handle, key))\n", esym.pyPkgId(g, slc.gopkg), qNm)
 		} else {
 			g.pywrap.Printf("return _%s_elem(self.handle, key)\n", qNm)
 		}
@@ -201,12 +222,33 @@ otherwise parameter is a python list that we copy from
 
 		g.pywrap.Printf("def __setitem__(self, idx, value):\n")
 		g.pywrap.Indent()
+		if slc.isSlice() {
+			g.pywrap.Printf("if isinstance(idx, slice):\n")
+			g.pywrap.Indent()
+			g.pywrap.Printf("st, ed, step = idx.indices(len(self))\n")
+			g.pywrap.Printf("if not isinstance(value, %s):\n", pysnm)
+			g.pywrap.Indent()
+			g.pywrap.Printf("value = %s(value)\n", pysnm)
+			g.pywrap.Outdent()
+			g.pywrap.Printf("if step == 1:\n")
+			g.pywrap.Indent()
+			g.pywrap.Printf("ed = max(st, ed)\n")
+			g.pywrap.Outdent()
+			g.pywrap.Printf("elif len(range(st, ed, step)) != len(value):\n")
+			g.pywrap.Indent()
+			g.pywrap.Printf("raise ValueError('attempt to assign sequence of size %%d to extended slice of size %%d' %% (len(value), len(range(st, ed, step))))\n")
+			g.pywrap.Outdent()
+			g.pywrap.Printf("_%s_setslice(self.handle, st, ed, step, value.handle)\n", qNm)
+			g.pywrap.Printf("return\n")
+			g.pywrap.Outdent()
+		}
 		g.pywrap.Printf("if idx < 0:\n")
 		g.pywrap.Indent()
 		g.pywrap.Printf("idx += len(self)\n")
 		g.pywrap.Outdent()
-		g.pywrap.Printf("if idx < len(self):\n")
+		g.pywrap.Printf("if 0 <= idx < len(self):\n")
 		g.pywrap.Indent()
+		g.genElemFromPy(slc, esym, gocl)
 		if esym.hasHandle() {
 			g.pywrap.Printf("_%s_set(self.handle, idx, value.handle)\n", qNm)
 		} else {
@@ -224,17 +266,21 @@ otherwise parameter is a python list that we copy from
 			g.pywrap.Indent()
 			g.pywrap.Printf("raise TypeError('%s.__iadd__ takes a sequence as argument')\n", slNm)
 			g.pywrap.Outdent()
-			g.pywrap.Printf("for elt in value:\n")
-			g.pywrap.Indent()
-			g.pywrap.Printf("self.append(elt)\n")
-			g.pywrap.Outdent()
+			if g.isCythonBuffer(slc, esym) {
+				g.pywrap.Printf("_%s_extend(self.handle, value)\n", qNm)
+			} else {
+				g.pywrap.Printf("for elt in value:\n")
+				g.pywrap.Indent()
+				g.pywrap.Printf("self.append(elt)\n")
+				g.pywrap.Outdent()
+			}
 			g.pywrap.Printf("return self\n")
 			g.pywrap.Outdent()
 		}
 
 		g.pywrap.Printf("def __iter__(self):\n")
 		g.pywrap.Indent()
-		g.pywrap.Println("self.index = 0")
+		g.pywrap.Println("self._iter_idx = 0")
 		g.pywrap.Println("return self")
 		g.pywrap.Outdent()
 
@@ -244,18 +290,29 @@ otherwise parameter is a python list that we copy from
 			g.pywrap.Printf("def __next__(self):\n")
 		}
 		g.pywrap.Indent()
-		g.pywrap.Printf("if self.index < len(self):\n")
+		g.pywrap.Printf("if self._iter_idx < len(self):\n")
 		g.pywrap.Indent()
-		g.pywrap.Printf("rv = _%s_elem(self.handle, self.index)\n", qNm)
-		g.pywrap.Println("self.index = self.index + 1")
+		if esym.hasHandle() {
+			g.pywrap.Printf("rv = %s(handle=_%s_elem(self.handle, self._iter_idx))\n", esym.pyPkgId(g, slc.gopkg), qNm)
+		} else {
+			g.pywrap.Printf("rv = _%s_elem(self.handle, self._iter_idx)\n", qNm)
+		}
+		g.pywrap.Println("self._iter_idx = self._iter_idx + 1")
 		g.pywrap.Println("return rv")
 		g.pywrap.Outdent()
 		g.pywrap.Printf("raise StopIteration\n")
 		g.pywrap.Outdent()
 
+		if esym.hasHandle() {
+			g.genSliceToList(slc, esym)
+		}
+		g.genSliceCython(slc, esym)
+		g.genSliceSequence(slc, esym, gocl)
+
 		if slc.isSlice() {
 			g.pywrap.Printf("def append(self, value):\n")
 			g.pywrap.Indent()
+			g.genElemFromPy(slc, esym, gocl)
 			if esym.hasHandle() {
 				g.pywrap.Printf("_%s_append(self.handle, value.handle)\n", qNm)
 			} else {
@@ -272,6 +329,9 @@ otherwise parameter is a python list that we copy from
 			g.pywrap.Printf("self[i] = src[i]\n")
 			g.pywrap.Outdent()
 			g.pywrap.Outdent()
+			g.genSliceSort(slc, esym)
+			g.genSliceBuffer(slc, esym)
+			g.genSliceNumpy(slc, esym)
 		}
 	}
 
@@ -286,23 +346,31 @@ otherwise parameter is a python list that we copy from
 		g.gofile.Outdent()
 		g.gofile.Printf("}\n\n")
 
-		g.pybuild.Printf("mod.add_function('%s', retval('%s'), [])\n", ctNm, PyHandle)
+		g.declFunc("mod.add_function", ctNm, PyHandle)
+		g.genDeepCopyGo(slNm, slc.goname)
+
+		if isValueSlice(slc.gotyp) {
+			g.genValueFromPyGo(slc, "[]interface{}")
+		}
 
 		g.gofile.Printf("//export %s_len\n", slNm)
 		g.gofile.Printf("func %s_len(handle CGoHandle) int {\n", slNm)
 		g.gofile.Indent()
+		g.genValueLock("ptrFromHandle_" + slNm + "(handle)")
 		g.gofile.Printf("return len(deptrFromHandle_%s(handle))\n", slNm)
 		g.gofile.Outdent()
 		g.gofile.Printf("}\n\n")
 
-		g.pybuild.Printf("mod.add_function('%s_len', retval('int'), [param('%s', 'handle')])\n", slNm, PyHandle)
+		g.declFunc("mod.add_function", slNm+"_len", "int", capiParam{PyHandle, "handle"})
 
 		g.gofile.Printf("//export %s_elem\n", slNm)
 		g.gofile.Printf("func %s_elem(handle CGoHandle, _idx int) %s {\n", slNm, esym.cgoname)
 		g.gofile.Indent()
+		g.genValueLock("ptrFromHandle_" + slNm + "(handle)")
 		g.gofile.Printf("s := deptrFromHandle_%s(handle)\n", slNm)
 		if esym.go2py != "" {
-			if !esym.isPointer() && esym.isStruct() {
+			if esym.hasHandle() && !esym.isPtrOrIface() {
+				// e.g., the inner slice of a [][]T, so that changes to it are shared
 				g.gofile.Printf("return %s(&(s[_idx]))%s\n", esym.go2py, esym.go2pyParenEx)
 			} else {
 				g.gofile.Printf("return %s(s[_idx])%s\n", esym.go2py, esym.go2pyParenEx)
@@ -313,53 +381,544 @@ otherwise parameter is a python list that we copy from
 		g.gofile.Outdent()
 		g.gofile.Printf("}\n\n")
 
-		g.pybuild.Printf("mod.add_function('%s_elem', retval('%s'), [param('%s', 'handle'), param('int', 'idx')])\n", slNm, esym.cpyname, PyHandle)
+		g.declFunc("mod.add_function", slNm+"_elem", esym.cpyname, capiParam{PyHandle, "handle"}, capiParam{"int", "idx"})
+
+		if esym.hasHandle() {
+			g.genSliceToListGo(slc, esym)
+		}
+		if isGoComparable(esym) {
+			g.genSliceContainsGo(slc, esym)
+		}
 
 		if slc.isSlice() {
 			g.gofile.Printf("//export %s_subslice\n", slNm)
 			g.gofile.Printf("func %s_subslice(handle CGoHandle, _st, _ed int) CGoHandle {\n", slNm)
 			g.gofile.Indent()
+			g.genValueLock("ptrFromHandle_" + slNm + "(handle)")
 			g.gofile.Printf("s := deptrFromHandle_%s(handle)\n", slNm)
 			g.gofile.Printf("ss := s[_st:_ed]\n")
 			g.gofile.Printf("return CGoHandle(handleFromPtr_%s(&ss))\n", slNm)
 			g.gofile.Outdent()
 			g.gofile.Printf("}\n\n")
 
-			g.pybuild.Printf("mod.add_function('%s_subslice', retval('%s'), [param('%s', 'handle'), param('int', 'st'), param('int', 'ed')])\n", slNm, PyHandle, PyHandle)
+			g.declFunc("mod.add_function", slNm+"_subslice", PyHandle, capiParam{PyHandle, "handle"}, capiParam{"int", "st"}, capiParam{"int", "ed"})
+
+			g.genSliceStridedGo(slc)
 		}
 
 		g.gofile.Printf("//export %s_set\n", slNm)
 		g.gofile.Printf("func %s_set(handle CGoHandle, _idx int, _vl %s) {\n", slNm, esym.cgoname)
 		g.gofile.Indent()
-		g.gofile.Printf("s := deptrFromHandle_%s(handle)\n", slNm)
-		if esym.py2go != "" {
-			g.gofile.Printf("s[_idx] = %s(_vl)%s\n", esym.py2go, esym.py2goParenEx)
+		g.genValueLock("ptrFromHandle_" + slNm + "(handle)")
+		if slc.isSlice() {
+			g.gofile.Printf("s := deptrFromHandle_%s(handle)\n", slNm)
 		} else {
-			g.gofile.Printf("s[_idx] = _vl\n")
+			// the array is set through its pointer, not in a copy
+			g.gofile.Printf("s := ptrFromHandle_%s(handle)\n", slNm)
 		}
+		g.genGoFromPy("v", esym, "_vl")
+		g.gofile.Printf("s[_idx] = v\n")
 		g.gofile.Outdent()
 		g.gofile.Printf("}\n\n")
 
-		g.pybuild.Printf("mod.add_function('%s_set', None, [param('%s', 'handle'), param('int', 'idx'), param('%v', 'value')])\n", slNm, PyHandle, esym.cpyname)
+		g.declFunc("add_checked_function", slNm+"_set", "", capiParam{PyHandle, "handle"}, capiParam{"int", "idx"}, capiParam{esym.cpyname, "value"})
 
 		if slc.isSlice() {
 			g.gofile.Printf("//export %s_append\n", slNm)
 			g.gofile.Printf("func %s_append(handle CGoHandle, _vl %s) {\n", slNm, esym.cgoname)
 			g.gofile.Indent()
+			g.genValueLock("ptrFromHandle_" + slNm + "(handle)")
 			g.gofile.Printf("s := ptrFromHandle_%s(handle)\n", slNm)
-			if esym.py2go != "" {
-				g.gofile.Printf("*s = append(*s, %s(_vl)%s)\n", esym.py2go, esym.py2goParenEx)
-			} else {
-				g.gofile.Printf("*s = append(*s, _vl)\n")
-			}
+			g.genGoFromPy("v", esym, "_vl")
+			g.gofile.Printf("*s = append(*s, v)\n")
 			g.gofile.Outdent()
 			g.gofile.Printf("}\n\n")
 
-			g.pybuild.Printf("mod.add_function('%s_append', None, [param('%s', 'handle'), param('%s', 'value')])\n", slNm, PyHandle, esym.cpyname)
+			g.declFunc("add_checked_function", slNm+"_append", "", capiParam{PyHandle, "handle"}, capiParam{esym.cpyname, "value"})
+
+			g.genSliceSequenceGo(slc, esym)
+			g.genSliceSortGo(slc, esym)
+			g.genSliceBufferGo(slc, esym)
+			g.genSliceNumpyGo(slc, esym)
+			g.genSliceCythonGo(slc, esym)
 		}
 	}
 }
 
+// genSliceSequence generates the python methods of the Sequence ABC, and
+// of the MutableSequence one for slices, so that they behave like lists.
+func (g *pyGen) genSliceSequence(slc *symbol, esym *symbol, gocl string) {
+	qNm := g.cfg.Name + "." + slc.id
+
+	g.pywrap.Printf("def index(self, value, start=0, stop=None):\n")
+	g.pywrap.Indent()
+	g.pywrap.Printf("for ii in range(*slice(start, stop).indices(len(self))):\n")
+	g.pywrap.Indent()
+	g.pywrap.Printf("if self[ii] == value:\n")
+	g.pywrap.Indent()
+	g.pywrap.Printf("return ii\n")
+	g.pywrap.Outdent()
+	g.pywrap.Outdent()
+	g.pywrap.Printf("raise ValueError('%%r is not in %s' %% (value,))\n", slc.id)
+	g.pywrap.Outdent()
+
+	g.pywrap.Printf("def count(self, value):\n")
+	g.pywrap.Indent()
+	g.pywrap.Printf("return sum(1 for ii in range(len(self)) if self[ii] == value)\n")
+	g.pywrap.Outdent()
+
+	g.pywrap.Printf("def __contains__(self, value):\n")
+	g.pywrap.Indent()
+	if isGoComparable(esym) {
+		// compared Go-side, unless not convertible to the element type
+		if esym.hasHandle() {
+			g.pywrap.Printf("if isinstance(value, %s):\n", esym.pyPkgId(g, slc.gopkg))
+			g.pywrap.Indent()
+			g.pywrap.Printf("return _%s_contains(self.handle, value.handle)\n", qNm)
+			g.pywrap.Outdent()
+		} else {
+			g.pywrap.Printf("try:\n")
+			g.pywrap.Indent()
+			g.pywrap.Printf("return _%s_contains(self.handle, value)\n", qNm)
+			g.pywrap.Outdent()
+			g.pywrap.Printf("except TypeError:\n")
+			g.pywrap.Indent()
+			g.pywrap.Printf("pass\n")
+			g.pywrap.Outdent()
+		}
+	}
+	g.pywrap.Printf("return any(self[ii] == value for ii in range(len(self)))\n")
+	g.pywrap.Outdent()
+
+	g.pywrap.Printf("def __reversed__(self):\n")
+	g.pywrap.Indent()
+	g.pywrap.Printf("for ii in range(len(self) - 1, -1, -1):\n")
+	g.pywrap.Indent()
+	g.pywrap.Printf("yield self[ii]\n")
+	g.pywrap.Outdent()
+	g.pywrap.Outdent()
+
+	if !slc.isSlice() {
+		return
+	}
+
+	g.pywrap.Printf("def insert(self, idx, value):\n")
+	g.pywrap.Indent()
+	g.pywrap.Printf("n = len(self)\n")
+	g.pywrap.Printf("if idx < 0:\n")
+	g.pywrap.Indent()
+	g.pywrap.Printf("idx = max(idx + n, 0)\n")
+	g.pywrap.Outdent()
+	g.pywrap.Printf("idx = min(idx, n)\n")
+	g.genElemFromPy(slc, esym, gocl)
+	if esym.hasHandle() {
+		g.pywrap.Printf("_%s_insert(self.handle, idx, value.handle)\n", qNm)
+	} else {
+		g.pywrap.Printf("_%s_insert(self.handle, idx, value)\n", qNm)
+	}
+	g.pywrap.Outdent()
+
+	g.pywrap.Printf("def __delitem__(self, key):\n")
+	g.pywrap.Indent()
+	g.pywrap.Printf("if isinstance(key, slice):\n")
+	g.pywrap.Indent()
+	g.pywrap.Printf("st, ed, step = key.indices(len(self))\n")
+	g.pywrap.Printf("if step == 1:\n")
+	g.pywrap.Indent()
+	g.pywrap.Printf("self[st:ed] = []\n")
+	g.pywrap.Printf("return\n")
+	g.pywrap.Outdent()
+	g.pywrap.Printf("for ii in sorted(range(st, ed, step), reverse=True):\n")
+	g.pywrap.Indent()
+	g.pywrap.Printf("_%s_delete(self.handle, ii)\n", qNm)
+	g.pywrap.Outdent()
+	g.pywrap.Printf("return\n")
+	g.pywrap.Outdent()
+	g.pywrap.Printf("if key < 0:\n")
+	g.pywrap.Indent()
+	g.pywrap.Printf("key += len(self)\n")
+	g.pywrap.Outdent()
+	g.pywrap.Printf("if key < 0 or key >= len(self):\n")
+	g.pywrap.Indent()
+	g.pywrap.Printf("raise IndexError('slice index out of range')\n")
+	g.pywrap.Outdent()
+	g.pywrap.Printf("_%s_delete(self.handle, key)\n", qNm)
+	g.pywrap.Outdent()
+
+	g.pywrap.Printf("def pop(self, idx=-1):\n")
+	g.pywrap.Indent()
+	g.pywrap.Printf("rv = self[idx]\n")
+	g.pywrap.Printf("del self[idx]\n")
+	g.pywrap.Printf("return rv\n")
+	g.pywrap.Outdent()
+
+	g.pywrap.Printf("def remove(self, value):\n")
+	g.pywrap.Indent()
+	g.pywrap.Printf("del self[self.index(value)]\n")
+	g.pywrap.Outdent()
+
+	g.pywrap.Printf("def reverse(self):\n")
+	g.pywrap.Indent()
+	g.pywrap.Printf("_%s_reverse(self.handle)\n", qNm)
+	g.pywrap.Outdent()
+
+	g.pywrap.Printf("def extend(self, values):\n")
+	g.pywrap.Indent()
+	g.pywrap.Printf("if not isinstance(values, _collections_abc.Iterable):\n")
+	g.pywrap.Indent()
+	g.pywrap.Printf("raise TypeError('%s.extend takes a sequence as argument')\n", slc.id)
+	g.pywrap.Outdent()
+	if g.isCythonBuffer(slc, esym) {
+		g.pywrap.Printf("_%s_extend(self.handle, values)\n", qNm)
+	} else {
+		g.pywrap.Printf("for elt in list(values):\n")
+		g.pywrap.Indent()
+		g.pywrap.Printf("self.append(elt)\n")
+		g.pywrap.Outdent()
+	}
+	g.pywrap.Outdent()
+}
+
+// genSliceSequenceGo generates the Go side of the insert, delete and reverse
+// of slice elements, which copy the slice to a new array, so that the elements
+// previously returned by pointer, e.g., structs, are not shifted.
+func (g *pyGen) genSliceSequenceGo(slc *symbol, esym *symbol) {
+	slNm := slc.id
+
+	g.gofile.Printf("//export %s_insert\n", slNm)
+	g.gofile.Printf("func %s_insert(handle CGoHandle, _idx int, _vl %s) {\n", slNm, esym.cgoname)
+	g.gofile.Indent()
+	g.genValueLock("ptrFromHandle_" + slNm + "(handle)")
+	g.gofile.Printf("s := ptrFromHandle_%s(handle)\n", slNm)
+	g.genGoFromPy("v", esym, "_vl")
+	g.gofile.Printf("ns := make(%s, 0, len(*s)+1)\n", slc.goname)
+	g.gofile.Printf("ns = append(append(ns, (*s)[:_idx]...), v)\n")
+	g.gofile.Printf("*s = append(ns, (*s)[_idx:]...)\n")
+	g.gofile.Outdent()
+	g.gofile.Printf("}\n\n")
+
+	g.declFunc("add_checked_function", slNm+"_insert", "", capiParam{PyHandle, "handle"}, capiParam{"int", "idx"}, capiParam{esym.cpyname, "value"})
+
+	g.gofile.Printf("//export %s_delete\n", slNm)
+	g.gofile.Printf("func %s_delete(handle CGoHandle, _idx int) {\n", slNm)
+	g.gofile.Indent()
+	g.genValueLock("ptrFromHandle_" + slNm + "(handle)")
+	g.gofile.Printf("s := ptrFromHandle_%s(handle)\n", slNm)
+	g.gofile.Printf("ns := make(%s, 0, len(*s)-1)\n", slc.goname)
+	g.gofile.Printf("*s = append(append(ns, (*s)[:_idx]...), (*s)[_idx+1:]...)\n")
+	g.gofile.Outdent()
+	g.gofile.Printf("}\n\n")
+
+	g.declFunc("mod.add_function", slNm+"_delete", "", capiParam{PyHandle, "handle"}, capiParam{"int", "idx"})
+
+	g.gofile.Printf("//export %s_reverse\n", slNm)
+	g.gofile.Printf("func %s_reverse(handle CGoHandle) {\n", slNm)
+	g.gofile.Indent()
+	g.genValueLock("ptrFromHandle_" + slNm + "(handle)")
+	g.gofile.Printf("s := ptrFromHandle_%s(handle)\n", slNm)
+	g.gofile.Printf("ns := make(%s, len(*s))\n", slc.goname)
+	g.gofile.Printf("for i, v := range *s {\n")
+	g.gofile.Indent()
+	g.gofile.Printf("ns[len(ns)-1-i] = v\n")
+	g.gofile.Outdent()
+	g.gofile.Printf("}\n")
+	g.gofile.Printf("*s = ns\n")
+	g.gofile.Outdent()
+	g.gofile.Printf("}\n\n")
+
+	g.declFunc("mod.add_function", slNm+"_reverse", "", capiParam{PyHandle, "handle"})
+}
+
+// genSliceStridedGo generates the Go side of the extended slicing of
+// a slice, s[st:ed:step] in python, and of the assignment to any slicing of
+// it, which replaces the elements, resizing the slice for contiguous ones.
+func (g *pyGen) genSliceStridedGo(slc *symbol) {
+	slNm := slc.id
+
+	g.gofile.Printf("//export %s_slice\n", slNm)
+	g.gofile.Printf("func %s_slice(handle CGoHandle, _st, _ed, _step int) CGoHandle {\n", slNm)
+	g.gofile.Indent()
+	g.genValueLock("ptrFromHandle_" + slNm + "(handle)")
+	g.gofile.Printf("s := deptrFromHandle_%s(handle)\n", slNm)
+	g.gofile.Printf("ss := %s{}\n", slc.goname)
+	g.gofile.Printf("for i := _st; (_step > 0 && i < _ed) || (_step < 0 && i > _ed); i += _step {\n")
+	g.gofile.Indent()
+	g.gofile.Printf("ss = append(ss, s[i])\n")
+	g.gofile.Outdent()
+	g.gofile.Printf("}\n")
+	g.gofile.Printf("return CGoHandle(handleFromPtr_%s(&ss))\n", slNm)
+	g.gofile.Outdent()
+	g.gofile.Printf("}\n\n")
+
+	g.declFunc("mod.add_function", slNm+"_slice", PyHandle, capiParam{PyHandle, "handle"}, capiParam{"int", "st"}, capiParam{"int", "ed"}, capiParam{"int", "step"})
+
+	g.gofile.Printf("//export %s_setslice\n", slNm)
+	g.gofile.Printf("func %s_setslice(handle CGoHandle, _st, _ed, _step int, _vl CGoHandle) {\n", slNm)
+	g.gofile.Indent()
+	g.genValueLock("ptrFromHandle_" + slNm + "(handle)")
+	g.gofile.Printf("s := ptrFromHandle_%s(handle)\n", slNm)
+	g.gofile.Printf("vs := deptrFromHandle_%s(_vl)\n", slNm)
+	g.gofile.Printf("if _step == 1 {\n")
+	g.gofile.Indent()
+	g.gofile.Printf("ns := make(%s, 0, len(*s)-(_ed-_st)+len(vs))\n", slc.goname)
+	g.gofile.Printf("ns = append(append(ns, (*s)[:_st]...), vs...)\n")
+	g.gofile.Printf("*s = append(ns, (*s)[_ed:]...)\n")
+	g.gofile.Printf("return\n")
+	g.gofile.Outdent()
+	g.gofile.Printf("}\n")
+	g.gofile.Printf("for i, v := range vs {\n")
+	g.gofile.Indent()
+	g.gofile.Printf("(*s)[_st+i*_step] = v\n")
+	g.gofile.Outdent()
+	g.gofile.Printf("}\n")
+	g.gofile.Outdent()
+	g.gofile.Printf("}\n\n")
+
+	g.declFunc("mod.add_function", slNm+"_setslice", "", capiParam{PyHandle, "handle"}, capiParam{"int", "st"}, capiParam{"int", "ed"}, capiParam{"int", "step"}, capiParam{PyHandle, "value"})
+}
+
+// isGoComparable returns true if the values of the element type of a slice
+// are compared with == Go-side, by __contains__: they must be comparable,
+// but not interfaces, which can panic, nor pointers that are converted by
+// value, which are always new.
+func isGoComparable(esym *symbol) bool {
+	if !types.Comparable(esym.gotyp) || esym.isInterface() {
+		return false
+	}
+	return esym.hasHandle() || !esym.isPointer()
+}
+
+// genSliceContainsGo generates the Go side of the __contains__ of a slice,
+// which compares the elements to the converted value in one call.
+func (g *pyGen) genSliceContainsGo(slc *symbol, esym *symbol) {
+	slNm := slc.id
+	g.gofile.Printf("//export %s_contains\n", slNm)
+	// named result for the return of genGoFromPy on conversion errors
+	g.gofile.Printf("func %s_contains(handle CGoHandle, _vl %s) (rv C.char) {\n", slNm, esym.cgoname)
+	g.gofile.Indent()
+	g.genValueLock("ptrFromHandle_" + slNm + "(handle)")
+	g.gofile.Printf("s := deptrFromHandle_%s(handle)\n", slNm)
+	g.genGoFromPy("v", esym, "_vl")
+	g.gofile.Printf("for _, e := range s {\n")
+	g.gofile.Indent()
+	g.gofile.Printf("if e == v {\n")
+	g.gofile.Indent()
+	g.gofile.Printf("return boolGoToPy(true)\n")
+	g.gofile.Outdent()
+	g.gofile.Printf("}\n")
+	g.gofile.Outdent()
+	g.gofile.Printf("}\n")
+	g.gofile.Printf("return boolGoToPy(false)\n")
+	g.gofile.Outdent()
+	g.gofile.Printf("}\n\n")
+
+	g.declFunc("add_checked_function", slNm+"_contains", "bool", capiParam{PyHandle, "handle"}, capiParam{esym.cpyname, "value"})
+}
+
+// genSliceToList generates the python tolist method of a slice or array
+// of elements wrapped by handles, e.g., structs, which returns a list of
+// wrappers of all the elements, built in one call to Go.
+func (g *pyGen) genSliceToList(slc *symbol, esym *symbol) {
+	qNm := g.cfg.Name + "." + slc.id
+	g.pywrap.Printf("def tolist(self):\n")
+	g.pywrap.Indent()
+	g.pywrap.Printf(`"""tolist() list
+
+tolist returns a list of the elements, as self[i] for each i, but getting
+all of them in one call to Go.
+"""
+`)
+	g.pywrap.Printf("return _%s_tolist(self.handle, %s)\n", qNm, esym.pyPkgId(g, slc.gopkg))
+	g.pywrap.Outdent()
+}
+
+// genSliceToListGo generates the Go side of the tolist method, which
+// builds the list of new instances of the python class of the elements.
+func (g *pyGen) genSliceToListGo(slc *symbol, esym *symbol) {
+	slNm := slc.id
+	g.gofile.Printf("//export %s_tolist\n", slNm)
+	g.gofile.Printf("func %s_tolist(handle CGoHandle, cls *C.PyObject) *C.PyObject {\n", slNm)
+	g.gofile.Indent()
+	g.genValueLock("ptrFromHandle_" + slNm + "(handle)")
+	g.gofile.Printf("s := deptrFromHandle_%s(handle)\n", slNm)
+	g.gofile.Printf("l := C.PyList_New(C.Py_ssize_t(len(s)))\n")
+	g.gofile.Printf("for i := range s {\n")
+	g.gofile.Indent()
+	if !esym.isPtrOrIface() {
+		g.gofile.Printf("h := %s(&(s[i]))%s\n", esym.go2py, esym.go2pyParenEx)
+	} else {
+		g.gofile.Printf("h := %s(s[i])%s\n", esym.go2py, esym.go2pyParenEx)
+	}
+	g.gofile.Printf("e := C.gopy_class_new(cls, C.int64_t(h))\n")
+	g.gofile.Printf("if e == nil {\n")
+	g.gofile.Indent()
+	g.gofile.Printf("C.gopy_decref(l)\n")
+	g.gofile.Printf("return nil // python error is set\n")
+	g.gofile.Outdent()
+	g.gofile.Printf("}\n")
+	g.gofile.Printf("C.PyList_SetItem(l, C.Py_ssize_t(i), e) // steals e\n")
+	g.gofile.Outdent()
+	g.gofile.Printf("}\n")
+	g.gofile.Printf("return l\n")
+	g.gofile.Outdent()
+	g.gofile.Printf("}\n\n")
+
+	g.declFunc("mod.add_function", slNm+"_tolist", "PyObject*", capiParam{PyHandle, "handle"}, capiParam{"PyObject*", "cls"})
+}
+
+var (
+	sortLen  = textIface("Len", nil, []types.Type{types.Typ[types.Int]})
+	sortLess = textIface("Less", []types.Type{types.Typ[types.Int], types.Typ[types.Int]}, []types.Type{types.Typ[types.Bool]})
+	sortSwap = textIface("Swap", []types.Type{types.Typ[types.Int], types.Typ[types.Int]}, nil)
+)
+
+// sliceSortLess returns how the elements of a slice are sorted Go-side, by
+// its sort method: iface is true if the slice implements sort.Interface,
+// and otherwise less is the Go comparison of the elements s[i] and s[j], with
+// the Less(other T) bool method of the element type T, or with < for ordered
+// basic types.  Both are zero if the slice is not
+// sortable Go-side.
+func sliceSortLess(slc *symbol, esym *symbol) (less string, iface bool) {
+	styp := slc.GoType()
+	if types.Implements(styp, sortLen) && types.Implements(styp, sortLess) && types.Implements(styp, sortSwap) {
+		return "", true
+	}
+	etyp := esym.GoType()
+	if obj, _, _ := types.LookupFieldOrMethod(etyp, true, nil, "Less"); obj != nil && !esym.isInterface() {
+		if fn, ok := obj.(*types.Func); ok {
+			sig := fn.Type().(*types.Signature)
+			res := sig.Results()
+			if sig.Params().Len() == 1 && types.Identical(sig.Params().At(0).Type(), etyp) &&
+				res.Len() == 1 && types.Identical(res.At(0).Type(), types.Typ[types.Bool]) {
+				return "s[i].Less(s[j])", false
+			}
+		}
+	}
+	if bt, ok := etyp.Underlying().(*types.Basic); ok && bt.Info()&types.IsOrdered != 0 {
+		return "s[i] < s[j]", false
+	}
+	return "", false
+}
+
+// genSliceSort generates the python sort method of a slice that is
+// sortable Go-side, see sliceSortLess, which sorts it in place in one call
+// to Go, unless a key function is given, which is applied python-side.
+func (g *pyGen) genSliceSort(slc *symbol, esym *symbol) {
+	if less, iface := sliceSortLess(slc, esym); less == "" && !iface {
+		return
+	}
+	qNm := g.cfg.Name + "." + slc.id
+	g.pywrap.Printf("def sort(self, key=None, reverse=False):\n")
+	g.pywrap.Indent()
+	g.pywrap.Printf(`"""sort(key=None, reverse=False)
+
+sort sorts the elements in place, stably, as list.sort, but in one call to Go
+comparing the elements Go-side, unless a key function is given.
+"""
+`)
+	g.pywrap.Printf("if key is not None:\n")
+	g.pywrap.Indent()
+	g.pywrap.Printf("self[:] = sorted(self, key=key, reverse=reverse)\n")
+	g.pywrap.Printf("return\n")
+	g.pywrap.Outdent()
+	g.pywrap.Printf("_%s_sort(self.handle, reverse)\n", qNm)
+	g.pywrap.Outdent()
+}
+
+// genSliceSortGo generates the Go side of the sort method of a slice, see
+// genSliceSort, with sort.Stable for a sort.Interface, and sort.SliceStable
+// otherwise.
+func (g *pyGen) genSliceSortGo(slc *symbol, esym *symbol) {
+	less, iface := sliceSortLess(slc, esym)
+	if less == "" && !iface {
+		return
+	}
+	slNm := slc.id
+	g.gofile.Printf("//export %s_sort\n", slNm)
+	g.gofile.Printf("func %s_sort(handle CGoHandle, reverse C.char) {\n", slNm)
+	g.gofile.Indent()
+	g.genValueLock("ptrFromHandle_" + slNm + "(handle)")
+	g.gofile.Printf("s := deptrFromHandle_%s(handle)\n", slNm)
+	if iface {
+		g.gofile.Printf("if boolPyToGo(reverse) {\n")
+		g.gofile.Indent()
+		g.gofile.Printf("sort.Stable(sort.Reverse(s))\n")
+		g.gofile.Printf("return\n")
+		g.gofile.Outdent()
+		g.gofile.Printf("}\n")
+		g.gofile.Printf("sort.Stable(s)\n")
+	} else {
+		g.gofile.Printf("rev := boolPyToGo(reverse)\n")
+		g.gofile.Printf("sort.SliceStable(s, func(i, j int) bool {\n")
+		g.gofile.Indent()
+		g.gofile.Printf("if rev {\n")
+		g.gofile.Indent()
+		g.gofile.Printf("i, j = j, i\n")
+		g.gofile.Outdent()
+		g.gofile.Printf("}\n")
+		g.gofile.Printf("return %s\n", less)
+		g.gofile.Outdent()
+		g.gofile.Printf("})\n")
+	}
+	g.gofile.Outdent()
+	g.gofile.Printf("}\n\n")
+
+	g.declFunc("mod.add_function", slNm+"_sort", "", capiParam{PyHandle, "handle"}, capiParam{"bool", "reverse"})
+}
+
+// isValueSlice returns true for slices of interface{} values, which are
+// deep-converted from python sequences, like the elements of value maps.
+func isValueSlice(typ types.Type) bool {
+	styp, ok := typ.Underlying().(*types.Slice)
+	if !ok {
+		return false
+	}
+	it, ok := styp.Elem().(*types.Interface)
+	return ok && it.Empty()
+}
+
+// genArrayInit generates the python construction of a new array, which
+// copies from the python sequence given as argument, if any, that must
+// have the exact length of the array.
+func (g *pyGen) genArrayInit(slc *symbol, esym *symbol) {
+	slNm := slc.id
+	qNm := g.cfg.Name + "." + slNm
+	alen := slc.GoType().Underlying().(*types.Array).Len()
+	g.pywrap.Printf("else:\n")
+	g.pywrap.Indent()
+	g.pywrap.Printf("self.handle = _%s_CTor()\n", qNm)
+	g.pywrap.Printf("_%s.IncRef(self.handle)\n", g.pypkgname)
+	g.pywrap.Printf("if len(args) > 0:\n")
+	g.pywrap.Indent()
+	g.pywrap.Printf("if not isinstance(args[0], _collections_abc.Iterable):\n")
+	g.pywrap.Indent()
+	g.pywrap.Printf("raise TypeError('%s.__init__ takes a sequence as argument')\n", slNm)
+	g.pywrap.Outdent()
+	if esym.goname == "byte" || esym.goname == "uint8" {
+		// bytes are iterated as single-char strings in py2
+		g.pywrap.Printf("if isinstance(args[0], (bytes, bytearray)):\n")
+		g.pywrap.Indent()
+		g.pywrap.Printf("vals = list(bytearray(args[0]))\n")
+		g.pywrap.Outdent()
+		g.pywrap.Printf("else:\n")
+		g.pywrap.Indent()
+		g.pywrap.Printf("vals = list(args[0])\n")
+		g.pywrap.Outdent()
+	} else {
+		g.pywrap.Printf("vals = list(args[0])\n")
+	}
+	g.pywrap.Printf("if len(vals) != %d:\n", alen)
+	g.pywrap.Indent()
+	g.pywrap.Printf("raise ValueError('%s.__init__ takes a sequence of length %d, not %%d' %% len(vals))\n", slNm, alen)
+	g.pywrap.Outdent()
+	g.pywrap.Printf("for i, elt in enumerate(vals):\n")
+	g.pywrap.Indent()
+	g.pywrap.Printf("self[i] = elt\n")
+	g.pywrap.Outdent()
+	g.pywrap.Outdent()
+	g.pywrap.Outdent()
+}
+
 func (g *pyGen) genSliceMethods(s *Slice) {
 	for _, m := range s.meths {
 		g.genMethod(s.sym, m)
@@ -0,0 +1,279 @@
+// Copyright 2019 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bind
+
+import (
+	"bytes"
+	"fmt"
+	"go/types"
+	"strings"
+)
+
+// PyStressPreamble starts the stress tests of the bindings of a package,
+// with -gen-stress-tests.  They are plain test functions, collected by
+// pytest, without depending on it.
+// 1 = name of package (outname), 2 = gencmd, 3 = specific package name,
+// 4 = spec pkg path, 5 = imports
+const PyStressPreamble = `# stress tests of the bindings of package %[4]s within overall package %[1]s
+# File is generated by gopy. Do not edit.
+# %[2]s
+#
+# Run with pytest, e.g., python3 -m pytest test_%[3]s_stress.py
+
+import gc
+import threading
+
+%[5]s
+THREADS = 8
+ITERS = 200
+TIMEOUT = 60
+
+def hammer(fn):
+	"""hammer calls fn(t, i) ITERS times in each of THREADS threads, started at once, and raises the
+	first exception of the threads.  It fails if they are not done within TIMEOUT seconds, e.g., as
+	they deadlock on the GIL, or if the handles of the Go values that they created are not released."""
+	gc.collect()
+	base = _%[1]s.NumHandles()
+	errors = []
+	start = threading.Barrier(THREADS)
+	def run(t):
+		try:
+			start.wait()
+			for i in range(ITERS):
+				fn(t, i)
+		except BaseException as e:
+			errors.append(e)
+	threads = [threading.Thread(target=run, args=(t,), daemon=True) for t in range(THREADS)]
+	for th in threads:
+		th.start()
+	for th in threads:
+		th.join(TIMEOUT)
+		assert not th.is_alive(), 'threads still running after %%ds' %% TIMEOUT
+	if errors:
+		raise errors[0]
+	gc.collect()
+	n = _%[1]s.NumHandles()
+	assert n <= base, '%%d handles leaked' %% (n - base)
+`
+
+// genPkgStressTests generates the stress tests of the bindings of the
+// current package, with -gen-stress-tests, which call them from THREADS
+// python threads at once, to validate the GIL handling and the handle
+// registry: the churn of the handles of its structs, slices, maps and
+// channels, the accesses to the basic fields of a shared struct, the
+// sends and receives on a shared channel, and the calls of its functions
+// with python callbacks.
+func (g *pyGen) genPkgStressTests() {
+	if !g.cfg.GenStressTests || g.pkg == goPackage || g.lang == 2 {
+		return
+	}
+	pkg := g.pkg.pkg.Name()
+	pt := &printer{buf: new(bytes.Buffer), indentEach: []byte("\t")}
+
+	for _, s := range g.pkg.structs {
+		g.genStructStress(pt, pkg, s)
+	}
+	slices, maps := g.stubCollections()
+	for _, sym := range append(slices, maps...) {
+		if sym.isArray() {
+			continue
+		}
+		nm := g.stubName(sym)
+		pt.Printf("\ndef test_churn_%s():\n", nm)
+		pt.Indent()
+		pt.Printf("def churn(t, i):\n")
+		pt.Indent()
+		pt.Printf("x = %s.%s()\n", pkg, nm)
+		pt.Printf("assert len(%s.%s(handle=x.handle)) == len(x)\n", pkg, nm)
+		pt.Outdent()
+		pt.Printf("hammer(churn)\n")
+		pt.Outdent()
+	}
+	for _, n := range g.syms.names() {
+		sym := g.syms.sym(n)
+		if sym.gopkg.Path() == g.pkg.pkg.Path() && sym.isType() && sym.isChan() && !sym.isNamed() && !sym.isPointer() {
+			g.genChanStress(pt, pkg, sym)
+		}
+	}
+	for _, f := range g.pkg.funcs {
+		g.genCallbackStress(pt, pkg, f)
+	}
+
+	var imps string
+	if g.mode == ModeGen || g.mode == ModeBuild {
+		imps = fmt.Sprintf("import go, %s, _%s\n", pkg, g.cfg.Name)
+	} else {
+		imps = fmt.Sprintf("from %s import go, %s, _%s\n", g.cfg.Name, pkg, g.cfg.Name)
+	}
+	body := pt.buf.Bytes()
+	pt = &printer{buf: new(bytes.Buffer), indentEach: []byte("\t")}
+	pt.Printf(PyStressPreamble, g.cfg.Name, g.cfg.Cmd, pkg, g.pkg.pkg.Path(), imps)
+	pt.buf.Write(body)
+	g.pytests = append(g.pytests, pyWrapOut{"test_" + pkg + "_stress.py", pt})
+}
+
+// genStructStress generates the stress tests of the struct s: the churn of
+// its handles, and the accesses to its basic fields on a shared struct.
+func (g *pyGen) genStructStress(pt *printer, pkg string, s *Struct) {
+	nm := s.obj.Name()
+	pt.Printf("\ndef test_churn_%s():\n", nm)
+	pt.Indent()
+	pt.Printf("def churn(t, i):\n")
+	pt.Indent()
+	pt.Printf("x = %s.%s()\n", pkg, nm)
+	pt.Printf("assert %s.%s(handle=x.handle).handle == x.handle\n", pkg, nm)
+	pt.Outdent()
+	pt.Printf("hammer(churn)\n")
+	pt.Outdent()
+
+	var fields []string
+	typ := s.Struct()
+	for i := 0; i < typ.NumFields(); i++ {
+		f := typ.Field(i)
+		fnm := g.pyFieldName(s, i, f)
+		if fnm == "" || isJSONField(s, i, f) {
+			continue
+		}
+		if fsym := fieldSetSymbol(s, i, f); fsym != nil && stressZero(fsym) != "" {
+			fields = append(fields, fnm)
+		}
+	}
+	if len(fields) == 0 {
+		return
+	}
+	pt.Printf("\ndef test_fields_%s():\n", nm)
+	pt.Indent()
+	pt.Printf("x = %s.%s()\n", pkg, nm)
+	pt.Printf("def access(t, i):\n")
+	pt.Indent()
+	for _, fnm := range fields {
+		pt.Printf("x.%[1]s = x.%[1]s\n", fnm)
+	}
+	pt.Outdent()
+	pt.Printf("hammer(access)\n")
+	pt.Outdent()
+}
+
+// genChanStress generates the stress tests of the channel type sym, if it
+// is bidirectional with a basic element type: the churn of its handles, and
+// the sends and receives on a shared channel, buffered for all the threads.
+func (g *pyGen) genChanStress(pt *printer, pkg string, sym *symbol) {
+	typ := sym.GoType().Underlying().(*types.Chan)
+	esym := g.syms.symtype(typ.Elem())
+	if typ.Dir() != types.SendRecv || esym == nil || stressZero(esym) == "" {
+		return
+	}
+	nm := g.stubName(sym)
+	pt.Printf("\ndef test_chan_%s():\n", nm)
+	pt.Indent()
+	pt.Printf("c = %s.%s(THREADS)\n", pkg, nm)
+	pt.Printf("def sendrecv(t, i):\n")
+	pt.Indent()
+	pt.Printf("assert %s.%s(1).cap() == 1\n", pkg, nm)
+	pt.Printf("c.send(%s)\n", stressZero(esym))
+	pt.Printf("c.recv(TIMEOUT)\n")
+	pt.Outdent()
+	pt.Printf("hammer(sendrecv)\n")
+	pt.Printf("assert c.len() == 0\n")
+	pt.Printf("c.close()\n")
+	pt.Outdent()
+}
+
+// genCallbackStress generates the stress test of the function fsym, if it
+// takes python callbacks, and otherwise only basic args: it is called with
+// callbacks returning zero values, or None for an error, and zero values
+// for the other args, except 1 for the numbers, so that it calls them, e.g.,
+// n times.  The Go errors and panics that it raises for them are expected,
+// as the calls are only checked for crashes, deadlocks and leaks.
+func (g *pyGen) genCallbackStress(pt *printer, pkg string, fsym *Func) {
+	sig := fsym.sig
+	if sig == nil || g.isAsync(fsym) {
+		return
+	}
+	gname, _, err := g.pyFuncName(fsym)
+	if err != nil {
+		return
+	}
+	args := sig.Params()
+	var vals []string
+	ncb := 0
+	for i, arg := range args {
+		if i == 0 && fsym.hasctx {
+			continue
+		}
+		if i == len(args)-1 && fsym.isVariadic {
+			continue
+		}
+		sym := g.syms.symtype(arg.GoType())
+		if sym == nil {
+			return
+		}
+		if !sym.isSignature() {
+			val := stressZero(sym)
+			switch val {
+			case "":
+				return
+			case "0":
+				val = "1"
+			}
+			vals = append(vals, val)
+			continue
+		}
+		ret := "None"
+		res := sym.GoType().Underlying().(*types.Signature).Results()
+		switch res.Len() {
+		case 0:
+		case 1:
+			if isErrorType(res.At(0).Type()) {
+				break
+			}
+			rsym := g.syms.symtype(res.At(0).Type())
+			if rsym == nil || stressZero(rsym) == "" {
+				return
+			}
+			ret = stressZero(rsym)
+		default:
+			return
+		}
+		vals = append(vals, "lambda *args: "+ret)
+		ncb++
+	}
+	if ncb == 0 {
+		return
+	}
+	pt.Printf("\ndef test_callback_%s():\n", gname)
+	pt.Indent()
+	pt.Printf("def call(t, i):\n")
+	pt.Indent()
+	pt.Printf("try:\n")
+	pt.Indent()
+	pt.Printf("%s.%s(%s)\n", pkg, gname, strings.Join(vals, ", "))
+	pt.Outdent()
+	pt.Printf("except (go.GoError, go.GoPanic):\n")
+	pt.Indent()
+	pt.Printf("pass\n")
+	pt.Outdent()
+	pt.Outdent()
+	pt.Printf("hammer(call)\n")
+	pt.Outdent()
+}
+
+// stressZero returns the python literal of the zero value of the basic
+// type sym, or "" if it is not basic, or has none, e.g., unsafe.Pointer.
+func stressZero(sym *symbol) string {
+	b, ok := sym.GoType().Underlying().(*types.Basic)
+	if !ok {
+		return ""
+	}
+	switch info := b.Info(); {
+	case info&types.IsBoolean != 0:
+		return "False"
+	case info&types.IsString != 0:
+		return "''"
+	case info&types.IsNumeric != 0:
+		return "0"
+	}
+	return ""
+}
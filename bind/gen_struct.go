@@ -7,6 +7,7 @@ package bind
 import (
 	"fmt"
 	"go/types"
+	"strings"
 )
 
 func (g *pyGen) genStruct(s *Struct) {
@@ -15,7 +16,18 @@ func (g *pyGen) genStruct(s *Struct) {
 	base := "go.GoClass"
 	emb := s.FirstEmbed()
 	if emb != nil {
-		base = emb.pyPkgId(s.sym.gopkg)
+		base = emb.pyPkgId(g, s.sym.gopkg)
+	}
+	if s.prots&ProtoError != 0 {
+		base += ", go.GoError"
+	}
+	_, sdoc := isSynchronized(s.Doc())
+	iname, sdoc := restrictIface(sdoc)
+	if nm := g.restrictTo(s); nm != "" {
+		iname = nm
+	}
+	if iname != "" {
+		s.meths = g.restrictMethods(s, iname)
 	}
 
 	g.pywrap.Printf(`
@@ -24,7 +36,7 @@ class %[1]s(%[4]s):
 	""%[2]q""
 `,
 		strNm,
-		s.Doc(),
+		sdoc,
 		s.GoName(),
 		base,
 	)
@@ -40,8 +52,6 @@ func (g *pyGen) genStructInit(s *Struct) {
 	qNm := s.GoName()
 	// strNm := s.obj.Name()
 
-	numFields := s.Struct().NumFields()
-
 	g.pywrap.Printf("def __init__(self, *args, **kwargs):\n")
 	g.pywrap.Indent()
 	g.pywrap.Printf(`"""
@@ -62,50 +72,69 @@ in which case a new Go object is constructed first
 	g.pywrap.Outdent()
 	g.pywrap.Printf("else:\n")
 	g.pywrap.Indent()
-	g.pywrap.Printf("self.handle = _%s.%s_CTor()\n", pkgname, s.ID())
-	g.pywrap.Printf("_%s.IncRef(self.handle)\n", g.pypkgname)
 
-	for i := 0; i < numFields; i++ {
-		f := s.Struct().Field(i)
-		if _, err := isPyCompatField(f); err != nil {
-			continue
+	// the fields given by position or name are set in one call to the Go
+	// ctor, see genStructFieldsCTor, and the rest by their setters
+	batch, rest := g.ctorFields(s)
+	if len(batch) == 0 {
+		g.pywrap.Printf("self.handle = _%s.%s_CTor()\n", pkgname, s.ID())
+	} else {
+		g.pywrap.Printf("_set = 0\n")
+		cargs := []string{"_set"}
+		for bi, i := range batch {
+			f := s.Struct().Field(i)
+			pnm := g.pyFieldName(s, i, f)
+			fsym := fieldSetSymbol(s, i, f)
+			vnm := fmt.Sprintf("_f%d", bi)
+			cargs = append(cargs, vnm)
+			g.pywrap.Printf("%s = %s\n", vnm, pyZeroArg(fsym.cpyname))
+			g.pywrap.Printf("if %[1]q in kwargs or %[2]d < len(args):\n", pnm, i)
+			g.pywrap.Indent()
+			g.pywrap.Printf("_set |= 1 << %d\n", bi)
+			g.pywrap.Printf("%[1]s = kwargs[%[2]q] if %[2]q in kwargs else args[%[3]d]\n", vnm, pnm, i)
+			// NOTE: this will accept int args for any handles / object fields so
+			// some kind of additional type-checking logic to prevent that in a way
+			// that also allows valid handles to be used as required. This is
+			// achieved in genFieldFromPy, as in the per-field setters (see below),
+			// with checks to ensure that a struct field that is a gopy managed
+			// object is only assigned gopy managed objects. Fields of basic types
+			// (e.g int, string) etc can be assigned to directly.
+			g.genFieldFromPy(f, fsym, vnm)
+			g.pywrap.Outdent()
 		}
-		// NOTE: this will accept int args for any handles / object fields so
-		// some kind of additional type-checking logic to prevent that in a way
-		// that also allows valid handles to be used as required. This is
-		// achieved in the per-field setters (see below) with checks to ensure
-		// that a struct field that is a gopy managed object is only
-		// assigned gopy managed objects. Fields of basic types (e.g int, string)
-		// etc can be assigned to directly.
+		g.pywrap.Printf("self.handle = _%s.%s_CTor_fields(%s)\n", pkgname, s.ID(), strings.Join(cargs, ", "))
+	}
+	g.pywrap.Printf("_%s.IncRef(self.handle)\n", g.pypkgname)
+
+	for _, i := range rest {
+		pnm := g.pyFieldName(s, i, s.Struct().Field(i))
 		g.pywrap.Printf("if  %[1]d < len(args):\n", i)
 		g.pywrap.Indent()
-		g.pywrap.Printf("self.%s = args[%d]\n", f.Name(), i)
+		g.pywrap.Printf("self.%s = args[%d]\n", pnm, i)
 		g.pywrap.Outdent()
-		g.pywrap.Printf("if %[1]q in kwargs:\n", f.Name())
+		g.pywrap.Printf("if %[1]q in kwargs:\n", pnm)
 		g.pywrap.Indent()
-		g.pywrap.Printf("self.%[1]s = kwargs[%[1]q]\n", f.Name())
+		g.pywrap.Printf("self.%[1]s = kwargs[%[1]q]\n", pnm)
 		g.pywrap.Outdent()
 	}
 	g.pywrap.Outdent()
 	g.pywrap.Outdent()
 
-	g.pywrap.Printf("def __del__(self):\n")
-	g.pywrap.Indent()
-	g.pywrap.Printf("_%s.DecRef(self.handle)\n", g.pypkgname)
-	g.pywrap.Outdent()
+	g.genDel()
 
-	if s.prots&ProtoStringer != 0 {
-		for _, m := range s.meths {
-			if !isStringer(m.obj) {
-				continue
-			}
-			g.pywrap.Printf("def __str__(self):\n")
-			g.pywrap.Indent()
-			g.pywrap.Printf("return self.String()\n")
-			g.pywrap.Outdent()
-			g.pywrap.Printf("\n")
-		}
-	} else {
+	stringer := false
+	switch {
+	case s.prots&ProtoError != 0 && s.hasMethod("Error"):
+		// raised as an exception, which is described by its error message
+		g.pywrap.Printf("def __str__(self):\n")
+		g.pywrap.Indent()
+		g.pywrap.Printf("return _%s.%s_Error(self.handle)\n", pkgname, s.ID())
+		g.pywrap.Outdent()
+		g.pywrap.Printf("\n")
+	case s.prots&ProtoStringer != 0:
+		g.genStringer(qNm)
+		stringer = true
+	default:
 		g.pywrap.Printf("def __str__(self):\n")
 		g.pywrap.Indent()
 		g.pywrap.Printf("pr = [(p, getattr(self, p)) for p in dir(self) if not p.startswith('__')]\n")
@@ -132,19 +161,31 @@ in which case a new Go object is constructed first
 		g.pywrap.Outdent()
 	}
 
-	g.pywrap.Printf("def __repr__(self):\n")
-	g.pywrap.Indent()
-	g.pywrap.Printf("pr = [(p, getattr(self, p)) for p in dir(self) if not p.startswith('__')]\n")
-	g.pywrap.Printf("sv = '%s ( '\n", qNm)
-	g.pywrap.Printf("for v in pr:\n")
-	g.pywrap.Indent()
-	g.pywrap.Printf("if not callable(v[1]):\n")
-	g.pywrap.Indent()
-	g.pywrap.Printf("sv += v[0] + '=' + str(v[1]) + ', '\n")
-	g.pywrap.Outdent()
-	g.pywrap.Outdent()
-	g.pywrap.Printf("return sv + ')'\n")
-	g.pywrap.Outdent()
+	if !stringer {
+		g.pywrap.Printf("def __repr__(self):\n")
+		g.pywrap.Indent()
+		g.pywrap.Printf("pr = [(p, getattr(self, p)) for p in dir(self) if not p.startswith('__')]\n")
+		g.pywrap.Printf("sv = '%s ( '\n", qNm)
+		g.pywrap.Printf("for v in pr:\n")
+		g.pywrap.Indent()
+		g.pywrap.Printf("if not callable(v[1]):\n")
+		g.pywrap.Indent()
+		g.pywrap.Printf("sv += v[0] + '=' + str(v[1]) + ', '\n")
+		g.pywrap.Outdent()
+		g.pywrap.Outdent()
+		g.pywrap.Printf("return sv + ')'\n")
+		g.pywrap.Outdent()
+	}
+	if s.prots&ProtoCloser != 0 {
+		g.genCloser()
+	}
+	g.genOperators(s.meths)
+	g.genBool(s.meths, false)
+	g.genCopy(fmt.Sprintf("_%s.%s_deepcopy", pkgname, s.ID()), false)
+	g.genPickle(s.ID())
+	g.genStructDict(s)
+	g.genStructJSON(s)
+	g.genStructFormat(s)
 
 	// go ctor
 	ctNm := s.ID() + "_CTor"
@@ -156,64 +197,195 @@ in which case a new Go object is constructed first
 	g.gofile.Outdent()
 	g.gofile.Printf("}\n")
 
-	g.pybuild.Printf("mod.add_function('%s', retval('%s'), [])\n", ctNm, PyHandle)
+	g.declFunc("mod.add_function", ctNm, PyHandle)
+	g.genStructFieldsCTor(s)
 
+	g.genDeepCopyGo(s.ID(), qNm)
+	g.genPickleGo(s.ID(), qNm)
+	g.genStructDictGo(s)
+	g.genStructJSONGo(s)
+	g.genStructFormatGo(s)
 }
 
 func (g *pyGen) genStructMembers(s *Struct) {
 	typ := s.Struct()
 	for i := 0; i < typ.NumFields(); i++ {
 		f := typ.Field(i)
-		ftyp, err := isPyCompatField(f)
-		if err != nil {
+		if g.pyFieldName(s, i, f) == "" {
 			continue
 		}
-		g.genStructMemberGetter(s, i, f)
-		if !ftyp.isArray() {
-			g.genStructMemberSetter(s, i, f)
+		g.genStructMemberGetter(s, s, i, f)
+		g.genStructMemberSetter(s, s, i, f)
+	}
+	for _, pf := range g.promotedFields(s) {
+		g.genStructMemberGetter(s, pf.s, pf.i, pf.f)
+		g.genStructMemberSetter(s, pf.s, pf.i, pf.f)
+	}
+}
+
+// promotedField is a field promoted to a struct from one of its embedded
+// structs, which is the i'th field of the struct s it is declared in.
+type promotedField struct {
+	s *Struct
+	i int
+	f *types.Var
+}
+
+// promotedFields returns the fields promoted to the struct from its
+// embedded structs of the same package, other than those inherited from its
+// python base class, see FirstEmbed, as the methods are, see promotedMethods.
+// Fields promoted through embedded pointers, which can be nil, are not.
+// Shadowed and ambiguous fields are not promoted, as in Go, nor are those
+// with the python name of a field or method of the struct.
+func (g *pyGen) promotedFields(s *Struct) []promotedField {
+	st := s.Struct()
+	taken := make(map[string]bool)
+	for i := 0; i < st.NumFields(); i++ {
+		taken[g.pyFieldName(s, i, st.Field(i))] = true
+	}
+	for _, m := range s.meths {
+		if pnm, _, err := g.pyFuncName(m); err == nil {
+			taken[pnm] = true
+		}
+	}
+	base := s.FirstEmbed() != nil
+	var pfs []promotedField
+	for i := 0; i < st.NumFields(); i++ {
+		f := st.Field(i)
+		if i == 0 && base {
+			continue
+		}
+		named := s.embeddedStruct(f)
+		if _, isPtr := f.Type().(*types.Pointer); named == nil || isPtr {
+			continue
+		}
+		esym := g.syms.symtype(named)
+		if esym == nil {
+			continue
+		}
+		es, ok := s.pkg.objs[esym.goname].(*Struct)
+		if !ok {
+			continue
+		}
+		est := es.Struct()
+		for j := 0; j < est.NumFields(); j++ {
+			ef := est.Field(j)
+			pnm := g.pyFieldName(es, j, ef)
+			if pnm == "" || taken[pnm] {
+				continue
+			}
+			obj, idx, _ := types.LookupFieldOrMethod(s.GoType(), true, s.pkg.pkg, ef.Name())
+			if obj != ef || len(idx) != 2 || idx[0] != i {
+				continue
+			}
+			taken[pnm] = true
+			pfs = append(pfs, promotedField{s: es, i: j, f: ef})
+		}
+	}
+	return pfs
+}
+
+// pyFieldName returns the python name of the i'th field of the struct,
+// or "" if the field is not exposed to python, see pyFieldNames.
+func (g *pyGen) pyFieldName(s *Struct, i int, f types.Object) string {
+	if s.pynames == nil {
+		s.pynames = g.pyFieldNames(s)
+	}
+	return s.pynames[i]
+}
+
+// pyFieldNames returns the python names of the fields of the struct.
+// A gopy struct tag takes precedence, then the json struct tag name when
+// generating with -json-names, and then the renamed or plain Go name.
+// Fields that are not python compatible or are tagged gopy:"-" have no
+// name.  Names that are python keywords are made safe, and a name that
+// is already taken falls back to the Go name of the field.
+func (g *pyGen) pyFieldNames(s *Struct) []string {
+	typ := s.Struct()
+	names := make([]string, typ.NumFields())
+	taken := map[string]bool{"handle": true}
+	for i := range names {
+		f := typ.Field(i)
+		if _, err := g.syms.isPyCompatField(f); err != nil {
+			continue
+		}
+		tag := typ.Tag(i)
+		gname := f.Name()
+		if g.cfg.JSONNames {
+			gname = extractJSONNameFieldTag(gname, tag)
+		}
+		if gname == f.Name() && g.cfg.RenameCase {
+			gname = toSnakeCase(gname)
+		}
+		newName, err := extractPythonNameFieldTag(gname, tag)
+		switch {
+		case err != nil:
+			fmt.Printf("ignoring struct field tag: %s.%s: %v\n", s.GoName(), f.Name(), err)
+		case newName == "-":
+			continue
+		default:
+			gname = newName
+		}
+		gname = pySafeName(gname)
+		if taken[gname] {
+			fmt.Printf("ignoring python name %q of struct field %s.%s: name already used\n", gname, s.GoName(), f.Name())
+			gname = f.Name()
+			if taken[gname] {
+				fmt.Printf("ignoring struct field %s.%s: name already used\n", s.GoName(), f.Name())
+				continue
+			}
 		}
+		taken[gname] = true
+		names[i] = gname
 	}
+	return names
 }
 
-func (g *pyGen) genStructMemberGetter(s *Struct, i int, f types.Object) {
+// genStructMemberGetter generates the getter of the i'th field f of the
+// struct fs, which is s, or one of its embedded structs for the fields
+// promoted to s, see promotedFields.
+func (g *pyGen) genStructMemberGetter(s, fs *Struct, i int, f types.Object) {
 	pkgname := g.cfg.Name
 	ft := f.Type()
-	ret := current.symtype(ft)
+	ret := g.syms.symtype(ft)
+	if isJSONField(fs, i, f) {
+		ret = g.syms.jsonFieldSymbol(f)
+	}
 	if ret == nil {
 		return
 	}
 
-	gname := f.Name()
-	if g.cfg.RenameCase {
-		gname = toSnakeCase(gname)
-	}
-
-	if newName, err := extractPythonNameFieldTag(gname, s.Struct().Tag(i)); err == nil {
-		gname = newName
-	}
+	gname := g.pyFieldName(fs, i, f)
 
 	cgoFn := fmt.Sprintf("%s_%s_Get", s.ID(), f.Name())
 
 	g.pywrap.Printf("@property\n")
-	g.pywrap.Printf("def %[1]s(self):\n", gname)
+	g.pywrap.Printf("def %[1]s(self)%[2]s:\n", gname, g.typeHint(" -> ", ret, false))
 	g.pywrap.Indent()
-	if gdoc := g.pkg.getDoc(s.Obj().Name(), f); gdoc != "" {
+	if gdoc := g.pkg.getDoc(fs.Obj().Name(), f); gdoc != "" {
 		g.pywrap.Printf(`"""`)
 		g.pywrap.Printf(gdoc)
 		g.pywrap.Println(`"""`)
 	}
-	if ret.hasHandle() {
-		cvnm := ret.pyPkgId(g.pkg.pkg)
+	if ret.hasHandle() || ret.isSignature() {
+		cvnm := ret.pyPkgId(g, g.pkg.pkg)
 		g.pywrap.Printf("return %s(handle=_%s.%s(self.handle))\n", cvnm, pkgname, cgoFn)
 	} else {
 		g.pywrap.Printf("return _%s.%s(self.handle)\n", pkgname, cgoFn)
 	}
 	g.pywrap.Outdent()
 
+	cgoRet, cpyRet := ret.cgoname, ret.cpyname
+	if ret.isSignature() { // returned as a handle to a callable class
+		cgoRet, cpyRet = "CGoHandle", PyHandle
+	}
+
 	g.gofile.Printf("//export %s\n", cgoFn)
-	g.gofile.Printf("func %s(handle CGoHandle) %s {\n", cgoFn, ret.cgoname)
+	g.gofile.Printf("func %s(handle CGoHandle) %s {\n", cgoFn, cgoRet)
 	g.gofile.Indent()
-	g.gofile.Printf("op := ptrFromHandle_%s(handle)\nreturn ", s.ID())
+	g.gofile.Printf("op := ptrFromHandle_%s(handle)\n", s.ID())
+	g.genValueLock("op")
+	g.gofile.Printf("return ")
 	if ret.go2py != "" {
 		if ret.hasHandle() && !ret.isPtrOrIface() {
 			g.gofile.Printf("%s(&op.%s)%s", ret.go2py, f.Name(), ret.go2pyParenEx)
@@ -227,31 +399,49 @@ func (g *pyGen) genStructMemberGetter(s *Struct, i int, f types.Object) {
 	g.gofile.Outdent()
 	g.gofile.Printf("}\n\n")
 
-	g.pybuild.Printf("mod.add_function('%s', retval('%s'), [param('%s', 'handle')])\n", cgoFn, ret.cpyname, PyHandle)
+	g.declFunc("mod.add_function", cgoFn, cpyRet, capiParam{PyHandle, "handle"})
+}
+
+// fieldSetSymbol returns the symbol of the values that the i'th field of
+// the struct is set from, or nil if the field is read-only.
+func fieldSetSymbol(s *Struct, i int, f types.Object) *symbol {
+	ret := s.pkg.syms.symtype(f.Type())
+	if isJSONField(s, i, f) {
+		ret = s.pkg.syms.jsonFieldSymbol(f)
+	}
+	if ret == nil || ret.isTextOnly() { // read-only
+		return nil
+	}
+	return ret
 }
 
-func (g *pyGen) genStructMemberSetter(s *Struct, i int, f types.Object) {
+// genStructMemberSetter generates the setter of the i'th field f of the
+// struct fs, as genStructMemberGetter does its getter.
+func (g *pyGen) genStructMemberSetter(s, fs *Struct, i int, f types.Object) {
 	pkgname := g.cfg.Name
-	ft := f.Type()
-	ret := current.symtype(ft)
+	ret := fieldSetSymbol(fs, i, f)
 	if ret == nil {
 		return
 	}
 
-	gname := f.Name()
-	if g.cfg.RenameCase {
-		gname = toSnakeCase(gname)
-	}
-
-	if newName, err := extractPythonNameFieldTag(gname, s.Struct().Tag(i)); err == nil {
-		gname = newName
-	}
+	gname := g.pyFieldName(fs, i, f)
 
 	cgoFn := fmt.Sprintf("%s_%s_Set", s.ID(), f.Name())
 
 	g.pywrap.Printf("@%s.setter\n", gname)
-	g.pywrap.Printf("def %[1]s(self, value):\n", gname)
+	g.pywrap.Printf("def %[1]s(self, value%[2]s)%[3]s:\n", gname, g.typeHint(": ", ret, ret.isArray()), g.typeHint(" -> ", nil, false))
 	g.pywrap.Indent()
+	if ret.isSignature() {
+		// any python callable, including a Go func value, is wrapped as a Go func
+		g.pywrap.Printf("if not callable(value):\n")
+		g.pywrap.Indent()
+		g.pywrap.Printf("raise TypeError(\"supplied argument type {t} is not callable\".format(t=type(value)))\n")
+		g.pywrap.Outdent()
+		g.pywrap.Printf("_%s.%s(self.handle, value)\n", pkgname, cgoFn)
+		g.pywrap.Outdent()
+		g.genStructMemberSetterGo(s, f, ret, cgoFn)
+		return
+	}
 	g.pywrap.Printf("if isinstance(value, go.GoClass):\n")
 	g.pywrap.Indent()
 	g.pywrap.Printf("_%s.%s(self.handle, value.handle)\n", pkgname, cgoFn)
@@ -264,29 +454,175 @@ func (g *pyGen) genStructMemberSetter(s *Struct, i int, f types.Object) {
 	if _, isNamed := utyp.(*types.Named); isNamed {
 		utyp = utyp.Underlying()
 	}
-	switch utyp.(type) {
-	case *types.Basic:
+	_, isArray := utyp.(*types.Array)
+	switch {
+	case ret.isBasic() && (!ret.isPointer() || isOptBasicPtr(ret.gotyp)): // including types converted by value, e.g., with -bytes
 		g.pywrap.Printf("_%s.%s(self.handle, value)\n", pkgname, cgoFn)
+	case isArray:
+		// arrays are copied by value, so any sequence of the right length is
+		// ok -- converted to a local, whose handle is released by its
+		// __del__ only once it is copied
+		g.pywrap.Printf("_v = %s(value)\n", ret.pyPkgId(g, g.pkg.pkg))
+		g.pywrap.Printf("_%s.%s(self.handle, _v.handle)\n", pkgname, cgoFn)
 	default:
 		g.pywrap.Printf("raise TypeError(\"supplied argument type {t} is not a go.GoClass\".format(t=type(value)))\n")
 	}
 	g.pywrap.Outdent()
 	g.pywrap.Outdent()
+	g.genStructMemberSetterGo(s, f, ret, cgoFn)
+}
+
+// genFieldFromPy generates the python code that converts the value vnm,
+// given for field f in the struct ctor, to the argument of the Go ctor, as
+// done by the setter of the field: gopy managed objects are passed by
+// handle, arrays are converted from any sequence, and other values are
+// passed as is to fields of basic types only.
+func (g *pyGen) genFieldFromPy(f types.Object, fsym *symbol, vnm string) {
+	g.pywrap.Printf("if isinstance(%[1]s, go.GoClass):\n", vnm)
+	g.pywrap.Indent()
+	g.pywrap.Printf("%[1]s = %[1]s.handle\n", vnm)
+	g.pywrap.Outdent()
+	_, isArray := f.Type().Underlying().(*types.Array)
+	switch {
+	case fsym.isBasic() && (!fsym.isPointer() || isOptBasicPtr(fsym.gotyp)):
+	case isArray:
+		g.pywrap.Printf("else:\n")
+		g.pywrap.Indent()
+		// kept alive until the ctor has copied it, see genStructMemberSetter
+		g.pywrap.Printf("_v%[1]s = %[2]s(%[1]s)\n", vnm, fsym.pyPkgId(g, g.pkg.pkg))
+		g.pywrap.Printf("%[1]s = _v%[1]s.handle\n", vnm)
+		g.pywrap.Outdent()
+	default:
+		g.pywrap.Printf("else:\n")
+		g.pywrap.Indent()
+		g.pywrap.Printf("raise TypeError(\"supplied argument type {t} is not a go.GoClass\".format(t=type(%s)))\n", vnm)
+		g.pywrap.Outdent()
+	}
+}
+
+// maxCTorFields is the maximum number of fields set by the Go ctor of
+// a struct, see genStructFieldsCTor, as flagged by the bits of an int64.
+const maxCTorFields = 63
+
+// ctorFields returns the indexes of the settable fields of the struct that
+// are set by its Go ctor, see genStructFieldsCTor, and of the rest, i.e.,
+// func fields, which are set from any python callable by their setter, and
+// any fields beyond maxCTorFields.
+func (g *pyGen) ctorFields(s *Struct) (batch, rest []int) {
+	for i := 0; i < s.Struct().NumFields(); i++ {
+		f := s.Struct().Field(i)
+		if g.pyFieldName(s, i, f) == "" {
+			continue
+		}
+		fsym := fieldSetSymbol(s, i, f)
+		switch {
+		case fsym == nil: // no setter
+		case fsym.isSignature() || len(batch) == maxCTorFields:
+			rest = append(rest, i)
+		default:
+			batch = append(batch, i)
+		}
+	}
+	return batch, rest
+}
+
+// genStructFieldsCTor generates the Go ctor of the struct that sets the
+// fields given by the python __init__, in one call: the i'th bit of _set
+// flags whether the i'th of the fields, see genStructInit, is given.
+func (g *pyGen) genStructFieldsCTor(s *Struct) {
+	batch, _ := g.ctorFields(s)
+	if len(batch) == 0 {
+		return
+	}
+	fields := make([]*types.Var, len(batch))
+	fsyms := make([]*symbol, len(batch))
+	for bi, i := range batch {
+		fields[bi] = s.Struct().Field(i)
+		fsyms[bi] = fieldSetSymbol(s, i, fields[bi])
+	}
+
+	ctNm := s.ID() + "_CTor_fields"
+	goArgs := []string{"_set C.longlong"}
+	pyArgs := []capiParam{{PyHandle, "_set"}}
+	for fi, fsym := range fsyms {
+		goArgs = append(goArgs, fmt.Sprintf("_f%d %s", fi, fsym.cgoname))
+		pyArgs = append(pyArgs, capiParam{fsym.cpyname, fmt.Sprintf("_f%d", fi)})
+	}
+	g.gofile.Printf("//export %s\n", ctNm)
+	g.gofile.Printf("func %s(%s) (rv CGoHandle) {\n", ctNm, strings.Join(goArgs, ", "))
+	g.gofile.Indent()
+	g.gofile.Printf("op := &%s{}\n", s.GoName())
+	for fi, f := range fields {
+		g.gofile.Printf("if _set&(1<<%d) != 0 {\n", fi)
+		g.gofile.Indent()
+		g.genGoFromPy("v", fsyms[fi], fmt.Sprintf("_f%d", fi))
+		g.gofile.Printf("op.%s = v\n", f.Name())
+		g.gofile.Outdent()
+		g.gofile.Printf("}\n")
+	}
+	g.gofile.Printf("return CGoHandle(handleFromPtr_%s(op))\n", s.ID())
+	g.gofile.Outdent()
+	g.gofile.Printf("}\n\n")
+
+	g.declFunc("add_checked_function", ctNm, PyHandle, pyArgs...)
+}
 
+// genStructMemberSetterGo generates the Go side of the setter for field f.
+func (g *pyGen) genStructMemberSetterGo(s *Struct, f types.Object, ret *symbol, cgoFn string) {
 	g.gofile.Printf("//export %s\n", cgoFn)
 	g.gofile.Printf("func %s(handle CGoHandle, val %s) {\n", cgoFn, ret.cgoname)
 	g.gofile.Indent()
 	g.gofile.Printf("op := ptrFromHandle_%s(handle)\n", s.ID())
-	if ret.py2go != "" {
-		g.gofile.Printf("op.%s = %s(val)%s", f.Name(), ret.py2go, ret.py2goParenEx)
-	} else {
-		g.gofile.Printf("op.%s = val", f.Name())
-	}
-	g.gofile.Printf("\n")
+	g.genGoFromPy("v", ret, "val")
+	g.genValueLock("op")
+	g.gofile.Printf("op.%s = v\n", f.Name())
 	g.gofile.Outdent()
 	g.gofile.Printf("}\n\n")
 
-	g.pybuild.Printf("mod.add_function('%s', None, [param('%s', 'handle'), param('%s', 'val')])\n", cgoFn, PyHandle, ret.cpyname)
+	g.declFunc("add_checked_function", cgoFn, "", capiParam{PyHandle, "handle"}, capiParam{ret.cpyname, "val"})
+}
+
+// genStructClasses registers the python classes of the structs of the
+// package with the Go side, so that interface{} values of them are returned
+// as their class, see valueGoToPy.
+func (g *pyGen) genStructClasses() {
+	for _, s := range g.pkg.structs {
+		g.pywrap.Printf("_%s.GoPyRegisterClass(%q, %s)\n", g.pypkgname, s.GoName(), s.obj.Name())
+	}
+}
+
+// genStructClassGo generates the Go function classifying the interface{}
+// values returned to python by their struct type, for all the packages.
+func (g *pyGen) genStructClassGo() {
+	var structs []*Struct
+	for _, p := range g.pkgs {
+		if p == goPackage {
+			continue
+		}
+		structs = append(structs, p.structs...)
+	}
+
+	g.gofile.Printf("\n// gopyClassOf returns the qualified Go name of the struct type that v is,\n")
+	g.gofile.Printf("// or points to, and a handle of the pointer to it.\n")
+	g.gofile.Printf("func gopyClassOf(v interface{}) (string, CGoHandle) {\n")
+	g.gofile.Indent()
+	if len(structs) > 0 {
+		g.gofile.Printf("switch e := v.(type) {\n")
+		for _, s := range structs {
+			g.gofile.Printf("case *%s:\n", s.GoName())
+			g.gofile.Indent()
+			g.gofile.Printf("return %q, %s(e)\n", s.GoName(), s.sym.go2py)
+			g.gofile.Outdent()
+			g.gofile.Printf("case %s:\n", s.GoName())
+			g.gofile.Indent()
+			g.gofile.Printf("return %q, %s(&e)\n", s.GoName(), s.sym.go2py)
+			g.gofile.Outdent()
+		}
+		g.gofile.Printf("}\n")
+	}
+	g.gofile.Printf("return \"\", -1\n")
+	g.gofile.Outdent()
+	g.gofile.Printf("}\n")
 }
 
 func (g *pyGen) genStructMethods(s *Struct) {
@@ -295,18 +631,135 @@ func (g *pyGen) genStructMethods(s *Struct) {
 	}
 }
 
+// restrictIface returns the interface name given in a gopy:restrict=Iface
+// directive in the doc, along with the doc stripped of it.
+func restrictIface(gdoc string) (string, string) {
+	const PythonRestrict = "gopy:restrict="
+	idx := strings.Index(gdoc, PythonRestrict)
+	if idx < 0 {
+		return "", gdoc
+	}
+	rest := gdoc[idx+len(PythonRestrict):]
+	end := strings.IndexAny(rest, " \t\n")
+	if end < 0 {
+		end = len(rest)
+	}
+	iname := rest[:end]
+	rest = strings.TrimPrefix(rest[end:], "\n")
+	return iname, gdoc[:idx] + rest
+}
+
+// restrictTo returns the interface name given for the struct in the
+// -restrict-to-interface option, if any.
+func (g *pyGen) restrictTo(s *Struct) string {
+	if g.cfg.RestrictTo == "" {
+		return ""
+	}
+	for _, pair := range strings.Split(g.cfg.RestrictTo, ",") {
+		pair = strings.TrimSpace(pair)
+		eq := strings.Index(pair, "=")
+		if eq < 0 {
+			continue
+		}
+		tnm := pair[:eq]
+		if tnm == s.GoName() || tnm == s.obj.Name() {
+			return pair[eq+1:]
+		}
+	}
+	return ""
+}
+
+// checkRestrictTo reports the entries of the -restrict-to-interface option
+// that are not a Type=Interface pair, or whose type is not a struct bound
+// in one of the packages, which would otherwise be silently ignored.
+func (g *pyGen) checkRestrictTo() {
+	if g.cfg.RestrictTo == "" {
+		return
+	}
+	for _, pair := range strings.Split(g.cfg.RestrictTo, ",") {
+		pair = strings.TrimSpace(pair)
+		eq := strings.Index(pair, "=")
+		if eq <= 0 || eq == len(pair)-1 {
+			g.err.Add(fmt.Errorf("gopy: invalid -restrict-to-interface entry %q: want Type=Interface", pair))
+			continue
+		}
+		tnm := pair[:eq]
+		found, other := false, false
+		for _, p := range g.pkgs {
+			for _, st := range p.structs {
+				if tnm == st.GoName() || tnm == st.obj.Name() {
+					found = true
+				}
+			}
+			if obj, ok := p.pkg.Scope().Lookup(tnm).(*types.TypeName); ok && obj.Exported() {
+				other = true
+			}
+		}
+		switch {
+		case found:
+		case other:
+			g.err.Add(fmt.Errorf("gopy: -restrict-to-interface: type %s is not a struct", tnm))
+		default:
+			g.err.Add(fmt.Errorf("gopy: -restrict-to-interface: unknown type %s", tnm))
+		}
+	}
+}
+
+// restrictMethods returns the methods of the struct that belong to the
+// method set of the named interface, which can be qualified by the name
+// or path of one of the packages imported by the struct's package.
+// Only the methods are restricted: the fields of the struct, and its
+// conversions (asdict, to_json, ...), are bound regardless.
+func (g *pyGen) restrictMethods(s *Struct, iname string) []*Func {
+	pkg := s.obj.Pkg()
+	nm := iname
+	if dot := strings.LastIndex(iname, "."); dot >= 0 {
+		ppath := iname[:dot]
+		nm = iname[dot+1:]
+		pkg = nil
+		for _, ip := range s.obj.Pkg().Imports() {
+			if ip.Path() == ppath || ip.Name() == ppath {
+				pkg = ip
+				break
+			}
+		}
+	}
+	var iface *types.Interface
+	if pkg != nil {
+		if obj := pkg.Scope().Lookup(nm); obj != nil {
+			iface, _ = obj.Type().Underlying().(*types.Interface)
+		}
+	}
+	if iface == nil {
+		g.err.Add(fmt.Errorf("gopy: could not find interface %q to restrict type %s to", iname, s.GoName()))
+		return s.meths
+	}
+	if !types.Implements(types.NewPointer(s.GoType()), iface) {
+		g.err.Add(fmt.Errorf("gopy: type %s does not implement interface %s it is restricted to", s.GoName(), iname))
+		return s.meths
+	}
+	var meths []*Func
+	for _, m := range s.meths {
+		if obj, _, _ := types.LookupFieldOrMethod(iface, false, nil, m.GoName()); obj != nil {
+			meths = append(meths, m)
+		}
+	}
+	return meths
+}
+
 //////////////////////////////////////////////////////////////////////////
 // Interface
 
 func (g *pyGen) genInterface(ifc *Interface) {
 	strNm := ifc.obj.Name()
+	_, idoc := isSynchronized(ifc.Doc())
 	g.pywrap.Printf(`
 # Python type for interface %[3]s
 class %[1]s(go.GoClass):
 	""%[2]q""
 `,
 		strNm,
-		ifc.Doc(),
+		idoc,
 		ifc.GoName(),
 	)
 	g.pywrap.Indent()
@@ -340,15 +793,15 @@ handle=A Go-side object is always initialized with an explicit handle=arg
 	g.pywrap.Outdent()
 
 	for _, m := range ifc.meths {
-		if !isStringer(m.obj) {
-			continue
+		if isStringer(m.obj) {
+			g.genStringer(ifc.GoName())
+		}
+		if isCloser(m.obj) {
+			g.genCloser()
 		}
-		g.pywrap.Printf("def __str__(self):\n")
-		g.pywrap.Indent()
-		g.pywrap.Printf("return self.String()\n")
-		g.pywrap.Outdent()
-		g.pywrap.Printf("\n")
 	}
+	g.genOperators(ifc.meths)
+	g.genBool(ifc.meths, false)
 }
 
 func (g *pyGen) genIfaceMethods(ifc *Interface) {
@@ -0,0 +1,505 @@
+// Copyright 2019 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bind
+
+import (
+	"bytes"
+	"fmt"
+	"go/types"
+	"sort"
+	"strings"
+)
+
+const (
+	// PyStubPreamble starts the .pyi type stub of the python wrapper of
+	// a package.  1 = name of package (outname), 2 = gencmd,
+	// 3 = specific package name, 4 = spec pkg path, 5 = imports
+	PyStubPreamble = `# type stub for package %[4]s within overall package %[1]s
+# File is generated by gopy. Do not edit.
+# %[2]s
+
+from typing import Any, AsyncIterator, Callable, Dict, Iterable, Iterator, List, Mapping, MutableMapping, MutableSequence, NewType, Optional, Sequence, Tuple, Type, TypeVar, Union, overload
+import concurrent.futures as _futures
+import datetime as _datetime
+%[5]s
+`
+
+	// GoPkgStubDefs are the stubs of the definitions of GoPkgDefs
+	GoPkgStubDefs = `
+class GoClass(object):
+    handle: int
+    def __init__(self) -> None: ...
+
+_E = TypeVar("_E", bound=BaseException)
+_T = TypeVar("_T")
+
+class GoError(RuntimeError):
+    exceptions: Tuple[BaseException, ...]
+    def is_(self, cls: Type[BaseException]) -> bool: ...
+    def as_(self, cls: Type[_E]) -> Optional[_E]: ...
+
+class GoPanic(RuntimeError):
+    trace: str
+    def __init__(self, msg: str, trace: str = ...) -> None: ...
+
+nil: GoClass
+
+def main() -> None: ...
+def Init() -> None: ...
+def setenv(key: str, value: str) -> None: ...
+
+class Context(object):
+    id: int
+    def cancel(self) -> None: ...
+    def __enter__(self) -> "Context": ...
+    def __exit__(self, *exc: Any) -> None: ...
+
+def with_deadline(dt: Union[_datetime.datetime, float]) -> Context: ...
+def with_timeout(seconds: float) -> Context: ...
+
+class Pool(_futures.Executor):
+    handle: int
+    def __init__(self, n: int) -> None: ...
+    def submit(self, fn: Callable[..., _T], *args: Any, **kwargs: Any) -> "_futures.Future[_T]": ...
+    def shutdown(self, wait: bool = ..., cancel_futures: bool = ...) -> None: ...
+`
+)
+
+// genPkgStub generates the PEP 561 .pyi type stub of the python wrapper of
+// the current package, for static type checkers such as mypy and pyright.
+// It declares the classes of its structs, interfaces, slices and maps, with
+// their fields and methods, along with its constants, variables and
+// functions.  Everything else, e.g., enums and channels, is resolved by the
+// module __getattr__, and typed as Any.
+func (g *pyGen) genPkgStub() {
+	g.pystub = &printer{buf: new(bytes.Buffer), indentEach: []byte("    ")}
+	g.stubImports = make(map[string]bool)
+	g.stubClasses = make(map[string]bool)
+
+	slices, maps := g.stubCollections()
+	for _, sym := range slices {
+		g.stubClasses[g.stubName(sym)] = true
+	}
+	for _, sym := range maps {
+		g.stubClasses[g.stubName(sym)] = true
+	}
+	if g.pkg == goPackage {
+		g.pystub.Printf("%s", GoPkgStubDefs)
+	} else {
+		for _, ifc := range g.pkg.ifaces {
+			g.stubClasses[ifc.obj.Name()] = true
+		}
+		for _, s := range g.pkg.structs {
+			g.stubClasses[s.obj.Name()] = true
+		}
+		g.genNamedBasicStubs()
+		for _, c := range g.pkg.consts {
+			if isPyCompatVar(c.sym) != nil || c.sym.isSignature() {
+				continue
+			}
+			g.pystub.Printf("%s: %s\n", c.GoName(), g.pyTypeHint(c.sym, false))
+		}
+		g.genVarStubs()
+		for _, ifc := range g.pkg.ifaces {
+			g.genIfaceStub(ifc)
+		}
+		for _, s := range g.pkg.structs {
+			g.genStructStub(s)
+		}
+		for _, v := range g.pkg.errorVars() {
+			g.pystub.Printf("\nclass %s(go.GoError): ...\n", v.Name())
+		}
+	}
+	for _, sym := range slices {
+		g.genSliceStub(sym, g.pkg.sliceOf(sym))
+	}
+	for _, sym := range maps {
+		g.genMapStub(sym, g.pkg.mapOf(sym))
+	}
+	if g.pkg != goPackage {
+		g.pystub.Printf("\n")
+		for _, s := range g.pkg.structs {
+			for _, ctor := range s.ctors {
+				g.genFuncStub(nil, ctor)
+			}
+		}
+		for _, f := range g.pkg.funcs {
+			g.genFuncStub(nil, f)
+		}
+	}
+	g.pystub.Printf("\ndef __getattr__(name: str) -> Any: ...\n")
+
+	var imps []string
+	if g.pkg != goPackage {
+		imps = append(imps, g.stubImport("go"))
+	}
+	var pkgs []string
+	for pkg := range g.stubImports {
+		pkgs = append(pkgs, pkg)
+	}
+	sort.Strings(pkgs)
+	for _, pkg := range pkgs {
+		imps = append(imps, g.stubImport(pkg))
+	}
+	g.stubImports, g.stubClasses = nil, nil
+	body := g.pystub.buf.Bytes()
+	g.pystub = &printer{buf: new(bytes.Buffer), indentEach: []byte("    ")}
+	g.pystub.Printf(PyStubPreamble, g.cfg.Name, g.cfg.Cmd, g.pkg.pkg.Name(), g.pkg.pkg.Path(), strings.Join(imps, ""))
+	g.pystub.buf.Write(body)
+	g.pystubs = append(g.pystubs, pyWrapOut{g.pkg.pkg.Name() + ".pyi", g.pystub})
+}
+
+// stubImport returns the import statement of the python module of a bound
+// package in a stub, as in the python wrappers, see genPyWrapPreamble.
+func (g *pyGen) stubImport(pkg string) string {
+	switch {
+	case pkg == "go" && g.mode == ModeExe:
+		return fmt.Sprintf("from %s import go\n", g.cfg.Name)
+	case g.mode == ModeGen || g.mode == ModeBuild:
+		if pkg == "go" && g.cfg.PkgPrefix != "" {
+			return fmt.Sprintf("from %s import go\n", g.cfg.PkgPrefix)
+		}
+		return fmt.Sprintf("import %s\n", pkg)
+	}
+	name := g.cfg.Name
+	if pkg == "go" && g.cfg.PkgPrefix != "" {
+		name = g.cfg.PkgPrefix + "." + name
+	}
+	return fmt.Sprintf("from %s import %s\n", name, pkg)
+}
+
+// stubCollections returns the symbols of the slices, arrays and maps whose
+// python classes are generated in the current package, see genAll and
+// genGoPkg.
+func (g *pyGen) stubCollections() (slices, maps []*symbol) {
+	add := func(sym *symbol) {
+		switch {
+		case sym.isPointer():
+		case sym.isSlice() || sym.isArray():
+			slices = append(slices, sym)
+		case sym.isMap():
+			maps = append(maps, sym)
+		}
+	}
+	if g.pkg == goPackage {
+		for _, n := range universe.names() {
+			sym := universe.sym(n)
+			if sym.gopkg != nil && sym.isType() && sym.gopkg.Path() == g.pkg.pkg.Path() {
+				add(sym)
+			}
+		}
+		return slices, maps
+	}
+	for _, n := range g.syms.names() {
+		sym := g.syms.sym(n)
+		if sym.gopkg.Path() == g.pkg.pkg.Path() && sym.isType() && !sym.isNamed() {
+			add(sym)
+		}
+	}
+	for _, s := range g.pkg.slices {
+		add(s.sym)
+	}
+	for _, m := range g.pkg.maps {
+		add(m.sym)
+	}
+	return slices, maps
+}
+
+// sliceOf returns the named slice of the package with the given symbol,
+// if any, for its methods.
+func (p *Package) sliceOf(sym *symbol) *Slice {
+	for _, s := range p.slices {
+		if s.sym == sym {
+			return s
+		}
+	}
+	return nil
+}
+
+// mapOf returns the named map of the package with the given symbol,
+// if any, for its methods.
+func (p *Package) mapOf(sym *symbol) *Map {
+	for _, m := range p.maps {
+		if m.sym == sym {
+			return m
+		}
+	}
+	return nil
+}
+
+// stubName returns the python name of the class of a type, qualified by its
+// module unless it is in the current one.
+func (g *pyGen) stubName(sym *symbol) string {
+	nm := sym.pyPkgId(g, g.pkg.pkg)
+	if g.pkg == goPackage {
+		nm = strings.TrimPrefix(nm, "go.")
+	}
+	return nm
+}
+
+// genNamedBasicStubs generates the stubs of the typing.NewType of the named
+// basic types, see genNamedBasics.
+func (g *pyGen) genNamedBasicStubs() {
+	if g.lang == 2 {
+		return
+	}
+	enums := make(map[string]bool)
+	for _, e := range g.pkg.enums {
+		enums[e.typ.Obj().Name()] = true
+	}
+	for _, n := range g.syms.names() {
+		sym := g.syms.sym(n)
+		if sym.gopkg.Path() != g.pkg.pkg.Path() || !sym.isType() || !sym.isNamedBasic() || enums[sym.goobj.Name()] {
+			continue
+		}
+		switch sym.pysig {
+		case "int", "float", "str", "bool", "complex":
+			g.pystub.Printf("%[1]s = NewType(%[1]q, %[2]s)\n", sym.goobj.Name(), sym.pysig)
+		}
+	}
+}
+
+// genVarStubs generates the stubs of the getter and setter functions of
+// the variables of the package, see genVar.
+func (g *pyGen) genVarStubs() {
+	for _, v := range g.pkg.vars {
+		if isPyCompatVar(v.sym) != nil || v.sym.isSignature() {
+			continue
+		}
+		get, set := v.Name(), "Set_"+v.Name()
+		if g.cfg.RenameCase {
+			get, set = toSnakeCase(get), toSnakeCase(set)
+		}
+		typ := g.pyTypeHint(v.sym, false)
+		g.pystub.Printf("def %s() -> %s: ...\n", get, typ)
+		if !v.sym.isArray() && !v.sym.isTextOnly() {
+			g.pystub.Printf("def %s(value: %s) -> None: ...\n", set, typ)
+		}
+	}
+}
+
+// genClassStubEnd generates the stubs of the context manager methods of
+// closers, and of the methods of a class.
+func (g *pyGen) genClassStubEnd(cls string, closer bool, sym *symbol, meths []*Func) {
+	if closer {
+		g.pystub.Printf("def __enter__(self) -> %s: ...\n", cls)
+		g.pystub.Printf("def __exit__(self, *args: Any) -> None: ...\n")
+	}
+	for _, m := range meths {
+		g.genFuncStub(sym, m)
+	}
+	g.pystub.Outdent()
+}
+
+// genIfaceStub generates the stub of the class of an interface.
+func (g *pyGen) genIfaceStub(ifc *Interface) {
+	nm := ifc.obj.Name()
+	g.pystub.Printf("\nclass %s(go.GoClass):\n", nm)
+	g.pystub.Indent()
+	g.pystub.Printf("def __init__(self, *args: Any, **kwargs: Any) -> None: ...\n")
+	closer := false
+	for _, m := range ifc.meths {
+		closer = closer || isCloser(m.obj)
+	}
+	g.genClassStubEnd(nm, closer, ifc.sym, ifc.meths)
+}
+
+// genStructStub generates the stub of the class of a struct, with its
+// fields as properties, which can be set to any value accepted by the
+// setter of the field where that is not the type of its getter.
+func (g *pyGen) genStructStub(s *Struct) {
+	nm := s.obj.Name()
+	base := "go.GoClass"
+	if emb := s.FirstEmbed(); emb != nil {
+		if cls := g.pyClassHint(emb); cls != "Any" {
+			base = cls
+		}
+	}
+	if s.prots&ProtoError != 0 {
+		base += ", go.GoError"
+	}
+	g.pystub.Printf("\nclass %s(%s):\n", nm, base)
+	g.pystub.Indent()
+
+	typ := s.Struct()
+	var kwargs []string
+	for i := 0; i < typ.NumFields(); i++ {
+		f := typ.Field(i)
+		if g.pyFieldName(s, i, f) == "" {
+			continue
+		}
+		kwargs = append(kwargs, g.genFieldStub(s, i, f))
+	}
+	for _, pf := range g.promotedFields(s) {
+		g.genFieldStub(pf.s, pf.i, pf.f)
+	}
+	kwargs = append(kwargs, "handle: int = ...")
+	g.pystub.Printf("def __init__(self, *args: Any, %s) -> None: ...\n", strings.Join(kwargs, ", "))
+	g.pystub.Printf("def asdict(self) -> Dict[str, Any]: ...\n")
+	g.pystub.Printf("@classmethod\n")
+	g.pystub.Printf("def from_dict(cls, d: Mapping[str, Any]) -> %s: ...\n", nm)
+	g.pystub.Printf("def to_json(self) -> str: ...\n")
+	g.pystub.Printf("@classmethod\n")
+	g.pystub.Printf("def from_json(cls, s: Union[str, bytes]) -> %s: ...\n", nm)
+	g.genClassStubEnd(nm, s.prots&ProtoCloser != 0, s.sym, s.meths)
+}
+
+// genFieldStub generates the stub of the i'th field f of the struct s, and
+// returns the keyword arg of its __init__.
+func (g *pyGen) genFieldStub(s *Struct, i int, f *types.Var) string {
+	fnm := g.pyFieldName(s, i, f)
+	fsym := g.syms.symtype(f.Type())
+	if isJSONField(s, i, f) {
+		fsym = g.syms.jsonFieldSymbol(f)
+	}
+	get := g.pyTypeHint(fsym, false)
+	set := ""
+	if ssym := fieldSetSymbol(s, i, f); ssym != nil {
+		set = g.pyTypeHint(ssym, ssym.isArray())
+	}
+	if set == get {
+		g.pystub.Printf("%s: %s\n", fnm, get)
+	} else {
+		g.pystub.Printf("@property\n")
+		g.pystub.Printf("def %s(self) -> %s: ...\n", fnm, get)
+		if set != "" {
+			g.pystub.Printf("@%s.setter\n", fnm)
+			g.pystub.Printf("def %s(self, value: %s) -> None: ...\n", fnm, set)
+		}
+	}
+	return fmt.Sprintf("%s: %s = ...", fnm, g.pyTypeHint(fsym, true))
+}
+
+// genSliceStub generates the stub of the class of a slice or an array, as
+// a MutableSequence or a Sequence of its elements.
+func (g *pyGen) genSliceStub(slc *symbol, slob *Slice) {
+	nm := g.stubName(slc)
+	var esym *symbol
+	abc := "Sequence"
+	switch typ := slc.GoType().Underlying().(type) {
+	case *types.Slice:
+		esym = g.syms.symtype(typ.Elem())
+		abc = "MutableSequence"
+	case *types.Array:
+		esym = g.syms.symtype(typ.Elem())
+	}
+	elem, param := g.pyTypeHint(esym, false), g.pyTypeHint(esym, true)
+	gocl := "go."
+	if g.pkg == goPackage {
+		gocl = ""
+	}
+	g.pystub.Printf("\nclass %s(%sGoClass, %s[%s]):\n", nm, gocl, abc, elem)
+	g.pystub.Indent()
+	g.pystub.Printf("def __init__(self, *args: Any, **kwargs: Any) -> None: ...\n")
+	g.pystub.Printf("def __len__(self) -> int: ...\n")
+	g.pystub.Printf("def __iter__(self) -> Iterator[%s]: ...\n", elem)
+	g.pystub.Printf("@overload\n")
+	g.pystub.Printf("def __getitem__(self, key: int) -> %s: ...\n", elem)
+	g.pystub.Printf("@overload\n")
+	if abc == "MutableSequence" {
+		g.pystub.Printf("def __getitem__(self, key: slice) -> %s: ...\n", nm)
+		g.pystub.Printf("@overload\n")
+		g.pystub.Printf("def __setitem__(self, key: int, value: %s) -> None: ...\n", param)
+		g.pystub.Printf("@overload\n")
+		g.pystub.Printf("def __setitem__(self, key: slice, value: Iterable[%s]) -> None: ...\n", param)
+		g.pystub.Printf("def __delitem__(self, key: Union[int, slice]) -> None: ...\n")
+		g.pystub.Printf("def insert(self, index: int, value: %s) -> None: ...\n", param)
+		if less, iface := sliceSortLess(slc, esym); less != "" || iface {
+			g.pystub.Printf("def sort(self, key: Optional[Callable[[%s], Any]] = ..., reverse: bool = ...) -> None: ...\n", elem)
+		}
+	} else {
+		g.pystub.Printf("def __getitem__(self, key: slice) -> List[%s]: ...\n", elem)
+		g.pystub.Printf("def __setitem__(self, key: int, value: %s) -> None: ...\n", param)
+	}
+	if slob == nil {
+		g.pystub.Outdent()
+		return
+	}
+	g.genClassStubEnd(nm, slob.prots&ProtoCloser != 0, slob.sym, slob.meths)
+}
+
+// genMapStub generates the stub of the class of a map, as a MutableMapping.
+func (g *pyGen) genMapStub(mp *symbol, mpob *Map) {
+	nm := g.stubName(mp)
+	typ := mp.GoType().Underlying().(*types.Map)
+	ksym, esym := g.syms.symtype(typ.Key()), g.syms.symtype(typ.Elem())
+	key, elem := g.pyTypeHint(ksym, false), g.pyTypeHint(esym, false)
+	gocl := "go."
+	if g.pkg == goPackage {
+		gocl = ""
+	}
+	g.pystub.Printf("\nclass %s(%sGoClass, MutableMapping[%s, %s]):\n", nm, gocl, key, elem)
+	g.pystub.Indent()
+	g.pystub.Printf("def __init__(self, *args: Any, **kwargs: Any) -> None: ...\n")
+	g.pystub.Printf("def __len__(self) -> int: ...\n")
+	g.pystub.Printf("def __iter__(self) -> Iterator[%s]: ...\n", key)
+	g.pystub.Printf("def __getitem__(self, key: %s) -> %s: ...\n", key, elem)
+	g.pystub.Printf("def __setitem__(self, key: %s, value: %s) -> None: ...\n", key, g.pyTypeHint(esym, true))
+	g.pystub.Printf("def __delitem__(self, key: %s) -> None: ...\n", key)
+	g.pystub.Printf("def iteritems(self) -> Iterator[Tuple[%s, %s]]: ...\n", key, elem)
+	if mpob == nil {
+		g.pystub.Outdent()
+		return
+	}
+	g.genClassStubEnd(nm, mpob.prots&ProtoCloser != 0, mpob.sym, mpob.meths)
+}
+
+// genFuncStub generates the stub of a function, or of a method of the given
+// type, for the functions that genFuncSig wraps.
+func (g *pyGen) genFuncStub(sym *symbol, fsym *Func) {
+	sig := fsym.sig
+	if sig == nil {
+		return
+	}
+	gname, gdoc, err := g.pyFuncName(fsym)
+	if err != nil {
+		return
+	}
+	ifchandle, _ := isIfaceHandle(gdoc)
+	args := sig.Params()
+	res := sig.Results()
+	nres := len(res)
+	if nres > 2 || (nres == 2 && !fsym.err) || (nres > 0 && !g.isFuncValueType(res[0].sym)) {
+		return
+	}
+
+	var params []string
+	if sym != nil {
+		params = append(params, "self")
+	}
+	variadic := ""
+	for i, arg := range args {
+		if g.syms.symtype(arg.GoType()) == nil {
+			return
+		}
+		if i == 0 && fsym.hasctx {
+			continue
+		}
+		if i == len(args)-1 && fsym.isVariadic {
+			variadic = "*args: " + g.variadicTypeHint(arg)
+			continue
+		}
+		params = append(params, fmt.Sprintf("%s: %s", pySafeArg(arg.Name(), i), g.argTypeHint(arg.sym, ifchandle)))
+	}
+	if nres == 0 {
+		params = append(params, "goRun: bool = ...")
+	}
+	if variadic != "" {
+		params = append(params, variadic)
+	}
+	if g.hasTimeout(fsym) {
+		params = append(params, "timeout: Optional[float] = ...")
+	}
+	if g.hasCtxKw(fsym) {
+		params = append(params, "ctx: Optional[go.Context] = ...")
+	}
+
+	ret := g.retTypeHint(fsym)
+	def := "def"
+	if g.isAsync(fsym) && !strings.HasPrefix(ret, "AsyncIterator[") {
+		def = "async def"
+	}
+	g.pystub.Printf("%s %s(%s) -> %s: ...\n", def, gname, strings.Join(params, ", "), ret)
+}
@@ -0,0 +1,160 @@
+// Copyright 2019 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bind
+
+import (
+	"fmt"
+	"go/token"
+	"go/types"
+)
+
+const (
+	// goTextDefs are the Go conversions of otherwise unsupported types to and
+	// from python str, through their encoding.TextMarshaler, fmt.Stringer and
+	// encoding.TextUnmarshaler implementations, when generating with -text
+	goTextDefs = `
+// textGoToPy converts a Go value to a python str, through its
+// encoding.TextMarshaler implementation, or else its fmt.Stringer one,
+// also looking at the methods of its pointer -- nil is converted to None
+func textGoToPy(v interface{}) *C.PyObject {
+	rv := reflect.ValueOf(v)
+	if !rv.IsValid() || (rv.Kind() == reflect.Ptr && rv.IsNil()) {
+		return C.gopy_none()
+	}
+	if rv.Kind() != reflect.Ptr {
+		pv := reflect.New(rv.Type())
+		pv.Elem().Set(rv)
+		v = pv.Interface()
+	}
+	var s string
+	switch tv := v.(type) {
+	case encoding.TextMarshaler:
+		b, err := tv.MarshalText()
+		if err != nil {
+			gopySetError(C.PyExc_ValueError, err.Error())
+			return nil
+		}
+		s = string(b)
+	case fmt.Stringer:
+		s = tv.String()
+	}
+	cs := C.CString(s)
+	defer C.free(unsafe.Pointer(cs))
+	return C.gopy_build_string(cs)
+}
+
+// textPyToGo converts a python str to a new Go value of the type pointed
+// to by the given pointer type, through its encoding.TextUnmarshaler
+// implementation, returning the pointer -- it is zero on errors, which are
+// raised after the call
+func textPyToGo(pt reflect.Type, o *C.PyObject) interface{} {
+	pv := reflect.New(pt.Elem())
+	cs := C.gopy_str(o)
+	if cs == nil {
+		return pv.Interface() // python error is set
+	}
+	if err := pv.Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(C.GoString(cs))); err != nil {
+		gopySetError(C.PyExc_ValueError, err.Error())
+		return reflect.New(pt.Elem()).Interface()
+	}
+	return pv.Interface()
+}
+`
+)
+
+// textGoToPy is the go2py conversion of the types converted to python str
+// with -text, which marks their symbols.
+const textGoToPy = "textGoToPy"
+
+// textIface returns an interface type with the given method, e.g., to
+// check for an implementation of encoding.TextMarshaler.
+func textIface(name string, params, results []types.Type) *types.Interface {
+	vars := func(ts []types.Type) *types.Tuple {
+		vs := make([]*types.Var, len(ts))
+		for i, t := range ts {
+			vs[i] = types.NewVar(token.NoPos, nil, "", t)
+		}
+		return types.NewTuple(vs...)
+	}
+	sig := types.NewSignatureType(nil, nil, nil, vars(params), vars(results), false)
+	m := types.NewFunc(token.NoPos, nil, name, sig)
+	return types.NewInterfaceType([]*types.Func{m}, nil).Complete()
+}
+
+var (
+	bytesType = types.NewSlice(types.Typ[types.Byte])
+	errorType = types.Universe.Lookup("error").Type()
+
+	textMarshaler   = textIface("MarshalText", nil, []types.Type{bytesType, errorType})
+	stringer        = textIface("String", nil, []types.Type{types.Typ[types.String]})
+	textUnmarshaler = textIface("UnmarshalText", []types.Type{bytesType}, []types.Type{errorType})
+)
+
+// textImplements returns true if the type, or a pointer to it, implements
+// the given interface.
+func textImplements(t types.Type, iface *types.Interface) bool {
+	if types.Implements(t, iface) {
+		return true
+	}
+	if _, isPtr := t.(*types.Pointer); isPtr {
+		return false
+	}
+	return types.Implements(types.NewPointer(t), iface)
+}
+
+// textSymbol returns the symbol of an otherwise unsupported type that is
+// converted to a python str with -text, through its encoding.TextMarshaler
+// or fmt.Stringer implementation, or nil if it has neither.  It is converted
+// back from a str only if it is an exported type whose pointer implements
+// encoding.TextUnmarshaler -- otherwise it is only bound as a result or
+// field getter, see isTextOnly.
+func (sym *symtab) textSymbol(obj types.Object, t types.Type) *symbol {
+	if !textImplements(t, textMarshaler) && !textImplements(t, stringer) {
+		return nil
+	}
+	n, id, pkg := sym.typeNamePkg(t)
+	tsym := &symbol{
+		gopkg:   pkg,
+		goobj:   obj,
+		gotyp:   t,
+		kind:    skType | skBasic,
+		id:      id,
+		goname:  n,
+		cgoname: "*C.PyObject",
+		cpyname: "PyObject*",
+		pysig:   "str",
+		go2py:   textGoToPy,
+		zval:    "nil",
+	}
+	et := t
+	deref := "*"
+	if pt, isPtr := t.(*types.Pointer); isPtr {
+		et = pt.Elem()
+		deref = ""
+	}
+	ntyp, isNamed := et.(*types.Named)
+	if !isNamed || !ntyp.Obj().Exported() {
+		return tsym
+	}
+	ptn := "*" + sym.typeGoName(et)
+	if types.Implements(types.NewPointer(et), textUnmarshaler) {
+		tsym.py2go = fmt.Sprintf("%stextPyToGo(reflect.TypeOf((%s)(nil)), ", deref, ptn)
+		tsym.py2goParenEx = ").(" + ptn + ")"
+	}
+	return tsym
+}
+
+// isText returns true if the symbol is converted to a python str with -text,
+// see textSymbol.
+func (s *symbol) isText() bool {
+	return s.go2py == textGoToPy
+}
+
+// isTextOnly returns true if the symbol is converted to a python str with
+// -text, but cannot be converted back, so that it is not supported as
+// a parameter or settable field.
+func (s *symbol) isTextOnly() bool {
+	return s.isText() && s.py2go == ""
+}
@@ -4,6 +4,11 @@
 
 package bind
 
+import (
+	"go/types"
+	"strings"
+)
+
 // extTypes = these are types external to any targeted packages
 // pyWrapOnly = only generate python wrapper code, not go code
 func (g *pyGen) genType(sym *symbol, extTypes, pyWrapOnly bool) {
@@ -14,13 +19,22 @@ func (g *pyGen) genType(sym *symbol, extTypes, pyWrapOnly bool) {
 		return
 	}
 
+	if isOptBasicPtr(sym.gotyp) { // converted by value, see optGoToPy
+		return
+	}
+
 	if sym.isNamedBasic() {
 		// TODO: could have methods!
 		return
 	}
 
-	// TODO: not handling yet:
 	if sym.isSignature() {
+		if !pyWrapOnly {
+			g.genTypeCallback(sym)
+		}
+		if !extTypes {
+			g.genFuncClass(sym)
+		}
 		return
 	}
 
@@ -28,7 +42,7 @@ func (g *pyGen) genType(sym *symbol, extTypes, pyWrapOnly bool) {
 		switch {
 		case sym.isPointer() || sym.isInterface():
 			g.genTypeHandlePtr(sym)
-		case sym.isSlice() || sym.isMap() || sym.isArray():
+		case sym.isSlice() || sym.isMap() || sym.isArray() || sym.isChan():
 			g.genTypeHandleImplPtr(sym)
 		default:
 			g.genTypeHandle(sym)
@@ -40,6 +54,8 @@ func (g *pyGen) genType(sym *symbol, extTypes, pyWrapOnly bool) {
 			g.genSlice(sym, extTypes, pyWrapOnly, nil)
 		} else if sym.isMap() {
 			g.genMap(sym, extTypes, pyWrapOnly, nil)
+		} else if sym.isChan() {
+			g.genChan(sym, extTypes, pyWrapOnly)
 		} else if sym.isInterface() || sym.isStruct() {
 			if pyWrapOnly {
 				g.genExtClass(sym)
@@ -47,15 +63,246 @@ func (g *pyGen) genType(sym *symbol, extTypes, pyWrapOnly bool) {
 		}
 	} else {
 		if g.pkg == goPackage || !sym.isNamed() { // only named types are generated separately
-			if sym.isSlice() {
+			if sym.isSlice() || sym.isArray() {
 				g.genSlice(sym, extTypes, pyWrapOnly, nil)
 			} else if sym.isMap() {
 				g.genMap(sym, extTypes, pyWrapOnly, nil)
+			} else if sym.isChan() {
+				g.genChan(sym, extTypes, pyWrapOnly)
+			} else if sym.isPointer() && g.pkg == goPackage { // opaque, e.g., unsafe.Pointer
+				g.genExtClass(sym)
 			}
 		}
-		if sym.isArray() {
-			g.genSlice(sym, extTypes, pyWrapOnly, nil)
+	}
+}
+
+// genStringer generates the __str__ and __repr__ of the python class of
+// a type implementing fmt.Stringer, from its String method, with the
+// given qualified name and the handle of the object in __repr__.
+func (g *pyGen) genStringer(qNm string) {
+	g.pywrap.Printf("def __str__(self):\n")
+	g.pywrap.Indent()
+	g.pywrap.Printf("return self.String()\n")
+	g.pywrap.Outdent()
+	g.pywrap.Printf("\n")
+	g.pywrap.Printf("def __repr__(self):\n")
+	g.pywrap.Indent()
+	g.pywrap.Printf("return '%s(handle=' + str(self.handle) + ', ' + repr(self.String()) + ')'\n", qNm)
+	g.pywrap.Outdent()
+	g.pywrap.Printf("\n")
+}
+
+// genCloser generates the __enter__ and __exit__ of the python class of
+// a type implementing io.Closer, so that its Close method is called at the
+// end of a with statement.
+func (g *pyGen) genCloser() {
+	g.pywrap.Printf("def __enter__(self):\n")
+	g.pywrap.Indent()
+	g.pywrap.Printf("return self\n")
+	g.pywrap.Outdent()
+	g.pywrap.Printf("def __exit__(self, exc_type, exc_value, traceback):\n")
+	g.pywrap.Indent()
+	g.pywrap.Printf("self.Close()\n")
+	g.pywrap.Outdent()
+}
+
+// genBool generates the __bool__ of a python class wrapping a Go value by
+// handle, which is False for a nil handle, and otherwise calls the IsZero()
+// bool method of the type, if it has one, or else compares the length to 0,
+// if hasLen, e.g., for slices and maps.
+func (g *pyGen) genBool(meths []*Func, hasLen bool) {
+	g.pywrap.Printf("def __bool__(self):\n")
+	g.pywrap.Indent()
+	g.pywrap.Printf("if self.handle < 1:\n")
+	g.pywrap.Indent()
+	g.pywrap.Printf("return False\n")
+	g.pywrap.Outdent()
+	isZero := ""
+	for _, m := range meths {
+		if isZeroer(m.obj) {
+			isZero, _, _ = g.pyFuncName(m)
+			break
+		}
+	}
+	switch {
+	case isZero != "":
+		g.pywrap.Printf("return not self.%s()\n", isZero)
+	case hasLen:
+		g.pywrap.Printf("return len(self) > 0\n")
+	default:
+		g.pywrap.Printf("return True\n")
+	}
+	g.pywrap.Outdent()
+	if g.lang == 2 {
+		g.pywrap.Printf("__nonzero__ = __bool__\n")
+	}
+}
+
+// genCopy generates the __copy__ and __deepcopy__ of a python class wrapping
+// a Go value by handle: a shallow copy shares the handle, while a deep copy
+// wraps the copy returned by the given python function of the Go side, see
+// genDeepCopyGo.  The buffer views pinned by slices, if pins, are not copied.
+func (g *pyGen) genCopy(deepFn string, pins bool) {
+	g.pywrap.Printf("def __copy__(self):\n")
+	g.pywrap.Indent()
+	g.pywrap.Printf("rv = type(self).__new__(type(self))\n")
+	g.pywrap.Printf("rv.__dict__.update(self.__dict__)\n")
+	if pins {
+		g.pywrap.Printf("rv._pins = {}\n")
+	}
+	g.pywrap.Printf("_%s.IncRef(rv.handle)\n", g.pypkgname)
+	g.pywrap.Printf("return rv\n")
+	g.pywrap.Outdent()
+	g.pywrap.Printf("def __deepcopy__(self, memo):\n")
+	g.pywrap.Indent()
+	g.pywrap.Printf("import copy\n")
+	g.pywrap.Printf("rv = type(self).__new__(type(self))\n")
+	g.pywrap.Printf("memo[id(self)] = rv\n")
+	g.pywrap.Printf("for k, v in self.__dict__.items():\n")
+	g.pywrap.Indent()
+	g.pywrap.Printf("if k not in ('handle', '_pins'):\n")
+	g.pywrap.Indent()
+	g.pywrap.Printf("rv.__dict__[k] = copy.deepcopy(v, memo)\n")
+	g.pywrap.Outdent()
+	g.pywrap.Outdent()
+	if pins {
+		g.pywrap.Printf("rv._pins = {}\n")
+	}
+	g.pywrap.Printf("rv.handle = %s(self.handle)\n", deepFn)
+	g.pywrap.Printf("_%s.IncRef(rv.handle)\n", g.pypkgname)
+	g.pywrap.Printf("return rv\n")
+	g.pywrap.Outdent()
+}
+
+// genDeepCopyGo generates the Go side of the __deepcopy__ of the python class
+// of a type wrapped by handle, with the given id and Go name, which returns
+// the handle of a deep copy of the value of the given handle.
+func (g *pyGen) genDeepCopyGo(id, goname string) {
+	g.gofile.Printf("//export %s_deepcopy\n", id)
+	g.gofile.Printf("func %s_deepcopy(handle CGoHandle) CGoHandle {\n", id)
+	g.gofile.Indent()
+	g.gofile.Printf("return CGoHandle(handleFromPtr_%s(gopyDeepCopy(ptrFromHandle_%s(handle)).(*%s)))\n", id, id, goname)
+	g.gofile.Outdent()
+	g.gofile.Printf("}\n\n")
+
+	g.declFunc("mod.add_function", id+"_deepcopy", PyHandle, capiParam{PyHandle, "handle"})
+}
+
+// genPickle generates the __reduce__ of a python class wrapping a Go value by
+// handle, which pickles the value encoded with encoding/gob, along with the
+// python attributes of the object, see genPickleGo.
+func (g *pyGen) genPickle(id string) {
+	if g.lang == 2 {
+		return // bound classmethods cannot be pickled
+	}
+	g.pywrap.Printf("def __reduce__(self):\n")
+	g.pywrap.Indent()
+	g.pywrap.Printf("state = dict(self.__dict__)\n")
+	g.pywrap.Printf("del state['handle']\n")
+	g.pywrap.Printf("return (type(self)._unpickle, (_%s.%s_gob_encode(self.handle),), state or None)\n", g.pypkgname, id)
+	g.pywrap.Outdent()
+	g.pywrap.Printf("@classmethod\n")
+	g.pywrap.Printf("def _unpickle(cls, data):\n")
+	g.pywrap.Indent()
+	g.pywrap.Printf("rv = cls.__new__(cls)\n")
+	g.pywrap.Printf("rv.handle = _%s.%s_gob_decode(data)\n", g.pypkgname, id)
+	g.pywrap.Printf("_%s.IncRef(rv.handle)\n", g.pypkgname)
+	g.pywrap.Printf("return rv\n")
+	g.pywrap.Outdent()
+}
+
+// genPickleGo generates the Go side of the __reduce__ of the python class of
+// the given id, wrapping a *goname, see genPickle.
+func (g *pyGen) genPickleGo(id, goname string) {
+	if g.lang == 2 {
+		return
+	}
+	g.gofile.Printf("//export %s_gob_encode\n", id)
+	g.gofile.Printf("func %s_gob_encode(handle CGoHandle) *C.PyObject {\n", id)
+	g.gofile.Indent()
+	g.gofile.Printf("return gopyGobEncode(ptrFromHandle_%s(handle))\n", id)
+	g.gofile.Outdent()
+	g.gofile.Printf("}\n\n")
+
+	g.declFunc("add_checked_function", id+"_gob_encode", "PyObject*", capiParam{PyHandle, "handle"})
+
+	g.gofile.Printf("//export %s_gob_decode\n", id)
+	g.gofile.Printf("func %s_gob_decode(o *C.PyObject) CGoHandle {\n", id)
+	g.gofile.Indent()
+	g.gofile.Printf("p := new(%s)\n", goname)
+	g.gofile.Printf("if !gopyGobDecode(o, p) {\n")
+	g.gofile.Indent()
+	g.gofile.Printf("return -1 // python error is set\n")
+	g.gofile.Outdent()
+	g.gofile.Printf("}\n")
+	g.gofile.Printf("return CGoHandle(handleFromPtr_%s(p))\n", id)
+	g.gofile.Outdent()
+	g.gofile.Printf("}\n\n")
+
+	g.declFunc("add_checked_function", id+"_gob_decode", PyHandle, capiParam{"PyObject*", "o"})
+}
+
+// genNamedBasics generates a typing.NewType for each named basic type of
+// the package, e.g., type Celsius float64, for use in type hints: values of
+// these types are passed to and from python as plain values of the basic
+// type.  Types with consts are named by their Enum instead.
+func (g *pyGen) genNamedBasics() {
+	if g.lang == 2 {
+		return // no typing module
+	}
+	enums := make(map[string]bool)
+	for _, e := range g.pkg.enums {
+		enums[e.typ.Obj().Name()] = true
+	}
+	var nbs []*symbol
+	for _, n := range g.syms.names() {
+		sym := g.syms.sym(n)
+		if sym.gopkg.Path() != g.pkg.pkg.Path() || !sym.isType() || !sym.isNamedBasic() {
+			continue
 		}
+		switch sym.pysig {
+		case "int", "float", "str", "bool", "complex":
+		default:
+			continue
+		}
+		if enums[sym.goobj.Name()] {
+			continue
+		}
+		nbs = append(nbs, sym)
+	}
+	if len(nbs) == 0 {
+		return
+	}
+	g.pywrap.Printf("\n\n#---- Named basic types: plain python values, typed for hints ---\n")
+	g.pywrap.Printf("import typing\n\n")
+	for _, sym := range nbs {
+		g.pywrap.Printf("%[1]s = typing.NewType(%[1]q, %[2]s)\n", sym.goobj.Name(), sym.pysig)
+	}
+}
+
+// genAliases generates a python name for each type alias of the package,
+// referring to the class of its target, e.g., Pt = Point for type Pt = Point.
+// Aliases of basic types refer to the corresponding python type.
+func (g *pyGen) genAliases() {
+	for _, tn := range g.pkg.aliases {
+		sym := g.syms.symtype(tn.Type())
+		if sym == nil {
+			continue // target not supported
+		}
+		var pynm string
+		switch {
+		case sym.isBasic():
+			switch sym.pysig {
+			case "int", "float", "str", "bool", "complex":
+				pynm = sym.pysig
+			}
+		case sym.gopkg != nil:
+			pynm = sym.pyPkgId(g, g.pkg.pkg)
+		}
+		if pynm == "" || pynm == tn.Name() {
+			continue
+		}
+		g.pywrap.Printf("%s = %s\n", tn.Name(), pynm)
 	}
 }
 
@@ -82,7 +329,7 @@ func (g *pyGen) genTypeHandlePtr(sym *symbol) {
 	g.gofile.Printf("}\n")
 	g.gofile.Printf("func %s(p interface{})%s CGoHandle {\n", sym.go2py, sym.go2pyParenEx)
 	g.gofile.Indent()
-	g.gofile.Printf("return CGoHandle(gopyh.Register(\"%s\", p))\n", gonm)
+	g.gofile.Printf("return CGoHandle(gopyh.Register(%q, p))\n", gonm)
 	g.gofile.Outdent()
 	g.gofile.Printf("}\n")
 }
@@ -124,7 +371,93 @@ func (g *pyGen) genTypeHandleImplPtr(sym *symbol) {
 	g.gofile.Printf("}\n")
 	g.gofile.Printf("func %s(p interface{})%s CGoHandle {\n", sym.go2py, sym.go2pyParenEx)
 	g.gofile.Indent()
-	g.gofile.Printf("return CGoHandle(gopyh.Register(\"%s\", p))\n", gonm)
+	g.gofile.Printf("return CGoHandle(gopyh.Register(%q, p))\n", gonm)
+	g.gofile.Outdent()
+	g.gofile.Printf("}\n")
+}
+
+// genTypeCallback generates the converter from a python callable to a Go
+// func of the given signature type: the returned func holds a reference
+// to the callable, and calls it with the GIL held, converting the args to
+// python and the result back to Go.  A python exception raised by the
+// callable is returned as the error result when the signature has one, and
+// panics otherwise, to be raised back by the wrapper, see gopyRecover.
+func (g *pyGen) genTypeCallback(sym *symbol) {
+	sig := sym.gotyp.Underlying().(*types.Signature)
+	args := sig.Params()
+	nargs := args.Len()
+	hasErr := sig.Results().Len() > 0 && isErrorType(sig.Results().At(sig.Results().Len()-1).Type())
+	ret, err := g.syms.callbackResult(sig)
+	if err != nil {
+		return
+	}
+
+	params := make([]string, nargs)
+	for i := 0; i < nargs; i++ {
+		v := args.At(i)
+		params[i] = pySafeArg(v.Name(), i) + " " + g.syms.typeGoName(v.Type())
+	}
+	var results []string
+	if ret != nil {
+		results = append(results, "_fcrv "+g.syms.typeGoName(ret))
+	}
+	if hasErr {
+		results = append(results, "_fcerr error")
+	}
+
+	g.gofile.Printf("\n// %s returns a Go func of type %s that calls the given python callable\n", sym.py2go, sym.goname)
+	g.gofile.Printf("func %s(_fun_arg *C.PyObject) %s {\n", sym.py2go, sym.goname)
+	g.gofile.Indent()
+	g.gofile.Printf("_cb := newPyCallback(_fun_arg)\n")
+	g.gofile.Printf("return func(%s) (%s) {\n", strings.Join(params, ", "), strings.Join(results, ", "))
+	g.gofile.Indent()
+	g.gofile.Printf("defer gopyEnter(_cb.interp)()\n")
+	g.gofile.Printf("if C.PyCallable_Check(_cb.obj) == 0 {\n")
+	g.gofile.Indent()
+	g.gofile.Printf("return\n")
+	g.gofile.Outdent()
+	g.gofile.Printf("}\n")
+	if nargs > 0 {
+		bstr, _ := g.syms.buildTuple(args, "_fcargs", "_fun_arg")
+		g.gofile.Printf("%s", bstr)
+		g.gofile.Printf("_fcret := C.PyObject_CallObject(_cb.obj, _fcargs)\n")
+		g.gofile.Printf("C.gopy_decref(_fcargs)\n")
+	} else {
+		g.gofile.Printf("_fcret := C.PyObject_CallObject(_cb.obj, nil)\n")
+	}
+	g.gofile.Printf("if _fcret != nil {\n")
+	g.gofile.Indent()
+	g.gofile.Printf("defer C.gopy_decref(_fcret)\n")
+	if ret != nil {
+		rsym := g.syms.symtype(ret)
+		cvt, _ := g.syms.pyObjectToGo(ret, rsym, "_fcret")
+		g.gofile.Printf("_fcrv = %s\n", cvt)
+	}
+	g.gofile.Outdent()
+	g.gofile.Printf("}\n")
+	g.gofile.Printf("if C.PyErr_Occurred() != nil {\n")
+	g.gofile.Indent()
+	if hasErr {
+		g.gofile.Printf("_fcerr = pyErrToGo()\n")
+	} else {
+		// the func may be called from any goroutine, where a panic could not
+		// be recovered: the exception is reported, and the zero values returned
+		g.gofile.Printf("C.PyErr_WriteUnraisable(_cb.obj)\n")
+		if ret != nil {
+			g.gofile.Printf("var _fczero %s\n", g.syms.typeGoName(ret))
+			g.gofile.Printf("_fcrv = _fczero\n")
+		}
+	}
+	g.gofile.Outdent()
+	g.gofile.Printf("}\n")
+	g.gofile.Printf("return\n")
+	g.gofile.Outdent()
+	g.gofile.Printf("}\n")
+	g.gofile.Outdent()
+	g.gofile.Printf("}\n")
+	g.gofile.Printf("func %s(p interface{}) CGoHandle {\n", sym.go2py)
+	g.gofile.Indent()
+	g.gofile.Printf("return CGoHandle(gopyh.Register(%q, p))\n", sym.goname)
 	g.gofile.Outdent()
 	g.gofile.Printf("}\n")
 }
@@ -161,7 +494,7 @@ func (g *pyGen) genTypeHandle(sym *symbol) {
 	g.gofile.Printf("}\n")
 	g.gofile.Printf("func %s(p interface{})%s CGoHandle {\n", sym.go2py, sym.go2pyParenEx)
 	g.gofile.Indent()
-	g.gofile.Printf("return CGoHandle(gopyh.Register(\"%s\", p))\n", gonm)
+	g.gofile.Printf("return CGoHandle(gopyh.Register(%q, p))\n", gonm)
 	g.gofile.Outdent()
 	g.gofile.Printf("}\n")
 }
@@ -208,12 +541,31 @@ handle=A Go-side object is always initialized with an explicit handle=arg
 	g.pywrap.Outdent()
 	g.pywrap.Outdent()
 
-	g.pywrap.Printf("def __del__(self):\n")
-	g.pywrap.Indent()
-	g.pywrap.Printf("_%s.DecRef(self.handle)\n", g.pypkgname)
-	g.pywrap.Outdent()
+	g.genDel()
 
 	g.pywrap.Printf("\n")
 	g.pywrap.Outdent()
 
 }
+
+// genValueFromPyGo generates the Go function returning a new handle of the
+// given map or slice type, deep-converted from a python value with
+// valuePyToGo, which must convert to the given unnamed Go type.
+func (g *pyGen) genValueFromPyGo(sym *symbol, vtyp string) {
+	slNm := sym.id
+	g.gofile.Printf("//export %s_from_py\n", slNm)
+	g.gofile.Printf("func %s_from_py(o *C.PyObject) CGoHandle {\n", slNm)
+	g.gofile.Indent()
+	g.gofile.Printf("v, ok := valuePyToGo(o).(%s)\n", vtyp)
+	g.gofile.Printf("if !ok {\n")
+	g.gofile.Indent()
+	g.gofile.Printf("return -1 // python error is set\n")
+	g.gofile.Outdent()
+	g.gofile.Printf("}\n")
+	g.gofile.Printf("s := %s(v)\n", sym.goname)
+	g.gofile.Printf("return handleFromPtr_%s(&s)\n", slNm)
+	g.gofile.Outdent()
+	g.gofile.Printf("}\n\n")
+
+	g.declFunc("add_checked_function", slNm+"_from_py", PyHandle, capiParam{"PyObject*", "o"})
+}
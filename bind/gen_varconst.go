@@ -6,6 +6,8 @@ package bind
 
 import (
 	"fmt"
+	"go/constant"
+	"strconv"
 	"strings"
 )
 
@@ -27,7 +29,7 @@ func (g *pyGen) genVar(v *Var) {
 		return
 	}
 	g.genVarGetter(v)
-	if !v.sym.isArray() {
+	if !v.sym.isArray() && !v.sym.isTextOnly() {
 		g.genVarSetter(v)
 	}
 }
@@ -43,11 +45,11 @@ func (g *pyGen) genVarGetter(v *Var) {
 	qFn := "_" + pkgname + "." + qCgoFn
 	qVn := gopkg + "." + v.Name()
 
-	g.pywrap.Printf("def %s():\n", cgoFn)
+	g.pywrap.Printf("def %s()%s:\n", cgoFn, g.typeHint(" -> ", v.sym, false))
 	g.pywrap.Indent()
 	g.pywrap.Printf("%s\n%s Gets Go Variable: %s\n%s\n%s\n", `"""`, cgoFn, qVn, v.doc, `"""`)
 	if v.sym.hasHandle() {
-		cvnm := v.sym.pyPkgId(g.pkg.pkg)
+		cvnm := v.sym.pyPkgId(g, g.pkg.pkg)
 		g.pywrap.Printf("return %s(handle=%s())\n", cvnm, qFn)
 	} else {
 		g.pywrap.Printf("return %s()\n", qFn)
@@ -58,6 +60,7 @@ func (g *pyGen) genVarGetter(v *Var) {
 	g.gofile.Printf("//export %s\n", qCgoFn)
 	g.gofile.Printf("func %s() %s {\n", qCgoFn, v.sym.cgoname)
 	g.gofile.Indent()
+	g.genValueLock("&" + qVn)
 	g.gofile.Printf("return ")
 	if v.sym.go2py != "" {
 		if v.sym.hasHandle() && !v.sym.isPtrOrIface() {
@@ -72,7 +75,7 @@ func (g *pyGen) genVarGetter(v *Var) {
 	g.gofile.Outdent()
 	g.gofile.Printf("}\n\n")
 
-	g.pybuild.Printf("mod.add_function('%s', retval('%s'), [])\n", qCgoFn, v.sym.cpyname)
+	g.declFunc("mod.add_function", qCgoFn, v.sym.cpyname)
 }
 
 func (g *pyGen) genVarSetter(v *Var) {
@@ -86,7 +89,7 @@ func (g *pyGen) genVarSetter(v *Var) {
 	qFn := "_" + pkgname + "." + qCgoFn
 	qVn := gopkg + "." + v.Name()
 
-	g.pywrap.Printf("def %s(value):\n", cgoFn)
+	g.pywrap.Printf("def %s(value%s)%s:\n", cgoFn, g.typeHint(": ", v.sym, false), g.typeHint(" -> ", nil, false))
 	g.pywrap.Indent()
 	g.pywrap.Printf("%s\n%s Sets Go Variable: %s\n%s\n%s\n", `"""`, cgoFn, qVn, v.doc, `"""`)
 	g.pywrap.Printf("if isinstance(value, go.GoClass):\n")
@@ -103,6 +106,7 @@ func (g *pyGen) genVarSetter(v *Var) {
 	g.gofile.Printf("//export %s\n", qCgoFn)
 	g.gofile.Printf("func %s(val %s) {\n", qCgoFn, v.sym.cgoname)
 	g.gofile.Indent()
+	g.genValueLock("&" + qVn)
 	if v.sym.py2go != "" {
 		g.gofile.Printf("%s = %s(val)%s", qVn, v.sym.py2go, v.sym.py2goParenEx)
 	} else {
@@ -112,7 +116,7 @@ func (g *pyGen) genVarSetter(v *Var) {
 	g.gofile.Outdent()
 	g.gofile.Printf("}\n\n")
 
-	g.pybuild.Printf("mod.add_function('%s', None, [param('%s', 'val')])\n", qCgoFn, v.sym.cpyname)
+	g.declFunc("mod.add_function", qCgoFn, "", capiParam{v.sym.cpyname, "val"})
 }
 
 func (g *pyGen) genConstValue(c *Const) {
@@ -124,11 +128,28 @@ func (g *pyGen) genConstValue(c *Const) {
 	case "false":
 		val = "False"
 	}
+	if c.sym.pysig == "timedelta" { // -datetime converted time.Duration
+		ns, err := strconv.ParseInt(val, 10, 64)
+		if err == nil {
+			val = fmt.Sprintf("_datetime.timedelta(microseconds=%d)", ns/1000)
+		}
+	}
 	g.pywrap.Printf("%s = %s\n", c.GoName(), val)
 }
 
+// genEnum generates the python IntEnum of the consts of a named integer
+// type, whose members are named after them, including the unexported ones,
+// so that they compare equal to the plain int values passed to and from Go.
+// Bit flags declared with a shifted iota generate an IntFlag instead, whose
+// members combine with | and & as in Go, and the consts of a named string
+// type a str Enum, whose members compare equal to str literals.
 func (g *pyGen) genEnum(e *Enum) {
-	g.pywrap.Printf("class %s(Enum):\n", e.typ.Obj().Name())
+	enm := e.typ.Obj().Name()
+	base, conv := e.pyBase(), "int"
+	if e.isStr() {
+		base, conv = "str, Enum", "str"
+	}
+	g.pywrap.Printf("class %s(%s):\n", enm, base)
 	g.pywrap.Indent()
 	doc := e.Doc()
 	if doc != "" {
@@ -143,17 +164,45 @@ func (g *pyGen) genEnum(e *Enum) {
 	}
 	e.SortConsts()
 	for _, c := range e.items {
-		g.genConstValue(c)
+		val := c.val
+		if e.isStr() { // c.val is shortened for long strings
+			val = strconv.Quote(constant.StringVal(c.obj.Val()))
+		}
+		g.pywrap.Printf("%s = %s\n", pySafeName(c.GoName()), val)
 	}
+	g.pywrap.Printf("\n")
+	g.pywrap.Printf("def __str__(self):\n")
+	g.pywrap.Indent()
+	g.pywrap.Printf("return str(self.value)\n")
+	g.pywrap.Outdent()
+	g.pywrap.Printf("\n")
+	g.pywrap.Printf("@classmethod\n")
+	g.pywrap.Printf("def from_value(cls, value):\n")
+	g.pywrap.Indent()
+	g.pywrap.Printf(`"""from_value returns the member of the given %[1]s value, e.g., as returned
+by Go, or the %[1]s itself if no member has it, as Go allows any value"""`, conv)
+	g.pywrap.Printf("\n")
+	g.pywrap.Printf("try:\n")
+	g.pywrap.Indent()
+	g.pywrap.Printf("return cls(value)\n")
+	g.pywrap.Outdent()
+	g.pywrap.Printf("except ValueError:\n")
+	g.pywrap.Indent()
+	g.pywrap.Printf("return %s(value)\n", conv)
+	g.pywrap.Outdent()
+	g.pywrap.Outdent()
 	g.pywrap.Outdent()
 
 	// Go has each const value globally available within a given package
-	// so to keep the code consistent, we redundantly generate the consts
-	// again here.  The Enum organization however is critical for organizing
-	// the values under the type (making them accessible programmatically)
+	// so to keep the code consistent, we redundantly make the exported
+	// members available again here.  The Enum organization however is
+	// critical for organizing the values under the type (making them
+	// accessible programmatically)
 	g.pywrap.Printf("\n")
 	for _, c := range e.items {
-		g.genConstValue(c)
+		if c.obj.Exported() {
+			g.pywrap.Printf("%[1]s = %[2]s.%[1]s\n", pySafeName(c.GoName()), enm)
+		}
 	}
 	g.pywrap.Printf("\n")
 }
@@ -0,0 +1,175 @@
+// Copyright 2019 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bind
+
+import (
+	"context"
+	"fmt"
+	"go/ast"
+	"go/doc"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// Options are the options for Generate, which correspond to those
+// of the gopy gen command.
+type Options struct {
+	BindCfg
+
+	// import paths of the Go packages to bind, in order of increasing dependency
+	Packages []string
+	// names of packages to skip in Packages
+	Exclude []string
+	// mode of generation -- defaults to ModeGen.  Only the code for the given
+	// mode is generated: building it is left to the caller (e.g., with make).
+	Mode BuildMode
+	// extension of the Go shared library -- defaults to that of the platform
+	LibExt string
+	// extra gcc args for building the extension -- defaults to those of the platform
+	ExtraGccArgs string
+}
+
+// Report describes the bindings produced by Generate.
+type Report struct {
+	// import paths of the Go packages that were bound
+	Packages []string
+	// names of the generated files, relative to OutputDir
+	Files []string
+	// major version of the python for which the bindings were generated
+	PyVersion int
+}
+
+// Generate generates the python bindings for the Go packages given in the
+// options -- it is the library equivalent of the gopy gen command, for
+// embedding gopy in other Go tools.  It can be called concurrently, as the
+// state of each call hangs off its own copy of the options' BindCfg.
+func Generate(ctx context.Context, opts Options) (Report, error) {
+	var rep Report
+	if len(opts.Packages) == 0 {
+		return rep, fmt.Errorf("gopy: no packages to generate bindings for")
+	}
+
+	cfg := opts.BindCfg
+	cfg.parse = nil // the packages are parsed anew
+	if opts.Mode == "" {
+		opts.Mode = ModeGen
+	}
+	if cfg.VM == "" {
+		cfg.VM = "python"
+	}
+	if cfg.PkgPrefix == "" && opts.Mode != ModeExe {
+		cfg.PkgPrefix = "."
+	}
+	if opts.LibExt == "" {
+		opts.LibExt, opts.ExtraGccArgs = PlatformLibExt()
+	}
+	if cfg.Cmd == "" {
+		cfg.Cmd = "gopy gen"
+	}
+
+	odir, err := filepath.Abs(cfg.OutputDir)
+	if err != nil {
+		return rep, fmt.Errorf("gopy: could not infer absolute path to output directory: %v", err)
+	}
+	cfg.OutputDir = odir
+	if !filepath.IsAbs(cfg.VM) {
+		cfg.VM, err = exec.LookPath(cfg.VM)
+		if err != nil {
+			return rep, fmt.Errorf("gopy: could not locate absolute path to python VM: %v", err)
+		}
+	}
+	pycfg, err := GetPythonConfigFor(&cfg)
+	if err != nil {
+		return rep, err
+	}
+	rep.PyVersion = pycfg.Version
+
+	excl := make(map[string]bool, len(opts.Exclude))
+	for _, ex := range opts.Exclude {
+		excl[ex] = true
+	}
+	for _, path := range opts.Packages {
+		if err := ctx.Err(); err != nil {
+			return rep, err
+		}
+		bpkgs, err := packages.Load(&packages.Config{Context: ctx, Mode: packages.LoadTypes, BuildFlags: BuildFlags(&cfg)}, path)
+		if err != nil {
+			return rep, fmt.Errorf("gopy: error resolving import path %q: %v", path, err)
+		}
+		for _, bpkg := range bpkgs {
+			if excl[bpkg.Name] {
+				continue
+			}
+			pkg, err := ParsePackage(&cfg, bpkg)
+			if err != nil {
+				return rep, err
+			}
+			if cfg.Name == "" {
+				cfg.Name = pkg.Name()
+			}
+			rep.Packages = append(rep.Packages, bpkg.PkgPath)
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return rep, err
+	}
+
+	rep.Files, err = genPyBind(opts.Mode, opts.LibExt, opts.ExtraGccArgs, pycfg.Version, &cfg)
+	return rep, err
+}
+
+// ParsePackage parses the documentation of the loaded Go package, and
+// adds it to the packages bound with cfg, whose bindings GenPyBind
+// generates.
+func ParsePackage(cfg *BindCfg, bpkg *packages.Package) (*Package, error) {
+	if len(bpkg.GoFiles) == 0 {
+		return nil, fmt.Errorf("gopy: no files in package %q", bpkg.PkgPath)
+	}
+	if bpkg.Name == "main" {
+		return nil, fmt.Errorf("gopy: skipping 'main' package %q", bpkg.PkgPath)
+	}
+	dir, _ := filepath.Split(bpkg.GoFiles[0])
+	p := bpkg.Types
+
+	fset := token.NewFileSet()
+	var pkgast *ast.Package
+	// only the files of the package for the build tags it was loaded with
+	gofiles := make(map[string]bool, len(bpkg.GoFiles))
+	for _, fn := range bpkg.GoFiles {
+		gofiles[filepath.Base(fn)] = true
+	}
+	filter := func(fi fs.FileInfo) bool { return gofiles[fi.Name()] }
+	pkgs, err := parser.ParseDir(fset, dir, filter, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+	pkgast = pkgs[p.Name()]
+	if pkgast == nil {
+		return nil, fmt.Errorf("gopy: could not find AST for package %q", p.Name())
+	}
+
+	pkgdoc := doc.New(pkgast, bpkg.PkgPath, 0)
+	return NewPackage(cfg, p, pkgdoc)
+}
+
+// PlatformLibExt returns the shared library extension and extra gcc args
+// for the current platform.
+func PlatformLibExt() (string, string) {
+	switch runtime.GOOS {
+	case "windows":
+		return ".pyd", ""
+	case "darwin":
+		// .dylib in theory, but python only recognizes .so
+		return ".so", "-dynamiclib"
+	default:
+		return ".so", ""
+	}
+}
@@ -0,0 +1,275 @@
+// Copyright 2019 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bind
+
+import (
+	"context"
+	"go/ast"
+	"go/doc"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	"golang.org/x/tools/go/packages"
+)
+
+func TestGenerate(t *testing.T) {
+	vm, err := exec.LookPath("python3")
+	if err != nil {
+		t.Skip("python3 not available")
+	}
+	odir, err := ioutil.TempDir("", "gopy-generate-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(odir)
+
+	opts := Options{
+		BindCfg:  BindCfg{OutputDir: odir, VM: vm, NoWarn: true},
+		Packages: []string{"../_examples/hi"},
+	}
+	rep, err := Generate(context.Background(), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if opts.parse != nil {
+		t.Fatalf("Generate parsed the packages into the options")
+	}
+
+	want := []string{"Makefile", "__init__.py", "build.py", "go.py", "go.pyi", "hi.go", "hi.py", "hi.pyi", "py.typed"}
+	sort.Strings(rep.Files)
+	if !equalStrings(rep.Files, want) {
+		t.Fatalf("got files %v, want %v", rep.Files, want)
+	}
+	for _, fn := range rep.Files {
+		if _, err := os.Stat(filepath.Join(odir, fn)); err != nil {
+			t.Errorf("generated file: %v", err)
+		}
+	}
+	if len(rep.Packages) != 1 || rep.Packages[0] != "github.com/rudderlabs/gopy/_examples/hi" {
+		t.Fatalf("got packages %v", rep.Packages)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = Generate(ctx, Options{
+		BindCfg:  BindCfg{OutputDir: odir, VM: vm},
+		Packages: []string{"../_examples/hi"},
+	})
+	if err != context.Canceled {
+		t.Fatalf("got error %v for cancelled context, want %v", err, context.Canceled)
+	}
+}
+
+func TestGenerateConcurrent(t *testing.T) {
+	vm, err := exec.LookPath("python3")
+	if err != nil {
+		t.Skip("python3 not available")
+	}
+	odir := t.TempDir()
+
+	const n = 4
+	errs := make(chan error, n)
+	for i := 0; i < n; i++ {
+		go func(i int) {
+			_, err := Generate(context.Background(), Options{
+				BindCfg:  BindCfg{OutputDir: filepath.Join(odir, strconv.Itoa(i)), VM: vm, NoWarn: true, NoMake: true},
+				Packages: []string{"../_examples/hi"},
+			})
+			errs <- err
+		}(i)
+	}
+	for i := 0; i < n; i++ {
+		if err := <-errs; err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(filepath.Join(odir, "0", "hi.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 1; i < n; i++ {
+		got, err := os.ReadFile(filepath.Join(odir, strconv.Itoa(i), "hi.go"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != string(want) {
+			t.Fatalf("got different bindings from concurrent calls %d and 0", i)
+		}
+	}
+}
+
+func TestGenRestrictTo(t *testing.T) {
+	const src = `package p
+
+// KV is an interface.
+type KV interface {
+	Get(k string) string
+}
+
+// Store is a struct.
+type Store struct{}
+
+// Get gets.
+func (s *Store) Get(k string) string { return k }
+`
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "p.go", src, parser.ParseComments)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tpkg, err := new(types.Config).Check("example.com/p", fset, []*ast.File{f}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "p.go"), []byte(src), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &BindCfg{
+		OutputDir:  filepath.Join(dir, "out"),
+		VM:         "python3",
+		Name:       "p",
+		NoWarn:     true,
+		NoMake:     true,
+		RestrictTo: "Store, Nope=KV, KV=KV, Store=KV",
+	}
+	bpkg := &packages.Package{Name: "p", PkgPath: "example.com/p", GoFiles: []string{filepath.Join(dir, "p.go")}, Types: tpkg}
+	if _, err := ParsePackage(cfg, bpkg); err != nil {
+		t.Fatal(err)
+	}
+	err = GenPyBind(ModeGen, ".so", "", 3, cfg)
+	if err == nil {
+		t.Fatal("got no error for the invalid -restrict-to-interface entries")
+	}
+	for _, want := range []string{
+		`invalid -restrict-to-interface entry "Store"`,
+		"unknown type Nope",
+		"type KV is not a struct",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("got error %q, want it to contain %q", err, want)
+		}
+	}
+	if strings.Contains(err.Error(), "Store=") {
+		t.Errorf("got error %q for the valid entry", err)
+	}
+}
+
+func TestAPIHash(t *testing.T) {
+	hash := func(src string) string {
+		fset := token.NewFileSet()
+		f, err := parser.ParseFile(fset, "p.go", src, parser.ParseComments)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pkg, err := new(types.Config).Check("p", fset, []*ast.File{f}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pdoc, err := doc.NewFromFiles(fset, []*ast.File{f}, "p")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return apiHash(pkg, pdoc)
+	}
+
+	const src = `package p
+
+// T is a type.
+type T struct {
+	// X is a field.
+	X int
+}
+
+// Add adds.
+func (t *T) Add(i int) int { return t.X + i }
+`
+	h := hash(src)
+	for _, tc := range []struct {
+		name, old, new string
+		same           bool
+	}{
+		{"body", "return t.X + i", "return i + t.X", true},
+		{"position", "package p\n", "package p\n\n", true},
+		{"signature", "Add(i int) int", "Add(i int, _ ...string) int", false},
+		{"doc", "// Add adds.", "// Add adds i.", false},
+		{"field doc", "// X is a field.", "// X is the field.", false},
+	} {
+		got := hash(strings.Replace(src, tc.old, tc.new, 1))
+		if (got == h) != tc.same {
+			t.Errorf("%s: got same hash=%v, want %v", tc.name, got == h, tc.same)
+		}
+	}
+}
+
+func TestParsePackageTags(t *testing.T) {
+	dir := t.TempDir()
+	srcs := map[string]string{
+		"a.go": "package p\n\n// Open is the open one.\nfunc Open() {}\n",
+		"b.go": "//go:build enterprise\n\npackage p\n\n// Enterprise is the enterprise one.\nfunc Enterprise() {}\n",
+	}
+	for fn, src := range srcs {
+		if err := os.WriteFile(filepath.Join(dir, fn), []byte(src), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "a.go", srcs["a.go"], parser.ParseComments)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tpkg, err := new(types.Config).Check("example.com/p", fset, []*ast.File{f}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &BindCfg{}
+	bpkg := &packages.Package{Name: "p", PkgPath: "example.com/p", GoFiles: []string{filepath.Join(dir, "a.go")}, Types: tpkg}
+	pkg, err := ParsePackage(cfg, bpkg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var funcs []string
+	for _, fn := range pkg.doc.Funcs {
+		funcs = append(funcs, fn.Name)
+	}
+	if !equalStrings(funcs, []string{"Open"}) {
+		t.Fatalf("got funcs %v, want the ones of the files without the enterprise tag", funcs)
+	}
+	if pkgs := cfg.parse.pkgs; len(pkgs) != 2 || pkgs[0] != goPackage || pkgs[1] != pkg {
+		t.Fatalf("got packages %v, want the go package and the parsed one", pkgs)
+	}
+
+	if got := BuildFlags(&BindCfg{}); got != nil {
+		t.Fatalf("got build flags %q without tags", got)
+	}
+	got := BuildFlags(&BindCfg{Tags: "enterprise", Static: true})
+	if want := []string{"-tags=enterprise," + StaticTags}; !equalStrings(got, want) {
+		t.Fatalf("got build flags %q, want %q", got, want)
+	}
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
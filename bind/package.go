@@ -8,10 +8,10 @@ import (
 	"fmt"
 	"go/ast"
 	"go/doc"
+	"go/token"
 	"go/types"
 	"path/filepath"
 	"reflect"
-	"strconv"
 	"strings"
 )
 
@@ -23,37 +23,44 @@ type Package struct {
 	sz  types.Sizes
 	doc *doc.Package
 
-	syms      *symtab // note: this is now *always* = symbols.current
+	syms      *symtab // the symbols of all the packages of the binding, see parseCtx
 	objs      map[string]Object
 	consts    []*Const
 	enums     []*Enum
+	aliases   []*types.TypeName // exported type aliases, bound as their target
 	vars      []*Var
 	structs   []*Struct
 	ifaces    []*Interface
 	slices    []*Slice
 	maps      []*Map
 	funcs     []*Func
-	pyimports map[string]string // extra python imports from incidental python wrapper includes
+	pyimports map[string]string         // extra python imports from incidental python wrapper includes
+	insts     map[types.Object]instance // instantiations of generic types and funcs
+	instNames map[string]string         // generic type names of instantiated types
+	anonDocs  map[string]string         // docs of the synthetic types of anonymous structs
 	// calls   []*Signature // TODO: could optimize calls back into python to gen once
 }
 
-// Packages accumulates all the packages processed
-var Packages []*Package
+// parseCtx is the state of the parsing of the packages bound with a
+// BindCfg, from which their bindings are generated.
+type parseCtx struct {
+	syms *symtab    // the symbols of all the packages
+	pkgs []*Package // the go package, then the packages in the order parsed
+}
 
-// ResetPackages resets any accumulated packages -- needed when doing tests
-func ResetPackages() {
-	universeMutex.Lock()
-	defer universeMutex.Unlock()
-	Packages = nil
-	makeGoPackage()
-	current = newSymtab(nil, universe)
+// parsed returns the parse context of the packages bound with cfg, which
+// starts with the go package when the first one is parsed.
+func (cfg *BindCfg) parsed() *parseCtx {
+	if cfg.parse == nil {
+		cfg.parse = &parseCtx{syms: newSymtab(cfg, universe), pkgs: []*Package{goPackage}}
+	}
+	return cfg.parse
 }
 
-// NewPackage creates a new Package, tying types.Package and ast.Package together.
-func NewPackage(pkg *types.Package, doc *doc.Package) (*Package, error) {
-	// protection for parallel tests
-	universeMutex.Lock()
-	defer universeMutex.Unlock()
+// NewPackage creates a new Package, tying types.Package and ast.Package together,
+// and adds it to the packages bound with cfg.
+func NewPackage(cfg *BindCfg, pkg *types.Package, doc *doc.Package) (*Package, error) {
+	pc := cfg.parsed()
 	fmt.Printf("\n--- Processing package: %v ---\n", pkg.Path())
 	sz := int64(reflect.TypeOf(int(0)).Size())
 	p := &Package{
@@ -61,16 +68,19 @@ func NewPackage(pkg *types.Package, doc *doc.Package) (*Package, error) {
 		n:         0,
 		sz:        &types.StdSizes{WordSize: sz, MaxAlign: sz},
 		doc:       doc,
-		syms:      current,
+		syms:      pc.syms,
 		objs:      map[string]Object{},
 		pyimports: map[string]string{},
 	}
+	if cfg.Bytes {
+		addBytesTypes(pc.syms)
+	}
 	err := p.process()
 	if err != nil {
 		return nil, err
 	}
 
-	Packages = append(Packages, p)
+	pc.pkgs = append(pc.pkgs, p)
 	return p, err
 }
 
@@ -110,6 +120,17 @@ func (p *Package) AddPyImport(ipath string, extra bool) {
 // parent is the name of the containing scope ("" for global scope)
 func (p *Package) getDoc(parent string, o types.Object) string {
 	n := o.Name()
+	if doc, ok := p.anonDocs[n]; ok && o.Pkg() == p.pkg && o.Parent() == nil {
+		return doc // synthetic types of anonymous structs are not in scope
+	}
+	// instantiations use the doc of their generic type or func
+	in, isInst := p.insts[o]
+	if isInst {
+		n = in.gen.Name()
+	}
+	if gn, ok := p.instNames[parent]; ok {
+		parent = gn
+	}
 	switch tp := o.(type) {
 	case *types.Const:
 		for _, c := range p.doc.Consts {
@@ -168,13 +189,14 @@ func (p *Package) getDoc(parent string, o types.Object) string {
 			return ""
 		}
 
+		inScope := o.Parent() != nil || isInst // not a method
 		doc := func() string {
-			if o.Parent() == nil || (o.Parent() != nil && parent != "") {
+			if !inScope || parent != "" {
 				for _, typ := range p.doc.Types {
 					if typ.Name != parent {
 						continue
 					}
-					if o.Parent() == nil {
+					if !inScope {
 						for _, m := range typ.Methods {
 							if m.Name == n {
 								return m.Doc
@@ -220,7 +242,7 @@ func (p *Package) getDoc(parent string, o types.Object) string {
 			}
 		}
 
-		parseFn := func(tup *types.Tuple) []string {
+		parseFn := func(tup *types.Tuple, results bool) []string {
 			params := []string{}
 			if tup == nil {
 				return params
@@ -228,11 +250,17 @@ func (p *Package) getDoc(parent string, o types.Object) string {
 			for i := 0; i < tup.Len(); i++ {
 				paramVar := tup.At(i)
 				paramSig := p.syms.symtype(paramVar.Type())
-				if paramSig == nil {
+				if paramSig == nil || (!results && i == 0 && isContextType(paramVar.Type())) {
 					continue
 				}
+				if results && paramSig.goname == "interface{}" {
+					paramSig = paramSig.anyResult()
+				}
 				paramType := paramSig.pysig
-				if paramVar.Name() != "" {
+				switch {
+				case !results: // named as the python parameter, for keyword arguments
+					paramType = fmt.Sprintf("%s %s", paramType, pySafeArg(paramVar.Name(), i))
+				case paramVar.Name() != "":
 					paramType = fmt.Sprintf("%s %s", paramType, paramVar.Name())
 				}
 				params = append(params, paramType)
@@ -240,8 +268,8 @@ func (p *Package) getDoc(parent string, o types.Object) string {
 			return params
 		}
 
-		params := parseFn(sig.Params())
-		results := parseFn(sig.Results())
+		params := parseFn(sig.Params(), false)
+		results := parseFn(sig.Results(), true)
 
 		paramString := strings.Join(params, ", ")
 		resultString := strings.Join(results, ", ")
@@ -254,6 +282,9 @@ func (p *Package) getDoc(parent string, o types.Object) string {
 		} else {
 			doc = docSig
 		}
+		if sections := p.docSections(sig); sections != "" {
+			doc = strings.TrimRight(doc, "\n") + "\n\n" + sections
+		}
 		return doc
 
 	case *types.TypeName:
@@ -271,6 +302,60 @@ func (p *Package) getDoc(parent string, o types.Object) string {
 	return ""
 }
 
+// docSections returns the Google-style Args, Returns and Raises sections
+// of the python doc string of a function with the given signature, which
+// give the python and Go types of its parameters and result.
+func (p *Package) docSections(sig *types.Signature) string {
+	qual := func(pkg *types.Package) string { return pkg.Name() }
+	var args, rets, raises []string
+	params := sig.Params()
+	for i := 0; i < params.Len(); i++ {
+		pv := params.At(i)
+		typ := pv.Type()
+		name := pySafeArg(pv.Name(), i)
+		if sig.Variadic() && i == params.Len()-1 {
+			typ = typ.(*types.Slice).Elem()
+			name = "*args"
+		}
+		psym := p.syms.symtype(typ)
+		if psym == nil || (i == 0 && isContextType(typ)) {
+			continue
+		}
+		args = append(args, fmt.Sprintf("%s (%s): Go %s", name, psym.pysig, types.TypeString(typ, qual)))
+	}
+	res := sig.Results()
+	for i := 0; i < res.Len(); i++ {
+		typ := res.At(i).Type()
+		if isErrorType(typ) {
+			raises = append(raises, "GoError: for a non-nil Go error")
+			continue
+		}
+		rsym := p.syms.symtype(typ)
+		if rsym == nil {
+			continue
+		}
+		if rsym.goname == "interface{}" {
+			rsym = rsym.anyResult()
+		}
+		rets = append(rets, fmt.Sprintf("%s: Go %s", rsym.pysig, types.TypeString(typ, qual)))
+	}
+	if res.Len() == 0 {
+		args = append(args, "goRun (bool): run the call in a new goroutine, without waiting for it to return")
+	}
+
+	var sections []string
+	for _, sec := range []struct {
+		title string
+		lines []string
+	}{{"Args", args}, {"Returns", rets}, {"Raises", raises}} {
+		if len(sec.lines) == 0 {
+			continue
+		}
+		sections = append(sections, sec.title+":\n    "+strings.Join(sec.lines, "\n    ")+"\n")
+	}
+	return strings.Join(sections, "\n")
+}
+
 // process collects informations about a go package.
 func (p *Package) process() error {
 	var err error
@@ -285,22 +370,38 @@ func (p *Package) process() error {
 	maps := make(map[string]*Map)
 
 	scope := p.pkg.Scope()
+	var objs, generics []types.Object
 	for _, name := range scope.Names() {
 		obj := scope.Lookup(name)
 		if !obj.Exported() {
 			continue
 		}
+		if isGeneric(obj) {
+			generics = append(generics, obj)
+			continue
+		}
+		if isConstraint(obj) {
+			continue
+		}
+		objs = append(objs, obj)
+	}
+	instObjs, err := p.instantiate(p.syms.cfg.Instantiate, generics)
+	if err != nil {
+		return err
+	}
+	objs = append(objs, instObjs...)
+	p.anonDocs = make(map[string]string)
+	for _, tn := range p.addAnonStructs(objs) {
+		objs = append(objs, tn)
+	}
 
+	for _, obj := range objs {
 		p.n++
 		p.syms.addSymbol(obj)
 	}
 
-	for _, name := range scope.Names() {
-		obj := scope.Lookup(name)
-		if !obj.Exported() {
-			continue
-		}
-
+	for _, obj := range objs {
+		name := obj.Name()
 		switch obj := obj.(type) {
 		case *types.Const:
 			p.addConst(obj)
@@ -313,9 +414,17 @@ func (p *Package) process() error {
 			if err != nil {
 				continue
 			}
+			fv.inst = p.insts[obj].expr
 			funcs[name] = fv
 
 		case *types.TypeName:
+			if obj.IsAlias() {
+				p.aliases = append(p.aliases, obj)
+				continue
+			}
+			if tsym := p.syms.symtype(obj.Type()); tsym != nil && tsym.isText() {
+				continue // converted to a python str
+			}
 			named := obj.Type().(*types.Named)
 			switch typ := named.Underlying().(type) {
 			case *types.Struct:
@@ -330,7 +439,12 @@ func (p *Package) process() error {
 				// ok. handled by p.syms-types
 
 			case *types.Array:
-				// ok. handled by p.syms-types
+				sl, err := newSlice(p, obj)
+				if err != nil {
+					fmt.Println(err)
+					continue
+				}
+				slices[name] = sl
 
 			case *types.Interface:
 				iv, err := newInterface(p, obj)
@@ -372,6 +486,19 @@ func (p *Package) process() error {
 
 	}
 
+	// unexported consts of the enum types are members of the enums too
+	for _, name := range scope.Names() {
+		obj, ok := scope.Lookup(name).(*types.Const)
+		if !ok || obj.Exported() {
+			continue
+		}
+		if ntyp, ok := unalias(obj.Type()).(*types.Named); ok {
+			if enm := p.findEnum(ntyp); enm != nil {
+				enm.AddConst(p, obj)
+			}
+		}
+	}
+
 	// attach docstrings to methods
 	for _, n := range p.syms.names() {
 		sym := p.syms.syms[n]
@@ -412,9 +539,9 @@ func (p *Package) process() error {
 			}
 			retptr, retIsPtr := ret.(*types.Pointer)
 
-			if ret == styp || (retIsPtr && retptr.Elem() == styp) {
+			if types.Identical(ret, styp) || (retIsPtr && types.Identical(retptr.Elem(), styp)) {
 				delete(funcs, name)
-				fct.doc = p.getDoc(sname, scope.Lookup(name))
+				fct.doc = p.getDoc(sname, fct.Obj())
 				fct.ctor = true
 				s.ctors = append(s.ctors, fct)
 				structs[sname] = s
@@ -442,6 +569,25 @@ func (p *Package) process() error {
 			if isStringer(meth) {
 				s.prots |= ProtoStringer
 			}
+			if isCloser(meth) {
+				s.prots |= ProtoCloser
+			}
+		}
+		for _, meth := range s.promotedMethods() {
+			m, err := newFuncFrom(p, sname, meth, meth.Type().(*types.Signature))
+			if err != nil {
+				continue
+			}
+			s.meths = append(s.meths, m)
+			if isStringer(meth) {
+				s.prots |= ProtoStringer
+			}
+			if isCloser(meth) {
+				s.prots |= ProtoCloser
+			}
+		}
+		if implementsError(ptyp) {
+			s.prots |= ProtoError
 		}
 		p.addStruct(s)
 	}
@@ -483,6 +629,9 @@ func (p *Package) process() error {
 			if isStringer(meth) {
 				s.prots |= ProtoStringer
 			}
+			if isCloser(meth) {
+				s.prots |= ProtoCloser
+			}
 		}
 		p.addSlice(s)
 	}
@@ -508,6 +657,9 @@ func (p *Package) process() error {
 			if isStringer(meth) {
 				s.prots |= ProtoStringer
 			}
+			if isCloser(meth) {
+				s.prots |= ProtoCloser
+			}
 		}
 		p.addMap(s)
 	}
@@ -519,6 +671,105 @@ func (p *Package) process() error {
 	return err
 }
 
+// isGeneric returns true if obj is a generic type or func, which can
+// only be bound through explicit instantiations of it.
+func isGeneric(obj types.Object) bool {
+	switch obj := obj.(type) {
+	case *types.TypeName:
+		if named, ok := obj.Type().(*types.Named); ok && !obj.IsAlias() {
+			return named.TypeParams().Len() > 0
+		}
+	case *types.Func:
+		return obj.Type().(*types.Signature).TypeParams().Len() > 0
+	}
+	return false
+}
+
+// isConstraint returns true if obj is an interface that can only be
+// used as a type constraint, e.g., a union of types.
+func isConstraint(obj types.Object) bool {
+	tn, ok := obj.(*types.TypeName)
+	if !ok {
+		return false
+	}
+	iface, ok := tn.Type().Underlying().(*types.Interface)
+	return ok && !iface.IsMethodSet()
+}
+
+// instance records the generic type or func of an instantiation, along
+// with the go expression for it in the case of a func, e.g., Max[int].
+type instance struct {
+	gen  types.Object
+	expr string
+}
+
+// instantiate returns the objects for the instantiations, given in the
+// comma-separated list insts, of the generic types and funcs of the package,
+// which are recorded in p.insts.
+// Those of generic types and funcs of other packages are skipped, and
+// the generics that are not instantiated are reported as ignored.
+func (p *Package) instantiate(insts string, generics []types.Object) ([]types.Object, error) {
+	var objs []types.Object
+	p.insts = make(map[types.Object]instance)
+	p.instNames = make(map[string]string)
+	used := make(map[types.Object]bool)
+	ctxt := types.NewContext()
+	scope := p.pkg.Scope()
+	for _, inst := range splitTopLevel(insts) {
+		lb := strings.Index(inst, "[")
+		if lb <= 0 || !strings.HasSuffix(inst, "]") {
+			return nil, fmt.Errorf("gopy: invalid instantiation %q: expected Name[T1,T2...]", inst)
+		}
+		gen := scope.Lookup(strings.TrimSpace(inst[:lb]))
+		if gen == nil {
+			continue
+		}
+		if !isGeneric(gen) {
+			return nil, fmt.Errorf("gopy: invalid instantiation %q: %s is not generic", inst, gen.Name())
+		}
+		var targs []types.Type
+		for _, ta := range splitTopLevel(inst[lb+1 : len(inst)-1]) {
+			tv, err := types.Eval(token.NewFileSet(), p.pkg, token.NoPos, ta)
+			if err != nil {
+				return nil, fmt.Errorf("gopy: invalid instantiation %q: %v", inst, err)
+			}
+			if !tv.IsType() {
+				return nil, fmt.Errorf("gopy: invalid instantiation %q: %s is not a type", inst, ta)
+			}
+			targs = append(targs, tv.Type)
+		}
+		used[gen] = true
+		typ, err := types.Instantiate(ctxt, gen.Type(), targs, true)
+		if err != nil {
+			return nil, fmt.Errorf("gopy: invalid instantiation %q: %v", inst, err)
+		}
+		name := strings.TrimPrefix(p.syms.instanceIdName(p.pkg, gen.Name(), targs), p.syms.addImport(p.pkg)+"_")
+		switch typ := typ.(type) {
+		case *types.Named:
+			tn := types.NewTypeName(gen.Pos(), p.pkg, name, typ)
+			p.insts[tn] = instance{gen: gen}
+			p.instNames[name] = gen.Name()
+			objs = append(objs, tn)
+		case *types.Signature:
+			fn := types.NewFunc(gen.Pos(), p.pkg, name, typ)
+			gargs := make([]string, len(targs))
+			for i, ta := range targs {
+				gargs[i] = p.syms.typeGoName(ta)
+			}
+			p.insts[fn] = instance{gen: gen, expr: gen.Name() + "[" + strings.Join(gargs, ", ") + "]"}
+			objs = append(objs, fn)
+		}
+	}
+	if !p.syms.cfg.NoWarn {
+		for _, gen := range generics {
+			if !used[gen] {
+				fmt.Printf("ignoring generic %s.%s: use -instantiate to bind instantiations of it\n", p.pkg.Name(), gen.Name())
+			}
+		}
+	}
+	return objs, nil
+}
+
 func (p *Package) findEnum(ntyp *types.Named) *Enum {
 	for _, enm := range p.enums {
 		if enm.typ == ntyp {
@@ -528,22 +779,75 @@ func (p *Package) findEnum(ntyp *types.Named) *Enum {
 	return nil
 }
 
+// isEnumType returns true if the consts of the given named type are
+// collected into an enum: it must be an integer or string type of the
+// package.
+func (p *Package) isEnumType(ntyp *types.Named) bool {
+	bt, ok := ntyp.Underlying().(*types.Basic)
+	return ok && bt.Info()&(types.IsInteger|types.IsString) != 0 && ntyp.Obj().Pkg() == p.pkg
+}
+
+// isFlagType returns true if any const of the given enum type is declared
+// with a shifted iota, e.g., 1 << iota, so that its consts are bit flags.
+func (p *Package) isFlagType(ntyp *types.Named) bool {
+	vals := append([]*doc.Value{}, p.doc.Consts...)
+	for _, t := range p.doc.Types {
+		vals = append(vals, t.Consts...)
+	}
+	for _, v := range vals {
+		var cur []ast.Expr // values are implicitly repeated in const blocks
+		for _, spec := range v.Decl.Specs {
+			vs, ok := spec.(*ast.ValueSpec)
+			if !ok {
+				continue
+			}
+			if len(vs.Values) > 0 {
+				cur = vs.Values
+			}
+			for i, n := range vs.Names {
+				obj := p.pkg.Scope().Lookup(n.Name)
+				if obj == nil || i >= len(cur) || !types.Identical(obj.Type(), ntyp) {
+					continue
+				}
+				if isShiftedIota(cur[i]) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// isShiftedIota returns true if the expression shifts by iota.
+func isShiftedIota(x ast.Expr) bool {
+	found := false
+	ast.Inspect(x, func(n ast.Node) bool {
+		be, ok := n.(*ast.BinaryExpr)
+		if !ok || be.Op != token.SHL {
+			return !found
+		}
+		ast.Inspect(be.Y, func(n ast.Node) bool {
+			if id, ok := n.(*ast.Ident); ok && id.Name == "iota" {
+				found = true
+			}
+			return !found
+		})
+		return !found
+	})
+	return found
+}
+
 func (p *Package) addConst(obj *types.Const) {
-	if ntyp, ok := obj.Type().(*types.Named); ok {
+	if ntyp, ok := unalias(obj.Type()).(*types.Named); ok && p.isEnumType(ntyp) {
 		enm := p.findEnum(ntyp)
 		if enm != nil {
 			enm.AddConst(p, obj)
 			return
-		} else {
-			val := obj.Val().String()
-			_, err := strconv.Atoi(val)
-			if err == nil {
-				enm, err := newEnum(p, obj)
-				if err == nil {
-					p.enums = append(p.enums, enm)
-					return
-				}
-			}
+		}
+		enm, err := newEnum(p, obj)
+		if err == nil {
+			p.enums = append(p.enums, enm)
+			return
 		}
 	}
 
@@ -0,0 +1,58 @@
+// Copyright 2019 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bind
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// StaticTags are the build tags of the go builds of -static: the pure Go
+// net and os/user packages do not load the NSS modules of glibc, which
+// musl does not have.
+const StaticTags = "netgo,osusergo"
+
+// checkStatic returns an error if cfg does not support -static, which
+// builds for linux, e.g., with musl on Alpine.
+func checkStatic(cfg *BindCfg) error {
+	if !cfg.Static {
+		return nil
+	}
+	if goos, _, _ := crossTarget(cfg); goos != "linux" {
+		return fmt.Errorf("gopy: -static builds for linux, not GOOS=%s", goos)
+	}
+	return nil
+}
+
+// StaticPythonConfig returns pycfg with the link flags of -static: libgcc
+// is linked statically, and the extension module is not linked against
+// libpython, whose symbols are resolved from the interpreter loading it,
+// as in the manylinux and musllinux wheels.  The exe links the static
+// libpython instead, if it is installed, and exports its symbols to the
+// extension modules it loads.
+func StaticPythonConfig(mode BuildMode, cfg *BindCfg, pycfg PyConfig) PyConfig {
+	if !cfg.Static || pycfg.LibPy == "" {
+		return pycfg
+	}
+	lib := "-l" + pycfg.LibPy
+	var flags []string
+	for _, f := range strings.Fields(pycfg.LdFlags) {
+		if f != lib {
+			flags = append(flags, f)
+			continue
+		}
+		if mode != ModeExe {
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(pycfg.LibDir, "lib"+pycfg.LibPy+".a")); err == nil {
+			f = "-l:lib" + pycfg.LibPy + ".a"
+		}
+		flags = append(flags, f, "-Wl,--export-dynamic")
+	}
+	pycfg.LdFlags = strings.Join(append(flags, "-static-libgcc"), " ")
+	return pycfg
+}
@@ -10,24 +10,90 @@ import (
 )
 
 // goPackage is the fake package that contains all our standard slice / map
-// types that we export
+// types that we export, which comes first in the packages of every binding
 var goPackage *Package
 
 // makeGoPackage
 func makeGoPackage() {
 	gopk := types.NewPackage("go", "go")
 	goPackage = &Package{pkg: gopk, syms: universe, objs: map[string]Object{}}
-	Packages = append(Packages, goPackage)
 }
 
 // addStdSliceMaps adds std Slice and Map types to universe
 func addStdSliceMaps() {
 	makeGoPackage()
 	gopk := goPackage.pkg
-	sltyps := []string{"int", "int64", "int32", "int16", "int8", "uint", "uint64", "uint32", "uint16", "uint8", "bool", "byte", "rune", "float64", "float32", "string"}
+	sltyps := []string{"int", "int64", "int32", "int16", "int8", "uint", "uint64", "uint32", "uint16", "uint8", "bool", "byte", "float64", "float32", "complex128", "complex64", "string"}
 	for _, tn := range sltyps {
 		universe.addSliceType(gopk, nil, types.NewSlice(universe.sym(tn).gotyp), skType, "Slice_"+tn, "[]"+tn)
 	}
+	addRunesType(gopk)
+	addUnsafePointerType(gopk)
+}
+
+// addUnsafePointerType adds unsafe.Pointer as an opaque handle type, wrapped
+// by the go.unsafe_Pointer class, which can only be passed back to Go.
+// The handle keeps the memory it points to alive, until it is released.
+func addUnsafePointerType(gopk *types.Package) {
+	t := types.Typ[types.UnsafePointer]
+	universe.syms[universe.fullTypeString(t)] = &symbol{
+		gopkg:   gopk,
+		gotyp:   t,
+		kind:    skType | skPointer,
+		goname:  "unsafe.Pointer",
+		id:      "unsafe_Pointer",
+		doc:     "unsafe_Pointer is an opaque Go unsafe.Pointer",
+		cgoname: "CGoHandle",
+		cpyname: PyHandle,
+		pysig:   "object",
+		go2py:   "handleFromPtr_unsafe_Pointer",
+		py2go:   "ptrFromHandle_unsafe_Pointer",
+		zval:    "nil",
+	}
+}
+
+// addRunesType adds the conversion of []rune by value to and from python str,
+// instead of a go.Slice_rune class of codepoints.
+func addRunesType(gopk *types.Package) {
+	t := types.NewSlice(universe.sym("rune").gotyp)
+	universe.syms[universe.fullTypeString(t)] = &symbol{
+		gopkg:   gopk,
+		gotyp:   t,
+		kind:    skType | skBasic,
+		goname:  "[]rune",
+		id:      "runes",
+		cpyname: "PyObject*",
+		cgoname: "*C.PyObject",
+		pysig:   "str",
+		go2py:   "runesGoToPy",
+		py2go:   "runesPyToGo",
+		zval:    "nil",
+		pyfmt:   "O&",
+	}
+}
+
+// addBytesTypes replaces the go.Slice_byte class used for []byte in the given
+// symtab with a conversion by value to and from python bytes.  Parameters
+// accept any object supporting the buffer protocol, e.g., bytes, bytearray
+// and memoryview.
+func addBytesTypes(sym *symtab) {
+	for _, tn := range []string{"byte", "uint8"} {
+		t := types.NewSlice(universe.sym(tn).gotyp)
+		sym.syms[sym.fullTypeString(t)] = &symbol{
+			gopkg:   goPackage.pkg,
+			gotyp:   t,
+			kind:    skType | skBasic,
+			goname:  "[]" + tn,
+			id:      "bytes",
+			cpyname: "PyObject*",
+			cgoname: "*C.PyObject",
+			pysig:   "bytes",
+			go2py:   "bytesGoToPy",
+			py2go:   "bytesPyToGo",
+			zval:    "nil",
+			pyfmt:   "O&",
+		}
+	}
 }
 
 // stdBasicTypes returns the basic int, float etc types as symbols
@@ -210,20 +276,20 @@ func stdBasicTypes() map[string]*symbol {
 			pyfmt:   "I",
 		},
 
-		"uint64": {
+		"uint64": { // converted exactly, with overflow checking
 			gopkg:   look("uint64").Pkg(),
 			goobj:   look("uint64"),
 			gotyp:   look("uint64").Type(),
 			kind:    skType | skBasic,
 			goname:  "uint64",
 			id:      "uint64",
-			cpyname: "uint64_t",
-			cgoname: "C.ulonglong",
-			pysig:   "long",
-			go2py:   "C.ulonglong",
-			py2go:   "uint64",
+			cpyname: "PyObject*",
+			cgoname: "*C.PyObject",
+			pysig:   "int",
+			go2py:   "uint64GoToPy",
+			py2go:   "uint64PyToGo",
 			zval:    "0",
-			pyfmt:   "K",
+			pyfmt:   "O&",
 		},
 
 		"uintptr": {
@@ -322,20 +388,20 @@ func stdBasicTypes() map[string]*symbol {
 			pyfmt:   "s",
 		},
 
-		"rune": { // FIXME(sbinet) py2/py3
+		"rune": { // a str of one character
 			gopkg:   look("rune").Pkg(),
 			goobj:   look("rune"),
 			gotyp:   look("rune").Type(),
 			kind:    skType | skBasic,
 			goname:  "rune",
 			id:      "rune",
-			cpyname: "int32_t",
-			cgoname: "C.long",
+			cpyname: "PyObject*",
+			cgoname: "*C.PyObject",
 			pysig:   "str",
-			go2py:   "C.long",
-			py2go:   "rune",
+			go2py:   "runeGoToPy",
+			py2go:   "runePyToGo",
 			zval:    "0",
-			pyfmt:   "i",
+			pyfmt:   "O&",
 		},
 
 		"error": {
@@ -372,20 +438,22 @@ func stdBasicTypes() map[string]*symbol {
 			zval:    "0",
 			pyfmt:   "k",
 		}
-		syms["uint"] = &symbol{
-			gopkg:   look("uint").Pkg(),
-			goobj:   look("uint"),
-			gotyp:   look("uint").Type(),
-			kind:    skType | skBasic,
-			goname:  "uint",
-			id:      "uint",
-			cpyname: "uint64_t",
-			cgoname: "C.ulonglong",
-			pysig:   "int",
-			go2py:   "C.ulonglong",
-			py2go:   "uint",
-			zval:    "0",
-			pyfmt:   "K",
+		syms["uint"] = &symbol{ // converted exactly, as uint64
+			gopkg:        look("uint").Pkg(),
+			goobj:        look("uint"),
+			gotyp:        look("uint").Type(),
+			kind:         skType | skBasic,
+			goname:       "uint",
+			id:           "uint",
+			cpyname:      "PyObject*",
+			cgoname:      "*C.PyObject",
+			pysig:        "int",
+			go2py:        "uint64GoToPy(uint64",
+			go2pyParenEx: ")",
+			py2go:        "uint(uint64PyToGo",
+			py2goParenEx: ")",
+			zval:         "0",
+			pyfmt:        "O&",
 		}
 	}
 
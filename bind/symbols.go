@@ -10,14 +10,10 @@ import (
 	"hash/fnv"
 	"sort"
 	"strings"
-	"sync"
 )
 
-var (
-	universeMutex sync.Mutex
-	universe      *symtab // universe contains Go global types that are not generated
-	current       *symtab // current contains all symbols from current multi-package gen run
-)
+// universe contains Go global types that are not generated
+var universe *symtab
 
 func hash(s string) string {
 	h := fnv.New32a()
@@ -43,6 +39,7 @@ const (
 	skSlice
 	skStruct
 	skString
+	skChan
 )
 
 var (
@@ -61,6 +58,7 @@ var (
 		"slice":     skSlice,
 		"struct":    skStruct,
 		"string":    skString,
+		"chan":      skChan,
 	}
 )
 
@@ -87,9 +85,10 @@ func pySafeName(nm string) string {
 	return nm
 }
 
-// pySafeArg returns an arg name that python will not barf on
+// pySafeArg returns an arg name that python will not barf on -- unnamed
+// and blank args are named by their index, as they cannot be referred to
 func pySafeArg(anm string, idx int) string {
-	if anm == "" {
+	if anm == "" || anm == "_" {
 		anm = fmt.Sprintf("arg_%d", idx)
 	}
 	return pySafeName(anm)
@@ -100,15 +99,12 @@ func isPyCompatVar(v *symbol) error {
 	if v == nil {
 		return fmt.Errorf("gopy: var symbol not found")
 	}
-	if v.isPointer() && v.isBasic() {
+	if v.isPointer() && v.isBasic() && !isOptBasicPtr(v.gotyp) {
 		return fmt.Errorf("gopy: var is pointer to basic type")
 	}
 	if isErrorType(v.gotyp) {
 		return fmt.Errorf("gopy: var is error type")
 	}
-	if _, isChan := v.gotyp.(*types.Chan); isChan {
-		return fmt.Errorf("gopy: var is channel type")
-	}
 	return nil
 }
 
@@ -116,28 +112,37 @@ func isPyCompatVar(v *symbol) error {
 func isPyCompatType(typ types.Type) error {
 	typ = typ.Underlying()
 	if ptyp, isPtr := typ.(*types.Pointer); isPtr {
-		if _, isBasic := ptyp.Elem().(*types.Basic); isBasic {
+		if _, isBasic := ptyp.Elem().(*types.Basic); isBasic && !isOptBasic(ptyp.Elem()) {
 			return fmt.Errorf("gopy: type is pointer to basic type")
 		}
 	}
 	if isErrorType(typ) {
 		return fmt.Errorf("gopy: type is error type")
 	}
-	if _, isChan := typ.(*types.Chan); isChan {
-		return fmt.Errorf("gopy: type is channel type")
-	}
 	return nil
 }
 
+// isOptBasic returns true for the basic types whose pointers are converted
+// by value to and from optional python values, where nil is None: booleans,
+// integers, floats and strings.
+func isOptBasic(typ types.Type) bool {
+	bt, ok := typ.Underlying().(*types.Basic)
+	return ok && bt.Info()&(types.IsBoolean|types.IsInteger|types.IsFloat|types.IsString) != 0
+}
+
+// isOptBasicPtr returns true for pointers to the basic types, e.g., *int,
+// that are converted to and from optional python values, see isOptBasic.
+func isOptBasicPtr(typ types.Type) bool {
+	ptyp, ok := typ.Underlying().(*types.Pointer)
+	return ok && isOptBasic(ptyp.Elem())
+}
+
 // isPyCompatField checks if field is compatible with python
-func isPyCompatField(f *types.Var) (*symbol, error) {
+func (sym *symtab) isPyCompatField(f *types.Var) (*symbol, error) {
 	if !f.Exported() || f.Embedded() {
 		return nil, fmt.Errorf("gopy: field not exported or is embedded")
 	}
-	ftyp := current.symtype(f.Type())
-	if _, isSig := f.Type().Underlying().(*types.Signature); isSig {
-		return nil, fmt.Errorf("gopy: type is function signature")
-	}
+	ftyp := sym.symtype(f.Type())
 	if f.Type().Underlying().String() == "interface{}" {
 		return nil, fmt.Errorf("gopy: type is interface{}")
 	}
@@ -179,12 +184,14 @@ func isPyCompatFunc(sig *types.Signature) (ret types.Type, haserr, hasfun bool,
 		if err = isPyCompatType(ret); err != nil {
 			return
 		}
-		if _, isSig := ret.Underlying().(*types.Signature); isSig {
-			err = fmt.Errorf("gopy: return type is signature")
-			return
+		if rsig, isSig := ret.Underlying().(*types.Signature); isSig {
+			if _, _, _, err = isPyCompatFunc(rsig); err != nil {
+				err = fmt.Errorf("gopy: returned func is not callable from python: %v", err)
+				return
+			}
 		}
-		if ret.Underlying().String() == "interface{}" {
-			err = fmt.Errorf("gopy: return type is interface{}")
+		if _, isNamed := unalias(ret).(*types.Named); isNamed && ret.Underlying().String() == "interface{}" {
+			err = fmt.Errorf("gopy: return type is a named interface{}")
 			return
 		}
 	}
@@ -198,12 +205,7 @@ func isPyCompatFunc(sig *types.Signature) (ret types.Type, haserr, hasfun bool,
 			return
 		}
 		if _, isSig := argt.Underlying().(*types.Signature); isSig {
-			if !hasfun {
-				hasfun = true
-			} else {
-				err = fmt.Errorf("gopy: only one function signature arg allowed: %s", sig.String())
-				return
-			}
+			hasfun = true
 		}
 	}
 	return
@@ -272,6 +274,10 @@ func (s *symbol) isMap() bool {
 	return (s.kind & skMap) != 0
 }
 
+func (s *symbol) isChan() bool {
+	return (s.kind & skChan) != 0
+}
+
 func (s *symbol) isPySequence() bool {
 	return s.isArray() || s.isSlice() || s.isMap()
 }
@@ -299,6 +305,20 @@ func (s *symbol) hasHandle() bool {
 	return !s.isBasic() && !s.isSignature()
 }
 
+// anyResult returns the symbol of interface{} function results, which are
+// deep-converted to python values by valueGoToPy, instead of to the str of
+// their Go format as for elements and fields.
+func (s *symbol) anyResult() *symbol {
+	rs := *s
+	rs.cgoname = "*C.PyObject"
+	rs.cpyname = "PyObject*"
+	rs.pysig = "object"
+	rs.go2py = "valueGoToPy"
+	rs.go2pyParenEx = ""
+	rs.zval = "nil"
+	return &rs
+}
+
 func (s *symbol) hasConverter() bool {
 	return (s.go2py != "" || s.py2go != "")
 }
@@ -340,11 +360,12 @@ func (s *symbol) cgotypename() string {
 	return s.cgoname
 }
 
-// pyPkgId returns the python package-qualified version of Id
-func (s *symbol) pyPkgId(curPkg *types.Package) string {
+// pyPkgId returns the python package-qualified version of Id, as used in
+// the current package of g
+func (s *symbol) pyPkgId(g *pyGen, curPkg *types.Package) string {
 	pnm := s.gopkg.Name()
 	ppath := s.gopkg.Path()
-	if _, has := thePyGen.pkgmap[ppath]; !has { // external symbols are all in go package
+	if _, has := g.pkgmap[ppath]; !has { // external symbols are all in go package
 		if pnm == "go" {
 			return s.id
 		} else {
@@ -354,10 +375,10 @@ func (s *symbol) pyPkgId(curPkg *types.Package) string {
 	if pnm == "go" {
 		return pnm + "." + s.id
 	}
-	if !s.isNamed() && (s.isMap() || s.isSlice() || s.isArray()) {
+	if !s.isNamed() && (s.isMap() || s.isSlice() || s.isArray() || s.isChan() || s.isSignature()) {
 		//		idnm := strings.TrimPrefix(s.id[uidx+1:], pnm+"_") // in case it has that redundantly
 		if ppath != curPkg.Path() {
-			thePyGen.pkg.AddPyImport(ppath, true) // ensure that this is included in current package
+			g.pkg.AddPyImport(ppath, true) // ensure that this is included in current package
 			return pnm + "." + s.id
 		} else {
 			return s.id
@@ -369,7 +390,7 @@ func (s *symbol) pyPkgId(curPkg *types.Package) string {
 	}
 	idnm := strings.TrimPrefix(s.id[uidx+1:], pnm+"_") // in case it has that redundantly
 	if ppath != curPkg.Path() {
-		thePyGen.pkg.AddPyImport(ppath, true) // ensure that this is included in current package
+		g.pkg.AddPyImport(ppath, true) // ensure that this is included in current package
 		return pnm + "." + idnm
 	} else {
 		return idnm
@@ -387,18 +408,26 @@ type symtab struct {
 	importNames map[string]string // package name to path map -- for detecting name conflicts
 	uniqName    byte              // char for making package name unique
 	parent      *symtab
+	cfg         *BindCfg // options of the binding the symbols are added for
+	// anonStructs maps the full type strings of the anonymous struct types
+	// that are bound to the synthetic named types wrapping them, which are
+	// named after where they are first used, e.g., Stats_Result for the
+	// result of func Stats.  The synthetic types stand in for the anonymous
+	// types in the symbol table, but the Go code uses the struct literals.
+	anonStructs map[string]*types.Named
 }
 
-func newSymtab(pkg *types.Package, parent *symtab) *symtab {
+func newSymtab(cfg *BindCfg, parent *symtab) *symtab {
 	if parent == nil {
 		parent = universe
 	}
 	s := &symtab{
-		pkg:         pkg,
 		syms:        make(map[string]*symbol),
 		imports:     make(map[string]string),
 		importNames: make(map[string]string),
 		parent:      parent,
+		cfg:         cfg,
+		anonStructs: make(map[string]*types.Named),
 	}
 	return s
 }
@@ -464,9 +493,90 @@ func (sym *symtab) typeof(n string) *symbol {
 	}
 }
 
+// resolveAlias returns the given type with all aliases within it resolved
+// to their targets, so that aliased types share the symbol of their target,
+// and with the anonymous struct types that are bound replaced by their
+// synthetic named types, see anonStructs.
+func (sym *symtab) resolveAlias(t types.Type) types.Type {
+	return mapType(t, func(t types.Type) types.Type {
+		t = unalias(t)
+		if st, ok := t.(*types.Struct); ok {
+			if nt, has := sym.anonStructs[types.TypeString(st, nil)]; has {
+				return nt
+			}
+		}
+		return t
+	})
+}
+
+// mapType returns the given type rebuilt with each of the types within it
+// replaced by the given func of it, which is applied before looking into
+// pointer, slice, array, chan, map and func types.
+func mapType(t types.Type, fn func(types.Type) types.Type) types.Type {
+	switch tt := fn(t).(type) {
+	case *types.Pointer:
+		if et := mapType(tt.Elem(), fn); et != tt.Elem() {
+			return types.NewPointer(et)
+		}
+		return tt
+	case *types.Slice:
+		if et := mapType(tt.Elem(), fn); et != tt.Elem() {
+			return types.NewSlice(et)
+		}
+		return tt
+	case *types.Array:
+		if et := mapType(tt.Elem(), fn); et != tt.Elem() {
+			return types.NewArray(et, tt.Len())
+		}
+		return tt
+	case *types.Chan:
+		if et := mapType(tt.Elem(), fn); et != tt.Elem() {
+			return types.NewChan(tt.Dir(), et)
+		}
+		return tt
+	case *types.Map:
+		kt, et := mapType(tt.Key(), fn), mapType(tt.Elem(), fn)
+		if kt != tt.Key() || et != tt.Elem() {
+			return types.NewMap(kt, et)
+		}
+		return tt
+	case *types.Signature:
+		if tt.Recv() != nil || tt.TypeParams().Len() > 0 {
+			return tt
+		}
+		params, pch := mapTuple(tt.Params(), fn)
+		results, rch := mapTuple(tt.Results(), fn)
+		if pch || rch {
+			return types.NewSignatureType(nil, nil, nil, params, results, tt.Variadic())
+		}
+		return tt
+	default:
+		return tt
+	}
+}
+
+// mapTuple returns the tuple with the types of its vars mapped as in
+// mapType, and whether any were changed.
+func mapTuple(tup *types.Tuple, fn func(types.Type) types.Type) (*types.Tuple, bool) {
+	if tup == nil {
+		return nil, false
+	}
+	changed := false
+	vars := make([]*types.Var, tup.Len())
+	for i := range vars {
+		v := tup.At(i)
+		vars[i] = v
+		if vt := mapType(v.Type(), fn); vt != v.Type() {
+			vars[i] = types.NewParam(v.Pos(), v.Pkg(), v.Name(), vt)
+			changed = true
+		}
+	}
+	return types.NewTuple(vars...), changed
+}
+
 // fullTypeString returns the fully-qualified type string with entire package import path
 func (sym *symtab) fullTypeString(t types.Type) string {
-	return types.TypeString(t, nil)
+	return types.TypeString(sym.resolveAlias(t), nil)
 }
 
 func (sym *symtab) symtype(t types.Type) *symbol {
@@ -476,6 +586,7 @@ func (sym *symtab) symtype(t types.Type) *symbol {
 
 // typePkg gets the package for a given types.Type
 func (sym *symtab) typePkg(t types.Type) *types.Package {
+	t = unalias(t)
 	if tn, ok := t.(*types.Named); ok {
 		return tn.Obj().Pkg()
 	}
@@ -489,28 +600,60 @@ func (sym *symtab) typePkg(t types.Type) *types.Package {
 // typeGoName returns the go type name that is always qualified by an appropriate package name
 // this should always be used for "goname" in general.
 func (sym *symtab) typeGoName(t types.Type) string {
-	return types.TypeString(t, func(pkg *types.Package) string {
-		pnm := sym.addImport(pkg) // always make sure
-		return pnm
-	})
+	return types.TypeString(sym.anonLiteral(t), sym.qualifier)
+}
+
+// qualifier returns the unique name of the given package, adding it to
+// the imports as needed, for qualifying type names.
+func (sym *symtab) qualifier(pkg *types.Package) string {
+	return sym.addImport(pkg) // always make sure
 }
 
 // typeIdName returns typeGoName with . -> _ -- this should always be used for id
 func (sym *symtab) typeIdName(t types.Type) string {
-	idn := strings.Replace(sym.typeGoName(t), ".", "_", -1)
+	t = sym.resolveAlias(t)
+	if nt, ok := t.(*types.Named); ok && nt.TypeArgs().Len() > 0 {
+		targs := make([]types.Type, nt.TypeArgs().Len())
+		for i := range targs {
+			targs[i] = nt.TypeArgs().At(i)
+		}
+		return sym.instanceIdName(nt.Obj().Pkg(), nt.Obj().Name(), targs)
+	}
+	if pt, ok := t.(*types.Pointer); ok {
+		if nt, ok := pt.Elem().(*types.Named); ok && nt.TypeArgs().Len() > 0 {
+			return "Ptr_" + sym.typeIdName(nt)
+		}
+	}
+	idn := strings.Replace(types.TypeString(t, sym.qualifier), ".", "_", -1)
 	if _, isary := t.(*types.Array); isary {
 		idn = strings.Replace(idn, "[", "Array_", 1)
 		idn = strings.Replace(idn, "]", "_", 1)
 	}
+	idn = strings.Replace(idn, "<-chan ", "RecvChan_", -1)
+	idn = strings.Replace(idn, "chan<- ", "SendChan_", -1)
+	idn = strings.Replace(idn, "chan ", "Chan_", -1)
 	idn = strings.Replace(idn, "[]", "Slice_", -1)
 	idn = strings.Replace(idn, "map[", "Map_", -1)
 	idn = strings.Replace(idn, "[", "_", -1)
 	idn = strings.Replace(idn, "]", "_", -1)
 	idn = strings.Replace(idn, "{}", "_", -1)
 	idn = strings.Replace(idn, "*", "Ptr_", -1)
+	idn = strings.Replace(idn, ", ", "_", -1) // type args of generic instantiations
 	return idn
 }
 
+// instanceIdName returns the id for the instantiation of the generic type
+// or func of given name with the given type args, e.g., pkg_List_int for
+// pkg.List[int] -- the package prefix is dropped for args in the same package.
+func (sym *symtab) instanceIdName(pkg *types.Package, name string, targs []types.Type) string {
+	pnm := sym.addImport(pkg)
+	idn := name
+	for _, ta := range targs {
+		idn += "_" + strings.TrimPrefix(sym.typeIdName(ta), pnm+"_")
+	}
+	return pnm + "_" + toIdent(idn)
+}
+
 func (sym *symtab) addSymbol(obj types.Object) error {
 	fn := types.ObjectString(obj, nil)
 	n := obj.Name()
@@ -571,15 +714,15 @@ func (sym *symtab) addSymbol(obj types.Object) error {
 			}
 			return sym.processTuple(sig.Results())
 		}
-		if !NoWarn {
+		if !sym.cfg.NoWarn {
 			fmt.Printf("ignoring python incompatible function: %v.%v: %v: %v\n", pkgnm, obj.String(), sig.String(), err)
 		}
 
 	case *types.TypeName:
-		// tn := obj.(*types.TypeName)
-		// if tn.IsAlias() {
-		// 	fmt.Printf("type is an alias: %v\n", tn)
-		// }
+		if obj.(*types.TypeName).IsAlias() { // the target is bound instead
+			_, err := sym.addTypeIfNew(sym.resolveAlias(obj.Type()))
+			return err
+		}
 		return sym.addType(obj, obj.Type())
 
 	default:
@@ -661,8 +804,12 @@ func (sym *symtab) buildTuple(tuple *types.Tuple, varnm string, methvar string)
 		switch {
 		case vsym.goname == "interface{}":
 			bstr += fmt.Sprintf("C.PyTuple_SetItem(%s, %d, C.gopy_build_string(%s(%s)%s))\n", varnm, i, vsym.go2py, anm, vsym.go2pyParenEx)
-		case vsym.hasHandle(): // note: assuming int64 handles
-			bstr += fmt.Sprintf("C.PyTuple_SetItem(%s, %d, C.gopy_build_int64(C.int64_t(%s(%s)%s)))\n", varnm, i, vsym.go2py, anm, vsym.go2pyParenEx)
+		case vsym.isBasic() && vsym.cgoname == "*C.PyObject": // converted by value, e.g., rune
+			bstr += fmt.Sprintf("C.PyTuple_SetItem(%s, %d, %s(%s)%s)\n", varnm, i, vsym.go2py, anm, vsym.go2pyParenEx)
+		case vsym.hasHandle() && !vsym.isPtrOrIface(): // note: assuming int64 handles
+			bstr += fmt.Sprintf("C.PyTuple_SetItem(%s, %d, gopyHandleToPy(%q, %s(&%s)%s))\n", varnm, i, sym.className(typ), vsym.go2py, anm, vsym.go2pyParenEx)
+		case vsym.hasHandle():
+			bstr += fmt.Sprintf("C.PyTuple_SetItem(%s, %d, gopyHandleToPy(%q, %s(%s)%s))\n", varnm, i, sym.className(typ), vsym.go2py, anm, vsym.go2pyParenEx)
 		case isb:
 			bk := bt.Kind()
 			switch {
@@ -684,11 +831,31 @@ func (sym *symtab) buildTuple(tuple *types.Tuple, varnm string, methvar string)
 	return bstr, nil
 }
 
+// className returns the Go name of the struct type that typ is, or points
+// to, whose python class is registered by GoPyRegisterClass, or "" if none.
+func (sym *symtab) className(typ types.Type) string {
+	if ptr, ok := typ.(*types.Pointer); ok {
+		typ = ptr.Elem()
+	}
+	if _, ok := typ.Underlying().(*types.Struct); !ok {
+		return ""
+	}
+	if esym := sym.symtype(typ); esym != nil {
+		return esym.goname
+	}
+	return ""
+}
+
 // pyObjectToGo returns code that decodes a PyObject variable of name objnm into a basic go type
 func (sym *symtab) pyObjectToGo(typ types.Type, sy *symbol, objnm string) (string, error) {
 	bstr := ""
 	bt, isb := typ.Underlying().(*types.Basic)
+	gonm := sym.typeGoName(typ)
 	switch {
+	case sy.isTextOnly():
+		return "", fmt.Errorf("pyObjectToGo: type cannot be converted from str: %s", typ.String())
+	case sy.isBasic() && sy.cgoname == "*C.PyObject": // converted by value, e.g., rune
+		bstr += fmt.Sprintf("%s(%s)%s", sy.py2go, objnm, sy.py2goParenEx)
 	// case vsym.goname == "interface{}":
 	// 	bstr += fmt.Sprintf("C.PyTuple_SetItem(%s, %d, C.gopy_build_string(%s(%s)%s))\n", varnm, i, vsym.go2py, anm, vsym.go2pyParenEx)
 	// case vsym.hasHandle(): // note: assuming int64 handles
@@ -697,16 +864,20 @@ func (sym *symtab) pyObjectToGo(typ types.Type, sy *symbol, objnm string) (strin
 		bk := bt.Kind()
 		switch {
 		case types.Int <= bk && bk <= types.Int64:
-			bstr += fmt.Sprintf("%s(C.PyLong_AsLongLong(%s))", sy.goname, objnm)
+			bstr += fmt.Sprintf("%s(C.PyLong_AsLongLong(%s))", gonm, objnm)
 		case types.Uint <= bk && bk <= types.Uintptr:
-			bstr += fmt.Sprintf("%s(C.PyLong_AsUnsignedLongLong(%s))", sy.goname, objnm)
+			bstr += fmt.Sprintf("%s(C.PyLong_AsUnsignedLongLong(%s))", gonm, objnm)
 		case types.Float32 <= bk && bk <= types.Float64:
-			bstr += fmt.Sprintf("%s(C.PyFloat_AsDouble(%s))", sy.goname, objnm)
+			bstr += fmt.Sprintf("%s(C.PyFloat_AsDouble(%s))", gonm, objnm)
 		case bk == types.String:
-			bstr += fmt.Sprintf("C.GoString(C.PyBytes_AsString(%s))", objnm)
+			bstr += fmt.Sprintf("%s(C.GoString(C.gopy_string(%s)))", gonm, objnm)
 		case bk == types.Bool:
-			bstr += fmt.Sprintf("boolPyToGo(C.char(C.PyLong_AsLongLong(%s)))", objnm)
+			bstr += fmt.Sprintf("%s(boolPyToGo(C.char(C.PyObject_IsTrue(%s))))", gonm, objnm)
+		default:
+			return "", fmt.Errorf("pyObjectToGo: type not handled: %s", typ.String())
 		}
+	case sy.hasHandle():
+		bstr += fmt.Sprintf("%s(CGoHandle(C.gopy_handle_of(%s)))%s", sy.py2go, objnm, sy.py2goParenEx)
 	default:
 		return "", fmt.Errorf("pyObjectToGo: type not handled: %s", typ.String())
 	}
@@ -779,7 +950,23 @@ func (sym *symtab) addTypeIfNew(t types.Type) (*symbol, error) {
 	return tsym, nil
 }
 
+// addType adds the symbol of the given type, falling back on its conversion
+// to a python str with -text if it is otherwise unsupported, see textSymbol.
 func (sym *symtab) addType(obj types.Object, t types.Type) error {
+	err := sym.addGoType(obj, t)
+	if err == nil || !sym.cfg.Text {
+		return err
+	}
+	t = sym.resolveAlias(t)
+	if tsym := sym.textSymbol(obj, t); tsym != nil {
+		sym.syms[sym.fullTypeString(t)] = tsym
+		return nil
+	}
+	return err
+}
+
+func (sym *symtab) addGoType(obj types.Object, t types.Type) error {
+	t = sym.resolveAlias(t)
 	fn := sym.fullTypeString(t)
 	n, id, pkg := sym.typeNamePkg(t)
 	kind := skType
@@ -792,6 +979,10 @@ func (sym *symtab) addType(obj types.Object, t types.Type) error {
 		}
 
 	case *types.Pointer:
+		if vc := sym.findValueConv(fn); vc != nil {
+			sym.syms[fn] = vc.symbol(pkg, obj, t, id, n)
+			return nil
+		}
 		return sym.addPointerType(pkg, obj, t, kind, id, n)
 
 	case *types.Array:
@@ -810,12 +1001,16 @@ func (sym *symtab) addType(obj types.Object, t types.Type) error {
 		return sym.addInterfaceType(pkg, obj, t, kind, id, n)
 
 	case *types.Chan:
-		return fmt.Errorf("gopy: channel type not supported: %s\n", n)
+		return sym.addChanType(pkg, obj, t, kind, id, n)
 
 	case *types.Named:
 		if !typ.Obj().Exported() {
 			return fmt.Errorf("gopy: non-exported named type: %s\n", n)
 		}
+		if vc := sym.findValueConv(fn); vc != nil {
+			sym.syms[fn] = vc.symbol(pkg, obj, t, id, n)
+			return nil
+		}
 		kind |= skNamed
 		var err error
 		switch st := typ.Underlying().(type) {
@@ -831,13 +1026,13 @@ func (sym *symtab) addType(obj types.Object, t types.Type) error {
 			py2goParEx := ""
 			if styp.py2go != "" {
 				py2go += "(" + styp.py2go
-				py2goParEx = ")"
+				py2goParEx = ")" + styp.py2goParenEx
 			}
 			go2py := styp.goname
 			go2pyParEx := ""
 			if styp.go2py != "" {
 				go2py = styp.go2py + "(" + go2py
-				go2pyParEx = ")"
+				go2pyParEx = ")" + styp.go2pyParenEx
 			}
 
 			sym.syms[fn] = &symbol{
@@ -903,18 +1098,107 @@ func (sym *symtab) addType(obj types.Object, t types.Type) error {
 	return nil
 }
 
+// valueConv is a conversion by value of a Go named type to and from a python
+// type, which is used instead of wrapping values of it as handles.
+type valueConv struct {
+	typ     string                  // full Go type name
+	enabled func(cfg *BindCfg) bool // option turning the conversion on
+	pysig   string
+	go2py   string
+	py2go   string
+	zval    string
+}
+
+// valueConvs are the available conversions by value, each of which is
+// turned on by its own option.
+var valueConvs = []valueConv{
+	{typ: "time.Time", enabled: dateTimeOpt, pysig: "datetime", go2py: "timeGoToPy", py2go: "timePyToGo", zval: "time.Time{}"},
+	{typ: "time.Duration", enabled: dateTimeOpt, pysig: "timedelta", go2py: "durationGoToPy", py2go: "durationPyToGo", zval: "0"},
+	{typ: "*math/big.Int", enabled: bigNumOpt, pysig: "int", go2py: "bigIntGoToPy", py2go: "bigIntPyToGo", zval: "nil"},
+	{typ: "*math/big.Float", enabled: bigNumOpt, pysig: "float", go2py: "bigFloatGoToPy", py2go: "bigFloatPyToGo", zval: "nil"},
+	{typ: rawJSONType, enabled: jsonOpt, pysig: "object", go2py: "rawJSONGoToPy", py2go: "rawJSONPyToGo", zval: "nil"},
+	{typ: "encoding/json/jsontext.Value", enabled: jsonOpt, pysig: "object", go2py: "rawJSONGoToPy", py2go: "rawJSONPyToGo", zval: "nil"}, // json.RawMessage is an alias of it with json v2
+}
+
+// the options turning the conversions by value on
+func dateTimeOpt(cfg *BindCfg) bool { return cfg.DateTime }
+func bigNumOpt(cfg *BindCfg) bool   { return cfg.BigNum }
+func jsonOpt(cfg *BindCfg) bool     { return cfg.JSON }
+
+// findValueConv returns the enabled conversion by value for the given
+// full type name, if any.
+func (sym *symtab) findValueConv(fn string) *valueConv {
+	for i := range valueConvs {
+		vc := &valueConvs[i]
+		if vc.typ == fn && vc.enabled(sym.cfg) {
+			return vc
+		}
+	}
+	return nil
+}
+
+// symbol returns the symbol of the converted type, which is handled as a
+// basic type passed by value as a python object.
+func (vc *valueConv) symbol(pkg *types.Package, obj types.Object, t types.Type, id, n string) *symbol {
+	return &symbol{
+		gopkg:   pkg,
+		goobj:   obj,
+		gotyp:   t,
+		kind:    skType | skBasic,
+		id:      id,
+		goname:  n,
+		cgoname: "*C.PyObject",
+		cpyname: "PyObject*",
+		pysig:   vc.pysig,
+		go2py:   vc.go2py,
+		py2go:   vc.py2go,
+		zval:    vc.zval,
+	}
+}
+
 func (sym *symtab) addArrayType(pkg *types.Package, obj types.Object, t types.Type, kind symkind, id, n string) error {
 	fn := sym.fullTypeString(t)
 	typ := t.Underlying().(*types.Array)
 	kind |= skArray
+	// add our type first before adding the elements -- prevents loops!
+	asym := &symbol{
+		gopkg:   pkg,
+		goobj:   obj,
+		gotyp:   t,
+		kind:    kind,
+		id:      id,
+		goname:  n,
+		cgoname: "CGoHandle", // handles
+		cpyname: PyHandle,
+		pysig:   "object",
+		go2py:   "handleFromPtr_" + id,
+		py2go:   "deptrFromHandle_" + id,
+		zval:    "nil",
+	}
+	sym.syms[fn] = asym
 	elt := typ.Elem()
 	elsym, err := sym.addTypeIfNew(elt)
 	if err != nil {
+		delete(sym.syms, fn)
 		return err
 	}
 	if elsym.isSignature() {
+		delete(sym.syms, fn)
 		return fmt.Errorf("gopy: array value type cannot be signature / func: %q", elsym.goname)
 	}
+	if elsym.isText() {
+		delete(sym.syms, fn)
+		return fmt.Errorf("gopy: array value type cannot be converted to str: %q", elsym.goname)
+	}
+	asym.pysig = fmt.Sprintf("[%d]%s", typ.Len(), elsym.pysig)
+	return nil
+}
+
+func (sym *symtab) addMapType(pkg *types.Package, obj types.Object, t types.Type, kind symkind, id, n string) error {
+	fn := sym.fullTypeString(t)
+	typ := t.Underlying().(*types.Map)
+	kind |= skMap
+	// add our type first before adding the elements -- prevents loops!
 	sym.syms[fn] = &symbol{
 		gopkg:   pkg,
 		goobj:   obj,
@@ -922,36 +1206,44 @@ func (sym *symtab) addArrayType(pkg *types.Package, obj types.Object, t types.Ty
 		kind:    kind,
 		id:      id,
 		goname:  n,
-		cgoname: "CGoHandle", // handles
+		cgoname: "CGoHandle",
 		cpyname: PyHandle,
-		pysig:   "[]" + elsym.pysig,
+		pysig:   "object",
 		go2py:   "handleFromPtr_" + id,
 		py2go:   "deptrFromHandle_" + id,
 		zval:    "nil",
 	}
-	return nil
-}
-
-func (sym *symtab) addMapType(pkg *types.Package, obj types.Object, t types.Type, kind symkind, id, n string) error {
-	fn := sym.fullTypeString(t)
-	typ := t.Underlying().(*types.Map)
-	kind |= skMap
 	elt := typ.Elem()
 	elsym, err := sym.addTypeIfNew(elt)
 	if err != nil {
+		delete(sym.syms, fn)
 		return err
 	}
 	if elsym.isSignature() {
+		delete(sym.syms, fn)
 		return fmt.Errorf("gopy: map value type cannot be signature / func: %q", elsym.goname)
 	}
+	if elsym.isText() {
+		delete(sym.syms, fn)
+		return fmt.Errorf("gopy: map value type cannot be converted to str: %q", elsym.goname)
+	}
 	// add type for keys method
 	keyt := typ.Key()
 	keyslt := types.NewSlice(keyt)
 	_, err = sym.addTypeIfNew(keyslt)
 	if err != nil {
+		delete(sym.syms, fn)
 		return err
 	}
-	sym.syms[fn] = &symbol{
+	return nil
+}
+
+func (sym *symtab) addSliceType(pkg *types.Package, obj types.Object, t types.Type, kind symkind, id, n string) error {
+	fn := sym.fullTypeString(t)
+	typ := t.Underlying().(*types.Slice)
+	kind |= skSlice
+	// add our type first before adding the elements -- prevents loops!
+	ssym := &symbol{
 		gopkg:   pkg,
 		goobj:   obj,
 		gotyp:   t,
@@ -965,22 +1257,31 @@ func (sym *symtab) addMapType(pkg *types.Package, obj types.Object, t types.Type
 		py2go:   "deptrFromHandle_" + id,
 		zval:    "nil",
 	}
-	return nil
-}
-
-func (sym *symtab) addSliceType(pkg *types.Package, obj types.Object, t types.Type, kind symkind, id, n string) error {
-	fn := sym.fullTypeString(t)
-	typ := t.Underlying().(*types.Slice)
-	kind |= skSlice
+	sym.syms[fn] = ssym
 	elt := typ.Elem()
 	elsym, err := sym.addTypeIfNew(elt)
 	if err != nil {
+		delete(sym.syms, fn)
 		return err
 	}
 	if elsym.isSignature() {
+		delete(sym.syms, fn)
 		return fmt.Errorf("gopy: slice value type cannot be signature / func: %q", elsym.goname)
 	}
-	sym.syms[fn] = &symbol{
+	if elsym.isText() {
+		delete(sym.syms, fn)
+		return fmt.Errorf("gopy: slice value type cannot be converted to str: %q", elsym.goname)
+	}
+	ssym.pysig = "[]" + elsym.pysig
+	return nil
+}
+
+func (sym *symtab) addChanType(pkg *types.Package, obj types.Object, t types.Type, kind symkind, id, n string) error {
+	fn := sym.fullTypeString(t)
+	typ := t.Underlying().(*types.Chan)
+	kind |= skChan
+	// add our type first before adding the elements -- prevents loops!
+	csym := &symbol{
 		gopkg:   pkg,
 		goobj:   obj,
 		gotyp:   t,
@@ -989,11 +1290,27 @@ func (sym *symtab) addSliceType(pkg *types.Package, obj types.Object, t types.Ty
 		goname:  n,
 		cgoname: "CGoHandle",
 		cpyname: PyHandle,
-		pysig:   "[]" + elsym.pysig,
+		pysig:   "object",
 		go2py:   "handleFromPtr_" + id,
 		py2go:   "deptrFromHandle_" + id,
 		zval:    "nil",
 	}
+	sym.syms[fn] = csym
+	elt := typ.Elem()
+	elsym, err := sym.addTypeIfNew(elt)
+	if err != nil {
+		delete(sym.syms, fn)
+		return err
+	}
+	if elsym.isSignature() {
+		delete(sym.syms, fn)
+		return fmt.Errorf("gopy: channel value type cannot be signature / func: %q", elsym.goname)
+	}
+	if elsym.isText() {
+		delete(sym.syms, fn)
+		return fmt.Errorf("gopy: channel value type cannot be converted to str: %q", elsym.goname)
+	}
+	csym.pysig = strings.Replace(n, elsym.goname, elsym.pysig, 1)
 	return nil
 }
 
@@ -1045,80 +1362,11 @@ func (sym *symtab) addSignatureType(pkg *types.Package, obj types.Object, t type
 	kind |= skSignature
 
 	sig := t.Underlying().(*types.Signature)
-	args := sig.Params()
-	nargs := args.Len()
-	rets := sig.Results()
-	nsig := sym.typeGoName(t.Underlying())
-
-	if rets.Len() > 1 {
-		return fmt.Errorf("multiple return values not supported")
-	}
-	retstr := ""
-	var ret *types.Var
-	var rsym *symbol
-
-	if rets.Len() == 1 {
-		retstr = "_fcret := "
-		ret = rets.At(0)
-		rsym = sym.symtype(ret.Type())
-		if rsym == nil {
-			return fmt.Errorf("return type not supported: %s", n)
-		}
-	}
-
-	if nargs > 0 { // need to deal with unnamed args
-		nsig = "func ("
-		for i := 0; i < nargs; i++ {
-			v := args.At(i)
-			typ := v.Type()
-			anm := pySafeArg(v.Name(), i)
-			if i > 0 {
-				nsig += ", "
-			}
-			nsig += anm + " " + sym.typeGoName(typ)
-		}
-		nsig += ")"
-		if rets.Len() == 1 {
-			nsig += " " + sym.typeGoName(ret.Type())
-		}
-	}
-
-	py2g := fmt.Sprintf("%s { ", nsig)
-
-	// TODO: use strings.Builder
-	if rets.Len() == 0 {
-		py2g += "if C.PyCallable_Check(_fun_arg) == 0 { return }\n"
-	} else {
-		zstr, err := sym.ZeroToGo(ret.Type(), rsym)
-		if err != nil {
-			return err
-		}
-		py2g += fmt.Sprintf("if C.PyCallable_Check(_fun_arg) == 0 { return %s }\n", zstr)
+	if sig.Variadic() {
+		return fmt.Errorf("gopy: variadic func signature not supported: %s", n)
 	}
-	py2g += "_gstate := C.PyGILState_Ensure()\n"
-	if nargs > 0 {
-		bstr, err := sym.buildTuple(args, "_fcargs", "_fun_arg")
-		if err != nil {
-			return err
-		}
-		py2g += bstr + retstr
-		py2g += fmt.Sprintf("C.PyObject_CallObject(_fun_arg, _fcargs)\n")
-		py2g += "C.gopy_decref(_fcargs)\n"
-	} else {
-		// TODO: methods not supported for no-args case -- requires self arg..
-		py2g += retstr + "C.PyObject_CallObject(_fun_arg, nil)\n"
-	}
-	py2g += "C.gopy_err_handle()\n"
-	py2g += "C.PyGILState_Release(_gstate)\n"
-	if rets.Len() == 1 {
-		cvt, err := sym.pyObjectToGo(ret.Type(), rsym, "_fcret")
-		if err != nil {
-			return err
-		}
-		py2g += fmt.Sprintf("return %s", cvt)
-	}
-	py2g += "}"
-
+	id = toIdent(id)
+	// add our type first before checking the args and results -- prevents loops!
 	sym.syms[fn] = &symbol{
 		gopkg:   pkg,
 		goobj:   obj,
@@ -1129,17 +1377,61 @@ func (sym *symtab) addSignatureType(pkg *types.Package, obj types.Object, t type
 		cgoname: "*C.PyObject",
 		cpyname: "PyObject*",
 		pysig:   "callable",
-		go2py:   "?",
-		py2go:   py2g,
+		go2py:   "handleFromPtr_" + id,
+		py2go:   "callbackFromPy_" + id,
+		zval:    "nil",
+	}
+	// check that the args and results can be converted
+	if sig.Params().Len() > 0 {
+		if _, err := sym.buildTuple(sig.Params(), "_fcargs", ""); err != nil {
+			delete(sym.syms, fn)
+			return err
+		}
+	}
+	if _, err := sym.callbackResult(sig); err != nil {
+		delete(sym.syms, fn)
+		return err
 	}
 	return nil
 }
 
+// callbackResult returns the type of the value result of the given
+// callback signature, or nil if it has none.  The supported results are
+// a single value, a single error, or a value and an error.
+func (sym *symtab) callbackResult(sig *types.Signature) (types.Type, error) {
+	rets := sig.Results()
+	var ret types.Type
+	switch rets.Len() {
+	case 0:
+		return nil, nil
+	case 1:
+		if isErrorType(rets.At(0).Type()) {
+			return nil, nil
+		}
+		ret = rets.At(0).Type()
+	case 2:
+		if !isErrorType(rets.At(1).Type()) {
+			return nil, fmt.Errorf("gopy: second result value must be of type error: %s", sig.String())
+		}
+		ret = rets.At(0).Type()
+	default:
+		return nil, fmt.Errorf("gopy: too many results to return: %s", sig.String())
+	}
+	rsym, err := sym.addTypeIfNew(ret)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := sym.pyObjectToGo(ret, rsym, "_fcret"); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
 func (sym *symtab) addMethod(pkg *types.Package, obj types.Object, t types.Type, kind symkind, id, n string) error {
 	sig := t.Underlying().(*types.Signature)
 	_, _, _, err := isPyCompatFunc(sig)
 	if err != nil {
-		if !NoWarn {
+		if !sym.cfg.NoWarn {
 			fmt.Printf("ignoring python incompatible method: %v.%v: %v: %v\n", pkg.Name(), obj.String(), t.String(), err)
 		}
 	}
@@ -1174,6 +1466,29 @@ func (sym *symtab) addPointerType(pkg *types.Package, obj types.Object, t types.
 			return fmt.Errorf("gopy: could not retrieve symbol for %q", sym.fullTypeString(etyp))
 		}
 	}
+	if esym.isText() { // the pointer is converted instead
+		return fmt.Errorf("gopy: pointer to type converted to str: %q", n)
+	}
+
+	if isOptBasic(etyp) {
+		// optional value, None for nil
+		sym.syms[fn] = &symbol{
+			gopkg:        pkg,
+			goobj:        obj,
+			gotyp:        t,
+			kind:         esym.kind | skPointer,
+			id:           id,
+			goname:       n,
+			cgoname:      "*C.PyObject",
+			cpyname:      "PyObject*",
+			pysig:        "Optional[" + esym.pysig + "]",
+			go2py:        "optGoToPy",
+			py2go:        fmt.Sprintf("optPyToGo(reflect.TypeOf((%s)(nil)), ", n),
+			py2goParenEx: ").(" + n + ")",
+			zval:         "nil",
+		}
+		return nil
+	}
 
 	sym.syms[fn] = &symbol{
 		gopkg:   pkg,
@@ -1246,12 +1561,10 @@ func (sym *symtab) print() {
 
 func init() {
 
-	universe = newSymtab(nil, nil)
+	universe = newSymtab(&BindCfg{}, nil)
 	universe.parent = nil
 
 	universe.syms = stdBasicTypes()
 
 	addStdSliceMaps()
-
-	current = newSymtab(nil, universe)
 }
@@ -6,9 +6,10 @@ package bind
 
 import (
 	"fmt"
+	"go/constant"
+	"go/token"
 	"go/types"
 	"sort"
-	"strconv"
 )
 
 type Object interface {
@@ -64,6 +65,8 @@ type Protocol int
 
 const (
 	ProtoStringer Protocol = 1 << iota
+	ProtoError
+	ProtoCloser
 )
 
 // Struct collects information about a go struct.
@@ -78,7 +81,8 @@ type Struct struct {
 	meths []*Func
 	idx   int // index position in list of structs
 
-	prots Protocol
+	prots   Protocol
+	pynames []string // python names of the fields, see pyFieldNames
 }
 
 func newStruct(p *Package, obj *types.TypeName) (*Struct, error) {
@@ -123,8 +127,22 @@ func (s *Struct) Struct() *types.Struct {
 	return s.sym.GoType().Underlying().(*types.Struct)
 }
 
+// hasMethod returns true if the struct has a bound method of the given
+// Go name, not counting the methods inherited from its python base class
+func (s *Struct) hasMethod(name string) bool {
+	for _, m := range s.meths {
+		if m.GoName() == name {
+			return true
+		}
+	}
+	return false
+}
+
 // FirstEmbed returns the first field if it is embedded,
-// supporting convention of placing embedded "parent" types first
+// supporting convention of placing embedded "parent" types first.
+// Interfaces of other packages have no python class to derive from,
+// so their methods are promoted instead, see promotedMethods, and
+// interfaces are only derived from if their methods are not ambiguous.
 func (s *Struct) FirstEmbed() *symbol {
 	st := s.Struct()
 	numFields := st.NumFields()
@@ -135,13 +153,62 @@ func (s *Struct) FirstEmbed() *symbol {
 	if !f.Embedded() {
 		return nil
 	}
-	ftyp := current.symtype(f.Type())
+	ftyp := s.pkg.syms.symtype(f.Type())
 	if ftyp == nil {
 		return nil
 	}
+	if ftyp.isInterface() {
+		iface := f.Type().Underlying().(*types.Interface)
+		if ftyp.gopkg != s.pkg.pkg || !types.Implements(types.NewPointer(s.GoType()), iface) {
+			return nil
+		}
+	}
 	return ftyp
 }
 
+// embeddedStruct returns the struct type of the same package that the
+// embedded field f embeds, by value or by pointer, or nil if it is not one.
+func (s *Struct) embeddedStruct(f *types.Var) *types.Named {
+	if !f.Embedded() {
+		return nil
+	}
+	typ := f.Type()
+	if ptr, ok := typ.(*types.Pointer); ok {
+		typ = ptr.Elem()
+	}
+	named, ok := typ.(*types.Named)
+	if !ok || named.Obj().Pkg() != s.pkg.pkg {
+		return nil
+	}
+	if _, ok := named.Underlying().(*types.Struct); !ok {
+		return nil
+	}
+	return named
+}
+
+// promotedMethods returns the exported methods promoted to the struct
+// from its embedded interfaces, and structs of the same package, other
+// than those inherited from its python base class, see FirstEmbed.
+// Ambiguous and shadowed methods are not in the method set, as in Go.
+func (s *Struct) promotedMethods() []*types.Func {
+	st := s.Struct()
+	base := s.FirstEmbed() != nil
+	mset := types.NewMethodSet(types.NewPointer(s.GoType()))
+	var meths []*types.Func
+	for i := 0; i < mset.Len(); i++ {
+		sel := mset.At(i)
+		idx := sel.Index()
+		if len(idx) != 2 || !sel.Obj().Exported() || (idx[0] == 0 && base) {
+			continue
+		}
+		if f := st.Field(idx[0]); !f.Embedded() || (!types.IsInterface(f.Type()) && s.embeddedStruct(f) == nil) {
+			continue
+		}
+		meths = append(meths, sel.Obj().(*types.Func))
+	}
+	return meths
+}
+
 ///////////////////////////////////////////////////////////////////////////////////
 //  Interface
 
@@ -324,10 +391,20 @@ func newSignatureFrom(pkg *Package, sig *types.Signature) (*Signature, error) {
 	if err != nil {
 		return nil, err
 	}
+	for _, r := range rv {
+		if r.sym.goname == "interface{}" {
+			r.sym = r.sym.anyResult()
+		}
+	}
 	av, err := newVarsFrom(pkg, sig.Params())
 	if err != nil {
 		return nil, err
 	}
+	for _, a := range av {
+		if a.sym.isTextOnly() {
+			return nil, fmt.Errorf("gopy: parameter type cannot be converted from str: %s", a.sym.goname)
+		}
+	}
 
 	return &Signature{
 		ret:  rv,
@@ -373,7 +450,9 @@ type Func struct {
 	err        bool       // true if original go func has comma-error
 	ctor       bool       // true if this is a newXXX function
 	hasfun     bool       // true if this function has a function argument
+	hasctx     bool       // true if the first arg is a context.Context, injected from python
 	isVariadic bool       // True, if this is a variadic function.
+	inst       string     // go expression for an instantiation of a generic func, e.g., Max[int]
 }
 
 func newFuncFrom(p *Package, parent string, obj types.Object, sig *types.Signature) (*Func, error) {
@@ -382,9 +461,10 @@ func newFuncFrom(p *Package, parent string, obj types.Object, sig *types.Signatu
 		return nil, err
 	}
 
-	id := obj.Pkg().Name() + "_" + obj.Name()
+	// methods promoted from embedded interfaces may be of other packages
+	id := p.pkg.Name() + "_" + obj.Name()
 	if parent != "" {
-		id = obj.Pkg().Name() + "_" + parent + "_" + obj.Name()
+		id = p.pkg.Name() + "_" + parent + "_" + obj.Name()
 	}
 
 	sv, err := newSignatureFrom(p, sig)
@@ -403,6 +483,7 @@ func newFuncFrom(p *Package, parent string, obj types.Object, sig *types.Signatu
 		ret:        ret,
 		err:        haserr,
 		hasfun:     hasfun,
+		hasctx:     sig.Params().Len() > 0 && isContextType(sig.Params().At(0).Type()),
 		isVariadic: sig.Variadic(),
 	}, nil
 
@@ -445,6 +526,9 @@ func (f *Func) GoName() string {
 }
 
 func (f *Func) GoFmt() string {
+	if f.inst != "" {
+		return f.pkg.Name() + "." + f.inst
+	}
 	return f.pkg.Name() + "." + f.name
 }
 
@@ -501,6 +585,7 @@ type Enum struct {
 	id    string
 	doc   string
 	items []*Const
+	flags bool // consts are declared with a shifted iota, see isFlagType
 }
 
 func newEnum(p *Package, o *types.Const) (*Enum, error) {
@@ -511,12 +596,13 @@ func newEnum(p *Package, o *types.Const) (*Enum, error) {
 	doc := p.getDoc("", typ.Obj())
 
 	e := &Enum{
-		pkg: p,
-		sym: sym,
-		obj: o,
-		typ: typ,
-		id:  id,
-		doc: doc,
+		pkg:   p,
+		sym:   sym,
+		obj:   o,
+		typ:   typ,
+		id:    id,
+		doc:   doc,
+		flags: p.isFlagType(typ),
 	}
 	e.AddConst(p, o)
 	return e, nil
@@ -535,11 +621,33 @@ func (e *Enum) AddConst(p *Package, o *types.Const) (*Const, error) {
 	return c, err
 }
 
+// isStr returns true if the consts of the enum are of a named string type.
+func (e *Enum) isStr() bool {
+	return e.typ.Underlying().(*types.Basic).Info()&types.IsString != 0
+}
+
+// pyBase returns the class of the python enum module the enum derives
+// from, see genEnum.
+func (e *Enum) pyBase() string {
+	switch {
+	case e.isStr():
+		return "Enum"
+	case e.flags:
+		return "IntFlag"
+	}
+	return "IntEnum"
+}
+
+// SortConsts sorts the consts of the enum by value, or in declaration order
+// for string enums.
 func (e *Enum) SortConsts() {
 	sort.Slice(e.items, func(i, j int) bool {
-		iv, _ := strconv.Atoi(e.items[i].val)
-		jv, _ := strconv.Atoi(e.items[j].val)
-		return iv < jv
+		vi, vj := e.items[i].obj.Val(), e.items[j].obj.Val()
+		if e.isStr() || constant.Compare(vi, token.EQL, vj) {
+			// the first declared is the canonical member of a python enum
+			return e.items[i].obj.Pos() < e.items[j].obj.Pos()
+		}
+		return constant.Compare(vi, token.LSS, vj)
 	})
 }
 
@@ -24,6 +24,21 @@ func isErrorType(typ types.Type) bool {
 	return typ == types.Universe.Lookup("error").Type()
 }
 
+// isContextType returns true if typ is context.Context, which is injected
+// in the Go calls as their first arg, see Func.hasctx.
+func isContextType(typ types.Type) bool {
+	named, ok := typ.(*types.Named)
+	if !ok || named.Obj().Pkg() == nil {
+		return false
+	}
+	return named.Obj().Pkg().Path() == "context" && named.Obj().Name() == "Context"
+}
+
+// implementsError returns true if typ implements the error interface
+func implementsError(typ types.Type) bool {
+	return types.Implements(typ, types.Universe.Lookup("error").Type().Underlying().(*types.Interface))
+}
+
 func isStringer(obj types.Object) bool {
 	switch obj := obj.(type) {
 	case *types.Func:
@@ -54,6 +69,34 @@ func isStringer(obj types.Object) bool {
 	}
 }
 
+// isCloser returns true if obj is a Close() error method, as of io.Closer
+func isCloser(obj types.Object) bool {
+	fn, ok := obj.(*types.Func)
+	if !ok || fn.Name() != "Close" {
+		return false
+	}
+	sig, ok := fn.Type().(*types.Signature)
+	if !ok || sig.Recv() == nil || sig.Params().Len() != 0 {
+		return false
+	}
+	res := sig.Results()
+	return res.Len() == 1 && isErrorType(res.At(0).Type())
+}
+
+// isZeroer returns true if obj is an IsZero() bool method, as of time.Time
+func isZeroer(obj types.Object) bool {
+	fn, ok := obj.(*types.Func)
+	if !ok || fn.Name() != "IsZero" {
+		return false
+	}
+	sig, ok := fn.Type().(*types.Signature)
+	if !ok || sig.Recv() == nil || sig.Params().Len() != 0 {
+		return false
+	}
+	res := sig.Results()
+	return res.Len() == 1 && types.Identical(res.At(0).Type(), types.Typ[types.Bool])
+}
+
 func hasError(sig *types.Signature) bool {
 	res := sig.Results()
 	if res == nil || res.Len() <= 0 {
@@ -88,9 +131,20 @@ func isConstructor(sig *types.Signature) bool {
 
 type PyConfig struct {
 	Version   int
+	Minor     int
 	CFlags    string
 	LdFlags   string
 	ExtSuffix string
+	// platform of the python build, as returned by sysconfig.get_platform()
+	Platform    string
+	MultiArch   string // e.g., aarch64-linux-gnu, on linux
+	GILDisabled bool
+	// C compiler of the target of a cross-compilation, if needed
+	CC string
+	// directory and name of the python library, e.g., python3.11, as linked
+	// by LdFlags
+	LibDir string
+	LibPy  string
 }
 
 // AllFlags returns CFlags + " " + LdFlags
@@ -105,7 +159,11 @@ func GetPythonConfig(vm string) (PyConfig, error) {
 import distutils.sysconfig as ds
 import json
 import os
+import sysconfig
 version=sys.version_info.major
+# the python.org builds for windows are built with MSVC, whose pyconfig.h
+# only defines MS_WIN64 for MSVC itself, not for the mingw gcc of cgo
+ms_win64 = sys.platform == "win32" and "MSC" in sys.version and sys.maxsize > 2**32
 
 if "GOPY_INCLUDE" in os.environ and "GOPY_LIBDIR" in os.environ and "GOPY_PYLIB" in os.environ:
 	print(json.dumps({
@@ -118,6 +176,10 @@ if "GOPY_INCLUDE" in os.environ and "GOPY_LIBDIR" in os.environ and "GOPY_PYLIB"
 		"syslibs": ds.get_config_var("SYSLIBS"),
 		"shlinks": ds.get_config_var("LINKFORSHARED"),
 		"extsuffix": ds.get_config_var("EXT_SUFFIX"),
+		"platform": sysconfig.get_platform(),
+		"multiarch": ds.get_config_var("MULTIARCH") or "",
+		"gil_disabled": bool(ds.get_config_var("Py_GIL_DISABLED")),
+		"ms_win64": ms_win64,
 }))
 else:
 	print(json.dumps({
@@ -130,9 +192,18 @@ else:
 		"syslibs": ds.get_config_var("SYSLIBS"),
 		"shlinks": ds.get_config_var("LINKFORSHARED"),
 		"extsuffix": ds.get_config_var("EXT_SUFFIX"),
+		"platform": sysconfig.get_platform(),
+		"multiarch": ds.get_config_var("MULTIARCH") or "",
+		"gil_disabled": bool(ds.get_config_var("Py_GIL_DISABLED")),
+		"ms_win64": ms_win64,
 }))
 `
+	return runPythonConfig(vm, code)
+}
 
+// runPythonConfig returns the python configuration printed in JSON by the
+// python code, run by the python VM with the given args.
+func runPythonConfig(vm, code string, args ...string) (PyConfig, error) {
 	var cfg PyConfig
 	bin, err := exec.LookPath(vm)
 	if err != nil {
@@ -140,7 +211,7 @@ else:
 	}
 
 	buf := new(bytes.Buffer)
-	cmd := exec.Command(bin, "-c", code)
+	cmd := exec.Command(bin, append([]string{"-c", code}, args...)...)
 	cmd.Stdin = os.Stdin
 	cmd.Stdout = buf
 	cmd.Stderr = os.Stderr
@@ -158,6 +229,10 @@ else:
 		ShLibs    string `json:"shlibs"`
 		SysLibs   string `json:"syslibs"`
 		ExtSuffix string `json:"extsuffix"`
+		Platform  string `json:"platform"`
+		MultiArch string `json:"multiarch"`
+		GILDis    bool   `json:"gil_disabled"`
+		MSWin64   bool   `json:"ms_win64"`
 	}
 	err = json.NewDecoder(buf).Decode(&raw)
 	if err != nil {
@@ -189,10 +264,19 @@ else:
 	}
 
 	cfg.Version = raw.Version
+	cfg.Minor = raw.Minor
 	cfg.ExtSuffix = raw.ExtSuffix
+	cfg.Platform = raw.Platform
+	cfg.MultiArch = raw.MultiArch
+	cfg.GILDisabled = raw.GILDis
+	cfg.LibDir = raw.LibDir
+	cfg.LibPy = raw.LibPy
 	cfg.CFlags = strings.Join([]string{
 		"-I" + raw.IncDir,
 	}, " ")
+	if raw.MSWin64 {
+		cfg.CFlags += " -DMS_WIN64"
+	}
 	cfg.LdFlags = strings.Join([]string{
 		"-L" + raw.LibDir,
 		"-l" + raw.LibPy,
@@ -216,7 +300,7 @@ func getGoVersion(version string) (int64, int64, error) {
 }
 
 var (
-	rxValidPythonName = regexp.MustCompile(`^[\pL_][\pL_\pN]+$`)
+	rxValidPythonName = regexp.MustCompile(`^[\pL_][\pL_\pN]*$`)
 )
 
 func extractPythonName(gname, gdoc string) (string, string, error) {
@@ -252,22 +336,45 @@ func extractPythonName(gname, gdoc string) (string, string, error) {
 // a new python name. If the tag is not defined then the original
 // name is returned.
 // If the tag name is specified but is an invalid python identifier,
-// then an error is returned.
+// then an error is returned.  A "-" tag name, which hides the field
+// from python, is returned as is.  Any options after a comma, e.g., json,
+// are ignored.
 func extractPythonNameFieldTag(gname, tag string) (string, error) {
 	const tagKey = "gopy"
 	if tag == "" {
 		return gname, nil
 	}
 	tagVal := reflect.StructTag(tag).Get(tagKey)
+	if idx := strings.Index(tagVal, ","); idx >= 0 { // options, e.g., json
+		tagVal = tagVal[:idx]
+	}
 	if tagVal == "" {
 		return gname, nil
 	}
-	if !isValidPythonName(tagVal) {
+	if tagVal != "-" && !isValidPythonName(tagVal) {
 		return "", fmt.Errorf("gopy: invalid identifier for struct field tag: %s", tagVal)
 	}
 	return tagVal, nil
 }
 
+// extractJSONNameFieldTag returns the field name given by the json
+// struct tag, without any options such as omitempty.  If the tag is not
+// defined, is "-" or is not a valid python identifier, then the original
+// name is returned.
+func extractJSONNameFieldTag(gname, tag string) string {
+	if tag == "" {
+		return gname
+	}
+	tagVal := reflect.StructTag(tag).Get("json")
+	if idx := strings.Index(tagVal, ","); idx >= 0 {
+		tagVal = tagVal[:idx]
+	}
+	if tagVal == "-" || !isValidPythonName(tagVal) {
+		return gname
+	}
+	return tagVal
+}
+
 // isValidPythonName returns true if the string is a valid
 // python identifier name
 func isValidPythonName(name string) bool {
@@ -280,8 +387,78 @@ func isValidPythonName(name string) bool {
 var (
 	rxMatchFirstCap = regexp.MustCompile("([A-Z])([A-Z][a-z])")
 	rxMatchAllCap   = regexp.MustCompile("([a-z0-9])([A-Z])")
+	rxNonIdent      = regexp.MustCompile("[^A-Za-z0-9_]+")
 )
 
+// toIdent converts the provided string to a valid identifier, replacing
+// each run of other characters with an underscore, e.g., for the names
+// generated for unnamed func signature types.
+func toIdent(input string) string {
+	return strings.Trim(rxNonIdent.ReplaceAllString(input, "_"), "_")
+}
+
+// splitTopLevel splits s at the commas that are not within brackets,
+// trimming spaces and dropping empty elements, e.g., for the list of
+// instantiations "List[int], Map[string,int]".
+func splitTopLevel(s string) []string {
+	var res []string
+	depth, st := 0, 0
+	add := func(e string) {
+		if e = strings.TrimSpace(e); e != "" {
+			res = append(res, e)
+		}
+	}
+	for i, r := range s {
+		switch r {
+		case '[', '(', '{':
+			depth++
+		case ']', ')', '}':
+			depth--
+		case ',':
+			if depth == 0 {
+				add(s[st:i])
+				st = i + 1
+			}
+		}
+	}
+	add(s[st:])
+	return res
+}
+
+// pyRetval returns the pybindgen return value spec for the given C type,
+// where the ownership of a returned python object passes to the caller.
+func pyRetval(cpyname string) string {
+	if cpyname == "PyObject*" {
+		return fmt.Sprintf("retval('%s', caller_owns_return=True)", cpyname)
+	}
+	return fmt.Sprintf("retval('%s')", cpyname)
+}
+
+// pyZeroArg returns the python literal of the zero value of the given
+// pybindgen C type, e.g., to pass for an argument that is not used.
+func pyZeroArg(cpyname string) string {
+	switch cpyname {
+	case "char*":
+		return "''"
+	case "bool":
+		return "False"
+	case "float", "double":
+		return "0.0"
+	case "PyObject*":
+		return "None"
+	}
+	return "0"
+}
+
+// pyParam returns the pybindgen parameter spec for the given C type,
+// where the caller keeps the ownership of a python object argument.
+func pyParam(cpyname, name string) string {
+	if cpyname == "PyObject*" {
+		return fmt.Sprintf("param('%s', '%s', transfer_ownership=False)", cpyname, name)
+	}
+	return fmt.Sprintf("param('%s', '%s')", cpyname, name)
+}
+
 // toSnakeCase converts the provided string to snake_case.
 // Based on https://gist.github.com/stoewer/fbe273b711e6a06315d19552dd4d33e6
 func toSnakeCase(input string) string {
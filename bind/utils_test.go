@@ -6,6 +6,13 @@ package bind
 
 import (
 	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
 	"testing"
 )
 
@@ -95,3 +102,244 @@ func TestPythonConfig(t *testing.T) {
 		})
 	}
 }
+
+func TestCrossPythonConfig(t *testing.T) {
+	vm, err := exec.LookPath("python3")
+	if err != nil {
+		t.Skip("python3 not found")
+	}
+	goarch := "arm64"
+	if runtime.GOARCH == goarch {
+		goarch = "amd64"
+	}
+	machine := map[string]string{"arm64": "aarch64", "amd64": "x86_64"}[goarch]
+
+	cfg := &BindCfg{VM: vm, GOOS: "linux", GOARCH: goarch}
+	_, err = GetPythonConfigFor(cfg)
+	if err == nil || !strings.Contains(err.Error(), "needs the -python-prefix") {
+		t.Fatalf("cross-compilation without -python-prefix: got err=%v", err)
+	}
+
+	prefix := t.TempDir()
+	err = os.MkdirAll(filepath.Join(prefix, "lib", "python3.12"), 0755)
+	if err != nil {
+		t.Fatal(err)
+	}
+	multiarch := machine + "-linux-gnu"
+	data := fmt.Sprintf(`build_time_vars = {'prefix': '/usr',
+ 'VERSION': '3.12',
+ 'INCLUDEPY': '/usr/include/python3.12',
+ 'LIBDIR': '/usr/lib/%[1]s',
+ 'LIBRARY': 'libpython3.12.a',
+ 'SHLIBS': '-ldl',
+ 'SYSLIBS': '-lm',
+ 'EXT_SUFFIX': '.cpython-312-%[1]s.so',
+ 'MULTIARCH': '%[1]s',
+ 'Py_GIL_DISABLED': 0}
+`, multiarch)
+	err = os.WriteFile(filepath.Join(prefix, "lib", "python3.12", "_sysconfigdata__linux_"+multiarch+".py"), []byte(data), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("CC", "")
+	cfg.PythonPrefix = prefix
+	got, err := GetPythonConfigFor(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := PyConfig{
+		Version:   3,
+		Minor:     12,
+		CFlags:    "-I" + prefix + "/include/python3.12",
+		LdFlags:   "-L" + prefix + "/lib/" + multiarch + " -lpython3.12 -ldl -lm",
+		ExtSuffix: ".cpython-312-" + multiarch + ".so",
+		Platform:  "linux-" + machine,
+		MultiArch: multiarch,
+		CC:        multiarch + "-gcc",
+		LibDir:    prefix + "/lib/" + multiarch,
+		LibPy:     "python3.12",
+	}
+	if got != want {
+		t.Fatalf("error:\ngot= %#v\nwant=%#v\n", got, want)
+	}
+	env := CrossEnv(cfg, got)
+	wantEnv := []string{"GOOS=linux", "GOARCH=" + goarch, "CGO_ENABLED=1", "CC=" + multiarch + "-gcc"}
+	if !reflect.DeepEqual(env, wantEnv) {
+		t.Fatalf("error:\ngot= %q\nwant=%q\n", env, wantEnv)
+	}
+
+	cfg.GOOS = "plan9"
+	if _, err = GetPythonConfigFor(cfg); err == nil {
+		t.Fatalf("cross-compilation to plan9 should not be supported")
+	}
+}
+
+func TestUniversal2(t *testing.T) {
+	for _, tc := range []struct {
+		mode BuildMode
+		cfg  BindCfg
+		err  string
+	}{
+		{ModePkg, BindCfg{GOOS: "linux"}, ""},
+		{ModePkg, BindCfg{Universal2: true, GOOS: "darwin"}, ""},
+		{ModeExe, BindCfg{Universal2: true, GOOS: "darwin"}, "gopy: -universal2 does not support exe"},
+		{ModePkg, BindCfg{Universal2: true, GOOS: "linux"}, "gopy: -universal2 builds for macOS, not GOOS=linux"},
+		{ModePkg, BindCfg{Universal2: true, GOOS: "darwin", GOARCH: "arm64"}, "gopy: -universal2 builds for both arm64 and amd64, not -goarch=arm64"},
+	} {
+		err := checkUniversal2(tc.mode, &tc.cfg)
+		if (err == nil && tc.err != "") || (err != nil && err.Error() != tc.err) {
+			t.Errorf("checkUniversal2(%s, %+v): got err=%v, want %q", tc.mode, tc.cfg, err, tc.err)
+		}
+	}
+
+	cfg := &BindCfg{Universal2: true}
+	for _, tc := range []struct{ plat, want string }{
+		{"macosx-11.0-arm64", "macosx-11.0-universal2"},
+		{"macosx-10.9-universal2", "macosx-10.9-universal2"},
+		{"linux-x86_64", "linux-x86_64"},
+	} {
+		got := universal2Platform(cfg, PyConfig{Platform: tc.plat}).Platform
+		if got != tc.want {
+			t.Errorf("universal2Platform(%s): got %s, want %s", tc.plat, got, tc.want)
+		}
+	}
+
+	t.Setenv("CC", "")
+	env := Universal2Env("amd64")
+	want := []string{"GOOS=darwin", "GOARCH=amd64", "CGO_ENABLED=1", "CC=clang -arch x86_64"}
+	if !reflect.DeepEqual(env, want) {
+		t.Fatalf("error:\ngot= %q\nwant=%q\n", env, want)
+	}
+}
+
+func TestStatic(t *testing.T) {
+	if err := checkStatic(&BindCfg{Static: true, GOOS: "darwin"}); err == nil {
+		t.Fatalf("-static should not be supported on darwin")
+	}
+	if err := checkStatic(&BindCfg{Static: true, GOOS: "linux"}); err != nil {
+		t.Fatalf("-static should be supported on linux: %v", err)
+	}
+
+	libdir := t.TempDir()
+	pycfg := PyConfig{
+		LdFlags: "-L" + libdir + " -lpython3.11 -ldl  -lm",
+		LibDir:  libdir,
+		LibPy:   "python3.11",
+	}
+	cfg := &BindCfg{Static: true}
+	for _, tc := range []struct {
+		mode BuildMode
+		want string
+	}{
+		{ModePkg, "-L" + libdir + " -ldl -lm -static-libgcc"},
+		{ModeExe, "-L" + libdir + " -lpython3.11 -Wl,--export-dynamic -ldl -lm -static-libgcc"},
+	} {
+		got := StaticPythonConfig(tc.mode, cfg, pycfg).LdFlags
+		if got != tc.want {
+			t.Errorf("StaticPythonConfig(%s):\ngot= %q\nwant=%q", tc.mode, got, tc.want)
+		}
+	}
+
+	if err := os.WriteFile(filepath.Join(libdir, "libpython3.11.a"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	got := StaticPythonConfig(ModeExe, cfg, pycfg).LdFlags
+	want := "-L" + libdir + " -l:libpython3.11.a -Wl,--export-dynamic -ldl -lm -static-libgcc"
+	if got != want {
+		t.Errorf("StaticPythonConfig(exe):\ngot= %q\nwant=%q", got, want)
+	}
+
+	if got := StaticPythonConfig(ModePkg, &BindCfg{}, pycfg); got != pycfg {
+		t.Errorf("StaticPythonConfig without -static: got %+v, want %+v", got, pycfg)
+	}
+}
+
+func TestExtractJSONNameFieldTag(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		tag  string
+		want string
+	}{
+		{"UserID", ``, "UserID"},
+		{"UserID", `json:"user_id"`, "user_id"},
+		{"UserID", `json:"user_id,omitempty"`, "user_id"},
+		{"UserID", `json:",omitempty"`, "UserID"},
+		{"UserID", `json:"-"`, "UserID"},
+		{"UserID", `json:"user-id"`, "UserID"},
+		{"UserID", `xml:"user" json:"uid"`, "uid"},
+	} {
+		got := extractJSONNameFieldTag(tt.name, tt.tag)
+		if got != tt.want {
+			t.Errorf("extractJSONNameFieldTag(%s, %s): expected %s, actual %s", tt.name, tt.tag, tt.want, got)
+		}
+	}
+}
+
+func TestExtractPythonNameFieldTag(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		tag  string
+		want string
+		err  bool
+	}{
+		{"UserID", ``, "UserID", false},
+		{"UserID", `gopy:"uid"`, "uid", false},
+		{"UserID", `gopy:"x"`, "x", false},
+		{"UserID", `gopy:"-"`, "-", false},
+		{"UserID", `gopy:",json"`, "UserID", false},
+		{"UserID", `json:"uid"`, "UserID", false},
+		{"UserID", `gopy:"user-id"`, "", true},
+	} {
+		got, err := extractPythonNameFieldTag(tt.name, tt.tag)
+		if (err != nil) != tt.err {
+			t.Errorf("extractPythonNameFieldTag(%s, %s): unexpected error: %v", tt.name, tt.tag, err)
+		}
+		if got != tt.want {
+			t.Errorf("extractPythonNameFieldTag(%s, %s): expected %s, actual %s", tt.name, tt.tag, tt.want, got)
+		}
+	}
+}
+
+func TestSplitTopLevel(t *testing.T) {
+	for _, tt := range []struct {
+		in   string
+		want []string
+	}{
+		{"", nil},
+		{"List[int]", []string{"List[int]"}},
+		{"List[int],List[string]", []string{"List[int]", "List[string]"}},
+		{" Map[string, int] , Pair[K[int],map[string]int],", []string{"Map[string, int]", "Pair[K[int],map[string]int]"}},
+	} {
+		got := splitTopLevel(tt.in)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("splitTopLevel(%q): expected %q, actual %q", tt.in, tt.want, got)
+		}
+	}
+}
+
+func TestParseInitEnv(t *testing.T) {
+	for _, tt := range []struct {
+		in   string
+		want [][2]string
+		err  bool
+	}{
+		{"", nil, false},
+		{"GOPY_INIT=init; GOGC=50;", [][2]string{{"GOPY_INIT", "init"}, {"GOGC", "50"}}, false},
+		{"GODEBUG=madvdontneed=1", [][2]string{{"GODEBUG", "madvdontneed=1"}}, false},
+		{"GOPY_INIT", nil, true},
+		{"=init", nil, true},
+		{"GOGC=lots", nil, true},
+		{"GOMEMLIMIT=1GB", nil, true},
+		{"GOMAXPROCS=0", nil, true},
+	} {
+		got, err := parseInitEnv(tt.in)
+		if (err != nil) != tt.err {
+			t.Errorf("parseInitEnv(%q): expected error %v, actual %v", tt.in, tt.err, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseInitEnv(%q): expected %q, actual %q", tt.in, tt.want, got)
+		}
+	}
+}
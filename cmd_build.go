@@ -44,6 +44,34 @@ ex:
 	cmd.Flag.Bool("symbols", true, "include symbols in output")
 	cmd.Flag.Bool("no-warn", false, "suppress warning messages, which may be expected")
 	cmd.Flag.Bool("no-make", false, "do not generate a Makefile, e.g., when called from Makefile")
+	cmd.Flag.String("restrict-to-interface", "", "comma-separated list of Type=Interface pairs: only the methods of Interface are exposed for Type, whose exported fields, and their conversions (asdict, to_json, ...), are still exposed")
+	cmd.Flag.String("async", "", "regexp of function / method names to wrap as asyncio coroutines, e.g., '.*Stream$|.*Watch$'")
+	cmd.Flag.String("hold-gil", "", "regexp of function / method names whose Go calls hold the python GIL, which is released during the other calls, e.g., '^Get.*'")
+	cmd.Flag.Bool("dev", false, "dev mode: the generated python can reload a rebuilt library with go.reload(), instead of restarting python")
+	cmd.Flag.Bool("metrics", false, "instrument the wrappers with call counts and latencies, available with go.metrics()")
+	cmd.Flag.String("init-env", "", "semicolon-separated list of KEY=value environment variables set on the Go side on import, as go.setenv does: GOGC, GOMEMLIMIT, GOMAXPROCS and most of GODEBUG take effect")
+	cmd.Flag.Bool("json-names", false, "use json struct tag names for python struct fields")
+	cmd.Flag.String("instantiate", "", "comma-separated list of instantiations of generic types and funcs to bind, e.g., 'List[int],Map[string,int]'")
+	cmd.Flag.Bool("bytes", false, "convert []byte to and from python bytes, instead of wrapping it as a go.Slice_byte object")
+	cmd.Flag.Bool("numpy", false, "generate to_numpy and from_numpy methods for numeric slices, copying in bulk")
+	cmd.Flag.Bool("datetime", false, "convert time.Time and time.Duration to and from python datetime and timedelta, instead of wrapping them as a handle and an int of nanoseconds")
+	cmd.Flag.Bool("bignum", false, "convert *big.Int and *big.Float to and from python int and float, instead of wrapping them as handles")
+	cmd.Flag.Bool("json", false, "convert json.RawMessage, and []byte struct fields tagged gopy:\",json\", to and from the python values they encode")
+	cmd.Flag.Bool("text", false, "convert values of otherwise unsupported types to python str, through their encoding.TextMarshaler or fmt.Stringer implementation")
+	cmd.Flag.String("operators", "", "comma-separated list of Method=__op__ pairs, adding to or overriding the default mapping of Go method names (Add, Sub, Mul, Div, Mod, Neg, Less, Cmp) to python operator methods, e.g., 'Scale=__mul__,Less='")
+	cmd.Flag.Bool("annotate", false, "emit PEP 484 type annotations in the python wrapper functions and properties (python 3.7+)")
+	cmd.Flag.Bool("finalize", false, "close the io.Closer values when the last python wrapper of their handle is finalized, and add go.collect() to release the handles of unreachable wrappers")
+	cmd.Flag.Bool("free-threaded", false, "target the free-threaded python build (e.g., -vm=python3.13t), which runs without the GIL: Go values are locked by their accessors, and gopy:notthreadsafe functions are serialized")
+	cmd.Flag.Bool("gen-stress-tests", false, "generate a test_<pkg>_stress.py pytest file for each package, which hammers its bindings from many python threads at once: handle churn, callbacks and channel ops")
+	cmd.Flag.String("backend", "pybindgen", "backend generating the C code of the python extension module: pybindgen, cffi (pip install cffi), which does not depend on pybindgen, and also supports PyPy for the functions with C args and results, cython (pip install cython), which copies the numeric slices in bulk with typed memoryviews, capi, which generates it directly with the CPython C-API, without a python build dependency, or ctypes, which only builds the Go library, called with ctypes by a generated python module")
+	cmd.Flag.Bool("limited-api", false, "restrict the generated C code to the limited API of python 3.11 (Py_LIMITED_API), so that the extension module is built once for the stable ABI (abi3) of python 3.11 and later")
+	cmd.Flag.String("goos", "", "target GOOS of a cross-compilation, e.g., linux (default: the host one), which needs -python-prefix")
+	cmd.Flag.String("goarch", "", "target GOARCH of a cross-compilation, e.g., arm64 (default: the host one), which needs -python-prefix")
+	cmd.Flag.String("python-prefix", "", "prefix of the target python installation of a cross-compilation, e.g., /path/to/sysroot/usr, whose sysconfig data (lib/python3.X/_sysconfigdata_*.py) is read instead of running -vm, which still runs build.py: the go builds then use the cross C compiler of the target, e.g., aarch64-linux-gnu-gcc, unless CC is set")
+	cmd.Flag.Bool("universal2", false, "on macOS, build the extension module for both arm64 and x86_64, and merge them with lipo into a universal2 one, which needs a universal2 python, e.g., from python.org")
+	cmd.Flag.Bool("static", false, "on linux, e.g., Alpine with musl, link the Go runtime and libgcc statically, with the netgo and osusergo build tags, and do not link the extension module against libpython (exe links the static libpython, if installed)")
+	cmd.Flag.Bool("incremental", false, "only regenerate the bindings if the flags or the API of the packages changed since the previous generation, whose state is kept in the output directory, only rewrite the changed files, and only rebuild what depends on them")
+	cmd.Flag.String("tags", "", "comma-separated list of build tags, as for go build -tags, with which the packages are loaded and the library is built, e.g., enterprise")
 	return cmd
 }
 
@@ -64,16 +92,41 @@ func gopyRunCmdBuild(cmdr *commander.Command, args []string) error {
 	cfg.Symbols = cmdr.Flag.Lookup("symbols").Value.Get().(bool)
 	cfg.NoWarn = cmdr.Flag.Lookup("no-warn").Value.Get().(bool)
 	cfg.NoMake = cmdr.Flag.Lookup("no-make").Value.Get().(bool)
-
-	bind.NoWarn = cfg.NoWarn
-	bind.NoMake = cfg.NoMake
+	cfg.RestrictTo = cmdr.Flag.Lookup("restrict-to-interface").Value.Get().(string)
+	cfg.AsyncNames = cmdr.Flag.Lookup("async").Value.Get().(string)
+	cfg.HoldGIL = cmdr.Flag.Lookup("hold-gil").Value.Get().(string)
+	cfg.DevMode = cmdr.Flag.Lookup("dev").Value.Get().(bool)
+	cfg.Metrics = cmdr.Flag.Lookup("metrics").Value.Get().(bool)
+	cfg.InitEnv = cmdr.Flag.Lookup("init-env").Value.Get().(string)
+	cfg.JSONNames = cmdr.Flag.Lookup("json-names").Value.Get().(bool)
+	cfg.Instantiate = cmdr.Flag.Lookup("instantiate").Value.Get().(string)
+	cfg.Bytes = cmdr.Flag.Lookup("bytes").Value.Get().(bool)
+	cfg.Numpy = cmdr.Flag.Lookup("numpy").Value.Get().(bool)
+	cfg.DateTime = cmdr.Flag.Lookup("datetime").Value.Get().(bool)
+	cfg.BigNum = cmdr.Flag.Lookup("bignum").Value.Get().(bool)
+	cfg.JSON = cmdr.Flag.Lookup("json").Value.Get().(bool)
+	cfg.Text = cmdr.Flag.Lookup("text").Value.Get().(bool)
+	cfg.Operators = cmdr.Flag.Lookup("operators").Value.Get().(string)
+	cfg.Annotate = cmdr.Flag.Lookup("annotate").Value.Get().(bool)
+	cfg.Finalize = cmdr.Flag.Lookup("finalize").Value.Get().(bool)
+	cfg.FreeThreaded = cmdr.Flag.Lookup("free-threaded").Value.Get().(bool)
+	cfg.GenStressTests = cmdr.Flag.Lookup("gen-stress-tests").Value.Get().(bool)
+	cfg.Backend = cmdr.Flag.Lookup("backend").Value.Get().(string)
+	cfg.LimitedAPI = cmdr.Flag.Lookup("limited-api").Value.Get().(bool)
+	cfg.GOOS = cmdr.Flag.Lookup("goos").Value.Get().(string)
+	cfg.GOARCH = cmdr.Flag.Lookup("goarch").Value.Get().(string)
+	cfg.PythonPrefix = cmdr.Flag.Lookup("python-prefix").Value.Get().(string)
+	cfg.Universal2 = cmdr.Flag.Lookup("universal2").Value.Get().(bool)
+	cfg.Static = cmdr.Flag.Lookup("static").Value.Get().(bool)
+	cfg.Incremental = cmdr.Flag.Lookup("incremental").Value.Get().(bool)
+	cfg.Tags = cmdr.Flag.Lookup("tags").Value.Get().(string)
 
 	for _, path := range args {
-		bpkg, err := loadPackage(path, true) // build first
+		bpkg, err := loadPackage(path, true, cfg) // build first
 		if err != nil {
 			return fmt.Errorf("gopy-gen: go build / load of package failed with path=%q: %v", path, err)
 		}
-		pkg, err := parsePackage(bpkg)
+		pkg, err := parsePackage(cfg, bpkg)
 		if err != nil {
 			return err
 		}
@@ -101,53 +154,76 @@ func runBuild(mode bind.BuildMode, cfg *BuildCfg) error {
 	fmt.Printf("\n--- building package ---\n%s\n", cfg.Cmd)
 
 	buildname := cfg.Name + "_go"
-	var cmdout []byte
+	var (
+		cmd    *exec.Cmd
+		cmdout []byte
+	)
 	cwd, err := os.Getwd()
 	os.Chdir(cfg.OutputDir)
 	defer os.Chdir(cwd)
 
-	os.Remove(cfg.Name + ".c") // may fail, we don't care
+	// with -incremental, the header generated by the first go build, and
+	// the C source generated by build.py, are kept if they are newer than
+	// the files they are generated from, which were not rewritten
+	hdrOK := cfg.Incremental && mode != bind.ModeExe && newerThan(buildname+".h", cfg.Name+".go")
+	srcOK := cfg.Incremental && mode != bind.ModeExe && newerThan(cfg.Name+".c", "build.py")
+
+	// the C source generated by the capi backend is only compiled with the
+	// header generated by the first go build, see bind.CapiPreamble
+	capi := cfg.Backend == bind.BackendCapi
+	if capi && !hdrOK {
+		os.Remove(buildname + ".h")
+	} else if !capi && !srcOK {
+		os.Remove(cfg.Name + ".c") // may fail, we don't care
+	}
 
-	fmt.Printf("goimports -w %v\n", cfg.Name+".go")
-	cmd := exec.Command("goimports", "-w", cfg.Name+".go")
-	cmdout, err = cmd.CombinedOutput()
+	pycfg, err := bind.GetPythonConfigFor(&cfg.BindCfg)
 	if err != nil {
-		fmt.Printf("cmd had error: %v  output:\no%v\n", err, string(cmdout))
 		return err
 	}
-
-	pycfg, err := bind.GetPythonConfig(cfg.VM)
+	pycfg = bind.StaticPythonConfig(mode, &cfg.BindCfg, pycfg)
+	// the go builds of a cross-compilation target the GOOS and GOARCH of cfg
+	goenv := append(os.Environ(), bind.CrossEnv(&cfg.BindCfg, pycfg)...)
+	tags := bind.BuildFlags(&cfg.BindCfg)
 
 	if mode == bind.ModeExe {
-		of, err := os.Create(buildname + ".h") // overwrite existing
-		fmt.Fprintf(of, "typedef uint8_t bool;\n")
-		of.Close()
-
-		fmt.Printf("%v build.py   # will fail, but needed to generate .c file\n", cfg.VM)
-		cmd = exec.Command(cfg.VM, "build.py")
-		cmd.Run() // will fail, we don't care about errors
+		if !capi {
+			of, _ := os.Create(buildname + ".h") // overwrite existing
+			fmt.Fprintf(of, "typedef uint8_t bool;\n")
+			of.Close()
+
+			fmt.Printf("%v build.py   # will fail, but needed to generate .c file\n", cfg.VM)
+			cmd = exec.Command(cfg.VM, "build.py")
+			cmd.Run() // will fail, we don't care about errors
+		}
 
-		args := []string{"build", "-mod=mod", "-buildmode=c-shared", "-o", buildname + libExt, "."}
+		args := append([]string{"build", "-mod=mod", "-buildmode=c-shared"}, tags...)
+		args = append(args, "-o", buildname+libExt, ".")
 		fmt.Printf("go %v\n", strings.Join(args, " "))
 		cmd = exec.Command("go", args...)
+		cmd.Env = goenv
 		cmdout, err = cmd.CombinedOutput()
 		if err != nil {
 			fmt.Printf("cmd had error: %v  output:\n%v\n", err, string(cmdout))
 			return err
 		}
 
-		fmt.Printf("%v build.py   # should work this time\n", cfg.VM)
-		cmd = exec.Command(cfg.VM, "build.py")
-		cmdout, err = cmd.CombinedOutput()
-		if err != nil {
-			fmt.Printf("cmd had error: %v  output:\n%v\n", err, string(cmdout))
-			return err
+		if !capi {
+			fmt.Printf("%v build.py   # should work this time\n", cfg.VM)
+			cmd = exec.Command(cfg.VM, "build.py")
+			cmdout, err = cmd.CombinedOutput()
+			if err != nil {
+				fmt.Printf("cmd had error: %v  output:\n%v\n", err, string(cmdout))
+				return err
+			}
 		}
 
 		err = os.Remove(cfg.Name + "_go" + libExt)
 
-		fmt.Printf("go build -o py%s\n", cfg.Name)
-		cmd = exec.Command("go", "build", "-mod=mod", "-o", "py"+cfg.Name)
+		args = append(append([]string{"build", "-mod=mod"}, tags...), "-o", "py"+cfg.Name)
+		fmt.Printf("go %v\n", strings.Join(args, " "))
+		cmd = exec.Command("go", args...)
+		cmd.Env = goenv
 		cmdout, err = cmd.CombinedOutput()
 		if err != nil {
 			fmt.Printf("cmd had error: %v  output:\n%v\n", err, string(cmdout))
@@ -156,18 +232,36 @@ func runBuild(mode bind.BuildMode, cfg *BuildCfg) error {
 
 	} else {
 		buildLib := buildname + libExt
+		goos := cfg.GOOS
+		if goos == "" {
+			goos = runtime.GOOS
+		}
 		extext := libExt
-		if runtime.GOOS == "windows" {
+		if goos == "windows" {
 			extext = ".pyd"
 		}
 		if pycfg.ExtSuffix != "" {
 			extext = pycfg.ExtSuffix
 		}
+		if cfg.LimitedAPI && goos != "windows" {
+			// the extension of the stable ABI is loaded by all the later versions
+			extext = ".abi3.so"
+		}
 		modlib := "_" + cfg.Name + extext
+		if cfg.Backend == bind.BackendCffi {
+			// the _ python module of the cffi backend wraps the _<name>_cffi one
+			modlib = "_" + cfg.Name + "_cffi" + extext
+		}
+		// the _ python module of the ctypes backend loads the Go library
+		// itself, which is the only one to build, see bind.CtypesPreamble
+		ctypes := cfg.Backend == bind.BackendCtypes
+		if ctypes {
+			modlib = "_" + buildname + libExt
+		}
 
 		// build the go shared library upfront to generate the header
 		// needed by our generated cpython code
-		args := []string{"build", "-mod=mod", "-buildmode=c-shared"}
+		args := append([]string{"build", "-mod=mod", "-buildmode=c-shared"}, tags...)
 		if !cfg.Symbols {
 			// These flags will omit the various symbol tables, thereby
 			// reducing the final size of the binary. From https://golang.org/cmd/link/
@@ -176,30 +270,25 @@ func runBuild(mode bind.BuildMode, cfg *BuildCfg) error {
 			args = append(args, "-ldflags=-s -w")
 		}
 		args = append(args, "-o", buildLib, ".")
-		fmt.Printf("go %v\n", strings.Join(args, " "))
-		cmd = exec.Command("go", args...)
-		cmdout, err = cmd.CombinedOutput()
-		if err != nil {
-			fmt.Printf("cmd had error: %v  output:\n%v\n", err, string(cmdout))
-			return err
+		if !ctypes && !hdrOK {
+			fmt.Printf("go %v\n", strings.Join(args, " "))
+			cmd = exec.Command("go", args...)
+			cmd.Env = goenv
+			cmdout, err = cmd.CombinedOutput()
+			if err != nil {
+				fmt.Printf("cmd had error: %v  output:\n%v\n", err, string(cmdout))
+				return err
+			}
+			// we don't need this initial lib because we are going to relink
+			os.Remove(buildLib)
 		}
 		// update the output name to the one with the ABI extension
 		args[len(args)-2] = modlib
-		// we don't need this initial lib because we are going to relink
-		os.Remove(buildLib)
-
-		// generate c code
-		fmt.Printf("%v build.py\n", cfg.VM)
-		cmd = exec.Command(cfg.VM, "build.py")
-		cmdout, err = cmd.CombinedOutput()
-		if err != nil {
-			fmt.Printf("cmd had error: %v  output:\no%v\n", err, string(cmdout))
-			return err
-		}
 
-		if bind.WindowsOS {
-			fmt.Printf("Doing windows sed hack to fix declspec for PyInit\n")
-			cmd = exec.Command("sed", "-i", "s/ PyInit_/ __declspec(dllexport) PyInit_/g", cfg.Name+".c")
+		// generate c code, unless generated by gopy
+		if !capi && !ctypes && !srcOK {
+			fmt.Printf("%v build.py\n", cfg.VM)
+			cmd = exec.Command(cfg.VM, "build.py")
 			cmdout, err = cmd.CombinedOutput()
 			if err != nil {
 				fmt.Printf("cmd had error: %v  output:\no%v\n", err, string(cmdout))
@@ -241,13 +330,17 @@ func runBuild(mode bind.BuildMode, cfg *BuildCfg) error {
 		cflagsEnv := fmt.Sprintf("CGO_CFLAGS=%s", strings.Join(cflags, " "))
 		ldflagsEnv := fmt.Sprintf("CGO_LDFLAGS=%s", strings.Join(ldflags, " "))
 
-		env := os.Environ()
+		env := goenv
 		env = append(env, cflagsEnv)
 		env = append(env, ldflagsEnv)
 
 		fmt.Println(cflagsEnv)
 		fmt.Println(ldflagsEnv)
 
+		if cfg.Universal2 {
+			return buildUniversal2(args, env, modlib)
+		}
+
 		// build extension with go + c
 		fmt.Printf("go %v\n", strings.Join(args, " "))
 		cmd = exec.Command("go", args...)
@@ -261,3 +354,52 @@ func runBuild(mode bind.BuildMode, cfg *BuildCfg) error {
 
 	return err
 }
+
+// newerThan returns whether the file out exists, and is not older than the
+// file in, which it is generated from.
+func newerThan(out, in string) bool {
+	o, err := os.Stat(out)
+	if err != nil {
+		return false
+	}
+	i, err := os.Stat(in)
+	if err != nil {
+		return false
+	}
+	return !o.ModTime().Before(i.ModTime())
+}
+
+// buildUniversal2 builds the universal2 modlib extension module of
+// -universal2: the go build args build its slice for each of
+// bind.Universal2Archs, which are merged with lipo.
+func buildUniversal2(args, env []string, modlib string) error {
+	var slices []string
+	defer func() {
+		for _, slice := range slices {
+			os.Remove(slice)
+			os.Remove(strings.TrimSuffix(slice, filepath.Ext(slice)) + ".h")
+		}
+	}()
+	for _, goarch := range bind.Universal2Archs {
+		slice := goarch + "_" + modlib
+		args[len(args)-2] = slice
+		fmt.Printf("GOARCH=%s go %v\n", goarch, strings.Join(args, " "))
+		cmd := exec.Command("go", args...)
+		cmd.Env = append(append([]string{}, env...), bind.Universal2Env(goarch)...)
+		cmdout, err := cmd.CombinedOutput()
+		if err != nil {
+			fmt.Printf("cmd had error: %v  output:\n%v\n", err, string(cmdout))
+			return err
+		}
+		slices = append(slices, slice)
+	}
+
+	lipo := append([]string{"-create", "-output", modlib}, slices...)
+	fmt.Printf("lipo %v\n", strings.Join(lipo, " "))
+	cmdout, err := exec.Command("lipo", lipo...).CombinedOutput()
+	if err != nil {
+		fmt.Printf("cmd had error: %v  output:\n%v\n", err, string(cmdout))
+		return err
+	}
+	return nil
+}
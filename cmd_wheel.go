@@ -0,0 +1,396 @@
+// Copyright 2019 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rudderlabs/gopy/bind"
+	"github.com/gonuts/commander"
+	"github.com/gonuts/flag"
+)
+
+// wheel format links:
+// https://packaging.python.org/en/latest/specifications/binary-distribution-format/
+// https://packaging.python.org/en/latest/specifications/core-metadata/
+// https://packaging.python.org/en/latest/specifications/platform-compatibility-tags/
+
+func gopyMakeCmdWheel() *commander.Command {
+	cmd := gopyMakeCmdPkg()
+	cmd.Run = gopyRunCmdWheel
+	cmd.UsageLine = "wheel <go-package-name> [other-go-package...]"
+	cmd.Short = "generate and compile (C)Python language bindings for Go, and make a python wheel of the package"
+	cmd.Long = `
+wheel does everything that pkg does, and then packs the python package into a wheel, with the platform and ABI tags of the python interpreter given by -vm (or the abi3 one with -limited-api), suitable for upload to https://pypi.org/, e.g., with twine.  The wheel is written to the -wheel-dir directory.  With -editable, the wheel only installs a .pth file adding the -output directory to the python path (PEP 660), so that the package built in place is imported: this is what the gopy_build.py backend generated by pkg uses for pip install -e.
+
+With -repair, the wheel is then repaired with auditwheel (pip install auditwheel) on linux, which copies the external shared libraries into it and retags it as manylinux (-plat selects the policy), or with delocate (pip install delocate) on macOS.
+
+ex:
+ $ gopy wheel [options] <go-package-name> [other-go-package...]
+ $ gopy wheel -vm=python3 -version=1.2.0 github.com/rudderlabs/gopy/_examples/hi
+`
+	cmd.Flag.Init("gopy-wheel", flag.ExitOnError)
+
+	cmd.Flag.String("wheel-dir", "", "output directory for the wheel (default: dist in the -output directory)")
+	cmd.Flag.String("dist-name", "", "distribution name of the wheel (default: name of the package, with the -user suffix)")
+	cmd.Flag.Bool("editable", false, "make an editable wheel (PEP 660), which installs a .pth file adding the -output directory to the python path, instead of the files of the package")
+	cmd.Flag.Bool("repair", false, "repair the wheel with auditwheel on linux, or delocate on macOS, bundling the external shared libraries")
+	cmd.Flag.String("plat", "", "platform tag of the repaired wheel on linux, passed to auditwheel repair --plat, e.g., manylinux_2_28_x86_64")
+
+	return cmd
+}
+
+func gopyRunCmdWheel(cmdr *commander.Command, args []string) error {
+	if len(args) == 0 {
+		err := fmt.Errorf("gopy: expect a fully qualified go package name as argument")
+		log.Println(err)
+		return err
+	}
+
+	var (
+		odir     = cmdr.Flag.Lookup("output").Value.Get().(string)
+		name     = cmdr.Flag.Lookup("name").Value.Get().(string)
+		wheelDir = cmdr.Flag.Lookup("wheel-dir").Value.Get().(string)
+		repair   = cmdr.Flag.Lookup("repair").Value.Get().(bool)
+		plat     = cmdr.Flag.Lookup("plat").Value.Get().(string)
+	)
+
+	// the python of the wheel tags, which is the target one of a cross-compilation
+	target := &bind.BindCfg{
+		VM:           cmdr.Flag.Lookup("vm").Value.Get().(string),
+		LimitedAPI:   cmdr.Flag.Lookup("limited-api").Value.Get().(bool),
+		GOOS:         cmdr.Flag.Lookup("goos").Value.Get().(string),
+		GOARCH:       cmdr.Flag.Lookup("goarch").Value.Get().(string),
+		PythonPrefix: cmdr.Flag.Lookup("python-prefix").Value.Get().(string),
+		Universal2:   cmdr.Flag.Lookup("universal2").Value.Get().(bool),
+	}
+
+	meta := wheelMeta{
+		Version: cmdr.Flag.Lookup("version").Value.Get().(string),
+		Author:  cmdr.Flag.Lookup("author").Value.Get().(string),
+		Email:   cmdr.Flag.Lookup("email").Value.Get().(string),
+		Desc:    cmdr.Flag.Lookup("desc").Value.Get().(string),
+		URL:     cmdr.Flag.Lookup("url").Value.Get().(string),
+	}
+
+	// resolve the directories upfront, as pkg changes the current directory
+	var err error
+	odir, err = genOutDir(odir)
+	if err != nil {
+		return err
+	}
+	if wheelDir == "" {
+		wheelDir = filepath.Join(odir, "dist")
+	}
+	wheelDir, err = filepath.Abs(wheelDir)
+	if err != nil {
+		return err
+	}
+	if name == "" {
+		_, name = filepath.Split(args[0])
+	}
+
+	err = gopyRunCmdPkg(cmdr, args)
+	if err != nil {
+		return err
+	}
+
+	meta.Name = cmdr.Flag.Lookup("dist-name").Value.Get().(string)
+	if meta.Name == "" {
+		meta.Name = name
+		if user := cmdr.Flag.Lookup("user").Value.Get().(string); user != "" {
+			meta.Name += "-" + user
+		}
+	}
+	meta.Editable = cmdr.Flag.Lookup("editable").Value.Get().(bool)
+	meta.Tag, meta.RequiresPython, err = wheelTag(target)
+	if err != nil {
+		return err
+	}
+	if readme, err := ioutil.ReadFile(filepath.Join(odir, "README.md")); err == nil {
+		meta.Readme = string(readme)
+	}
+
+	if !repair {
+		_, err = writeWheel(wheelDir, odir, name, meta)
+		return err
+	}
+
+	tmpdir, err := ioutil.TempDir("", "gopy-wheel-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmpdir)
+	whl, err := writeWheel(tmpdir, odir, name, meta)
+	if err != nil {
+		return err
+	}
+	return repairWheel(whl, wheelDir, plat)
+}
+
+// wheelMeta is the metadata of the wheel of a package.
+type wheelMeta struct {
+	Name    string // distribution name, with the -user suffix
+	Version string
+	Author  string
+	Email   string
+	Desc    string
+	URL     string
+	Readme  string // long description, from README.md
+
+	Editable bool // only install a .pth file pointing to the package
+
+	Tag            string // compatibility tag, e.g., cp311-cp311-linux_x86_64
+	RequiresPython string
+}
+
+// wheelTag returns the compatibility tag of the wheel of the extension
+// module built for the python of cfg, which is the target one of a
+// cross-compilation, and the Requires-Python of its metadata.  With
+// -limited-api, the module is built for the stable ABI of
+// bind.LimitedAPIVersion, and later versions.
+func wheelTag(cfg *bind.BindCfg) (tag, requires string, err error) {
+	pycfg, err := bind.GetPythonConfigFor(cfg)
+	if err != nil {
+		return "", "", fmt.Errorf("gopy: could not get the wheel tags of %s: %v", cfg.VM, err)
+	}
+	plat := strings.NewReplacer("-", "_", ".", "_").Replace(pycfg.Platform)
+	if !cfg.LimitedAPI {
+		py := fmt.Sprintf("cp%d%d", pycfg.Version, pycfg.Minor)
+		abi := py
+		if pycfg.GILDisabled {
+			abi += "t"
+		}
+		return py + "-" + abi + "-" + plat, fmt.Sprintf("==%d.%d.*", pycfg.Version, pycfg.Minor), nil
+	}
+	hex, err := strconv.ParseUint(strings.TrimPrefix(bind.LimitedAPIVersion, "0x"), 16, 32)
+	if err != nil {
+		return "", "", err
+	}
+	major, minor := hex>>24, (hex>>16)&0xff
+	return fmt.Sprintf("cp%d%d-abi3-%s", major, minor, plat), fmt.Sprintf(">=%d.%d", major, minor), nil
+}
+
+var wheelNameRe = regexp.MustCompile(`[-_.]+`)
+
+// wheelName returns the normalized distribution name of the file name of a
+// wheel.
+func wheelName(s string) string {
+	return strings.ToLower(wheelNameRe.ReplaceAllString(s, "_"))
+}
+
+// isWheelFile returns true if the generated file fname of the package is
+// installed by its wheel: the python modules and the extension modules and
+// libraries, but not the Go and C sources, nor build.py.
+func isWheelFile(fname string) bool {
+	switch fname {
+	case "build.py":
+		return false
+	case "py.typed":
+		return true
+	}
+	switch filepath.Ext(fname) {
+	case ".py", ".pyi", ".so", ".pyd", ".dylib", ".dll":
+		return true
+	}
+	return false
+}
+
+// writeWheel writes the wheel of the python package name in the directory
+// odir, with the metadata meta, to the directory wheelDir, and returns its
+// path.
+func writeWheel(wheelDir, odir, name string, meta wheelMeta) (string, error) {
+	var files []string
+	if !meta.Editable {
+		err := filepath.Walk(filepath.Join(odir, name), func(path string, fi os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if fi.IsDir() {
+				if fi.Name() == "__pycache__" {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if isWheelFile(fi.Name()) {
+				files = append(files, path)
+			}
+			return nil
+		})
+		if err != nil {
+			return "", err
+		}
+	}
+	sort.Strings(files)
+
+	err := os.MkdirAll(wheelDir, 0755)
+	if err != nil {
+		return "", fmt.Errorf("gopy-wheel: could not create wheel directory: %v", err)
+	}
+	dist := wheelName(meta.Name) + "-" + strings.Replace(meta.Version, "-", "_", -1)
+	whl := filepath.Join(wheelDir, dist+"-"+meta.Tag+".whl")
+	f, err := os.Create(whl)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	zw := zip.NewWriter(f)
+	record := new(bytes.Buffer)
+	add := func(arc string, mode os.FileMode, data []byte) error {
+		hdr := wheelHeader(arc, mode)
+		w, err := zw.CreateHeader(hdr)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(data)
+		if err != nil {
+			return err
+		}
+		sum := sha256.Sum256(data)
+		fmt.Fprintf(record, "%s,sha256=%s,%d\n", arc, base64.RawURLEncoding.EncodeToString(sum[:]), len(data))
+		return nil
+	}
+
+	for _, fn := range files {
+		fi, err := os.Stat(fn)
+		if err != nil {
+			return "", err
+		}
+		data, err := ioutil.ReadFile(fn)
+		if err != nil {
+			return "", err
+		}
+		arc, err := filepath.Rel(odir, fn)
+		if err != nil {
+			return "", err
+		}
+		err = add(filepath.ToSlash(arc), fi.Mode(), data)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	if meta.Editable {
+		err = add("__editable__."+dist+".pth", 0644, []byte(odir+"\n"))
+		if err != nil {
+			return "", err
+		}
+	}
+
+	info := dist + ".dist-info/"
+	err = add(info+"METADATA", 0644, []byte(wheelMetadata(meta)))
+	if err != nil {
+		return "", err
+	}
+	err = add(info+"WHEEL", 0644, []byte(fmt.Sprintf(wheelTempl, Version, meta.Tag)))
+	if err != nil {
+		return "", err
+	}
+	if license, err := ioutil.ReadFile(filepath.Join(odir, "LICENSE")); err == nil {
+		err = add(info+"LICENSE", 0644, license)
+		if err != nil {
+			return "", err
+		}
+	}
+	fmt.Fprintf(record, "%sRECORD,,\n", info)
+	w, err := zw.CreateHeader(wheelHeader(info+"RECORD", 0644))
+	if err != nil {
+		return "", err
+	}
+	_, err = io.Copy(w, record)
+	if err != nil {
+		return "", err
+	}
+	err = zw.Close()
+	if err != nil {
+		return "", err
+	}
+	fmt.Printf("wrote wheel: %s\n", whl)
+	return whl, f.Close()
+}
+
+// wheelHeader returns the zip header of the file arc of a wheel, with a
+// fixed time, so that the wheel of the same files is the same.
+func wheelHeader(arc string, mode os.FileMode) *zip.FileHeader {
+	hdr := &zip.FileHeader{
+		Name:     arc,
+		Method:   zip.Deflate,
+		Modified: time.Date(1980, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+	hdr.SetMode(mode)
+	return hdr
+}
+
+// 1 = gopy version, 2 = tag
+const wheelTempl = `Wheel-Version: 1.0
+Generator: gopy (%[1]s)
+Root-Is-Purelib: false
+Tag: %[2]s
+`
+
+// wheelMetadata returns the METADATA file of the wheel.
+func wheelMetadata(meta wheelMeta) string {
+	buf := new(bytes.Buffer)
+	fmt.Fprintf(buf, "Metadata-Version: 2.1\n")
+	fmt.Fprintf(buf, "Name: %s\n", meta.Name)
+	fmt.Fprintf(buf, "Version: %s\n", meta.Version)
+	fmt.Fprintf(buf, "Summary: %s\n", meta.Desc)
+	fmt.Fprintf(buf, "Home-page: %s\n", meta.URL)
+	fmt.Fprintf(buf, "Author: %s\n", meta.Author)
+	fmt.Fprintf(buf, "Author-email: %s\n", meta.Email)
+	fmt.Fprintf(buf, "License: BSD\n")
+	fmt.Fprintf(buf, "Requires-Python: %s\n", meta.RequiresPython)
+	if meta.Readme != "" {
+		fmt.Fprintf(buf, "Description-Content-Type: text/markdown\n\n%s", meta.Readme)
+	}
+	return buf.String()
+}
+
+// repairWheel repairs the wheel whl into the directory wheelDir, with
+// auditwheel on linux, or delocate on macOS.  libpython is not bundled, as
+// the extension module is loaded by the interpreter, which provides it.
+func repairWheel(whl, wheelDir, plat string) error {
+	var args []string
+	switch runtime.GOOS {
+	case "linux":
+		args = []string{"auditwheel", "repair", "--wheel-dir", wheelDir, "--exclude", "libpython*"}
+		if plat != "" {
+			args = append(args, "--plat", plat)
+		}
+	case "darwin":
+		args = []string{"delocate-wheel", "-w", wheelDir, "-e", "libpython", "-e", "Python.framework"}
+	default:
+		return fmt.Errorf("gopy: -repair is not supported on %s", runtime.GOOS)
+	}
+	args = append(args, whl)
+	if _, err := exec.LookPath(args[0]); err != nil {
+		return fmt.Errorf("gopy: -repair needs %s (pip install %s): %v", args[0], strings.TrimSuffix(args[0], "-wheel"), err)
+	}
+	fmt.Printf("%s\n", strings.Join(args, " "))
+	cmd := exec.Command(args[0], args[1:]...)
+	cmdout, err := cmd.CombinedOutput()
+	if err != nil {
+		fmt.Printf("cmd had error: %v  output:\n%v\n", err, string(cmdout))
+		return err
+	}
+	return nil
+}
@@ -6,10 +6,6 @@ package main
 
 import (
 	"fmt"
-	"go/ast"
-	"go/doc"
-	"go/parser"
-	"go/token"
 	"log"
 	"os"
 	"os/exec"
@@ -32,6 +28,12 @@ func argStr() string {
 		if strings.HasPrefix(ma[i], "-main=") {
 			ma[i] = "-main=\"" + ma[i][6:] + "\""
 		}
+		// the regexps and lists that the shell would expand or split
+		for _, opt := range []string{"-async=", "-init-env=", "-hold-gil=", "-restrict-to-interface=", "-operators="} {
+			if strings.HasPrefix(ma[i], opt) {
+				ma[i] = opt + "'" + ma[i][len(opt):] + "'"
+			}
+		}
 	}
 	return strings.Join(ma, " ")
 }
@@ -85,14 +87,15 @@ func genPkg(mode bind.BuildMode, cfg *BuildCfg) error {
 	return err
 }
 
-func loadPackage(path string, buildFirst bool) (*packages.Package, error) {
+func loadPackage(path string, buildFirst bool, cfg *BuildCfg) (*packages.Package, error) {
 	cwd, err := os.Getwd()
 	if err != nil {
 		return nil, err
 	}
 
 	if buildFirst {
-		cmd := exec.Command("go", "build", "-v", path)
+		args := append([]string{"build", "-v"}, bind.BuildFlags(&cfg.BindCfg)...)
+		cmd := exec.Command("go", append(args, path)...)
 		cmd.Stdin = os.Stdin
 		cmd.Stdout = os.Stdout
 		cmd.Stderr = os.Stderr
@@ -108,7 +111,7 @@ func loadPackage(path string, buildFirst bool) (*packages.Package, error) {
 	}
 
 	// golang.org/x/tools/go/packages supports modules or GOPATH etc
-	bpkgs, err := packages.Load(&packages.Config{Mode: packages.LoadTypes}, path)
+	bpkgs, err := packages.Load(&packages.Config{Mode: packages.LoadTypes, BuildFlags: bind.BuildFlags(&cfg.BindCfg)}, path)
 	if err != nil {
 		log.Printf("error resolving import path [%s]: %v\n",
 			path,
@@ -121,32 +124,10 @@ func loadPackage(path string, buildFirst bool) (*packages.Package, error) {
 	return bpkg, nil
 }
 
-func parsePackage(bpkg *packages.Package) (*bind.Package, error) {
-	if len(bpkg.GoFiles) == 0 {
-		err := fmt.Errorf("gopy: no files in package %q", bpkg.PkgPath)
-		fmt.Println(err)
-		return nil, err
-	}
-	dir, _ := filepath.Split(bpkg.GoFiles[0])
-	p := bpkg.Types
-
-	if bpkg.Name == "main" {
-		err := fmt.Errorf("gopy: skipping 'main' package %q", bpkg.PkgPath)
-		fmt.Println(err)
-		return nil, err
-	}
-
-	fset := token.NewFileSet()
-	var pkgast *ast.Package
-	pkgs, err := parser.ParseDir(fset, dir, nil, parser.ParseComments)
+func parsePackage(cfg *BuildCfg, bpkg *packages.Package) (*bind.Package, error) {
+	pkg, err := bind.ParsePackage(&cfg.BindCfg, bpkg)
 	if err != nil {
-		return nil, err
-	}
-	pkgast = pkgs[p.Name()]
-	if pkgast == nil {
-		return nil, fmt.Errorf("gopy: could not find AST for package %q", p.Name())
+		fmt.Println(err)
 	}
-
-	pkgdoc := doc.New(pkgast, bpkg.PkgPath, 0)
-	return bind.NewPackage(p, pkgdoc)
+	return pkg, err
 }
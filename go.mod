@@ -1,6 +1,6 @@
 module github.com/rudderlabs/gopy
 
-go 1.15
+go 1.18
 
 require (
 	github.com/gonuts/commander v0.1.0
@@ -8,3 +8,9 @@ require (
 	github.com/pkg/errors v0.9.1
 	golang.org/x/tools v0.1.11-0.20220413170336-afc6aad76eb1
 )
+
+require (
+	golang.org/x/mod v0.6.0-dev.0.20220106191415-9b9b3d81d5e3 // indirect
+	golang.org/x/sys v0.0.0-20211019181941-9d821ace8654 // indirect
+	golang.org/x/xerrors v0.0.0-20200804184101-5ec99f83aff1 // indirect
+)
@@ -0,0 +1,115 @@
+// Copyright 2019 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gopyh
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sync"
+	"time"
+)
+
+// contexts are the cancellable contexts injected in the Go calls from
+// python, by their ids, until they are cancelled or the calls return.
+var contexts struct {
+	sync.Mutex
+	last    int64
+	cancels map[int64]context.CancelFunc
+	ctxs    map[int64]context.Context
+}
+
+// NewContext returns the id of a new cancellable context, to be injected
+// in a Go call from python, see Context, and cancelled by CancelContext,
+// e.g., when the python task awaiting the call is cancelled.  Unless the
+// timeout is negative, it is also cancelled after timeout seconds.
+func NewContext(timeout float64) int64 {
+	return newContext(context.Background(), timeout)
+}
+
+// NewDeadlineContext returns the id of a new cancellable context, as
+// NewContext, that is cancelled at the deadline, in seconds since the Unix
+// epoch, e.g., for go.with_deadline.
+func NewDeadlineContext(deadline float64) int64 {
+	sec, frac := math.Modf(deadline)
+	ctx, cancel := context.WithDeadline(context.Background(), time.Unix(int64(sec), int64(frac*1e9)))
+	return addContext(ctx, cancel)
+}
+
+// DeriveContext returns the id of a new cancellable context, as NewContext,
+// derived from the context with the given parent id, which cancels it too,
+// e.g., for a Go call with a context of go.with_timeout.  A parent id that
+// is no longer in use, i.e., that was cancelled, gives a cancelled context.
+func DeriveContext(parent int64, timeout float64) int64 {
+	contexts.Lock()
+	ctx, ok := contexts.ctxs[parent]
+	contexts.Unlock()
+	if !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithCancel(context.Background())
+		cancel()
+	}
+	return newContext(ctx, timeout)
+}
+
+// newContext returns the id of a new cancellable context derived from
+// parent, with a timeout in seconds unless it is negative.
+func newContext(parent context.Context, timeout float64) int64 {
+	if timeout < 0 {
+		return addContext(context.WithCancel(parent))
+	}
+	return addContext(context.WithTimeout(parent, time.Duration(timeout*float64(time.Second))))
+}
+
+// addContext registers ctx and its cancel func, and returns its new id.
+func addContext(ctx context.Context, cancel context.CancelFunc) int64 {
+	contexts.Lock()
+	defer contexts.Unlock()
+	if contexts.ctxs == nil {
+		contexts.cancels = make(map[int64]context.CancelFunc)
+		contexts.ctxs = make(map[int64]context.Context)
+	}
+	contexts.last++
+	contexts.ctxs[contexts.last] = ctx
+	contexts.cancels[contexts.last] = cancel
+	return contexts.last
+}
+
+// CancelContext cancels the context with the given id, if it is still
+// in use.
+func CancelContext(id int64) {
+	contexts.Lock()
+	cancel := contexts.cancels[id]
+	delete(contexts.ctxs, id)
+	delete(contexts.cancels, id)
+	contexts.Unlock()
+	if cancel != nil {
+		cancel()
+	}
+}
+
+// Context returns the context with the given id, for a Go call, and the
+// func to release it once the call returns.  The id 0 is a new context
+// that is only cancelled by the release, and an id that is no longer in
+// use, i.e., that was cancelled before the call, is a cancelled one.
+func Context(id int64) (context.Context, func()) {
+	if id == 0 {
+		return context.WithCancel(context.Background())
+	}
+	contexts.Lock()
+	ctx, ok := contexts.ctxs[id]
+	contexts.Unlock()
+	if !ok {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		return ctx, cancel
+	}
+	return ctx, func() { CancelContext(id) }
+}
+
+// TimedOut returns true if the context was cancelled by its timeout.
+func TimedOut(ctx context.Context) bool {
+	return errors.Is(ctx.Err(), context.DeadlineExceeded)
+}
@@ -0,0 +1,58 @@
+// Copyright 2019 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gopyh
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDeriveContext(t *testing.T) {
+	parent := NewContext(-1)
+	child, release := Context(DeriveContext(parent, -1))
+	defer release()
+	if child.Err() != nil {
+		t.Fatalf("got derived context error %v before the parent is cancelled", child.Err())
+	}
+	CancelContext(parent)
+	select {
+	case <-child.Done():
+	case <-time.After(time.Second):
+		t.Fatalf("derived context not cancelled with its parent")
+	}
+	if TimedOut(child) {
+		t.Fatalf("got timed out derived context for a cancelled parent")
+	}
+
+	stale, release := Context(DeriveContext(parent, -1))
+	defer release()
+	if stale.Err() == nil {
+		t.Fatalf("got live context derived from a cancelled parent")
+	}
+}
+
+func TestNewDeadlineContext(t *testing.T) {
+	past := float64(time.Now().Add(-time.Second).UnixNano()) / 1e9
+	ctx, release := Context(NewDeadlineContext(past))
+	defer release()
+	if !TimedOut(ctx) {
+		t.Fatalf("got %v for a past deadline, want deadline exceeded", ctx.Err())
+	}
+
+	deadline := time.Now().Add(time.Hour)
+	parent := NewDeadlineContext(float64(deadline.UnixNano()) / 1e9)
+	defer CancelContext(parent)
+	ctx, release = Context(DeriveContext(parent, 0.01))
+	defer release()
+	<-ctx.Done()
+	if !TimedOut(ctx) {
+		t.Fatalf("got %v for the timeout of a derived context, want deadline exceeded", ctx.Err())
+	}
+	pctx, prelease := Context(parent)
+	defer prelease()
+	if d, ok := pctx.Deadline(); !ok || d.Sub(deadline).Abs() > time.Millisecond {
+		t.Fatalf("got deadline %v, want %v", d, deadline)
+	}
+}
@@ -0,0 +1,138 @@
+// Copyright 2019 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gopyh
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"runtime"
+	"runtime/debug"
+	"strconv"
+	"strings"
+)
+
+// Setenv sets the environment variable for the Go side, and applies those
+// settings of the Go runtime that can still be changed once it is running:
+// GOGC, GOMEMLIMIT (Go 1.19+) and GOMAXPROCS.  os.Setenv of GODEBUG updates
+// most of its settings, those of the standard library and some of those of
+// the runtime.  The Go runtime is initialized when the library is loaded, so
+// the other runtime settings must be in the environment before then.  The variable is
+// checked by CheckEnv first, and is not set if it is invalid.
+func Setenv(key, value string) error {
+	err := CheckEnv(key, value)
+	if err != nil {
+		return err
+	}
+	err = os.Setenv(key, value)
+	if err != nil {
+		return err
+	}
+	switch key {
+	case "GOGC":
+		pct, _ := parseGOGC(value)
+		debug.SetGCPercent(pct)
+	case "GOMEMLIMIT":
+		lim, _ := parseGOMEMLIMIT(value)
+		return setMemoryLimit(lim)
+	case "GOMAXPROCS":
+		n, _ := parseGOMAXPROCS(value)
+		runtime.GOMAXPROCS(n)
+	}
+	return nil
+}
+
+// CheckEnv returns an error if the environment variable is invalid: its key
+// is empty, or contains an = or a NUL, its value contains a NUL, or it is a
+// Go runtime setting applied by Setenv with an invalid value.
+func CheckEnv(key, value string) error {
+	if key == "" || strings.ContainsAny(key, "=\x00") {
+		return fmt.Errorf("gopy: invalid environment variable name: %q", key)
+	}
+	if strings.ContainsRune(value, 0) {
+		return fmt.Errorf("gopy: invalid %s value: %q", key, value)
+	}
+	var err error
+	switch key {
+	case "GOGC":
+		_, err = parseGOGC(value)
+	case "GOMEMLIMIT":
+		_, err = parseGOMEMLIMIT(value)
+	case "GOMAXPROCS":
+		_, err = parseGOMAXPROCS(value)
+	}
+	return err
+}
+
+// parseGOGC returns the GC percent of the GOGC value, -1 if off.
+func parseGOGC(value string) (int, error) {
+	switch {
+	case value == "":
+		return 100, nil
+	case strings.ToLower(value) == "off":
+		return -1, nil
+	}
+	pct, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("gopy: invalid GOGC value: %q", value)
+	}
+	return pct, nil
+}
+
+// parseGOMEMLIMIT returns the memory limit of the GOMEMLIMIT value,
+// math.MaxInt64 if off.
+func parseGOMEMLIMIT(value string) (int64, error) {
+	if value == "" || strings.ToLower(value) == "off" {
+		return math.MaxInt64, nil
+	}
+	lim, err := parseByteSize(value)
+	if err != nil {
+		return 0, fmt.Errorf("gopy: invalid GOMEMLIMIT value: %q", value)
+	}
+	return lim, nil
+}
+
+// parseGOMAXPROCS returns the number of procs of the GOMAXPROCS value,
+// the number of CPUs if empty.
+func parseGOMAXPROCS(value string) (int, error) {
+	if value == "" {
+		return runtime.NumCPU(), nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 1 {
+		return 0, fmt.Errorf("gopy: invalid GOMAXPROCS value: %q", value)
+	}
+	return n, nil
+}
+
+// parseByteSize parses a size in bytes in the GOMEMLIMIT format,
+// i.e., an integer with an optional B, KiB, MiB, GiB or TiB suffix.
+func parseByteSize(s string) (int64, error) {
+	mult := int64(1)
+	for _, u := range []struct {
+		sfx  string
+		mult int64
+	}{
+		{"KiB", 1 << 10},
+		{"MiB", 1 << 20},
+		{"GiB", 1 << 30},
+		{"TiB", 1 << 40},
+		{"B", 1},
+	} {
+		if strings.HasSuffix(s, u.sfx) {
+			s = strings.TrimSuffix(s, u.sfx)
+			mult = u.mult
+			break
+		}
+	}
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("gopy: invalid byte size: %q", s)
+	}
+	if n > math.MaxInt64/mult {
+		return math.MaxInt64, nil
+	}
+	return n * mult, nil
+}
@@ -0,0 +1,34 @@
+// Copyright 2019 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gopyh
+
+import (
+	"os"
+	"runtime/debug"
+	"testing"
+)
+
+func TestSetenvInvalid(t *testing.T) {
+	t.Setenv("GOGC", "75")
+	pct := debug.SetGCPercent(75)
+	defer debug.SetGCPercent(pct)
+
+	for _, kv := range [][2]string{
+		{"GOGC", "lots"},
+		{"", "x"},
+		{"A=B", "x"},
+		{"GOPY_NUL", "a\x00b"},
+	} {
+		if err := Setenv(kv[0], kv[1]); err == nil {
+			t.Errorf("Setenv(%q, %q): expected an error", kv[0], kv[1])
+		}
+	}
+	if got := os.Getenv("GOGC"); got != "75" {
+		t.Fatalf("GOGC set to %q by an invalid Setenv", got)
+	}
+	if got := debug.SetGCPercent(75); got != 75 {
+		t.Fatalf("GC percent set to %d by an invalid Setenv", got)
+	}
+}
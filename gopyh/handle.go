@@ -18,10 +18,13 @@ package gopyh
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"reflect"
+	"runtime"
 	"strconv"
 	"sync"
+	"time"
 )
 
 // GoHandle is the type for the handle
@@ -35,6 +38,8 @@ var (
 	ctr     int64
 	handles map[GoHandle]interface{}
 	counts  map[GoHandle]int64
+	locks   map[interface{}]*ptrLock
+	closers map[uintptr]int64 // number of handles of the io.Closer pointers, see Release
 )
 
 // IfaceIsNil returns true if interface or value represented by interface is nil
@@ -44,7 +49,7 @@ func IfaceIsNil(it interface{}) bool {
 	}
 	v := reflect.ValueOf(it)
 	vk := v.Kind()
-	if vk == reflect.Ptr || vk == reflect.Interface || vk == reflect.Map || vk == reflect.Slice || vk == reflect.Func || vk == reflect.Chan {
+	if vk == reflect.Ptr || vk == reflect.Interface || vk == reflect.Map || vk == reflect.Slice || vk == reflect.Func || vk == reflect.Chan || vk == reflect.UnsafePointer {
 		return v.IsNil()
 	}
 	return false
@@ -121,32 +126,91 @@ func Register(typnm string, ifc interface{}) CGoHandle {
 	ghc := GoHandle(hc)
 	handles[ghc] = ifc
 	counts[ghc] = 0
+	if p := closerPtr(ifc); p != 0 {
+		if closers == nil {
+			closers = make(map[uintptr]int64)
+		}
+		closers[p]++
+	}
 	if trace {
 		fmt.Printf("gopy Registered: %s %v %d\n", typnm, ifc, hc)
 	}
 	return CGoHandle(hc)
 }
 
+// ResetHandles clears the registry and restarts handle numbering from
+// a time-based offset.  It is used in dev mode, where a rebuilt library
+// can be reloaded into a running python session: each loaded copy then
+// issues distinct handles, so that a handle held from a previous copy
+// is reported as not registered instead of referring to another variable.
+func ResetHandles() {
+	mu.Lock()
+	defer mu.Unlock()
+	handles = make(map[GoHandle]interface{})
+	counts = make(map[GoHandle]int64)
+	closers = nil
+	ctr = time.Now().UnixNano()
+}
+
 // DecRef decrements the reference count for the specified handle
 // and removes it if the reference count goes to zero.
 func DecRef(handle CGoHandle) {
+	decRef(handle)
+}
+
+// Release decrements the reference count for the specified handle, as
+// DecRef does, and closes its variable if it is an io.Closer once the
+// handle is removed, for the deterministic finalization of the python
+// wrappers of resources -- unless other handles of the same pointer, e.g.,
+// returned by other calls, are still in use.
+func Release(handle CGoHandle) {
+	if c, ok := decRef(handle).(io.Closer); ok {
+		c.Close()
+	}
+}
+
+// closerPtr returns the pointer of ifc if it is an io.Closer pointer, whose
+// handles are counted in closers, or 0.
+func closerPtr(ifc interface{}) uintptr {
+	if _, ok := ifc.(io.Closer); !ok {
+		return 0
+	}
+	if v := reflect.ValueOf(ifc); v.Kind() == reflect.Ptr {
+		return v.Pointer()
+	}
+	return 0
+}
+
+// decRef decrements the reference count for the specified handle, and
+// returns its variable if it is removed, and it is not an io.Closer pointer
+// that other handles still refer to.
+func decRef(handle CGoHandle) (removed interface{}) {
 	if handle < 1 {
-		return
+		return nil
 	}
 	mu.Lock()
 	defer mu.Unlock()
 	if handles == nil {
-		return
+		return nil
 	}
 	ghc := GoHandle(handle)
 	if _, exists := handles[ghc]; !exists {
-		return
+		return nil
 	}
 	counts[ghc]--
 	switch cnt := counts[ghc]; {
 	case cnt == 0:
+		removed = handles[ghc]
 		delete(counts, ghc)
 		delete(handles, ghc)
+		if p := closerPtr(removed); p != 0 {
+			closers[p]--
+			if closers[p] > 0 {
+				removed = nil
+			} else {
+				delete(closers, p)
+			}
+		}
 		if trace {
 			fmt.Printf("gopy DecRef: %d\n", handle)
 		}
@@ -157,6 +221,7 @@ func DecRef(handle CGoHandle) {
 			fmt.Printf("gopy DecRef: %d: %d\n", handle, cnt)
 		}
 	}
+	return removed
 }
 
 //  IncRef increments the reference count for the specified handle.
@@ -202,9 +267,84 @@ func VarFromHandleTry(h CGoHandle, typnm string) (interface{}, error) {
 	return v, nil
 }
 
+// ptrLock is the mutex of LockHandle for a Go value, with the number of
+// calls holding or waiting for it, so that it is removed once unused.
+type ptrLock struct {
+	sync.Mutex
+	n int
+}
+
+// LockHandle acquires the mutex associated with the Go value of the given
+// handle, keyed by its pointer, as PtrMutex does, so that all the handles
+// of the same value share it, and returns the function that releases it.
+// It is used by the method wrappers of types marked with the
+// gopy:synchronized directive, so that concurrent calls from multiple
+// python threads on the same object are serialized.
+func LockHandle(h CGoHandle) func() {
+	mu.Lock()
+	var key interface{} = GoHandle(h)
+	if ptr := ptrOf(handles[GoHandle(h)]); ptr != 0 {
+		key = ptr
+	}
+	if locks == nil {
+		locks = make(map[interface{}]*ptrLock)
+	}
+	lk, has := locks[key]
+	if !has {
+		lk = &ptrLock{}
+		locks[key] = lk
+	}
+	lk.n++
+	mu.Unlock()
+	lk.Lock()
+	return func() {
+		lk.Unlock()
+		mu.Lock()
+		lk.n--
+		if lk.n == 0 {
+			delete(locks, key)
+		}
+		mu.Unlock()
+	}
+}
+
+// Collect runs the Go garbage collector, collecting the variables of the
+// removed handles, and returns the number of handles still in use.
+func Collect() int {
+	runtime.GC()
+	return NumHandles()
+}
+
 // NumHandles returns the number of handles in use.
 func NumHandles() int {
 	mu.RLock()
 	defer mu.RUnlock()
 	return len(handles)
 }
+
+// ptrMutexes are the mutexes that PtrMutex hashes the pointers to.
+var ptrMutexes [64]sync.Mutex
+
+// PtrMutex returns the mutex for the Go value that p points to, or for the
+// map that it points to or is, shared with the other values that hash to
+// the same one.  It is used by the accessors of slices, maps and struct
+// fields generated for the free-threaded python build, so that concurrent
+// accesses to the same value are serialized, whichever handles refer to it.
+func PtrMutex(p interface{}) *sync.Mutex {
+	ptr := ptrOf(p)
+	return &ptrMutexes[(ptr>>4^ptr>>10)%uintptr(len(ptrMutexes))]
+}
+
+// ptrOf returns the pointer of the Go value that p points to, or of the map
+// that it points to or is, or 0 if p is not a pointer, e.g., a struct.
+func ptrOf(p interface{}) uintptr {
+	v := reflect.ValueOf(p)
+	if v.Kind() == reflect.Ptr && v.Elem().Kind() == reflect.Map {
+		v = v.Elem()
+	}
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Chan, reflect.Func, reflect.UnsafePointer:
+		return v.Pointer()
+	}
+	return 0
+}
@@ -0,0 +1,140 @@
+// Copyright 2019 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gopyh
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestHandlesConcurrent registers, references, looks up, locks and releases
+// handles from many goroutines in parallel, as python threads calling the
+// bindings with the GIL released do -- run with -race.
+func TestHandlesConcurrent(t *testing.T) {
+	const (
+		workers = 16
+		iters   = 1000
+	)
+	before := NumHandles()
+	shared := Register("int", new(int))
+	IncRef(shared)
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < iters; i++ {
+				v := w*iters + i
+				h := Register("int", &v)
+				IncRef(h)
+				IncRef(shared)
+				if p, err := VarFromHandleTry(h, "int"); err != nil || *p.(*int) != v {
+					t.Errorf("handle %d: got %v, %v, want %d", h, p, err, v)
+					return
+				}
+				unlock := LockHandle(shared)
+				*VarFromHandle(shared, "int").(*int) += 1
+				unlock()
+				DecRef(shared)
+				DecRef(h)
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	if got := *VarFromHandle(shared, "int").(*int); got != workers*iters {
+		t.Fatalf("got shared count %d, want %d", got, workers*iters)
+	}
+	DecRef(shared)
+	if got := NumHandles(); got != before {
+		t.Fatalf("got %d handles in use, want %d", got, before)
+	}
+}
+
+// TestLockHandleShared locks a value by one of its handles, which must
+// also lock it for its other handles.
+func TestLockHandleShared(t *testing.T) {
+	v := new(int)
+	h1, h2 := Register("int", v), Register("int", v)
+	IncRef(h1)
+	IncRef(h2)
+	defer DecRef(h1)
+	defer DecRef(h2)
+
+	unlock := LockHandle(h1)
+	locked := make(chan struct{})
+	go func() {
+		LockHandle(h2)()
+		close(locked)
+	}()
+	select {
+	case <-locked:
+		t.Fatalf("locked by another handle of a locked value")
+	case <-time.After(50 * time.Millisecond):
+	}
+	unlock()
+	<-locked
+}
+
+type closer struct{ closed bool }
+
+func (c *closer) Close() error {
+	c.closed = true
+	return nil
+}
+
+// TestReleaseShared releases one of the handles of a closer returned twice,
+// which must not close it until its last handle is released.
+func TestReleaseShared(t *testing.T) {
+	c := &closer{}
+	h1 := Register("closer", c)
+	h2 := Register("closer", c)
+	IncRef(h1)
+	IncRef(h2)
+	Release(h1)
+	if c.closed {
+		t.Fatalf("closed with a handle still in use")
+	}
+	Release(h2)
+	if !c.closed {
+		t.Fatalf("not closed once its handles are released")
+	}
+}
+
+func TestPtrMutex(t *testing.T) {
+	m := map[string]int{}
+	m1, m2 := m, m
+	if PtrMutex(&m1) != PtrMutex(&m2) || PtrMutex(&m1) != PtrMutex(m) {
+		t.Fatalf("got different mutexes for the same map")
+	}
+	s := []int{1, 2}
+	if PtrMutex(&s) != PtrMutex(&s) {
+		t.Fatalf("got different mutexes for the same slice")
+	}
+
+	const (
+		workers = 16
+		iters   = 1000
+	)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < iters; i++ {
+				mu := PtrMutex(&m1)
+				mu.Lock()
+				m1["n"]++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	if m["n"] != workers*iters {
+		t.Fatalf("got %d, want %d", m["n"], workers*iters)
+	}
+}
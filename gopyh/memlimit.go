@@ -2,11 +2,14 @@
 // Use of this source code is governed by a BSD-style
 // license that can be found in the LICENSE file.
 
-// +build linux,!android dragonfly openbsd
+//go:build go1.19
+// +build go1.19
 
-package main
+package gopyh
 
-const (
-	libExt       = ".so"
-	extraGccArgs = ""
-)
+import "runtime/debug"
+
+func setMemoryLimit(lim int64) error {
+	debug.SetMemoryLimit(lim)
+	return nil
+}
@@ -0,0 +1,14 @@
+// Copyright 2019 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !go1.19
+// +build !go1.19
+
+package gopyh
+
+import "fmt"
+
+func setMemoryLimit(lim int64) error {
+	return fmt.Errorf("gopy: GOMEMLIMIT requires Go 1.19 or later")
+}
@@ -0,0 +1,163 @@
+// Copyright 2019 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package gopym records the call metrics of the wrappers generated by gopy
+// with the -metrics option: the number of calls and a latency histogram for
+// each wrapped function and method, keyed by its Go name (e.g., pkg.Type.Method).
+// The metrics are available in python through go.metrics(), and can be
+// served from the Go side in the Prometheus text format.
+package gopym
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Buckets are the upper bounds of the latency histogram buckets, in seconds.
+var Buckets = []float64{1e-6, 1e-5, 1e-4, 1e-3, 1e-2, 0.1, 1, 10}
+
+// Metric holds the metrics for one wrapped function.
+type Metric struct {
+	// Count is the number of calls
+	Count int64
+	// Sum is the total duration of the calls, in seconds
+	Sum float64
+	// Buckets are the number of calls with a duration <= the
+	// corresponding upper bound in Buckets (i.e., cumulative).
+	Buckets []int64
+}
+
+var (
+	mu      sync.Mutex
+	metrics = make(map[string]*Metric)
+)
+
+// Track starts timing a call of the named function, and returns the
+// function to call when it is done -- typically: defer Track(name)()
+func Track(name string) func() {
+	start := time.Now()
+	return func() {
+		Observe(name, time.Since(start))
+	}
+}
+
+// Observe records a call of the named function with the given duration.
+func Observe(name string, d time.Duration) {
+	secs := d.Seconds()
+	mu.Lock()
+	defer mu.Unlock()
+	m, has := metrics[name]
+	if !has {
+		m = &Metric{Buckets: make([]int64, len(Buckets))}
+		metrics[name] = m
+	}
+	m.Count++
+	m.Sum += secs
+	for i, ub := range Buckets {
+		if secs <= ub {
+			m.Buckets[i]++
+		}
+	}
+}
+
+// Snapshot returns a copy of the current metrics, by function name.
+func Snapshot() map[string]Metric {
+	mu.Lock()
+	defer mu.Unlock()
+	snap := make(map[string]Metric, len(metrics))
+	for nm, m := range metrics {
+		cm := *m
+		cm.Buckets = append([]int64(nil), m.Buckets...)
+		snap[nm] = cm
+	}
+	return snap
+}
+
+// Reset clears all of the metrics.
+func Reset() {
+	mu.Lock()
+	defer mu.Unlock()
+	metrics = make(map[string]*Metric)
+}
+
+// JSON returns the current metrics as a JSON object keyed by function name,
+// with the count, sum and cumulative buckets keyed by their upper bound.
+func JSON() string {
+	type jmetric struct {
+		Count   int64            `json:"count"`
+		Sum     float64          `json:"sum"`
+		Buckets map[string]int64 `json:"buckets"`
+	}
+	snap := Snapshot()
+	jm := make(map[string]jmetric, len(snap))
+	for nm, m := range snap {
+		bk := make(map[string]int64, len(Buckets)+1)
+		for i, ub := range Buckets {
+			bk[formatBound(ub)] = m.Buckets[i]
+		}
+		bk["+Inf"] = m.Count
+		jm[nm] = jmetric{Count: m.Count, Sum: m.Sum, Buckets: bk}
+	}
+	b, err := json.Marshal(jm)
+	if err != nil {
+		return "{}"
+	}
+	return string(b)
+}
+
+// WritePrometheus writes the current metrics in the Prometheus text format.
+func WritePrometheus(w io.Writer) error {
+	snap := Snapshot()
+	names := make([]string, 0, len(snap))
+	for nm := range snap {
+		names = append(names, nm)
+	}
+	sort.Strings(names)
+	_, err := fmt.Fprintf(w, "# HELP gopy_call_duration_seconds Duration of calls from python to wrapped Go functions.\n"+
+		"# TYPE gopy_call_duration_seconds histogram\n")
+	if err != nil {
+		return err
+	}
+	for _, nm := range names {
+		m := snap[nm]
+		for i, ub := range Buckets {
+			fmt.Fprintf(w, "gopy_call_duration_seconds_bucket{func=%q,le=%q} %d\n", nm, formatBound(ub), m.Buckets[i])
+		}
+		fmt.Fprintf(w, "gopy_call_duration_seconds_bucket{func=%q,le=\"+Inf\"} %d\n", nm, m.Count)
+		fmt.Fprintf(w, "gopy_call_duration_seconds_sum{func=%q} %s\n", nm, strconv.FormatFloat(m.Sum, 'g', -1, 64))
+		_, err = fmt.Fprintf(w, "gopy_call_duration_seconds_count{func=%q} %d\n", nm, m.Count)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Serve starts serving the metrics in the Prometheus text format at
+// http://addr/metrics, in the background.  An error is returned if
+// the address can not be listened on.
+func Serve(addr string) error {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		WritePrometheus(w)
+	})
+	go http.Serve(ln, mux)
+	return nil
+}
+
+func formatBound(ub float64) string {
+	return strconv.FormatFloat(ub, 'g', -1, 64)
+}
@@ -0,0 +1,64 @@
+// Copyright 2019 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gopym
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestObserve(t *testing.T) {
+	Reset()
+	Observe("pkg.Fast", 500*time.Nanosecond)
+	Observe("pkg.Fast", 2*time.Millisecond)
+	Observe("pkg.Slow", 20*time.Second)
+
+	snap := Snapshot()
+	fast := snap["pkg.Fast"]
+	if fast.Count != 2 {
+		t.Fatalf("got count %d, want 2", fast.Count)
+	}
+	want := []int64{1, 1, 1, 1, 2, 2, 2, 2}
+	for i, n := range want {
+		if fast.Buckets[i] != n {
+			t.Fatalf("bucket %d: got %d, want %d (buckets: %v)", i, fast.Buckets[i], n, fast.Buckets)
+		}
+	}
+	slow := snap["pkg.Slow"]
+	if slow.Buckets[len(Buckets)-1] != 0 {
+		t.Fatalf("got %d calls <= 10s, want 0", slow.Buckets[len(Buckets)-1])
+	}
+
+	var jm map[string]struct {
+		Count   int64            `json:"count"`
+		Buckets map[string]int64 `json:"buckets"`
+	}
+	if err := json.Unmarshal([]byte(JSON()), &jm); err != nil {
+		t.Fatal(err)
+	}
+	if got := jm["pkg.Fast"].Buckets["0.001"]; got != 1 {
+		t.Fatalf("got %d calls <= 1ms in json, want 1", got)
+	}
+	if got := jm["pkg.Slow"].Buckets["+Inf"]; got != 1 {
+		t.Fatalf("got %d calls in +Inf bucket in json, want 1", got)
+	}
+
+	buf := new(bytes.Buffer)
+	if err := WritePrometheus(buf); err != nil {
+		t.Fatal(err)
+	}
+	for _, ln := range []string{
+		`gopy_call_duration_seconds_bucket{func="pkg.Fast",le="1e-06"} 1`,
+		`gopy_call_duration_seconds_bucket{func="pkg.Fast",le="+Inf"} 2`,
+		`gopy_call_duration_seconds_count{func="pkg.Slow"} 1`,
+	} {
+		if !strings.Contains(buf.String(), ln+"\n") {
+			t.Errorf("missing line %q in:\n%s", ln, buf.String())
+		}
+	}
+}
@@ -24,12 +24,11 @@ type BuildCfg struct {
 
 	// include symbols in output
 	Symbols bool
-	// suppress warning messages, which may be expected
-	NoWarn bool
-	// do not generate a Makefile, e.g., when called from Makefile
-	NoMake bool
 }
 
+// extension of the Go shared library, and extra gcc args to build it
+var libExt, extraGccArgs = bind.PlatformLibExt()
+
 // NewBuildCfg returns a newly constructed build config
 func NewBuildCfg() *BuildCfg {
 	var cfg BuildCfg
@@ -45,6 +44,7 @@ func run(args []string) error {
 			gopyMakeCmdBuild(),
 			gopyMakeCmdPkg(),
 			gopyMakeCmdExe(),
+			gopyMakeCmdWheel(),
 		},
 		Flag: *flag.NewFlagSet("gopy", flag.ExitOnError),
 	}
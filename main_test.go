@@ -5,7 +5,10 @@
 package main
 
 import (
+	"archive/zip"
 	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
 	"fmt"
 	"io/ioutil"
 	"log"
@@ -17,38 +20,115 @@ import (
 	"strings"
 	"testing"
 
-	"github.com/rudderlabs/gopy/bind"
 )
 
 var (
 	testBackends = map[string]string{}
 	features     = map[string][]string{
-		"_examples/hi":          []string{"py3"}, // output is different for 2 vs. 3 -- only checking 3 output
-		"_examples/funcs":       []string{"py2", "py3"},
-		"_examples/sliceptr":    []string{"py2", "py3"},
-		"_examples/simple":      []string{"py2", "py3"},
-		"_examples/empty":       []string{"py2", "py3"},
-		"_examples/named":       []string{"py2", "py3"},
-		"_examples/structs":     []string{"py2", "py3"},
-		"_examples/consts":      []string{"py2", "py3"}, // 2 doesn't report .666 decimals
-		"_examples/vars":        []string{"py2", "py3"},
-		"_examples/seqs":        []string{"py2", "py3"},
-		"_examples/cgo":         []string{"py2", "py3"},
-		"_examples/pyerrors":    []string{"py2", "py3"},
-		"_examples/iface":       []string{"py3"}, // output order diff for 2, fails but actually works
-		"_examples/pointers":    []string{"py2", "py3"},
-		"_examples/arrays":      []string{"py2", "py3"},
-		"_examples/slices":      []string{"py2", "py3"},
-		"_examples/maps":        []string{"py2", "py3"},
-		"_examples/gostrings":   []string{"py2", "py3"},
-		"_examples/rename":      []string{"py2", "py3"},
-		"_examples/lot":         []string{"py2", "py3"},
-		"_examples/unicode":     []string{"py3"}, // doesn't work for 2
-		"_examples/osfile":      []string{"py2", "py3"},
-		"_examples/gopygc":      []string{"py2", "py3"},
-		"_examples/cstrings":    []string{"py2", "py3"},
-		"_examples/pkgconflict": []string{"py2", "py3"},
-		"_examples/variadic":    []string{"py3"},
+		"_examples/hi":            []string{"py3"}, // output is different for 2 vs. 3 -- only checking 3 output
+		"_examples/funcs":         []string{"py2", "py3"},
+		"_examples/sliceptr":      []string{"py2", "py3"},
+		"_examples/simple":        []string{"py2", "py3"},
+		"_examples/empty":         []string{"py2", "py3"},
+		"_examples/named":         []string{"py2", "py3"},
+		"_examples/structs":       []string{"py2", "py3"},
+		"_examples/consts":        []string{"py2", "py3"}, // 2 doesn't report .666 decimals
+		"_examples/vars":          []string{"py2", "py3"},
+		"_examples/seqs":          []string{"py2", "py3"},
+		"_examples/cgo":           []string{"py2", "py3"},
+		"_examples/pyerrors":      []string{"py2", "py3"},
+		"_examples/iface":         []string{"py3"}, // output order diff for 2, fails but actually works
+		"_examples/pointers":      []string{"py2", "py3"},
+		"_examples/arrays":        []string{"py2", "py3"},
+		"_examples/slices":        []string{"py2", "py3"},
+		"_examples/maps":          []string{"py2", "py3"},
+		"_examples/gostrings":     []string{"py2", "py3"},
+		"_examples/rename":        []string{"py2", "py3"},
+		"_examples/lot":           []string{"py2", "py3"},
+		"_examples/unicode":       []string{"py3"}, // doesn't work for 2
+		"_examples/osfile":        []string{"py2", "py3"},
+		"_examples/gopygc":        []string{"py2", "py3"},
+		"_examples/cstrings":      []string{"py2", "py3"},
+		"_examples/pkgconflict":   []string{"py2", "py3"},
+		"_examples/variadic":      []string{"py3"},
+		"_examples/synchronized":  []string{"py2", "py3"},
+		"_examples/restrict":      []string{"py2", "py3"},
+		"_examples/asyncnames":    []string{"py3"},
+		"_examples/devmode":       []string{"py3"},
+		"_examples/metrics":       []string{"py2", "py3"},
+		"_examples/goenv":         []string{"py2", "py3"},
+		"_examples/jsonnames":     []string{"py2", "py3"},
+		"_examples/gochan":        []string{"py3"},
+		"_examples/callbacks":     []string{"py2", "py3"},
+		"_examples/funcvals":      []string{"py3"},
+		"_examples/generics":      []string{"py2", "py3"},
+		"_examples/fixedarrays":   []string{"py2", "py3"},
+		"_examples/bytesconv":     []string{"py3"},
+		"_examples/buffers":       []string{"py3"},
+		"_examples/numpyconv":     []string{"py3"},
+		"_examples/datetimes":     []string{"py3"},
+		"_examples/errtypes":      []string{"py2", "py3"},
+		"_examples/anymaps":       []string{"py2", "py3"},
+		"_examples/listargs":      []string{"py2", "py3"},
+		"_examples/anyargs":       []string{"py2", "py3"},
+		"_examples/nestedmaps":    []string{"py2", "py3"},
+		"_examples/structmaps":    []string{"py2", "py3"},
+		"_examples/grid":          []string{"py2", "py3"},
+		"_examples/ptrslices":     []string{"py2", "py3"},
+		"_examples/optptrs":       []string{"py2", "py3"},
+		"_examples/units":         []string{"py3"},
+		"_examples/aliases":       []string{"py2", "py3"},
+		"_examples/bignums":       []string{"py2", "py3"},
+		"_examples/rawjson":       []string{"py3"},
+		"_examples/runes":         []string{"py3"},
+		"_examples/uints":         []string{"py2", "py3"},
+		"_examples/complexes":     []string{"py3"},
+		"_examples/tagnames":      []string{"py2", "py3"},
+		"_examples/anonstructs":   []string{"py2", "py3"},
+		"_examples/recursive":     []string{"py2", "py3"},
+		"_examples/embediface":    []string{"py2", "py3"},
+		"_examples/unsafeptrs":    []string{"py2", "py3"},
+		"_examples/textconv":      []string{"py3"},
+		"_examples/anyresults":    []string{"py3"},
+		"_examples/enums":         []string{"py3"},
+		"_examples/stringers":     []string{"py3"},
+		"_examples/copies":        []string{"py3"},
+		"_examples/pickles":       []string{"py3"},
+		"_examples/closers":       []string{"py3"},
+		"_examples/keywords":      []string{"py3"},
+		"_examples/structinit":    []string{"py3"},
+		"_examples/asdicts":       []string{"py3"},
+		"_examples/jsonconv":      []string{"py3"},
+		"_examples/operators":     []string{"py3"},
+		"_examples/sorting":       []string{"py3"},
+		"_examples/truthy":        []string{"py3"},
+		"_examples/docstrings":    []string{"py3"},
+		"_examples/stubs":         []string{"py3"},
+		"_examples/typehints":     []string{"py3"},
+		"_examples/introspect":    []string{"py3"},
+		"_examples/textsig":       []string{"py3"},
+		"_examples/finalize":      []string{"py3"},
+		"_examples/mapiter":       []string{"py3"},
+		"_examples/formats":       []string{"py3"},
+		"_examples/nogil":         []string{"py3"},
+		"_examples/asyncdoc":      []string{"py3"},
+		"_examples/chanaio":       []string{"py3"},
+		"_examples/cancel":        []string{"py3"},
+		"_examples/timeout":       []string{"py3"},
+		"_examples/interrupt":     []string{"py3"},
+		"_examples/concurrency":   []string{"py3"},
+		"_examples/subinterp":     []string{"py3"},
+		"_examples/freethreaded":  []string{"py3"},
+		"_examples/panics":        []string{"py3"},
+		"_examples/deadlines":     []string{"py3"},
+		"_examples/pool":          []string{"py3"},
+		"_examples/stress":        []string{"py3"},
+		"_examples/cffibackend":   []string{"py3"},
+		"_examples/cythonbackend": []string{"py3"},
+		"_examples/capibackend":   []string{"py3"},
+		"_examples/ctypesbackend": []string{"py3"},
+		"_examples/limitedapi":    []string{"py3"},
+		"_examples/checksum":      []string{"py3"},
 	}
 
 	testEnvironment = os.Environ()
@@ -127,6 +207,150 @@ ignoring python incompatible function: .func github.com/rudderlabs/gopy/_example
 	}
 }
 
+func TestWheel(t *testing.T) {
+	workdir, err := ioutil.TempDir("", "gopy-")
+	if err != nil {
+		t.Fatalf("could not create workdir: %v\n", err)
+	}
+	defer os.RemoveAll(workdir)
+
+	files := map[string]string{
+		"README.md":             "# hi\n",
+		"LICENSE":               "BSD\n",
+		"pyproject.toml":        "",
+		"hi/__init__.py":        "",
+		"hi/hi.py":              "import _hi\n",
+		"hi/hi.pyi":             "",
+		"hi/py.typed":           "",
+		"hi/_hi.abi3.so":        "\x7fELF",
+		"hi/build.py":           "",
+		"hi/hi.go":              "package main\n",
+		"hi/hi.c":               "",
+		"hi/Makefile":           "",
+		"hi/__pycache__/hi.pyc": "",
+		"hi/sub/__init__.py":    "",
+		"hi/sub/_sub_go.dylib":  "",
+		"hi/hi_go.h":            "",
+	}
+	for fn, data := range files {
+		fn = filepath.Join(workdir, fn)
+		os.MkdirAll(filepath.Dir(fn), 0755)
+		err = ioutil.WriteFile(fn, []byte(data), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	meta := wheelMeta{
+		Name:           "Hi-me",
+		Version:        "1.0.0-rc1",
+		Desc:           "hi there",
+		Readme:         "# hi\n",
+		Tag:            "cp311-abi3-linux_x86_64",
+		RequiresPython: ">=3.11",
+	}
+	whl, err := writeWheel(filepath.Join(workdir, "dist"), workdir, "hi", meta)
+	if err != nil {
+		t.Fatalf("could not write wheel: %v", err)
+	}
+	if got, want := filepath.Base(whl), "hi_me-1.0.0_rc1-cp311-abi3-linux_x86_64.whl"; got != want {
+		t.Fatalf("invalid wheel name:\ngot= %s\nwant=%s", got, want)
+	}
+
+	zr, err := zip.OpenReader(whl)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer zr.Close()
+	var names []string
+	content := make(map[string][]byte)
+	for _, f := range zr.File {
+		names = append(names, f.Name)
+		r, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		content[f.Name], _ = ioutil.ReadAll(r)
+		r.Close()
+	}
+	want := []string{
+		"hi/__init__.py",
+		"hi/_hi.abi3.so",
+		"hi/hi.py",
+		"hi/hi.pyi",
+		"hi/py.typed",
+		"hi/sub/__init__.py",
+		"hi/sub/_sub_go.dylib",
+		"hi_me-1.0.0_rc1.dist-info/METADATA",
+		"hi_me-1.0.0_rc1.dist-info/WHEEL",
+		"hi_me-1.0.0_rc1.dist-info/LICENSE",
+		"hi_me-1.0.0_rc1.dist-info/RECORD",
+	}
+	if !reflect.DeepEqual(names, want) {
+		t.Fatalf("invalid wheel files:\ngot= %q\nwant=%q", names, want)
+	}
+
+	wheel := string(content["hi_me-1.0.0_rc1.dist-info/WHEEL"])
+	if !strings.Contains(wheel, "Root-Is-Purelib: false\nTag: cp311-abi3-linux_x86_64\n") {
+		t.Fatalf("invalid WHEEL:\n%s", wheel)
+	}
+	metadata := string(content["hi_me-1.0.0_rc1.dist-info/METADATA"])
+	for _, line := range []string{"Name: Hi-me\n", "Version: 1.0.0-rc1\n", "Summary: hi there\n", "Requires-Python: >=3.11\n", "\n\n# hi\n"} {
+		if !strings.Contains(metadata, line) {
+			t.Fatalf("METADATA does not contain %q:\n%s", line, metadata)
+		}
+	}
+
+	record := strings.Split(strings.TrimSpace(string(content["hi_me-1.0.0_rc1.dist-info/RECORD"])), "\n")
+	if len(record) != len(want) {
+		t.Fatalf("invalid RECORD:\n%s", strings.Join(record, "\n"))
+	}
+	for i, line := range record[:len(record)-1] {
+		sum := sha256.Sum256(content[want[i]])
+		exp := fmt.Sprintf("%s,sha256=%s,%d", want[i], base64.RawURLEncoding.EncodeToString(sum[:]), len(content[want[i]]))
+		if line != exp {
+			t.Fatalf("invalid RECORD line:\ngot= %s\nwant=%s", line, exp)
+		}
+	}
+	if got, want := record[len(record)-1], "hi_me-1.0.0_rc1.dist-info/RECORD,,"; got != want {
+		t.Fatalf("invalid RECORD line:\ngot= %s\nwant=%s", got, want)
+	}
+
+	meta.Editable = true
+	whl, err = writeWheel(filepath.Join(workdir, "dist-editable"), workdir, "hi", meta)
+	if err != nil {
+		t.Fatalf("could not write editable wheel: %v", err)
+	}
+	zr, err = zip.OpenReader(whl)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer zr.Close()
+	names = names[:0]
+	for _, f := range zr.File {
+		names = append(names, f.Name)
+	}
+	want = []string{
+		"__editable__.hi_me-1.0.0_rc1.pth",
+		"hi_me-1.0.0_rc1.dist-info/METADATA",
+		"hi_me-1.0.0_rc1.dist-info/WHEEL",
+		"hi_me-1.0.0_rc1.dist-info/LICENSE",
+		"hi_me-1.0.0_rc1.dist-info/RECORD",
+	}
+	if !reflect.DeepEqual(names, want) {
+		t.Fatalf("invalid editable wheel files:\ngot= %q\nwant=%q", names, want)
+	}
+	r, err := zr.File[0].Open()
+	if err != nil {
+		t.Fatal(err)
+	}
+	pth, _ := ioutil.ReadAll(r)
+	r.Close()
+	if got, want := string(pth), workdir+"\n"; got != want {
+		t.Fatalf("invalid .pth file:\ngot= %q\nwant=%q", got, want)
+	}
+}
+
 func TestHi(t *testing.T) {
 	// t.Parallel()
 	path := "_examples/hi"
@@ -164,18 +388,32 @@ Hi()
 	
 	Hi prints hi from Go
 	
+	Args:
+	    goRun (bool): run the call in a new goroutine, without waiting for it to return
+	
 --- hi.Hi()...
 --- doc(hi.Hello)...
 Hello(str s) 
 	
 	Hello prints a greeting from Go
 	
+	Args:
+	    s (str): Go string
+	    goRun (bool): run the call in a new goroutine, without waiting for it to return
+	
 --- hi.Hello('you')...
 --- doc(hi.Add)...
 Add(int i, int j) int
 	
 	Add returns the sum of its arguments.
 	
+	Args:
+	    i (int): Go int
+	    j (int): Go int
+	
+	Returns:
+	    int: Go int
+	
 --- hi.Add(1, 41)...
 42
 --- hi.Concat('4', '2')...
@@ -196,6 +434,9 @@ Greet() str
 		
 		Greet sends greetings
 		
+		Returns:
+		    str: Go string
+		
 --- p.Greet()...
 Hello, I am 
 --- p.String()...
@@ -594,10 +835,36 @@ slices.IntSum from Python list: 10
 slices.IntSum from Go slice: 10
 unsigned slice elements: 1 2 3 4
 signed slice elements: -1 -2 -3 -4
-struct slice:  slices.Slice_Ptr_slices_S len: 3 handle: 11 [12, 13, 14]
+struct slice:  slices.Slice_Ptr_slices_S len: 3 handle: 11 [slices.S{Name=S0, handle=12}, slices.S{Name=S1, handle=13}, slices.S{Name=S2, handle=14}]
 struct slice[0]:  slices.S{Name=S0, handle=15}
 struct slice[1]:  slices.S{Name=S1, handle=16}
 struct slice[2].Name:  S2
+struct slice tolist: list ['S0', 'S1', 'S2']
+struct values tolist: ['S', 'S'] ['V0', 'V1*']
+struct values insert: ['V-', 'V0', 'V1*'] V0
+sequence: True True
+insert: [0, 1, 2, 9, 3, 4]
+index/count: 3 1 True False
+remove/del: [1, 2, 3, 4]
+pop: 4 1 [2, 3]
+extend: [2, 3, 5, 6, 7] [7, 6, 5, 3, 2]
+reverse: [7, 6, 5, 3, 2]
+del slice: [2, 6, 7]
+index: caught: 42 is not in Slice_int
+slicing: [1, 2, 3, 4] [0, 3, 6, 9] [9, 7, 5, 3, 1] [7, 8, 9] []
+shared: 42 0 Slice_int
+assign: [0, 7, 8, 9, 3, 4, 5, 6, 7, 8, 9]
+assign extended: [-1, 7, 8, 9, -2, 4, 5, 6, -3, 8, 9]
+assign empty: [-1, 7]
+assign extended: caught: attempt to assign sequence of size 3 to extended slice of size 1
+negative: 30 1 [1, 2, 30]
+negative del: [2, 30]
+negative get: caught: slice index out of range
+negative set: caught: slice index out of range
+negative del: caught: slice index out of range
+contains: True False False True
+contains struct: True False False
+contains ptr: True False
 OK
 `),
 	})
@@ -623,6 +890,16 @@ maps.Values from Go map: go.Slice_float64 len: 2 handle: 4 [3.0, 5.0]
 maps.Keys from Python dictionary: go.Slice_int len: 2 handle: 6 [1, 2]
 maps.Values from Python dictionary: go.Slice_float64 len: 2 handle: 8 [3.0, 5.0]
 deleted 1 from a: maps.Map_int_float64 len: 1 handle: 1 {2=5.0, }
+mapping: True [(1, 3.0), (2, 5.0)] [1, 2]
+kwargs: [('a', 1), ('b', 2)]
+get: 1 None 0
+pop: 1 -1 ['b']
+update: [('b', 2), ('c', 3), ('d', 4), ('e', 5)]
+setdefault: 2 6 5
+popitem: True 4
+clear: 0 {}
+eq: True False False False False
+pop: caught: 'a'
 OK
 `),
 	})
@@ -824,11 +1101,1800 @@ func TestBindVariadic(t *testing.T) {
 Variadic 1+2+3+4+5 = 15
 Variadic Struct s(1)+s(2)+s(3) = 6
 Variadic InterFace i(1)+i(2)+i(3) = 6
+Variadic Join a-b-c = a-b-c
+Variadic Log x:1:2.5 = x:1:2.5
+Variadic TypeError: VariFunc() variadic argument 1 must be int, not str
 Type OK
 `),
 	})
 }
 
+func TestBindSynchronized(t *testing.T) {
+	// t.Parallel()
+	path := "_examples/synchronized"
+	testPkg(t, pkg{
+		path:   path,
+		lang:   features[path],
+		cmd:    "build",
+		extras: nil,
+		want: []byte(`c.Value() = 8000
+doc: Counter is not safe for concurrent use on the Go side:
+the directive below makes gopy serialize all method calls.
+OK
+`),
+	})
+}
+
+func TestBindRestrict(t *testing.T) {
+	// t.Parallel()
+	path := "_examples/restrict"
+	testPkg(t, pkg{
+		path:   path,
+		lang:   features[path],
+		cmd:    "build",
+		extras: []string{"-restrict-to-interface=Named=fmt.Stringer"},
+		want: []byte(`s.Get('k') = v
+hasattr(s, 'Reset') = False
+hasattr(s, 'Dump') = False
+str(n) = Named{a}
+hasattr(n, 'Rename') = False
+n.Name = a
+OK
+`),
+	})
+}
+
+func TestBindAsyncNames(t *testing.T) {
+	// t.Parallel()
+	path := "_examples/asyncnames"
+	testPkg(t, pkg{
+		path:   path,
+		lang:   features[path],
+		cmd:    "build",
+		extras: []string{"-async=.*Stream$|.*Watch$"},
+		want: []byte(`sum: 3
+sum watch: 7
+number stream: [0, 1, 2]
+ticks: 2
+iscoroutinefunction(Sum): False
+OK
+`),
+	})
+}
+
+func TestBindDevMode(t *testing.T) {
+	// t.Parallel()
+	path := "_examples/devmode"
+	testPkg(t, pkg{
+		path:   path,
+		lang:   features[path],
+		cmd:    "build",
+		extras: []string{"-dev"},
+		want: []byte(`t.Name = before
+generation = 2
+generation = 3
+devmode.Loads = 1
+t.Name = after
+OK
+`),
+	})
+}
+
+func TestBindMetrics(t *testing.T) {
+	// t.Parallel()
+	path := "_examples/metrics"
+	testPkg(t, pkg{
+		path:   path,
+		lang:   features[path],
+		cmd:    "build",
+		extras: []string{"-metrics"},
+		want: []byte(`metrics.Add count: 3
+metrics.Acc.Add count: 1
+metrics.Add +Inf bucket: 3
+metrics.Add sum >= 0: True
+after reset: {}
+OK
+`),
+	})
+}
+
+func TestBindGoEnv(t *testing.T) {
+	// t.Parallel()
+	path := "_examples/goenv"
+	testPkg(t, pkg{
+		path:   path,
+		lang:   features[path],
+		cmd:    "build",
+		extras: []string{"-init-env=GOPY_INIT=init;GOGC=50"},
+		want: []byte(`GOPY_INIT = init
+GC percent = 50
+GOPY_SET = set
+GC percent = 75
+caught error: gopy: invalid GOGC value: "lots"
+GOGC = 75
+OK
+`),
+	})
+}
+
+func TestBindJSONNames(t *testing.T) {
+	// t.Parallel()
+	path := "_examples/jsonnames"
+	testPkg(t, pkg{
+		path:   path,
+		lang:   features[path],
+		cmd:    "build",
+		extras: []string{"-json-names"},
+		want: []byte(`u.user_id: 42
+u.email_address: gopher@example.com
+u.user_id: 7
+u.email_address: a@b.c
+u.Secret: s
+u.custom_field: c
+u.Plain: True
+User.user_id.__doc__: UserID is exposed as user_id
+OK
+`),
+	})
+}
+
+func TestBindChan(t *testing.T) {
+	// t.Parallel()
+	path := "_examples/gochan"
+	testPkg(t, pkg{
+		path:   path,
+		lang:   features[path],
+		cmd:    "build",
+		extras: nil,
+		want: []byte(`Produce(4): [0, 1, 4, 9]
+Stream: ['a', 'stream', 'of', 'words']
+range Produce: 0
+range Produce: 1
+range Produce: 4
+RecvChan_string(): RecvChan_string can only be obtained from Go
+cap: 3
+len: 3
+Sum: 6
+send after close: send on closed channel
+close after close: close of closed channel
+Upper: HELLO
+recv after close: channel closed
+Items: a b
+recv timeout: channel receive timed out
+nil chan: nil channel
+nil chan: nil channel
+nil chan: nil channel
+recv interrupted
+send interrupted
+iteration interrupted
+OK
+`),
+	})
+}
+
+func TestBindCallbacks(t *testing.T) {
+	// t.Parallel()
+	path := "_examples/callbacks"
+	testPkg(t, pkg{
+		path:   path,
+		lang:   features[path],
+		cmd:    "build",
+		extras: nil,
+		want: []byte(`Map: [10, 20, 30]
+Filter: ['ann', 'al']
+Combine: <5>
+Each: [0, 1, 2] each 2: stop at two
+unraisable: ZeroDivisionError
+unraisable: ZeroDivisionError
+Map raising: [0, 0]
+Spawn: 42
+unraisable: ZeroDivisionError
+Spawn raising: 0
+Parse: 2.5
+Parse error: GoError
+Move: 2 4
+notified: HELLO
+notified: AGAIN
+OK
+`),
+	})
+}
+
+func TestBindFuncVals(t *testing.T) {
+	// t.Parallel()
+	path := "_examples/funcvals"
+	testPkg(t, pkg{
+		path:   path,
+		lang:   features[path],
+		cmd:    "build",
+		extras: nil,
+		want: []byte(`callable: True
+add5(10): 15
+add5(-5): 0
+Counter: [1, 2, 3]
+Formatter: val: x=42
+NewFormatter error: empty prefix
+Checker error: empty string
+Compose(add5, double)(1): 12
+Run: HELLO
+Transform: GO
+Run reversed: olleh
+Run restored: HELLO
+Transform TypeError: supplied argument type <class 'int'> is not callable
+Formatter() TypeError: Formatter can only be obtained from Go
+OK
+`),
+	})
+}
+
+func TestBindGenerics(t *testing.T) {
+	// t.Parallel()
+	path := "_examples/generics"
+	testPkg(t, pkg{
+		path:   path,
+		lang:   features[path],
+		cmd:    "build",
+		extras: []string{"-instantiate=List[int],List[string],NewList[int],Pair[string,int],MakePair[string,int],Max[int],Max[float64],Sum[int]"},
+		want: []byte(`li.Len(): 3
+li.At(2): 39
+li: List[1, 2, 39]
+Sum_int(li): 42
+ls: List[a, b]
+p.Key: answer p.Val: 42
+p2.Key: one p2.Val: 1
+Max_int(3, 7): 7
+Max_float64(2.5, 1.5): 2.5
+Hello: hello gopher
+OK
+`),
+	})
+}
+
+func TestBindFixedArrays(t *testing.T) {
+	// t.Parallel()
+	path := "_examples/fixedarrays"
+	testPkg(t, pkg{
+		path:   path,
+		lang:   features[path],
+		cmd:    "build",
+		extras: nil,
+		want: []byte(`len(a): 4
+Sum(a): 10
+Reverse(a): [4, 3, 2, 1]
+a after Reverse: [1, 2, 3, 4]
+caught: Array_4_int.__init__ takes a sequence of length 4, not 3
+NewID: abababababababababababababababab
+NewID IsZero: False
+zero IsZero: True
+from bytes: 000102030405060708090a0b0c0d0e0f
+ParseID: 000102030405060708090a0b0c0d0e0f 15
+caught: invalid id length 2
+Record.ID IsZero: True
+Record.ID: abababababababababababababababab
+Record.Coord: [1.5, -2.0, 0.5]
+Record.Norm1: 4.0
+caught: Array_3_float64.__init__ takes a sequence of length 3, not 2
+OK
+`),
+	})
+}
+
+func TestBindBytesConv(t *testing.T) {
+	// t.Parallel()
+	path := "_examples/bytesconv"
+	testPkg(t, pkg{
+		path:   path,
+		lang:   features[path],
+		cmd:    "build",
+		extras: []string{"-bytes"},
+		want: []byte(`Upper(bytes): True HELLO
+Upper(bytearray): ABC
+Upper(memoryview): XYZ
+Len: 3 0
+Concat: hi
+Hash: ba7816bf8f01cfea
+caught TypeError for int arg
+Decode: data
+caught: empty input
+Chunks: 3 ['abc', 'def', 'g']
+Message.Payload: payload 7
+Message.Payload: xy 2
+OK
+`),
+	})
+}
+
+func TestBindBuffers(t *testing.T) {
+	// t.Parallel()
+	path := "_examples/buffers"
+	testPkg(t, pkg{
+		path:   path,
+		lang:   features[path],
+		cmd:    "build",
+		extras: nil,
+		want: []byte(`view: d 8 4 False [0.0, 1.0, 2.0, 3.0]
+Sum after write: 16.5
+Sum after memoryview write: 35.5
+bytes: b'hello'
+String: jello
+ints: i [7, 7, 7]
+empty: 0 d
+OK
+`),
+	})
+}
+
+func TestBindNumpyConv(t *testing.T) {
+	// t.Parallel()
+	path := "_examples/numpyconv"
+	testPkg(t, pkg{
+		path:   path,
+		lang:   features[path],
+		cmd:    "build",
+		extras: []string{"-numpy"},
+		want: []byte(`Range: int64 [0, 1, 2, 3, 4]
+from_numpy: 5 [0.0, 0.25, 0.5, 0.75, 1.0]
+Scale: float64 [0.0, 0.5, 1.0, 1.5, 2.0]
+from 2d ints: [0.0, 1.0, 2.0, 3.0, 4.0, 5.0]
+Samples: float32 [0.5, 0.5, 0.5, 0.5]
+Samples.from_numpy: Samples 2.0
+empty: (0,)
+big: 100000 100000.0
+OK
+`),
+	})
+}
+
+func TestBindDateTimes(t *testing.T) {
+	// t.Parallel()
+	path := "_examples/datetimes"
+	testPkg(t, pkg{
+		path:   path,
+		lang:   features[path],
+		cmd:    "build",
+		extras: []string{"-datetime"},
+		want: []byte(`Epoch: 1970-01-01T00:00:00+00:00 UTC
+AddDays: 2024-03-01T12:30:15.123456+00:00
+Unix: 1709123415
+Unix with offset: 1704096000 2024-01-01T08:00:00+00:00
+naive is local: True
+Parse: 2023-06-01T08:00:00.500000+00:00
+caught Parse error
+caught: a datetime.datetime is required
+Latest: 2024-02-28T12:30:15.123456+00:00
+Event.At: 2024-02-28T12:30:15.123456+00:00
+Before: False True
+Event.At: 1970-01-01T00:00:00+00:00
+DefaultTimeout: datetime.timedelta(seconds=1, microseconds=500000)
+Elapsed: datetime.timedelta(days=58, seconds=16215, microseconds=123456)
+Elapsed negative: True
+Format timedelta: 1h2m0.000003s
+Format seconds: 1m30s 250ms
+caught: a datetime.timedelta or a number of seconds is required
+Event.For: datetime.timedelta(days=2) 1970-01-03T00:00:00+00:00
+Event.For: datetime.timedelta(seconds=30)
+OK
+`),
+	})
+}
+
+func TestBindErrTypes(t *testing.T) {
+	// t.Parallel()
+	path := "_examples/errtypes"
+	testPkg(t, pkg{
+		path:   path,
+		lang:   features[path],
+		cmd:    "build",
+		extras: nil,
+		want: []byte(`Lookup: 2
+caught ErrNotFound: not found
+caught ErrEmpty: empty key
+caught InvalidKeyError: invalid key "_x" (code 42)
+InvalidKeyError fields: _x 42
+caught LimitError: limit of 10 exceeded 10
+caught GoError: GoError negative
+caught RuntimeError: True not found
+Check: ''
+caught Find: GoError find "three": not found
+cause: ErrNotFound not found
+is_: True False
+as_: _y 42
+as_ none: None
+caught CheckAll: 2 errors
+exceptions: None ['limit of 10 exceeded', 'negative']
+is_: True 10
+OK
+`),
+	})
+}
+
+func TestBindAnyMaps(t *testing.T) {
+	// t.Parallel()
+	path := "_examples/anymaps"
+	testPkg(t, pkg{
+		path:   path,
+		lang:   features[path],
+		cmd:    "build",
+		extras: nil,
+		want: []byte(`Describe: {a:{n:nil t:bool(true)} b:[int(1) float64(2.5) string(x)]}
+Describe tuple: {t:[int(1) [int(2) int(3)]]}
+Count: 4
+Merge: {a:int(1) b:string(two)}
+Describe handle: {k:int(-7)}
+caught: dict keys must be str to convert to a Go map[string]interface{}
+caught: value cannot be converted to a Go interface{}
+caught: Map_string_interface_.__init__ takes a mapping as argument
+OK
+`),
+	})
+}
+
+func TestBindListArgs(t *testing.T) {
+	// t.Parallel()
+	path := "_examples/listargs"
+	testPkg(t, pkg{
+		path:   path,
+		lang:   features[path],
+		cmd:    "build",
+		extras: nil,
+		want: []byte(`Sum list: 6
+Sum tuple: 9
+Sum empty: 0
+Sum handle: 13
+Mean: 2.0
+Join: a-b-c
+Centroid: 2 1
+Triple: 24
+Types: int float64 string bool <nil> []interface {} map[string]interface {}
+Count: 1
+caught: Sum() argument xs element 1 must be int, not str
+caught: Mean() argument xs element 1 must be float, not NoneType
+caught: Centroid() argument pts element 1 must be *listargs.Point, not int
+caught ValueError
+OK
+`),
+	})
+}
+
+func TestBindAnyArgs(t *testing.T) {
+	// t.Parallel()
+	path := "_examples/anyargs"
+	testPkg(t, pkg{
+		path:   path,
+		lang:   features[path],
+		cmd:    "build",
+		extras: nil,
+		want: []byte(`int: int(42)
+float: float64(2.5)
+str: string(hi)
+bool: bool(true)
+None: nil
+list: [int(1) string(a) [bool(false)]]
+dict: {a:{c:nil} b:float64(1.5)}
+handle: *Point(1,2)
+Equal: True False
+Box: {p:*Point(1,2) x:[int(1) int(2)]}
+caught: empty name
+caught: value cannot be converted to a Go interface{}
+caught: value cannot be converted to a Go interface{}
+Box: {p:*Point(1,2) x:[int(1) int(2)]}
+OK
+`),
+	})
+}
+
+func TestBindNestedMaps(t *testing.T) {
+	// t.Parallel()
+	path := "_examples/nestedmaps"
+	testPkg(t, pkg{
+		path:   path,
+		lang:   features[path],
+		cmd:    "build",
+		extras: nil,
+		want: []byte(`db host: localhost
+sections: ['db', 'web']
+Count: 5
+Describe: cache.ttl=60 db.host=localhost db.port=5432 db.user=admin web.port=8080
+Count dict: 2
+Sum: 10
+Lookup: example.com 80
+caught: 'key not in map'
+OK
+`),
+	})
+}
+
+func TestBindStructMaps(t *testing.T) {
+	// t.Parallel()
+	path := "_examples/structmaps"
+	testPkg(t, pkg{
+		path:   path,
+		lang:   features[path],
+		cmd:    "build",
+		extras: nil,
+		want: []byte(`tr: 4 3
+Width: 4
+Describe: a:(1,2) o:(0,0)
+copy: 1
+assigned: 10
+Width dict: 12
+SumX: 10
+caught: structmaps_Registry values must be Point, not int
+OK
+`),
+	})
+}
+
+func TestBindGrid(t *testing.T) {
+	// t.Parallel()
+	path := "_examples/grid"
+	testPkg(t, pkg{
+		path:   path,
+		lang:   features[path],
+		cmd:    "build",
+		extras: nil,
+		want: []byte(`t[1][2]: r1c2
+rows: [['r0c0', 'r0c1', 'r0c2'], ['r1c0', 'r1c1', 'r1c2']]
+[1 0 7]
+[0 1 0 5]
+[0 0 1]
+Sum: 15
+appended: 4 [1, 1, 1] [2, 2]
+Sum: 14
+Sum list: 6
+Shape: 1,2 0
+caught TypeError
+OK
+`),
+	})
+}
+
+func TestBindPtrSlices(t *testing.T) {
+	// t.Parallel()
+	path := "_examples/ptrslices"
+	testPkg(t, pkg{
+		path:   path,
+		lang:   features[path],
+		cmd:    "build",
+		extras: nil,
+		want: []byte(`Total: 3
+shared: 2 5
+Names: z,b,c 8
+iter: [('z', 5), ('b', 2), ('c', 1)]
+Total list: 10
+caught: Slice_Ptr_ptrslices_Item elements must be Item, not int
+caught: Slice_Ptr_ptrslices_Item elements must be Item, not str
+OK
+`),
+	})
+}
+
+func TestBindOptPtrs(t *testing.T) {
+	// t.Parallel()
+	path := "_examples/optptrs"
+	testPkg(t, pkg{
+		path:   path,
+		lang:   features[path],
+		cmd:    "build",
+		extras: nil,
+		want: []byte(`Describe: 1 "a" true
+Describe None: nil nil nil
+Parse: 42 None
+Incr: 6 None
+empty: Query{ } None None None None
+set: Query{ Name="bob" MinAge=18 Active=false Score=2.5 }
+fields: bob 18 False 2.5
+unset: Query{ Name="bob" Active=false Score=2.5 } None
+caught TypeError
+OK
+`),
+	})
+}
+
+func TestBindUnits(t *testing.T) {
+	// t.Parallel()
+	path := "_examples/units"
+	testPkg(t, pkg{
+		path:   path,
+		lang:   features[path],
+		cmd:    "build",
+		extras: nil,
+		want: []byte(`Warmer: 21.5 float
+Fahrenheit: 212.0
+Tag: temp-a str
+Raise: 3 int
+Reading: s1: 21.5C s1 21.5 float
+Temps: [21.5, -3.0]
+NewType: Celsius Label Level
+OK
+`),
+	})
+}
+
+func TestBindAliases(t *testing.T) {
+	// t.Parallel()
+	path := "_examples/aliases"
+	testPkg(t, pkg{
+		path:   path,
+		lang:   features[path],
+		cmd:    "build",
+		extras: nil,
+		want: []byte(`NewPt: Point 1 2 3
+Pt is Point: True
+Pt: Point 7
+Add: 5 True
+Length: 2
+Origin: 3 4
+Write: abcd True
+OK
+`),
+	})
+}
+
+func TestBindBigNums(t *testing.T) {
+	// t.Parallel()
+	path := "_examples/bignums"
+	testPkg(t, pkg{
+		path:   path,
+		lang:   features[path],
+		cmd:    "build",
+		extras: []string{"-bignum"},
+		want: []byte(`Factorial: 265252859812191058636308480000000 True
+Add: 1180591620717411303423
+Digits: 41 2 -1
+Max: True
+Sqrt: 1.5 4.0
+caught: an int is required
+caught: nan cannot be converted to a Go *big.Float
+Balance: None
+Balance: 100000000000000000005
+Balance: 7
+OK
+`),
+	})
+}
+
+func TestBindRawJSON(t *testing.T) {
+	// t.Parallel()
+	path := "_examples/rawjson"
+	testPkg(t, pkg{
+		path:   path,
+		lang:   features[path],
+		cmd:    "build",
+		extras: []string{"-json"},
+		want: []byte(`Config: dict [('name', 'gopy'), ('none', None), ('ok', True), ('size', 3), ('tags', ['a', 'b'])]
+Keys: ['a', 'b']
+Compact: [1,{"x":null},"s",2.5]
+caught not an object
+caught TypeError
+Payload: {'x': 1, 'y': [2, 3]}
+meta: None
+Event: Event{click {"x": 1, "y": [2, 3]} {"source": "test"} ""}
+meta: {'source': 'test'} Slice_byte
+Payload: None
+OK
+`),
+	})
+}
+
+func TestBindRunes(t *testing.T) {
+	// t.Parallel()
+	path := "_examples/runes"
+	testPkg(t, pkg{
+		path:   path,
+		lang:   features[path],
+		cmd:    "build",
+		extras: nil,
+		want: []byte(`Upper: A True
+Code: 233
+Reverse: olleh True
+Count: 3
+Map: ABC
+Sep: |
+caught: a str of one character is required
+caught: a str of one character is required
+caught: a str is required
+Cell: x Slice_int32
+caught: a str of one character is required
+OK
+`),
+	})
+}
+
+func TestBindUints(t *testing.T) {
+	// t.Parallel()
+	path := "_examples/uints"
+	testPkg(t, pkg{
+		path:   path,
+		lang:   features[path],
+		cmd:    "build",
+		extras: nil,
+		want: []byte(`Max: 18446744073709551615 True
+Half: 9223372036854775807
+Next: 9223372036854775809
+Hash: 11327638770689273320
+Grow: 13835058055282163712
+Sum: 13835058055282163713
+Apply: 9223372036854775808
+caught OverflowError: -1
+caught OverflowError: 18446744073709551616
+caught OverflowError: Delete
+Deleted: [7]
+Counter: 18446744073709551615
+caught OverflowError: N
+caught OverflowError: element
+Slice: [1, 2, 18446744073709551615]
+OK
+`),
+	})
+}
+
+func TestBindComplexes(t *testing.T) {
+	// t.Parallel()
+	path := "_examples/complexes"
+	testPkg(t, pkg{
+		path:   path,
+		lang:   features[path],
+		cmd:    "build",
+		extras: nil,
+		want: []byte(`Roots: Slice_complex128 [(1+0j), 1j, (-1+0j), -1j]
+Sum: (6.5+5j)
+Product: (-2+0j)
+caught: Sum() argument cs element 1 must be complex, not str
+Slice: [(5+0j), 2j, (3-1j)] (3-1j)
+caught TypeError
+Slice: [(5+0j), 2j, (3-1j)]
+Poles: [('a', (3+4j)), ('b', (2+0j))] (3+4j)
+Gain: 10.0
+Zeros: [(1+1j)]
+Transform: [2j, (-2+0j)]
+OK
+`),
+	})
+}
+
+func TestBindTagNames(t *testing.T) {
+	// t.Parallel()
+	path := "_examples/tagnames"
+	testPkg(t, pkg{
+		path:   path,
+		lang:   features[path],
+		cmd:    "build",
+		extras: nil,
+		want: []byte(`u.x: 7
+u.name: Gopher
+u.myclass: admin
+u.Handle: 3
+u.Age: 12
+u.name: Gordon
+has ID: False
+has FullName: False
+has Secret: False
+u.GetSecret(): s3cr3t
+OK
+`),
+	})
+}
+
+func TestBindAnonStructs(t *testing.T) {
+	// t.Parallel()
+	path := "_examples/anonstructs"
+	testPkg(t, pkg{
+		path:   path,
+		lang:   features[path],
+		cmd:    "build",
+		extras: nil,
+		want: []byte(`type: Stats_Result
+s.Count: 3
+s.Err: none
+Total(s): 5
+Total(new): 7
+type: Point_Result
+p.X, p.Y: 1 2
+type: Config_Limits
+c.Limits: 2 10
+c.Limits.Step.Size: 1
+c.Range(): 18
+pair: Pairs_Result a 1
+pair: Pairs_Result b 2
+has Hidden: False
+OK
+`),
+	})
+}
+
+func TestBindRecursive(t *testing.T) {
+	// t.Parallel()
+	path := "_examples/recursive"
+	testPkg(t, pkg{
+		path:   path,
+		lang:   features[path],
+		cmd:    "build",
+		extras: nil,
+		want: []byte(`children: 2 a b
+parent: root
+byname: a
+sum: 6
+staff: alice, bob
+dept: eng
+depth: 3
+dict: x, y
+has Walk: False
+OK
+`),
+	})
+}
+
+func TestBindEmbedIface(t *testing.T) {
+	// t.Parallel()
+	path := "_examples/embediface"
+	testPkg(t, pkg{
+		path:   path,
+		lang:   features[path],
+		cmd:    "build",
+		extras: nil,
+		want: []byte(`p.Name(): ann
+p is Namer: True
+e.GetID(), e.Name(): 7 bob
+d.Read(buf): 5
+buf: hello
+l.String(): <lbl>
+str(l): <lbl>
+str(f): boom
+Both has Name: False
+OK
+`),
+	})
+}
+
+func TestBindUnsafePtrs(t *testing.T) {
+	// t.Parallel()
+	path := "_examples/unsafeptrs"
+	testPkg(t, pkg{
+		path:   path,
+		lang:   features[path],
+		cmd:    "build",
+		extras: nil,
+		want: []byte(`type: unsafe_Pointer
+Sum(b.Data, b.Len): 12
+Same(b.Data, b.Data): True
+Same(b.Data, c.Data): False
+Sum(c.Data, 4): 12
+len(ps): 2
+Sum(ps[1], 1): 9
+Sum(Nil(), 1): 0
+Addr(Nil()): 0
+Addr(b.Data) > 0: True
+OK
+`),
+	})
+}
+
+func TestBindTextConv(t *testing.T) {
+	// t.Parallel()
+	path := "_examples/textconv"
+	testPkg(t, pkg{
+		path:   path,
+		lang:   features[path],
+		cmd:    "build",
+		extras: []string{"-text"},
+		want: []byte(`Version: v1.2 str
+Current: v1.2
+Latest: v1.3 None
+DefaultLevel: info
+caught: invalid level: 7
+SetLevel: False
+DefaultFlags: fast,safe
+Has: True True False
+caught: empty flag in "a,,b"
+caught TypeError
+Build: gopy v1.2 fast,safe
+Describe: gopy v1.2 [debug,fast]
+caught read-only Version
+Describe: x v0.0 [a]
+OK
+`),
+	})
+}
+
+func TestBindAnyResults(t *testing.T) {
+	// t.Parallel()
+	path := "_examples/anyresults"
+	testPkg(t, pkg{
+		path:   path,
+		lang:   features[path],
+		cmd:    "build",
+		extras: nil,
+		want: []byte(`none: NoneType None
+bool: bool True
+int: int 42
+level: int 3
+uint: int 9223372036854775808
+float: float 2.5
+string: str 'hello'
+bytes: bytes b'raw'
+ints: list [1, 2, 3]
+intptr: int 7
+complex: str '(1+2i)'
+intmap: str 'map[1:a]'
+map: dict [('a', 1), ('b', ['x', 2.0, None])]
+point: Point 1 2 3
+ptr: Point 3 4 7
+nilptr: None
+Lookup: [1, 'b']
+caught: no key: b
+Get: {'k': 1.5}
+Get: Point 11
+OK
+`),
+	})
+}
+
+func TestBindEnums(t *testing.T) {
+	// t.Parallel()
+	path := "_examples/enums"
+	testPkg(t, pkg{
+		path:   path,
+		lang:   features[path],
+		cmd:    "build",
+		extras: nil,
+		want: []byte(`Color: ['colorUnknown', 'Red', 'Green', 'Blue']
+Red: 1 Red True True
+Crimson: Red True
+colorUnknown: False True
+Next: 2 Green
+Next: True
+Unknown: colorUnknown
+Invalid: 42 False
+Flags: ['FlagA', 'FlagB', 'FlagHigh'] 3
+Default: 129 True True False
+Has: True False
+IntFlag: True False
+MaxBig: 18446744073709551615 MaxBig
+Ratio: 0.5 1 True
+Status: ['Pending', 'Done', 'failed'] True pending True
+Check: True Pending
+Fail: True 'failed: "quoted"\n'
+from_value: other str
+Describe: status done status x
+OK
+`),
+	})
+}
+
+func TestBindStringers(t *testing.T) {
+	// t.Parallel()
+	path := "_examples/stringers"
+	testPkg(t, pkg{
+		path:   path,
+		lang:   features[path],
+		cmd:    "build",
+		extras: nil,
+		want: []byte(`Point: (1, 2) stringers.Point(handle=h, '(1, 2)')
+Point list: ['(1, 2)']
+Path: (0, 0) -> (1, 1) -> (2, 2) stringers.Path(handle=h, '(0, 0) -> (1, 1) -> (2, 2)')
+Plain: stringers.Plain{Name=x, handle=h}
+Names: ada, bob stringers.Names(handle=h, 'ada, bob')
+Counts: a:1 b:2 stringers.Counts(handle=h, 'a:1 b:2')
+Shape: square 2 stringers.Shape(handle=h, 'square 2') 4.0
+OK
+`),
+	})
+}
+
+func TestBindCopies(t *testing.T) {
+	// t.Parallel()
+	path := "_examples/copies"
+	testPkg(t, pkg{
+		path:   path,
+		lang:   features[path],
+		cmd:    "build",
+		extras: nil,
+		want: []byte(`copy: same handle: True tag: t
+copy: shared Name: c
+deepcopy: same handle: False tag: t
+deepcopy: o: o 1 1 1
+deepcopy: d: d 2 10 10
+deepcopy: IsSelf: True d
+Names: ['ada', 'dan'] ['cyd', 'bob']
+Ages: 36 37
+memo: True True
+OK
+`),
+	})
+}
+
+func TestBindPickles(t *testing.T) {
+	// t.Parallel()
+	path := "_examples/pickles"
+	testPkg(t, pkg{
+		path:   path,
+		lang:   features[path],
+		cmd:    "build",
+		extras: nil,
+		want: []byte(`Shape: line 2 3 2 6 n
+new handle: True Shape
+MyPoint: MyPoint -1 2 3
+Hidden: ValueError: gob: type pickles.Hidden has no exported fields
+bad data: ValueError: unexpected EOF
+OK
+`),
+	})
+}
+
+func TestBindClosers(t *testing.T) {
+	// t.Parallel()
+	path := "_examples/closers"
+	testPkg(t, pkg{
+		path:   path,
+		lang:   features[path],
+		cmd:    "build",
+		extras: nil,
+		want: []byte(`in with: a False 1
+after with: True 0
+raised: KeyError('b') True 0
+close error: already closed 0
+Resource: 1
+after Resource: 0
+Files: 2 2
+after Files: True True 0
+OK
+`),
+	})
+}
+
+func TestBindKeywords(t *testing.T) {
+	// t.Parallel()
+	path := "_examples/keywords"
+	testPkg(t, pkg{
+		path:   path,
+		lang:   features[path],
+		cmd:    "build",
+		extras: nil,
+		want: []byte(`Connect: x:5432
+Connect reordered: y:80
+Connect mixed: z:8080
+Join: a-b ''
+Move: a->b
+Blank: 3
+Unnamed: unnamed
+Add: 21 12
+Scale: 3 6
+Connect: TypeError for an unknown keyword
+OK
+`),
+	})
+}
+
+func TestBindStructInit(t *testing.T) {
+	// t.Parallel()
+	path := "_examples/structinit"
+	testPkg(t, pkg{
+		path:   path,
+		lang:   features[path],
+		cmd:    "build",
+		extras: nil,
+		want: []byte(`kwargs: "a" 3 0.5 true "in" ptr [x,y] {k=1} [4 5] cb
+args: "b" 4 0 false "" nil [] {} [0 0] nil
+mixed: "d" 5 0 false "" nil [] {} [0 0] nil
+none: "" 0 0 false "" nil [] {} [0 0] nil
+Nested: TypeError: supplied argument type <class 'int'> is not a go.GoClass
+Count: TypeError
+OK
+`),
+	})
+}
+
+func TestBindAsDicts(t *testing.T) {
+	// t.Parallel()
+	path := "_examples/asdicts"
+	testPkg(t, pkg{
+		path:   path,
+		lang:   features[path],
+		cmd:    "build",
+		extras: nil,
+		want: []byte(`asdict: {'Name': 'tri', 'Origin': {'X': 1, 'Y': 2}, 'Center': None, 'Points': [{'X': 0, 'Y': 0}, {'X': 3, 'Y': 0}, {'X': 0, 'Y': 4}], 'Tags': {'a': [1, 2]}, 'Scale': 1.5, 'Visible': True, 'Data': b'xyz', 'Box': [{'X': 0, 'Y': 0}, {'X': 3, 'Y': 4}], 'label': 'L'}
+from_dict: quad {1 2} &{5 6} [{0 0} {3 0} {0 4} {7 8}] map[a:[1 2] b:[3]] 1.5 true "xyz" [{0 0} {3 4}] L 0
+json: quad {1 2} &{5 6} [{0 0} {3 0} {0 4} {7 8}] map[a:[1 2] b:[3]] 1.5 true "" [{0 0} {3 4}] L 0
+partial: {'X': 1, 'Y': 0}
+GoClass: 1 1
+TypeError: Go asdicts.Point has no field "Z"
+TypeError: cannot convert str to Go int
+TypeError: cannot convert float to Go int
+TypeError: cannot convert list to Go asdicts.Point
+ValueError: cannot convert a sequence of length 1 to Go [2]asdicts.Point
+OK
+`),
+	})
+}
+
+func TestBindJSONConv(t *testing.T) {
+	// t.Parallel()
+	path := "_examples/jsonconv"
+	testPkg(t, pkg{
+		path:   path,
+		lang:   features[path],
+		cmd:    "build",
+		extras: nil,
+		want: []byte(`to_json: {"name":"srv","tags":["a","b"],"origin":{"x":1,"y":2},"level":"**","Enabled":true}
+from_json: srv 0 "" [a b] {1 2} map[] 2 true
+bytes: db 5432 "" [] {0 0} map[k:v] 3 false
+again: {"name":"db","port":5432,"tags":null,"origin":{"x":0,"y":0},"labels":{"k":"v"},"level":"***","Enabled":false}
+point: 3 0 {"x":3,"y":0}
+ValueError: json: cannot unmarshal number into Go struct field Config.name of type string
+ValueError: invalid level: "x"
+ValueError: invalid character 'o' in literal null (expecting 'u')
+TypeError: a bytes-like object is required, not 'int'
+OK
+`),
+	})
+}
+
+func TestBindOperators(t *testing.T) {
+	// t.Parallel()
+	path := "_examples/operators"
+	testPkg(t, pkg{
+		path:   path,
+		lang:   features[path],
+		cmd:    "build",
+		extras: []string{"-operators=Dot=__matmul__"},
+		want: []byte(`a + b: (4, 7)
+b - a: (2, 3)
+a * 3: (3, 6)
+b / 2: (1.5, 2.5)
+-a: (-1, -2)
+a @ b: 13.0
+c: (4, 7) a: (1, 2)
+GoError: division by zero
+Vec is not ordered
+cheap < dear: True cheap >= dear: False
+dear <= dear: True dear > cheap: True
+sorted: [150, 500, 1000]
+dear % 300: 100
+v1 < v2: True v2 < v1: False
+OK
+`),
+	})
+}
+
+func TestBindSorting(t *testing.T) {
+	// t.Parallel()
+	path := "_examples/sorting"
+	testPkg(t, pkg{
+		path:   path,
+		lang:   features[path],
+		cmd:    "build",
+		extras: nil,
+		want: []byte(`by age: ['Bob', 'Dee', 'Cid', 'Ann']
+reversed: ['Ann', 'Cid', 'Bob', 'Dee']
+by key: ['Ann', 'Bob', 'Cid', 'Dee']
+Ann < Bob: False Bob < Ann: True
+by name: ['Ann', 'Bob', 'Dee']
+reversed: ['Dee', 'Bob', 'Ann']
+scores: [1.0, 2.25, 3.5]
+words: ['pear', 'fig', 'apple']
+points sortable: False
+OK
+`),
+	})
+}
+
+func TestBindTruthy(t *testing.T) {
+	// t.Parallel()
+	path := "_examples/truthy"
+	testPkg(t, pkg{
+		path:   path,
+		lang:   features[path],
+		cmd:    "build",
+		extras: nil,
+		want: []byte(`node: True next: False nil: False
+end of list
+zero span: False span: True
+empty ints: False
+ints: True
+empty counts: False counts: True
+shape: True nil shape: False
+OK
+`),
+	})
+}
+
+func TestBindDocstrings(t *testing.T) {
+	// t.Parallel()
+	path := "_examples/docstrings"
+	testPkg(t, pkg{
+		path:   path,
+		lang:   features[path],
+		cmd:    "build",
+		extras: nil,
+		want: []byte(`Join(str sep, []str words) str
+
+Join joins the words with the separator
+
+Args:
+    sep (str): Go string
+    *args (str): Go string
+
+Returns:
+    str: Go string
+---
+Parse(int n) int count, str err
+
+Parse parses a count, failing for negative ones
+
+Args:
+    n (int): Go int
+
+Returns:
+    int: Go uint
+
+Raises:
+    GoError: for a non-nil Go error
+---
+Get() object
+
+Get returns the value of the box
+
+Returns:
+    object: Go interface{}
+---
+Fill(object o, int arg_1) 
+
+Fill fills the box with copies of the given box
+
+Args:
+    o (object): Go *docstrings.Box
+    arg_1 (int): Go int
+    goRun (bool): run the call in a new goroutine, without waiting for it to return
+---
+OK
+`),
+	})
+}
+
+func TestBindStubs(t *testing.T) {
+	// t.Parallel()
+	path := "_examples/stubs"
+	testPkg(t, pkg{
+		path:   path,
+		lang:   features[path],
+		cmd:    "build",
+		extras: nil,
+		want: []byte(`py.typed: True
+go.GoClass: True
+Max: int
+class Point(go.GoClass)
+    X: float
+    Y: float
+    Tags: go.Slice_string
+    def __init__(self, *args: Any, X: float=..., Y: float=..., Tags: Union[go.Slice_string, Sequence[str]]=..., handle: int=...) -> None
+    def Scale(self, f: float) -> Point
+class Path(go.GoClass, MutableSequence[Point])
+class Counts(go.GoClass, MutableMapping[str, int])
+def NewPoint(x: float, y: float) -> Point
+def Tag(p: Optional[Point], *args: str) -> None
+def Total(c: Union[Counts, Mapping[str, int]]) -> int
+OK
+`),
+	})
+}
+
+func TestBindTypeHints(t *testing.T) {
+	// t.Parallel()
+	path := "_examples/typehints"
+	testPkg(t, pkg{
+		path:   path,
+		lang:   features[path],
+		cmd:    "build",
+		extras: []string{"-annotate"},
+		want: []byte(`Describe (s: 'Optional[Shape]') -> 'str'
+Total (shapes: 'Union[Slice_typehints_Shape, Sequence[Shape]]') -> 'int'
+Lookup (counts: 'Union[Map_string_int, Mapping[str, int]]', key: 'str') -> 'int'
+Log (goRun: 'bool' = False, *args: 'str') -> 'None'
+Square () -> 'Shape'
+Grown (self, n: 'int') -> 'Shape'
+Sides: int
+Grown: Shape
+square with 4 sides
+nil
+OK
+`),
+	})
+}
+
+func TestBindIntrospect(t *testing.T) {
+	// t.Parallel()
+	path := "_examples/introspect"
+	testPkg(t, pkg{
+		path: path,
+		lang: features[path],
+		cmd:  "build",
+		want: []byte(`Thing: ['Describe', 'Grow', 'Hello', 'ID', 'Name', 'Size']
+box hello box
+crate 7 base
+Deep: ['Depth', 'Describe', 'Grow', 'Hello', 'ID', 'Name', 'Size']
+hello deep
+Both: ['Hello', 'Size', 'Title']
+False title
+__all__: ['Base', 'Both', 'Deep', 'Named', 'NewThing', 'Thing', 'Titled']
+True True
+OK
+`),
+	})
+}
+
+func TestBindTextSig(t *testing.T) {
+	// t.Parallel()
+	path := "_examples/textsig"
+	testPkg(t, pkg{
+		path: path,
+		lang: features[path],
+		cmd:  "build",
+		want: []byte(`textsig_Add (a, b)
+textsig_Point_Scale (_handle, factor, goRun)
+textsig_Point_X_Set (handle, val)
+GoPySetenv (key, value)
+(a, b)
+3 7
+OK
+`),
+	})
+}
+
+func TestBindFinalize(t *testing.T) {
+	// t.Parallel()
+	path := "_examples/finalize"
+	testPkg(t, pkg{
+		path:   path,
+		lang:   features[path],
+		cmd:    "build",
+		extras: []string{"-finalize"},
+		want: []byte(`closed on release: ['a']
+still referenced: ['a']
+closed on last release: ['a', 'b']
+closed once: ['a', 'b', 'c']
+shared still referenced: ['a', 'b', 'c']
+shared closed on last release: ['a', 'b', 'c', 'shared']
+in a cycle: ['a', 'b', 'c', 'shared']
+handles: 0
+collected: ['a', 'b', 'c', 'shared', 'd']
+OK
+`),
+	})
+}
+
+func TestBindMapIter(t *testing.T) {
+	// t.Parallel()
+	path := "_examples/mapiter"
+	testPkg(t, pkg{
+		path: path,
+		lang: features[path],
+		cmd:  "build",
+		want: []byte(`ann str 31 int
+bob str 27 int
+cy str 45 int
+keys: ['ann', 'bob', 'cy']
+values: [27, 31, 45]
+origin Point 0 0
+unit Point 1 1
+released: True
+OK
+`),
+	})
+}
+
+func TestBindFormats(t *testing.T) {
+	// t.Parallel()
+	path := "_examples/formats"
+	testPkg(t, pkg{
+		path: path,
+		lang: features[path],
+		cmd:  "build",
+		want: []byte(`{1 2 a}
+{X:1 Y:2 Label:a}
+formats.Point{X:1, Y:2, Label:"a"}
+formats.Point
+{From:{X:1 Y:2 Label:a} To:{X:3 Y:4 Label:}}
+21.5C|21.5C|formats.Temp{C:21.5}|  21.5C|
+OK
+`),
+	})
+}
+
+func TestBindNoGIL(t *testing.T) {
+	// t.Parallel()
+	path := "_examples/nogil"
+	testPkg(t, pkg{
+		path:   path,
+		lang:   features[path],
+		cmd:    "build",
+		extras: []string{"-hold-gil=^Hold|^Tick$"},
+		want: []byte(`ticks while sleeping: True
+ticks while holding the GIL: 0
+ticks in a method: True
+slept: 200
+repeat: ababab
+OK
+`),
+	})
+}
+
+func TestBindAsyncDoc(t *testing.T) {
+	// t.Parallel()
+	path := "_examples/asyncdoc"
+	testPkg(t, pkg{
+		path:   path,
+		lang:   features[path],
+		cmd:    "build",
+		extras: nil,
+		want: []byte(`fetch: item-1
+gathered: ['item-0', 'item-1', 'item-2', 'item-3']
+concurrent: True
+lookup: item-2
+add: 5
+iscoroutinefunction(Fetch): True
+iscoroutinefunction(Lookup): False
+directive in doc: False
+OK
+`),
+	})
+}
+
+func TestBindChanAio(t *testing.T) {
+	// t.Parallel()
+	path := "_examples/chanaio"
+	testPkg(t, pkg{
+		path:   path,
+		lang:   features[path],
+		cmd:    "build",
+		extras: nil,
+		want: []byte(`Count: [0, 1, 2, 3, 4]
+event loop ran: True
+Events: [0, 1, 2]
+chan_queue: [0, 1]
+OK
+`),
+	})
+}
+
+func TestBindCancel(t *testing.T) {
+	// t.Parallel()
+	path := "_examples/cancel"
+	testPkg(t, pkg{
+		path:   path,
+		lang:   features[path],
+		cmd:    "build",
+		extras: nil,
+		want: []byte(`Wait: 10
+interrupted
+stopped: Wait
+WaitAsync: 10
+cancelled
+stopped: Wait
+timed out
+stopped: worker
+OK
+`),
+	})
+}
+
+func TestBindTimeout(t *testing.T) {
+	// t.Parallel()
+	path := "_examples/timeout"
+	testPkg(t, pkg{
+		path:   path,
+		lang:   features[path],
+		cmd:    "build",
+		extras: nil,
+		want: []byte(`Wait: 10
+Wait timed out: call timed out
+stopped: Wait
+Sleep timed out: call timed out
+stopped: Wait
+Slow: 1
+Slow with timeout: 2
+Slow timed out: call timed out after 0.05 seconds
+WaitAsync: 10
+WaitAsync timed out: call timed out
+stopped: Wait
+OK
+`),
+	})
+}
+
+func TestBindInterrupt(t *testing.T) {
+	// t.Parallel()
+	path := "_examples/interrupt"
+	testPkg(t, pkg{
+		path:   path,
+		lang:   features[path],
+		cmd:    "build",
+		extras: nil,
+		want: []byte(`Spin: 1
+Spin interrupted promptly: True
+stopped: Spin
+Serve interrupted
+stopped: Serve
+Spin after: 3
+OK
+`),
+	})
+}
+
+func TestBindConcurrency(t *testing.T) {
+	// t.Parallel()
+	path := "_examples/concurrency"
+	testPkg(t, pkg{
+		path:   path,
+		lang:   features[path],
+		cmd:    "build",
+		extras: nil,
+		want: []byte(`errors: []
+counter: True
+handles released: True
+OK
+`),
+	})
+}
+
+func TestBindSubinterp(t *testing.T) {
+	// t.Parallel()
+	path := "_examples/subinterp"
+	testPkg(t, pkg{
+		path:   path,
+		lang:   features[path],
+		cmd:    "build",
+		extras: nil,
+		want: []byte(`main Twice: 42
+main caught ErrNegative: negative number
+main Boxed: True
+main Apply: 2
+sub Twice: 42
+sub caught ErrNegative: negative number
+sub Boxed: True
+sub Apply: 2
+main Twice: 42
+main caught ErrNegative: negative number
+main Boxed: True
+main Apply: 2
+OK
+`),
+	})
+}
+
+func TestBindFreeThreaded(t *testing.T) {
+	// t.Parallel()
+	path := "_examples/freethreaded"
+	testPkg(t, pkg{
+		path:   path,
+		lang:   features[path],
+		cmd:    "build",
+		extras: []string{"-free-threaded"},
+		want: []byte(`errors: []
+log: True
+counts: True
+items: True
+name: True
+bumps: True
+doc: True
+OK
+`),
+	})
+}
+
+func TestBindPanics(t *testing.T) {
+	// t.Parallel()
+	path := "_examples/panics"
+	testPkg(t, pkg{
+		path:   path,
+		lang:   features[path],
+		cmd:    "build",
+		extras: nil,
+		want: []byte(`Index: runtime error: index out of range [5] with length 3
+Index trace: True
+Fail: boom
+Fail trace: True
+FailError: failed with code 42
+Inc: 1 2
+Inc: counter reached 3
+Inc after panic: 3
+GoPanic is RuntimeError: True
+goRun: ['in goroutine']
+Ok: 42
+OK
+`),
+	})
+}
+
+func TestBindDeadlines(t *testing.T) {
+	// t.Parallel()
+	path := "_examples/deadlines"
+	testPkg(t, pkg{
+		path:   path,
+		lang:   features[path],
+		cmd:    "build",
+		extras: nil,
+		want: []byte(`HasDeadline: False
+HasDeadline with_timeout: True
+Wait: 10
+Wait timed out: call timed out True
+Wait deadline: call timed out
+Wait timeout: call timed out
+Wait after timeout: 10
+Wait cancelled: context canceled
+TypeError: ctx must be a go.Context, not int
+OK
+`),
+	})
+}
+
+func TestBindPool(t *testing.T) {
+	// t.Parallel()
+	path := "_examples/pool"
+	testPkg(t, pkg{
+		path:   path,
+		lang:   features[path],
+		cmd:    "build",
+		extras: nil,
+		want: []byte(`results: True
+max running: 4
+map: [1, 2, 3]
+error: negative
+threads: True
+asyncio: 45
+after shutdown: cannot schedule new futures after shutdown
+ValueError: Pool needs at least 1 goroutine, not 0
+OK
+`),
+	})
+}
+
+func TestBindStress(t *testing.T) {
+	// t.Parallel()
+	path := "_examples/stress"
+	testPkg(t, pkg{
+		path:   path,
+		lang:   features[path],
+		cmd:    "build",
+		extras: []string{"-gen-stress-tests"},
+		want: []byte(`test_callback_Apply ok
+test_callback_Format ok
+test_callback_Visit ok
+test_chan_Chan_int ok
+test_churn_Config ok
+test_churn_Counts ok
+test_churn_Names ok
+test_fields_Config ok
+calls: True
+OK
+`),
+	})
+}
+
+func TestBindCffiBackend(t *testing.T) {
+	// t.Parallel()
+	path := "_examples/cffibackend"
+	testPkg(t, pkg{
+		path:   path,
+		lang:   features[path],
+		cmd:    "build",
+		extras: []string{"-backend=cffi"},
+		want: []byte(`shim: True
+Greet: hello pypy
+Scale: 3.0
+GoError: negative factor
+Negate: False
+Apply: 42
+Join: a-b-c
+Swap: ababab 2
+Value: 1
+OK
+`),
+	})
+}
+
+func TestBindCythonBackend(t *testing.T) {
+	// t.Parallel()
+	path := "_examples/cythonbackend"
+	testPkg(t, pkg{
+		path:   path,
+		lang:   features[path],
+		cmd:    "build",
+		extras: []string{"-backend=cython"},
+		want: []byte(`Sum: 4.0
+extend: [0.5, 1.5, 2.0, 1.0, 2.0, 3.0]
+Ramp: [0, 1, 2, 3, 4] 5
+extend: [0, 1, 2, 3, 4, 5, 6, 7]
+TypeError
+Greet: hello cython
+Scale: 3.0
+GoError: negative factor
+Apply: 42
+OK
+`),
+	})
+}
+
+func TestBindCapiBackend(t *testing.T) {
+	// t.Parallel()
+	path := "_examples/capibackend"
+	testPkg(t, pkg{
+		path:   path,
+		lang:   features[path],
+		cmd:    "build",
+		extras: []string{"-backend=capi"},
+		want: []byte(`build.py: False
+signature: (a, b)
+Add8: 127
+OverflowError
+IsEven: True False
+Greet: hello capi
+Scale: 3.0
+GoError: negative factor
+Apply: 42
+OK
+`),
+	})
+}
+
+func TestBindCtypesBackend(t *testing.T) {
+	// t.Parallel()
+	path := "_examples/ctypesbackend"
+	testPkg(t, pkg{
+		path:   path,
+		lang:   features[path],
+		cmd:    "build",
+		extras: []string{"-backend=ctypes"},
+		want: []byte(`build.py: False
+python module: True
+Norm1: 7
+Add8: 127
+OverflowError
+Shout: CTYPES
+Scale: 3.0
+GoError: negative factor
+Apply: 42
+OK
+`),
+	})
+}
+
+func TestBindLimitedAPI(t *testing.T) {
+	// t.Parallel()
+	path := "_examples/limitedapi"
+	testPkg(t, pkg{
+		path:   path,
+		lang:   features[path],
+		cmd:    "build",
+		extras: []string{"-limited-api"},
+		want: []byte(`abi3: True
+Abs: 5.0
+Conj: (1+2j)
+buffer: d [0.0, 2.5, 2.0] 4.5
+from_dict: {'X': 1, 'Y': 2}
+Greet: hello abi3
+Apply: 42
+OK
+`),
+	})
+}
+
+func TestBindChecksum(t *testing.T) {
+	// t.Parallel()
+	path := "_examples/checksum"
+	testPkg(t, pkg{
+		path: path,
+		lang: features[path],
+		cmd:  "build",
+		want: []byte(`Hello: hello gopy
+caught ImportError: True
+caught ImportError: True
+Hello: hello again
+OK
+`),
+	})
+}
+
 // Generate / verify SUPPORT_MATRIX.md from features map.
 func TestCheckSupportMatrix(t *testing.T) {
 	var buf bytes.Buffer
@@ -981,7 +3047,6 @@ func testPkgBackend(t *testing.T, pyvm string, table pkg) {
 		}
 	}
 	defer os.RemoveAll(workdir)
-	defer bind.ResetPackages()
 
 	env := make([]string, len(testEnvironment))
 	copy(env, testEnvironment)
@@ -12,34 +12,145 @@ import (
 	"github.com/rudderlabs/gopy/bind"
 )
 
-// 1 = pkg name, 2 = -user, 3 = version 4 = author, 5 = email, 6 = desc, 7 = url
+// 1 = pkg name, 2 = -user, 3 = version 4 = author, 5 = email, 6 = desc, 7 = url,
+// 8 = build requirements
 const (
-	setupTempl = `import setuptools
-
-with open("README.md", "r") as fh:
-    long_description = fh.read()
-
-setuptools.setup(
-    name="%[1]s%[2]s",
-    version="%[3]s",
-    author="%[4]s",
-    author_email="%[5]s",
-    description="%[6]s",
-    long_description=long_description,
-    long_description_content_type="text/markdown",
-    url="%[7]s",
-    packages=setuptools.find_packages(),
-    classifiers=[
-        "Programming Language :: Python :: 3",
-        "License :: OSI Approved :: BSD License",
-        "Operating System :: OS Independent",
-    ],
-    include_package_data=True,
-)
+	pyprojectTempl = `[build-system]
+requires = [%[8]s]
+build-backend = "gopy_build"
+backend-path = ["."]
+
+[project]
+name = "%[1]s%[2]s"
+version = "%[3]s"
+description = "%[6]s"
+readme = "README.md"
+license = {file = "LICENSE"}
+authors = [{name = "%[4]s", email = "%[5]s"}]
+classifiers = [
+    "Programming Language :: Python :: 3",
+    "License :: OSI Approved :: BSD License",
+]
+
+[project.urls]
+Homepage = "%[7]s"
 `
 
-	manifestTempl = `global-include *.so *.py
-global-exclude build.py
+	// gopyBuildTempl is the PEP 517 build backend of the package, which runs
+	// gopy wheel to build the wheels, so that the Go code is built during
+	// pip install.
+	// 1 = pkg name, 2 = cmd
+	gopyBuildTempl = `"""PEP 517 build backend of the %[1]s package, which builds the Go extension module
+with gopy wheel (https://github.com/rudderlabs/gopy), the gopy command being on PATH,
+or given by the GOPY environment variable.  The [project] metadata of pyproject.toml
+is passed to gopy wheel, which builds the package in place, like gopy pkg.
+File is generated by gopy (will not be overwritten though)."""
+
+import glob
+import io
+import os
+import shlex
+import subprocess
+import sys
+import tarfile
+import time
+
+# the gopy command that generated the package
+GOPY_CMD = %[2]q
+
+# flags of GOPY_CMD that are set by the backend
+_OVERRIDES = ("output", "vm", "version", "desc", "author", "email", "url", "user",
+    "wheel-dir", "dist-name", "editable", "repair", "plat")
+
+def _project():
+    try:
+        import tomllib
+    except ImportError:
+        import tomli as tomllib
+    with open("pyproject.toml", "rb") as f:
+        return tomllib.load(f)["project"]
+
+def _gopy_args(*extra):
+    """returns the gopy wheel command of GOPY_CMD, with the metadata of pyproject.toml"""
+    args = shlex.split(GOPY_CMD)
+    flags = []
+    i = 2
+    while i < len(args):
+        name, eq, _ = args[i].lstrip("-").partition("=")
+        if args[i].startswith("-") and name in _OVERRIDES:
+            if not eq and name not in ("editable", "repair"):
+                i += 1  # value in the next arg
+        else:
+            flags.append(args[i])
+        i += 1
+    project = _project()
+    authors = project.get("authors") or [{}]
+    meta = [
+        "-output=" + os.getcwd(),
+        "-vm=" + sys.executable,
+        "-dist-name=" + project["name"],
+        "-version=" + project["version"],
+        "-desc=" + project.get("description", ""),
+        "-author=" + authors[0].get("name", ""),
+        "-email=" + authors[0].get("email", ""),
+        "-url=" + project.get("urls", {}).get("Homepage", ""),
+    ]
+    return [os.environ.get("GOPY", args[0]), "wheel"] + meta + list(extra) + flags
+
+def _build(wheel_directory, *extra):
+    before = set(glob.glob(os.path.join(wheel_directory, "*.whl")))
+    args = _gopy_args("-wheel-dir=" + os.path.abspath(wheel_directory), *extra)
+    print(" ".join(args))
+    subprocess.check_call(args)
+    built = set(glob.glob(os.path.join(wheel_directory, "*.whl"))) - before
+    if not built:
+        raise RuntimeError("gopy wheel did not build a wheel in %%s" %% wheel_directory)
+    return os.path.basename(max(built, key=os.path.getmtime))
+
+def _requires():
+    return ["tomli"] if sys.version_info < (3, 11) else []
+
+def get_requires_for_build_wheel(config_settings=None):
+    return _requires()
+
+def get_requires_for_build_editable(config_settings=None):
+    return _requires()
+
+def get_requires_for_build_sdist(config_settings=None):
+    return _requires()
+
+def build_wheel(wheel_directory, config_settings=None, metadata_directory=None):
+    return _build(wheel_directory)
+
+def build_editable(wheel_directory, config_settings=None, metadata_directory=None):
+    return _build(wheel_directory, "-editable")
+
+def build_sdist(sdist_directory, config_settings=None):
+    project = _project()
+    authors = project.get("authors") or [{}]
+    base = "%%s-%%s" %% (project["name"].replace("-", "_").replace(".", "_").lower(), project["version"])
+    pkginfo = "".join([
+        "Metadata-Version: 2.1\n",
+        "Name: %%s\n" %% project["name"],
+        "Version: %%s\n" %% project["version"],
+        "Summary: %%s\n" %% project.get("description", ""),
+        "Home-page: %%s\n" %% project.get("urls", {}).get("Homepage", ""),
+        "Author: %%s\n" %% authors[0].get("name", ""),
+        "Author-email: %%s\n" %% authors[0].get("email", ""),
+    ]).encode()
+    fname = base + ".tar.gz"
+    with tarfile.open(os.path.join(sdist_directory, fname), "w:gz", format=tarfile.PAX_FORMAT) as tar:
+        for root, dirs, files in os.walk("."):
+            dirs[:] = sorted(d for d in dirs if d not in ("dist", "build", "__pycache__", ".git") and not d.endswith(".egg-info"))
+            for f in sorted(files):
+                if not f.endswith((".so", ".pyd", ".dylib", ".dll", ".whl")):
+                    path = os.path.normpath(os.path.join(root, f))
+                    tar.add(path, arcname=os.path.join(base, path))
+        info = tarfile.TarInfo(os.path.join(base, "PKG-INFO"))
+        info.size = len(pkginfo)
+        info.mtime = time.time()
+        tar.addfile(info, io.BytesIO(pkginfo))
+    return fname
 `
 
 	// 1 = pkg name
@@ -100,10 +211,12 @@ build:
 	$(MAKE) -C %[1]s build
 
 install-pkg:
-	# this does a local install of the package, building the sdist and then directly installing it
-	rm -rf dist build */*.egg-info *.egg-info
-	$(PYTHON) setup.py sdist
-	$(PIP) install dist/*.tar.gz
+	# this does a local install of the package, which is built by the gopy_build.py backend
+	$(PIP) install .
+
+install-dev:
+	# this does an editable install of the package, which is rebuilt in place by make build
+	$(PIP) install -e .
 
 install-exe:
 	# install executable into /usr/local/bin
@@ -112,7 +225,22 @@ install-exe:
 `
 )
 
-// GenPyPkgSetup generates python package setup files
+// buildRequires returns the python packages needed to build the package
+// with the given backend, for the build-system of pyproject.toml.
+func buildRequires(backend string) string {
+	switch backend {
+	case "", bind.BackendPyBindGen:
+		return `"pybindgen"`
+	case bind.BackendCffi:
+		return `"cffi"`
+	case bind.BackendCython:
+		return `"cython"`
+	}
+	return ""
+}
+
+// GenPyPkgSetup generates python package setup files: pyproject.toml, with
+// the gopy_build.py PEP 517 backend building the package with gopy wheel
 func GenPyPkgSetup(cfg *BuildCfg, user, version, author, email, desc, url string) error {
 	os.Chdir(cfg.OutputDir)
 
@@ -121,19 +249,19 @@ func GenPyPkgSetup(cfg *BuildCfg, user, version, author, email, desc, url string
 		dashUser = "-" + user
 	}
 
-	sf, err := os.Create(filepath.Join(cfg.OutputDir, "setup.py"))
+	pf, err := os.Create(filepath.Join(cfg.OutputDir, "pyproject.toml"))
 	if err != nil {
 		return err
 	}
-	fmt.Fprintf(sf, setupTempl, cfg.Name, dashUser, version, author, email, desc, url)
-	sf.Close()
+	fmt.Fprintf(pf, pyprojectTempl, cfg.Name, dashUser, version, author, email, desc, url, buildRequires(cfg.Backend))
+	pf.Close()
 
-	mi, err := os.Create(filepath.Join(cfg.OutputDir, "MANIFEST.in"))
+	bf, err := os.Create(filepath.Join(cfg.OutputDir, "gopy_build.py"))
 	if err != nil {
 		return err
 	}
-	fmt.Fprintf(mi, manifestTempl)
-	mi.Close()
+	fmt.Fprintf(bf, gopyBuildTempl, cfg.Name, cfg.Cmd)
+	bf.Close()
 
 	lf, err := os.Create(filepath.Join(cfg.OutputDir, "LICENSE"))
 	if err != nil {